@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/containers/libpod/cmd/podman/shared"
+	"github.com/containers/libpod/libpod"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	autoUpdateCommand     cliconfig.AutoUpdateValues
+	autoUpdateDescription = `
+        podman auto-update
+
+        Update containers labeled with io.containers.autoupdate=registry to newer images of the same reference in their registries, restarting the systemd units running them, or re-creating the containers not run by a unit.
+`
+
+	_autoUpdateCommand = &cobra.Command{
+		Use:   "auto-update [flags]",
+		Args:  noSubArgs,
+		Short: "Auto-update containers according to their auto-update policy",
+		Long:  autoUpdateDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			autoUpdateCommand.InputArgs = args
+			autoUpdateCommand.GlobalFlags = MainGlobalOpts
+			autoUpdateCommand.Remote = remoteclient
+			return autoUpdateCmd(&autoUpdateCommand)
+		},
+		Example: `podman auto-update`,
+	}
+)
+
+func init() {
+	autoUpdateCommand.Command = _autoUpdateCommand
+	autoUpdateCommand.SetHelpTemplate(HelpTemplate())
+	autoUpdateCommand.SetUsageTemplate(UsageTemplate())
+	flags := autoUpdateCommand.Flags()
+	flags.StringVar(&autoUpdateCommand.Authfile, "authfile", shared.GetAuthFile(""), "Path of the authentication file. Use REGISTRY_AUTH_FILE environment variable to override")
+}
+
+func autoUpdateCmd(c *cliconfig.AutoUpdateValues) error {
+	runtime, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer runtime.DeferredShutdown(false)
+
+	options := libpod.AutoUpdateOptions{
+		Authfile: c.Authfile,
+		Writer:   os.Stderr,
+	}
+	reports, errs := runtime.AutoUpdate(getContext(), options)
+	// Print the restarted units once, whatever the number of containers
+	// they run, and the re-created containers
+	restarted := make(map[string]bool)
+	for _, report := range reports {
+		switch {
+		case !report.Updated:
+		case report.Unit == "":
+			fmt.Println(report.ContainerID)
+		case !restarted[report.Unit]:
+			restarted[report.Unit] = true
+			fmt.Println(report.Unit)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs[1:] {
+		logrus.Error(err)
+	}
+	return errs[0]
+}
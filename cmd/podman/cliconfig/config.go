@@ -1,6 +1,8 @@
 package cliconfig
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 )
 
@@ -36,11 +38,18 @@ type MainFlags struct {
 
 	RemoteUserName       string
 	RemoteHost           string
+	Port                 int
+	IdentityFile         string
 	VarlinkAddress       string
 	ConnectionName       string
 	RemoteConfigFilePath string
 }
 
+type AutoUpdateValues struct {
+	PodmanCommand
+	Authfile string
+}
+
 type AttachValues struct {
 	PodmanCommand
 	DetachKeys string
@@ -120,6 +129,7 @@ type DiffValues struct {
 
 type ExecValues struct {
 	PodmanCommand
+	Detach      bool
 	DetachKeys  string
 	Env         []string
 	Privileged  bool
@@ -157,6 +167,7 @@ type GenerateSystemdValues struct {
 	PodmanCommand
 	Name          bool
 	Files         bool
+	New           bool
 	RestartPolicy string
 	StopTimeout   int
 }
@@ -208,6 +219,7 @@ type InspectValues struct {
 	Format     string
 	Size       bool
 	Latest     bool
+	Verify     bool
 }
 
 type KillValues struct {
@@ -246,6 +258,7 @@ type LogsValues struct {
 	Details    bool
 	Follow     bool
 	Since      string
+	Until      string
 	Tail       uint64
 	Timestamps bool
 	Latest     bool
@@ -267,6 +280,12 @@ type NetworkListValues struct {
 
 type NetworkRmValues struct {
 	PodmanCommand
+	Force bool
+}
+
+type NetworkPruneValues struct {
+	PodmanCommand
+	Force bool
 }
 
 type NetworkInspectValues struct {
@@ -278,6 +297,15 @@ type PauseValues struct {
 	All bool
 }
 
+type UpdateValues struct {
+	PodmanCommand
+	BlkioWeight string
+	CPUShares   uint64
+	Memory      string
+	MemorySwap  string
+	PidsLimit   int64
+}
+
 type HealthCheckValues struct {
 	PodmanCommand
 }
@@ -295,16 +323,23 @@ type KubePlayValues struct {
 type PodCreateValues struct {
 	PodmanCommand
 	CgroupParent string
+	CPUs         float64
+	DNSCache     bool
+	Expose       []string
 	Infra        bool
 	InfraImage   string
 	InfraCommand string
 	LabelFile    []string
 	Labels       []string
+	Memory       string
 	Name         string
 	Hostname     string
+	PidsLimit    int64
 	PodIDFile    string
 	Publish      []string
+	Restart      string
 	Share        string
+	Volume       []string
 }
 
 type PodInspectValues struct {
@@ -557,7 +592,8 @@ type UnpauseValues struct {
 
 type VarlinkValues struct {
 	PodmanCommand
-	Timeout int64
+	Timeout    int64
+	TokensFile string
 }
 
 type SetTrustValues struct {
@@ -601,7 +637,8 @@ type VolumeLsValues struct {
 
 type VolumePruneValues struct {
 	PodmanCommand
-	Force bool
+	Filter []string
+	Force  bool
 }
 
 type VolumeRmValues struct {
@@ -626,10 +663,57 @@ type SystemPruneValues struct {
 
 type SystemRenumberValues struct {
 	PodmanCommand
+	DryRun bool
+}
+
+type SystemMemoryGuardValues struct {
+	PodmanCommand
+	Interval time.Duration
+}
+
+type SystemSchedulerValues struct {
+	PodmanCommand
+	Interval time.Duration
+}
+
+type SystemReaperValues struct {
+	PodmanCommand
+	Interval time.Duration
+}
+
+type SystemStateSyncValues struct {
+	PodmanCommand
+	Interval time.Duration
+}
+
+type SystemMetricsValues struct {
+	PodmanCommand
+	Listen string
+}
+
+type SystemServiceValues struct {
+	PodmanCommand
+	Timeout  int64
+	TLSCert  string
+	TLSKey   string
+	TLSCA    string
+	Insecure bool
+}
+
+type SystemGRPCValues struct {
+	PodmanCommand
+	TLSCert string
+	TLSKey  string
+	TLSCA   string
+}
+
+type SystemDialStdioValues struct {
+	PodmanCommand
 }
 
 type SystemMigrateValues struct {
 	PodmanCommand
+	NewRuntime string
 }
 
 type SystemDfValues struct {
@@ -11,6 +11,7 @@ const remoteclient = false
 // Commands that the local client implements
 func getMainCommands() []*cobra.Command {
 	rootCommands := []*cobra.Command{
+		_autoUpdateCommand,
 		_cpCommand,
 		_playCommand,
 		_loginCommand,
@@ -21,6 +22,7 @@ func getMainCommands() []*cobra.Command {
 		_statsCommand,
 		_umountCommand,
 		_unshareCommand,
+		_updateCommand,
 	}
 
 	if len(_varlinkCommand.Use) > 0 {
@@ -47,6 +49,7 @@ func getContainerSubCommands() []*cobra.Command {
 		_runlabelCommand,
 		_statsCommand,
 		_umountCommand,
+		_updateCommand,
 	}
 }
 
@@ -71,5 +74,13 @@ func getSystemSubCommands() []*cobra.Command {
 		_renumberCommand,
 		_dfSystemCommand,
 		_migrateCommand,
+		_memoryGuardCommand,
+		_schedulerCommand,
+		_reaperCommand,
+		_stateSyncCommand,
+		_metricsCommand,
+		_serviceCommand,
+		_dialStdioCommand,
+		_grpcCommand,
 	}
 }
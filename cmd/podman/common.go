@@ -141,10 +141,22 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 		"cidfile", "",
 		"Write the container ID to the file",
 	)
+	createFlags.Bool(
+		"conmon-debug-log", false,
+		"Capture conmon's own output to a file in the container's static directory",
+	)
+	createFlags.String(
+		"conmon-log-level", "",
+		"Log level for the container's conmon (default is podman's log level)",
+	)
 	createFlags.String(
 		"conmon-pidfile", "",
 		"Path to the file that will receive the PID of conmon",
 	)
+	createFlags.Bool(
+		"conmon-syslog", false,
+		"Log conmon messages to syslog regardless of the conmon log level",
+	)
 	createFlags.Uint64(
 		"cpu-period", 0,
 		"Limit the CPU CFS (Completely Fair Scheduler) period",
@@ -255,6 +267,10 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 		"health-interval", cliconfig.DefaultHealthCheckInterval,
 		"set an interval for the healthchecks (a value of disable results in no automatic timer setup)",
 	)
+	createFlags.String(
+		"health-on-failure", "none",
+		"action to take when the container becomes unhealthy: none, restart, stop or kill",
+	)
 	createFlags.Uint(
 		"health-retries", cliconfig.DefaultHealthCheckRetries,
 		"the number of retries allowed before a healthcheck is considered to be unhealthy",
@@ -273,7 +289,7 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 	)
 	createFlags.Bool(
 		"http-proxy", true,
-		"Set proxy environment variables in the container based on the host proxy vars",
+		"Set proxy environment variables in the container based on the host proxy vars (default from libpod.conf)",
 	)
 	createFlags.String(
 		"image-volume", cliconfig.DefaultImageVolume,
@@ -283,6 +299,10 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 		"init", false,
 		"Run an init binary inside the container that forwards signals and reaps processes",
 	)
+	createFlags.String(
+		"init-ctr", "",
+		"Make this an init container of its pod, run to completion before the pod's other containers start: 'always' or 'once'",
+	)
 	createFlags.String(
 		"init-path", "",
 		// Do not use  the Value field for setting the default value to determine user input (i.e., non-empty string)
@@ -322,7 +342,7 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 	)
 	createFlags.String(
 		"mac-address", "",
-		"Container MAC address (e.g. 92:d0:c6:0a:29:33), not currently supported",
+		"Container MAC address (e.g. 92:d0:c6:0a:29:33)",
 	)
 	createFlags.StringP(
 		"memory", "m", "",
@@ -352,6 +372,18 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 		"network", getDefaultNetwork(),
 		"Connect a container to a network",
 	)
+	createFlags.StringSlice(
+		"network-alias", []string{},
+		"Add network-scoped alias for the container",
+	)
+	createFlags.String(
+		"network-egress-rate", "",
+		"Limit outgoing network traffic in bits per second (format: <number>[<unit>], where unit = k, m or g)",
+	)
+	createFlags.String(
+		"network-ingress-rate", "",
+		"Limit incoming network traffic in bits per second (format: <number>[<unit>], where unit = k, m or g)",
+	)
 	createFlags.Bool(
 		"no-hosts", false,
 		"Do not create /etc/hosts within the container, instead use the version from the image",
@@ -416,6 +448,14 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 		"rootfs", false,
 		"The first argument is not an image but the rootfs to the exploded container",
 	)
+	createFlags.String(
+		"schedule", "",
+		"Start the container with podman system scheduler at a time in RFC 3339 format, or on a cron expression",
+	)
+	createFlags.String(
+		"sdnotify", string(define.SdNotifyModeConmon),
+		"Notify the systemd unit running podman that the container is ready from the `container`, from conmon once it is started, or ignore",
+	)
 	createFlags.StringArray(
 		"security-opt", []string{},
 		"Security Options (default [])",
@@ -424,6 +464,10 @@ func getCreateFlags(c *cliconfig.PodmanCommand) {
 		"shm-size", cliconfig.DefaultShmSize,
 		"Size of `/dev/shm`. The format is `<number><unit>`",
 	)
+	createFlags.String(
+		"stop-escalation", "",
+		"Ordered signals to stop a container, each with a timeout, replacing --stop-signal and --stop-timeout (format: `SIGNAL:TIMEOUT,...[,SIGKILL]`)",
+	)
 	createFlags.String(
 		"stop-signal", "",
 		"Signal to stop a container. Default is SIGTERM",
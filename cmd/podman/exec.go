@@ -24,7 +24,8 @@ var (
 		},
 		Example: `podman exec -it ctrID ls
   podman exec -it -w /tmp myCtr pwd
-  podman exec --user root ctrID ls`,
+  podman exec --user root ctrID ls
+  podman exec -d ctrID sleep 100`,
 	}
 )
 
@@ -34,6 +35,7 @@ func init() {
 	execCommand.SetUsageTemplate(UsageTemplate())
 	flags := execCommand.Flags()
 	flags.SetInterspersed(false)
+	flags.BoolVarP(&execCommand.Detach, "detach", "d", false, "Run the exec session in detached mode (backgrounded) and print its ID")
 	flags.StringVar(&execCommand.DetachKeys, "detach-keys", "", "Override the key sequence for detaching a container. Format is a single character [a-Z] or ctrl-<value> where <value> is one of: a-z, @, ^, [, , or _")
 	flags.StringArrayVarP(&execCommand.Env, "env", "e", []string{}, "Set environment variables")
 	flags.BoolVarP(&execCommand.Interactive, "interactive", "i", false, "Keep STDIN open even if not attached")
@@ -44,6 +46,7 @@ func init() {
 
 	flags.IntVar(&execCommand.PreserveFDs, "preserve-fds", 0, "Pass N additional file descriptors to the container")
 	flags.StringVarP(&execCommand.Workdir, "workdir", "w", "", "Working directory inside the container")
+	markFlagHiddenForRemoteClient("detach", flags)
 	markFlagHiddenForRemoteClient("latest", flags)
 	markFlagHiddenForRemoteClient("preserve-fds", flags)
 }
@@ -30,6 +30,7 @@ var (
 			return nil
 		},
 		Example: `podman generate systemd ctrID
+  podman generate systemd --new --name ctrName
 `,
 	}
 )
@@ -43,8 +44,9 @@ func init() {
 	if !remoteclient {
 		flags.BoolVarP(&containerSystemdCommand.Files, "files", "f", false, "generate files instead of printing to stdout")
 	}
+	flags.BoolVar(&containerSystemdCommand.New, "new", false, "create a new container when the unit starts, and remove it when the unit stops")
 	flags.IntVarP(&containerSystemdCommand.StopTimeout, "timeout", "t", -1, "stop timeout override")
-	flags.StringVar(&containerSystemdCommand.RestartPolicy, "restart-policy", "on-failure", "applicable systemd restart-policy")
+	flags.StringVar(&containerSystemdCommand.RestartPolicy, "restart-policy", "", "applicable systemd restart-policy, mapped from the restart policy of the container if not set")
 }
 
 func generateSystemdCmd(c *cliconfig.GenerateSystemdValues) error {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/containers/buildah/pkg/formats"
 	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/libpod"
 	"github.com/containers/libpod/pkg/adapter"
 	"github.com/containers/libpod/pkg/util"
 	"github.com/pkg/errors"
@@ -60,7 +61,9 @@ func inspectInit(command *cliconfig.InspectValues) {
 		}
 		flags.BoolVarP(&command.Latest, "latest", "l", false, "Act on the latest container podman is aware of"+containers_only)
 		flags.BoolVarP(&command.Size, "size", "s", false, "Display total file size"+containers_only)
+		flags.BoolVar(&command.Verify, "verify", false, "Report files changed from the container's image"+containers_only)
 		markFlagHiddenForRemoteClient("latest", flags)
+		markFlagHiddenForRemoteClient("verify", flags)
 	} else {
 		command.TypeObject = inspectTypeImage
 	}
@@ -113,7 +116,7 @@ func inspectCmd(c *cliconfig.InspectValues) error {
 		inspectType = inspectTypeContainer
 	}
 
-	inspectedObjects, iterateErr := iterateInput(getContext(), c.Size, args, runtime, inspectType)
+	inspectedObjects, iterateErr := iterateInput(getContext(), c.Size, c.Verify, args, runtime, inspectType)
 	if iterateErr != nil {
 		return iterateErr
 	}
@@ -131,7 +134,7 @@ func inspectCmd(c *cliconfig.InspectValues) error {
 }
 
 // func iterateInput iterates the images|containers the user has requested and returns the inspect data and error
-func iterateInput(ctx context.Context, size bool, args []string, runtime *adapter.LocalRuntime, inspectType string) ([]interface{}, error) {
+func iterateInput(ctx context.Context, size, verify bool, args []string, runtime *adapter.LocalRuntime, inspectType string) ([]interface{}, error) {
 	var (
 		data           interface{}
 		inspectedItems []interface{}
@@ -146,9 +149,9 @@ func iterateInput(ctx context.Context, size bool, args []string, runtime *adapte
 				inspectError = errors.Wrapf(err, "error looking up container %q", input)
 				break
 			}
-			data, err = ctr.Inspect(size)
+			data, err = inspectContainer(ctx, ctr, size, verify)
 			if err != nil {
-				inspectError = errors.Wrapf(err, "error inspecting container %s", ctr.ID())
+				inspectError = err
 				break
 			}
 		case inspectTypeImage:
@@ -176,9 +179,9 @@ func iterateInput(ctx context.Context, size bool, args []string, runtime *adapte
 					break
 				}
 			} else {
-				data, err = ctr.Inspect(size)
+				data, err = inspectContainer(ctx, ctr, size, verify)
 				if err != nil {
-					inspectError = errors.Wrapf(err, "error inspecting container %s", ctr.ID())
+					inspectError = err
 					break
 				}
 			}
@@ -189,3 +192,20 @@ func iterateInput(ctx context.Context, size bool, args []string, runtime *adapte
 	}
 	return inspectedItems, inspectError
 }
+
+// inspectContainer returns the inspect data for a container, verifying its
+// filesystem against its image if requested
+func inspectContainer(ctx context.Context, ctr *adapter.Container, size, verify bool) (*libpod.InspectContainerData, error) {
+	data, err := ctr.Inspect(size)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error inspecting container %s", ctr.ID())
+	}
+	if verify {
+		report, err := ctr.Verify(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error verifying container %s", ctr.ID())
+		}
+		data.Verify = report
+	}
+	return data, nil
+}
@@ -13,32 +13,27 @@ import (
 	"github.com/pkg/errors"
 )
 
-// GetRuntimeMigrate gets a libpod runtime that will perform a migration of existing containers
-func GetRuntimeMigrate(ctx context.Context, c *cliconfig.PodmanCommand) (*libpod.Runtime, error) {
-	return getRuntime(ctx, c, false, true, false, true)
-}
-
 // GetRuntimeDisableFDs gets a libpod runtime that will disable sd notify
 func GetRuntimeDisableFDs(ctx context.Context, c *cliconfig.PodmanCommand) (*libpod.Runtime, error) {
-	return getRuntime(ctx, c, false, false, false, false)
+	return getRuntime(ctx, c, false, false, false)
 }
 
 // GetRuntimeRenumber gets a libpod runtime that will perform a lock renumber
 func GetRuntimeRenumber(ctx context.Context, c *cliconfig.PodmanCommand) (*libpod.Runtime, error) {
-	return getRuntime(ctx, c, true, false, false, true)
+	return getRuntime(ctx, c, true, false, true)
 }
 
 // GetRuntime generates a new libpod runtime configured by command line options
 func GetRuntime(ctx context.Context, c *cliconfig.PodmanCommand) (*libpod.Runtime, error) {
-	return getRuntime(ctx, c, false, false, false, true)
+	return getRuntime(ctx, c, false, false, true)
 }
 
 // GetRuntimeNoStore generates a new libpod runtime configured by command line options
 func GetRuntimeNoStore(ctx context.Context, c *cliconfig.PodmanCommand) (*libpod.Runtime, error) {
-	return getRuntime(ctx, c, false, false, true, true)
+	return getRuntime(ctx, c, false, true, true)
 }
 
-func getRuntime(ctx context.Context, c *cliconfig.PodmanCommand, renumber, migrate, noStore, withFDS bool) (*libpod.Runtime, error) {
+func getRuntime(ctx context.Context, c *cliconfig.PodmanCommand, renumber, noStore, withFDS bool) (*libpod.Runtime, error) {
 	options := []libpod.RuntimeOption{}
 	storageOpts := storage.StoreOptions{}
 	storageSet := false
@@ -86,10 +81,6 @@ func getRuntime(ctx context.Context, c *cliconfig.PodmanCommand, renumber, migra
 		storageSet = true
 		storageOpts.GraphDriverOptions = c.GlobalFlags.StorageOpts
 	}
-	if migrate {
-		options = append(options, libpod.WithMigrate())
-	}
-
 	if renumber {
 		options = append(options, libpod.WithRenumber())
 	}
@@ -154,23 +145,6 @@ func getRuntime(ctx context.Context, c *cliconfig.PodmanCommand, renumber, migra
 
 	// TODO flag to set CNI plugins dir?
 
-	// TODO I dont think these belong here?
-	// Will follow up with a different PR to address
-	//
-	// Pod create options
-
-	infraImageFlag := c.Flags().Lookup("infra-image")
-	if infraImageFlag != nil && infraImageFlag.Changed {
-		infraImage, _ := c.Flags().GetString("infra-image")
-		options = append(options, libpod.WithDefaultInfraImage(infraImage))
-	}
-
-	infraCommandFlag := c.Flags().Lookup("infra-command")
-	if infraCommandFlag != nil && infraImageFlag.Changed {
-		infraCommand, _ := c.Flags().GetString("infra-command")
-		options = append(options, libpod.WithDefaultInfraCommand(infraCommand))
-	}
-
 	if withFDS {
 		options = append(options, libpod.WithEnableSDNotify())
 	}
@@ -52,6 +52,7 @@ func init() {
 	flags.BoolVarP(&logsCommand.Follow, "follow", "f", false, "Follow log output.  The default is false")
 	flags.BoolVarP(&logsCommand.Latest, "latest", "l", false, "Act on the latest container podman is aware of")
 	flags.StringVar(&logsCommand.Since, "since", "", "Show logs since TIMESTAMP")
+	flags.StringVar(&logsCommand.Until, "until", "", "Show logs until TIMESTAMP")
 	flags.Uint64Var(&logsCommand.Tail, "tail", 0, "Output the specified number of LINES at the end of the logs.  Defaults to 0, which prints all lines")
 	flags.BoolVarP(&logsCommand.Timestamps, "timestamps", "t", false, "Output the timestamps in the log")
 	markFlagHidden(flags, "details")
@@ -78,13 +79,22 @@ func logsCmd(c *cliconfig.LogsValues) error {
 		}
 		sinceTime = since
 	}
+	untilTime := time.Time{}
+	if c.Flag("until").Changed {
+		until, err := util.ParseInputTime(c.Until)
+		if err != nil {
+			return errors.Wrapf(err, "could not parse time: %q", c.Until)
+		}
+		untilTime = until
+	}
 
 	options := &logs.LogOptions{
 		Details:    c.Details,
 		Follow:     c.Follow,
 		Since:      sinceTime,
+		Until:      untilTime,
 		Tail:       c.Tail,
 		Timestamps: c.Timestamps,
 	}
-	return runtime.Log(c, options)
+	return runtime.Log(getContext(), c, options)
 }
@@ -21,6 +21,7 @@ var networkcheckCommand = cliconfig.PodmanCommand{
 var networkcheckCommands = []*cobra.Command{
 	_networkinspectCommand,
 	_networklistCommand,
+	_networkpruneCommand,
 	_networkrmCommand,
 }
 
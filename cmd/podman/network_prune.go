@@ -0,0 +1,87 @@
+// +build !remoteclient
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/pkg/adapter"
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	networkpruneCommand     cliconfig.NetworkPruneValues
+	networkpruneDescription = `Networks tracked by podman that are not used by any container will be removed, along with their CNI configuration.
+
+  The default network is never removed. The command prompts for confirmation which can be overridden with the --force flag.`
+	_networkpruneCommand = &cobra.Command{
+		Use:   "prune",
+		Args:  noSubArgs,
+		Short: "Remove all unused networks",
+		Long:  networkpruneDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			networkpruneCommand.InputArgs = args
+			networkpruneCommand.GlobalFlags = MainGlobalOpts
+			networkpruneCommand.Remote = remoteclient
+			return networkpruneCmd(&networkpruneCommand)
+		},
+		Example: `podman network prune`,
+	}
+)
+
+func init() {
+	networkpruneCommand.Command = _networkpruneCommand
+	networkpruneCommand.SetHelpTemplate(HelpTemplate())
+	networkpruneCommand.SetUsageTemplate(UsageTemplate())
+	flags := networkpruneCommand.Flags()
+	flags.BoolVarP(&networkpruneCommand.Force, "force", "f", false, "Do not prompt for confirmation")
+}
+
+func networkpruneCmd(c *cliconfig.NetworkPruneValues) error {
+	if rootless.IsRootless() && !remoteclient {
+		return errors.New("network prune is not supported for rootless mode")
+	}
+	runtime, err := adapter.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer runtime.DeferredShutdown(false)
+
+	// Prompt for confirmation if --force is not set
+	if !c.Force {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Println("WARNING! This will remove all networks not used by at least one container.")
+		fmt.Print("Are you sure you want to continue? [y/N] ")
+		ans, err := reader.ReadString('\n')
+		if err != nil {
+			return errors.Wrapf(err, "error reading input")
+		}
+		if strings.ToLower(ans)[0] != 'y' {
+			return nil
+		}
+	}
+
+	pruned, pruneErrors := runtime.NetworkPrune(getContext())
+	for _, name := range pruned {
+		fmt.Println(name)
+	}
+	if len(pruneErrors) == 0 {
+		return nil
+	}
+	// Grab the last error
+	lastError := pruneErrors[len(pruneErrors)-1]
+	// Remove the last error from the error slice
+	pruneErrors = pruneErrors[:len(pruneErrors)-1]
+
+	for _, err := range pruneErrors {
+		logrus.Errorf("%q", err)
+	}
+	return lastError
+}
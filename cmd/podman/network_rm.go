@@ -31,6 +31,8 @@ func init() {
 	networkrmCommand.Command = _networkrmCommand
 	networkrmCommand.SetHelpTemplate(HelpTemplate())
 	networkrmCommand.SetUsageTemplate(UsageTemplate())
+	flags := networkrmCommand.Flags()
+	flags.BoolVarP(&networkrmCommand.Force, "force", "f", false, "Disconnect the containers using the network from it before removing it")
 }
 
 func networkrmCmd(c *cliconfig.NetworkRmValues) error {
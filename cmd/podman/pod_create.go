@@ -46,16 +46,23 @@ func init() {
 	flags.SetInterspersed(false)
 
 	flags.StringVar(&podCreateCommand.CgroupParent, "cgroup-parent", "", "Set parent cgroup for the pod")
+	flags.Float64Var(&podCreateCommand.CPUs, "cpus", 0, "Number of CPUs the containers of the pod may use together")
+	flags.BoolVar(&podCreateCommand.DNSCache, "dns-cache", false, "Run a caching DNS resolver in the pod's network namespace")
+	flags.StringSliceVar(&podCreateCommand.Expose, "expose", []string{}, "Expose a port or a range of ports of the pod without publishing it (default [])")
 	flags.BoolVar(&podCreateCommand.Infra, "infra", true, "Create an infra container associated with the pod to share namespaces with")
 	flags.StringVar(&podCreateCommand.InfraImage, "infra-image", define.DefaultInfraImage, "The image of the infra container to associate with the pod")
 	flags.StringVar(&podCreateCommand.InfraCommand, "infra-command", define.DefaultInfraCommand, "The command to run on the infra container when the pod is started")
 	flags.StringSliceVar(&podCreateCommand.LabelFile, "label-file", []string{}, "Read in a line delimited file of labels")
 	flags.StringSliceVarP(&podCreateCommand.Labels, "label", "l", []string{}, "Set metadata on pod (default [])")
+	flags.StringVarP(&podCreateCommand.Memory, "memory", "m", "", "Memory limit of the pod (format: <number>[<unit>], where unit = b, k, m or g)")
 	flags.StringVarP(&podCreateCommand.Name, "name", "n", "", "Assign a name to the pod")
 	flags.StringVarP(&podCreateCommand.Hostname, "hostname", "", "", "Set a hostname to the pod")
+	flags.Int64Var(&podCreateCommand.PidsLimit, "pids-limit", 0, "Maximum number of processes in the pod")
 	flags.StringVar(&podCreateCommand.PodIDFile, "pod-id-file", "", "Write the pod ID to the file")
 	flags.StringSliceVarP(&podCreateCommand.Publish, "publish", "p", []string{}, "Publish a container's port, or a range of ports, to the host (default [])")
+	flags.StringVar(&podCreateCommand.Restart, "restart", "", "Restart policy to apply to the containers of the pod when one of them exits")
 	flags.StringVar(&podCreateCommand.Share, "share", shared.DefaultKernelNamespaces, "A comma delimited list of kernel namespaces the pod will share")
+	flags.StringArrayVarP(&podCreateCommand.Volume, "volume", "v", []string{}, "Mount a named volume into every container of the pod (default [])")
 
 }
 func podCreateCmd(c *cliconfig.PodCreateValues) error {
@@ -76,6 +83,18 @@ func podCreateCmd(c *cliconfig.PodCreateValues) error {
 		}
 	}
 
+	if len(c.Expose) > 0 && !c.Infra {
+		return errors.Errorf("you must have an infra container to expose ports")
+	}
+
+	if (c.Flag("infra-image").Changed || c.Flag("infra-command").Changed) && !c.Infra {
+		return errors.Errorf("you must have an infra container to set its image or command")
+	}
+
+	if c.DNSCache && !c.Infra {
+		return errors.Errorf("you must have an infra container to use a DNS cache")
+	}
+
 	if !c.Infra && c.Flag("share").Changed && c.Share != "none" && c.Share != "" {
 		return errors.Errorf("You cannot share kernel namespaces on the pod level without an infra container")
 	}
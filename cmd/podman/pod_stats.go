@@ -148,7 +148,7 @@ func podStatsCmd(c *cliconfig.PodStatsValues) error {
 			}
 			newPod := adapter.PodContainerStats{
 				Pod:            p,
-				ContainerStats: newPodStats,
+				ContainerStats: newPodStats.Containers,
 			}
 			newStats = append(newStats, &newPod)
 		}
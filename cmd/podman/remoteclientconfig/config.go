@@ -12,6 +12,16 @@ type RemoteConnection struct {
 	Destination string `toml:"destination"`
 	Username    string `toml:"username"`
 	IsDefault   bool   `toml:"default"`
+	// Port is the SSH port of the remote host, 22 if not set
+	Port int `toml:"port"`
+	// IdentityFile is a private key authenticating the user, tried in
+	// addition to the keys of the SSH agent
+	IdentityFile string `toml:"identity_file"`
+	// KnownHostsFile replaces the known_hosts files of the user to verify
+	// the key of the remote host
+	KnownHostsFile string `toml:"known_hosts_file"`
+	// Socket is the path of the REST API socket on the remote host
+	Socket string `toml:"socket"`
 }
 
 // GetConfigFilePath is a simple helper to export the configuration file's
@@ -18,6 +18,10 @@ default = true
 [connections.bart]
 destination = "foobar.com"
 username = "root"
+port = 2222
+identity_file = "~/.ssh/id_ed25519"
+known_hosts_file = "~/.ssh/podman_known_hosts"
+socket = "/run/podman/podman.sock"
 `
 var noDest = `
 [connections]
@@ -46,8 +50,12 @@ func makeGoodResult() *RemoteConfig {
 		IsDefault:   true,
 	}
 	goodConnections["bart"] = RemoteConnection{
-		Destination: "foobar.com",
-		Username:    "root",
+		Destination:    "foobar.com",
+		Username:       "root",
+		Port:           2222,
+		IdentityFile:   "~/.ssh/id_ed25519",
+		KnownHostsFile: "~/.ssh/podman_known_hosts",
+		Socket:         "/run/podman/podman.sock",
 	}
 	var goodResult = RemoteConfig{
 		Connections: goodConnections,
@@ -135,7 +143,7 @@ func TestRemoteConfig_GetDefault(t *testing.T) {
 		wantErr bool
 	}{
 		// A good toml should return the connection that is marked isDefault
-		{"good", fields{Connections: makeGoodResult().Connections}, &RemoteConnection{"192.168.1.1", "myuser", true}, false},
+		{"good", fields{Connections: makeGoodResult().Connections}, &RemoteConnection{Destination: "192.168.1.1", Username: "myuser", IsDefault: true}, false},
 		// If nothing is marked as isDefault and there is more than one connection, error should occur
 		{"nodefault", fields{Connections: noDefault}, nil, true},
 		// if nothing is marked as isDefault but there is only one connection, the one connection is considered the default
@@ -175,9 +183,9 @@ func TestRemoteConfig_GetRemoteConnection(t *testing.T) {
 		wantErr bool
 	}{
 		// Good connection
-		{"goodhomer", fields{Connections: makeGoodResult().Connections}, args{name: "homer"}, &RemoteConnection{"192.168.1.1", "myuser", true}, false},
+		{"goodhomer", fields{Connections: makeGoodResult().Connections}, args{name: "homer"}, &RemoteConnection{Destination: "192.168.1.1", Username: "myuser", IsDefault: true}, false},
 		// Good connection
-		{"goodbart", fields{Connections: makeGoodResult().Connections}, args{name: "bart"}, &RemoteConnection{"foobar.com", "root", false}, false},
+		{"goodbart", fields{Connections: makeGoodResult().Connections}, args{name: "bart"}, &RemoteConnection{Destination: "foobar.com", Username: "root", Port: 2222, IdentityFile: "~/.ssh/id_ed25519", KnownHostsFile: "~/.ssh/podman_known_hosts", Socket: "/run/podman/podman.sock"}, false},
 		// Getting an unknown connection should result in error
 		{"noexist", fields{Connections: makeGoodResult().Connections}, args{name: "foobar"}, nil, true},
 		// Getting a connection when there are none should result in an error
@@ -199,3 +207,23 @@ func TestRemoteConfig_GetRemoteConnection(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoteConnection_URI(t *testing.T) {
+	tests := []struct {
+		name string
+		conn RemoteConnection
+		want string
+	}{
+		{"host", RemoteConnection{Destination: "foobar.com"}, "ssh://foobar.com"},
+		{"full", RemoteConnection{Destination: "foobar.com", Username: "root", Port: 2222, Socket: "/run/podman/podman.sock"}, "ssh://root@foobar.com:2222/run/podman/podman.sock"},
+		{"ipv6", RemoteConnection{Destination: "fe80::1", Username: "myuser"}, "ssh://myuser@[fe80::1]"},
+		{"ipv6port", RemoteConnection{Destination: "fe80::1", Port: 2222}, "ssh://[fe80::1]:2222"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.conn.URI(); got != tt.want {
+				t.Errorf("RemoteConnection.URI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
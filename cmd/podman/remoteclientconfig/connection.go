@@ -0,0 +1,40 @@
+package remoteclientconfig
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// URI returns the URI of the REST API of the remote host, tunneled through
+// SSH
+func (r *RemoteConnection) URI() string {
+	host := r.Destination
+	if r.Port != 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(r.Port))
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u := url.URL{Scheme: "ssh", Host: host, Path: r.Socket}
+	if r.Username != "" {
+		u.User = url.User(r.Username)
+	}
+	return u.String()
+}
+
+// SSHFlags returns the flags of the ssh client selecting the port, identity
+// and known hosts of the connection
+func (r *RemoteConnection) SSHFlags() []string {
+	var flags []string
+	if r.Port != 0 {
+		flags = append(flags, "-p", strconv.Itoa(r.Port))
+	}
+	if r.IdentityFile != "" {
+		flags = append(flags, "-i", r.IdentityFile)
+	}
+	if r.KnownHostsFile != "" {
+		flags = append(flags, "-o", "UserKnownHostsFile="+r.KnownHostsFile)
+	}
+	return flags
+}
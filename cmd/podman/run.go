@@ -46,6 +46,13 @@ func runCmd(c *cliconfig.RunValues) error {
 		defer span.Finish()
 	}
 
+	if c.IsSet("init-ctr") {
+		return errors.Errorf("init containers are run by starting their pod, --init-ctr can only be used with podman create")
+	}
+	if c.IsSet("schedule") {
+		return errors.Errorf("scheduled containers are started by podman system scheduler, --schedule can only be used with podman create")
+	}
+
 	if err := createInit(&c.PodmanCommand); err != nil {
 		return err
 	}
@@ -195,6 +195,8 @@ func NewBatchContainer(ctr *libpod.Container, opts PsOptions) (PsContainerOutput
 		status = "Paused"
 	case define.ContainerStateCreated.String(), define.ContainerStateConfigured.String():
 		status = "Created"
+	case define.ContainerStateRemoving.String():
+		status = "Removing"
 	default:
 		status = "Error"
 	}
@@ -315,7 +317,7 @@ func generateContainerFilterFuncs(filter, filterValue string, r *libpod.Runtime)
 			return false
 		}, nil
 	case "status":
-		if !util.StringInSlice(filterValue, []string{"created", "running", "paused", "stopped", "exited", "unknown"}) {
+		if !util.StringInSlice(filterValue, []string{"created", "running", "paused", "stopped", "exited", "removing", "unknown"}) {
 			return nil, errors.Errorf("%s is not a valid status", filterValue)
 		}
 		return func(c *libpod.Container) bool {
@@ -418,6 +420,9 @@ func GetPsContainerOutput(r *libpod.Runtime, opts PsOptions, filters []string, m
 			filterFuncs = append(filterFuncs, generatedFunc)
 		}
 	}
+	if canUseContainerSummaries(opts, filters) {
+		return getPsSummaryOutput(r, opts)
+	}
 	if !opts.Latest {
 		// Get all containers.
 		containers, err := r.GetContainers(filterFuncs...)
@@ -446,6 +451,57 @@ func GetPsContainerOutput(r *libpod.Runtime, opts PsOptions, filters []string, m
 	return pss, nil
 }
 
+// canUseContainerSummaries returns whether ps output can be generated from
+// container summaries, which are retrieved without locking every container.
+// Summaries hold only a few fields, so this is only possible when just
+// container IDs are printed, and no filtering or sorting needs other fields.
+func canUseContainerSummaries(opts PsOptions, filters []string) bool {
+	if !opts.Quiet || opts.Latest || opts.Last > 0 || opts.Sync || opts.Size || opts.Namespace || len(filters) > 0 {
+		return false
+	}
+	switch opts.Sort {
+	case "", "created", "id", "image", "names":
+		return true
+	}
+	return false
+}
+
+// getPsSummaryOutput generates ps output from container summaries.
+func getPsSummaryOutput(r *libpod.Runtime, opts PsOptions) ([]PsContainerOutput, error) {
+	summaries, err := r.GetAllContainersSummaries()
+	if err != nil {
+		return nil, err
+	}
+
+	psResults := make([]PsContainerOutput, 0, len(summaries))
+	for _, summary := range summaries {
+		if !opts.All && (summary.IsInfra || summary.State != define.ContainerStateRunning) {
+			continue
+		}
+
+		cid := summary.ID
+		pod := summary.Pod
+		if !opts.NoTrunc {
+			cid = cid[0:cidTruncLength]
+			if len(pod) > podTruncLength {
+				pod = pod[0:podTruncLength]
+			}
+		}
+
+		psResults = append(psResults, PsContainerOutput{
+			ID:        cid,
+			Image:     summary.ImageName,
+			Names:     summary.Name,
+			IsInfra:   summary.IsInfra,
+			State:     summary.State,
+			Pod:       pod,
+			Ports:     portsToString(summary.PortMappings),
+			CreatedAt: summary.CreatedTime,
+		})
+	}
+	return psResults, nil
+}
+
 // PBatch performs batch operations on a container in parallel. It spawns the
 // number of workers relative to the number of parallel operations desired.
 func PBatch(containers []*libpod.Container, workers int, opts PsOptions) []PsContainerOutput {
@@ -684,6 +740,9 @@ func portsToString(ports []ocicni.PortMapping) string {
 		hostIP := v.HostIP
 		if hostIP == "" {
 			hostIP = "0.0.0.0"
+		} else if strings.Contains(hostIP, ":") {
+			// Enclose IPv6 addresses so they can be told apart from the port
+			hostIP = "[" + hostIP + "]"
 		}
 		// If hostPort and containerPort are not same, consider as individual port.
 		if v.ContainerPort != v.HostPort {
@@ -340,10 +340,6 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 		return nil, err
 	}
 
-	if c.String("mac-address") != "" {
-		return nil, errors.Errorf("--mac-address option not currently supported")
-	}
-
 	imageID := ""
 
 	inputCommand = c.InputArgs[1:]
@@ -361,6 +357,20 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 		return nil, errors.Wrapf(err, "invalid value for sysctl")
 	}
 
+	var ingressRate, egressRate uint64
+	if c.String("network-ingress-rate") != "" {
+		ingressRate, err = cc.ParseBandwidth(c.String("network-ingress-rate"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for network-ingress-rate")
+		}
+	}
+	if c.String("network-egress-rate") != "" {
+		egressRate, err = cc.ParseBandwidth(c.String("network-egress-rate"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for network-egress-rate")
+		}
+	}
+
 	if c.String("memory") != "" {
 		memoryLimit, err = units.RAMInBytes(c.String("memory"))
 		if err != nil {
@@ -534,6 +544,17 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 		}
 	}
 
+	var stopEscalation []libpod.StopSignalStep
+	if c.IsSet("stop-escalation") {
+		if c.IsSet("stop-signal") || c.IsSet("stop-timeout") {
+			return nil, errors.Errorf("--stop-escalation cannot be set together with --stop-signal or --stop-timeout")
+		}
+		stopEscalation, err = parseStopEscalation(c.String("stop-escalation"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// ENVIRONMENT VARIABLES
 	env := EnvVariablesFromData(data)
 	if c.Bool("env-host") {
@@ -562,6 +583,13 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 			}
 		}
 	}
+	// Record the systemd unit running the podman CLI, so that podman
+	// auto-update can restart it
+	if unit, ok := os.LookupEnv(libpod.SystemdUnitLabel); ok && len(c.StringSlice("create-command")) > 0 {
+		if _, ok := labels[libpod.SystemdUnitLabel]; !ok {
+			labels[libpod.SystemdUnitLabel] = unit
+		}
+	}
 
 	// ANNOTATIONS
 	annotations := make(map[string]string)
@@ -648,6 +676,14 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 		}
 	}
 
+	// Leave the proxy setting unset unless requested, so the runtime
+	// default applies
+	var httpProxy *bool
+	if c.IsSet("http-proxy") {
+		setProxy := c.Bool("http-proxy")
+		httpProxy = &setProxy
+	}
+
 	var ImageVolumes map[string]struct{}
 	if data != nil && c.String("image-volume") != "ignore" {
 		ImageVolumes = data.Config.Volumes
@@ -690,10 +726,14 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 		Annotations:       annotations,
 		BuiltinImgVolumes: ImageVolumes,
 		ConmonPidFile:     c.String("conmon-pidfile"),
+		ConmonLogLevel:    c.String("conmon-log-level"),
+		ConmonSyslog:      c.Bool("conmon-syslog"),
+		ConmonDebugLog:    c.Bool("conmon-debug-log"),
 		ImageVolumeType:   c.String("image-volume"),
 		CapAdd:            c.StringSlice("cap-add"),
 		CapDrop:           c.StringSlice("cap-drop"),
 		CidFile:           c.String("cidfile"),
+		CreateCommand:     c.StringSlice("create-command"),
 		Cgroupns:          c.String("cgroupns"),
 		CgroupParent:      c.String("cgroup-parent"),
 		Command:           command,
@@ -706,27 +746,31 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 		Entrypoint:        entrypoint,
 		Env:               env,
 		// ExposedPorts:   ports,
-		GroupAdd:    c.StringSlice("group-add"),
-		Hostname:    c.String("hostname"),
-		HostAdd:     c.StringSlice("add-host"),
-		HTTPProxy:   c.Bool("http-proxy"),
-		NoHosts:     c.Bool("no-hosts"),
-		IDMappings:  idmappings,
-		Init:        c.Bool("init"),
-		InitPath:    c.String("init-path"),
-		Image:       imageName,
-		ImageID:     imageID,
-		Interactive: c.Bool("interactive"),
+		GroupAdd:        c.StringSlice("group-add"),
+		HealthOnFailure: c.String("healthcheck-on-failure"),
+		Hostname:        c.String("hostname"),
+		HostAdd:         c.StringSlice("add-host"),
+		HTTPProxy:       httpProxy,
+		NoHosts:         c.Bool("no-hosts"),
+		IDMappings:      idmappings,
+		Init:            c.Bool("init"),
+		InitCtrType:     c.String("init-ctr"),
+		InitPath:        c.String("init-path"),
+		Image:           imageName,
+		ImageID:         imageID,
+		Interactive:     c.Bool("interactive"),
 		// IP6Address:     c.String("ipv6"), // Not implemented yet - needs CNI support for static v6
 		IPAddress: c.String("ip"),
 		Labels:    labels,
 		// LinkLocalIP:    c.StringSlice("link-local-ip"), // Not implemented yet
-		LogDriver:    logDriver,
-		LogDriverOpt: c.StringSlice("log-opt"),
-		MacAddress:   c.String("mac-address"),
-		Name:         c.String("name"),
-		Network:      network,
-		// NetworkAlias:   c.StringSlice("network-alias"), // Not implemented - does this make sense in Podman?
+		LogDriver:      logDriver,
+		LogDriverOpt:   c.StringSlice("log-opt"),
+		MacAddress:     c.String("mac-address"),
+		Name:           c.String("name"),
+		Network:        network,
+		NetworkAlias:   c.StringSlice("network-alias"),
+		NetIngressRate: ingressRate,
+		NetEgressRate:  egressRate,
 		IpcMode:        ipcMode,
 		NetMode:        netMode,
 		UtsMode:        utsMode,
@@ -766,22 +810,25 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 			PidsLimit:         c.Int64("pids-limit"),
 			Ulimit:            c.StringSlice("ulimit"),
 		},
-		RestartPolicy: c.String("restart"),
-		Rm:            c.Bool("rm"),
-		StopSignal:    stopSignal,
-		StopTimeout:   c.Uint("stop-timeout"),
-		Sysctl:        sysctl,
-		Systemd:       systemd,
-		Tmpfs:         c.StringArray("tmpfs"),
-		Tty:           tty,
-		User:          user,
-		UsernsMode:    usernsMode,
-		MountsFlag:    c.StringArray("mount"),
-		Volumes:       c.StringArray("volume"),
-		WorkDir:       workDir,
-		Rootfs:        rootfs,
-		VolumesFrom:   c.StringSlice("volumes-from"),
-		Syslog:        c.Bool("syslog"),
+		RestartPolicy:  c.String("restart"),
+		Rm:             c.Bool("rm"),
+		SdNotifyMode:   c.String("sdnotify"),
+		StartSchedule:  c.String("schedule"),
+		StopSignal:     stopSignal,
+		StopTimeout:    c.Uint("stop-timeout"),
+		StopEscalation: stopEscalation,
+		Sysctl:         sysctl,
+		Systemd:        systemd,
+		Tmpfs:          c.StringArray("tmpfs"),
+		Tty:            tty,
+		User:           user,
+		UsernsMode:     usernsMode,
+		MountsFlag:     c.StringArray("mount"),
+		Volumes:        c.StringArray("volume"),
+		WorkDir:        workDir,
+		Rootfs:         rootfs,
+		VolumesFrom:    c.StringSlice("volumes-from"),
+		Syslog:         c.Bool("syslog"),
 	}
 
 	if config.Privileged {
@@ -802,6 +849,30 @@ func ParseCreateOpts(ctx context.Context, c *GenericCLIResults, runtime *libpod.
 	return config, nil
 }
 
+// parseStopEscalation parses a stop signal escalation chain in the
+// SIGNAL:TIMEOUT,...[,SIGKILL] format
+func parseStopEscalation(chain string) ([]libpod.StopSignalStep, error) {
+	var steps []libpod.StopSignalStep
+	for _, step := range strings.Split(chain, ",") {
+		splitStep := strings.SplitN(step, ":", 2)
+		sig, err := signal.ParseSignal(splitStep[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid signal in --stop-escalation step %q", step)
+		}
+		newStep := libpod.StopSignalStep{Signal: uint(sig)}
+		if len(splitStep) == 2 {
+			newStep.Timeout, err = time.ParseDuration(splitStep[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid timeout in --stop-escalation step %q", step)
+			}
+		} else if sig != syscall.SIGKILL {
+			return nil, errors.Errorf("--stop-escalation step %q must have a timeout", step)
+		}
+		steps = append(steps, newStep)
+	}
+	return steps, nil
+}
+
 func CreateContainerFromCreateConfig(r *libpod.Runtime, createConfig *cc.CreateConfig, ctx context.Context, pod *libpod.Pod) (*libpod.Container, error) {
 	runtimeSpec, options, err := createConfig.MakeContainerConfig(r, pod)
 	if err != nil {
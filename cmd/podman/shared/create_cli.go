@@ -6,8 +6,10 @@ import (
 
 	"github.com/containers/libpod/cmd/podman/shared/parse"
 	"github.com/containers/libpod/pkg/cgroups"
+	"github.com/containers/libpod/pkg/rootless"
 	cc "github.com/containers/libpod/pkg/spec"
 	"github.com/containers/libpod/pkg/sysinfo"
+	"github.com/containers/libpod/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -79,9 +81,20 @@ func verifyContainerResources(config *cc.CreateConfig, update bool) ([]string, e
 	warnings := []string{}
 
 	cgroup2, err := cgroups.IsCgroup2UnifiedMode()
-	if err != nil || cgroup2 {
+	if err != nil {
 		return warnings, err
 	}
+	if cgroup2 {
+		cgroupPath := ""
+		if rootless.IsRootless() {
+			cgroupPath = cgroups.RootlessSystemdCgroup(rootless.GetRootlessUID())
+		}
+		controllers, err := cgroups.AvailableControllers(cgroupPath)
+		if err != nil {
+			return warnings, errors.Wrapf(err, "error retrieving the available cgroup controllers")
+		}
+		return verifyContainerResourcesCgroupV2(config, update, controllers)
+	}
 
 	sysInfo := sysinfo.New(true)
 
@@ -215,3 +228,100 @@ func verifyContainerResources(config *cc.CreateConfig, update bool) ([]string, e
 
 	return warnings, nil
 }
+
+// verifyContainerResourcesCgroupV2 checks the resource limits of a container
+// on a cgroups v2 host. Limits enforced by a controller which is not
+// available, for instance because systemd does not delegate it to rootless
+// users, are discarded, as are the limits cgroups v2 does not support.
+func verifyContainerResourcesCgroupV2(config *cc.CreateConfig, update bool, controllers []string) ([]string, error) {
+	warnings := []string{}
+	missing := func(controller string) bool {
+		return !util.StringInSlice(controller, controllers)
+	}
+
+	// memory controller checks and adjustments
+	if (config.Resources.Memory != 0 || config.Resources.MemoryReservation != 0 || config.Resources.MemorySwap > 0) && missing("memory") {
+		warnings = addWarning(warnings, "The memory cgroup controller is not available. Memory limits discarded.")
+		config.Resources.Memory = 0
+		config.Resources.MemoryReservation = 0
+		config.Resources.MemorySwap = -1
+	}
+	if config.Resources.Memory != 0 && config.Resources.Memory < linuxMinMemory {
+		return warnings, fmt.Errorf("minimum memory limit allowed is 4MB")
+	}
+	if config.Resources.Memory > 0 && config.Resources.MemorySwap > 0 && config.Resources.MemorySwap < config.Resources.Memory {
+		return warnings, fmt.Errorf("minimum memoryswap limit should be larger than memory limit, see usage")
+	}
+	if config.Resources.Memory == 0 && config.Resources.MemorySwap > 0 && !update {
+		return warnings, fmt.Errorf("you should always set the memory limit when using memoryswap limit, see usage")
+	}
+	if config.Resources.MemoryReservation > 0 && config.Resources.MemoryReservation < linuxMinMemory {
+		return warnings, fmt.Errorf("minimum memory reservation allowed is 4MB")
+	}
+	if config.Resources.Memory > 0 && config.Resources.MemoryReservation > 0 && config.Resources.Memory < config.Resources.MemoryReservation {
+		return warnings, fmt.Errorf("minimum memory limit cannot be less than memory reservation limit, see usage")
+	}
+	if config.Resources.MemorySwappiness != -1 {
+		warnings = addWarning(warnings, "Memory swappiness is not supported with cgroups v2. Memory swappiness discarded.")
+		config.Resources.MemorySwappiness = -1
+	}
+	if config.Resources.KernelMemory > 0 {
+		warnings = addWarning(warnings, "Kernel memory limits are not supported with cgroups v2. Limitation discarded.")
+		config.Resources.KernelMemory = 0
+	}
+	if config.Resources.DisableOomKiller {
+		warnings = addWarning(warnings, "Disabling the OOM killer is not supported with cgroups v2. OomKillDisable discarded.")
+		config.Resources.DisableOomKiller = false
+	}
+
+	// pids controller checks and adjustments
+	if config.Resources.PidsLimit != 0 && missing("pids") {
+		warnings = addWarning(warnings, "The pids cgroup controller is not available. PIDs limit discarded.")
+		config.Resources.PidsLimit = 0
+	}
+
+	// cpu controller checks and adjustments
+	if (config.Resources.CPUShares > 0 || config.Resources.CPUPeriod > 0 || config.Resources.CPUQuota > 0 || config.Resources.CPUs > 0) && missing("cpu") {
+		warnings = addWarning(warnings, "The cpu cgroup controller is not available. CPU limits discarded.")
+		config.Resources.CPUShares = 0
+		config.Resources.CPUPeriod = 0
+		config.Resources.CPUQuota = 0
+		config.Resources.CPUs = 0
+	}
+	if config.Resources.CPUPeriod != 0 && (config.Resources.CPUPeriod < 1000 || config.Resources.CPUPeriod > 1000000) {
+		return warnings, fmt.Errorf("CPU cfs period cannot be less than 1ms (i.e. 1000) or larger than 1s (i.e. 1000000)")
+	}
+	if config.Resources.CPUQuota > 0 && config.Resources.CPUQuota < 1000 {
+		return warnings, fmt.Errorf("CPU cfs quota cannot be less than 1ms (i.e. 1000)")
+	}
+	if config.Resources.CPURtPeriod > 0 || config.Resources.CPURtRuntime > 0 {
+		warnings = addWarning(warnings, "Realtime CPU limits are not supported with cgroups v2. Realtime period and runtime discarded.")
+		config.Resources.CPURtPeriod = 0
+		config.Resources.CPURtRuntime = 0
+	}
+
+	// cpuset controller checks and adjustments
+	if (config.Resources.CPUsetCPUs != "" || config.Resources.CPUsetMems != "") && missing("cpuset") {
+		warnings = addWarning(warnings, "The cpuset cgroup controller is not available. CPUset discarded.")
+		config.Resources.CPUsetCPUs = ""
+		config.Resources.CPUsetMems = ""
+	}
+
+	// io controller checks and adjustments
+	if (config.Resources.BlkioWeight > 0 || len(config.Resources.BlkioWeightDevice) > 0 ||
+		len(config.Resources.DeviceReadBps) > 0 || len(config.Resources.DeviceWriteBps) > 0 ||
+		len(config.Resources.DeviceReadIOps) > 0 || len(config.Resources.DeviceWriteIOps) > 0) && missing("io") {
+		warnings = addWarning(warnings, "The io cgroup controller is not available. Block I/O limits discarded.")
+		config.Resources.BlkioWeight = 0
+		config.Resources.BlkioWeightDevice = []string{}
+		config.Resources.DeviceReadBps = []string{}
+		config.Resources.DeviceWriteBps = []string{}
+		config.Resources.DeviceReadIOps = []string{}
+		config.Resources.DeviceWriteIOps = []string{}
+	}
+	if config.Resources.BlkioWeight > 0 && (config.Resources.BlkioWeight < 10 || config.Resources.BlkioWeight > 1000) {
+		return warnings, fmt.Errorf("range of blkio weight is from 10 to 1000")
+	}
+
+	return warnings, nil
+}
@@ -3,8 +3,12 @@ package shared
 import (
 	"io/ioutil"
 	"os"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/containers/libpod/libpod"
+	cc "github.com/containers/libpod/pkg/spec"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -68,3 +72,46 @@ func TestGetAllLabelsFile(t *testing.T) {
 	result, _ := GetAllLabels(fileLabels, Var1)
 	assert.Equal(t, len(result), 3)
 }
+
+func TestParseStopEscalation(t *testing.T) {
+	steps, err := parseStopEscalation("SIGTERM:10s,INT:500ms,SIGKILL")
+	assert.NoError(t, err)
+	assert.Equal(t, []libpod.StopSignalStep{
+		{Signal: uint(syscall.SIGTERM), Timeout: 10 * time.Second},
+		{Signal: uint(syscall.SIGINT), Timeout: 500 * time.Millisecond},
+		{Signal: uint(syscall.SIGKILL)},
+	}, steps)
+}
+
+func TestParseStopEscalationBadStep(t *testing.T) {
+	for _, chain := range []string{"SIGTERM", "SIGFOO:10s", "SIGTERM:ten"} {
+		_, err := parseStopEscalation(chain)
+		assert.Error(t, err, chain)
+	}
+}
+
+func TestVerifyContainerResourcesCgroupV2(t *testing.T) {
+	config := &cc.CreateConfig{
+		Resources: cc.CreateResourceConfig{
+			Memory:           64 * 1024 * 1024,
+			MemorySwap:       -1,
+			MemorySwappiness: 60,
+			CPUShares:        512,
+			PidsLimit:        100,
+			BlkioWeight:      500,
+		},
+	}
+	// systemd delegates the memory and pids controllers to users by default
+	warnings, err := verifyContainerResourcesCgroupV2(config, false, []string{"memory", "pids"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(warnings))
+	assert.Equal(t, int64(64*1024*1024), config.Resources.Memory)
+	assert.Equal(t, int64(100), config.Resources.PidsLimit)
+	assert.Equal(t, -1, config.Resources.MemorySwappiness)
+	assert.Equal(t, uint64(0), config.Resources.CPUShares)
+	assert.Equal(t, uint16(0), config.Resources.BlkioWeight)
+
+	config.Resources.MemorySwap = 32 * 1024 * 1024
+	_, err = verifyContainerResourcesCgroupV2(config, false, []string{"memory", "pids"})
+	assert.Error(t, err)
+}
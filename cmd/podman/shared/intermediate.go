@@ -1,6 +1,8 @@
 package shared
 
 import (
+	"os"
+
 	"github.com/containers/libpod/cmd/podman/cliconfig"
 	"github.com/sirupsen/logrus"
 )
@@ -372,7 +374,10 @@ func NewIntermediateLayer(c *cliconfig.PodmanCommand, remote bool) GenericCLIRes
 	m["cap-drop"] = newCRStringSlice(c, "cap-drop")
 	m["cgroup-parent"] = newCRString(c, "cgroup-parent")
 	m["cidfile"] = newCRString(c, "cidfile")
+	m["conmon-debug-log"] = newCRBool(c, "conmon-debug-log")
+	m["conmon-log-level"] = newCRString(c, "conmon-log-level")
 	m["conmon-pidfile"] = newCRString(c, "conmon-pidfile")
+	m["conmon-syslog"] = newCRBool(c, "conmon-syslog")
 	m["cpu-period"] = newCRUint64(c, "cpu-period")
 	m["cpu-quota"] = newCRInt64(c, "cpu-quota")
 	m["cpu-rt-period"] = newCRUint64(c, "cpu-rt-period")
@@ -423,6 +428,9 @@ func NewIntermediateLayer(c *cliconfig.PodmanCommand, remote bool) GenericCLIRes
 	m["name"] = newCRString(c, "name")
 	m["net"] = newCRString(c, "net")
 	m["network"] = newCRString(c, "network")
+	m["network-alias"] = newCRStringSlice(c, "network-alias")
+	m["network-egress-rate"] = newCRString(c, "network-egress-rate")
+	m["network-ingress-rate"] = newCRString(c, "network-ingress-rate")
 	m["no-hosts"] = newCRBool(c, "no-hosts")
 	m["oom-kill-disable"] = newCRBool(c, "oom-kill-disable")
 	m["oom-score-adj"] = newCRInt(c, "oom-score-adj")
@@ -465,9 +473,20 @@ func NewIntermediateLayer(c *cliconfig.PodmanCommand, remote bool) GenericCLIRes
 		m["cgroupns"] = newCRString(c, "cgroupns")
 		m["env-host"] = newCRBool(c, "env-host")
 		m["http-proxy"] = newCRBool(c, "http-proxy")
+		m["healthcheck-on-failure"] = newCRString(c, "health-on-failure")
+		m["init-ctr"] = newCRString(c, "init-ctr")
+		m["schedule"] = newCRString(c, "schedule")
+		m["sdnotify"] = newCRString(c, "sdnotify")
+		m["stop-escalation"] = newCRString(c, "stop-escalation")
 		m["trace"] = newCRBool(c, "trace")
 		m["syslog"] = newCRBool(c, "syslog")
 	}
 
+	// The command line of the podman CLI creating the container, absent
+	// when created through an API
+	if !remote {
+		m["create-command"] = CRStringSlice{Val: os.Args, createResult: createResult{Flag: "create-command", Changed: true}}
+	}
+
 	return GenericCLIResults{m, c.InputArgs}
 }
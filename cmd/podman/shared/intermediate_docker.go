@@ -0,0 +1,316 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/docker/docker/api/types/blkiodev"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/pkg/errors"
+)
+
+// dockerCreateResults builds the results of the create command for a
+// container created through the Docker API. Flags which are not set keep
+// the defaults of the create command.
+type dockerCreateResults map[string]GenericCLIResult
+
+func (m dockerCreateResults) setString(flag, value string) {
+	if value != "" {
+		m[flag] = CRString{Val: value, createResult: createResult{Flag: flag, Changed: true}}
+	}
+}
+
+func (m dockerCreateResults) setStringSlice(flag string, value []string) {
+	if len(value) > 0 {
+		m[flag] = CRStringSlice{Val: value, createResult: createResult{Flag: flag, Changed: true}}
+	}
+}
+
+func (m dockerCreateResults) setStringArray(flag string, value []string) {
+	if len(value) > 0 {
+		m[flag] = CRStringArray{Val: value, createResult: createResult{Flag: flag, Changed: true}}
+	}
+}
+
+func (m dockerCreateResults) setBool(flag string, value bool) {
+	m[flag] = CRBool{Val: value, createResult: createResult{Flag: flag, Changed: value}}
+}
+
+func (m dockerCreateResults) setInt64(flag string, value int64) {
+	if value != 0 {
+		m[flag] = CRInt64{Val: value, createResult: createResult{Flag: flag, Changed: true}}
+	}
+}
+
+func (m dockerCreateResults) setUint64(flag string, value int64) {
+	if value > 0 {
+		m[flag] = CRUint64{Val: uint64(value), createResult: createResult{Flag: flag, Changed: true}}
+	}
+}
+
+func (m dockerCreateResults) setBytes(flag string, value int64) {
+	if value != 0 {
+		m.setString(flag, strconv.FormatInt(value, 10))
+	}
+}
+
+// keyValues returns the entries of a map in the KEY=VALUE form, sorted
+func keyValues(values map[string]string, separator string) []string {
+	var result []string
+	for key, value := range values {
+		result = append(result, key+separator+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// DockerCreateToGeneric converts the configuration of a container created
+// through the Docker-compatible API into the intermediate layer used to create
+// containers, so that the container is created as `podman create` would.
+// The image is never pulled, as Docker clients pull missing images themselves.
+func DockerCreateToGeneric(name string, config *container.Config, hostConfig *container.HostConfig, networking *network.NetworkingConfig) (GenericCLIResults, error) {
+	if config == nil || config.Image == "" {
+		return GenericCLIResults{}, errors.Errorf("an image is required to create a container")
+	}
+	if hostConfig == nil {
+		hostConfig = &container.HostConfig{}
+	}
+
+	netModeDefault := "bridge"
+	if rootless.IsRootless() {
+		netModeDefault = "slirp4netns"
+	}
+	m := dockerCreateResults{
+		"detach":                   CRBool{Val: true, createResult: createResult{Flag: "detach"}},
+		"healthcheck-interval":     CRString{Val: cliconfig.DefaultHealthCheckInterval, createResult: createResult{Flag: "healthcheck-interval"}},
+		"healthcheck-retries":      CRUint{Val: cliconfig.DefaultHealthCheckRetries, createResult: createResult{Flag: "healthcheck-retries"}},
+		"healthcheck-start-period": CRString{Val: cliconfig.DefaultHealthCheckStartPeriod, createResult: createResult{Flag: "healthcheck-start-period"}},
+		"healthcheck-timeout":      CRString{Val: cliconfig.DefaultHealthCheckTimeout, createResult: createResult{Flag: "healthcheck-timeout"}},
+		"image-volume":             CRString{Val: cliconfig.DefaultImageVolume, createResult: createResult{Flag: "image-volume"}},
+		"memory-swappiness":        CRInt64{Val: -1, createResult: createResult{Flag: "memory-swappiness"}},
+		"network":                  CRString{Val: netModeDefault, createResult: createResult{Flag: "network"}},
+		"pull":                     CRString{Val: "never", createResult: createResult{Flag: "pull"}},
+		"quiet":                    CRBool{Val: true, createResult: createResult{Flag: "quiet"}},
+		"read-only-tmpfs":          CRBool{Val: true, createResult: createResult{Flag: "read-only-tmpfs"}},
+		"shm-size":                 CRString{Val: cliconfig.DefaultShmSize, createResult: createResult{Flag: "shm-size"}},
+		"systemd":                  CRBool{Val: cliconfig.DefaultSystemD, createResult: createResult{Flag: "systemd"}},
+	}
+
+	// Container configuration
+	m.setString("name", strings.TrimPrefix(name, "/"))
+	m.setString("hostname", config.Hostname)
+	m.setString("user", config.User)
+	m.setString("workdir", config.WorkingDir)
+	m.setString("stop-signal", config.StopSignal)
+	m.setStringArray("env", config.Env)
+	m.setStringArray("label", keyValues(config.Labels, "="))
+	m.setBool("tty", config.Tty)
+	m.setBool("interactive", config.OpenStdin)
+	if config.StopTimeout != nil {
+		m["stop-timeout"] = CRUint{Val: uint(*config.StopTimeout), createResult: createResult{Flag: "stop-timeout", Changed: true}}
+	}
+	if config.Entrypoint != nil {
+		// An empty entrypoint resets the entrypoint of the image
+		var entrypoint []byte
+		if len(config.Entrypoint) > 0 {
+			var err error
+			if entrypoint, err = json.Marshal([]string(config.Entrypoint)); err != nil {
+				return GenericCLIResults{}, err
+			}
+		}
+		m["entrypoint"] = CRString{Val: string(entrypoint), createResult: createResult{Flag: "entrypoint", Changed: true}}
+	}
+	var expose []string
+	for port := range config.ExposedPorts {
+		expose = append(expose, string(port))
+	}
+	sort.Strings(expose)
+	m.setStringSlice("expose", expose)
+	if hc := config.Healthcheck; hc != nil && len(hc.Test) > 0 {
+		if hc.Test[0] == "NONE" {
+			m.setString("healthcheck-command", "none")
+		} else {
+			test, err := json.Marshal(hc.Test)
+			if err != nil {
+				return GenericCLIResults{}, err
+			}
+			m.setString("healthcheck-command", string(test))
+			if hc.Interval != 0 {
+				m.setString("healthcheck-interval", hc.Interval.String())
+			}
+			if hc.Timeout != 0 {
+				m.setString("healthcheck-timeout", hc.Timeout.String())
+			}
+			if hc.StartPeriod != 0 {
+				m.setString("healthcheck-start-period", hc.StartPeriod.String())
+			}
+			if hc.Retries > 0 {
+				m["healthcheck-retries"] = CRUint{Val: uint(hc.Retries), createResult: createResult{Flag: "healthcheck-retries", Changed: true}}
+			}
+		}
+	}
+
+	// Host configuration
+	m.setStringArray("volume", hostConfig.Binds)
+	var mounts []string
+	for _, mnt := range hostConfig.Mounts {
+		mounts = append(mounts, dockerMountToFlag(mnt))
+	}
+	m.setStringArray("mount", mounts)
+	m.setStringSlice("volumes-from", hostConfig.VolumesFrom)
+	var publish []string
+	for port, bindings := range hostConfig.PortBindings {
+		for _, binding := range bindings {
+			publish = append(publish, fmt.Sprintf("%s:%s:%s", binding.HostIP, binding.HostPort, port))
+		}
+	}
+	sort.Strings(publish)
+	m.setStringSlice("publish", publish)
+	m.setBool("publish-all", hostConfig.PublishAllPorts)
+	m.setBool("privileged", hostConfig.Privileged)
+	m.setBool("read-only", hostConfig.ReadonlyRootfs)
+	m.setBool("rm", hostConfig.AutoRemove)
+	m.setStringSlice("cap-add", hostConfig.CapAdd)
+	m.setStringSlice("cap-drop", hostConfig.CapDrop)
+	m.setStringSlice("dns", hostConfig.DNS)
+	m.setStringSlice("dns-opt", hostConfig.DNSOptions)
+	m.setStringSlice("dns-search", hostConfig.DNSSearch)
+	m.setStringSlice("add-host", hostConfig.ExtraHosts)
+	m.setStringSlice("group-add", hostConfig.GroupAdd)
+	m.setStringArray("security-opt", hostConfig.SecurityOpt)
+	m.setStringSlice("sysctl", keyValues(hostConfig.Sysctls, "="))
+	m.setStringArray("tmpfs", keyValues(hostConfig.Tmpfs, ":"))
+	m.setString("ipc", string(hostConfig.IpcMode))
+	m.setString("pid", string(hostConfig.PidMode))
+	m.setString("uts", string(hostConfig.UTSMode))
+	m.setString("userns", string(hostConfig.UsernsMode))
+	m.setString("cgroup-parent", hostConfig.CgroupParent)
+	if hostConfig.Init != nil {
+		m.setBool("init", *hostConfig.Init)
+	}
+	switch policy := hostConfig.RestartPolicy; {
+	case policy.Name == "on-failure" && policy.MaximumRetryCount > 0:
+		m.setString("restart", fmt.Sprintf("on-failure:%d", policy.MaximumRetryCount))
+	default:
+		m.setString("restart", policy.Name)
+	}
+	switch driver := hostConfig.LogConfig.Type; driver {
+	case "", "json-file":
+		// The default podman log driver keeps the logs in a file too
+	default:
+		m.setString("log-driver", driver)
+	}
+	m.setStringSlice("log-opt", keyValues(hostConfig.LogConfig.Config, "="))
+	var ulimits []string
+	for _, ulimit := range hostConfig.Ulimits {
+		ulimits = append(ulimits, ulimit.String())
+	}
+	m.setStringSlice("ulimit", ulimits)
+	var devices []string
+	for _, device := range hostConfig.Devices {
+		devices = append(devices, strings.TrimSuffix(fmt.Sprintf("%s:%s:%s", device.PathOnHost, device.PathInContainer, device.CgroupPermissions), ":"))
+	}
+	m.setStringSlice("device", devices)
+
+	// Networking
+	networkMode := string(hostConfig.NetworkMode)
+	if networkMode != "" && networkMode != "default" {
+		m.setString("network", networkMode)
+	}
+	if networking != nil {
+		if endpoint, ok := networking.EndpointsConfig[networkMode]; ok && endpoint != nil {
+			m.setStringSlice("network-alias", endpoint.Aliases)
+			if endpoint.IPAMConfig != nil {
+				m.setString("ip", endpoint.IPAMConfig.IPv4Address)
+			}
+		}
+	}
+	m.setString("mac-address", config.MacAddress)
+
+	// Resources
+	m.setBytes("memory", hostConfig.Memory)
+	m.setBytes("memory-reservation", hostConfig.MemoryReservation)
+	m.setBytes("memory-swap", hostConfig.MemorySwap)
+	m.setBytes("kernel-memory", hostConfig.KernelMemory)
+	if hostConfig.ShmSize > 0 {
+		m.setBytes("shm-size", hostConfig.ShmSize)
+	}
+	if hostConfig.MemorySwappiness != nil {
+		m["memory-swappiness"] = CRInt64{Val: *hostConfig.MemorySwappiness, createResult: createResult{Flag: "memory-swappiness", Changed: true}}
+	}
+	if hostConfig.OomKillDisable != nil {
+		m.setBool("oom-kill-disable", *hostConfig.OomKillDisable)
+	}
+	if hostConfig.OomScoreAdj != 0 {
+		m["oom-score-adj"] = CRInt{Val: hostConfig.OomScoreAdj, createResult: createResult{Flag: "oom-score-adj", Changed: true}}
+	}
+	if hostConfig.PidsLimit != nil {
+		m.setInt64("pids-limit", *hostConfig.PidsLimit)
+	}
+	m.setUint64("cpu-shares", hostConfig.CPUShares)
+	m.setUint64("cpu-period", hostConfig.CPUPeriod)
+	m.setInt64("cpu-quota", hostConfig.CPUQuota)
+	m.setUint64("cpu-rt-period", hostConfig.CPURealtimePeriod)
+	m.setInt64("cpu-rt-runtime", hostConfig.CPURealtimeRuntime)
+	if hostConfig.NanoCPUs > 0 {
+		m["cpus"] = CRFloat64{Val: float64(hostConfig.NanoCPUs) / 1e9, createResult: createResult{Flag: "cpus", Changed: true}}
+	}
+	m.setString("cpuset-cpus", hostConfig.CpusetCpus)
+	m.setString("cpuset-mems", hostConfig.CpusetMems)
+	if hostConfig.BlkioWeight > 0 {
+		m.setString("blkio-weight", strconv.Itoa(int(hostConfig.BlkioWeight)))
+	}
+	var weightDevices []string
+	for _, device := range hostConfig.BlkioWeightDevice {
+		weightDevices = append(weightDevices, fmt.Sprintf("%s:%d", device.Path, device.Weight))
+	}
+	m.setStringSlice("blkio-weight-device", weightDevices)
+	m.setStringSlice("device-read-bps", throttleDeviceFlags(hostConfig.BlkioDeviceReadBps))
+	m.setStringSlice("device-write-bps", throttleDeviceFlags(hostConfig.BlkioDeviceWriteBps))
+	m.setStringSlice("device-read-iops", throttleDeviceFlags(hostConfig.BlkioDeviceReadIOps))
+	m.setStringSlice("device-write-iops", throttleDeviceFlags(hostConfig.BlkioDeviceWriteIOps))
+
+	return GenericCLIResults{results: m, InputArgs: append([]string{config.Image}, config.Cmd...)}, nil
+}
+
+// throttleDeviceFlags converts throttled devices of the Docker API into the
+// format of the --device-{read,write}-{bps,iops} flags
+func throttleDeviceFlags(devices []*blkiodev.ThrottleDevice) []string {
+	var flags []string
+	for _, device := range devices {
+		flags = append(flags, fmt.Sprintf("%s:%d", device.Path, device.Rate))
+	}
+	return flags
+}
+
+// dockerMountToFlag converts a mount of the Docker API into the format of the
+// --mount flag
+func dockerMountToFlag(mnt mount.Mount) string {
+	options := []string{"type=" + string(mnt.Type)}
+	if mnt.Source != "" {
+		options = append(options, "src="+mnt.Source)
+	}
+	options = append(options, "dst="+mnt.Target)
+	if mnt.ReadOnly {
+		options = append(options, "ro=true")
+	}
+	if mnt.BindOptions != nil && mnt.BindOptions.Propagation != "" {
+		options = append(options, "bind-propagation="+string(mnt.BindOptions.Propagation))
+	}
+	if mnt.TmpfsOptions != nil {
+		if mnt.TmpfsOptions.SizeBytes > 0 {
+			options = append(options, fmt.Sprintf("tmpfs-size=%d", mnt.TmpfsOptions.SizeBytes))
+		}
+		if mnt.TmpfsOptions.Mode != 0 {
+			options = append(options, fmt.Sprintf("tmpfs-mode=%o", mnt.TmpfsOptions.Mode))
+		}
+	}
+	return strings.Join(options, ",")
+}
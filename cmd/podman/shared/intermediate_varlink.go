@@ -129,6 +129,9 @@ func (g GenericCLIResults) MakeVarlink() iopodman.Create {
 		Name:                   StringToPtr(g.Find("name")),
 		Net:                    StringToPtr(g.Find("net")),
 		Network:                StringToPtr(g.Find("network")),
+		NetworkAlias:           StringSliceToPtr(g.Find("network-alias")),
+		NetworkEgressRate:      StringToPtr(g.Find("network-egress-rate")),
+		NetworkIngressRate:     StringToPtr(g.Find("network-ingress-rate")),
 		OomKillDisable:         BoolToPtr(g.Find("oom-kill-disable")),
 		OomScoreAdj:            AnyIntToInt64Ptr(g.Find("oom-score-adj")),
 		Pid:                    StringToPtr(g.Find("pid")),
@@ -385,6 +388,9 @@ func VarlinkCreateToGeneric(opts iopodman.Create) GenericCLIResults {
 	m["name"] = stringFromVarlink(opts.Name, "name", nil)
 	m["net"] = stringFromVarlink(opts.Net, "net", &netModeDefault)
 	m["network"] = stringFromVarlink(opts.Network, "network", &netModeDefault)
+	m["network-alias"] = stringSliceFromVarlink(opts.NetworkAlias, "network-alias", nil)
+	m["network-egress-rate"] = stringFromVarlink(opts.NetworkEgressRate, "network-egress-rate", nil)
+	m["network-ingress-rate"] = stringFromVarlink(opts.NetworkIngressRate, "network-ingress-rate", nil)
 	m["no-hosts"] = boolFromVarlink(opts.NoHosts, "no-hosts", false)
 	m["oom-kill-disable"] = boolFromVarlink(opts.OomKillDisable, "oon-kill-disable", false)
 	m["oom-score-adj"] = intFromVarlink(opts.OomScoreAdj, "oom-score-adj", nil)
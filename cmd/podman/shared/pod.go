@@ -1,8 +1,11 @@
 package shared
 
 import (
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/containers/buildah/pkg/parse"
 	"github.com/containers/libpod/libpod"
 	"github.com/containers/libpod/libpod/define"
 	"github.com/cri-o/ocicni/pkg/ocicni"
@@ -138,4 +141,56 @@ func CreatePortBindings(ports []string) ([]ocicni.PortMapping, error) {
 	return portBindings, nil
 }
 
+// CreateExposedPorts converts ports given as <port>[/<proto>] or
+// <startport-endport>[/<proto>] to port mappings with no host port
+func CreateExposedPorts(ports []string) ([]ocicni.PortMapping, error) {
+	var exposed []ocicni.PortMapping
+	for _, expose := range ports {
+		proto, port := nat.SplitProtoPort(expose)
+		start, end, err := nat.ParsePortRange(port)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid format for exposed port %s", expose)
+		}
+		for i := start; i <= end; i++ {
+			exposed = append(exposed, ocicni.PortMapping{
+				ContainerPort: int32(i),
+				Protocol:      proto,
+			})
+		}
+	}
+	return exposed, nil
+}
+
 var DefaultKernelNamespaces = "cgroup,ipc,net,uts"
+
+// CreatePodVolumes converts volumes given as name:ctr-dir[:option] to the
+// named volumes of a pod. Bind mounts of host directories are not supported.
+func CreatePodVolumes(volumes []string) ([]*libpod.ContainerNamedVolume, error) {
+	var podVolumes []*libpod.ContainerNamedVolume
+	for _, vol := range volumes {
+		splitVol := strings.Split(vol, ":")
+		if len(splitVol) < 2 || len(splitVol) > 3 {
+			return nil, errors.Errorf("incorrect pod volume format %q, should be name:ctr-dir[:option]", vol)
+		}
+		name, dest := splitVol[0], splitVol[1]
+		if name == "" || strings.HasPrefix(name, "/") || strings.HasPrefix(name, ".") {
+			return nil, errors.Errorf("pod volume %q must be a named volume", vol)
+		}
+		if err := parse.ValidateVolumeCtrDir(dest); err != nil {
+			return nil, err
+		}
+		var options []string
+		if len(splitVol) > 2 {
+			var err error
+			if options, err = parse.ValidateVolumeOpts(strings.Split(splitVol[2], ",")); err != nil {
+				return nil, err
+			}
+		}
+		podVolumes = append(podVolumes, &libpod.ContainerNamedVolume{
+			Name:    name,
+			Dest:    filepath.Clean(dest),
+			Options: options,
+		})
+	}
+	return podVolumes, nil
+}
@@ -0,0 +1,61 @@
+package shared
+
+import (
+	"strings"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// GenerateVolumePruneFilters parses filters of the form filter=value into
+// functions selecting the volumes to prune. The supported filters are label,
+// matching volumes with a label key or key=value, and until, matching volumes
+// created before a timestamp or a duration ago.
+func GenerateVolumePruneFilters(filters []string) ([]libpod.VolumeFilter, error) {
+	filterFuncs := make([]libpod.VolumeFilter, 0, len(filters))
+	for _, f := range filters {
+		filterSplit := strings.SplitN(f, "=", 2)
+		if len(filterSplit) < 2 {
+			return nil, errors.Errorf("filter input must be in the form of filter=value: %s is invalid", f)
+		}
+		filterFunc, err := generateVolumePruneFilterFunc(filterSplit[0], filterSplit[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid filter")
+		}
+		filterFuncs = append(filterFuncs, filterFunc)
+	}
+	return filterFuncs, nil
+}
+
+func generateVolumePruneFilterFunc(filter, filterValue string) (libpod.VolumeFilter, error) {
+	switch filter {
+	case "label":
+		return func(v *libpod.Volume) bool {
+			return MatchesKeyValueFilter(v.Labels(), filterValue)
+		}, nil
+	case "until":
+		until, err := util.ParseInputTime(filterValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid until filter %q", filterValue)
+		}
+		// Volumes created by older versions of libpod do not have a
+		// creation time, and are considered old enough
+		return func(v *libpod.Volume) bool {
+			return v.CreatedTime().Before(until)
+		}, nil
+	}
+	return nil, errors.Errorf("%s is an invalid filter", filter)
+}
+
+// MatchesKeyValueFilter returns whether a filter value of the form key or
+// key=value matches the given labels or options: the key must be present and,
+// if a value is given, have that value.
+func MatchesKeyValueFilter(pairs map[string]string, filterValue string) bool {
+	filterArray := strings.SplitN(filterValue, "=", 2)
+	value, ok := pairs[filterArray[0]]
+	if !ok {
+		return false
+	}
+	return len(filterArray) == 1 || filterArray[1] == "" || value == filterArray[1]
+}
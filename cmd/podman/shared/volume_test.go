@@ -0,0 +1,18 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesKeyValueFilter(t *testing.T) {
+	labels := map[string]string{"app": "web", "empty": ""}
+
+	assert.True(t, MatchesKeyValueFilter(labels, "app"))
+	assert.True(t, MatchesKeyValueFilter(labels, "app=web"))
+	assert.True(t, MatchesKeyValueFilter(labels, "empty"))
+	assert.False(t, MatchesKeyValueFilter(labels, "app=db"))
+	assert.False(t, MatchesKeyValueFilter(labels, "tier"))
+	assert.False(t, MatchesKeyValueFilter(nil, "app"))
+}
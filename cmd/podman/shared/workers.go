@@ -119,6 +119,8 @@ func DefaultPoolSize(name string) int {
 	case "rm":
 		fallthrough
 	case "unpause":
+		fallthrough
+	case "update":
 		if numCpus <= 3 {
 			return numCpus * 3
 		}
@@ -127,6 +129,8 @@ func DefaultPoolSize(name string) int {
 		return 8
 	case "restart":
 		return numCpus * 2
+	case "stats":
+		return numCpus
 	case "stop":
 		if numCpus <= 2 {
 			return 4
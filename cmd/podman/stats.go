@@ -10,12 +10,14 @@ import (
 	"github.com/containers/buildah/pkg/formats"
 	"github.com/containers/libpod/cmd/podman/cliconfig"
 	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/containers/libpod/cmd/podman/shared"
 	"github.com/containers/libpod/libpod"
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/pkg/cgroups"
 	"github.com/containers/libpod/pkg/rootless"
 	"github.com/docker/go-units"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -107,6 +109,18 @@ func statsCmd(c *cliconfig.StatsValues) error {
 		times = 1
 	}
 
+	format := genStatsFormat(c.Format)
+
+	// The stats of all running containers are gathered at once
+	if len(c.InputArgs) == 0 && !latest {
+		maxWorkers := shared.DefaultPoolSize("stats")
+		if c.GlobalIsSet("max-workers") {
+			maxWorkers = c.GlobalFlags.MaxWorks
+		}
+		logrus.Debugf("Setting maximum stats workers to %d", maxWorkers)
+		return allStats(c, runtime, format, times, maxWorkers)
+	}
+
 	var ctrs []*libpod.Container
 
 	containerFunc := runtime.GetRunningContainers
@@ -146,8 +160,6 @@ func statsCmd(c *cliconfig.StatsValues) error {
 		containerStats[ctr.ID()] = initialStats
 	}
 
-	format := genStatsFormat(c.Format)
-
 	step := 1
 	if times == -1 {
 		times = 1
@@ -193,6 +205,57 @@ func statsCmd(c *cliconfig.StatsValues) error {
 	return nil
 }
 
+// allStats reports the stats of all running containers, gathering the stats of
+// every container at once
+func allStats(c *cliconfig.StatsValues, runtime *libpod.Runtime, format string, times, maxWorkers int) error {
+	containerStats := map[string]*libpod.ContainerStats{}
+	update := func() ([]*libpod.ContainerStats, error) {
+		stats, errs, err := runtime.GetAllContainerStats(containerStats, maxWorkers)
+		if err != nil {
+			return nil, err
+		}
+		for id, err := range errs {
+			// skip dealing with a container that stopped or is gone
+			cause := errors.Cause(err)
+			if cause == define.ErrCtrRemoved || cause == define.ErrNoSuchCtr || cause == define.ErrCtrStateInvalid {
+				continue
+			}
+			return nil, errors.Wrapf(err, "error getting stats of container %s", id)
+		}
+		containerStats = make(map[string]*libpod.ContainerStats, len(stats))
+		for _, s := range stats {
+			containerStats[s.ContainerID] = s
+		}
+		return stats, nil
+	}
+
+	// The first measurement only serves as the base of the CPU usage
+	if _, err := update(); err != nil {
+		return err
+	}
+	step := 1
+	if times == -1 {
+		times = 1
+		step = 0
+	}
+	for i := 0; i < times; i += step {
+		reportStats, err := update()
+		if err != nil {
+			return err
+		}
+		if strings.ToLower(format) != formats.JSONString && !c.NoReset {
+			tm.Clear()
+			tm.MoveCursor(1, 1)
+			tm.Flush()
+		}
+		if err := outputStats(reportStats, format); err != nil {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+	return nil
+}
+
 func outputStats(stats []*libpod.ContainerStats, format string) error {
 	var out formats.Writer
 	var outputStats []statsOutputParams
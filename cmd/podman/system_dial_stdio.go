@@ -0,0 +1,84 @@
+// +build !remoteclient
+
+package main
+
+import (
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dialStdioCommand     cliconfig.SystemDialStdioValues
+	dialStdioDescription = `
+        podman system dial-stdio
+
+        Relay the standard input and output to the REST API served by podman system service, so that remote clients can reach it through SSH.
+`
+
+	_dialStdioCommand = &cobra.Command{
+		Use:   "dial-stdio [URI]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Relay the standard streams to the REST API",
+		Long:  dialStdioDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dialStdioCommand.InputArgs = args
+			dialStdioCommand.GlobalFlags = MainGlobalOpts
+			dialStdioCommand.Remote = remoteclient
+			return dialStdioCmd(&dialStdioCommand)
+		},
+		Example: `podman system dial-stdio
+  ssh user@host podman system dial-stdio unix:///run/podman/podman.sock`,
+	}
+)
+
+func init() {
+	dialStdioCommand.Command = _dialStdioCommand
+	dialStdioCommand.SetHelpTemplate(HelpTemplate())
+	dialStdioCommand.SetUsageTemplate(UsageTemplate())
+}
+
+func dialStdioCmd(c *cliconfig.SystemDialStdioValues) error {
+	address, err := defaultServiceAddress()
+	if err != nil {
+		return err
+	}
+	if len(c.InputArgs) > 0 {
+		address = c.InputArgs[0]
+	}
+	network := "tcp"
+	if split := strings.SplitN(address, "://", 2); len(split) == 2 {
+		network, address = split[0], split[1]
+	}
+	if network != "unix" && network != "tcp" {
+		return errors.Errorf("unsupported network %q for API service, expected tcp or unix", network)
+	}
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return errors.Wrapf(err, "error connecting to API service on %s", address)
+	}
+	defer conn.Close()
+
+	// The input ending only closes the write side of the connection, the
+	// output of the service is relayed until it closes it
+	go func() {
+		if _, err := io.Copy(conn, os.Stdin); err != nil {
+			logrus.Debugf("Error relaying standard input: %v", err)
+		}
+		if closer, ok := conn.(interface{ CloseWrite() error }); ok {
+			if err := closer.CloseWrite(); err != nil {
+				logrus.Debugf("Error closing connection to API service: %v", err)
+			}
+		}
+	}()
+	if _, err := io.Copy(os.Stdout, conn); err != nil {
+		return errors.Wrapf(err, "error relaying output of API service")
+	}
+	return nil
+}
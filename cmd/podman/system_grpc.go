@@ -0,0 +1,112 @@
+// +build !remoteclient
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/containers/libpod/pkg/api/grpc"
+	"github.com/containers/libpod/pkg/api/server"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	grpcCommand     cliconfig.SystemGRPCValues
+	grpcDescription = `
+        podman system grpc
+
+        Serve the gRPC API, streaming events, logs and stats, on the given address. TCP clients must authenticate with a TLS certificate. Runs until interrupted.
+`
+
+	_grpcCommand = &cobra.Command{
+		Use:   "grpc [flags] [URI]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Serve the gRPC API",
+		Long:  grpcDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			grpcCommand.InputArgs = args
+			grpcCommand.GlobalFlags = MainGlobalOpts
+			grpcCommand.Remote = remoteclient
+			return grpcCmd(&grpcCommand)
+		},
+		Example: `podman system grpc
+  podman system grpc --tls-cert cert.pem --tls-key key.pem --tls-ca ca.pem tcp://0.0.0.0:8444`,
+	}
+)
+
+func init() {
+	grpcCommand.Command = _grpcCommand
+	grpcCommand.SetHelpTemplate(HelpTemplate())
+	grpcCommand.SetUsageTemplate(UsageTemplate())
+	flags := grpcCommand.Flags()
+	flags.StringVar(&grpcCommand.TLSCert, "tls-cert", "", "Serve over TLS with the certificate in `file`")
+	flags.StringVar(&grpcCommand.TLSKey, "tls-key", "", "Private key of the TLS certificate, in `file`")
+	flags.StringVar(&grpcCommand.TLSCA, "tls-ca", "", "Require clients to present a certificate signed by the CA in `file`")
+}
+
+func grpcCmd(c *cliconfig.SystemGRPCValues) error {
+	address, err := defaultSocketAddress("podman-grpc.sock")
+	if err != nil {
+		return err
+	}
+	if len(c.InputArgs) > 0 {
+		address = c.InputArgs[0]
+	}
+
+	var tlsConfig *tls.Config
+	if c.TLSCert != "" || c.TLSKey != "" || c.TLSCA != "" {
+		if c.TLSCert == "" || c.TLSKey == "" || c.TLSCA == "" {
+			return errors.Errorf("--tls-cert, --tls-key and --tls-ca must be given together")
+		}
+		options := tlsconfig.Options{
+			CertFile:   c.TLSCert,
+			KeyFile:    c.TLSKey,
+			CAFile:     c.TLSCA,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+		if tlsConfig, err = tlsconfig.Server(options); err != nil {
+			return errors.Wrapf(err, "error loading TLS configuration")
+		}
+		tlsConfig.NextProtos = []string{"h2"}
+	} else if !strings.HasPrefix(address, "unix://") {
+		return errors.Errorf("serving the gRPC API on %s requires --tls-cert, --tls-key and --tls-ca", address)
+	}
+
+	runtime, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer runtime.DeferredShutdown(false)
+
+	listener, err := server.NewListener(address, tlsConfig)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("Serving gRPC API on %s", address)
+
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return grpc.NewServer(runtime, listener).Serve(ctx)
+}
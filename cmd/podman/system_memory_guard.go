@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// defaultMemoryGuardInterval is the default interval between host memory
+// pressure checks
+const defaultMemoryGuardInterval = 5 * time.Second
+
+var (
+	memoryGuardCommand     cliconfig.SystemMemoryGuardValues
+	memoryGuardDescription = `
+        podman system memory-guard
+
+        Freeze low-priority containers with SIGSTOP while the host is under memory pressure,
+        and resume them once the pressure clears. Runs until interrupted.
+`
+
+	_memoryGuardCommand = &cobra.Command{
+		Use:   "memory-guard",
+		Args:  noSubArgs,
+		Short: "Freeze low-priority containers under host memory pressure",
+		Long:  memoryGuardDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			memoryGuardCommand.InputArgs = args
+			memoryGuardCommand.GlobalFlags = MainGlobalOpts
+			memoryGuardCommand.Remote = remoteclient
+			return memoryGuardCmd(&memoryGuardCommand)
+		},
+	}
+)
+
+func init() {
+	memoryGuardCommand.Command = _memoryGuardCommand
+	memoryGuardCommand.SetHelpTemplate(HelpTemplate())
+	memoryGuardCommand.SetUsageTemplate(UsageTemplate())
+	flags := memoryGuardCommand.Flags()
+	flags.DurationVar(&memoryGuardCommand.Interval, "interval", defaultMemoryGuardInterval, "Interval between host memory pressure checks")
+}
+
+func memoryGuardCmd(c *cliconfig.SystemMemoryGuardValues) error {
+	r, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer r.DeferredShutdown(false)
+
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return r.MemoryPressureGuard(ctx, c.Interval)
+}
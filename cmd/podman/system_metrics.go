@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// defaultMetricsAddress is the default address the metrics are served on
+const defaultMetricsAddress = "tcp://127.0.0.1:9882"
+
+var (
+	metricsCommand     cliconfig.SystemMetricsValues
+	metricsDescription = `
+        podman system metrics
+
+        Serve the metrics of containers, pods, volumes and of the Podman database in the Prometheus exposition format under /metrics. Runs until interrupted.
+`
+
+	_metricsCommand = &cobra.Command{
+		Use:   "metrics",
+		Args:  noSubArgs,
+		Short: "Serve Prometheus metrics of the containers and of Podman",
+		Long:  metricsDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			metricsCommand.InputArgs = args
+			metricsCommand.GlobalFlags = MainGlobalOpts
+			metricsCommand.Remote = remoteclient
+			return metricsCmd(&metricsCommand)
+		},
+	}
+)
+
+func init() {
+	metricsCommand.Command = _metricsCommand
+	metricsCommand.SetHelpTemplate(HelpTemplate())
+	metricsCommand.SetUsageTemplate(UsageTemplate())
+	flags := metricsCommand.Flags()
+	flags.StringVar(&metricsCommand.Listen, "listen", defaultMetricsAddress, "Address to serve the metrics on, as unix:///path, tcp://host:port or host:port")
+}
+
+func metricsCmd(c *cliconfig.SystemMetricsValues) error {
+	r, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer r.DeferredShutdown(false)
+
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return r.ServeMetrics(ctx, c.Listen)
+}
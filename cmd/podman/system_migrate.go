@@ -1,8 +1,12 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/containers/libpod/cmd/podman/cliconfig"
 	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/containers/libpod/libpod"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +29,8 @@ var (
 			migrateCommand.GlobalFlags = MainGlobalOpts
 			return migrateCmd(&migrateCommand)
 		},
+		Example: `podman system migrate
+  podman system migrate --new-runtime crun`,
 	}
 )
 
@@ -32,19 +38,31 @@ func init() {
 	migrateCommand.Command = _migrateCommand
 	migrateCommand.SetHelpTemplate(HelpTemplate())
 	migrateCommand.SetUsageTemplate(UsageTemplate())
+	flags := migrateCommand.Flags()
+	flags.StringVar(&migrateCommand.NewRuntime, "new-runtime", "", "Move all containers to the given OCI `runtime`")
 }
 
 func migrateCmd(c *cliconfig.SystemMigrateValues) error {
-	// We need to pass one extra option to NewRuntime.
-	// This will inform the OCI runtime to start a migrate.
-	// That's controlled by the last argument to GetRuntime.
-	r, err := libpodruntime.GetRuntimeMigrate(getContext(), &c.PodmanCommand)
+	r, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
 	if err != nil {
-		return errors.Wrapf(err, "error migrating containers")
-	}
-	if err := r.Shutdown(false); err != nil {
-		return err
+		return errors.Wrapf(err, "error creating libpod runtime")
 	}
+	defer r.DeferredShutdown(false)
 
+	report, err := r.Migrate(getContext(), libpod.MigrateOptions{NewRuntime: c.NewRuntime})
+	if report != nil {
+		for _, id := range report.StoppedContainers {
+			fmt.Printf("stopped %s\n", id)
+		}
+		for _, id := range report.RewrittenContainers {
+			fmt.Printf("migrated %s\n", id)
+		}
+		for id, ctrErr := range report.FailedContainers {
+			fmt.Fprintf(os.Stderr, "error migrating %s: %v\n", id, ctrErr)
+		}
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error migrating containers")
+	}
 	return nil
 }
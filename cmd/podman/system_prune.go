@@ -106,7 +106,7 @@ Are you sure you want to continue? [y/N] `, volumeString)
 
 	if c.Bool("volumes") {
 		fmt.Println("Deleted Volumes")
-		err := volumePrune(runtime, getContext())
+		err := volumePrune(runtime, getContext(), nil)
 		if err != nil {
 			if lasterr != nil {
 				logrus.Errorf("%q", lasterr)
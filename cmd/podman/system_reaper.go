@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// defaultReaperInterval is the default interval between checks for
+// containers queued for removal
+const defaultReaperInterval = time.Minute
+
+var (
+	reaperCommand     cliconfig.SystemReaperValues
+	reaperDescription = `
+        podman system reaper
+
+        Remove containers created with --rm that were left behind after they exited. Runs until interrupted.
+`
+
+	_reaperCommand = &cobra.Command{
+		Use:   "reaper",
+		Args:  noSubArgs,
+		Short: "Remove auto-remove containers left behind after they exited",
+		Long:  reaperDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reaperCommand.InputArgs = args
+			reaperCommand.GlobalFlags = MainGlobalOpts
+			reaperCommand.Remote = remoteclient
+			return reaperCmd(&reaperCommand)
+		},
+	}
+)
+
+func init() {
+	reaperCommand.Command = _reaperCommand
+	reaperCommand.SetHelpTemplate(HelpTemplate())
+	reaperCommand.SetUsageTemplate(UsageTemplate())
+	flags := reaperCommand.Flags()
+	flags.DurationVar(&reaperCommand.Interval, "interval", defaultReaperInterval, "Interval between checks for containers queued for removal")
+}
+
+func reaperCmd(c *cliconfig.SystemReaperValues) error {
+	r, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer r.DeferredShutdown(false)
+
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return r.RunReaper(ctx, c.Interval)
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/containers/libpod/cmd/podman/cliconfig"
 	"github.com/containers/libpod/cmd/podman/libpodruntime"
 	"github.com/pkg/errors"
@@ -34,9 +37,15 @@ func init() {
 	renumberCommand.Command = _renumberCommand
 	renumberCommand.SetHelpTemplate(HelpTemplate())
 	renumberCommand.SetUsageTemplate(UsageTemplate())
+	flags := renumberCommand.Flags()
+	flags.BoolVar(&renumberCommand.DryRun, "dry-run", false, "Print the lock numbers that would be assigned, without changing them")
 }
 
 func renumberCmd(c *cliconfig.SystemRenumberValues) error {
+	if c.DryRun {
+		return renumberDryRun(c)
+	}
+
 	// We need to pass one extra option to NewRuntime.
 	// This will inform the OCI runtime to start a renumber.
 	// That's controlled by the last argument to GetRuntime.
@@ -50,3 +59,32 @@ func renumberCmd(c *cliconfig.SystemRenumberValues) error {
 
 	return nil
 }
+
+func renumberDryRun(c *cliconfig.SystemRenumberValues) error {
+	r, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer r.DeferredShutdown(false)
+
+	report, err := r.RenumberLocks(0, true)
+	if err != nil {
+		return errors.Wrapf(err, "error renumbering locks")
+	}
+
+	printLocks := func(kind string, locks map[string]uint32) {
+		ids := make([]string, 0, len(locks))
+		for id := range locks {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			fmt.Printf("%s %s: lock %d\n", kind, id, locks[id])
+		}
+	}
+	printLocks("container", report.Containers)
+	printLocks("pod", report.Pods)
+	printLocks("volume", report.Volumes)
+
+	return nil
+}
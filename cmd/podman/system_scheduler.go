@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// defaultSchedulerInterval is the default interval between checks for
+// scheduled containers that are due
+const defaultSchedulerInterval = 30 * time.Second
+
+var (
+	schedulerCommand     cliconfig.SystemSchedulerValues
+	schedulerDescription = `
+        podman system scheduler
+
+        Start containers created with --schedule when they are due. Runs until interrupted.
+`
+
+	_schedulerCommand = &cobra.Command{
+		Use:   "scheduler",
+		Args:  noSubArgs,
+		Short: "Start containers according to their start schedules",
+		Long:  schedulerDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schedulerCommand.InputArgs = args
+			schedulerCommand.GlobalFlags = MainGlobalOpts
+			schedulerCommand.Remote = remoteclient
+			return schedulerCmd(&schedulerCommand)
+		},
+	}
+)
+
+func init() {
+	schedulerCommand.Command = _schedulerCommand
+	schedulerCommand.SetHelpTemplate(HelpTemplate())
+	schedulerCommand.SetUsageTemplate(UsageTemplate())
+	flags := schedulerCommand.Flags()
+	flags.DurationVar(&schedulerCommand.Interval, "interval", defaultSchedulerInterval, "Interval between checks for scheduled containers that are due")
+}
+
+func schedulerCmd(c *cliconfig.SystemSchedulerValues) error {
+	r, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer r.DeferredShutdown(false)
+
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return r.RunScheduler(ctx, c.Interval)
+}
@@ -0,0 +1,139 @@
+// +build !remoteclient
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/containers/libpod/pkg/api/server"
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/containers/libpod/pkg/util"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serviceCommand     cliconfig.SystemServiceValues
+	serviceDescription = `
+        podman system service
+
+        Serve the Docker-compatible REST API, and the Podman specific endpoints under /libpod, on the given address.
+`
+
+	_serviceCommand = &cobra.Command{
+		Use:   "service [flags] [URI]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Serve the Docker-compatible REST API",
+		Long:  serviceDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serviceCommand.InputArgs = args
+			serviceCommand.GlobalFlags = MainGlobalOpts
+			serviceCommand.Remote = remoteclient
+			return serviceCmd(&serviceCommand)
+		},
+		Example: `podman system service unix:///run/podman/podman.sock
+  podman system service --timeout 0 --tls-cert cert.pem --tls-key key.pem --tls-ca ca.pem tcp://0.0.0.0:8443`,
+	}
+)
+
+func init() {
+	serviceCommand.Command = _serviceCommand
+	serviceCommand.SetHelpTemplate(HelpTemplate())
+	serviceCommand.SetUsageTemplate(UsageTemplate())
+	flags := serviceCommand.Flags()
+	flags.Int64VarP(&serviceCommand.Timeout, "timeout", "t", 5, "Time until the service exits without connections in seconds.  Use 0 to disable the timeout")
+	flags.StringVar(&serviceCommand.TLSCert, "tls-cert", "", "Serve TCP connections over TLS with the certificate in `file`")
+	flags.StringVar(&serviceCommand.TLSKey, "tls-key", "", "Private key of the TLS certificate, in `file`")
+	flags.StringVar(&serviceCommand.TLSCA, "tls-ca", "", "Require clients to present a certificate signed by the CA in `file`")
+	flags.BoolVar(&serviceCommand.Insecure, "insecure", false, "Allow serving TCP connections without authenticating clients with TLS certificates")
+}
+
+// defaultSocketAddress returns the address of the unix socket with the given
+// name under /run/podman, or under the runtime directory of rootless users
+func defaultSocketAddress(name string) (string, error) {
+	dir := "/run"
+	if rootless.IsRootless() {
+		xdg, err := util.GetRuntimeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = xdg
+	}
+	return fmt.Sprintf("unix://%s", filepath.Join(dir, "podman", name)), nil
+}
+
+// defaultServiceAddress returns the address the API is served on by default
+func defaultServiceAddress() (string, error) {
+	return defaultSocketAddress("podman.sock")
+}
+
+func serviceCmd(c *cliconfig.SystemServiceValues) error {
+	address, err := defaultServiceAddress()
+	if err != nil {
+		return err
+	}
+	if len(c.InputArgs) > 0 {
+		address = c.InputArgs[0]
+	}
+	if c.Timeout < 0 {
+		return errors.Errorf("invalid timeout %d, expected 0 or more seconds", c.Timeout)
+	}
+
+	var tlsConfig *tls.Config
+	if c.TLSCert != "" || c.TLSKey != "" || c.TLSCA != "" {
+		if c.TLSCert == "" || c.TLSKey == "" {
+			return errors.Errorf("--tls-cert and --tls-key must be given together")
+		}
+		options := tlsconfig.Options{CertFile: c.TLSCert, KeyFile: c.TLSKey, CAFile: c.TLSCA}
+		if c.TLSCA != "" {
+			options.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		if tlsConfig, err = tlsconfig.Server(options); err != nil {
+			return errors.Wrapf(err, "error loading TLS configuration")
+		}
+	}
+	// Anyone reaching a TCP address could otherwise manage containers
+	if !strings.HasPrefix(address, "unix://") && c.TLSCA == "" && !c.Insecure {
+		return errors.Errorf("serving the API on %s requires --tls-cert, --tls-key and --tls-ca, or --insecure", address)
+	}
+
+	runtime, err := libpodruntime.GetRuntimeDisableFDs(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer runtime.DeferredShutdown(false)
+
+	listener, err := server.NewListener(address, tlsConfig)
+	if err != nil {
+		return err
+	}
+	logrus.Debugf("Serving API on %s", address)
+
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return server.NewServer(runtime, listener, time.Duration(c.Timeout)*time.Second).Serve(ctx)
+}
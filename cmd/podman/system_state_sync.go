@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/libpodruntime"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// defaultStateSyncInterval is the default interval in which every
+// externally-managed container is synced
+const defaultStateSyncInterval = 30 * time.Second
+
+var (
+	stateSyncCommand     cliconfig.SystemStateSyncValues
+	stateSyncDescription = `
+        podman system state-sync
+
+        Keep the status of externally-managed containers, such as those restored from imported checkpoints, in sync with the OCI runtime. Runs until interrupted.
+`
+
+	_stateSyncCommand = &cobra.Command{
+		Use:   "state-sync",
+		Args:  noSubArgs,
+		Short: "Sync the status of externally-managed containers in the background",
+		Long:  stateSyncDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stateSyncCommand.InputArgs = args
+			stateSyncCommand.GlobalFlags = MainGlobalOpts
+			stateSyncCommand.Remote = remoteclient
+			return stateSyncCmd(&stateSyncCommand)
+		},
+	}
+)
+
+func init() {
+	stateSyncCommand.Command = _stateSyncCommand
+	stateSyncCommand.SetHelpTemplate(HelpTemplate())
+	stateSyncCommand.SetUsageTemplate(UsageTemplate())
+	flags := stateSyncCommand.Flags()
+	flags.DurationVar(&stateSyncCommand.Interval, "interval", defaultStateSyncInterval, "Interval in which every externally-managed container is synced")
+}
+
+func stateSyncCmd(c *cliconfig.SystemStateSyncValues) error {
+	r, err := libpodruntime.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "error creating libpod runtime")
+	}
+	defer r.DeferredShutdown(false)
+
+	ctx, cancel := context.WithCancel(getContext())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		select {
+		case <-sigChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return r.RunStateSync(ctx, c.Interval)
+}
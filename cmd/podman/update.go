@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/adapter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateCommand     cliconfig.UpdateValues
+	updateDescription = `Updates the resource limits of one or more containers.
+
+  Running containers are updated immediately. The new limits are kept when the containers are restarted.`
+	_updateCommand = &cobra.Command{
+		Use:   "update [flags] CONTAINER [CONTAINER...]",
+		Short: "Update the resource limits of one or more containers",
+		Long:  updateDescription,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			updateCommand.InputArgs = args
+			updateCommand.GlobalFlags = MainGlobalOpts
+			updateCommand.Remote = remoteclient
+			return updateCmd(&updateCommand)
+		},
+		Example: `podman update --memory 512m mywebserver
+  podman update --cpu-shares 512 --pids-limit 100 860a4b23`,
+	}
+)
+
+func init() {
+	updateCommand.Command = _updateCommand
+	updateCommand.SetHelpTemplate(HelpTemplate())
+	updateCommand.SetUsageTemplate(UsageTemplate())
+	flags := updateCommand.Flags()
+	flags.StringVar(&updateCommand.BlkioWeight, "blkio-weight", "", "Block IO weight (relative weight) accepts a weight value between 10 and 1000.")
+	flags.Uint64Var(&updateCommand.CPUShares, "cpu-shares", 0, "CPU shares (relative weight)")
+	flags.StringVarP(&updateCommand.Memory, "memory", "m", "", "Memory limit (format: <number>[<unit>], where unit = b, k, m or g)")
+	flags.StringVar(&updateCommand.MemorySwap, "memory-swap", "", "Swap limit equal to memory plus swap: '-1' to enable unlimited swap")
+	flags.Int64Var(&updateCommand.PidsLimit, "pids-limit", 0, "Tune container pids limit (set -1 for unlimited)")
+}
+
+func updateCmd(c *cliconfig.UpdateValues) error {
+	if len(c.InputArgs) < 1 {
+		return errors.Errorf("you must provide at least one container name or id")
+	}
+	changed := false
+	for _, flag := range []string{"blkio-weight", "cpu-shares", "memory", "memory-swap", "pids-limit"} {
+		if c.IsSet(flag) {
+			changed = true
+		}
+	}
+	if !changed {
+		return errors.Errorf("you must provide at least one resource limit to update")
+	}
+
+	runtime, err := adapter.GetRuntime(getContext(), &c.PodmanCommand)
+	if err != nil {
+		return errors.Wrapf(err, "could not get runtime")
+	}
+	defer runtime.DeferredShutdown(false)
+
+	ok, failures, err := runtime.UpdateContainers(getContext(), c)
+	if err != nil {
+		if errors.Cause(err) == define.ErrNoSuchCtr {
+			if len(c.InputArgs) > 1 {
+				exitCode = 125
+			} else {
+				exitCode = 1
+			}
+		}
+		return err
+	}
+	if len(failures) > 0 {
+		exitCode = 125
+	}
+	return printCmdResults(ok, failures)
+}
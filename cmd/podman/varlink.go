@@ -5,12 +5,15 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/containers/libpod/cmd/podman/cliconfig"
 	"github.com/containers/libpod/cmd/podman/libpodruntime"
 	iopodman "github.com/containers/libpod/cmd/podman/varlink"
+	"github.com/containers/libpod/libpod"
 	"github.com/containers/libpod/pkg/adapter"
 	"github.com/containers/libpod/pkg/rootless"
 	"github.com/containers/libpod/pkg/util"
@@ -48,6 +51,7 @@ func init() {
 	varlinkCommand.SetUsageTemplate(UsageTemplate())
 	flags := varlinkCommand.Flags()
 	flags.Int64VarP(&varlinkCommand.Timeout, "timeout", "t", 1000, "Time until the varlink session expires in milliseconds.  Use 0 to disable the timeout")
+	flags.StringVar(&varlinkCommand.TokensFile, "tokens-file", "", "Require clients to authenticate with one of the API tokens in `file`, limiting them to its namespace and methods")
 }
 
 func varlinkCmd(c *cliconfig.VarlinkValues) error {
@@ -78,14 +82,71 @@ func varlinkCmd(c *cliconfig.VarlinkValues) error {
 	logrus.Debugf("Using varlink socket: %s", varlinkURI)
 	timeout := time.Duration(c.Timeout) * time.Millisecond
 
+	var tokens *varlinkapi.APITokens
+	if c.TokensFile != "" {
+		var err error
+		tokens, err = varlinkapi.LoadAPITokens(c.TokensFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create a single runtime for varlink
 	runtime, err := libpodruntime.GetRuntimeDisableFDs(getContext(), &c.PodmanCommand)
 	if err != nil {
 		return errors.Wrapf(err, "error creating libpod runtime")
 	}
 	defer runtime.DeferredShutdown(false)
+	runtimes := []*libpod.Runtime{runtime}
 
-	var varlinkInterfaces = []*iopodman.VarlinkInterface{varlinkapi.New(&c.PodmanCommand, runtime)}
+	api := varlinkapi.New(&c.PodmanCommand, runtime)
+	var scoped *varlinkapi.ScopedInterface
+	if tokens != nil {
+		// Calls made with a token are served by a runtime joined to
+		// the token's namespace
+		apis := map[string]*iopodman.VarlinkInterface{
+			"":                      api,
+			c.GlobalFlags.Namespace: api,
+		}
+		for _, namespace := range tokens.Namespaces() {
+			if _, ok := apis[namespace]; ok {
+				continue
+			}
+			nsCommand := c.PodmanCommand
+			nsCommand.GlobalFlags.Namespace = namespace
+			nsRuntime, err := libpodruntime.GetRuntimeDisableFDs(getContext(), &nsCommand)
+			if err != nil {
+				return errors.Wrapf(err, "error creating libpod runtime for namespace %s", namespace)
+			}
+			defer nsRuntime.DeferredShutdown(false)
+			runtimes = append(runtimes, nsRuntime)
+			apis[namespace] = varlinkapi.New(&nsCommand, nsRuntime)
+		}
+		scoped, err = varlinkapi.NewScoped(tokens, apis)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Reload the configuration on SIGHUP so that long-running services
+	// pick up changes without a restart
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+	go func() {
+		for range sigChan {
+			for _, r := range runtimes {
+				changed, err := r.ReloadConfig()
+				if err != nil {
+					logrus.Errorf("Error reloading configuration: %v", err)
+					continue
+				}
+				logrus.Infof("Configuration reloaded, %d settings changed", len(changed))
+			}
+		}
+	}()
+
+	var varlinkInterfaces = []*iopodman.VarlinkInterface{api}
 	// Register varlink service. The metadata can be retrieved with:
 	// $ varlink info [varlink address URI]
 	service, err := varlink.NewService(
@@ -98,9 +159,15 @@ func varlinkCmd(c *cliconfig.VarlinkValues) error {
 		return errors.Wrapf(err, "unable to create new varlink service")
 	}
 
-	for _, i := range varlinkInterfaces {
-		if err := service.RegisterInterface(i); err != nil {
-			return errors.Errorf("unable to register varlink interface %v", i)
+	if scoped != nil {
+		if err := service.RegisterInterface(scoped); err != nil {
+			return errors.Errorf("unable to register varlink interface %v", scoped)
+		}
+	} else {
+		for _, i := range varlinkInterfaces {
+			if err := service.RegisterInterface(i); err != nil {
+				return errors.Errorf("unable to register varlink interface %v", i)
+			}
 		}
 	}
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/containers/buildah/pkg/formats"
 	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/cmd/podman/shared"
 	"github.com/containers/libpod/pkg/adapter"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -257,36 +258,12 @@ func generateVolumeFilterFuncs(filter, filterValue string) (func(volume *adapter
 			return v.Scope() == filterValue
 		}, nil
 	case "label":
-		filterArray := strings.SplitN(filterValue, "=", 2)
-		filterKey := filterArray[0]
-		if len(filterArray) > 1 {
-			filterValue = filterArray[1]
-		} else {
-			filterValue = ""
-		}
 		return func(v *adapter.Volume) bool {
-			for labelKey, labelValue := range v.Labels() {
-				if labelKey == filterKey && ("" == filterValue || labelValue == filterValue) {
-					return true
-				}
-			}
-			return false
+			return shared.MatchesKeyValueFilter(v.Labels(), filterValue)
 		}, nil
 	case "opt":
-		filterArray := strings.SplitN(filterValue, "=", 2)
-		filterKey := filterArray[0]
-		if len(filterArray) > 1 {
-			filterValue = filterArray[1]
-		} else {
-			filterValue = ""
-		}
 		return func(v *adapter.Volume) bool {
-			for labelKey, labelValue := range v.Options() {
-				if labelKey == filterKey && ("" == filterValue || labelValue == filterValue) {
-					return true
-				}
-			}
-			return false
+			return shared.MatchesKeyValueFilter(v.Options(), filterValue)
 		}, nil
 	}
 	return nil, errors.Errorf("%s is an invalid filter", filter)
@@ -9,6 +9,7 @@ import (
 
 	"github.com/containers/libpod/cmd/podman/cliconfig"
 	"github.com/containers/libpod/pkg/adapter"
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -40,14 +41,18 @@ func init() {
 	volumePruneCommand.SetUsageTemplate(UsageTemplate())
 	flags := volumePruneCommand.Flags()
 
+	flags.StringArrayVar(&volumePruneCommand.Filter, "filter", []string{}, "Provide filter values (e.g. 'label=<key>=<value>')")
 	flags.BoolVarP(&volumePruneCommand.Force, "force", "f", false, "Do not prompt for confirmation")
 }
 
-func volumePrune(runtime *adapter.LocalRuntime, ctx context.Context) error {
-	prunedNames, prunedErrors := runtime.PruneVolumes(ctx)
+func volumePrune(runtime *adapter.LocalRuntime, ctx context.Context, filters []string) error {
+	prunedNames, reclaimed, prunedErrors := runtime.PruneVolumes(ctx, filters)
 	for _, name := range prunedNames {
 		fmt.Println(name)
 	}
+	if len(prunedNames) > 0 {
+		fmt.Printf("Total reclaimed space: %s\n", units.HumanSize(float64(reclaimed)))
+	}
 	if len(prunedErrors) == 0 {
 		return nil
 	}
@@ -82,5 +87,5 @@ func volumePruneCmd(c *cliconfig.VolumePruneValues) error {
 			return nil
 		}
 	}
-	return volumePrune(runtime, getContext())
+	return volumePrune(runtime, getContext(), c.Filter)
 }
@@ -0,0 +1,345 @@
+package libpod
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/containers/image/docker"
+	"github.com/containers/image/docker/reference"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/image"
+	"github.com/containers/libpod/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// AutoUpdateLabel labels containers to be updated by AutoUpdate, with
+	// the policy deciding when to update them
+	AutoUpdateLabel = "io.containers.autoupdate"
+	// AutoUpdatePolicyRegistry updates containers whose image has a newer
+	// version in its registry, under the same reference
+	AutoUpdatePolicyRegistry = "registry"
+	// SystemdUnitLabel is the systemd unit running the container, recorded
+	// from $PODMAN_SYSTEMD_UNIT when the container is created
+	SystemdUnitLabel = "PODMAN_SYSTEMD_UNIT"
+)
+
+// AutoUpdateOptions are the options of AutoUpdate
+type AutoUpdateOptions struct {
+	// Authfile is the path of the file authenticating to the registries
+	Authfile string
+	// Writer receives the progress of the image pulls, if set
+	Writer io.Writer
+}
+
+// AutoUpdateReport is the result of auto-updating a container
+type AutoUpdateReport struct {
+	// ContainerID is the ID of the container. Containers re-created with
+	// the newer image keep their ID.
+	ContainerID string
+	// Image is the reference of the image of the container
+	Image string
+	// Unit is the systemd unit running the container, if any
+	Unit string
+	// Updated is set if the container runs a newer image, after its unit
+	// was restarted or it was re-created
+	Updated bool
+}
+
+// autoUpdater holds the operations of AutoUpdate reaching out of libpod, so
+// they can be replaced in tests
+type autoUpdater struct {
+	runtime *Runtime
+	// remoteImageID returns the ID of the image under the reference in
+	// its registry
+	remoteImageID func(ctx context.Context, named reference.Named) (string, error)
+	// localImageID returns the ID of the local image under the reference
+	localImageID func(name string) (string, error)
+	// pullImage pulls the image under the reference, and returns its ID
+	pullImage func(ctx context.Context, name string) (string, error)
+	// restartUnit restarts a systemd unit
+	restartUnit func(ctx context.Context, unit string) error
+	// recreate re-creates a container with another image
+	recreate func(ctx context.Context, ctr *Container, imageID string) (*Container, error)
+	// newestImages maps the references already checked to the ID of
+	// their newest image
+	newestImages map[string]string
+}
+
+// newAutoUpdater returns an auto-updater using the registries, the image
+// store and systemd
+func (r *Runtime) newAutoUpdater(options AutoUpdateOptions) *autoUpdater {
+	return &autoUpdater{
+		runtime: r,
+		remoteImageID: func(ctx context.Context, named reference.Named) (string, error) {
+			return remoteImageID(ctx, named, options)
+		},
+		localImageID: func(name string) (string, error) {
+			img, err := r.imageRuntime.NewFromLocal(name)
+			if err != nil {
+				return "", err
+			}
+			return img.ID(), nil
+		},
+		pullImage: func(ctx context.Context, name string) (string, error) {
+			img, err := r.imageRuntime.New(ctx, name, "", options.Authfile, options.Writer, &image.DockerRegistryOptions{}, image.SigningOptions{}, nil, util.PullImageAlways)
+			if err != nil {
+				return "", errors.Wrapf(err, "error pulling image %s", name)
+			}
+			return img.ID(), nil
+		},
+		restartUnit:  restartSystemdUnit,
+		recreate:     r.recreateContainer,
+		newestImages: make(map[string]string),
+	}
+}
+
+// AutoUpdate updates the containers labeled with AutoUpdateLabel. If the
+// registry of the image of a container has a newer image under the same
+// reference, it is pulled. The systemd unit running the container is then
+// restarted, which runs the newer image if the unit creates a new container
+// at every start. Containers not run by a systemd unit are re-created with the
+// newer image instead, keeping their ID, name and configuration, and started
+// again if they were running.
+// The times of the last check and update are recorded in the state of the
+// containers. Containers failing to update are returned as errors, and do not
+// keep the others from updating.
+func (r *Runtime) AutoUpdate(ctx context.Context, options AutoUpdateOptions) ([]*AutoUpdateReport, []error) {
+	return r.newAutoUpdater(options).run(ctx)
+}
+
+// run updates the containers labeled with AutoUpdateLabel
+func (u *autoUpdater) run(ctx context.Context) ([]*AutoUpdateReport, []error) {
+	ctrs, err := u.runtime.GetContainers(func(c *Container) bool {
+		_, ok := c.Labels()[AutoUpdateLabel]
+		return ok
+	})
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var (
+		reports []*AutoUpdateReport
+		errs    []error
+	)
+	// Units are restarted once, whatever the number of containers they run
+	unitReports := make(map[string][]*AutoUpdateReport)
+	var units []string
+	for _, ctr := range ctrs {
+		report, newestID, err := u.checkImage(ctx, ctr)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "error auto-updating container %s", ctr.ID()))
+			continue
+		}
+		reports = append(reports, report)
+		if newestID == "" {
+			if err := ctr.recordAutoUpdate(time.Now(), false); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if report.Unit == "" {
+			logrus.Debugf("Re-creating container %s with image %s", ctr.ID(), newestID)
+			newCtr, err := u.recreate(ctx, ctr, newestID)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "error re-creating container %s with image %s", ctr.ID(), report.Image))
+				continue
+			}
+			report.Updated = true
+			if err := newCtr.recordAutoUpdate(time.Now(), true); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if _, ok := unitReports[report.Unit]; !ok {
+			units = append(units, report.Unit)
+		}
+		unitReports[report.Unit] = append(unitReports[report.Unit], report)
+	}
+
+	for _, unit := range units {
+		errs = append(errs, u.updateUnit(ctx, unit, unitReports[unit])...)
+	}
+	return reports, errs
+}
+
+// updateUnit restarts a systemd unit, and checks that its containers run the
+// newest images afterwards. Units restarting the same containers keep running
+// the previous images.
+func (u *autoUpdater) updateUnit(ctx context.Context, unit string, reports []*AutoUpdateReport) []error {
+	var errs []error
+	logrus.Debugf("Restarting systemd unit %s to update its containers", unit)
+	restartErr := u.restartUnit(ctx, unit)
+	if restartErr != nil {
+		errs = append(errs, errors.Wrapf(restartErr, "error restarting systemd unit %s", unit))
+	}
+
+	// Units creating new containers at every start replace them, so the
+	// containers are found again by unit
+	unitCtrs, err := u.runtime.GetContainers(func(c *Container) bool {
+		return c.Labels()[SystemdUnitLabel] == unit
+	})
+	if err != nil {
+		return append(errs, err)
+	}
+	updatedImages := make(map[string]bool)
+	for _, ctr := range unitCtrs {
+		imageID, _ := ctr.Image()
+		named, err := autoUpdateReference(ctr)
+		updated := restartErr == nil && err == nil && imageID == u.newestImages[named.String()]
+		if updated {
+			updatedImages[named.String()] = true
+		}
+		if err := ctr.recordAutoUpdate(time.Now(), updated); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, report := range reports {
+		report.Updated = updatedImages[report.Image]
+		if restartErr == nil && !report.Updated {
+			errs = append(errs, errors.Wrapf(define.ErrInvalidArg, "systemd unit %s was restarted but does not run image %s: only units generated with --new create containers with newer images", unit, report.Image))
+		}
+	}
+	return errs
+}
+
+// checkImage checks whether the registry of the image of the container has a
+// newer image under the same reference, and pulls it. The ID of the newest
+// image is returned if the container does not run it.
+func (u *autoUpdater) checkImage(ctx context.Context, ctr *Container) (*AutoUpdateReport, string, error) {
+	named, err := autoUpdateReference(ctr)
+	if err != nil {
+		return nil, "", err
+	}
+	report := &AutoUpdateReport{
+		ContainerID: ctr.ID(),
+		Image:       named.String(),
+		Unit:        ctr.Labels()[SystemdUnitLabel],
+	}
+
+	// Images are checked and pulled once, whatever the number of
+	// containers using them
+	newestID, ok := u.newestImages[report.Image]
+	if !ok {
+		remoteID, err := u.remoteImageID(ctx, named)
+		if err != nil {
+			return nil, "", err
+		}
+		newestID, err = u.localImageID(report.Image)
+		if err != nil {
+			logrus.Debugf("Error looking up local image %s: %v", report.Image, err)
+		}
+		if remoteID != newestID {
+			logrus.Debugf("Pulling %s to update container %s", report.Image, ctr.ID())
+			if newestID, err = u.pullImage(ctx, report.Image); err != nil {
+				return nil, "", err
+			}
+		}
+		u.newestImages[report.Image] = newestID
+	}
+
+	if imageID, _ := ctr.Image(); imageID == newestID {
+		return report, "", nil
+	}
+	return report, newestID, nil
+}
+
+// autoUpdateReference returns the reference of the image of a container with
+// a valid auto-update policy. It must be fully qualified to be looked up in
+// its registry.
+func autoUpdateReference(ctr *Container) (reference.Named, error) {
+	if policy := ctr.Labels()[AutoUpdateLabel]; policy != AutoUpdatePolicyRegistry {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "unknown auto-update policy %q, must be %q", policy, AutoUpdatePolicyRegistry)
+	}
+	_, rawName := ctr.Image()
+	if rawName == "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "container %s was not created from an image", ctr.ID())
+	}
+	named, err := reference.ParseNamed(rawName)
+	if err != nil {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "image %s must be a fully-qualified reference to be auto-updated", rawName)
+	}
+	return reference.TagNameOnly(named), nil
+}
+
+// remoteImageID returns the ID of the image under the reference in its
+// registry, for the platform of the host
+func remoteImageID(ctx context.Context, named reference.Named, options AutoUpdateOptions) (string, error) {
+	ref, err := docker.NewReference(named)
+	if err != nil {
+		return "", err
+	}
+	img, err := ref.NewImage(ctx, image.GetSystemContext("", options.Authfile, false))
+	if err != nil {
+		return "", errors.Wrapf(err, "error inspecting image %s in its registry", named.String())
+	}
+	defer func() {
+		if err := img.Close(); err != nil {
+			logrus.Errorf("Error closing image %s: %v", named.String(), err)
+		}
+	}()
+	return img.ConfigInfo().Digest.Hex(), nil
+}
+
+// recreateContainer removes a container, and creates it again with the same
+// ID, name and configuration, from another image. Its volumes are kept. The
+// new container is started if the removed one was running.
+func (r *Runtime) recreateContainer(ctx context.Context, ctr *Container, imageID string) (*Container, error) {
+	state, err := ctr.State()
+	if err != nil {
+		return nil, err
+	}
+	config := new(ContainerConfig)
+	if err := JSONDeepCopy(ctr.config, config); err != nil {
+		return nil, errors.Wrapf(err, "error copying configuration of container %s", ctr.ID())
+	}
+	config.RootfsImageID = imageID
+	config.CreatedTime = time.Now()
+	// Automatic user namespaces are allocated again
+	if config.AutoUserNS {
+		config.IDMappings.UIDMap = nil
+		config.IDMappings.GIDMap = nil
+	}
+
+	if err := r.RemoveContainer(ctx, ctr, true, false); err != nil {
+		return nil, err
+	}
+
+	r.lock.Lock()
+	newCtr := &Container{
+		config:  config,
+		state:   &ContainerState{BindMounts: make(map[string]string)},
+		runtime: r,
+	}
+	newCtr, err = r.setupContainer(ctx, newCtr)
+	r.lock.Unlock()
+	if err != nil {
+		return nil, errors.Wrapf(err, "container %s was removed but could not be created again", config.ID)
+	}
+
+	if state == define.ContainerStateRunning {
+		if err := newCtr.Start(ctx, true); err != nil {
+			return newCtr, err
+		}
+	}
+	return newCtr, nil
+}
+
+// recordAutoUpdate records the time the container was last checked for
+// updates, and updated if it was
+func (c *Container) recordAutoUpdate(now time.Time, updated bool) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.syncContainer(); err != nil {
+		return err
+	}
+	c.state.AutoUpdateLastCheck = now
+	if updated {
+		c.state.AutoUpdateLastUpdate = now
+	}
+	return c.save()
+}
@@ -0,0 +1,31 @@
+package libpod
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// restartSystemdUnit restarts the systemd unit and waits for the restart to
+// complete
+func restartSystemdUnit(ctx context.Context, unit string) error {
+	conn, err := getConnection()
+	if err != nil {
+		return errors.Wrapf(err, "error connecting to systemd")
+	}
+	defer conn.Close()
+
+	done := make(chan string, 1)
+	if _, err := conn.RestartUnit(unit, "replace", done); err != nil {
+		return err
+	}
+	select {
+	case result := <-done:
+		if result != "done" {
+			return errors.Errorf("restart job of unit %s finished with result %q", unit, result)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,143 @@
+package libpod
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/containers/image/docker/reference"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoUpdateReference(t *testing.T) {
+	c := Container{config: &ContainerConfig{}}
+	for _, tc := range []struct {
+		policy, image, expected string
+	}{
+		{"registry", "quay.io/podman/stable", "quay.io/podman/stable:latest"},
+		{"registry", "docker.io/library/alpine:3.10", "docker.io/library/alpine:3.10"},
+		{"registry", "alpine", ""},
+		{"registry", "", ""},
+		{"image", "quay.io/podman/stable:latest", ""},
+	} {
+		c.config.Labels = map[string]string{AutoUpdateLabel: tc.policy}
+		c.config.RootfsImageName = tc.image
+		named, err := autoUpdateReference(&c)
+		if tc.expected == "" {
+			assert.Error(t, err, tc.image)
+			continue
+		}
+		if assert.NoError(t, err, tc.image) {
+			assert.Equal(t, tc.expected, named.String())
+		}
+	}
+}
+
+func TestAutoUpdate(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	runtime := &Runtime{
+		config:  &RuntimeConfig{},
+		state:   state,
+		eventer: &recordingEventer{},
+		valid:   true,
+	}
+
+	ctrs := make(map[string]*Container)
+	for _, tc := range []struct {
+		name, image, imageID, unit string
+	}{
+		// Run by a unit creating a new container at every start
+		{"1", "quay.io/test/a:latest", "old-a", "new.service"},
+		// Run by a unit restarting the same container
+		{"2", "quay.io/test/b:latest", "old-b", "same.service"},
+		// Not run by a unit
+		{"3", "quay.io/test/a:latest", "old-a", ""},
+		// Already running the newest image
+		{"4", "quay.io/test/c:latest", "c", ""},
+	} {
+		ctr, err := getTestCtrN(tc.name, manager)
+		require.NoError(t, err)
+		ctr.runtime = runtime
+		// Exited containers are not synced with the OCI runtime
+		ctr.state.State = define.ContainerStateExited
+		ctr.config.RootfsImageName = tc.image
+		ctr.config.RootfsImageID = tc.imageID
+		ctr.config.Labels[AutoUpdateLabel] = AutoUpdatePolicyRegistry
+		if tc.unit != "" {
+			ctr.config.Labels[SystemdUnitLabel] = tc.unit
+		}
+		require.NoError(t, state.AddContainer(ctr))
+		ctrs[tc.name] = ctr
+	}
+
+	remote := map[string]string{
+		"quay.io/test/a:latest": "new-a",
+		"quay.io/test/b:latest": "new-b",
+		"quay.io/test/c:latest": "c",
+	}
+	local := map[string]string{
+		"quay.io/test/a:latest": "old-a",
+		"quay.io/test/b:latest": "old-b",
+		"quay.io/test/c:latest": "c",
+	}
+	var pulled, restarted, recreated []string
+	u := &autoUpdater{
+		runtime: runtime,
+		remoteImageID: func(ctx context.Context, named reference.Named) (string, error) {
+			return remote[named.String()], nil
+		},
+		localImageID: func(name string) (string, error) {
+			return local[name], nil
+		},
+		pullImage: func(ctx context.Context, name string) (string, error) {
+			pulled = append(pulled, name)
+			local[name] = remote[name]
+			return local[name], nil
+		},
+		restartUnit: func(ctx context.Context, unit string) error {
+			restarted = append(restarted, unit)
+			if unit == "new.service" {
+				ctrs["1"].config.RootfsImageID = local[ctrs["1"].config.RootfsImageName]
+			}
+			return nil
+		},
+		recreate: func(ctx context.Context, ctr *Container, imageID string) (*Container, error) {
+			recreated = append(recreated, ctr.Name())
+			ctr.config.RootfsImageID = imageID
+			return ctr, nil
+		},
+		newestImages: make(map[string]string),
+	}
+
+	reports, errs := u.run(context.Background())
+	assert.ElementsMatch(t, []string{"quay.io/test/a:latest", "quay.io/test/b:latest"}, pulled)
+	assert.ElementsMatch(t, []string{"new.service", "same.service"}, restarted)
+	assert.Equal(t, []string{"test3"}, recreated)
+
+	updated := make(map[string]bool)
+	for _, report := range reports {
+		updated[report.ContainerID] = report.Updated
+	}
+	assert.Equal(t, map[string]bool{
+		ctrs["1"].ID(): true,
+		ctrs["2"].ID(): false,
+		ctrs["3"].ID(): true,
+		ctrs["4"].ID(): false,
+	}, updated)
+
+	// The unit restarting the same container still runs the previous image
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "same.service")
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(errs[0]))
+
+	for name, updated := range map[string]bool{"1": true, "2": false, "3": true, "4": false} {
+		ctr := ctrs[name]
+		assert.False(t, ctr.state.AutoUpdateLastCheck.IsZero(), name)
+		assert.Equal(t, updated, !ctr.state.AutoUpdateLastUpdate.IsZero(), name)
+	}
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package libpod
+
+import (
+	"context"
+
+	"github.com/containers/libpod/libpod/define"
+)
+
+func restartSystemdUnit(ctx context.Context, unit string) error {
+	return define.ErrOSNotSupported
+}
@@ -0,0 +1,206 @@
+// +build linux
+
+package libpod
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// binfmtMiscDir is where binfmt_misc handlers are listed and registered.
+const binfmtMiscDir = "/proc/sys/fs/binfmt_misc"
+
+// qemuBinfmt describes the binfmt_misc handler for the qemu user-mode emulator
+// of an architecture, matching the ELF headers of its binaries.
+type qemuBinfmt struct {
+	// qemuArch is the name qemu uses for the architecture.
+	qemuArch string
+	magic    string
+	mask     string
+}
+
+// qemuBinfmts maps image architectures to their qemu emulators, as registered
+// by qemu-binfmt-conf.sh.
+var qemuBinfmts = map[string]qemuBinfmt{
+	"amd64": {
+		qemuArch: "x86_64",
+		magic:    `\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x3e\x00`,
+		mask:     `\xff\xff\xff\xff\xff\xfe\xfe\x00\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff\xff`,
+	},
+	"arm": {
+		qemuArch: "arm",
+		magic:    `\x7fELF\x01\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x28\x00`,
+		mask:     `\xff\xff\xff\xff\xff\xff\xff\x00\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff\xff`,
+	},
+	"arm64": {
+		qemuArch: "aarch64",
+		magic:    `\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\xb7\x00`,
+		mask:     `\xff\xff\xff\xff\xff\xff\xff\x00\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff\xff`,
+	},
+	"ppc64le": {
+		qemuArch: "ppc64le",
+		magic:    `\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x15\x00`,
+		mask:     `\xff\xff\xff\xff\xff\xff\xff\xfc\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff\x00`,
+	},
+	"riscv64": {
+		qemuArch: "riscv64",
+		magic:    `\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\xf3\x00`,
+		mask:     `\xff\xff\xff\xff\xff\xff\xff\x00\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff\xff`,
+	},
+	"s390x": {
+		qemuArch: "s390x",
+		magic:    `\x7fELF\x02\x02\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x16`,
+		mask:     `\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff`,
+	},
+}
+
+// archRunsNatively returns whether binaries for the given architecture can be
+// run by the host without emulation.
+func archRunsNatively(arch string) bool {
+	if arch == "" || arch == goruntime.GOARCH {
+		return true
+	}
+	switch goruntime.GOARCH {
+	case "amd64":
+		return arch == "386"
+	case "arm64":
+		return arch == "arm"
+	}
+	return false
+}
+
+// findBinfmtHandler returns the name of an enabled binfmt_misc handler running
+// binaries with the qemu emulator for the given qemu architecture, or "" if
+// there is none.
+func findBinfmtHandler(qemuArch string) (string, error) {
+	entries, err := ioutil.ReadDir(binfmtMiscDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "error reading binfmt_misc handlers in %s", binfmtMiscDir)
+	}
+
+	emulator := "qemu-" + qemuArch
+	for _, entry := range entries {
+		if entry.Name() == "register" || entry.Name() == "status" {
+			continue
+		}
+		contents, err := ioutil.ReadFile(filepath.Join(binfmtMiscDir, entry.Name()))
+		if err != nil {
+			logrus.Debugf("Unable to read binfmt_misc handler %s: %v", entry.Name(), err)
+			continue
+		}
+
+		enabled := false
+		interpreter := ""
+		for _, line := range strings.Split(string(contents), "\n") {
+			switch {
+			case line == "enabled":
+				enabled = true
+			case strings.HasPrefix(line, "interpreter "):
+				interpreter = filepath.Base(strings.TrimPrefix(line, "interpreter "))
+			}
+		}
+		if !enabled {
+			continue
+		}
+		if entry.Name() == emulator || interpreter == emulator || interpreter == emulator+"-static" {
+			return entry.Name(), nil
+		}
+	}
+
+	return "", nil
+}
+
+// registerBinfmtHandler registers a binfmt_misc handler for the qemu emulator
+// of the given architecture. The emulator is looked up in $PATH, preferring
+// statically linked builds, and is opened at registration so that it can be
+// used from within containers.
+func registerBinfmtHandler(binfmt qemuBinfmt) error {
+	emulator := "qemu-" + binfmt.qemuArch
+	interpreter, err := exec.LookPath(emulator + "-static")
+	if err != nil {
+		interpreter, err = exec.LookPath(emulator)
+		if err != nil {
+			return errors.Wrapf(define.ErrArchNotSupported, "no %s emulator found in $PATH", emulator)
+		}
+	}
+
+	registerFile := filepath.Join(binfmtMiscDir, "register")
+	f, err := os.OpenFile(registerFile, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrapf(err, "error opening %s, is binfmt_misc mounted?", registerFile)
+	}
+	defer f.Close()
+
+	rule := fmt.Sprintf(":%s:M::%s:%s:%s:F", emulator, binfmt.magic, binfmt.mask, interpreter)
+	if _, err := f.WriteString(rule); err != nil {
+		return errors.Wrapf(err, "error registering binfmt_misc handler for %s", interpreter)
+	}
+	logrus.Infof("Registered binfmt_misc handler %s for %s", emulator, interpreter)
+
+	return nil
+}
+
+// checkArchitecture verifies that the host can run binaries for the
+// architecture of the container's image. Foreign architectures require a
+// binfmt_misc handler for a qemu emulator. If there is none, one is registered
+// when the runtime is configured to do so and we are root; otherwise an error
+// is returned.
+func (c *Container) checkArchitecture(ctx context.Context) error {
+	if c.config.RootfsImageID == "" {
+		return nil
+	}
+
+	img, err := c.runtime.imageRuntime.NewFromLocal(c.config.RootfsImageID)
+	if err != nil {
+		// The image may have been removed; the runtime will report any
+		// problem running the container
+		logrus.Debugf("Unable to retrieve image %s of container %s to check its architecture: %v", c.config.RootfsImageID, c.ID(), err)
+		return nil
+	}
+	arch, err := img.Architecture(ctx)
+	if err != nil {
+		logrus.Debugf("Unable to retrieve architecture of image %s: %v", c.config.RootfsImageID, err)
+		return nil
+	}
+
+	if archRunsNatively(arch) {
+		return nil
+	}
+
+	binfmt, ok := qemuBinfmts[arch]
+	if !ok {
+		return errors.Wrapf(define.ErrArchNotSupported, "container %s uses an image for architecture %s, which cannot be run or emulated on this %s host", c.ID(), arch, goruntime.GOARCH)
+	}
+
+	handler, err := findBinfmtHandler(binfmt.qemuArch)
+	if err != nil {
+		return err
+	}
+	if handler != "" {
+		logrus.Debugf("Container %s for architecture %s will be emulated using binfmt_misc handler %s", c.ID(), arch, handler)
+		return nil
+	}
+
+	if c.runtime.config.BinfmtRegister && !rootless.IsRootless() {
+		if err := registerBinfmtHandler(binfmt); err != nil {
+			return errors.Wrapf(err, "error setting up emulation of architecture %s for container %s", arch, c.ID())
+		}
+		return nil
+	}
+
+	return errors.Wrapf(define.ErrArchNotSupported, "container %s uses an image for architecture %s, but no binfmt_misc handler for qemu-%s is registered on this %s host; install a qemu user-mode emulator with binfmt_misc support, or set binfmt_register in libpod.conf", c.ID(), arch, binfmt.qemuArch, goruntime.GOARCH)
+}
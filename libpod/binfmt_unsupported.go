@@ -0,0 +1,10 @@
+// +build !linux
+
+package libpod
+
+import "context"
+
+// checkArchitecture is a no-op on this platform.
+func (c *Container) checkArchitecture(ctx context.Context) error {
+	return nil
+}
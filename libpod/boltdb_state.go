@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/pkg/idtools"
 	bolt "github.com/etcd-io/bbolt"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
@@ -19,9 +21,12 @@ type BoltState struct {
 	valid          bool
 	dbPath         string
 	dbLock         sync.Mutex
+	dbOpStart      time.Time
 	namespace      string
 	namespaceBytes []byte
 	runtime        *Runtime
+	dbOptions      *bolt.Options
+	syncMode       string
 }
 
 // A brief description of the format of the BoltDB state:
@@ -37,8 +42,11 @@ type BoltState struct {
 //   Each sub-bucket has config and state keys holding the container's JSON
 //   encoded configuration and state (respectively), an optional netNS key
 //   containing the path to the container's network namespace, a dependencies
-//   bucket containing the container's dependencies, and an optional pod key
-//   containing the ID of the pod the container is joined to.
+//   bucket containing the container's dependencies, a dependency-conditions
+//   bucket holding the conditions the containers depending on the container
+//   wait for it to meet, an optional pod key
+//   containing the ID of the pod the container is joined to, and a summary key
+//   holding a JSON encoded ContainerSummary, updated whenever the state is.
 // - allCtrsBkt: Map of ID to name containing only containers. Used for
 //   container lookup operations.
 // - podBkt: Contains a sub-bucket for each pod in the state.
@@ -47,10 +55,41 @@ type BoltState struct {
 //   containers in the pod.
 // - allPodsBkt: Map of ID to name containing only pods. Used for pod lookup
 //   operations.
+// - volBkt: Contains a sub-bucket for each volume in the state.
+//   Each sub-bucket has config and state keys holding the volume's JSON
+//   encoded configuration and state, plus a vol-dependencies sub bucket
+//   holding the IDs of containers using the volume.
+// - allVolsBkt: Map of name to name containing all volumes.
+// - netBkt: Contains a sub-bucket for each network in the state.
+//   Each sub-bucket has a config key holding the network's JSON encoded
+//   configuration, plus a net-dependencies sub bucket holding the IDs of
+//   containers joined to the network.
+// - allNetsBkt: Map of name to name containing all networks.
+// - podTemplateBkt: Map of name to the JSON encoded pod template of that name.
 // - runtimeConfigBkt: Contains configuration of the libpod instance that
 //   initially created the database. This must match for any further instances
 //   that access the database, to ensure that state mismatches with
 //   containers/storage do not occur.
+// - pendingRemovalBkt: Map of ID to the time it was queued for containers
+//   that must be removed once they stop, such as --rm containers. Entries are
+//   deleted with their containers.
+// - ipamBkt: Contains a sub-bucket for each CNI network on which containers
+//   requested static addresses. Each maps a static IP or MAC address to the ID
+//   of the container that reserved it. Reservations are made when containers
+//   are added and released when they are removed, so conflicting addresses
+//   are rejected at creation even when CNI's own leases have been lost.
+// - execBkt: Map of exec session ID to the ID of the container the session
+//   runs in, so sessions can be found by processes other than the one that
+//   started them. The sessions themselves are part of the container's state.
+//   Entries are deleted with their containers, and all of them on refresh.
+// - exitCodeBkt: Map of container ID to the JSON encoded exit code of the
+//   container and the time it was recorded. Entries outlive their containers,
+//   so the exit codes of removed containers can still be retrieved, and are
+//   pruned once they are old enough.
+// - usernsBkt: Map of container ID to the JSON encoded range of host UIDs and
+//   GIDs allocated to the container for its automatic user namespace.
+//   Entries are added before their containers, so the IDs can be mapped in
+//   their storage, and are deleted when the containers are removed.
 
 // NewBoltState creates a new bolt-backed state database
 func NewBoltState(path string, runtime *Runtime) (State, error) {
@@ -62,7 +101,18 @@ func NewBoltState(path string, runtime *Runtime) (State, error) {
 
 	logrus.Debugf("Initializing boltdb state at %s", path)
 
-	db, err := bolt.Open(path, 0600, nil)
+	options, err := boltOptions(runtime.config)
+	if err != nil {
+		return nil, err
+	}
+	state.dbOptions = options
+	state.syncMode = runtime.config.StateSyncMode
+
+	// The buckets are always synced to disk when they are created
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		FreelistType:    options.FreelistType,
+		InitialMmapSize: options.InitialMmapSize,
+	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "error opening database %s", path)
 	}
@@ -84,7 +134,15 @@ func NewBoltState(path string, runtime *Runtime) (State, error) {
 		allPodsBkt,
 		volBkt,
 		allVolsBkt,
+		netBkt,
+		allNetsBkt,
+		podTemplateBkt,
 		runtimeConfigBkt,
+		pendingRemovalBkt,
+		ipamBkt,
+		execBkt,
+		exitCodeBkt,
+		usernsBkt,
 	}
 
 	// Does the DB need an update?
@@ -131,7 +189,7 @@ func (s *BoltState) Close() error {
 	return nil
 }
 
-// Refresh clears container and pod states after a reboot
+// Refresh clears container, pod, and volume states after a reboot
 func (s *BoltState) Refresh() error {
 	if !s.valid {
 		return define.ErrDBClosed
@@ -159,6 +217,16 @@ func (s *BoltState) Refresh() error {
 			return err
 		}
 
+		allVolsBucket, err := getAllVolsBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		volBucket, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+
 		// Iterate through all IDs. Check if they are containers.
 		// If they are, unmarshal their state, and then clear
 		// PID, mountpoint, and state for all of them
@@ -233,6 +301,75 @@ func (s *BoltState) Refresh() error {
 				return errors.Wrapf(err, "error updating state for container %s in DB", string(id))
 			}
 
+			// Update the state in the container's summary, if it
+			// has one
+			summaryBytes := ctrBkt.Get(summaryKey)
+			if summaryBytes != nil {
+				summary := new(ContainerSummary)
+				if err := json.Unmarshal(summaryBytes, summary); err != nil {
+					return errors.Wrapf(err, "error unmarshalling summary for container %s", string(id))
+				}
+
+				summary.State = state.State
+
+				newSummaryBytes, err := json.Marshal(summary)
+				if err != nil {
+					return errors.Wrapf(err, "error marshalling modified summary for container %s", string(id))
+				}
+
+				if err := ctrBkt.Put(summaryKey, newSummaryBytes); err != nil {
+					return errors.Wrapf(err, "error updating summary for container %s in DB", string(id))
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Exec sessions do not survive a reboot
+		if err := tx.DeleteBucket(execBkt); err != nil {
+			return errors.Wrapf(err, "error removing exec sessions bucket")
+		}
+		if _, err := tx.CreateBucket(execBkt); err != nil {
+			return errors.Wrapf(err, "error recreating exec sessions bucket")
+		}
+
+		// Now refresh volumes
+		err = allVolsBucket.ForEach(func(id, name []byte) error {
+			dbVol := volBucket.Bucket(id)
+			if dbVol == nil {
+				return errors.Wrapf(define.ErrInternal, "inconsistency in state - volume %s is in all volumes bucket but volume not found", string(id))
+			}
+
+			// Get the state
+			volStateBytes := dbVol.Get(stateKey)
+			if volStateBytes == nil {
+				// If the volume doesn't have a state, nothing to do
+				return nil
+			}
+
+			oldState := new(VolumeState)
+
+			if err := json.Unmarshal(volStateBytes, oldState); err != nil {
+				return errors.Wrapf(err, "error unmarshalling state for volume %s", string(id))
+			}
+
+			// Reset mount count and mountpoint, the volume is no
+			// longer mounted anywhere after a reboot.
+			oldState.MountCount = 0
+			oldState.MountPoint = ""
+
+			newState, err := json.Marshal(oldState)
+			if err != nil {
+				return errors.Wrapf(err, "error marshalling state for volume %s", string(id))
+			}
+
+			if err := dbVol.Put(stateKey, newState); err != nil {
+				return errors.Wrapf(err, "error storing new state for volume %s", string(id))
+			}
+
 			return nil
 		})
 		return err
@@ -308,6 +445,35 @@ func (s *BoltState) ValidateDBConfig(runtime *Runtime) error {
 	return nil
 }
 
+// RewriteGraphDriver replaces the graph driver recorded in the database.
+// This function is DANGEROUS.
+// Please read the full comment on it in state.go before using it.
+func (s *BoltState) RewriteGraphDriver(driver string) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		configBkt, err := getRuntimeConfigBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := configBkt.Put(graphDriverKey, []byte(driver)); err != nil {
+			return errors.Wrapf(err, "error updating storage graph driver in DB runtime config")
+		}
+
+		return nil
+	})
+	return err
+}
+
 // SetNamespace sets the namespace that will be used for container and pod
 // retrieval
 func (s *BoltState) SetNamespace(ns string) error {
@@ -545,7 +711,7 @@ func (s *BoltState) RemoveContainer(ctr *Container) error {
 		return errors.Wrapf(define.ErrPodExists, "container %s is part of a pod, use RemoveContainerFromPod instead", ctr.ID())
 	}
 
-	db, err := s.getDBCon()
+	db, err := s.getDBConSync()
 	if err != nil {
 		return err
 	}
@@ -557,6 +723,106 @@ func (s *BoltState) RemoveContainer(ctr *Container) error {
 	return err
 }
 
+// RemoveContainers removes the given containers from the state in a single
+// transaction
+// Containers in pods will also be removed from their pods
+func (s *BoltState) RemoveContainers(ctrs []*Container) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if len(ctrs) == 0 {
+		return nil
+	}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		ctrBkt, err := getCtrBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		podBkt, err := getPodBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		toRemove := make(map[string]bool, len(ctrs))
+		for _, ctr := range ctrs {
+			toRemove[ctr.ID()] = true
+		}
+
+		// Drop dependency edges between the containers being removed,
+		// so they can be removed in any order.
+		// Any edges that remain are from containers we were not asked
+		// to remove, and will cause removeContainer to fail.
+		for _, ctr := range ctrs {
+			ctrDB := ctrBkt.Bucket([]byte(ctr.ID()))
+			if ctrDB == nil {
+				ctr.valid = false
+				return errors.Wrapf(define.ErrNoSuchCtr, "no container with ID %s found in DB", ctr.ID())
+			}
+
+			ctrDepsBkt := ctrDB.Bucket(dependenciesBkt)
+			if ctrDepsBkt == nil {
+				return errors.Wrapf(define.ErrInternal, "container %s does not have a dependencies bucket", ctr.ID())
+			}
+
+			internalDeps := [][]byte{}
+			err := ctrDepsBkt.ForEach(func(id, value []byte) error {
+				if toRemove[string(id)] {
+					internalDeps = append(internalDeps, id)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, id := range internalDeps {
+				if err := ctrDepsBkt.Delete(id); err != nil {
+					return errors.Wrapf(err, "error removing container %s as a dependency of container %s", string(id), ctr.ID())
+				}
+				if err := deleteDependencyCondition(ctrDB, id); err != nil {
+					return errors.Wrapf(err, "error removing condition of container %s on container %s", string(id), ctr.ID())
+				}
+			}
+		}
+
+		pods := make(map[string]*Pod)
+		for _, ctr := range ctrs {
+			var pod *Pod
+			if ctr.config.Pod != "" {
+				var ok bool
+				pod, ok = pods[ctr.config.Pod]
+				if !ok {
+					pod = new(Pod)
+					pod.config = new(PodConfig)
+					pod.state = new(podState)
+
+					if err := s.getPodFromDB([]byte(ctr.config.Pod), pod, podBkt); err != nil {
+						return errors.Wrapf(err, "error retrieving pod %s of container %s", ctr.config.Pod, ctr.ID())
+					}
+
+					pods[ctr.config.Pod] = pod
+				}
+			}
+
+			if err := s.removeContainer(ctr, pod, tx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	return err
+}
+
 // UpdateContainer updates a container's state from the database
 func (s *BoltState) UpdateContainer(ctr *Container) error {
 	if !s.valid {
@@ -643,6 +909,10 @@ func (s *BoltState) SaveContainer(ctr *Container) error {
 	if err != nil {
 		return errors.Wrapf(err, "error marshalling container %s state to JSON", ctr.ID())
 	}
+	summaryJSON, err := json.Marshal(ctr.summary())
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling container %s summary to JSON", ctr.ID())
+	}
 	netNSPath := getNetNSPath(ctr)
 
 	ctrID := []byte(ctr.ID())
@@ -670,6 +940,10 @@ func (s *BoltState) SaveContainer(ctr *Container) error {
 			return errors.Wrapf(err, "error updating container %s state in DB", ctr.ID())
 		}
 
+		if err := ctrToSave.Put(summaryKey, summaryJSON); err != nil {
+			return errors.Wrapf(err, "error updating container %s summary in DB", ctr.ID())
+		}
+
 		if netNSPath != "" {
 			if err := ctrToSave.Put(netNSKey, []byte(netNSPath)); err != nil {
 				return errors.Wrapf(err, "error updating network namespace path for container %s in DB", ctr.ID())
@@ -747,6 +1021,67 @@ func (s *BoltState) ContainerInUse(ctr *Container) ([]string, error) {
 
 }
 
+// ContainerDependencyConditions returns the conditions that the containers
+// depending on the given container wait for it to meet
+func (s *BoltState) ContainerDependencyConditions(ctr *Container) (map[string]define.DependencyCondition, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	if !ctr.valid {
+		return nil, define.ErrCtrRemoved
+	}
+
+	if s.namespace != "" && s.namespace != ctr.config.Namespace {
+		return nil, errors.Wrapf(define.ErrNSMismatch, "container %s is in namespace %q, does not match our namespace %q", ctr.ID(), ctr.config.Namespace, s.namespace)
+	}
+
+	conditions := make(map[string]define.DependencyCondition)
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		ctrBucket, err := getCtrBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		ctrDB := ctrBucket.Bucket([]byte(ctr.ID()))
+		if ctrDB == nil {
+			ctr.valid = false
+			return errors.Wrapf(define.ErrNoSuchCtr, "no container with ID %s found in DB", ctr.ID())
+		}
+
+		dependsBkt := ctrDB.Bucket(dependenciesBkt)
+		if dependsBkt == nil {
+			return errors.Wrapf(define.ErrInternal, "container %s has no dependencies bucket", ctr.ID())
+		}
+		// Missing for containers depended on before conditions were
+		// recorded
+		conditionsBkt := ctrDB.Bucket(depConditionsBkt)
+
+		return dependsBkt.ForEach(func(id, value []byte) error {
+			condition := define.DependencyConditionRunning
+			if conditionsBkt != nil {
+				if recorded := conditionsBkt.Get(id); recorded != nil {
+					condition = define.DependencyCondition(recorded)
+				}
+			}
+			conditions[string(id)] = condition
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conditions, nil
+}
+
 // AllContainers retrieves all the containers in the database
 func (s *BoltState) AllContainers() ([]*Container, error) {
 	if !s.valid {
@@ -812,67 +1147,83 @@ func (s *BoltState) AllContainers() ([]*Container, error) {
 	return ctrs, nil
 }
 
-// RewriteContainerConfig rewrites a container's configuration.
-// WARNING: This function is DANGEROUS. Do not use without reading the full
-// comment on this function in state.go.
-func (s *BoltState) RewriteContainerConfig(ctr *Container, newCfg *ContainerConfig) error {
+// AllContainersSummaries returns summaries of all containers in the state
+// Containers are not locked or fully retrieved from the database
+func (s *BoltState) AllContainersSummaries() ([]*ContainerSummary, error) {
 	if !s.valid {
-		return define.ErrDBClosed
-	}
-
-	if !ctr.valid {
-		return define.ErrCtrRemoved
+		return nil, define.ErrDBClosed
 	}
 
-	newCfgJSON, err := json.Marshal(newCfg)
-	if err != nil {
-		return errors.Wrapf(err, "error marshalling new configuration JSON for container %s", ctr.ID())
-	}
+	summaries := []*ContainerSummary{}
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer s.deferredCloseDBCon(db)
 
-	err = db.Update(func(tx *bolt.Tx) error {
-		ctrBkt, err := getCtrBucket(tx)
+	err = db.View(func(tx *bolt.Tx) error {
+		allCtrsBucket, err := getAllCtrsBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		ctrDB := ctrBkt.Bucket([]byte(ctr.ID()))
-		if ctrDB == nil {
-			ctr.valid = false
-			return errors.Wrapf(define.ErrNoSuchCtr, "no container with ID %s found in DB", ctr.ID())
+		ctrBucket, err := getCtrBucket(tx)
+		if err != nil {
+			return err
 		}
 
-		if err := ctrDB.Put(configKey, newCfgJSON); err != nil {
-			return errors.Wrapf(err, "error updating container %s config JSON", ctr.ID())
-		}
+		return allCtrsBucket.ForEach(func(id, name []byte) error {
+			ctrDB := ctrBucket.Bucket(id)
+			if ctrDB == nil {
+				return errors.Wrapf(define.ErrInternal, "state is inconsistent - container ID %s in all containers, but container not found", string(id))
+			}
 
-		return nil
+			summary, err := getContainerSummaryFromDB(id, ctrDB)
+			if err != nil {
+				// As with AllContainers, don't fail over a
+				// single bad container
+				logrus.Errorf("Error retrieving container %s summary from the database: %v", string(id), err)
+				return nil
+			}
+
+			if s.namespace != "" && s.namespace != summary.Namespace {
+				return nil
+			}
+
+			summaries = append(summaries, summary)
+
+			return nil
+		})
 	})
-	return err
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
 }
 
-// RewritePodConfig rewrites a pod's configuration.
-// WARNING: This function is DANGEROUS. Do not use without reading the full
-// comment on this function in state.go.
-func (s *BoltState) RewritePodConfig(pod *Pod, newCfg *PodConfig) error {
+// AddPendingRemoval queues a container for removal once it stops
+func (s *BoltState) AddPendingRemoval(ctr *Container) error {
 	if !s.valid {
 		return define.ErrDBClosed
 	}
 
-	if !pod.valid {
-		return define.ErrPodRemoved
+	if !ctr.valid {
+		return define.ErrCtrRemoved
 	}
 
-	newCfgJSON, err := json.Marshal(newCfg)
+	if s.namespace != "" && s.namespace != ctr.config.Namespace {
+		return errors.Wrapf(define.ErrNSMismatch, "container %s is in namespace %q, does not match our namespace %q", ctr.ID(), ctr.config.Namespace, s.namespace)
+	}
+
+	queued, err := time.Now().MarshalText()
 	if err != nil {
-		return errors.Wrapf(err, "error marshalling new configuration JSON for pod %s", pod.ID())
+		return errors.Wrapf(err, "error marshalling time container %s was queued for removal", ctr.ID())
 	}
 
+	ctrID := []byte(ctr.ID())
+
 	db, err := s.getDBCon()
 	if err != nil {
 		return err
@@ -880,43 +1231,103 @@ func (s *BoltState) RewritePodConfig(pod *Pod, newCfg *PodConfig) error {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.Update(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+		ctrBucket, err := getCtrBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		podDB := podBkt.Bucket([]byte(pod.ID()))
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "no pod with ID %s found in DB", pod.ID())
+		pendingRemovalBucket, err := getPendingRemovalBucket(tx)
+		if err != nil {
+			return err
 		}
 
-		if err := podDB.Put(configKey, newCfgJSON); err != nil {
-			return errors.Wrapf(err, "error updating pod %s config JSON", pod.ID())
+		if ctrBucket.Bucket(ctrID) == nil {
+			ctr.valid = false
+			return errors.Wrapf(define.ErrNoSuchCtr, "container %s does not exist in DB", ctr.ID())
 		}
 
-		return nil
+		// Keep the time the container was first queued
+		if pendingRemovalBucket.Get(ctrID) != nil {
+			return nil
+		}
+
+		if err := pendingRemovalBucket.Put(ctrID, queued); err != nil {
+			return errors.Wrapf(err, "error queueing container %s for removal in DB", ctr.ID())
+		}
+
+		return nil
 	})
 	return err
 }
 
-// RewriteVolumeConfig rewrites a volume's configuration.
-// WARNING: This function is DANGEROUS. Do not use without reading the full
-// comment on this function in state.go.
-func (s *BoltState) RewriteVolumeConfig(volume *Volume, newCfg *VolumeConfig) error {
+// PendingRemovals returns the containers queued for removal and the times
+// they were queued
+func (s *BoltState) PendingRemovals() (map[string]time.Time, error) {
 	if !s.valid {
-		return define.ErrDBClosed
+		return nil, define.ErrDBClosed
 	}
 
-	if !volume.valid {
-		return define.ErrVolumeRemoved
+	pending := make(map[string]time.Time)
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
 	}
+	defer s.deferredCloseDBCon(db)
 
-	newCfgJSON, err := json.Marshal(newCfg)
+	err = db.View(func(tx *bolt.Tx) error {
+		pendingRemovalBucket, err := getPendingRemovalBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		nsBucket, err := getNSBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		return pendingRemovalBucket.ForEach(func(id, value []byte) error {
+			if s.namespace != "" && s.namespace != string(nsBucket.Get(id)) {
+				return nil
+			}
+
+			var queued time.Time
+			if err := queued.UnmarshalText(value); err != nil {
+				logrus.Errorf("Error parsing time container %s was queued for removal: %v", string(id), err)
+			}
+			pending[string(id)] = queued
+
+			return nil
+		})
+	})
 	if err != nil {
-		return errors.Wrapf(err, "error marshalling new configuration JSON for volume %q", volume.Name())
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// AddExecSession registers an exec session of a container
+func (s *BoltState) AddExecSession(ctr *Container, session *ExecSession) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !ctr.valid {
+		return define.ErrCtrRemoved
+	}
+
+	if session.ID == "" {
+		return errors.Wrapf(define.ErrEmptyID, "exec session must have an ID")
+	}
+
+	if s.namespace != "" && s.namespace != ctr.config.Namespace {
+		return errors.Wrapf(define.ErrNSMismatch, "container %s is in namespace %q, does not match our namespace %q", ctr.ID(), ctr.config.Namespace, s.namespace)
 	}
 
+	ctrID := []byte(ctr.ID())
+	sessionID := []byte(session.ID)
+
 	db, err := s.getDBCon()
 	if err != nil {
 		return err
@@ -924,19 +1335,27 @@ func (s *BoltState) RewriteVolumeConfig(volume *Volume, newCfg *VolumeConfig) er
 	defer s.deferredCloseDBCon(db)
 
 	err = db.Update(func(tx *bolt.Tx) error {
-		volBkt, err := getVolBucket(tx)
+		ctrBucket, err := getCtrBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		volDB := volBkt.Bucket([]byte(volume.Name()))
-		if volDB == nil {
-			volume.valid = false
-			return errors.Wrapf(define.ErrNoSuchVolume, "no volume with name %q found in DB", volume.Name())
+		execBucket, err := getExecBucket(tx)
+		if err != nil {
+			return err
 		}
 
-		if err := volDB.Put(configKey, newCfgJSON); err != nil {
-			return errors.Wrapf(err, "error updating volume %q config JSON", volume.Name())
+		if ctrBucket.Bucket(ctrID) == nil {
+			ctr.valid = false
+			return errors.Wrapf(define.ErrNoSuchCtr, "container %s does not exist in DB", ctr.ID())
+		}
+
+		if execBucket.Get(sessionID) != nil {
+			return errors.Wrapf(define.ErrExecSessionExists, "an exec session with ID %s already exists", session.ID)
+		}
+
+		if err := execBucket.Put(sessionID, ctrID); err != nil {
+			return errors.Wrapf(err, "error adding exec session %s of container %s to DB", session.ID, ctr.ID())
 		}
 
 		return nil
@@ -944,281 +1363,269 @@ func (s *BoltState) RewriteVolumeConfig(volume *Volume, newCfg *VolumeConfig) er
 	return err
 }
 
-// Pod retrieves a pod given its full ID
-func (s *BoltState) Pod(id string) (*Pod, error) {
+// ExecSessionContainer returns the ID of the container an exec session runs in
+func (s *BoltState) ExecSessionContainer(id string) (string, error) {
 	if id == "" {
-		return nil, define.ErrEmptyID
+		return "", define.ErrEmptyID
 	}
 
 	if !s.valid {
-		return nil, define.ErrDBClosed
+		return "", define.ErrDBClosed
 	}
 
-	podID := []byte(id)
-
-	pod := new(Pod)
-	pod.config = new(PodConfig)
-	pod.state = new(podState)
+	sessionID := []byte(id)
+	var ctrID string
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer s.deferredCloseDBCon(db)
 
 	err = db.View(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+		execBucket, err := getExecBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		return s.getPodFromDB(podID, pod, podBkt)
+		nsBucket, err := getNSBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		ctrIDBytes := execBucket.Get(sessionID)
+		if ctrIDBytes == nil {
+			return errors.Wrapf(define.ErrNoSuchExecSession, "no exec session with ID %s found", id)
+		}
+
+		if s.namespace != "" && s.namespace != string(nsBucket.Get(ctrIDBytes)) {
+			return errors.Wrapf(define.ErrNoSuchExecSession, "no exec session with ID %s found in namespace %q", id, s.namespace)
+		}
+
+		ctrID = string(ctrIDBytes)
+
+		return nil
 	})
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return pod, nil
+	return ctrID, nil
 }
 
-// LookupPod retrieves a pod from full or unique partial ID or name
-func (s *BoltState) LookupPod(idOrName string) (*Pod, error) {
-	if idOrName == "" {
-		return nil, define.ErrEmptyID
+// RemoveExecSession removes the registration of an exec session
+func (s *BoltState) RemoveExecSession(id string) error {
+	if id == "" {
+		return define.ErrEmptyID
 	}
 
 	if !s.valid {
-		return nil, define.ErrDBClosed
+		return define.ErrDBClosed
 	}
 
-	pod := new(Pod)
-	pod.config = new(PodConfig)
-	pod.state = new(podState)
+	sessionID := []byte(id)
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer s.deferredCloseDBCon(db)
 
-	err = db.View(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+	err = db.Update(func(tx *bolt.Tx) error {
+		execBucket, err := getExecBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		namesBkt, err := getNamesBucket(tx)
-		if err != nil {
-			return err
+		if execBucket.Get(sessionID) == nil {
+			return errors.Wrapf(define.ErrNoSuchExecSession, "no exec session with ID %s found", id)
 		}
 
-		nsBkt, err := getNSBucket(tx)
+		if err := execBucket.Delete(sessionID); err != nil {
+			return errors.Wrapf(err, "error removing exec session %s from DB", id)
+		}
+
+		return nil
+	})
+	return err
+}
+
+// AllExecSessions returns all registered exec sessions and the IDs of their
+// containers
+func (s *BoltState) AllExecSessions() (map[string]string, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	sessions := make(map[string]string)
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		execBucket, err := getExecBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// First, check if the ID given was the actual pod ID
-		var id []byte
-		podExists := podBkt.Bucket([]byte(idOrName))
-		if podExists != nil {
-			// A full pod ID was given.
-			// It might not be in our namespace, but getPodFromDB()
-			// will handle that case.
-			id = []byte(idOrName)
-			return s.getPodFromDB(id, pod, podBkt)
+		nsBucket, err := getNSBucket(tx)
+		if err != nil {
+			return err
 		}
 
-		// Next, check if the full name was given
-		isCtr := false
-		fullID := namesBkt.Get([]byte(idOrName))
-		if fullID != nil {
-			// The name exists and maps to an ID.
-			// However, we aren't yet sure if the ID is a pod.
-			podExists = podBkt.Bucket(fullID)
-			if podExists != nil {
-				// A pod bucket matching the full ID was found.
-				return s.getPodFromDB(fullID, pod, podBkt)
-			}
-			// Don't error if we have a name match but it's not a
-			// pod - there's a chance we have a pod with an ID
-			// starting with those characters.
-			// However, so we can return a good error, note whether
-			// this is a container.
-			isCtr = true
-		}
-		// They did not give us a full pod name or ID.
-		// Search for partial ID matches.
-		exists := false
-		err = podBkt.ForEach(func(checkID, checkName []byte) error {
-			// If the pod isn't in our namespace, we
-			// can't match it
-			if s.namespaceBytes != nil {
-				ns := nsBkt.Get(checkID)
-				if !bytes.Equal(ns, s.namespaceBytes) {
-					return nil
-				}
-			}
-			if strings.HasPrefix(string(checkID), idOrName) {
-				if exists {
-					return errors.Wrapf(define.ErrPodExists, "more than one result for ID or name %s", idOrName)
-				}
-				id = checkID
-				exists = true
+		return execBucket.ForEach(func(id, ctrID []byte) error {
+			if s.namespace != "" && s.namespace != string(nsBucket.Get(ctrID)) {
+				return nil
 			}
 
+			sessions[string(id)] = string(ctrID)
+
 			return nil
 		})
-		if err != nil {
-			return err
-		} else if !exists {
-			if isCtr {
-				return errors.Wrapf(define.ErrNoSuchPod, "%s is a container, not a pod", idOrName)
-			}
-			return errors.Wrapf(define.ErrNoSuchPod, "no pod with name or ID %s found", idOrName)
-		}
-
-		// We might have found a container ID, but it's OK
-		// We'll just fail in getPodFromDB with ErrNoSuchPod
-		return s.getPodFromDB(id, pod, podBkt)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return pod, nil
+	return sessions, nil
 }
 
-// HasPod checks if a pod with the given ID exists in the state
-func (s *BoltState) HasPod(id string) (bool, error) {
+// AddContainerExitCode records the exit code of a container
+func (s *BoltState) AddContainerExitCode(id string, exitCode int32) error {
 	if id == "" {
-		return false, define.ErrEmptyID
+		return define.ErrEmptyID
 	}
 
 	if !s.valid {
-		return false, define.ErrDBClosed
+		return define.ErrDBClosed
 	}
 
-	podID := []byte(id)
-
-	exists := false
+	recorded, err := json.Marshal(recordedExitCode{ExitCode: exitCode, Recorded: time.Now()})
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling exit code of container %s", id)
+	}
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return false, err
+		return err
 	}
 	defer s.deferredCloseDBCon(db)
 
-	err = db.View(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+	err = db.Update(func(tx *bolt.Tx) error {
+		exitCodeBucket, err := getExitCodeBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		podDB := podBkt.Bucket(podID)
-		if podDB != nil {
-			if s.namespaceBytes != nil {
-				podNS := podDB.Get(namespaceKey)
-				if bytes.Equal(s.namespaceBytes, podNS) {
-					exists = true
-				}
-			} else {
-				exists = true
-			}
+		if err := exitCodeBucket.Put([]byte(id), recorded); err != nil {
+			return errors.Wrapf(err, "error adding exit code of container %s to DB", id)
 		}
 
 		return nil
 	})
-	if err != nil {
-		return false, err
-	}
-
-	return exists, nil
+	return err
 }
 
-// PodHasContainer checks if the given pod has a container with the given ID
-func (s *BoltState) PodHasContainer(pod *Pod, id string) (bool, error) {
+// GetContainerExitCode returns the recorded exit code of a container
+func (s *BoltState) GetContainerExitCode(id string) (int32, error) {
 	if id == "" {
-		return false, define.ErrEmptyID
+		return -1, define.ErrEmptyID
 	}
 
 	if !s.valid {
-		return false, define.ErrDBClosed
-	}
-
-	if !pod.valid {
-		return false, define.ErrPodRemoved
-	}
-
-	if s.namespace != "" && s.namespace != pod.config.Namespace {
-		return false, errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+		return -1, define.ErrDBClosed
 	}
 
-	ctrID := []byte(id)
-	podID := []byte(pod.ID())
-
-	exists := false
+	recorded := recordedExitCode{ExitCode: -1}
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return false, err
+		return -1, err
 	}
 	defer s.deferredCloseDBCon(db)
 
 	err = db.View(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+		exitCodeBucket, err := getExitCodeBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// Get pod itself
-		podDB := podBkt.Bucket(podID)
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "pod %s not found in database", pod.ID())
+		recordedBytes := exitCodeBucket.Get([]byte(id))
+		if recordedBytes == nil {
+			return errors.Wrapf(define.ErrNoSuchExitCode, "no exit code recorded for container %s", id)
 		}
 
-		// Get pod containers bucket
-		podCtrs := podDB.Bucket(containersBkt)
-		if podCtrs == nil {
-			return errors.Wrapf(define.ErrInternal, "pod %s missing containers bucket in DB", pod.ID())
-		}
-
-		// Don't bother with a namespace check on the container -
-		// We maintain the invariant that container namespaces must
-		// match the namespace of the pod they join.
-		// We already checked the pod namespace, so we should be fine.
-
-		ctr := podCtrs.Get(ctrID)
-		if ctr != nil {
-			exists = true
+		if err := json.Unmarshal(recordedBytes, &recorded); err != nil {
+			return errors.Wrapf(err, "error unmarshalling exit code of container %s", id)
 		}
 
 		return nil
 	})
 	if err != nil {
-		return false, err
+		return -1, err
 	}
 
-	return exists, nil
+	return recorded.ExitCode, nil
 }
 
-// PodContainersByID returns the IDs of all containers present in the given pod
-func (s *BoltState) PodContainersByID(pod *Pod) ([]string, error) {
+// PruneContainerExitCodes removes the exit codes recorded before the given
+// time
+func (s *BoltState) PruneContainerExitCodes(before time.Time) error {
 	if !s.valid {
-		return nil, define.ErrDBClosed
+		return define.ErrDBClosed
 	}
 
-	if !pod.valid {
-		return nil, define.ErrPodRemoved
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
 	}
+	defer s.deferredCloseDBCon(db)
 
-	if s.namespace != "" && s.namespace != pod.config.Namespace {
-		return nil, errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
-	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		exitCodeBucket, err := getExitCodeBucket(tx)
+		if err != nil {
+			return err
+		}
 
-	podID := []byte(pod.ID())
+		// Keys cannot be deleted while iterating over the bucket
+		var expired [][]byte
+		err = exitCodeBucket.ForEach(func(id, recordedBytes []byte) error {
+			recorded := recordedExitCode{}
+			if err := json.Unmarshal(recordedBytes, &recorded); err != nil {
+				logrus.Errorf("Error unmarshalling exit code of container %s, removing it: %v", string(id), err)
+			} else if !recorded.Recorded.Before(before) {
+				return nil
+			}
+			expired = append(expired, id)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 
-	ctrs := []string{}
+		for _, id := range expired {
+			if err := exitCodeBucket.Delete(id); err != nil {
+				return errors.Wrapf(err, "error removing exit code of container %s from DB", string(id))
+			}
+		}
+
+		return nil
+	})
+	return err
+}
+
+// AllocateUserNSRange allocates a range of UIDs and GIDs to a container
+func (s *BoltState) AllocateUserNSRange(ctrID string, size int, uidPool, gidPool []idtools.IDMap) (*AutoUserNSRange, error) {
+	if ctrID == "" {
+		return nil, define.ErrEmptyID
+	}
+
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
 
 	db, err := s.getDBCon()
 	if err != nil {
@@ -1226,132 +1633,156 @@ func (s *BoltState) PodContainersByID(pod *Pod) ([]string, error) {
 	}
 	defer s.deferredCloseDBCon(db)
 
-	err = db.View(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+	var newRange *AutoUserNSRange
+	err = db.Update(func(tx *bolt.Tx) error {
+		usernsBucket, err := getUserNSBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// Get pod itself
-		podDB := podBkt.Bucket(podID)
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "pod %s not found in database", pod.ID())
-		}
-
-		// Get pod containers bucket
-		podCtrs := podDB.Bucket(containersBkt)
-		if podCtrs == nil {
-			return errors.Wrapf(define.ErrInternal, "pod %s missing containers bucket in DB", pod.ID())
+		if usernsBucket.Get([]byte(ctrID)) != nil {
+			return errors.Wrapf(define.ErrCtrExists, "container %s already has a user namespace allocated", ctrID)
 		}
 
-		// Iterate through all containers in the pod
-		err = podCtrs.ForEach(func(id, val []byte) error {
-			ctrs = append(ctrs, string(id))
-
+		var allocated []AutoUserNSRange
+		err = usernsBucket.ForEach(func(id, rangeBytes []byte) error {
+			r := AutoUserNSRange{}
+			if err := json.Unmarshal(rangeBytes, &r); err != nil {
+				return errors.Wrapf(err, "error unmarshalling user namespace of container %s", string(id))
+			}
+			allocated = append(allocated, r)
 			return nil
 		})
 		if err != nil {
 			return err
 		}
 
+		newRange, err = allocateAutoUserNSRange(size, uidPool, gidPool, allocated)
+		if err != nil {
+			return err
+		}
+
+		rangeJSON, err := json.Marshal(newRange)
+		if err != nil {
+			return errors.Wrapf(err, "error marshalling user namespace of container %s", ctrID)
+		}
+		if err := usernsBucket.Put([]byte(ctrID), rangeJSON); err != nil {
+			return errors.Wrapf(err, "error adding user namespace of container %s to DB", ctrID)
+		}
+
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return ctrs, nil
+	return newRange, nil
 }
 
-// PodContainers returns all the containers present in the given pod
-func (s *BoltState) PodContainers(pod *Pod) ([]*Container, error) {
+// ReleaseUserNSRange releases the range of UIDs and GIDs allocated to a
+// container
+func (s *BoltState) ReleaseUserNSRange(ctrID string) error {
+	if ctrID == "" {
+		return define.ErrEmptyID
+	}
+
 	if !s.valid {
-		return nil, define.ErrDBClosed
+		return define.ErrDBClosed
 	}
 
-	if !pod.valid {
-		return nil, define.ErrPodRemoved
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
 	}
+	defer s.deferredCloseDBCon(db)
 
-	if s.namespace != "" && s.namespace != pod.config.Namespace {
-		return nil, errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	err = db.Update(func(tx *bolt.Tx) error {
+		usernsBucket, err := getUserNSBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := usernsBucket.Delete([]byte(ctrID)); err != nil {
+			return errors.Wrapf(err, "error removing user namespace of container %s from DB", ctrID)
+		}
+
+		return nil
+	})
+	return err
+}
+
+// RewriteContainerConfig rewrites a container's configuration.
+// WARNING: This function is DANGEROUS. Do not use without reading the full
+// comment on this function in state.go.
+func (s *BoltState) RewriteContainerConfig(ctr *Container, newCfg *ContainerConfig) error {
+	if !s.valid {
+		return define.ErrDBClosed
 	}
 
-	podID := []byte(pod.ID())
+	if !ctr.valid {
+		return define.ErrCtrRemoved
+	}
 
-	ctrs := []*Container{}
+	newCfgJSON, err := json.Marshal(newCfg)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling new configuration JSON for container %s", ctr.ID())
+	}
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer s.deferredCloseDBCon(db)
 
-	err = db.View(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
-		if err != nil {
-			return err
-		}
-
+	err = db.Update(func(tx *bolt.Tx) error {
 		ctrBkt, err := getCtrBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// Get pod itself
-		podDB := podBkt.Bucket(podID)
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "pod %s not found in database", pod.ID())
+		ctrDB := ctrBkt.Bucket([]byte(ctr.ID()))
+		if ctrDB == nil {
+			ctr.valid = false
+			return errors.Wrapf(define.ErrNoSuchCtr, "no container with ID %s found in DB", ctr.ID())
 		}
 
-		// Get pod containers bucket
-		podCtrs := podDB.Bucket(containersBkt)
-		if podCtrs == nil {
-			return errors.Wrapf(define.ErrInternal, "pod %s missing containers bucket in DB", pod.ID())
+		if err := ctrDB.Put(configKey, newCfgJSON); err != nil {
+			return errors.Wrapf(err, "error updating container %s config JSON", ctr.ID())
 		}
 
-		// Iterate through all containers in the pod
-		err = podCtrs.ForEach(func(id, val []byte) error {
-			newCtr := new(Container)
-			newCtr.config = new(ContainerConfig)
-			newCtr.state = new(ContainerState)
-			ctrs = append(ctrs, newCtr)
-
-			return s.getContainerFromDB(id, newCtr, ctrBkt)
-		})
-		if err != nil {
-			return err
+		// The summary may no longer match the config.
+		// Remove it, it will be regenerated when the container's state
+		// is next saved.
+		if err := ctrDB.Delete(summaryKey); err != nil {
+			return errors.Wrapf(err, "error removing container %s summary", ctr.ID())
 		}
 
 		return nil
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	return ctrs, nil
+	return err
 }
 
-// AddVolume adds the given volume to the state. It also adds ctrDepID to
-// the sub bucket holding the container dependencies that this volume has
-func (s *BoltState) AddVolume(volume *Volume) error {
+// RewriteContainerNetworks rewrites a container's configuration after the
+// networks it is attached to have changed, and updates the dependencies of the
+// networks in the database to match.
+// WARNING: This function is DANGEROUS. Do not use without reading the full
+// comment on this function in state.go.
+func (s *BoltState) RewriteContainerNetworks(ctr *Container, newCfg *ContainerConfig) error {
 	if !s.valid {
 		return define.ErrDBClosed
 	}
 
-	if !volume.valid {
-		return define.ErrVolumeRemoved
+	if !ctr.valid {
+		return define.ErrCtrRemoved
 	}
 
-	volName := []byte(volume.Name())
-
-	volConfigJSON, err := json.Marshal(volume.config)
+	newCfgJSON, err := json.Marshal(newCfg)
 	if err != nil {
-		return errors.Wrapf(err, "error marshalling volume %s config to JSON", volume.Name())
+		return errors.Wrapf(err, "error marshalling new configuration JSON for container %s", ctr.ID())
 	}
 
+	ctrID := []byte(ctr.ID())
+
 	db, err := s.getDBCon()
 	if err != nil {
 		return err
@@ -1359,41 +1790,73 @@ func (s *BoltState) AddVolume(volume *Volume) error {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.Update(func(tx *bolt.Tx) error {
-		volBkt, err := getVolBucket(tx)
+		ctrBkt, err := getCtrBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		allVolsBkt, err := getAllVolsBucket(tx)
+		netBkt, err := getNetBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// Check if we already have a volume with the given name
-		volExists := allVolsBkt.Get(volName)
-		if volExists != nil {
-			return errors.Wrapf(define.ErrVolumeExists, "name %s is in use", volume.Name())
+		ctrDB := ctrBkt.Bucket(ctrID)
+		if ctrDB == nil {
+			ctr.valid = false
+			return errors.Wrapf(define.ErrNoSuchCtr, "no container with ID %s found in DB", ctr.ID())
 		}
 
-		// We are good to add the volume
-		// Make a bucket for it
-		newVol, err := volBkt.CreateBucket(volName)
+		ipamBucket, err := getIPAMBucket(tx)
 		if err != nil {
-			return errors.Wrapf(err, "error creating bucket for volume %s", volume.Name())
+			return err
 		}
 
-		// Make a subbucket for the containers using the volume. Dependent container IDs will be addedremoved to
-		// this bucket in addcontainer/removeContainer
-		if _, err := newVol.CreateBucket(volDependenciesBkt); err != nil {
-			return errors.Wrapf(err, "error creating bucket for containers using volume %s", volume.Name())
+		// Move the container's address reservations to its new
+		// static addresses
+		if err := releaseAddresses(ipamBucket, ctrID, ctr.staticAddresses(ctr.config)); err != nil {
+			return err
+		}
+		if err := reserveAddresses(ipamBucket, ctrID, ctr.staticAddresses(newCfg)); err != nil {
+			return err
 		}
 
-		if err := newVol.Put(configKey, volConfigJSON); err != nil {
-			return errors.Wrapf(err, "error storing volume %s configuration in DB", volume.Name())
+		// Remove the container from the dependencies of all its old
+		// networks, then add it to those of its new networks.
+		// Networks not tracked in the DB are skipped.
+		for _, net := range ctr.config.Networks {
+			netDB := netBkt.Bucket([]byte(net))
+			if netDB == nil {
+				continue
+			}
+			ctrDepsBkt := netDB.Bucket(netDependenciesBkt)
+			if ctrDepsBkt == nil {
+				continue
+			}
+			if err := ctrDepsBkt.Delete(ctrID); err != nil {
+				return errors.Wrapf(err, "error deleting container %s dependency on network %s", ctr.ID(), net)
+			}
+		}
+		for _, net := range newCfg.Networks {
+			netDB := netBkt.Bucket([]byte(net))
+			if netDB == nil {
+				continue
+			}
+			ctrDepsBkt := netDB.Bucket(netDependenciesBkt)
+			if ctrDepsBkt == nil {
+				return errors.Wrapf(define.ErrInternal, "network %s has no dependencies bucket", net)
+			}
+			if err := ctrDepsBkt.Put(ctrID, ctrID); err != nil {
+				return errors.Wrapf(err, "error adding container %s to network %s dependencies", ctr.ID(), net)
+			}
 		}
 
-		if err := allVolsBkt.Put(volName, volName); err != nil {
-			return errors.Wrapf(err, "error storing volume %s in all volumes bucket in DB", volume.Name())
+		if err := ctrDB.Put(configKey, newCfgJSON); err != nil {
+			return errors.Wrapf(err, "error updating container %s config JSON", ctr.ID())
+		}
+
+		// The summary may no longer match the config.
+		if err := ctrDB.Delete(summaryKey); err != nil {
+			return errors.Wrapf(err, "error removing container %s summary", ctr.ID())
 		}
 
 		return nil
@@ -1401,13 +1864,22 @@ func (s *BoltState) AddVolume(volume *Volume) error {
 	return err
 }
 
-// RemoveVolume removes the given volume from the state
-func (s *BoltState) RemoveVolume(volume *Volume) error {
+// RewritePodConfig rewrites a pod's configuration.
+// WARNING: This function is DANGEROUS. Do not use without reading the full
+// comment on this function in state.go.
+func (s *BoltState) RewritePodConfig(pod *Pod, newCfg *PodConfig) error {
 	if !s.valid {
 		return define.ErrDBClosed
 	}
 
-	volName := []byte(volume.Name())
+	if !pod.valid {
+		return define.ErrPodRemoved
+	}
+
+	newCfgJSON, err := json.Marshal(newCfg)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling new configuration JSON for pod %s", pod.ID())
+	}
 
 	db, err := s.getDBCon()
 	if err != nil {
@@ -1416,66 +1888,63 @@ func (s *BoltState) RemoveVolume(volume *Volume) error {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.Update(func(tx *bolt.Tx) error {
-		volBkt, err := getVolBucket(tx)
-		if err != nil {
-			return err
-		}
-
-		allVolsBkt, err := getAllVolsBucket(tx)
+		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		ctrBkt, err := getCtrBucket(tx)
-		if err != nil {
-			return err
+		podDB := podBkt.Bucket([]byte(pod.ID()))
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "no pod with ID %s found in DB", pod.ID())
 		}
 
-		// Check if the volume exists
-		volDB := volBkt.Bucket(volName)
-		if volDB == nil {
-			volume.valid = false
-			return errors.Wrapf(define.ErrNoSuchVolume, "volume %s does not exist in DB", volume.Name())
+		if err := podDB.Put(configKey, newCfgJSON); err != nil {
+			return errors.Wrapf(err, "error updating pod %s config JSON", pod.ID())
 		}
 
-		// Check if volume is not being used by any container
-		// This should never be nil
-		// But if it is, we can assume that no containers are using
-		// the volume.
-		volCtrsBkt := volDB.Bucket(volDependenciesBkt)
-		if volCtrsBkt != nil {
-			var deps []string
-			err = volCtrsBkt.ForEach(func(id, value []byte) error {
-				// Alright, this is ugly.
-				// But we need it to work around the change in
-				// volume dependency handling, to make sure that
-				// older Podman versions don't cause DB
-				// corruption.
-				// Look up all dependencies and see that they
-				// still exist before appending.
-				ctrExists := ctrBkt.Bucket(id)
-				if ctrExists == nil {
-					return nil
-				}
+		return nil
+	})
+	return err
+}
 
-				deps = append(deps, string(id))
-				return nil
-			})
-			if err != nil {
-				return errors.Wrapf(err, "error getting list of dependencies from dependencies bucket for volumes %q", volume.Name())
-			}
-			if len(deps) > 0 {
-				return errors.Wrapf(define.ErrVolumeBeingUsed, "volume %s is being used by container(s) %s", volume.Name(), strings.Join(deps, ","))
-			}
+// RewriteVolumeConfig rewrites a volume's configuration.
+// WARNING: This function is DANGEROUS. Do not use without reading the full
+// comment on this function in state.go.
+func (s *BoltState) RewriteVolumeConfig(volume *Volume, newCfg *VolumeConfig) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !volume.valid {
+		return define.ErrVolumeRemoved
+	}
+
+	newCfgJSON, err := json.Marshal(newCfg)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling new configuration JSON for volume %q", volume.Name())
+	}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		volBkt, err := getVolBucket(tx)
+		if err != nil {
+			return err
 		}
 
-		// volume is ready for removal
-		// Let's kick it out
-		if err := allVolsBkt.Delete(volName); err != nil {
-			return errors.Wrapf(err, "error removing volume %s from all volumes bucket in DB", volume.Name())
+		volDB := volBkt.Bucket([]byte(volume.Name()))
+		if volDB == nil {
+			volume.valid = false
+			return errors.Wrapf(define.ErrNoSuchVolume, "no volume with name %q found in DB", volume.Name())
 		}
-		if err := volBkt.DeleteBucket(volName); err != nil {
-			return errors.Wrapf(err, "error removing volume %s from DB", volume.Name())
+
+		if err := volDB.Put(configKey, newCfgJSON); err != nil {
+			return errors.Wrapf(err, "error updating volume %q config JSON", volume.Name())
 		}
 
 		return nil
@@ -1483,13 +1952,21 @@ func (s *BoltState) RemoveVolume(volume *Volume) error {
 	return err
 }
 
-// AllVolumes returns all volumes present in the state
-func (s *BoltState) AllVolumes() ([]*Volume, error) {
+// Pod retrieves a pod given its full ID
+func (s *BoltState) Pod(id string) (*Pod, error) {
+	if id == "" {
+		return nil, define.ErrEmptyID
+	}
+
 	if !s.valid {
 		return nil, define.ErrDBClosed
 	}
 
-	volumes := []*Volume{}
+	podID := []byte(id)
+
+	pod := new(Pod)
+	pod.config = new(PodConfig)
+	pod.state = new(podState)
 
 	db, err := s.getDBCon()
 	if err != nil {
@@ -1498,48 +1975,23 @@ func (s *BoltState) AllVolumes() ([]*Volume, error) {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.View(func(tx *bolt.Tx) error {
-		allVolsBucket, err := getAllVolsBucket(tx)
-		if err != nil {
-			return err
-		}
-
-		volBucket, err := getVolBucket(tx)
+		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
-		err = allVolsBucket.ForEach(func(id, name []byte) error {
-			volExists := volBucket.Bucket(id)
-			// This check can be removed if performance becomes an
-			// issue, but much less helpful errors will be produced
-			if volExists == nil {
-				return errors.Wrapf(define.ErrInternal, "inconsistency in state - volume %s is in all volumes bucket but volume not found", string(id))
-			}
-
-			volume := new(Volume)
-			volume.config = new(VolumeConfig)
-
-			if err := s.getVolumeFromDB(id, volume, volBucket); err != nil {
-				if errors.Cause(err) != define.ErrNSMismatch {
-					logrus.Errorf("Error retrieving volume %s from the database: %v", string(id), err)
-				}
-			} else {
-				volumes = append(volumes, volume)
-			}
 
-			return nil
-		})
-		return err
+		return s.getPodFromDB(podID, pod, podBkt)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return volumes, nil
+	return pod, nil
 }
 
-// Volume retrieves a volume from full name
-func (s *BoltState) Volume(name string) (*Volume, error) {
-	if name == "" {
+// LookupPod retrieves a pod from full or unique partial ID or name
+func (s *BoltState) LookupPod(idOrName string) (*Pod, error) {
+	if idOrName == "" {
 		return nil, define.ErrEmptyID
 	}
 
@@ -1547,10 +1999,9 @@ func (s *BoltState) Volume(name string) (*Volume, error) {
 		return nil, define.ErrDBClosed
 	}
 
-	volName := []byte(name)
-
-	volume := new(Volume)
-	volume.config = new(VolumeConfig)
+	pod := new(Pod)
+	pod.config = new(PodConfig)
+	pod.state = new(podState)
 
 	db, err := s.getDBCon()
 	if err != nil {
@@ -1559,23 +2010,95 @@ func (s *BoltState) Volume(name string) (*Volume, error) {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.View(func(tx *bolt.Tx) error {
-		volBkt, err := getVolBucket(tx)
+		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		return s.getVolumeFromDB(volName, volume, volBkt)
+		namesBkt, err := getNamesBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		nsBkt, err := getNSBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		// First, check if the ID given was the actual pod ID
+		var id []byte
+		podExists := podBkt.Bucket([]byte(idOrName))
+		if podExists != nil {
+			// A full pod ID was given.
+			// It might not be in our namespace, but getPodFromDB()
+			// will handle that case.
+			id = []byte(idOrName)
+			return s.getPodFromDB(id, pod, podBkt)
+		}
+
+		// Next, check if the full name was given
+		isCtr := false
+		fullID := namesBkt.Get([]byte(idOrName))
+		if fullID != nil {
+			// The name exists and maps to an ID.
+			// However, we aren't yet sure if the ID is a pod.
+			podExists = podBkt.Bucket(fullID)
+			if podExists != nil {
+				// A pod bucket matching the full ID was found.
+				return s.getPodFromDB(fullID, pod, podBkt)
+			}
+			// Don't error if we have a name match but it's not a
+			// pod - there's a chance we have a pod with an ID
+			// starting with those characters.
+			// However, so we can return a good error, note whether
+			// this is a container.
+			isCtr = true
+		}
+		// They did not give us a full pod name or ID.
+		// Search for partial ID matches.
+		exists := false
+		err = podBkt.ForEach(func(checkID, checkName []byte) error {
+			// If the pod isn't in our namespace, we
+			// can't match it
+			if s.namespaceBytes != nil {
+				ns := nsBkt.Get(checkID)
+				if !bytes.Equal(ns, s.namespaceBytes) {
+					return nil
+				}
+			}
+			if strings.HasPrefix(string(checkID), idOrName) {
+				if exists {
+					return errors.Wrapf(define.ErrPodExists, "more than one result for ID or name %s", idOrName)
+				}
+				id = checkID
+				exists = true
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		} else if !exists {
+			if isCtr {
+				return errors.Wrapf(define.ErrNoSuchPod, "%s is a container, not a pod", idOrName)
+			}
+			return errors.Wrapf(define.ErrNoSuchPod, "no pod with name or ID %s found", idOrName)
+		}
+
+		// We might have found a container ID, but it's OK
+		// We'll just fail in getPodFromDB with ErrNoSuchPod
+		return s.getPodFromDB(id, pod, podBkt)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return volume, nil
+	return pod, nil
 }
 
-// HasVolume returns true if the given volume exists in the state, otherwise it returns false
-func (s *BoltState) HasVolume(name string) (bool, error) {
-	if name == "" {
+// HasPod checks if a pod with the given ID exists in the state
+func (s *BoltState) HasPod(id string) (bool, error) {
+	if id == "" {
 		return false, define.ErrEmptyID
 	}
 
@@ -1583,7 +2106,7 @@ func (s *BoltState) HasVolume(name string) (bool, error) {
 		return false, define.ErrDBClosed
 	}
 
-	volName := []byte(name)
+	podID := []byte(id)
 
 	exists := false
 
@@ -1594,14 +2117,21 @@ func (s *BoltState) HasVolume(name string) (bool, error) {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.View(func(tx *bolt.Tx) error {
-		volBkt, err := getVolBucket(tx)
+		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		volDB := volBkt.Bucket(volName)
-		if volDB != nil {
-			exists = true
+		podDB := podBkt.Bucket(podID)
+		if podDB != nil {
+			if s.namespaceBytes != nil {
+				podNS := podDB.Get(namespaceKey)
+				if bytes.Equal(s.namespaceBytes, podNS) {
+					exists = true
+				}
+			} else {
+				exists = true
+			}
 		}
 
 		return nil
@@ -1613,58 +2143,119 @@ func (s *BoltState) HasVolume(name string) (bool, error) {
 	return exists, nil
 }
 
-// VolumeInUse checks if any container is using the volume
-// It returns a slice of the IDs of the containers using the given
-// volume. If the slice is empty, no containers use the given volume
-func (s *BoltState) VolumeInUse(volume *Volume) ([]string, error) {
+// PodHasContainer checks if the given pod has a container with the given ID
+func (s *BoltState) PodHasContainer(pod *Pod, id string) (bool, error) {
+	if id == "" {
+		return false, define.ErrEmptyID
+	}
+
 	if !s.valid {
-		return nil, define.ErrDBClosed
+		return false, define.ErrDBClosed
 	}
 
-	if !volume.valid {
-		return nil, define.ErrVolumeRemoved
+	if !pod.valid {
+		return false, define.ErrPodRemoved
 	}
 
-	depCtrs := []string{}
+	if s.namespace != "" && s.namespace != pod.config.Namespace {
+		return false, errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	}
+
+	ctrID := []byte(id)
+	podID := []byte(pod.ID())
+
+	exists := false
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 	defer s.deferredCloseDBCon(db)
 
 	err = db.View(func(tx *bolt.Tx) error {
-		volBucket, err := getVolBucket(tx)
+		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		ctrBucket, err := getCtrBucket(tx)
+		// Get pod itself
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "pod %s not found in database", pod.ID())
+		}
+
+		// Get pod containers bucket
+		podCtrs := podDB.Bucket(containersBkt)
+		if podCtrs == nil {
+			return errors.Wrapf(define.ErrInternal, "pod %s missing containers bucket in DB", pod.ID())
+		}
+
+		// Don't bother with a namespace check on the container -
+		// We maintain the invariant that container namespaces must
+		// match the namespace of the pod they join.
+		// We already checked the pod namespace, so we should be fine.
+
+		ctr := podCtrs.Get(ctrID)
+		if ctr != nil {
+			exists = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// PodContainersByID returns the IDs of all containers present in the given pod
+func (s *BoltState) PodContainersByID(pod *Pod) ([]string, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return nil, define.ErrPodRemoved
+	}
+
+	if s.namespace != "" && s.namespace != pod.config.Namespace {
+		return nil, errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	}
+
+	podID := []byte(pod.ID())
+
+	ctrs := []string{}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		volDB := volBucket.Bucket([]byte(volume.Name()))
-		if volDB == nil {
-			volume.valid = false
-			return errors.Wrapf(define.ErrNoSuchVolume, "no volume with name %s found in DB", volume.Name())
+		// Get pod itself
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "pod %s not found in database", pod.ID())
 		}
 
-		dependsBkt := volDB.Bucket(volDependenciesBkt)
-		if dependsBkt == nil {
-			return errors.Wrapf(define.ErrInternal, "volume %s has no dependencies bucket", volume.Name())
+		// Get pod containers bucket
+		podCtrs := podDB.Bucket(containersBkt)
+		if podCtrs == nil {
+			return errors.Wrapf(define.ErrInternal, "pod %s missing containers bucket in DB", pod.ID())
 		}
 
-		// Iterate through and add dependencies
-		err = dependsBkt.ForEach(func(id, value []byte) error {
-			// Look up all dependencies and see that they
-			// still exist before appending.
-			ctrExists := ctrBucket.Bucket(id)
-			if ctrExists == nil {
-				return nil
-			}
-
-			depCtrs = append(depCtrs, string(id))
+		// Iterate through all containers in the pod
+		err = podCtrs.ForEach(func(id, val []byte) error {
+			ctrs = append(ctrs, string(id))
 
 			return nil
 		})
@@ -1678,88 +2269,678 @@ func (s *BoltState) VolumeInUse(volume *Volume) ([]string, error) {
 		return nil, err
 	}
 
-	return depCtrs, nil
+	return ctrs, nil
 }
 
-// AddPod adds the given pod to the state.
-func (s *BoltState) AddPod(pod *Pod) error {
+// PodContainers returns all the containers present in the given pod
+func (s *BoltState) PodContainers(pod *Pod) ([]*Container, error) {
 	if !s.valid {
-		return define.ErrDBClosed
+		return nil, define.ErrDBClosed
 	}
 
 	if !pod.valid {
-		return define.ErrPodRemoved
+		return nil, define.ErrPodRemoved
 	}
 
 	if s.namespace != "" && s.namespace != pod.config.Namespace {
-		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+		return nil, errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
 	}
 
 	podID := []byte(pod.ID())
-	podName := []byte(pod.Name())
 
-	var podNamespace []byte
-	if pod.config.Namespace != "" {
-		podNamespace = []byte(pod.config.Namespace)
-	}
+	ctrs := []*Container{}
 
-	podConfigJSON, err := json.Marshal(pod.config)
+	db, err := s.getDBCon()
 	if err != nil {
-		return errors.Wrapf(err, "error marshalling pod %s config to JSON", pod.ID())
+		return nil, err
 	}
+	defer s.deferredCloseDBCon(db)
 
-	podStateJSON, err := json.Marshal(pod.state)
+	err = db.View(func(tx *bolt.Tx) error {
+		podBkt, err := getPodBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		ctrBkt, err := getCtrBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		// Get pod itself
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "pod %s not found in database", pod.ID())
+		}
+
+		// Get pod containers bucket
+		podCtrs := podDB.Bucket(containersBkt)
+		if podCtrs == nil {
+			return errors.Wrapf(define.ErrInternal, "pod %s missing containers bucket in DB", pod.ID())
+		}
+
+		// Iterate through all containers in the pod
+		err = podCtrs.ForEach(func(id, val []byte) error {
+			newCtr := new(Container)
+			newCtr.config = new(ContainerConfig)
+			newCtr.state = new(ContainerState)
+			ctrs = append(ctrs, newCtr)
+
+			return s.getContainerFromDB(id, newCtr, ctrBkt)
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
 	if err != nil {
-		return errors.Wrapf(err, "error marshalling pod %s state to JSON", pod.ID())
+		return nil, err
+	}
+
+	return ctrs, nil
+}
+
+// PodInitContainers returns the init containers of the given pod
+func (s *BoltState) PodInitContainers(pod *Pod) ([]*Container, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return nil, define.ErrPodRemoved
+	}
+
+	if s.namespace != "" && s.namespace != pod.config.Namespace {
+		return nil, errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
 	}
 
+	podID := []byte(pod.ID())
+
+	ctrs := []*Container{}
+
 	db, err := s.getDBCon()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer s.deferredCloseDBCon(db)
 
-	err = db.Update(func(tx *bolt.Tx) error {
+	err = db.View(func(tx *bolt.Tx) error {
 		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		allPodsBkt, err := getAllPodsBucket(tx)
+		ctrBkt, err := getCtrBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		idsBkt, err := getIDBucket(tx)
-		if err != nil {
-			return err
+		// Get pod itself
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "pod %s not found in database", pod.ID())
 		}
 
-		namesBkt, err := getNamesBucket(tx)
+		// The init containers bucket is only created once an init
+		// container is added to the pod
+		podInitCtrs := podDB.Bucket(initContainersBkt)
+		if podInitCtrs == nil {
+			return nil
+		}
+
+		return podInitCtrs.ForEach(func(id, val []byte) error {
+			newCtr := new(Container)
+			newCtr.config = new(ContainerConfig)
+			newCtr.state = new(ContainerState)
+			ctrs = append(ctrs, newCtr)
+
+			return s.getContainerFromDB(id, newCtr, ctrBkt)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ctrs, nil
+}
+
+// AddVolume adds the given volume to the state. It also adds ctrDepID to
+// the sub bucket holding the container dependencies that this volume has
+func (s *BoltState) AddVolume(volume *Volume) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !volume.valid {
+		return define.ErrVolumeRemoved
+	}
+
+	volName := []byte(volume.Name())
+
+	volConfigJSON, err := json.Marshal(volume.config)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling volume %s config to JSON", volume.Name())
+	}
+
+	volStateJSON, err := json.Marshal(volume.state)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling volume %s state to JSON", volume.Name())
+	}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		volBkt, err := getVolBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		nsBkt, err := getNSBucket(tx)
+		allVolsBkt, err := getAllVolsBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// Check if we already have something with the given ID and name
-		idExist := idsBkt.Get(podID)
-		if idExist != nil {
-			return errors.Wrapf(define.ErrPodExists, "ID %s is in use", pod.ID())
-		}
-		nameExist := namesBkt.Get(podName)
-		if nameExist != nil {
-			return errors.Wrapf(define.ErrPodExists, "name %s is in use", pod.Name())
+		// Check if we already have a volume with the given name
+		volExists := allVolsBkt.Get(volName)
+		if volExists != nil {
+			return errors.Wrapf(define.ErrVolumeExists, "name %s is in use", volume.Name())
 		}
 
-		// We are good to add the pod
+		// We are good to add the volume
 		// Make a bucket for it
-		newPod, err := podBkt.CreateBucket(podID)
+		newVol, err := volBkt.CreateBucket(volName)
 		if err != nil {
-			return errors.Wrapf(err, "error creating bucket for pod %s", pod.ID())
+			return errors.Wrapf(err, "error creating bucket for volume %s", volume.Name())
+		}
+
+		// Make a subbucket for the containers using the volume. Dependent container IDs will be addedremoved to
+		// this bucket in addcontainer/removeContainer
+		if _, err := newVol.CreateBucket(volDependenciesBkt); err != nil {
+			return errors.Wrapf(err, "error creating bucket for containers using volume %s", volume.Name())
+		}
+
+		if err := newVol.Put(configKey, volConfigJSON); err != nil {
+			return errors.Wrapf(err, "error storing volume %s configuration in DB", volume.Name())
+		}
+
+		if err := newVol.Put(stateKey, volStateJSON); err != nil {
+			return errors.Wrapf(err, "error storing volume %s state in DB", volume.Name())
+		}
+
+		if err := allVolsBkt.Put(volName, volName); err != nil {
+			return errors.Wrapf(err, "error storing volume %s in all volumes bucket in DB", volume.Name())
+		}
+
+		return nil
+	})
+	return err
+}
+
+// RemoveVolume removes the given volume from the state
+func (s *BoltState) RemoveVolume(volume *Volume) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	volName := []byte(volume.Name())
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		volBkt, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		allVolsBkt, err := getAllVolsBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		ctrBkt, err := getCtrBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		// Check if the volume exists
+		volDB := volBkt.Bucket(volName)
+		if volDB == nil {
+			volume.valid = false
+			return errors.Wrapf(define.ErrNoSuchVolume, "volume %s does not exist in DB", volume.Name())
+		}
+
+		// Check if volume is not being used by any container
+		// This should never be nil
+		// But if it is, we can assume that no containers are using
+		// the volume.
+		volCtrsBkt := volDB.Bucket(volDependenciesBkt)
+		if volCtrsBkt != nil {
+			var deps []string
+			err = volCtrsBkt.ForEach(func(id, value []byte) error {
+				// Alright, this is ugly.
+				// But we need it to work around the change in
+				// volume dependency handling, to make sure that
+				// older Podman versions don't cause DB
+				// corruption.
+				// Look up all dependencies and see that they
+				// still exist before appending.
+				ctrExists := ctrBkt.Bucket(id)
+				if ctrExists == nil {
+					return nil
+				}
+
+				deps = append(deps, string(id))
+				return nil
+			})
+			if err != nil {
+				return errors.Wrapf(err, "error getting list of dependencies from dependencies bucket for volumes %q", volume.Name())
+			}
+			if len(deps) > 0 {
+				return errors.Wrapf(define.ErrVolumeBeingUsed, "volume %s is being used by container(s) %s", volume.Name(), strings.Join(deps, ","))
+			}
+		}
+
+		// volume is ready for removal
+		// Let's kick it out
+		if err := allVolsBkt.Delete(volName); err != nil {
+			return errors.Wrapf(err, "error removing volume %s from all volumes bucket in DB", volume.Name())
+		}
+		if err := volBkt.DeleteBucket(volName); err != nil {
+			return errors.Wrapf(err, "error removing volume %s from DB", volume.Name())
+		}
+
+		return nil
+	})
+	return err
+}
+
+// UpdateVolume updates the volume's state from the database.
+func (s *BoltState) UpdateVolume(volume *Volume) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !volume.valid {
+		return define.ErrVolumeRemoved
+	}
+
+	newState := new(VolumeState)
+	volumeName := []byte(volume.Name())
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		volBucket, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		volToUpdate := volBucket.Bucket(volumeName)
+		if volToUpdate == nil {
+			volume.valid = false
+			return errors.Wrapf(define.ErrNoSuchVolume, "no volume with name %s found in database", volume.Name())
+		}
+
+		// Volumes created by older versions of libpod have no
+		// state. Treat them as having an empty one.
+		stateBytes := volToUpdate.Get(stateKey)
+		if stateBytes == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(stateBytes, newState); err != nil {
+			return errors.Wrapf(err, "error unmarshalling volume %s state", volume.Name())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	volume.state = newState
+
+	return nil
+}
+
+// SaveVolume saves the volume's state to the database.
+func (s *BoltState) SaveVolume(volume *Volume) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !volume.valid {
+		return define.ErrVolumeRemoved
+	}
+
+	volumeName := []byte(volume.Name())
+
+	stateJSON, err := json.Marshal(volume.state)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling volume %s state to JSON", volume.Name())
+	}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		volBucket, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		volToUpdate := volBucket.Bucket(volumeName)
+		if volToUpdate == nil {
+			volume.valid = false
+			return errors.Wrapf(define.ErrNoSuchVolume, "no volume with name %s found in database", volume.Name())
+		}
+
+		if err := volToUpdate.Put(stateKey, stateJSON); err != nil {
+			return errors.Wrapf(err, "error updating volume %s state in DB", volume.Name())
+		}
+
+		return nil
+	})
+	return err
+}
+
+// AllVolumes returns all volumes present in the state
+func (s *BoltState) AllVolumes() ([]*Volume, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	volumes := []*Volume{}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		allVolsBucket, err := getAllVolsBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		volBucket, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+		err = allVolsBucket.ForEach(func(id, name []byte) error {
+			volExists := volBucket.Bucket(id)
+			// This check can be removed if performance becomes an
+			// issue, but much less helpful errors will be produced
+			if volExists == nil {
+				return errors.Wrapf(define.ErrInternal, "inconsistency in state - volume %s is in all volumes bucket but volume not found", string(id))
+			}
+
+			volume := new(Volume)
+			volume.config = new(VolumeConfig)
+			volume.state = new(VolumeState)
+
+			if err := s.getVolumeFromDB(id, volume, volBucket); err != nil {
+				if errors.Cause(err) != define.ErrNSMismatch {
+					logrus.Errorf("Error retrieving volume %s from the database: %v", string(id), err)
+				}
+			} else {
+				volumes = append(volumes, volume)
+			}
+
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return volumes, nil
+}
+
+// Volume retrieves a volume from full name
+func (s *BoltState) Volume(name string) (*Volume, error) {
+	if name == "" {
+		return nil, define.ErrEmptyID
+	}
+
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	volName := []byte(name)
+
+	volume := new(Volume)
+	volume.config = new(VolumeConfig)
+	volume.state = new(VolumeState)
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		volBkt, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		return s.getVolumeFromDB(volName, volume, volBkt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return volume, nil
+}
+
+// HasVolume returns true if the given volume exists in the state, otherwise it returns false
+func (s *BoltState) HasVolume(name string) (bool, error) {
+	if name == "" {
+		return false, define.ErrEmptyID
+	}
+
+	if !s.valid {
+		return false, define.ErrDBClosed
+	}
+
+	volName := []byte(name)
+
+	exists := false
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return false, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		volBkt, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		volDB := volBkt.Bucket(volName)
+		if volDB != nil {
+			exists = true
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// VolumeInUse checks if any container is using the volume
+// It returns a slice of the IDs of the containers using the given
+// volume. If the slice is empty, no containers use the given volume
+func (s *BoltState) VolumeInUse(volume *Volume) ([]string, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	if !volume.valid {
+		return nil, define.ErrVolumeRemoved
+	}
+
+	depCtrs := []string{}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		volBucket, err := getVolBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		ctrBucket, err := getCtrBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		volDB := volBucket.Bucket([]byte(volume.Name()))
+		if volDB == nil {
+			volume.valid = false
+			return errors.Wrapf(define.ErrNoSuchVolume, "no volume with name %s found in DB", volume.Name())
+		}
+
+		dependsBkt := volDB.Bucket(volDependenciesBkt)
+		if dependsBkt == nil {
+			return errors.Wrapf(define.ErrInternal, "volume %s has no dependencies bucket", volume.Name())
+		}
+
+		// Iterate through and add dependencies
+		err = dependsBkt.ForEach(func(id, value []byte) error {
+			// Look up all dependencies and see that they
+			// still exist before appending.
+			ctrExists := ctrBucket.Bucket(id)
+			if ctrExists == nil {
+				return nil
+			}
+
+			depCtrs = append(depCtrs, string(id))
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return depCtrs, nil
+}
+
+// AddPod adds the given pod to the state.
+func (s *BoltState) AddPod(pod *Pod) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return define.ErrPodRemoved
+	}
+
+	if s.namespace != "" && s.namespace != pod.config.Namespace {
+		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	}
+
+	podID := []byte(pod.ID())
+	podName := []byte(pod.Name())
+
+	var podNamespace []byte
+	if pod.config.Namespace != "" {
+		podNamespace = []byte(pod.config.Namespace)
+	}
+
+	podConfigJSON, err := json.Marshal(pod.config)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling pod %s config to JSON", pod.ID())
+	}
+
+	podStateJSON, err := json.Marshal(pod.state)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling pod %s state to JSON", pod.ID())
+	}
+
+	db, err := s.getDBConSync()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		podBkt, err := getPodBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		allPodsBkt, err := getAllPodsBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		idsBkt, err := getIDBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		namesBkt, err := getNamesBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		nsBkt, err := getNSBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		// Check if we already have something with the given ID and name
+		idExist := idsBkt.Get(podID)
+		if idExist != nil {
+			return errors.Wrapf(define.ErrPodExists, "ID %s is in use", pod.ID())
+		}
+		nameExist := namesBkt.Get(podName)
+		if nameExist != nil {
+			return errors.Wrapf(define.ErrPodExists, "name %s is in use", pod.Name())
+		}
+
+		// We are good to add the pod
+		// Make a bucket for it
+		newPod, err := podBkt.CreateBucket(podID)
+		if err != nil {
+			return errors.Wrapf(err, "error creating bucket for pod %s", pod.ID())
 		}
 
 		// Make a subbucket for pod containers
@@ -1767,32 +2948,396 @@ func (s *BoltState) AddPod(pod *Pod) error {
 			return errors.Wrapf(err, "error creating bucket for pod %s containers", pod.ID())
 		}
 
-		if err := newPod.Put(configKey, podConfigJSON); err != nil {
-			return errors.Wrapf(err, "error storing pod %s configuration in DB", pod.ID())
+		if err := newPod.Put(configKey, podConfigJSON); err != nil {
+			return errors.Wrapf(err, "error storing pod %s configuration in DB", pod.ID())
+		}
+
+		if err := newPod.Put(stateKey, podStateJSON); err != nil {
+			return errors.Wrapf(err, "error storing pod %s state JSON in DB", pod.ID())
+		}
+
+		if podNamespace != nil {
+			if err := newPod.Put(namespaceKey, podNamespace); err != nil {
+				return errors.Wrapf(err, "error storing pod %s namespace in DB", pod.ID())
+			}
+			if err := nsBkt.Put(podID, podNamespace); err != nil {
+				return errors.Wrapf(err, "error storing pod %s namespace in DB", pod.ID())
+			}
+		}
+
+		// Add us to the ID and names buckets
+		if err := idsBkt.Put(podID, podName); err != nil {
+			return errors.Wrapf(err, "error storing pod %s ID in DB", pod.ID())
+		}
+		if err := namesBkt.Put(podName, podID); err != nil {
+			return errors.Wrapf(err, "error storing pod %s name in DB", pod.Name())
+		}
+		if err := allPodsBkt.Put(podID, podName); err != nil {
+			return errors.Wrapf(err, "error storing pod %s in all pods bucket in DB", pod.ID())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemovePod removes the given pod from the state
+// Only empty pods can be removed
+func (s *BoltState) RemovePod(pod *Pod) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return define.ErrPodRemoved
+	}
+
+	if s.namespace != "" && s.namespace != pod.config.Namespace {
+		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	}
+
+	podID := []byte(pod.ID())
+	podName := []byte(pod.Name())
+
+	db, err := s.getDBConSync()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		podBkt, err := getPodBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		allPodsBkt, err := getAllPodsBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		idsBkt, err := getIDBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		namesBkt, err := getNamesBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		nsBkt, err := getNSBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		// Check if the pod exists
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "pod %s does not exist in DB", pod.ID())
+		}
+
+		// Check if pod is empty
+		// This should never be nil
+		// But if it is, we can assume there are no containers in the
+		// pod.
+		// So let's eject the malformed pod without error.
+		podCtrsBkt := podDB.Bucket(containersBkt)
+		if podCtrsBkt != nil {
+			cursor := podCtrsBkt.Cursor()
+			if id, _ := cursor.First(); id != nil {
+				return errors.Wrapf(define.ErrCtrExists, "pod %s is not empty", pod.ID())
+			}
+		}
+
+		// Pod is empty, and ready for removal
+		// Let's kick it out
+		if err := idsBkt.Delete(podID); err != nil {
+			return errors.Wrapf(err, "error removing pod %s ID from DB", pod.ID())
+		}
+		if err := namesBkt.Delete(podName); err != nil {
+			return errors.Wrapf(err, "error removing pod %s name (%s) from DB", pod.ID(), pod.Name())
+		}
+		if err := nsBkt.Delete(podID); err != nil {
+			return errors.Wrapf(err, "error removing pod %s namespace from DB", pod.ID())
+		}
+		if err := allPodsBkt.Delete(podID); err != nil {
+			return errors.Wrapf(err, "error removing pod %s ID from all pods bucket in DB", pod.ID())
+		}
+		if err := podBkt.DeleteBucket(podID); err != nil {
+			return errors.Wrapf(err, "error removing pod %s from DB", pod.ID())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemovePodContainers removes all containers in a pod
+func (s *BoltState) RemovePodContainers(pod *Pod) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return define.ErrPodRemoved
+	}
+
+	if s.namespace != "" && s.namespace != pod.config.Namespace {
+		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	}
+
+	podID := []byte(pod.ID())
+
+	db, err := s.getDBConSync()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		podBkt, err := getPodBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		ctrBkt, err := getCtrBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		allCtrsBkt, err := getAllCtrsBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		pendingRemovalBkt, err := getPendingRemovalBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		execBucket, err := getExecBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		idsBkt, err := getIDBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		namesBkt, err := getNamesBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		// Check if the pod exists
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "pod %s does not exist in DB", pod.ID())
+		}
+
+		podCtrsBkt := podDB.Bucket(containersBkt)
+		if podCtrsBkt == nil {
+			return errors.Wrapf(define.ErrInternal, "pod %s does not have a containers bucket", pod.ID())
+		}
+
+		// Traverse all containers in the pod with a cursor
+		// for-each has issues with data mutation
+		err = podCtrsBkt.ForEach(func(id, name []byte) error {
+			// Get the container so we can check dependencies
+			ctr := ctrBkt.Bucket(id)
+			if ctr == nil {
+				// This should never happen
+				// State is inconsistent
+				return errors.Wrapf(define.ErrNoSuchCtr, "pod %s referenced nonexistant container %s", pod.ID(), string(id))
+			}
+			ctrDeps := ctr.Bucket(dependenciesBkt)
+			// This should never be nil, but if it is, we're
+			// removing it anyways, so continue if it is
+			if ctrDeps != nil {
+				err = ctrDeps.ForEach(func(depID, name []byte) error {
+					exists := podCtrsBkt.Get(depID)
+					if exists == nil {
+						return errors.Wrapf(define.ErrCtrExists, "container %s has dependency %s outside of pod %s", string(id), string(depID), pod.ID())
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			// Dependencies are set, we're clear to remove
+
+			if err := ctrBkt.DeleteBucket(id); err != nil {
+				return errors.Wrapf(define.ErrInternal, "error deleting container %s from DB", string(id))
+			}
+
+			if err := idsBkt.Delete(id); err != nil {
+				return errors.Wrapf(err, "error deleting container %s ID in DB", string(id))
+			}
+
+			if err := namesBkt.Delete(name); err != nil {
+				return errors.Wrapf(err, "error deleting container %s name in DB", string(id))
+			}
+
+			if err := allCtrsBkt.Delete(id); err != nil {
+				return errors.Wrapf(err, "error deleting container %s ID from all containers bucket in DB", string(id))
+			}
+
+			if err := pendingRemovalBkt.Delete(id); err != nil {
+				return errors.Wrapf(err, "error deleting container %s from pending removal bucket in DB", string(id))
+			}
+
+			if err := removeCtrExecSessions(execBucket, id); err != nil {
+				return err
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Delete and recreate the bucket to empty it
+		if err := podDB.DeleteBucket(containersBkt); err != nil {
+			return errors.Wrapf(err, "error removing pod %s containers bucket", pod.ID())
+		}
+		if _, err := podDB.CreateBucket(containersBkt); err != nil {
+			return errors.Wrapf(err, "error recreating pod %s containers bucket", pod.ID())
+		}
+		if podDB.Bucket(initContainersBkt) != nil {
+			if err := podDB.DeleteBucket(initContainersBkt); err != nil {
+				return errors.Wrapf(err, "error removing pod %s init containers bucket", pod.ID())
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddContainerToPod adds the given container to an existing pod
+// The container will be added to the state and the pod
+func (s *BoltState) AddContainerToPod(pod *Pod, ctr *Container) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return define.ErrPodRemoved
+	}
+
+	if !ctr.valid {
+		return define.ErrCtrRemoved
+	}
+
+	if ctr.config.Pod != pod.ID() {
+		return errors.Wrapf(define.ErrNoSuchCtr, "container %s is not part of pod %s", ctr.ID(), pod.ID())
+	}
+
+	return s.addContainer(ctr, pod)
+}
+
+// RemoveContainerFromPod removes a container from an existing pod
+// The container will also be removed from the state
+func (s *BoltState) RemoveContainerFromPod(pod *Pod, ctr *Container) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return define.ErrPodRemoved
+	}
+
+	if s.namespace != "" {
+		if s.namespace != pod.config.Namespace {
+			return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+		}
+		if s.namespace != ctr.config.Namespace {
+			return errors.Wrapf(define.ErrNSMismatch, "container %s in in namespace %q but we are in namespace %q", ctr.ID(), ctr.config.Namespace, s.namespace)
+		}
+	}
+
+	if ctr.config.Pod == "" {
+		return errors.Wrapf(define.ErrNoSuchPod, "container %s is not part of a pod, use RemoveContainer instead", ctr.ID())
+	}
+
+	if ctr.config.Pod != pod.ID() {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s is not part of pod %s", ctr.ID(), pod.ID())
+	}
+
+	db, err := s.getDBConSync()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		return s.removeContainer(ctr, pod, tx)
+	})
+	return err
+}
+
+// UpdatePod updates a pod's state from the database
+func (s *BoltState) UpdatePod(pod *Pod) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	if !pod.valid {
+		return define.ErrPodRemoved
+	}
+
+	if s.namespace != "" && s.namespace != pod.config.Namespace {
+		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	}
+
+	newState := new(podState)
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	podID := []byte(pod.ID())
+
+	err = db.View(func(tx *bolt.Tx) error {
+		podBkt, err := getPodBucket(tx)
+		if err != nil {
+			return err
 		}
 
-		if err := newPod.Put(stateKey, podStateJSON); err != nil {
-			return errors.Wrapf(err, "error storing pod %s state JSON in DB", pod.ID())
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "no pod with ID %s found in database", pod.ID())
 		}
 
-		if podNamespace != nil {
-			if err := newPod.Put(namespaceKey, podNamespace); err != nil {
-				return errors.Wrapf(err, "error storing pod %s namespace in DB", pod.ID())
-			}
-			if err := nsBkt.Put(podID, podNamespace); err != nil {
-				return errors.Wrapf(err, "error storing pod %s namespace in DB", pod.ID())
-			}
+		// Get the pod state JSON
+		podStateBytes := podDB.Get(stateKey)
+		if podStateBytes == nil {
+			return errors.Wrapf(define.ErrInternal, "pod %s is missing state key in DB", pod.ID())
 		}
 
-		// Add us to the ID and names buckets
-		if err := idsBkt.Put(podID, podName); err != nil {
-			return errors.Wrapf(err, "error storing pod %s ID in DB", pod.ID())
-		}
-		if err := namesBkt.Put(podName, podID); err != nil {
-			return errors.Wrapf(err, "error storing pod %s name in DB", pod.Name())
-		}
-		if err := allPodsBkt.Put(podID, podName); err != nil {
-			return errors.Wrapf(err, "error storing pod %s in all pods bucket in DB", pod.ID())
+		if err := json.Unmarshal(podStateBytes, newState); err != nil {
+			return errors.Wrapf(err, "error unmarshalling pod %s state JSON", pod.ID())
 		}
 
 		return nil
@@ -1801,12 +3346,13 @@ func (s *BoltState) AddPod(pod *Pod) error {
 		return err
 	}
 
+	pod.state = newState
+
 	return nil
 }
 
-// RemovePod removes the given pod from the state
-// Only empty pods can be removed
-func (s *BoltState) RemovePod(pod *Pod) error {
+// SavePod saves a pod's state to the database
+func (s *BoltState) SavePod(pod *Pod) error {
 	if !s.valid {
 		return define.ErrDBClosed
 	}
@@ -1819,8 +3365,10 @@ func (s *BoltState) RemovePod(pod *Pod) error {
 		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
 	}
 
-	podID := []byte(pod.ID())
-	podName := []byte(pod.Name())
+	stateJSON, err := json.Marshal(pod.state)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling pod %s state to JSON", pod.ID())
+	}
 
 	db, err := s.getDBCon()
 	if err != nil {
@@ -1828,94 +3376,106 @@ func (s *BoltState) RemovePod(pod *Pod) error {
 	}
 	defer s.deferredCloseDBCon(db)
 
+	podID := []byte(pod.ID())
+
 	err = db.Update(func(tx *bolt.Tx) error {
 		podBkt, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		allPodsBkt, err := getAllPodsBucket(tx)
-		if err != nil {
-			return err
+		podDB := podBkt.Bucket(podID)
+		if podDB == nil {
+			pod.valid = false
+			return errors.Wrapf(define.ErrNoSuchPod, "no pod with ID %s found in database", pod.ID())
 		}
 
-		idsBkt, err := getIDBucket(tx)
-		if err != nil {
-			return err
+		// Set the pod state JSON
+		if err := podDB.Put(stateKey, stateJSON); err != nil {
+			return errors.Wrapf(err, "error updating pod %s state in database", pod.ID())
 		}
 
-		namesBkt, err := getNamesBucket(tx)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AllPods returns all pods present in the state
+func (s *BoltState) AllPods() ([]*Pod, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	pods := []*Pod{}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		allPodsBucket, err := getAllPodsBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		nsBkt, err := getNSBucket(tx)
+		podBucket, err := getPodBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// Check if the pod exists
-		podDB := podBkt.Bucket(podID)
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "pod %s does not exist in DB", pod.ID())
-		}
-
-		// Check if pod is empty
-		// This should never be nil
-		// But if it is, we can assume there are no containers in the
-		// pod.
-		// So let's eject the malformed pod without error.
-		podCtrsBkt := podDB.Bucket(containersBkt)
-		if podCtrsBkt != nil {
-			cursor := podCtrsBkt.Cursor()
-			if id, _ := cursor.First(); id != nil {
-				return errors.Wrapf(define.ErrCtrExists, "pod %s is not empty", pod.ID())
+		err = allPodsBucket.ForEach(func(id, name []byte) error {
+			podExists := podBucket.Bucket(id)
+			// This check can be removed if performance becomes an
+			// issue, but much less helpful errors will be produced
+			if podExists == nil {
+				return errors.Wrapf(define.ErrInternal, "inconsistency in state - pod %s is in all pods bucket but pod not found", string(id))
 			}
-		}
 
-		// Pod is empty, and ready for removal
-		// Let's kick it out
-		if err := idsBkt.Delete(podID); err != nil {
-			return errors.Wrapf(err, "error removing pod %s ID from DB", pod.ID())
-		}
-		if err := namesBkt.Delete(podName); err != nil {
-			return errors.Wrapf(err, "error removing pod %s name (%s) from DB", pod.ID(), pod.Name())
-		}
-		if err := nsBkt.Delete(podID); err != nil {
-			return errors.Wrapf(err, "error removing pod %s namespace from DB", pod.ID())
-		}
-		if err := allPodsBkt.Delete(podID); err != nil {
-			return errors.Wrapf(err, "error removing pod %s ID from all pods bucket in DB", pod.ID())
-		}
-		if err := podBkt.DeleteBucket(podID); err != nil {
-			return errors.Wrapf(err, "error removing pod %s from DB", pod.ID())
-		}
+			pod := new(Pod)
+			pod.config = new(PodConfig)
+			pod.state = new(podState)
 
-		return nil
+			if err := s.getPodFromDB(id, pod, podBucket); err != nil {
+				if errors.Cause(err) != define.ErrNSMismatch {
+					logrus.Errorf("Error retrieving pod %s from the database: %v", string(id), err)
+				}
+			} else {
+				pods = append(pods, pod)
+			}
+
+			return nil
+		})
+		return err
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return pods, nil
 }
 
-// RemovePodContainers removes all containers in a pod
-func (s *BoltState) RemovePodContainers(pod *Pod) error {
+// AddNetwork adds the given network to the state.
+func (s *BoltState) AddNetwork(network *Network) error {
 	if !s.valid {
 		return define.ErrDBClosed
 	}
 
-	if !pod.valid {
-		return define.ErrPodRemoved
+	if !network.valid {
+		return define.ErrNetworkRemoved
 	}
 
-	if s.namespace != "" && s.namespace != pod.config.Namespace {
-		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
-	}
+	netName := []byte(network.Name())
 
-	podID := []byte(pod.ID())
+	netConfigJSON, err := json.Marshal(network.config)
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling network %s config to JSON", network.Name())
+	}
 
 	db, err := s.getDBCon()
 	if err != nil {
@@ -1924,246 +3484,356 @@ func (s *BoltState) RemovePodContainers(pod *Pod) error {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.Update(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+		netBkt, err := getNetBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		ctrBkt, err := getCtrBucket(tx)
+		allNetsBkt, err := getAllNetsBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		allCtrsBkt, err := getAllCtrsBucket(tx)
+		// Check if we already have a network with the given name
+		if netExists := allNetsBkt.Get(netName); netExists != nil {
+			return errors.Wrapf(define.ErrNetworkExists, "name %s is in use", network.Name())
+		}
+
+		newNet, err := netBkt.CreateBucket(netName)
 		if err != nil {
-			return err
+			return errors.Wrapf(err, "error creating bucket for network %s", network.Name())
 		}
 
-		idsBkt, err := getIDBucket(tx)
+		// Make a subbucket for the containers using the network.
+		// Dependent container IDs will be added and removed in
+		// addContainer/removeContainer.
+		if _, err := newNet.CreateBucket(netDependenciesBkt); err != nil {
+			return errors.Wrapf(err, "error creating bucket for containers using network %s", network.Name())
+		}
+
+		if err := newNet.Put(configKey, netConfigJSON); err != nil {
+			return errors.Wrapf(err, "error storing network %s configuration in DB", network.Name())
+		}
+
+		if err := allNetsBkt.Put(netName, netName); err != nil {
+			return errors.Wrapf(err, "error storing network %s in all networks bucket in DB", network.Name())
+		}
+
+		return nil
+	})
+	return err
+}
+
+// RemoveNetwork removes the given network from the state
+func (s *BoltState) RemoveNetwork(network *Network) error {
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	netName := []byte(network.Name())
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		netBkt, err := getNetBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		namesBkt, err := getNamesBucket(tx)
+		allNetsBkt, err := getAllNetsBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		// Check if the pod exists
-		podDB := podBkt.Bucket(podID)
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "pod %s does not exist in DB", pod.ID())
+		ctrBkt, err := getCtrBucket(tx)
+		if err != nil {
+			return err
 		}
 
-		podCtrsBkt := podDB.Bucket(containersBkt)
-		if podCtrsBkt == nil {
-			return errors.Wrapf(define.ErrInternal, "pod %s does not have a containers bucket", pod.ID())
+		netDB := netBkt.Bucket(netName)
+		if netDB == nil {
+			network.valid = false
+			return errors.Wrapf(define.ErrNoSuchNetwork, "network %s does not exist in DB", network.Name())
 		}
 
-		// Traverse all containers in the pod with a cursor
-		// for-each has issues with data mutation
-		err = podCtrsBkt.ForEach(func(id, name []byte) error {
-			// Get the container so we can check dependencies
-			ctr := ctrBkt.Bucket(id)
-			if ctr == nil {
-				// This should never happen
-				// State is inconsistent
-				return errors.Wrapf(define.ErrNoSuchCtr, "pod %s referenced nonexistant container %s", pod.ID(), string(id))
-			}
-			ctrDeps := ctr.Bucket(dependenciesBkt)
-			// This should never be nil, but if it is, we're
-			// removing it anyways, so continue if it is
-			if ctrDeps != nil {
-				err = ctrDeps.ForEach(func(depID, name []byte) error {
-					exists := podCtrsBkt.Get(depID)
-					if exists == nil {
-						return errors.Wrapf(define.ErrCtrExists, "container %s has dependency %s outside of pod %s", string(id), string(depID), pod.ID())
-					}
+		// Check if the network is not being used by any container
+		if netCtrsBkt := netDB.Bucket(netDependenciesBkt); netCtrsBkt != nil {
+			var deps []string
+			err = netCtrsBkt.ForEach(func(id, value []byte) error {
+				if ctrExists := ctrBkt.Bucket(id); ctrExists == nil {
 					return nil
-				})
-				if err != nil {
-					return err
 				}
+
+				deps = append(deps, string(id))
+				return nil
+			})
+			if err != nil {
+				return errors.Wrapf(err, "error getting list of dependencies from dependencies bucket for network %q", network.Name())
+			}
+			if len(deps) > 0 {
+				return errors.Wrapf(define.ErrNetworkBeingUsed, "network %s is being used by container(s) %s", network.Name(), strings.Join(deps, ","))
 			}
+		}
 
-			// Dependencies are set, we're clear to remove
+		if err := allNetsBkt.Delete(netName); err != nil {
+			return errors.Wrapf(err, "error removing network %s from all networks bucket in DB", network.Name())
+		}
+		if err := netBkt.DeleteBucket(netName); err != nil {
+			return errors.Wrapf(err, "error removing network %s from DB", network.Name())
+		}
+
+		return nil
+	})
+	return err
+}
+
+// AllNetworks returns all networks present in the state
+func (s *BoltState) AllNetworks() ([]*Network, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
+	}
+
+	networks := []*Network{}
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	defer s.deferredCloseDBCon(db)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		allNetsBucket, err := getAllNetsBucket(tx)
+		if err != nil {
+			return err
+		}
 
-			if err := ctrBkt.DeleteBucket(id); err != nil {
-				return errors.Wrapf(define.ErrInternal, "error deleting container %s from DB", string(id))
-			}
+		netBucket, err := getNetBucket(tx)
+		if err != nil {
+			return err
+		}
 
-			if err := idsBkt.Delete(id); err != nil {
-				return errors.Wrapf(err, "error deleting container %s ID in DB", string(id))
+		return allNetsBucket.ForEach(func(id, name []byte) error {
+			if netExists := netBucket.Bucket(id); netExists == nil {
+				return errors.Wrapf(define.ErrInternal, "inconsistency in state - network %s is in all networks bucket but network not found", string(id))
 			}
 
-			if err := namesBkt.Delete(name); err != nil {
-				return errors.Wrapf(err, "error deleting container %s name in DB", string(id))
-			}
+			network := new(Network)
+			network.config = new(NetworkConfig)
 
-			if err := allCtrsBkt.Delete(id); err != nil {
-				return errors.Wrapf(err, "error deleting container %s ID from all containers bucket in DB", string(id))
+			if err := s.getNetworkFromDB(id, network, netBucket); err != nil {
+				logrus.Errorf("Error retrieving network %s from the database: %v", string(id), err)
+				return nil
 			}
 
+			networks = append(networks, network)
 			return nil
 		})
-		if err != nil {
-			return err
-		}
-
-		// Delete and recreate the bucket to empty it
-		if err := podDB.DeleteBucket(containersBkt); err != nil {
-			return errors.Wrapf(err, "error removing pod %s containers bucket", pod.ID())
-		}
-		if _, err := podDB.CreateBucket(containersBkt); err != nil {
-			return errors.Wrapf(err, "error recreating pod %s containers bucket", pod.ID())
-		}
-
-		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return networks, nil
 }
 
-// AddContainerToPod adds the given container to an existing pod
-// The container will be added to the state and the pod
-func (s *BoltState) AddContainerToPod(pod *Pod, ctr *Container) error {
-	if !s.valid {
-		return define.ErrDBClosed
+// Network retrieves a network from full name
+func (s *BoltState) Network(name string) (*Network, error) {
+	if name == "" {
+		return nil, define.ErrEmptyID
 	}
 
-	if !pod.valid {
-		return define.ErrPodRemoved
+	if !s.valid {
+		return nil, define.ErrDBClosed
 	}
 
-	if !ctr.valid {
-		return define.ErrCtrRemoved
+	netName := []byte(name)
+
+	network := new(Network)
+	network.config = new(NetworkConfig)
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
 	}
+	defer s.deferredCloseDBCon(db)
 
-	if ctr.config.Pod != pod.ID() {
-		return errors.Wrapf(define.ErrNoSuchCtr, "container %s is not part of pod %s", ctr.ID(), pod.ID())
+	err = db.View(func(tx *bolt.Tx) error {
+		netBkt, err := getNetBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		return s.getNetworkFromDB(netName, network, netBkt)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.addContainer(ctr, pod)
+	return network, nil
 }
 
-// RemoveContainerFromPod removes a container from an existing pod
-// The container will also be removed from the state
-func (s *BoltState) RemoveContainerFromPod(pod *Pod, ctr *Container) error {
+// HasNetwork returns true if the given network exists in the state, otherwise
+// it returns false
+func (s *BoltState) HasNetwork(name string) (bool, error) {
+	if name == "" {
+		return false, define.ErrEmptyID
+	}
+
 	if !s.valid {
-		return define.ErrDBClosed
+		return false, define.ErrDBClosed
 	}
 
-	if !pod.valid {
-		return define.ErrPodRemoved
+	netName := []byte(name)
+
+	exists := false
+
+	db, err := s.getDBCon()
+	if err != nil {
+		return false, err
 	}
+	defer s.deferredCloseDBCon(db)
 
-	if s.namespace != "" {
-		if s.namespace != pod.config.Namespace {
-			return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	err = db.View(func(tx *bolt.Tx) error {
+		netBkt, err := getNetBucket(tx)
+		if err != nil {
+			return err
 		}
-		if s.namespace != ctr.config.Namespace {
-			return errors.Wrapf(define.ErrNSMismatch, "container %s in in namespace %q but we are in namespace %q", ctr.ID(), ctr.config.Namespace, s.namespace)
+
+		if netDB := netBkt.Bucket(netName); netDB != nil {
+			exists = true
 		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
 	}
 
-	if ctr.config.Pod == "" {
-		return errors.Wrapf(define.ErrNoSuchPod, "container %s is not part of a pod, use RemoveContainer instead", ctr.ID())
+	return exists, nil
+}
+
+// NetworkInUse checks if any container is using the network
+// It returns a slice of the IDs of the containers using the given
+// network. If the slice is empty, no containers use the given network
+func (s *BoltState) NetworkInUse(network *Network) ([]string, error) {
+	if !s.valid {
+		return nil, define.ErrDBClosed
 	}
 
-	if ctr.config.Pod != pod.ID() {
-		return errors.Wrapf(define.ErrInvalidArg, "container %s is not part of pod %s", ctr.ID(), pod.ID())
+	if !network.valid {
+		return nil, define.ErrNetworkRemoved
 	}
 
+	depCtrs := []string{}
+
 	db, err := s.getDBCon()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer s.deferredCloseDBCon(db)
 
-	err = db.Update(func(tx *bolt.Tx) error {
-		return s.removeContainer(ctr, pod, tx)
-	})
-	return err
-}
+	err = db.View(func(tx *bolt.Tx) error {
+		netBucket, err := getNetBucket(tx)
+		if err != nil {
+			return err
+		}
 
-// UpdatePod updates a pod's state from the database
-func (s *BoltState) UpdatePod(pod *Pod) error {
-	if !s.valid {
-		return define.ErrDBClosed
+		ctrBucket, err := getCtrBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		netDB := netBucket.Bucket([]byte(network.Name()))
+		if netDB == nil {
+			network.valid = false
+			return errors.Wrapf(define.ErrNoSuchNetwork, "no network with name %s found in DB", network.Name())
+		}
+
+		dependsBkt := netDB.Bucket(netDependenciesBkt)
+		if dependsBkt == nil {
+			return errors.Wrapf(define.ErrInternal, "network %s has no dependencies bucket", network.Name())
+		}
+
+		return dependsBkt.ForEach(func(id, value []byte) error {
+			// Look up all dependencies and see that they
+			// still exist before appending.
+			if ctrExists := ctrBucket.Bucket(id); ctrExists == nil {
+				return nil
+			}
+
+			depCtrs = append(depCtrs, string(id))
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if !pod.valid {
-		return define.ErrPodRemoved
+	return depCtrs, nil
+}
+
+// PodTemplate retrieves the pod template with the given name from the state
+func (s *BoltState) PodTemplate(name string) (*PodTemplate, error) {
+	if name == "" {
+		return nil, define.ErrEmptyID
 	}
 
-	if s.namespace != "" && s.namespace != pod.config.Namespace {
-		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
+	if !s.valid {
+		return nil, define.ErrDBClosed
 	}
 
-	newState := new(podState)
+	tmpl := new(PodTemplate)
 
 	db, err := s.getDBCon()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer s.deferredCloseDBCon(db)
 
-	podID := []byte(pod.ID())
-
 	err = db.View(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+		tmplBkt, err := getPodTemplateBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		podDB := podBkt.Bucket(podID)
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "no pod with ID %s found in database", pod.ID())
-		}
-
-		// Get the pod state JSON
-		podStateBytes := podDB.Get(stateKey)
-		if podStateBytes == nil {
-			return errors.Wrapf(define.ErrInternal, "pod %s is missing state key in DB", pod.ID())
+		tmplJSON := tmplBkt.Get([]byte(name))
+		if tmplJSON == nil {
+			return errors.Wrapf(define.ErrNoSuchPodTemplate, "pod template with name %s not found", name)
 		}
 
-		if err := json.Unmarshal(podStateBytes, newState); err != nil {
-			return errors.Wrapf(err, "error unmarshalling pod %s state JSON", pod.ID())
+		if err := json.Unmarshal(tmplJSON, tmpl); err != nil {
+			return errors.Wrapf(err, "error unmarshalling pod template %s from DB", name)
 		}
 
 		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	pod.state = newState
-
-	return nil
+	return tmpl, nil
 }
 
-// SavePod saves a pod's state to the database
-func (s *BoltState) SavePod(pod *Pod) error {
-	if !s.valid {
-		return define.ErrDBClosed
+// AddPodTemplate adds the given pod template to the state
+func (s *BoltState) AddPodTemplate(tmpl *PodTemplate) error {
+	if tmpl.Name == "" {
+		return define.ErrEmptyID
 	}
 
-	if !pod.valid {
-		return define.ErrPodRemoved
+	if !s.valid {
+		return define.ErrDBClosed
 	}
 
-	if s.namespace != "" && s.namespace != pod.config.Namespace {
-		return errors.Wrapf(define.ErrNSMismatch, "pod %s is in namespace %q but we are in namespace %q", pod.ID(), pod.config.Namespace, s.namespace)
-	}
+	tmplName := []byte(tmpl.Name)
 
-	stateJSON, err := json.Marshal(pod.state)
+	tmplJSON, err := json.Marshal(tmpl)
 	if err != nil {
-		return errors.Wrapf(err, "error marshalling pod %s state to JSON", pod.ID())
+		return errors.Wrapf(err, "error marshalling pod template %s to JSON", tmpl.Name)
 	}
 
 	db, err := s.getDBCon()
@@ -2172,41 +3842,70 @@ func (s *BoltState) SavePod(pod *Pod) error {
 	}
 	defer s.deferredCloseDBCon(db)
 
-	podID := []byte(pod.ID())
-
 	err = db.Update(func(tx *bolt.Tx) error {
-		podBkt, err := getPodBucket(tx)
+		tmplBkt, err := getPodTemplateBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		podDB := podBkt.Bucket(podID)
-		if podDB == nil {
-			pod.valid = false
-			return errors.Wrapf(define.ErrNoSuchPod, "no pod with ID %s found in database", pod.ID())
+		if tmplExists := tmplBkt.Get(tmplName); tmplExists != nil {
+			return errors.Wrapf(define.ErrPodTemplateExists, "name %s is in use", tmpl.Name)
 		}
 
-		// Set the pod state JSON
-		if err := podDB.Put(stateKey, stateJSON); err != nil {
-			return errors.Wrapf(err, "error updating pod %s state in database", pod.ID())
+		if err := tmplBkt.Put(tmplName, tmplJSON); err != nil {
+			return errors.Wrapf(err, "error storing pod template %s in DB", tmpl.Name)
 		}
 
 		return nil
 	})
+	return err
+}
+
+// RemovePodTemplate removes the pod template with the given name from the
+// state
+func (s *BoltState) RemovePodTemplate(name string) error {
+	if name == "" {
+		return define.ErrEmptyID
+	}
+
+	if !s.valid {
+		return define.ErrDBClosed
+	}
+
+	tmplName := []byte(name)
+
+	db, err := s.getDBCon()
 	if err != nil {
 		return err
 	}
+	defer s.deferredCloseDBCon(db)
 
-	return nil
+	err = db.Update(func(tx *bolt.Tx) error {
+		tmplBkt, err := getPodTemplateBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		if tmplExists := tmplBkt.Get(tmplName); tmplExists == nil {
+			return errors.Wrapf(define.ErrNoSuchPodTemplate, "pod template %s does not exist in DB", name)
+		}
+
+		if err := tmplBkt.Delete(tmplName); err != nil {
+			return errors.Wrapf(err, "error removing pod template %s from DB", name)
+		}
+
+		return nil
+	})
+	return err
 }
 
-// AllPods returns all pods present in the state
-func (s *BoltState) AllPods() ([]*Pod, error) {
+// AllPodTemplates returns all pod templates present in the state
+func (s *BoltState) AllPodTemplates() ([]*PodTemplate, error) {
 	if !s.valid {
 		return nil, define.ErrDBClosed
 	}
 
-	pods := []*Pod{}
+	tmpls := []*PodTemplate{}
 
 	db, err := s.getDBCon()
 	if err != nil {
@@ -2215,43 +3914,25 @@ func (s *BoltState) AllPods() ([]*Pod, error) {
 	defer s.deferredCloseDBCon(db)
 
 	err = db.View(func(tx *bolt.Tx) error {
-		allPodsBucket, err := getAllPodsBucket(tx)
-		if err != nil {
-			return err
-		}
-
-		podBucket, err := getPodBucket(tx)
+		tmplBkt, err := getPodTemplateBucket(tx)
 		if err != nil {
 			return err
 		}
 
-		err = allPodsBucket.ForEach(func(id, name []byte) error {
-			podExists := podBucket.Bucket(id)
-			// This check can be removed if performance becomes an
-			// issue, but much less helpful errors will be produced
-			if podExists == nil {
-				return errors.Wrapf(define.ErrInternal, "inconsistency in state - pod %s is in all pods bucket but pod not found", string(id))
-			}
-
-			pod := new(Pod)
-			pod.config = new(PodConfig)
-			pod.state = new(podState)
-
-			if err := s.getPodFromDB(id, pod, podBucket); err != nil {
-				if errors.Cause(err) != define.ErrNSMismatch {
-					logrus.Errorf("Error retrieving pod %s from the database: %v", string(id), err)
-				}
-			} else {
-				pods = append(pods, pod)
+		return tmplBkt.ForEach(func(name, tmplJSON []byte) error {
+			tmpl := new(PodTemplate)
+			if err := json.Unmarshal(tmplJSON, tmpl); err != nil {
+				logrus.Errorf("Error unmarshalling pod template %s from the database: %v", string(name), err)
+				return nil
 			}
 
+			tmpls = append(tmpls, tmpl)
 			return nil
 		})
-		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return pods, nil
+	return tmpls, nil
 }
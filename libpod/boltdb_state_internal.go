@@ -2,9 +2,11 @@ package libpod
 
 import (
 	"bytes"
+	"fmt"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/pkg/rootless"
@@ -15,23 +17,35 @@ import (
 )
 
 const (
-	idRegistryName    = "id-registry"
-	nameRegistryName  = "name-registry"
-	nsRegistryName    = "ns-registry"
-	ctrName           = "ctr"
-	allCtrsName       = "all-ctrs"
-	podName           = "pod"
-	allPodsName       = "allPods"
-	volName           = "vol"
-	allVolsName       = "allVolumes"
-	runtimeConfigName = "runtime-config"
+	idRegistryName     = "id-registry"
+	nameRegistryName   = "name-registry"
+	nsRegistryName     = "ns-registry"
+	ctrName            = "ctr"
+	allCtrsName        = "all-ctrs"
+	podName            = "pod"
+	allPodsName        = "allPods"
+	volName            = "vol"
+	allVolsName        = "allVolumes"
+	netName            = "network"
+	allNetsName        = "allNetworks"
+	podTemplateName    = "pod-templates"
+	runtimeConfigName  = "runtime-config"
+	pendingRemovalName = "pending-removal"
+	ipamName           = "ipam"
+	execName           = "exec-sessions"
+	exitCodeName       = "exit-code"
+	usernsName         = "userns"
 
 	configName         = "config"
 	stateName          = "state"
+	summaryName        = "summary"
 	dependenciesName   = "dependencies"
+	depConditionsName  = "dependency-conditions"
 	volCtrDependencies = "vol-dependencies"
+	netCtrDependencies = "net-dependencies"
 	netNSName          = "netns"
 	containersName     = "containers"
+	initContainersName = "init-containers"
 	podIDName          = "pod-id"
 	namespaceName      = "namespace"
 
@@ -45,23 +59,35 @@ const (
 )
 
 var (
-	idRegistryBkt    = []byte(idRegistryName)
-	nameRegistryBkt  = []byte(nameRegistryName)
-	nsRegistryBkt    = []byte(nsRegistryName)
-	ctrBkt           = []byte(ctrName)
-	allCtrsBkt       = []byte(allCtrsName)
-	podBkt           = []byte(podName)
-	allPodsBkt       = []byte(allPodsName)
-	volBkt           = []byte(volName)
-	allVolsBkt       = []byte(allVolsName)
-	runtimeConfigBkt = []byte(runtimeConfigName)
+	idRegistryBkt     = []byte(idRegistryName)
+	nameRegistryBkt   = []byte(nameRegistryName)
+	nsRegistryBkt     = []byte(nsRegistryName)
+	ctrBkt            = []byte(ctrName)
+	allCtrsBkt        = []byte(allCtrsName)
+	podBkt            = []byte(podName)
+	allPodsBkt        = []byte(allPodsName)
+	volBkt            = []byte(volName)
+	allVolsBkt        = []byte(allVolsName)
+	netBkt            = []byte(netName)
+	allNetsBkt        = []byte(allNetsName)
+	podTemplateBkt    = []byte(podTemplateName)
+	runtimeConfigBkt  = []byte(runtimeConfigName)
+	pendingRemovalBkt = []byte(pendingRemovalName)
+	ipamBkt           = []byte(ipamName)
+	execBkt           = []byte(execName)
+	exitCodeBkt       = []byte(exitCodeName)
+	usernsBkt         = []byte(usernsName)
 
 	configKey          = []byte(configName)
 	stateKey           = []byte(stateName)
+	summaryKey         = []byte(summaryName)
 	dependenciesBkt    = []byte(dependenciesName)
+	depConditionsBkt   = []byte(depConditionsName)
 	volDependenciesBkt = []byte(volCtrDependencies)
+	netDependenciesBkt = []byte(netCtrDependencies)
 	netNSKey           = []byte(netNSName)
 	containersBkt      = []byte(containersName)
+	initContainersBkt  = []byte(initContainersName)
 	podIDKey           = []byte(podIDName)
 	namespaceKey       = []byte(namespaceName)
 
@@ -72,6 +98,8 @@ var (
 	graphDriverKey = []byte(graphDriverName)
 	osKey          = []byte(osName)
 	volPathKey     = []byte(volPathName)
+
+	namespaceTmpDirPrefix = []byte(tmpDirName + "-ns-")
 )
 
 // This represents a field in the runtime configuration that will be validated
@@ -138,6 +166,32 @@ func checkRuntimeConfig(db *bolt.DB, rt *Runtime) error {
 		},
 	}
 
+	// Directories configured for libpod namespaces must be usable, and the
+	// temporary files directory of a namespace cannot change while its
+	// containers may be running
+	for namespace, nsDirs := range rt.config.NamespaceDirs {
+		if namespace == "" {
+			return errors.Wrapf(define.ErrInvalidArg, "directories cannot be configured for the empty namespace, use the runtime's directories instead")
+		}
+		for _, dir := range []string{nsDirs.TmpDir, nsDirs.EventsLogFilePath, nsDirs.LogDir} {
+			if dir != "" && !filepath.IsAbs(dir) {
+				return errors.Wrapf(define.ErrInvalidArg, "directories for namespace %s must be absolute paths, got %q", namespace, dir)
+			}
+		}
+		if nsDirs.TmpDir == "" {
+			continue
+		}
+		if filepath.Clean(nsDirs.TmpDir) == filepath.Clean(rt.config.TmpDir) {
+			return errors.Wrapf(define.ErrInvalidArg, "tmp dir of namespace %s must differ from the runtime's tmp dir %s", namespace, rt.config.TmpDir)
+		}
+		checks = append(checks, dbConfigValidation{
+			fmt.Sprintf("libpod temporary files directory (tmpdir) of namespace %s", namespace),
+			nsDirs.TmpDir,
+			namespaceTmpDirKey(namespace),
+			"",
+		})
+	}
+
 	// These fields were missing and will have to be recreated.
 	missingFields := []dbConfigValidation{}
 
@@ -148,9 +202,35 @@ func checkRuntimeConfig(db *bolt.DB, rt *Runtime) error {
 			return err
 		}
 
+		// A namespace's tmp dir cannot be removed from the
+		// configuration once set
+		err = configBkt.ForEach(func(key, value []byte) error {
+			if !bytes.HasPrefix(key, namespaceTmpDirPrefix) {
+				return nil
+			}
+			namespace := string(bytes.TrimPrefix(key, namespaceTmpDirPrefix))
+			if rt.config.NamespaceDirs[namespace].TmpDir == "" {
+				return errors.Wrapf(define.ErrDBBadConfig, "database has libpod temporary files directory (tmpdir) %q for namespace %s, but none is configured", string(value), namespace)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
 		for _, check := range checks {
 			exists, err := readOnlyValidateConfig(configBkt, check)
 			if err != nil {
+				// A graph driver change can be handled by
+				// re-adopting container storage once the
+				// store is available.
+				if bytes.Equal(check.key, graphDriverKey) && errors.Cause(err) == define.ErrDBBadConfig {
+					if rt.doReadoptStorage {
+						logrus.Warnf("Storage graph driver changed from %q to %q, will attempt to re-adopt existing containers", string(configBkt.Get(graphDriverKey)), check.runtimeValue)
+						continue
+					}
+					return errors.Wrapf(err, "graph driver changes require existing containers to be re-adopted or removed")
+				}
 				return err
 			}
 			if !exists {
@@ -190,6 +270,14 @@ func checkRuntimeConfig(db *bolt.DB, rt *Runtime) error {
 	})
 }
 
+// namespaceTmpDirKey returns the DB runtime configuration key holding the tmp
+// dir of the given libpod namespace.
+func namespaceTmpDirKey(namespace string) []byte {
+	key := make([]byte, 0, len(namespaceTmpDirPrefix)+len(namespace))
+	key = append(key, namespaceTmpDirPrefix...)
+	return append(key, namespace...)
+}
+
 // Attempt a read-only validation of a configuration entry in the DB against an
 // element of the current runtime configuration.
 // If the configuration key in question does not exist, (false, nil) will be
@@ -237,9 +325,11 @@ func (s *BoltState) getDBCon() (*bolt.DB, error) {
 	// We need an in-memory lock to avoid issues around POSIX file advisory
 	// locks as described in the link below:
 	// https://www.sqlite.org/src/artifact/c230a7a24?ln=994-1081
+	start := time.Now()
 	s.dbLock.Lock()
+	s.dbOpStart = start
 
-	db, err := bolt.Open(s.dbPath, 0600, nil)
+	db, err := bolt.Open(s.dbPath, 0600, s.dbOptions)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error opening database %s", s.dbPath)
 	}
@@ -262,6 +352,7 @@ func (s *BoltState) deferredCloseDBCon(db *bolt.DB) {
 func (s *BoltState) closeDBCon(db *bolt.DB) error {
 	err := db.Close()
 
+	observeStateOperation(s.dbOpStart, "closeDBCon", "deferredCloseDBCon")
 	s.dbLock.Unlock()
 
 	return err
@@ -339,6 +430,30 @@ func getAllVolsBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
 	return bkt, nil
 }
 
+func getNetBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(netBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "networks bucket not found in DB")
+	}
+	return bkt, nil
+}
+
+func getAllNetsBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(allNetsBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "all networks bucket not found in DB")
+	}
+	return bkt, nil
+}
+
+func getPodTemplateBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(podTemplateBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "pod templates bucket not found in DB")
+	}
+	return bkt, nil
+}
+
 func getRuntimeConfigBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
 	bkt := tx.Bucket(runtimeConfigBkt)
 	if bkt == nil {
@@ -347,6 +462,107 @@ func getRuntimeConfigBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
 	return bkt, nil
 }
 
+func getPendingRemovalBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(pendingRemovalBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "pending removal bucket not found in DB")
+	}
+	return bkt, nil
+}
+
+func getIPAMBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(ipamBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "IPAM reservations bucket not found in DB")
+	}
+	return bkt, nil
+}
+
+func getExecBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(execBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "exec sessions bucket not found in DB")
+	}
+	return bkt, nil
+}
+
+func getExitCodeBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(exitCodeBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "exit codes bucket not found in DB")
+	}
+	return bkt, nil
+}
+
+func getUserNSBucket(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bkt := tx.Bucket(usernsBkt)
+	if bkt == nil {
+		return nil, errors.Wrapf(define.ErrDBBadConfig, "user namespace allocations bucket not found in DB")
+	}
+	return bkt, nil
+}
+
+// removeCtrExecSessions deletes the registrations of the exec sessions of a
+// container from the exec sessions bucket
+func removeCtrExecSessions(execBucket *bolt.Bucket, ctrID []byte) error {
+	// Keys cannot be deleted while iterating over the bucket
+	var sessions [][]byte
+	err := execBucket.ForEach(func(id, sessionCtrID []byte) error {
+		if bytes.Equal(sessionCtrID, ctrID) {
+			sessions = append(sessions, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, id := range sessions {
+		if err := execBucket.Delete(id); err != nil {
+			return errors.Wrapf(err, "error deleting exec session %s of container %s from DB", string(id), string(ctrID))
+		}
+	}
+	return nil
+}
+
+// reserveAddresses records the static addresses of a container in the IPAM
+// bucket, failing if another container has reserved any of them
+func reserveAddresses(ipamBucket *bolt.Bucket, ctrID []byte, addresses []addressReservation) error {
+	for _, address := range addresses {
+		if err := checkAddressNetwork(string(ctrID), address); err != nil {
+			return err
+		}
+		netBkt, err := ipamBucket.CreateBucketIfNotExists([]byte(address.Network))
+		if err != nil {
+			return errors.Wrapf(err, "error creating IPAM bucket for network %s", address.Network)
+		}
+		if owner := netBkt.Get([]byte(address.Address)); owner != nil && !bytes.Equal(owner, ctrID) {
+			return errAddressInUse(string(ctrID), address, string(owner))
+		}
+		if err := netBkt.Put([]byte(address.Address), ctrID); err != nil {
+			return errors.Wrapf(err, "error reserving address %s on network %s", address.Address, address.Network)
+		}
+	}
+	return nil
+}
+
+// releaseAddresses removes the reservations of a container's static addresses
+// from the IPAM bucket
+func releaseAddresses(ipamBucket *bolt.Bucket, ctrID []byte, addresses []addressReservation) error {
+	for _, address := range addresses {
+		netBkt := ipamBucket.Bucket([]byte(address.Network))
+		if netBkt == nil {
+			continue
+		}
+		if owner := netBkt.Get([]byte(address.Address)); !bytes.Equal(owner, ctrID) {
+			continue
+		}
+		if err := netBkt.Delete([]byte(address.Address)); err != nil {
+			return errors.Wrapf(err, "error releasing address %s on network %s", address.Address, address.Network)
+		}
+	}
+	return nil
+}
+
 func (s *BoltState) getContainerFromDB(id []byte, ctr *Container, ctrsBkt *bolt.Bucket) error {
 	ctrBkt := ctrsBkt.Bucket(id)
 	if ctrBkt == nil {
@@ -374,24 +590,17 @@ func (s *BoltState) getContainerFromDB(id []byte, ctr *Container, ctrsBkt *bolt.
 	if err != nil {
 		return errors.Wrapf(err, "error retrieving lock for container %s", string(id))
 	}
-	ctr.lock = lock
-
-	if ctr.config.OCIRuntime == "" {
-		ctr.ociRuntime = s.runtime.defaultOCIRuntime
-	} else {
-		// Handle legacy containers which might use a literal path for
-		// their OCI runtime name.
-		runtimeName := ctr.config.OCIRuntime
-		if strings.HasPrefix(runtimeName, "/") {
-			runtimeName = filepath.Base(runtimeName)
-		}
-
-		ociRuntime, ok := s.runtime.ociRuntimes[runtimeName]
-		if !ok {
-			return errors.Wrapf(define.ErrInternal, "container %s was created with OCI runtime %s, but that runtime is not available in the current configuration", ctr.ID(), ctr.config.OCIRuntime)
-		}
-		ctr.ociRuntime = ociRuntime
+	ctr.lock = ctrLocker(lock, ctr.ID())
+
+	// The runtime may be one provided by the embedder. Containers whose
+	// runtime was removed from the configuration can still be moved to
+	// another runtime.
+	ociRuntime, ok := s.runtime.getOCIRuntime(ctr.config.OCIRuntime)
+	if !ok {
+		logrus.Debugf("OCI runtime %s of container %s is not available in the current configuration", ctr.config.OCIRuntime, ctr.ID())
+		ociRuntime = &missingOCIRuntime{name: ctr.config.OCIRuntime}
 	}
+	ctr.ociRuntime = ociRuntime
 
 	ctr.runtime = s.runtime
 	ctr.valid = true
@@ -399,6 +608,43 @@ func (s *BoltState) getContainerFromDB(id []byte, ctr *Container, ctrsBkt *bolt.
 	return nil
 }
 
+// Get a container's summary from the DB.
+// If the container does not have a summary (for example, it was created before
+// summaries were stored), one is generated from its config and state.
+func getContainerSummaryFromDB(id []byte, ctrDB *bolt.Bucket) (*ContainerSummary, error) {
+	summary := new(ContainerSummary)
+
+	summaryBytes := ctrDB.Get(summaryKey)
+	if summaryBytes != nil {
+		if err := json.Unmarshal(summaryBytes, summary); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling container %s summary", string(id))
+		}
+		return summary, nil
+	}
+
+	ctr := new(Container)
+	ctr.config = new(ContainerConfig)
+	ctr.state = new(ContainerState)
+
+	configBytes := ctrDB.Get(configKey)
+	if configBytes == nil {
+		return nil, errors.Wrapf(define.ErrInternal, "container %s missing config key in DB", string(id))
+	}
+	if err := json.Unmarshal(configBytes, ctr.config); err != nil {
+		return nil, errors.Wrapf(err, "error unmarshalling container %s config", string(id))
+	}
+
+	stateBytes := ctrDB.Get(stateKey)
+	if stateBytes == nil {
+		return nil, errors.Wrapf(define.ErrInternal, "container %s missing state key in DB", string(id))
+	}
+	if err := json.Unmarshal(stateBytes, ctr.state); err != nil {
+		return nil, errors.Wrapf(err, "error unmarshalling container %s state", string(id))
+	}
+
+	return ctr.summary(), nil
+}
+
 func (s *BoltState) getPodFromDB(id []byte, pod *Pod, podBkt *bolt.Bucket) error {
 	podDB := podBkt.Bucket(id)
 	if podDB == nil {
@@ -426,7 +672,7 @@ func (s *BoltState) getPodFromDB(id []byte, pod *Pod, podBkt *bolt.Bucket) error
 	if err != nil {
 		return errors.Wrapf(err, "error retrieving lock for pod %s", string(id))
 	}
-	pod.lock = lock
+	pod.lock = podLocker(lock, pod.ID())
 
 	pod.runtime = s.runtime
 	pod.valid = true
@@ -449,6 +695,14 @@ func (s *BoltState) getVolumeFromDB(name []byte, volume *Volume, volBkt *bolt.Bu
 		return errors.Wrapf(err, "error unmarshalling volume %s config from DB", string(name))
 	}
 
+	// Volumes created before volume state was tracked have no state key.
+	volStateBytes := volDB.Get(stateKey)
+	if volStateBytes != nil {
+		if err := json.Unmarshal(volStateBytes, volume.state); err != nil {
+			return errors.Wrapf(err, "error unmarshalling volume %s state from DB", string(name))
+		}
+	}
+
 	// Get the lock
 	lock, err := s.runtime.lockManager.RetrieveLock(volume.config.LockID)
 	if err != nil {
@@ -462,6 +716,27 @@ func (s *BoltState) getVolumeFromDB(name []byte, volume *Volume, volBkt *bolt.Bu
 	return nil
 }
 
+func (s *BoltState) getNetworkFromDB(name []byte, network *Network, netBkt *bolt.Bucket) error {
+	netDB := netBkt.Bucket(name)
+	if netDB == nil {
+		return errors.Wrapf(define.ErrNoSuchNetwork, "network with name %s not found", string(name))
+	}
+
+	netConfigBytes := netDB.Get(configKey)
+	if netConfigBytes == nil {
+		return errors.Wrapf(define.ErrInternal, "network %s is missing configuration key in DB", string(name))
+	}
+
+	if err := json.Unmarshal(netConfigBytes, network.config); err != nil {
+		return errors.Wrapf(err, "error unmarshalling network %s config from DB", string(name))
+	}
+
+	network.runtime = s.runtime
+	network.valid = true
+
+	return nil
+}
+
 // Add a container to the DB
 // If pod is not nil, the container is added to the pod as well
 func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
@@ -480,8 +755,13 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 	if err != nil {
 		return errors.Wrapf(err, "error marshalling container %s state to JSON", ctr.ID())
 	}
+	summaryJSON, err := json.Marshal(ctr.summary())
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling container %s summary to JSON", ctr.ID())
+	}
 	netNSPath := getNetNSPath(ctr)
 	dependsCtrs := ctr.Dependencies()
+	addresses := ctr.staticAddresses(ctr.config)
 
 	ctrID := []byte(ctr.ID())
 	ctrName := []byte(ctr.Name())
@@ -491,7 +771,7 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 		ctrNamespace = []byte(ctr.config.Namespace)
 	}
 
-	db, err := s.getDBCon()
+	db, err := s.getDBConSync()
 	if err != nil {
 		return err
 	}
@@ -528,6 +808,16 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 			return err
 		}
 
+		netBkt, err := getNetBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		ipamBucket, err := getIPAMBucket(tx)
+		if err != nil {
+			return err
+		}
+
 		// If a pod was given, check if it exists
 		var podDB *bolt.Bucket
 		var podCtrs *bolt.Bucket
@@ -566,6 +856,12 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 			return errors.Wrapf(define.ErrCtrExists, "name %s is in use", ctr.Name())
 		}
 
+		// Reserve the container's static addresses, rejecting
+		// addresses already used by other containers
+		if err := reserveAddresses(ipamBucket, ctrID, addresses); err != nil {
+			return err
+		}
+
 		// No overlapping containers
 		// Add the new container to the DB
 		if err := idsBucket.Put(ctrID, ctrName); err != nil {
@@ -591,6 +887,9 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 		if err := newCtrBkt.Put(configKey, configJSON); err != nil {
 			return errors.Wrapf(err, "error adding container %s config to DB", ctr.ID())
 		}
+		if err := newCtrBkt.Put(summaryKey, summaryJSON); err != nil {
+			return errors.Wrapf(err, "error adding container %s summary to DB", ctr.ID())
+		}
 		if err := newCtrBkt.Put(stateKey, stateJSON); err != nil {
 			return errors.Wrapf(err, "error adding container %s state to DB", ctr.ID())
 		}
@@ -651,6 +950,15 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 			if err := depCtrDependsBkt.Put(ctrID, ctrName); err != nil {
 				return errors.Wrapf(err, "error adding ctr %s as dependency of container %s", ctr.ID(), dependsCtr)
 			}
+			// Containers created before conditions were recorded
+			// have no conditions bucket
+			depCtrConditionsBkt, err := depCtrBkt.CreateBucketIfNotExists(depConditionsBkt)
+			if err != nil {
+				return errors.Wrapf(err, "error creating dependency conditions bucket for container %s", dependsCtr)
+			}
+			if err := depCtrConditionsBkt.Put(ctrID, []byte(ctr.dependencyCondition(dependsCtr))); err != nil {
+				return errors.Wrapf(err, "error recording condition of ctr %s on container %s", ctr.ID(), dependsCtr)
+			}
 		}
 
 		// Add ctr to pod
@@ -658,6 +966,17 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 			if err := podCtrs.Put(ctrID, ctrName); err != nil {
 				return errors.Wrapf(err, "error adding container %s to pod %s", ctr.ID(), pod.ID())
 			}
+			// Init containers are also recorded separately, so
+			// they can be found without retrieving every container
+			if ctr.config.InitContainerType != "" {
+				podInitCtrs, err := podDB.CreateBucketIfNotExists(initContainersBkt)
+				if err != nil {
+					return errors.Wrapf(err, "error creating init containers bucket for pod %s", pod.ID())
+				}
+				if err := podInitCtrs.Put(ctrID, []byte(ctr.config.InitContainerType)); err != nil {
+					return errors.Wrapf(err, "error adding init container %s to pod %s", ctr.ID(), pod.ID())
+				}
+			}
 		}
 
 		// Add container to named volume dependencies buckets
@@ -675,6 +994,24 @@ func (s *BoltState) addContainer(ctr *Container, pod *Pod) error {
 			}
 		}
 
+		// Add container to network dependencies buckets.
+		// Networks not tracked in the DB (CNI configurations created
+		// outside of libpod) are not recorded.
+		for _, net := range ctr.config.Networks {
+			netDB := netBkt.Bucket([]byte(net))
+			if netDB == nil {
+				continue
+			}
+
+			ctrDepsBkt := netDB.Bucket(netDependenciesBkt)
+			if ctrDepsBkt == nil {
+				return errors.Wrapf(define.ErrInternal, "network %s has no dependencies bucket", net)
+			}
+			if err := ctrDepsBkt.Put(ctrID, ctrID); err != nil {
+				return errors.Wrapf(err, "error adding container %s to network %s dependencies", ctr.ID(), net)
+			}
+		}
+
 		return nil
 	})
 	return err
@@ -717,6 +1054,26 @@ func (s *BoltState) removeContainer(ctr *Container, pod *Pod, tx *bolt.Tx) error
 		return err
 	}
 
+	netBkt, err := getNetBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	ipamBucket, err := getIPAMBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	pendingRemovalBucket, err := getPendingRemovalBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	execBucket, err := getExecBucket(tx)
+	if err != nil {
+		return err
+	}
+
 	// Does the pod exist?
 	var podDB *bolt.Bucket
 	if pod != nil {
@@ -766,6 +1123,11 @@ func (s *BoltState) removeContainer(ctr *Container, pod *Pod, tx *bolt.Tx) error
 			if err := podCtrs.Delete(ctrID); err != nil {
 				return errors.Wrapf(err, "error removing container %s from pod %s", ctr.ID(), pod.ID())
 			}
+			if podInitCtrs := podDB.Bucket(initContainersBkt); podInitCtrs != nil && podInitCtrs.Get(ctrID) != nil {
+				if err := podInitCtrs.Delete(ctrID); err != nil {
+					return errors.Wrapf(err, "error removing init container %s from pod %s", ctr.ID(), pod.ID())
+				}
+			}
 		}
 	}
 
@@ -804,6 +1166,12 @@ func (s *BoltState) removeContainer(ctr *Container, pod *Pod, tx *bolt.Tx) error
 	if err := allCtrsBucket.Delete(ctrID); err != nil {
 		return errors.Wrapf(err, "error deleting container %s from all containers bucket in DB", ctr.ID())
 	}
+	if err := pendingRemovalBucket.Delete(ctrID); err != nil {
+		return errors.Wrapf(err, "error deleting container %s from pending removal bucket in DB", ctr.ID())
+	}
+	if err := removeCtrExecSessions(execBucket, ctrID); err != nil {
+		return err
+	}
 
 	depCtrs := ctr.Dependencies()
 
@@ -833,6 +1201,9 @@ func (s *BoltState) removeContainer(ctr *Container, pod *Pod, tx *bolt.Tx) error
 		if err := depCtrDependsBkt.Delete(ctrID); err != nil {
 			return errors.Wrapf(err, "error removing container %s as a dependency of container %s", ctr.ID(), depCtr)
 		}
+		if err := deleteDependencyCondition(depCtrBkt, ctrID); err != nil {
+			return errors.Wrapf(err, "error removing condition of container %s on container %s", ctr.ID(), depCtr)
+		}
 	}
 
 	// Remove container from named volume dependencies buckets
@@ -852,5 +1223,37 @@ func (s *BoltState) removeContainer(ctr *Container, pod *Pod, tx *bolt.Tx) error
 		}
 	}
 
+	if err := releaseAddresses(ipamBucket, ctrID, ctr.staticAddresses(ctr.config)); err != nil {
+		return err
+	}
+
+	// Remove container from network dependencies buckets
+	for _, net := range ctr.config.Networks {
+		netDB := netBkt.Bucket([]byte(net))
+		if netDB == nil {
+			// Network is not tracked in the DB, or was already
+			// removed
+			continue
+		}
+
+		ctrDepsBkt := netDB.Bucket(netDependenciesBkt)
+		if ctrDepsBkt == nil {
+			continue
+		}
+		if err := ctrDepsBkt.Delete(ctrID); err != nil {
+			return errors.Wrapf(err, "error deleting container %s dependency on network %s", ctr.ID(), net)
+		}
+	}
+
 	return nil
 }
+
+// deleteDependencyCondition removes the condition that the container with the
+// given ID waits for from the bucket of its dependency
+func deleteDependencyCondition(depCtrBkt *bolt.Bucket, ctrID []byte) error {
+	conditionsBkt := depCtrBkt.Bucket(depConditionsBkt)
+	if conditionsBkt == nil {
+		return nil
+	}
+	return conditionsBkt.Delete(ctrID)
+}
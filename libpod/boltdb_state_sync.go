@@ -0,0 +1,69 @@
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/define"
+	bolt "github.com/etcd-io/bbolt"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StateSyncFull syncs the state database to disk on every commit. It is
+	// the slowest mode, and the only one that is safe against power loss.
+	StateSyncFull = "full"
+	// StateSyncHybrid syncs the state database when containers and pods
+	// are added or removed, but not on state updates, such as containers
+	// starting and stopping. Like StateSyncNone, it is not safe against
+	// power loss or an operating system crash: an unsynced update can
+	// leave the database pointing at data that never reached the disk,
+	// corrupting it, and later synced commits do not repair it.
+	// It is also unsafe with concurrent podman processes, which can act on
+	// each other's unsynced updates.
+	StateSyncHybrid = "hybrid"
+	// StateSyncNone never syncs the state database to disk, leaving it to
+	// the kernel. It is the fastest mode, but a power loss can corrupt the
+	// database.
+	StateSyncNone = "none"
+)
+
+// boltOptions returns the options used to open the bolt database for the
+// given runtime configuration
+func boltOptions(config *RuntimeConfig) (*bolt.Options, error) {
+	options := *bolt.DefaultOptions
+
+	switch config.StateSyncMode {
+	case "", StateSyncFull:
+	case StateSyncHybrid, StateSyncNone:
+		options.NoSync = true
+	default:
+		return nil, errors.Wrapf(define.ErrInvalidArg, "invalid state sync mode %q, must be %q, %q or %q", config.StateSyncMode, StateSyncFull, StateSyncHybrid, StateSyncNone)
+	}
+
+	switch bolt.FreelistType(config.StateFreelistType) {
+	case "":
+	case bolt.FreelistArrayType, bolt.FreelistMapType:
+		options.FreelistType = bolt.FreelistType(config.StateFreelistType)
+	default:
+		return nil, errors.Wrapf(define.ErrInvalidArg, "invalid state freelist type %q, must be %q or %q", config.StateFreelistType, bolt.FreelistArrayType, bolt.FreelistMapType)
+	}
+
+	if config.StateInitialMmapSize < 0 {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "state initial mmap size cannot be negative")
+	}
+	options.InitialMmapSize = config.StateInitialMmapSize
+
+	return &options, nil
+}
+
+// getDBConSync opens a connection to the database whose commits are synced to
+// disk unless the state sync mode is "none". It is used for adding and
+// removing containers and pods, which must survive a crash in the hybrid mode.
+func (s *BoltState) getDBConSync() (*bolt.DB, error) {
+	db, err := s.getDBCon()
+	if err != nil {
+		return nil, err
+	}
+	if s.syncMode == StateSyncHybrid {
+		db.NoSync = false
+	}
+	return db, nil
+}
@@ -0,0 +1,29 @@
+package libpod
+
+import (
+	"testing"
+
+	bolt "github.com/etcd-io/bbolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltOptions(t *testing.T) {
+	options, err := boltOptions(&RuntimeConfig{})
+	require.NoError(t, err)
+	assert.False(t, options.NoSync)
+	assert.Equal(t, bolt.FreelistArrayType, options.FreelistType)
+
+	options, err = boltOptions(&RuntimeConfig{StateSyncMode: StateSyncHybrid, StateFreelistType: "hashmap", StateInitialMmapSize: 1 << 20})
+	require.NoError(t, err)
+	assert.True(t, options.NoSync)
+	assert.Equal(t, bolt.FreelistMapType, options.FreelistType)
+	assert.Equal(t, 1<<20, options.InitialMmapSize)
+
+	_, err = boltOptions(&RuntimeConfig{StateSyncMode: "sometimes"})
+	assert.Error(t, err)
+	_, err = boltOptions(&RuntimeConfig{StateFreelistType: "list"})
+	assert.Error(t, err)
+	_, err = boltOptions(&RuntimeConfig{StateInitialMmapSize: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,394 @@
+package libpod
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/cgroups"
+	"github.com/containers/libpod/pkg/rootless"
+	systemdDbus "github.com/coreos/go-systemd/dbus"
+	"github.com/godbus/dbus"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// cgroupManager creates and removes the cgroups of containers, pods
+// and conmon processes, as set by the cgroup_manager option. Cgroup paths are
+// relative to the cgroup root, except for the cgroups of pods and the parents
+// of containers with systemd, which are slices of the systemd instance
+// managing them.
+type cgroupManager interface {
+	// name returns the name of the manager in the configuration
+	name() string
+	// defaultParent returns the cgroup parent of containers and pods
+	// created without one
+	defaultParent() string
+	// validateParent checks that the cgroup parent can be used by the
+	// manager
+	validateParent(parent string) error
+	// containerCgroup returns the cgroup of the container
+	containerCgroup(ctr *Container) string
+	// specCgroupsPath returns the cgroups path of the container in its OCI
+	// spec, in the format the OCI runtime expects for the manager
+	specCgroupsPath(ctr *Container) string
+	// createPodCgroup creates the cgroup of the pod, if the manager does
+	// not leave it to the first container of the pod, and returns it
+	createPodCgroup(pod *Pod) (string, error)
+	// podCgroup returns the cgroup of the pod created by createPodCgroup
+	podCgroup(pod *Pod) string
+	// setPodResources sets the resource limits of the cgroup of the pod,
+	// creating it if the manager does not
+	setPodResources(pod *Pod, resources *spec.LinuxResources) error
+	// limitPodConmon keeps the conmon processes of the pod from spawning
+	// processes, which would keep the cgroup of the pod from being removed
+	limitPodConmon(pod *Pod) error
+	// removePodCgroup removes the cgroup of the pod, and the cgroup of its
+	// conmon processes
+	removePodCgroup(pod *Pod) error
+	// moveConmon moves the conmon process of the container to its cgroup
+	moveConmon(ctr *Container, pid int) error
+}
+
+// newCgroupManager returns the cgroup manager of the given name
+func newCgroupManager(name string) (cgroupManager, error) {
+	switch name {
+	case CgroupfsCgroupsManager:
+		return &cgroupfsManager{}, nil
+	case SystemdCgroupsManager:
+		return &systemdManager{
+			rootless: rootless.IsRootless(),
+			uid:      rootless.GetRootlessUID(),
+		}, nil
+	default:
+		return nil, errors.Wrapf(define.ErrInvalidArg, "unsupported CGroup manager: %s", name)
+	}
+}
+
+// getCgroupManager returns the cgroup manager of the runtime. Runtimes not
+// set up by NewRuntime, such as those of tests, manage cgroups with cgroupfs.
+func (r *Runtime) getCgroupManager() cgroupManager {
+	if r.cgroups == nil {
+		return &cgroupfsManager{}
+	}
+	return r.cgroups
+}
+
+// noRootlessCgroups returns whether cgroups cannot be used by the process.
+// Rootless users can only use the cgroups v2 delegated to them.
+func noRootlessCgroups() (bool, error) {
+	if !rootless.IsRootless() {
+		return false, nil
+	}
+	unified, err := cgroups.IsCgroup2UnifiedMode()
+	if err != nil {
+		return false, err
+	}
+	return !unified, nil
+}
+
+// cgroupfsManager manages cgroups by writing to the cgroup filesystem
+type cgroupfsManager struct{}
+
+func (m *cgroupfsManager) name() string {
+	return CgroupfsCgroupsManager
+}
+
+func (m *cgroupfsManager) defaultParent() string {
+	return CgroupfsDefaultCgroupParent
+}
+
+func (m *cgroupfsManager) validateParent(parent string) error {
+	if strings.HasSuffix(path.Base(parent), ".slice") {
+		return errors.Wrapf(define.ErrInvalidArg, "systemd slice received as cgroup parent when using cgroupfs")
+	}
+	return nil
+}
+
+func (m *cgroupfsManager) containerCgroup(ctr *Container) string {
+	return filepath.Join(ctr.config.CgroupParent, fmt.Sprintf("libpod-%s", ctr.ID()))
+}
+
+func (m *cgroupfsManager) specCgroupsPath(ctr *Container) string {
+	return m.containerCgroup(ctr)
+}
+
+// No need to create the pod cgroup with cgroupfs - the first container to
+// launch should do it for us
+func (m *cgroupfsManager) createPodCgroup(pod *Pod) (string, error) {
+	return filepath.Join(pod.config.CgroupParent, pod.ID()), nil
+}
+
+func (m *cgroupfsManager) podCgroup(pod *Pod) string {
+	return pod.state.CgroupPath
+}
+
+// The cgroup may be gone after a reboot, so it is created if needed
+func (m *cgroupfsManager) setPodResources(pod *Pod, resources *spec.LinuxResources) error {
+	control, err := cgroups.New(pod.state.CgroupPath, resources)
+	if err != nil {
+		return err
+	}
+	return control.Update(resources)
+}
+
+// podConmonCgroup returns the cgroup of the conmon processes of the pod
+func (m *cgroupfsManager) podConmonCgroup(pod *Pod) string {
+	return filepath.Join(pod.state.CgroupPath, "conmon")
+}
+
+func (m *cgroupfsManager) limitPodConmon(pod *Pod) error {
+	conmonCgroup, err := cgroups.Load(m.podConmonCgroup(pod))
+	if err == cgroups.ErrCgroupDeleted || err == cgroups.ErrCgroupV1Rootless {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving pod %s conmon cgroup", pod.ID())
+	}
+	// Inhibit forks with very low pids limit
+	resLimits := &spec.LinuxResources{
+		Pids: &spec.LinuxPids{Limit: 1},
+	}
+	if err := conmonCgroup.Update(resLimits); err != nil {
+		return errors.Wrapf(err, "error updating pod %s conmon cgroup", pod.ID())
+	}
+	return nil
+}
+
+// The conmon cgroup is deleted first, as it is nested in the pod cgroup
+func (m *cgroupfsManager) removePodCgroup(pod *Pod) error {
+	var lastError error
+	for _, cgroup := range []string{m.podConmonCgroup(pod), pod.state.CgroupPath} {
+		control, err := cgroups.Load(cgroup)
+		if err == cgroups.ErrCgroupDeleted || err == cgroups.ErrCgroupV1Rootless {
+			continue
+		}
+		if err == nil {
+			err = control.Delete()
+		}
+		if err != nil {
+			if lastError != nil {
+				logrus.Errorf("%v", lastError)
+			}
+			lastError = errors.Wrapf(err, "error removing pod %s cgroup %s", pod.ID(), cgroup)
+		}
+	}
+	return lastError
+}
+
+// The conmon processes of the containers sharing a parent share a cgroup
+func (m *cgroupfsManager) moveConmon(ctr *Container, pid int) error {
+	if noCgroups, err := noRootlessCgroups(); err != nil || noCgroups {
+		return err
+	}
+	control, err := cgroups.New(filepath.Join(ctr.config.CgroupParent, "conmon"), &spec.LinuxResources{})
+	if err != nil {
+		return err
+	}
+	// we need to remove this defer and delete the cgroup once conmon exits
+	// maybe need a conmon monitor?
+	return control.AddPid(pid)
+}
+
+// systemdManager manages cgroups as transient units of systemd. The units of
+// rootless users are created by their systemd user instance, whose cgroup
+// delegates the cgroups v2 controllers enforcing their resource limits.
+type systemdManager struct {
+	rootless bool
+	uid      int
+}
+
+func (m *systemdManager) name() string {
+	return SystemdCgroupsManager
+}
+
+func (m *systemdManager) defaultParent() string {
+	if m.rootless {
+		return SystemdDefaultRootlessCgroupParent
+	}
+	return SystemdDefaultCgroupParent
+}
+
+func (m *systemdManager) validateParent(parent string) error {
+	if len(parent) < 6 || !strings.HasSuffix(path.Base(parent), ".slice") {
+		return errors.Wrapf(define.ErrInvalidArg, "did not receive systemd slice as cgroup parent when using systemd to manage cgroups")
+	}
+	return nil
+}
+
+// instanceCgroup returns the cgroup of a unit path of the systemd instance
+// managing the units of the process
+func (m *systemdManager) instanceCgroup(unitPath string) string {
+	if m.rootless {
+		return filepath.Join(fmt.Sprintf("user.slice/user-%d.slice/user@%d.service", m.uid, m.uid), unitPath)
+	}
+	return unitPath
+}
+
+func (m *systemdManager) containerCgroup(ctr *Container) string {
+	return m.instanceCgroup(filepath.Join(ctr.config.CgroupParent, createUnitName("libpod", ctr.ID())))
+}
+
+// When runc is set to use Systemd as a cgroup manager, it expects cgroups to
+// be passed as follows: slice:prefix:name
+func (m *systemdManager) specCgroupsPath(ctr *Container) string {
+	return fmt.Sprintf("%s:libpod:%s", path.Base(ctr.config.CgroupParent), ctr.ID())
+}
+
+// connection returns a connection to the systemd instance managing the units
+// of the process
+func (m *systemdManager) connection() (*systemdDbus.Conn, error) {
+	if m.rootless {
+		return cgroups.GetUserConnection(m.uid)
+	}
+	return systemdDbus.New()
+}
+
+// The resource limits of the pod are set as properties of its slice, as
+// systemd only creates the cgroup of a slice once a unit runs in it
+func (m *systemdManager) createPodCgroup(pod *Pod) (string, error) {
+	unitPath, err := assembleSystemdCgroupName(pod.config.CgroupParent, fmt.Sprintf("libpod_pod_%s", pod.ID()))
+	if err != nil {
+		return "", err
+	}
+	var properties []systemdDbus.Property
+	if pod.config.ResourceLimits != nil {
+		if properties, err = m.resourceProperties(pod.config.ResourceLimits.linuxResources()); err != nil {
+			return "", err
+		}
+	}
+	controller, err := cgroups.NewSystemd(m.defaultParent())
+	if err != nil {
+		return "", err
+	}
+	if m.rootless {
+		err = controller.CreateSystemdUserUnit(unitPath, m.uid, properties...)
+	} else {
+		err = controller.CreateSystemdUnit(unitPath, properties...)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "error creating cgroup %s", unitPath)
+	}
+	logrus.Debugf("Created cgroup %s", unitPath)
+	return unitPath, nil
+}
+
+// The cgroup of the pod is recorded as a slice of the systemd instance
+func (m *systemdManager) podCgroup(pod *Pod) string {
+	return m.instanceCgroup(pod.state.CgroupPath)
+}
+
+// The limits are set as properties of the slice of the pod, which systemd
+// applies to its cgroup whenever it exists
+func (m *systemdManager) setPodResources(pod *Pod, resources *spec.LinuxResources) error {
+	properties, err := m.resourceProperties(resources)
+	if err != nil {
+		return err
+	}
+	conn, err := m.connection()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.SetUnitProperties(path.Base(pod.state.CgroupPath), true, properties...)
+}
+
+// resourceProperties returns the unit properties setting the resource limits
+func (m *systemdManager) resourceProperties(resources *spec.LinuxResources) ([]systemdDbus.Property, error) {
+	cgroup2, err := cgroups.IsCgroup2UnifiedMode()
+	if err != nil {
+		return nil, err
+	}
+	return systemdResourceProperties(resources, cgroup2), nil
+}
+
+// systemdResourceProperties converts resource limits to unit properties.
+// Systemd expresses CPU quotas per second rather than per period, and names
+// the memory limit differently on cgroups v1 and v2.
+func systemdResourceProperties(resources *spec.LinuxResources, cgroup2 bool) []systemdDbus.Property {
+	var properties []systemdDbus.Property
+	if resources.CPU != nil && resources.CPU.Quota != nil && *resources.CPU.Quota > 0 {
+		period := defaultCPUPeriod
+		if resources.CPU.Period != nil && *resources.CPU.Period > 0 {
+			period = *resources.CPU.Period
+		}
+		properties = append(properties, systemdDbus.Property{
+			Name:  "CPUQuotaPerSecUSec",
+			Value: dbus.MakeVariant(uint64(*resources.CPU.Quota) * 1000000 / period),
+		})
+	}
+	if resources.Memory != nil && resources.Memory.Limit != nil && *resources.Memory.Limit > 0 {
+		name := "MemoryLimit"
+		if cgroup2 {
+			name = "MemoryMax"
+		}
+		properties = append(properties, systemdDbus.Property{
+			Name:  name,
+			Value: dbus.MakeVariant(uint64(*resources.Memory.Limit)),
+		})
+	}
+	if resources.Pids != nil && resources.Pids.Limit > 0 {
+		properties = append(properties,
+			systemdDbus.Property{Name: "TasksAccounting", Value: dbus.MakeVariant(true)},
+			systemdDbus.Property{Name: "TasksMax", Value: dbus.MakeVariant(uint64(resources.Pids.Limit))},
+		)
+	}
+	return properties
+}
+
+// The conmon processes of the pod run in scopes stopped with them
+func (m *systemdManager) limitPodConmon(pod *Pod) error {
+	return nil
+}
+
+func (m *systemdManager) removePodCgroup(pod *Pod) error {
+	controller, err := cgroups.NewSystemd(m.defaultParent())
+	if err != nil {
+		return err
+	}
+	conn, err := m.connection()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := controller.DeleteByPathConn(pod.state.CgroupPath, conn); err != nil {
+		return errors.Wrapf(err, "error removing pod %s cgroup %s", pod.ID(), pod.state.CgroupPath)
+	}
+	return nil
+}
+
+// Conmon runs in a scope of its own, under the slice of the container
+func (m *systemdManager) moveConmon(ctr *Container, pid int) error {
+	if noCgroups, err := noRootlessCgroups(); err != nil || noCgroups {
+		return err
+	}
+	slice := path.Base(ctr.config.CgroupParent)
+	unitName := createUnitName("libpod-conmon", ctr.ID())
+	logrus.Infof("Running conmon under slice %s and unitName %s", slice, unitName)
+
+	conn, err := m.connection()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return cgroups.CreateSystemdScope(conn, pid, slice, unitName)
+}
+
+// assembleSystemdCgroupName creates a systemd cgroup path given a base and
+// a new component to add.
+// The base MUST be systemd slice (end in .slice)
+func assembleSystemdCgroupName(baseSlice, newSlice string) (string, error) {
+	const sliceSuffix = ".slice"
+
+	if !strings.HasSuffix(baseSlice, sliceSuffix) {
+		return "", errors.Wrapf(define.ErrInvalidArg, "cannot assemble cgroup path with base %q - must end in .slice", baseSlice)
+	}
+
+	noSlice := strings.TrimSuffix(baseSlice, sliceSuffix)
+	final := fmt.Sprintf("%s/%s-%s%s", baseSlice, noSlice, newSlice, sliceSuffix)
+
+	return final, nil
+}
@@ -0,0 +1,74 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCgroupManagerPaths(t *testing.T) {
+	ctr := &Container{config: &ContainerConfig{ID: "abc", CgroupParent: "machine.slice"}}
+
+	cgroupfs := &cgroupfsManager{}
+	assert.Error(t, cgroupfs.validateParent("machine.slice"))
+	assert.NoError(t, cgroupfs.validateParent("/libpod_parent"))
+	ctr.config.CgroupParent = "/libpod_parent"
+	assert.Equal(t, "/libpod_parent/libpod-abc", cgroupfs.containerCgroup(ctr))
+	assert.Equal(t, "/libpod_parent/libpod-abc", cgroupfs.specCgroupsPath(ctr))
+
+	systemd := &systemdManager{}
+	assert.Error(t, systemd.validateParent("/libpod_parent"))
+	assert.NoError(t, systemd.validateParent("machine.slice"))
+	ctr.config.CgroupParent = "machine.slice"
+	assert.Equal(t, "machine.slice", systemd.defaultParent())
+	assert.Equal(t, "machine.slice/libpod-abc.scope", systemd.containerCgroup(ctr))
+	assert.Equal(t, "machine.slice:libpod:abc", systemd.specCgroupsPath(ctr))
+
+	// Units of rootless users live in the cgroup of their systemd instance
+	rootless := &systemdManager{rootless: true, uid: 1000}
+	ctr.config.CgroupParent = "user.slice"
+	assert.Equal(t, "user.slice", rootless.defaultParent())
+	assert.Equal(t, "user.slice/user-1000.slice/user@1000.service/user.slice/libpod-abc.scope", rootless.containerCgroup(ctr))
+	assert.Equal(t, "user.slice:libpod:abc", rootless.specCgroupsPath(ctr))
+	pod := &Pod{state: &podState{CgroupPath: "user.slice/user-libpod_pod_def.slice"}}
+	assert.Equal(t, "user.slice/user-1000.slice/user@1000.service/user.slice/user-libpod_pod_def.slice", rootless.podCgroup(pod))
+}
+
+func TestCgroupManagerDefaultsToCgroupfs(t *testing.T) {
+	ctr := &Container{
+		config:  &ContainerConfig{ID: "abc", CgroupParent: "/libpod_parent"},
+		runtime: &Runtime{},
+	}
+
+	cgroupPath, err := ctr.CGroupPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/libpod_parent/libpod-abc", cgroupPath)
+}
+
+func TestSystemdResourceProperties(t *testing.T) {
+	limits := &PodResourceLimits{CPUQuota: 50000, MemoryLimit: 1 << 30, PidsLimit: 100}
+	properties := make(map[string]interface{})
+	for _, p := range systemdResourceProperties(limits.linuxResources(), false) {
+		properties[p.Name] = p.Value.Value()
+	}
+	assert.Equal(t, map[string]interface{}{
+		"CPUQuotaPerSecUSec": uint64(500000),
+		"MemoryLimit":        uint64(1 << 30),
+		"TasksAccounting":    true,
+		"TasksMax":           uint64(100),
+	}, properties)
+
+	// Quotas are converted from their own period, and cgroups v2 name the
+	// memory limit differently
+	limits = &PodResourceLimits{CPUQuota: 20000, CPUPeriod: 10000, MemoryLimit: 1 << 20}
+	properties = make(map[string]interface{})
+	for _, p := range systemdResourceProperties(limits.linuxResources(), true) {
+		properties[p.Name] = p.Value.Value()
+	}
+	assert.Equal(t, map[string]interface{}{
+		"CPUQuotaPerSecUSec": uint64(2000000),
+		"MemoryMax":          uint64(1 << 20),
+	}, properties)
+
+	assert.Empty(t, systemdResourceProperties((&PodResourceLimits{}).linuxResources(), false))
+}
@@ -0,0 +1,202 @@
+package libpod
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/containers/image/docker"
+	"github.com/containers/image/manifest"
+	"github.com/containers/image/pkg/blobinfocache/none"
+	"github.com/containers/image/types"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// LocalCheckpointStorage is the scheme of the checkpoint storage
+	// backend storing checkpoint archives in local files. Locations
+	// without a scheme also use it.
+	LocalCheckpointStorage = "file"
+	// RegistryCheckpointStorage is the scheme of the checkpoint storage
+	// backend storing checkpoint archives in OCI registries, e.g.
+	// docker://quay.io/user/checkpoint:tag
+	RegistryCheckpointStorage = "docker"
+
+	// checkpointAnnotation is the manifest annotation marking images in a
+	// registry as checkpoint archives
+	checkpointAnnotation = "io.podman.checkpoint"
+)
+
+// CheckpointStorage stores exported checkpoint archives, and retrieves them
+// again when the checkpoint is imported.
+// Locations are given without the scheme used to select the storage.
+type CheckpointStorage interface {
+	// Export stores the checkpoint archive read from the given reader at
+	// the given location.
+	Export(ctx context.Context, location string, archive io.Reader) error
+	// Import returns the checkpoint archive stored at the given location.
+	Import(ctx context.Context, location string) (io.ReadCloser, error)
+}
+
+// checkpointStorage returns the checkpoint storage backend for the given
+// location, and the location with its scheme removed.
+func (r *Runtime) checkpointStorage(location string) (CheckpointStorage, string, error) {
+	scheme := LocalCheckpointStorage
+	if splitLocation := strings.SplitN(location, "://", 2); len(splitLocation) == 2 {
+		scheme = splitLocation[0]
+		location = splitLocation[1]
+	}
+	if location == "" {
+		return nil, "", errors.Wrapf(define.ErrInvalidArg, "must provide a checkpoint archive location")
+	}
+
+	switch scheme {
+	case LocalCheckpointStorage:
+		return localCheckpointStorage{}, location, nil
+	case RegistryCheckpointStorage:
+		return &registryCheckpointStorage{systemContext: r.imageContext}, location, nil
+	}
+	storage, ok := r.checkpointStorages[scheme]
+	if !ok {
+		return nil, "", errors.Wrapf(define.ErrInvalidArg, "no checkpoint storage for scheme %q", scheme)
+	}
+	return storage, location, nil
+}
+
+// OpenCheckpointArchive returns the checkpoint archive stored at the given
+// location. The location is a path to a local file, or is prefixed with the
+// scheme of the checkpoint storage backend to use, e.g. docker://.
+func (r *Runtime) OpenCheckpointArchive(ctx context.Context, location string) (io.ReadCloser, error) {
+	storage, location, err := r.checkpointStorage(location)
+	if err != nil {
+		return nil, err
+	}
+	return storage.Import(ctx, location)
+}
+
+// localCheckpointStorage stores checkpoint archives in local files
+type localCheckpointStorage struct{}
+
+// Export writes the archive to the file at location
+func (localCheckpointStorage) Export(ctx context.Context, location string, archive io.Reader) error {
+	outFile, err := os.Create(location)
+	if err != nil {
+		return errors.Wrapf(err, "error creating checkpoint export file %q", location)
+	}
+	defer outFile.Close()
+
+	if err := os.Chmod(location, 0600); err != nil {
+		return errors.Wrapf(err, "cannot chmod %q", location)
+	}
+
+	if _, err := io.Copy(outFile, archive); err != nil {
+		return errors.Wrapf(err, "error writing checkpoint export file %q", location)
+	}
+	return nil
+}
+
+// Import opens the file at location
+func (localCheckpointStorage) Import(ctx context.Context, location string) (io.ReadCloser, error) {
+	archiveFile, err := os.Open(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to open checkpoint archive %s for import", location)
+	}
+	return archiveFile, nil
+}
+
+// registryCheckpointStorage stores checkpoint archives in an OCI registry, as
+// the single layer of an image annotated as a checkpoint
+type registryCheckpointStorage struct {
+	systemContext *types.SystemContext
+}
+
+// Export pushes the archive to the image reference at location
+func (s *registryCheckpointStorage) Export(ctx context.Context, location string, archive io.Reader) error {
+	ref, err := docker.ParseReference("//" + location)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing checkpoint image reference %q", location)
+	}
+	dest, err := ref.NewImageDestination(ctx, s.systemContext)
+	if err != nil {
+		return errors.Wrapf(err, "error accessing checkpoint image %q", location)
+	}
+	defer dest.Close()
+
+	layer, err := dest.PutBlob(ctx, archive, types.BlobInfo{Size: -1}, none.NoCache, false)
+	if err != nil {
+		return errors.Wrapf(err, "error uploading checkpoint archive to %q", location)
+	}
+	configBlob := []byte("{}")
+	config, err := dest.PutBlob(ctx, bytes.NewReader(configBlob), types.BlobInfo{Digest: digest.FromBytes(configBlob), Size: int64(len(configBlob))}, none.NoCache, true)
+	if err != nil {
+		return errors.Wrapf(err, "error uploading checkpoint config to %q", location)
+	}
+
+	m := manifest.OCI1FromComponents(
+		imgspecv1.Descriptor{MediaType: imgspecv1.MediaTypeImageConfig, Digest: config.Digest, Size: config.Size},
+		[]imgspecv1.Descriptor{{MediaType: imgspecv1.MediaTypeImageLayerGzip, Digest: layer.Digest, Size: layer.Size}},
+	)
+	m.Annotations = map[string]string{checkpointAnnotation: "true"}
+	manifestBlob, err := m.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := dest.PutManifest(ctx, manifestBlob); err != nil {
+		return errors.Wrapf(err, "error uploading checkpoint manifest to %q", location)
+	}
+	return dest.Commit(ctx)
+}
+
+// Import pulls the archive from the image reference at location
+func (s *registryCheckpointStorage) Import(ctx context.Context, location string) (io.ReadCloser, error) {
+	ref, err := docker.ParseReference("//" + location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing checkpoint image reference %q", location)
+	}
+	src, err := ref.NewImageSource(ctx, s.systemContext)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error accessing checkpoint image %q", location)
+	}
+
+	manifestBlob, _, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		src.Close()
+		return nil, errors.Wrapf(err, "error reading checkpoint manifest from %q", location)
+	}
+	m, err := manifest.OCI1FromManifest(manifestBlob)
+	if err != nil {
+		src.Close()
+		return nil, errors.Wrapf(err, "error parsing checkpoint manifest from %q", location)
+	}
+	if m.Annotations[checkpointAnnotation] != "true" || len(m.Layers) != 1 {
+		src.Close()
+		return nil, errors.Wrapf(define.ErrInvalidArg, "image %q is not a checkpoint archive", location)
+	}
+
+	layer, _, err := src.GetBlob(ctx, manifest.BlobInfoFromOCI1Descriptor(m.Layers[0]), none.NoCache)
+	if err != nil {
+		src.Close()
+		return nil, errors.Wrapf(err, "error downloading checkpoint archive from %q", location)
+	}
+	return &registryCheckpointArchive{ReadCloser: layer, src: src}, nil
+}
+
+// registryCheckpointArchive closes the image source along with the archive
+type registryCheckpointArchive struct {
+	io.ReadCloser
+	src types.ImageSource
+}
+
+// Close closes the archive and the image source it was read from
+func (a *registryCheckpointArchive) Close() error {
+	err := a.ReadCloser.Close()
+	if srcErr := a.src.Close(); err == nil {
+		err = srcErr
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package libpod
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointStorageScheme(t *testing.T) {
+	runtime := &Runtime{}
+	custom := localCheckpointStorage{}
+	require.NoError(t, WithCheckpointStorage("s3", custom)(runtime))
+	assert.Error(t, WithCheckpointStorage(RegistryCheckpointStorage, custom)(runtime))
+
+	for _, tt := range []struct {
+		location string
+		storage  CheckpointStorage
+		stripped string
+	}{
+		{"/tmp/checkpoint.tar.gz", localCheckpointStorage{}, "/tmp/checkpoint.tar.gz"},
+		{"file:///tmp/checkpoint.tar.gz", localCheckpointStorage{}, "/tmp/checkpoint.tar.gz"},
+		{"docker://quay.io/user/checkpoint:latest", &registryCheckpointStorage{}, "quay.io/user/checkpoint:latest"},
+		{"s3://bucket/checkpoint.tar.gz", custom, "bucket/checkpoint.tar.gz"},
+	} {
+		storage, location, err := runtime.checkpointStorage(tt.location)
+		require.NoError(t, err, tt.location)
+		assert.IsType(t, tt.storage, storage, tt.location)
+		assert.Equal(t, tt.stripped, location, tt.location)
+	}
+
+	_, _, err := runtime.checkpointStorage("ftp://host/checkpoint.tar.gz")
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
+
+func TestLocalCheckpointStorage(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "checkpoint")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	runtime := &Runtime{}
+	location := filepath.Join(tmpDir, "checkpoint.tar.gz")
+	storage, location, err := runtime.checkpointStorage(location)
+	require.NoError(t, err)
+	require.NoError(t, storage.Export(context.Background(), location, strings.NewReader("checkpoint")))
+
+	info, err := os.Stat(location)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	archive, err := runtime.OpenCheckpointArchive(context.Background(), location)
+	require.NoError(t, err)
+	defer archive.Close()
+	content, err := ioutil.ReadAll(archive)
+	require.NoError(t, err)
+	assert.Equal(t, "checkpoint", string(content))
+}
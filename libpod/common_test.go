@@ -3,6 +3,8 @@ package libpod
 import (
 	"net"
 	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -15,6 +17,19 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// skipIfJSONDeepCopyBroken skips tests copying container configurations with
+// JSONDeepCopy, as the vendored reflect2 crashes iterating maps when built with
+// Go 1.18 and later
+func skipIfJSONDeepCopyBroken(t *testing.T) {
+	version := strings.Split(strings.TrimPrefix(runtime.Version(), "go"), ".")
+	if len(version) >= 2 {
+		if minor, err := strconv.Atoi(version[1]); err == nil && version[0] == "1" && minor < 18 {
+			return
+		}
+	}
+	t.Skipf("JSONDeepCopy is not supported with %s", runtime.Version())
+}
+
 func getTestContainer(id, name string, manager lock.Manager) (*Container, error) {
 	ctr := &Container{
 		config: &ContainerConfig{
@@ -5,7 +5,6 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/containernetworking/cni/pkg/types"
@@ -14,7 +13,6 @@ import (
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/lock"
 	"github.com/containers/libpod/pkg/namespaces"
-	"github.com/containers/libpod/pkg/rootless"
 	"github.com/containers/storage"
 	"github.com/cri-o/ocicni/pkg/ocicni"
 	spec "github.com/opencontainers/runtime-spec/specs-go"
@@ -38,9 +36,16 @@ const JournaldLogging = "journald"
 // KubernetesLogging is the string conmon expects when specifying to use the kubernetes logging format
 const KubernetesLogging = "k8s-file"
 
-// JSONLogging is the string conmon expects when specifying to use the json logging format
+// JSONLogging is the log driver writing logs in the format of Docker's json-file
+// log driver, with size-based rotation
 const JSONLogging = "json-file"
 
+// SyslogLogging is the log driver sending logs to a syslog endpoint
+const SyslogLogging = "syslog"
+
+// FluentdLogging is the log driver sending logs to a fluentd endpoint
+const FluentdLogging = "fluentd"
+
 // DefaultWaitInterval is the default interval between container status checks
 // while waiting.
 const DefaultWaitInterval = 250 * time.Millisecond
@@ -104,6 +109,23 @@ const (
 	// RestartPolicyOnFailure restarts the container on non-0 exit code,
 	// with an optional maximum number of retries.
 	RestartPolicyOnFailure = "on-failure"
+	// RestartPolicyUnlessStopped restarts the container when it exits,
+	// unless it was stopped by the user. As Libpod does not restart
+	// containers after a reboot, this presently behaves like
+	// RestartPolicyAlways.
+	RestartPolicyUnlessStopped = "unless-stopped"
+)
+
+// Delays applied between restarts of a container by its restart policy.
+const (
+	// restartBackoffInitial is the delay before the first restart.
+	restartBackoffInitial = 100 * time.Millisecond
+	// restartBackoffMax is the longest delay between restarts. The delay
+	// doubles with each restart until it reaches this.
+	restartBackoffMax = time.Minute
+	// restartBackoffReset is how long a container must run before exiting
+	// for the delay to be reset to restartBackoffInitial.
+	restartBackoffReset = 10 * time.Second
 )
 
 // Container is a single OCI container.
@@ -129,7 +151,7 @@ type Container struct {
 	valid      bool
 	lock       lock.Locker
 	runtime    *Runtime
-	ociRuntime *OCIRuntime
+	ociRuntime OCIRuntime
 
 	rootlessSlirpSyncR *os.File
 	rootlessSlirpSyncW *os.File
@@ -143,6 +165,32 @@ type Container struct {
 	restoreFromCheckpoint bool
 }
 
+// ContainerSummary is a small subset of a container's configuration and state,
+// sufficient to list containers.
+// It is stored in the database separately from the container's full
+// configuration and state, and updated every time the container's state is
+// saved, so that it can be retrieved without locking the container.
+// As a result, it reflects the container's state as of the last time it was
+// saved, and may be stale if the container has exited but has not yet been
+// synced.
+type ContainerSummary struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Pod          string                 `json:"pod,omitempty"`
+	Namespace    string                 `json:"namespace,omitempty"`
+	ImageID      string                 `json:"imageID,omitempty"`
+	ImageName    string                 `json:"imageName,omitempty"`
+	State        define.ContainerStatus `json:"state"`
+	IsInfra      bool                   `json:"isInfra,omitempty"`
+	PortMappings []ocicni.PortMapping   `json:"portMappings,omitempty"`
+	CreatedTime  time.Time              `json:"createdTime"`
+}
+
+// ContainerStateHook is a function called after a container moves from one
+// state to another. Hooks are called with the container locked, and must not
+// attempt to lock it again.
+type ContainerStateHook func(ctr *Container, from, to define.ContainerStatus)
+
 // ContainerState contains the current state of the container
 // It is stored on disk in a tmpfs and recreated on reboot
 type ContainerState struct {
@@ -181,6 +229,21 @@ type ContainerState struct {
 	// namespace for the container, and the network namespace is currently
 	// active
 	NetworkStatus []*cnitypes.Result `json:"networkResults,omitempty"`
+	// NetworkInterfaces maps the names of the CNI networks the container
+	// is attached to to the name of the network's interface in the
+	// container's network namespace.
+	// Only populated if the network namespace is currently active.
+	NetworkInterfaces map[string]string `json:"networkInterfaces,omitempty"`
+	// RootlessNetworkPIDs are the PIDs of the processes of the rootless
+	// network stack (slirp4netns or pasta) connecting the container to the
+	// host. They are stopped when the container is cleaned up.
+	// Only populated if the container uses a rootless network stack and is
+	// running.
+	RootlessNetworkPIDs []int `json:"rootlessNetworkPids,omitempty"`
+	// RootlessNetworkIPv6 indicates that the rootless network stack of the
+	// container has IPv6 enabled, and forwards ports without a host IP
+	// from both the IPv4 and IPv6 addresses of the host.
+	RootlessNetworkIPv6 bool `json:"rootlessNetworkIPv6,omitempty"`
 	// BindMounts contains files that will be bind-mounted into the
 	// container when it is mounted.
 	// These include /etc/hosts and /etc/resolv.conf
@@ -197,6 +260,76 @@ type ContainerState struct {
 	// restart policy. This is NOT incremented by normal container restarts
 	// (only by restart policy).
 	RestartCount uint `json:"restartCount,omitempty"`
+	// RestartBackoff is the delay that was applied before the last restart
+	// of the container by its restart policy. The next restart will wait
+	// twice as long, unless the container ran long enough to reset it.
+	RestartBackoff time.Duration `json:"restartBackoff,omitempty"`
+	// MemoryPressureFrozen indicates that the container's processes were
+	// stopped with SIGSTOP because of host memory pressure, and will be
+	// resumed once the pressure clears.
+	MemoryPressureFrozen bool `json:"memoryPressureFrozen,omitempty"`
+	// ScheduleNextRun is the next time the scheduler will start the
+	// container, according to its start schedule. It is the zero time if
+	// the container has no start schedule, or will not be started again.
+	ScheduleNextRun time.Time `json:"scheduleNextRun,omitempty"`
+	// ScheduleLastRun is the last time the scheduler started the container.
+	ScheduleLastRun time.Time `json:"scheduleLastRun,omitempty"`
+	// AutoUpdateLastCheck is the last time the container was checked for
+	// a newer image by AutoUpdate.
+	AutoUpdateLastCheck time.Time `json:"autoUpdateLastCheck,omitempty"`
+	// AutoUpdateLastUpdate is the last time the unit of the container was
+	// restarted by AutoUpdate to run a newer image.
+	AutoUpdateLastUpdate time.Time `json:"autoUpdateLastUpdate,omitempty"`
+	// StopStep is the step of the stop signal escalation chain that was
+	// last reached while stopping the container, starting at 1. It is 0
+	// if the container has not been stopped since it was last started.
+	StopStep int `json:"stopStep,omitempty"`
+	// StopStepSignal is the signal sent at StopStep.
+	StopStepSignal uint `json:"stopStepSignal,omitempty"`
+	// StopStepTime is when StopStep was reached.
+	StopStepTime time.Time `json:"stopStepTime,omitempty"`
+	// ExitReport summarizes the resources used during the container's
+	// last run. It is collected when the container exits.
+	ExitReport *ContainerExitReport `json:"exitReport,omitempty"`
+	// Unmonitored indicates that no conmon monitors the container's
+	// current processes, as they were started by another tool before the
+	// container was adopted. No exit file is written when they exit, so
+	// the container's status is only updated from the OCI runtime.
+	Unmonitored bool `json:"unmonitored,omitempty"`
+	// Checkpointed indicates that the container was stopped by a checkpoint
+	// and can be restored from its checkpoint images. It is cleared when
+	// the container is restored or started again from scratch.
+	Checkpointed bool `json:"checkpointed,omitempty"`
+	// CheckpointedTime is the time the container was last checkpointed
+	CheckpointedTime time.Time `json:"checkpointedTime,omitempty"`
+	// RestoredTime is the time the container was last restored from a
+	// checkpoint
+	RestoredTime time.Time `json:"restoredTime,omitempty"`
+	// HealthCheck holds the health status of the container and the results
+	// of its last healthchecks, if it has a healthcheck
+	HealthCheck *HealthCheckResults `json:"healthCheck,omitempty"`
+	// HealthCheckSchedulerPID is the PID of the process running the
+	// healthchecks of the container periodically, when they are not
+	// scheduled by systemd timers
+	HealthCheckSchedulerPID int `json:"healthCheckSchedulerPID,omitempty"`
+	// HealthCheckUnit is the name of the systemd timer and service running
+	// the healthchecks of the container, when they are scheduled by
+	// systemd. Each init of the container uses a new name, so the timer can
+	// be recreated by a healthcheck running in the previous service.
+	HealthCheckUnit string `json:"healthCheckUnit,omitempty"`
+	// HealthCheckActionCount is how many times the action configured for
+	// the container becoming unhealthy was taken since it was last
+	// healthy. Each action waits twice as long as the previous one.
+	HealthCheckActionCount uint `json:"healthCheckActionCount,omitempty"`
+	// HealthCheckActionTime is when the action configured for the
+	// container becoming unhealthy was last taken
+	HealthCheckActionTime time.Time `json:"healthCheckActionTime,omitempty"`
+
+	// DiskUsed is the disk space used by the writable layer of the
+	// container, in bytes, when last computed
+	DiskUsed uint64 `json:"diskUsed,omitempty"`
+	// DiskUsageTime is when DiskUsed was last computed
+	DiskUsageTime time.Time `json:"diskUsageTime,omitempty"`
 
 	// ExtensionStageHooks holds hooks which will be executed by libpod
 	// and not delegated to the OCI runtime.
@@ -211,6 +344,12 @@ type ExecSession struct {
 	ID      string   `json:"id"`
 	Command []string `json:"command"`
 	PID     int      `json:"pid"`
+	// ConmonPID is the PID of the conmon monitoring a detached session
+	ConmonPID int `json:"conmonPid,omitempty"`
+	// Detached is whether the session was started without attaching to
+	// it. Detached sessions outlive the process that started them, and
+	// are removed by reaping them once they have exited.
+	Detached bool `json:"detached,omitempty"`
 }
 
 // ContainerConfig contains all information that was used to create the
@@ -266,6 +405,17 @@ type ContainerConfig struct {
 	// User and group to use in the container
 	// Can be specified by name or UID/GID
 	User string `json:"user,omitempty"`
+	// KeepID is the invoking user the container keeps the IDs of when it
+	// is created with --userns=keep-id. It is added to the container's
+	// passwd file and owns its home and working directories.
+	KeepID *KeepIDUser `json:"keepID,omitempty"`
+	// AutoUserNS is set if the container is created with --userns=auto.
+	// Its IDMappings are then allocated from the subordinate IDs of the
+	// host when it is created, and released when it is removed.
+	AutoUserNS bool `json:"autoUserNS,omitempty"`
+	// AutoUserNSSize is the number of UIDs and GIDs allocated to a
+	// container with AutoUserNS set.
+	AutoUserNSSize int `json:"autoUserNSSize,omitempty"`
 	// Additional groups to add
 	Groups []string `json:"groups,omitempty"`
 
@@ -285,6 +435,10 @@ type ContainerConfig struct {
 	// IDs of dependency containers.
 	// These containers must be started before this container is started.
 	Dependencies []string
+	// DependencyConditions maps the IDs of dependency containers to the
+	// condition they must meet before this container is started.
+	// Dependencies not present here must be running.
+	DependencyConditions map[string]define.DependencyCondition `json:"dependencyConditions,omitempty"`
 
 	// Network Config
 
@@ -292,14 +446,29 @@ type ContainerConfig struct {
 	// network namespace for the container.
 	// This cannot be set if NetNsCtr is also set.
 	CreateNetNS bool `json:"createNetNS"`
+	// NetNsPath is the path to an existing network namespace the
+	// container joins, such as /proc/<pid>/ns/net or a namespace mounted
+	// by `ip netns`. The path must exist when the container starts. The
+	// namespace is not configured by libpod, and is never removed by it.
+	// This cannot be set if CreateNetNS or NetNsCtr is also set.
+	NetNsPath string `json:"netNsPath,omitempty"`
 	// StaticIP is a static IP to request for the container.
 	// This cannot be set unless CreateNetNS is set.
 	// If not set, the container will be dynamically assigned an IP by CNI.
 	StaticIP net.IP `json:"staticIP"`
+	// StaticMAC is a static MAC address to request for the container on
+	// the default network.
+	// This cannot be set unless CreateNetNS is set.
+	StaticMAC net.HardwareAddr `json:"staticMAC,omitempty"`
 	// PortMappings are the ports forwarded to the container's network
 	// namespace
 	// These are not used unless CreateNetNS is true
 	PortMappings []ocicni.PortMapping `json:"portMappings,omitempty"`
+	// Bandwidth limits the rate of the container's incoming and outgoing
+	// traffic on each of its CNI networks. It is applied by the CNI
+	// bandwidth plugin, which must be configured for the networks.
+	// This cannot be set unless CreateNetNS is set.
+	Bandwidth *ocicni.BandwidthConfig `json:"bandwidth,omitempty"`
 	// UseImageResolvConf indicates that resolv.conf should not be
 	// bind-mounted inside the container.
 	// Conflicts with DNSServer, DNSSearch, DNSOption.
@@ -313,6 +482,12 @@ type ContainerConfig struct {
 	// DNS options to be set in container resolv.conf
 	// With override options in host resolv if set
 	DNSOption []string `json:"dnsOption,omitempty"`
+	// DNSCache indicates that a caching DNS resolver should be run in the
+	// container's network namespace, forwarding to the DNS servers that
+	// would otherwise be used. The container's resolv.conf will point to
+	// the resolver instead.
+	// This is only set for pod infra containers.
+	DNSCache bool `json:"dnsCache,omitempty"`
 	// UseImageHosts indicates that /etc/hosts should not be
 	// bind-mounted inside the container.
 	// Conflicts with HostAdd.
@@ -322,6 +497,13 @@ type ContainerConfig struct {
 	HostAdd []string `json:"hostsAdd,omitempty"`
 	// Network names (CNI) to add container to. Empty to use default network.
 	Networks []string `json:"networks,omitempty"`
+	// NetworkAliases are additional names of the container on each of the
+	// CNI networks it is attached to, keyed by network name.
+	NetworkAliases map[string][]string `json:"networkAliases,omitempty"`
+	// NetworkStaticIPs are static IPs to request for the container on each
+	// of the CNI networks it is attached to, keyed by network name.
+	// Unlike StaticIP, these can be used with multiple networks.
+	NetworkStaticIPs map[string]net.IP `json:"networkStaticIPs,omitempty"`
 	// Network mode specified for the default network.
 	NetMode namespaces.NetworkMode `json:"networkMode,omitempty"`
 
@@ -354,6 +536,15 @@ type ContainerConfig struct {
 	StopSignal uint `json:"stopSignal,omitempty"`
 	// StopTimeout is the signal that will be used to stop the container
 	StopTimeout uint `json:"stopTimeout,omitempty"`
+	// StopEscalation is the ordered list of signals sent to stop the
+	// container, each followed by a wait for the container to exit. If
+	// set, it is used instead of StopSignal and StopTimeout. The container
+	// is killed with SIGKILL if it is still running after the last step.
+	StopEscalation []StopSignalStep `json:"stopEscalation,omitempty"`
+	// ReadOnlyTmpfs are the paths a tmpfs is mounted on when the
+	// container's root filesystem is read-only. Paths that are already
+	// mount or volume destinations are left alone.
+	ReadOnlyTmpfs []string `json:"readOnlyTmpfs,omitempty"`
 	// Time container was created
 	CreatedTime time.Time `json:"createdTime"`
 	// Cgroup parent of the container
@@ -362,8 +553,36 @@ type ContainerConfig struct {
 	LogPath string `json:"logPath"`
 	// LogDriver driver for logs
 	LogDriver string `json:"logDriver"`
+	// LogTag is the tag given to the log entries of the container in the
+	// journal, or by the syslog and fluentd log drivers
+	LogTag string `json:"logTag,omitempty"`
+	// LogAddress is the address of the endpoint the syslog and fluentd log
+	// drivers send the logs to. The default endpoint of the driver is used
+	// if empty.
+	LogAddress string `json:"logAddress,omitempty"`
+	// LogMaxSize is the size in bytes the log file may grow to before it
+	// is rotated, if the json-file log driver is used. Unlimited if not
+	// positive.
+	LogMaxSize int64 `json:"logMaxSize,omitempty"`
+	// LogMaxFiles is the number of log files kept when rotating the log,
+	// including the one being written. If at most one, the log file is
+	// truncated when rotated.
+	LogMaxFiles uint `json:"logMaxFiles,omitempty"`
 	// File containing the conmon PID
 	ConmonPidFile string `json:"conmonPidFile,omitempty"`
+	// ConmonLogLevel is the log level conmon will be run with.
+	// If empty, the log level of the libpod process creating the container
+	// is used.
+	ConmonLogLevel string `json:"conmonLogLevel,omitempty"`
+	// ConmonSyslog indicates that conmon should log to syslog regardless of
+	// its log level.
+	ConmonSyslog bool `json:"conmonSyslog,omitempty"`
+	// ConmonDebugLog indicates that conmon's own output should be captured
+	// to a file in the container's static directory.
+	ConmonDebugLog bool `json:"conmonDebugLog,omitempty"`
+	// HTTPProxy indicates that the proxy environment variables of the host
+	// were added to the container's environment when it was created.
+	HTTPProxy bool `json:"httpProxy,omitempty"`
 	// RestartPolicy indicates what action the container will take upon
 	// exiting naturally.
 	// Allowed options are "no" (take no action), "on-failure" (restart on
@@ -388,15 +607,39 @@ type ContainerConfig struct {
 	// ExitCommand is the container's exit command.
 	// This Command will be executed when the container exits
 	ExitCommand []string `json:"exitCommand,omitempty"`
+	// CreateCommand is the command line the container was created with,
+	// if created by the podman CLI. Used to recreate the container, e.g.
+	// by the systemd units generated for it.
+	CreateCommand []string `json:"createCommand,omitempty"`
 	// IsInfra is a bool indicating whether this container is an infra container used for
 	// sharing kernel namespaces in a pod
 	IsInfra bool `json:"pause"`
+	// InitContainerType is set if the container is an init container of
+	// its pod. Init containers are run to completion, in dependency order,
+	// when the pod is started, before the other containers of the pod.
+	InitContainerType define.InitContainerType `json:"initContainerType,omitempty"`
+	// SdNotifyMode determines how the systemd unit running podman is
+	// notified that the container is ready. Containers created without a
+	// mode pass the socket to the container.
+	SdNotifyMode define.SdNotifyMode `json:"sdNotifyMode,omitempty"`
+	// StartSchedule is when the scheduler starts the container: either a
+	// time in RFC 3339 format, or a cron expression.
+	StartSchedule string `json:"startSchedule,omitempty"`
+	// ExternallyManaged indicates the container's conmon and OCI runtime
+	// processes may be managed outside of this Podman, as with containers
+	// restored from imported checkpoints or adopted from the OCI runtime.
+	// Their status is reconciled periodically by the runtime's state sync.
+	ExternallyManaged bool `json:"externallyManaged,omitempty"`
 
 	// Systemd tells libpod to setup the container in systemd mode
 	Systemd bool `json:"systemd"`
 
 	// HealthCheckConfig has the health check command and related timings
 	HealthCheckConfig *manifest.Schema2HealthConfig `json:"healthcheck"`
+	// HealthCheckOnFailure is the action taken when the container becomes
+	// unhealthy: one of HealthCheckOnFailureNone, Restart, Stop or Kill.
+	// No action is taken if empty.
+	HealthCheckOnFailure string `json:"healthcheckOnFailure,omitempty"`
 }
 
 // ContainerNamedVolume is a named volume that will be mounted into the
@@ -509,6 +752,17 @@ func (c *Container) StaticDir() string {
 	return c.config.StaticDir
 }
 
+// LockHolder returns the process currently holding the container's lock, or
+// nil if it is not held or the holder cannot be determined. It does not take
+// the lock, so it can be used to diagnose operations blocked on the container.
+func (c *Container) LockHolder() (*lock.Holder, error) {
+	holder, err := c.lock.Holder()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving holder of lock for container %s", c.ID())
+	}
+	return holder, nil
+}
+
 // NamedVolumes returns the container's named volumes.
 // The name of each is guaranteed to point to a valid libpod Volume present in
 // the state.
@@ -545,6 +799,16 @@ func (c *Container) User() string {
 	return c.config.User
 }
 
+// KeepID returns the user whose IDs the container keeps, or nil if the
+// container was not created with --userns=keep-id
+func (c *Container) KeepID() *KeepIDUser {
+	if c.config.KeepID == nil {
+		return nil
+	}
+	keepID := *c.config.KeepID
+	return &keepID
+}
+
 // Dependencies gets the containers this container depends upon
 func (c *Container) Dependencies() []string {
 	// Collect in a map first to remove dupes
@@ -683,6 +947,17 @@ func (c *Container) StopSignal() uint {
 	return c.config.StopSignal
 }
 
+// StopEscalation returns the container's stop signal escalation chain, or nil
+// if the container is stopped using its stop signal and timeout
+func (c *Container) StopEscalation() []StopSignalStep {
+	if c.config.StopEscalation == nil {
+		return nil
+	}
+	steps := make([]StopSignalStep, len(c.config.StopEscalation))
+	copy(steps, c.config.StopEscalation)
+	return steps
+}
+
 // StopTimeout returns the container's stop timeout
 // If the container's default stop signal fails to kill the container, SIGKILL
 // will be used after this timeout
@@ -718,11 +993,37 @@ func (c *Container) RestartRetries() uint {
 	return c.config.RestartRetries
 }
 
+// CreateCommand returns the command line the container was created with,
+// empty if it was not created by the podman CLI
+func (c *Container) CreateCommand() []string {
+	return c.config.CreateCommand
+}
+
+// SdNotifyMode returns how the systemd unit running podman is notified that
+// the container is ready
+func (c *Container) SdNotifyMode() define.SdNotifyMode {
+	if c.config.SdNotifyMode == "" {
+		return define.SdNotifyModeContainer
+	}
+	return c.config.SdNotifyMode
+}
+
 // LogDriver returns the log driver for this container
 func (c *Container) LogDriver() string {
 	return c.config.LogDriver
 }
 
+// LogTag returns the tag of the container's log entries in the journal
+func (c *Container) LogTag() string {
+	return c.config.LogTag
+}
+
+// LogAddress returns the address of the endpoint the logs of the container are
+// sent to
+func (c *Container) LogAddress() string {
+	return c.config.LogAddress
+}
+
 // RuntimeName returns the name of the runtime
 func (c *Container) RuntimeName() string {
 	return c.config.OCIRuntime
@@ -920,6 +1221,8 @@ func (c *Container) ExecSession(id string) (*ExecSession, error) {
 	returnSession.ID = session.ID
 	returnSession.Command = session.Command
 	returnSession.PID = session.PID
+	returnSession.ConmonPID = session.ConmonPID
+	returnSession.Detached = session.Detached
 
 	return returnSession, nil
 }
@@ -1055,18 +1358,7 @@ func (c *Container) NamespacePath(linuxNS LinuxNS) (string, error) { //nolint:in
 
 // CGroupPath returns a cgroups "path" for a given container.
 func (c *Container) CGroupPath() (string, error) {
-	switch c.runtime.config.CgroupManager {
-	case CgroupfsCgroupsManager:
-		return filepath.Join(c.config.CgroupParent, fmt.Sprintf("libpod-%s", c.ID())), nil
-	case SystemdCgroupsManager:
-		if rootless.IsRootless() {
-			uid := rootless.GetRootlessUID()
-			return filepath.Join(c.config.CgroupParent, fmt.Sprintf("user-%d.slice/user@%d.service/user.slice", uid, uid), createUnitName("libpod", c.ID())), nil
-		}
-		return filepath.Join(c.config.CgroupParent, createUnitName("libpod", c.ID())), nil
-	default:
-		return "", errors.Wrapf(define.ErrInvalidArg, "unsupported CGroup manager %s in use", c.runtime.config.CgroupManager)
-	}
+	return c.runtime.getCgroupManager().containerCgroup(c), nil
 }
 
 // RootFsSize returns the root FS size of the container
@@ -1123,11 +1415,59 @@ func (c *Container) IsInfra() bool {
 	return c.config.IsInfra
 }
 
+// IsInitCtr returns whether the container is an init container of its pod
+func (c *Container) IsInitCtr() bool {
+	return c.config.InitContainerType != ""
+}
+
+// InitContainerType returns the type of the init container, or "" if the
+// container is not an init container
+func (c *Container) InitContainerType() define.InitContainerType {
+	return c.config.InitContainerType
+}
+
+// StartSchedule returns the schedule the container is started on by the
+// scheduler, or "" if it has none
+func (c *Container) StartSchedule() string {
+	return c.config.StartSchedule
+}
+
+// ExternallyManaged returns whether the container's processes may be managed
+// outside of this Podman, so its status is reconciled by the state sync
+func (c *Container) ExternallyManaged() bool {
+	return c.config.ExternallyManaged
+}
+
+// ScheduleNextRun returns the next time the scheduler will start the container.
+// The zero time is returned if it will not be started by the scheduler again.
+func (c *Container) ScheduleNextRun() (time.Time, error) {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return c.state.ScheduleNextRun, nil
+}
+
 // IsReadOnly returns whether the container is running in read only mode
 func (c *Container) IsReadOnly() bool {
 	return c.config.Spec.Root.Readonly
 }
 
+// ReadOnlyTmpfs returns the paths a tmpfs is mounted on because the
+// container's root filesystem is read-only
+func (c *Container) ReadOnlyTmpfs() []string {
+	if c.config.ReadOnlyTmpfs == nil {
+		return nil
+	}
+	paths := make([]string, len(c.config.ReadOnlyTmpfs))
+	copy(paths, c.config.ReadOnlyTmpfs)
+	return paths
+}
+
 // NetworkDisabled returns whether the container is running with a disabled network
 func (c *Container) NetworkDisabled() (bool, error) {
 	if c.config.NetNsCtr != "" {
@@ -1142,7 +1482,7 @@ func (c *Container) NetworkDisabled() (bool, error) {
 }
 
 func networkDisabled(c *Container) (bool, error) {
-	if c.config.CreateNetNS {
+	if c.config.CreateNetNS || c.config.NetNsPath != "" {
 		return false, nil
 	}
 	if !c.config.PostConfigureNetNS {
@@ -1182,3 +1522,9 @@ func (c *Container) HasHealthCheck() bool {
 func (c *Container) HealthCheckConfig() *manifest.Schema2HealthConfig {
 	return c.config.HealthCheckConfig
 }
+
+// HealthCheckOnFailure returns the action taken when the container becomes
+// unhealthy
+func (c *Container) HealthCheckOnFailure() string {
+	return c.config.HealthCheckOnFailure
+}
@@ -1,73 +1,149 @@
 package libpod
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/logs"
+	"github.com/hpcloud/tail"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 // Log is a runtime function that can read one or more container logs.
-func (r *Runtime) Log(containers []*Container, options *logs.LogOptions, logChannel chan *logs.LogLine) error {
+func (r *Runtime) Log(ctx context.Context, containers []*Container, options *logs.LogOptions, logChannel chan *logs.LogLine) error {
 	for _, ctr := range containers {
-		if err := ctr.ReadLog(options, logChannel); err != nil {
+		if err := ctr.ReadLogs(ctx, options, logChannel); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// ReadLog reads a containers log based on the input options and returns loglines over a channel
-func (c *Container) ReadLog(options *logs.LogOptions, logChannel chan *logs.LogLine) error {
-	// TODO Skip sending logs until journald logs can be read
-	// TODO make this not a magic string
-	if c.LogDriver() == JournaldLogging {
-		return c.readFromJournal(options, logChannel)
+// ReadLogs reads the logs of the container, whatever its log driver, and sends
+// them over logChannel. Lines are sent in order, with partial lines joined to
+// the lines completing them, and only the lines between options.Since and
+// options.Until are sent. If options.Tail is positive, only that many of the
+// lines logged so far are sent.
+// Lines are sent in the background, and options.WaitGroup is done once they
+// all are. If options.Follow is set, new lines are sent as they are logged
+// until the context is cancelled, or options.Until is reached if set.
+func (c *Container) ReadLogs(ctx context.Context, options *logs.LogOptions, logChannel chan *logs.LogLine) error {
+	if options.WaitGroup == nil {
+		return errors.Wrapf(define.ErrInvalidArg, "a wait group must be given to read logs")
+	}
+	if options.Follow && !options.Until.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, options.Until)
+		// Following ends at the deadline at the latest
+		go func() {
+			<-ctx.Done()
+			cancel()
+		}()
 	}
-	return c.readFromLogFile(options, logChannel)
-}
 
-func (c *Container) readFromLogFile(options *logs.LogOptions, logChannel chan *logs.LogLine) error {
-	t, tailLog, err := logs.GetLogFile(c.LogPath(), options)
-	if err != nil {
-		// If the log file does not exist, this is not fatal.
-		if os.IsNotExist(errors.Cause(err)) {
-			return nil
+	switch c.LogDriver() {
+	case SyslogLogging, FluentdLogging:
+		return errors.Wrapf(define.ErrNotImplemented, "the %s log driver of container %s does not support reading logs", c.LogDriver(), c.ID())
+	case JournaldLogging:
+		return c.readFromJournal(ctx, options, logChannel)
+	case JSONLogging:
+		t, lines, err := logs.GetJSONFileLog(c.LogPath(), options)
+		if err != nil {
+			return c.logFileError(err)
 		}
-		return errors.Wrapf(err, "unable to read log file %s for %s ", c.ID(), c.LogPath())
+		c.sendLogLines(ctx, t, lines, logs.NewJSONFileLogLine, options, logChannel)
+		return nil
+	}
+	t, lines, err := logs.GetLogFile(c.LogPath(), options)
+	if err != nil {
+		return c.logFileError(err)
+	}
+	c.sendLogLines(ctx, t, lines, logs.NewLogLine, options, logChannel)
+	return nil
+}
+
+// logFileError returns the error to report when the log file of the container
+// cannot be read
+func (c *Container) logFileError(err error) error {
+	// If the log file does not exist, this is not fatal.
+	if os.IsNotExist(errors.Cause(err)) {
+		return nil
 	}
+	return errors.Wrapf(err, "unable to read log file %s for %s ", c.LogPath(), c.ID())
+}
+
+// sendLogLines sends the lines read from a log file in the background, then
+// those of the tail following it, parsed by parse, if there is one. Sending
+// stops when the context is cancelled, even if the lines are not received.
+func (c *Container) sendLogLines(ctx context.Context, t *tail.Tail, lines []*logs.LogLine, parse func(string) (*logs.LogLine, error), options *logs.LogOptions, logChannel chan *logs.LogLine) {
 	options.WaitGroup.Add(1)
-	if len(tailLog) > 0 {
-		for _, nll := range tailLog {
-			nll.CID = c.ID()
-			if nll.Since(options.Since) {
-				logChannel <- nll
+	go func() {
+		defer options.WaitGroup.Done()
+		if t != nil {
+			defer t.Cleanup()
+			defer func() {
+				if err := t.Stop(); err != nil {
+					logrus.Debugf("Error stopping tail of log of container %s: %v", c.ID(), err)
+				}
+			}()
+		}
+		for _, line := range lines {
+			line.CID = c.ID()
+			select {
+			case logChannel <- line:
+			case <-ctx.Done():
+				return
 			}
 		}
-	}
+		if t == nil {
+			return
+		}
 
-	go func() {
 		var partial string
-		for line := range t.Lines {
-			nll, err := logs.NewLogLine(line.Text)
-			if err != nil {
-				logrus.Error(err)
-				continue
-			}
-			if nll.Partial() {
-				partial = partial + nll.Msg
-				continue
-			} else if !nll.Partial() && len(partial) > 1 {
-				nll.Msg = partial
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tailLine, ok := <-t.Lines:
+				if !ok {
+					return
+				}
+				line, err := parse(tailLine.Text)
+				if err != nil {
+					logrus.Error(err)
+					continue
+				}
+				if line.Partial() {
+					partial += line.Msg
+					continue
+				}
+				line.Msg = partial + line.Msg
 				partial = ""
-			}
-			nll.CID = c.ID()
-			if nll.Since(options.Since) {
-				logChannel <- nll
+				if !line.Until(options.Until) {
+					continue
+				}
+				line.CID = c.ID()
+				select {
+				case logChannel <- line:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
-		options.WaitGroup.Done()
 	}()
-	return nil
+}
+
+// logSpillPath returns the path of the file the logs of the container are
+// spilled to while the endpoint of its log driver is unreachable
+func (c *Container) logSpillPath() string {
+	return filepath.Join(c.config.StaticDir, "log-spill.json")
+}
+
+// logShipperStatusPath returns the path of the file the log shipper of the
+// container records its health in
+func (c *Container) logShipperStatusPath() string {
+	return filepath.Join(c.state.RunDir, "log-shipper-status")
 }
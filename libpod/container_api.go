@@ -4,12 +4,13 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"time"
 
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/events"
-	"github.com/containers/storage/pkg/stringid"
+	"github.com/cri-o/ocicni/pkg/ocicni"
 	"github.com/docker/docker/oci/caps"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
@@ -178,7 +179,8 @@ func (c *Container) StopWithTimeout(timeout uint) error {
 
 	if c.state.State == define.ContainerStateConfigured ||
 		c.state.State == define.ContainerStateUnknown ||
-		c.state.State == define.ContainerStatePaused {
+		c.state.State == define.ContainerStatePaused ||
+		c.state.State == define.ContainerStateRemoving {
 		return errors.Wrapf(define.ErrCtrStateInvalid, "can only stop created, running, or stopped containers. %s is in state %s", c.ID(), c.state.State.String())
 	}
 
@@ -206,7 +208,7 @@ func (c *Container) Kill(signal uint) error {
 	}
 
 	defer c.newContainerEvent(events.Kill)
-	if err := c.ociRuntime.killContainer(c, signal); err != nil {
+	if err := c.ociRuntime.KillContainer(c, signal, false); err != nil {
 		return err
 	}
 
@@ -243,23 +245,7 @@ func (c *Container) Exec(tty, privileged bool, env, cmd []string, user, workDir
 		capList = caps.GetAllCapabilities()
 	}
 
-	// Generate exec session ID
-	// Ensure we don't conflict with an existing session ID
-	sessionID := stringid.GenerateNonCryptoID()
-	found := true
-	// This really ought to be a do-while, but Go doesn't have those...
-	for found {
-		found = false
-		for id := range c.state.ExecSessions {
-			if id == sessionID {
-				found = true
-				break
-			}
-		}
-		if found {
-			sessionID = stringid.GenerateNonCryptoID()
-		}
-	}
+	sessionID := c.newExecSessionID()
 
 	logrus.Debugf("Creating new exec session in container %s with session id %s", c.ID(), sessionID)
 	if err := c.createExecBundle(sessionID); err != nil {
@@ -278,7 +264,7 @@ func (c *Container) Exec(tty, privileged bool, env, cmd []string, user, workDir
 		user = c.config.User
 	}
 
-	pid, attachChan, err := c.ociRuntime.execContainer(c, cmd, capList, env, tty, workDir, user, sessionID, streams, preserveFDs, resize, detachKeys)
+	pid, attachChan, err := c.ociRuntime.ExecContainer(c, cmd, capList, env, tty, workDir, user, sessionID, streams, preserveFDs, resize, detachKeys, false)
 	if err != nil {
 		ec := define.ExecErrorCodeGeneric
 		// Conmon will pass a non-zero exit code from the runtime as a pid here.
@@ -304,6 +290,9 @@ func (c *Container) Exec(tty, privileged bool, env, cmd []string, user, workDir
 		// TODO handle this better
 		return define.ExecErrorCodeGeneric, errors.Wrapf(err, "error saving exec sessions %s for container %s", sessionID, c.ID())
 	}
+	if err := c.runtime.state.AddExecSession(c, session); err != nil {
+		logrus.Errorf("Error registering exec session %s of container %s: %v", sessionID, c.ID(), err)
+	}
 	c.newContainerEvent(events.Exec)
 	logrus.Debugf("Successfully started exec session %s in container %s", sessionID, c.ID())
 
@@ -340,11 +329,124 @@ func (c *Container) Exec(tty, privileged bool, env, cmd []string, user, workDir
 	}
 
 	// Remove the exec session from state
-	delete(c.state.ExecSessions, sessionID)
+	c.removeExecSession(sessionID)
+	return exitCode, lastErr
+}
+
+// ExecDetached starts a command in the container without attaching to it, and
+// returns the ID of the exec session running it. The session is monitored by
+// its own conmon and survives the exit of the calling process. Its output is
+// written to the session's log and can be attached to with ExecAttach, and it
+// must be reaped with ReapExecSessions once it has exited.
+func (c *Container) ExecDetached(tty, privileged bool, env, cmd []string, user, workDir string, preserveFDs int) (string, error) {
+	var capList []string
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return "", err
+		}
+	}
+
+	if c.state.State != define.ContainerStateRunning {
+		return "", errors.Wrapf(define.ErrCtrStateInvalid, "cannot exec into container that is not running")
+	}
+
+	if privileged || c.config.Privileged {
+		capList = caps.GetAllCapabilities()
+	}
+
+	sessionID := c.newExecSessionID()
+
+	logrus.Debugf("Creating new detached exec session in container %s with session id %s", c.ID(), sessionID)
+	if err := c.createExecBundle(sessionID); err != nil {
+		return "", err
+	}
+
+	// if the user is empty, we should inherit the user that the container is currently running with
+	if user == "" {
+		user = c.config.User
+	}
+
+	pid, _, err := c.ociRuntime.ExecContainer(c, cmd, capList, env, tty, workDir, user, sessionID, nil, preserveFDs, nil, "", true)
+	if err != nil {
+		if err2 := c.cleanupExecBundle(sessionID); err2 != nil {
+			logrus.Errorf("Error removing exec session %s bundle path for container %s: %v", sessionID, c.ID(), err2)
+		}
+		return "", err
+	}
+
+	session := new(ExecSession)
+	session.ID = sessionID
+	session.Command = cmd
+	session.PID = pid
+	session.Detached = true
+	conmonPID, err := readConmonPidFile(c.execConmonPidPath(sessionID))
+	if err != nil {
+		logrus.Warnf("Error reading conmon PID of exec session %s of container %s: %v", sessionID, c.ID(), err)
+	} else {
+		session.ConmonPID = conmonPID
+	}
+
+	if c.state.ExecSessions == nil {
+		c.state.ExecSessions = make(map[string]*ExecSession)
+	}
+	c.state.ExecSessions[sessionID] = session
 	if err := c.save(); err != nil {
-		logrus.Errorf("Error removing exec session %s from container %s state: %v", sessionID, c.ID(), err)
+		return "", errors.Wrapf(err, "error saving exec sessions %s for container %s", sessionID, c.ID())
 	}
-	return exitCode, lastErr
+	if err := c.runtime.state.AddExecSession(c, session); err != nil {
+		return "", errors.Wrapf(err, "error registering exec session %s of container %s", sessionID, c.ID())
+	}
+	c.newContainerEvent(events.Exec)
+	logrus.Debugf("Successfully started detached exec session %s in container %s", sessionID, c.ID())
+
+	return sessionID, nil
+}
+
+// ExecAttach attaches to the streams of a detached exec session of the
+// container
+func (c *Container) ExecAttach(sessionID string, streams *AttachStreams, keys string, resize <-chan remotecommand.TerminalSize) error {
+	if !c.batched {
+		c.lock.Lock()
+		if err := c.syncContainer(); err != nil {
+			c.lock.Unlock()
+			return err
+		}
+		// Unlock so other processes can use the container while we
+		// are attached
+		c.lock.Unlock()
+	}
+
+	session, ok := c.state.ExecSessions[sessionID]
+	if !ok {
+		return errors.Wrapf(define.ErrNoSuchExecSession, "no exec session with ID %s found in container %s", sessionID, c.ID())
+	}
+	if !session.Detached {
+		return errors.Wrapf(define.ErrInvalidArg, "exec session %s of container %s is already attached", sessionID, c.ID())
+	}
+	if c.execSessionExited(session) {
+		return errors.Wrapf(define.ErrCtrStateInvalid, "exec session %s of container %s has exited", sessionID, c.ID())
+	}
+
+	return c.attachToExecSession(streams, keys, resize, sessionID)
+}
+
+// ReapExecSessions removes the detached exec sessions of the container whose
+// processes have exited, and returns their exit codes by session ID. Sessions
+// whose exit code could not be read are reported with an exit code of -1.
+func (c *Container) ReapExecSessions() (map[string]int, error) {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.reapExecSessions(), nil
 }
 
 // AttachStreams contains streams that will be attached to the container
@@ -386,6 +488,27 @@ func (c *Container) Attach(streams *AttachStreams, keys string, resize <-chan re
 	return c.attach(streams, keys, resize, false, nil)
 }
 
+// AttachResize resizes the terminal of a running container, as seen by the
+// sessions attached to it
+func (c *Container) AttachResize(newSize remotecommand.TerminalSize) error {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return err
+		}
+	}
+
+	if c.state.State != define.ContainerStateRunning {
+		return errors.Wrapf(define.ErrCtrStateInvalid, "can only resize the terminal of running containers")
+	}
+	if c.config.Spec.Process == nil || !c.config.Spec.Process.Terminal {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s does not have a terminal", c.ID())
+	}
+	return resizeTerminal(c.bundlePath(), newSize)
+}
+
 // Mount mounts a container's filesystem on the host
 // The path where the container has been mounted is returned
 func (c *Container) Mount() (string, error) {
@@ -514,7 +637,8 @@ func (c *Container) RemoveArtifact(name string) error {
 
 // Wait blocks until the container exits and returns its exit code.
 func (c *Container) Wait() (int32, error) {
-	return c.WaitWithInterval(DefaultWaitInterval)
+	exitCode, _, err := c.WaitWithCondition(context.Background(), WaitConditionStopped)
+	return exitCode, err
 }
 
 // WaitWithInterval blocks until the container to exit and returns its exit
@@ -536,6 +660,13 @@ func (c *Container) WaitWithInterval(waitTimeout time.Duration) (int32, error) {
 
 		stopped, err := c.isStopped()
 		if err != nil {
+			// The exit code of a removed container may have been
+			// recorded when it exited
+			if isRemovedError(err) {
+				if exitCode, err2 := c.runtime.state.GetContainerExitCode(c.ID()); err2 == nil {
+					return exitCode, nil
+				}
+			}
 			return -1, err
 		}
 		if stopped {
@@ -546,19 +677,39 @@ func (c *Container) WaitWithInterval(waitTimeout time.Duration) (int32, error) {
 
 // Cleanup unmounts all mount points in container and cleans up container storage
 // It also cleans up the network stack
+// If the container is in a pod whose restart policy matches, the containers of
+// the pod are then restarted.
 func (c *Container) Cleanup(ctx context.Context) error {
+	restartPod, finished, err := c.cleanupOrRestart(ctx)
+	if err != nil || !restartPod {
+		return err
+	}
+
+	pod, err := c.runtime.state.Pod(c.config.Pod)
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving pod %s of container %s", c.config.Pod, c.ID())
+	}
+	return pod.handleRestartPolicy(ctx, c, finished)
+}
+
+// cleanupOrRestart restarts the container if its restart policy matches, and
+// cleans it up otherwise. It returns whether the restart policy of the
+// container's pod matches, and when the container exited.
+// The pod's containers cannot be restarted in a batch operation, as the pod
+// must be locked before the container.
+func (c *Container) cleanupOrRestart(ctx context.Context) (bool, time.Time, error) {
 	if !c.batched {
 		c.lock.Lock()
 		defer c.lock.Unlock()
 
 		if err := c.syncContainer(); err != nil {
-			return err
+			return false, time.Time{}, err
 		}
 	}
 
 	// Check if state is good
 	if c.state.State == define.ContainerStateRunning || c.state.State == define.ContainerStatePaused {
-		return errors.Wrapf(define.ErrCtrStateInvalid, "container %s is running or paused, refusing to clean up", c.ID())
+		return false, time.Time{}, errors.Wrapf(define.ErrCtrStateInvalid, "container %s is running or paused, refusing to clean up", c.ID())
 	}
 
 	// Handle restart policy.
@@ -566,20 +717,34 @@ func (c *Container) Cleanup(ctx context.Context) error {
 	// If we did, don't proceed to cleanup - just exit.
 	didRestart, err := c.handleRestartPolicy(ctx)
 	if err != nil {
-		return err
+		return false, time.Time{}, err
 	}
 	if didRestart {
-		return nil
+		return false, time.Time{}, nil
 	}
 
 	// If we didn't restart, we perform a normal cleanup
 
+	// Detached exec sessions end with the container
+	c.reapExecSessions()
+
 	// Check if we have active exec sessions
 	if len(c.state.ExecSessions) != 0 {
-		return errors.Wrapf(define.ErrCtrStateInvalid, "container %s has active exec sessions, refusing to clean up", c.ID())
+		return false, time.Time{}, errors.Wrapf(define.ErrCtrStateInvalid, "container %s has active exec sessions, refusing to clean up", c.ID())
 	}
+
+	restartPod := false
+	if !c.batched {
+		if restartPod, err = c.podRestartMatch(); err != nil {
+			return false, time.Time{}, err
+		}
+	}
+
 	defer c.newContainerEvent(events.Cleanup)
-	return c.cleanup(ctx)
+	if err := c.cleanup(ctx); err != nil {
+		return false, time.Time{}, err
+	}
+	return restartPod, c.state.FinishedTime, nil
 }
 
 // Batch starts a batch operation on the given container
@@ -637,9 +802,10 @@ func (c *Container) Sync() error {
 	// And then save back to disk
 	if (c.state.State != define.ContainerStateUnknown) &&
 		(c.state.State != define.ContainerStateConfigured) &&
-		(c.state.State != define.ContainerStateExited) {
+		(c.state.State != define.ContainerStateExited) &&
+		(c.state.State != define.ContainerStateRemoving) {
 		oldState := c.state.State
-		if err := c.ociRuntime.updateContainerStatus(c, true); err != nil {
+		if err := c.ociRuntime.UpdateContainerStatus(c, true); err != nil {
 			return err
 		}
 		// Only save back to DB if state changed
@@ -696,7 +862,7 @@ func (c *Container) Refresh(ctx context.Context) error {
 	if len(c.state.ExecSessions) > 0 {
 		logrus.Infof("Killing %d exec sessions in container %s. They will not be restored after refresh.",
 			len(c.state.ExecSessions), c.ID())
-		if err := c.ociRuntime.execStopContainer(c, c.config.StopTimeout); err != nil {
+		if err := c.ociRuntime.ExecStopContainer(c, c.config.StopTimeout); err != nil {
 			return err
 		}
 	}
@@ -790,6 +956,9 @@ func (c *Container) Checkpoint(ctx context.Context, options ContainerCheckpointO
 	logrus.Debugf("Trying to checkpoint container %s", c.ID())
 
 	if options.TargetFile != "" {
+		if _, _, err := c.runtime.checkpointStorage(options.TargetFile); err != nil {
+			return err
+		}
 		if err := c.prepareCheckpointExport(); err != nil {
 			return err
 		}
@@ -821,3 +990,71 @@ func (c *Container) Restore(ctx context.Context, options ContainerCheckpointOpti
 	defer c.newContainerEvent(events.Restore)
 	return c.restore(ctx, options)
 }
+
+// NetworkConnect connects the container to an additional CNI network, with the
+// given aliases and, if not nil, static IP on that network.
+// If the container's network namespace is active, the network is attached to
+// it immediately. The network is also added to the container's configuration,
+// so it remains attached when the container is restarted.
+func (c *Container) NetworkConnect(netName string, aliases []string, staticIP net.IP) error {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return err
+		}
+	}
+
+	return c.networkConnect(netName, aliases, staticIP)
+}
+
+// NetworkDisconnect disconnects the container from one of its CNI networks.
+// If the container's network namespace is active, the network is detached from
+// it immediately. A container cannot be disconnected from its only network.
+func (c *Container) NetworkDisconnect(netName string) error {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return err
+		}
+	}
+
+	return c.networkDisconnect(netName)
+}
+
+// PublishPorts publishes additional ports of the container.
+// If the container is running, the ports are forwarded to it immediately. The
+// ports are also saved in the container's configuration, so they remain
+// published when the container is restarted.
+func (c *Container) PublishPorts(ports []ocicni.PortMapping) error {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return err
+		}
+	}
+
+	return c.updatePorts(ports, nil)
+}
+
+// UnpublishPorts stops publishing ports of the container. Ports are matched by
+// host IP, host port and protocol.
+// If the container is running, the ports are no longer forwarded to it
+// immediately.
+func (c *Container) UnpublishPorts(ports []ocicni.PortMapping) error {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return err
+		}
+	}
+
+	return c.updatePorts(nil, ports)
+}
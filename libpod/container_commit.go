@@ -50,11 +50,11 @@ func (c *Container) Commit(ctx context.Context, destImage string, options Contai
 	}
 
 	if c.state.State == define.ContainerStateRunning && options.Pause {
-		if err := c.ociRuntime.pauseContainer(c); err != nil {
+		if err := c.ociRuntime.PauseContainer(c); err != nil {
 			return nil, errors.Wrapf(err, "error pausing container %q", c.ID())
 		}
 		defer func() {
-			if err := c.ociRuntime.unpauseContainer(c); err != nil {
+			if err := c.ociRuntime.UnpauseContainer(c); err != nil {
 				logrus.Errorf("error unpausing container %q: %v", c.ID(), err)
 			}
 		}()
@@ -0,0 +1,66 @@
+package libpod
+
+import (
+	"strconv"
+	"time"
+)
+
+// ContainerExitReport summarizes the resources a container used during its
+// last run. It is collected from the container's cgroup when the container
+// exits, and is only available on systems using cgroups v2.
+type ContainerExitReport struct {
+	// PeakMemory is the highest memory usage of the container's
+	// processes combined, in bytes. It is 0 if the kernel does not record
+	// it.
+	PeakMemory uint64 `json:"peakMemory,omitempty"`
+	// CPUTime is the CPU time used by the container's processes
+	CPUTime time.Duration `json:"cpuTime"`
+	// CPUUserTime is the CPU time used in user mode
+	CPUUserTime time.Duration `json:"cpuUserTime"`
+	// CPUSystemTime is the CPU time used in kernel mode
+	CPUSystemTime time.Duration `json:"cpuSystemTime"`
+	// PeakPids is the highest number of processes in the container. It
+	// is 0 if the kernel does not record it.
+	PeakPids uint64 `json:"peakPids,omitempty"`
+	// OOMKills is the number of processes killed by the OOM killer
+	OOMKills uint64 `json:"oomKills,omitempty"`
+}
+
+// ExitReport returns a summary of the resources the container used during its
+// last run. If no report was collected, because the container has not exited
+// since it was last started or the system does not use cgroups v2, nil is
+// returned.
+func (c *Container) ExitReport() (*ContainerExitReport, error) {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.state.ExitReport == nil {
+		return nil, nil
+	}
+	report := *c.state.ExitReport
+	return &report, nil
+}
+
+// eventAttributes returns the report as attributes of the container's exited
+// event
+func (r *ContainerExitReport) eventAttributes() map[string]string {
+	attributes := map[string]string{
+		"cpu_time": r.CPUTime.String(),
+	}
+	if r.PeakMemory > 0 {
+		attributes["peak_memory"] = strconv.FormatUint(r.PeakMemory, 10)
+	}
+	if r.PeakPids > 0 {
+		attributes["peak_pids"] = strconv.FormatUint(r.PeakPids, 10)
+	}
+	if r.OOMKills > 0 {
+		attributes["oom_kills"] = strconv.FormatUint(r.OOMKills, 10)
+	}
+	return attributes
+}
@@ -2,7 +2,10 @@ package libpod
 
 import (
 	"context"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/containers/libpod/libpod/define"
 	"github.com/pkg/errors"
@@ -91,6 +94,35 @@ func BuildContainerGraph(ctrs []*Container) (*ContainerGraph, error) {
 	return graph, nil
 }
 
+// dependencyOrder returns the containers of the graph ordered so that every
+// container comes after the containers it depends on. Containers that do not
+// depend on each other are ordered by ID, so the order is stable.
+func (cg *ContainerGraph) dependencyOrder() []*Container {
+	remainingDeps := make(map[string]int, len(cg.nodes))
+	ready := make([]*containerNode, 0, len(cg.noDepNodes))
+	for _, node := range cg.nodes {
+		remainingDeps[node.id] = len(node.dependsOn)
+		if len(node.dependsOn) == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	ctrs := make([]*Container, 0, len(cg.nodes))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return ready[i].id < ready[j].id })
+		node := ready[0]
+		ready = ready[1:]
+		ctrs = append(ctrs, node.container)
+		for _, successor := range node.dependedOn {
+			remainingDeps[successor.id]--
+			if remainingDeps[successor.id] == 0 {
+				ready = append(ready, successor)
+			}
+		}
+	}
+	return ctrs
+}
+
 // Detect cycles in a container graph using Tarjan's strongly connected
 // components algorithm
 // Return true if a cycle is found, false otherwise
@@ -230,6 +262,25 @@ func startNode(ctx context.Context, node *containerNode, setError bool, ctrError
 	ctrsVisited[node.id] = true
 
 	ctrErrored := false
+	if err := startGraphNode(ctx, node, restart); err != nil {
+		ctrErrors[node.id] = err
+		ctrErrored = true
+	}
+
+	// Recurse to anyone who depends on us and start them
+	for _, successor := range node.dependedOn {
+		startNode(ctx, successor, ctrErrored, ctrErrors, ctrsVisited, restart)
+	}
+}
+
+// startGraphNode starts, or restarts if restart is set, the container of a
+// node whose dependencies have all been visited
+func startGraphNode(ctx context.Context, node *containerNode, restart bool) error {
+	// Dependencies that must become healthy or exit may take some time to
+	// do so after being started
+	if err := node.container.waitForDependencyConditions(ctx); err != nil {
+		return err
+	}
 
 	// Check if dependencies are running
 	// Graph traversal means we should have started them
@@ -238,46 +289,137 @@ func startNode(ctx context.Context, node *containerNode, setError bool, ctrError
 	// the dependencies
 	depsStopped, err := node.container.checkDependenciesRunning()
 	if err != nil {
-		ctrErrors[node.id] = err
-		ctrErrored = true
+		return err
 	} else if len(depsStopped) > 0 {
-		// Our dependencies are not running
+		// Our dependencies are not ready
 		depsList := strings.Join(depsStopped, ",")
-		ctrErrors[node.id] = errors.Wrapf(define.ErrCtrStateInvalid, "the following dependencies of container %s are not running: %s", node.id, depsList)
-		ctrErrored = true
+		return errors.Wrapf(define.ErrCtrStateInvalid, "the following dependencies of container %s are not ready: %s", node.id, depsList)
 	}
 
 	// Lock before we start
 	node.container.lock.Lock()
+	defer node.container.lock.Unlock()
 
 	// Sync the container to pick up current state
-	if !ctrErrored {
-		if err := node.container.syncContainer(); err != nil {
-			ctrErrored = true
-			ctrErrors[node.id] = err
-		}
+	if err := node.container.syncContainer(); err != nil {
+		return err
 	}
 
 	// Start the container (only if it is not running)
-	if !ctrErrored {
-		if !restart && node.container.state.State != define.ContainerStateRunning {
-			if err := node.container.initAndStart(ctx); err != nil {
-				ctrErrored = true
-				ctrErrors[node.id] = err
+	if !restart && node.container.state.State != define.ContainerStateRunning {
+		return node.container.initAndStart(ctx)
+	}
+	if restart && node.container.state.State != define.ContainerStatePaused && node.container.state.State != define.ContainerStateUnknown {
+		return node.container.restartWithTimeout(ctx, node.container.config.StopTimeout)
+	}
+	return nil
+}
+
+// ContainerStartResult is the outcome of starting one container of a pod
+type ContainerStartResult struct {
+	// ID is the ID of the container.
+	ID string
+	// Name is the name of the container.
+	Name string
+	// Err is the error starting the container, or nil if it started.
+	Err error
+	// DependencyFailed indicates the container was not started because
+	// one of its dependencies failed to start.
+	DependencyFailed bool
+	// Duration is how long starting the container took, including
+	// waiting for the conditions on its dependencies.
+	Duration time.Duration
+}
+
+// startGraphParallel starts the containers of a graph with startFn, starting a
+// container only once all of its dependencies have been started, with up to
+// parallelism containers starting at once. Containers depending on a container
+// that failed to start are not started.
+func startGraphParallel(ctx context.Context, graph *ContainerGraph, parallelism int, startFn func(context.Context, *containerNode) error) map[string]*ContainerStartResult {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	type nodeResult struct {
+		node     *containerNode
+		err      error
+		duration time.Duration
+	}
+	work := make(chan *containerNode)
+	done := make(chan nodeResult)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range work {
+				start := time.Now()
+				err := startFn(ctx, node)
+				done <- nodeResult{node: node, err: err, duration: time.Since(start)}
 			}
-		}
-		if restart && node.container.state.State != define.ContainerStatePaused && node.container.state.State != define.ContainerStateUnknown {
-			if err := node.container.restartWithTimeout(ctx, node.container.config.StopTimeout); err != nil {
-				ctrErrored = true
-				ctrErrors[node.id] = err
+		}()
+	}
+
+	results := make(map[string]*ContainerStartResult, len(graph.nodes))
+	// Number of dependencies of each node not yet started
+	pending := make(map[string]int, len(graph.nodes))
+	for id, node := range graph.nodes {
+		pending[id] = len(node.dependsOn)
+	}
+	ready := append([]*containerNode{}, graph.noDepNodes...)
+
+	// finish records the result of a node, and releases the nodes
+	// depending on it, failing them if it failed
+	var finish func(node *containerNode, result *ContainerStartResult)
+	finish = func(node *containerNode, result *ContainerStartResult) {
+		results[node.id] = result
+		for _, successor := range node.dependedOn {
+			if result.Err != nil && results[successor.id] == nil {
+				results[successor.id] = &ContainerStartResult{
+					ID:               successor.id,
+					Name:             successor.container.Name(),
+					Err:              errors.Wrapf(define.ErrCtrStateInvalid, "a dependency of container %s failed to start", successor.id),
+					DependencyFailed: true,
+				}
+			}
+			pending[successor.id]--
+			if pending[successor.id] > 0 {
+				continue
+			}
+			if failed := results[successor.id]; failed != nil {
+				finish(successor, failed)
+			} else {
+				ready = append(ready, successor)
 			}
 		}
 	}
 
-	node.container.lock.Unlock()
-
-	// Recurse to anyone who depends on us and start them
-	for _, successor := range node.dependedOn {
-		startNode(ctx, successor, ctrErrored, ctrErrors, ctrsVisited, restart)
+	inFlight := 0
+	for len(ready) > 0 || inFlight > 0 {
+		var (
+			send chan *containerNode
+			next *containerNode
+		)
+		if len(ready) > 0 {
+			send = work
+			next = ready[0]
+		}
+		select {
+		case send <- next:
+			ready = ready[1:]
+			inFlight++
+		case res := <-done:
+			inFlight--
+			finish(res.node, &ContainerStartResult{
+				ID:       res.node.id,
+				Name:     res.node.container.Name(),
+				Err:      res.err,
+				Duration: res.duration,
+			})
+		}
 	}
+	close(work)
+	wg.Wait()
+
+	return results
 }
@@ -1,9 +1,13 @@
 package libpod
 
 import (
+	"context"
+	"sync"
 	"testing"
 
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/lock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -309,3 +313,83 @@ func TestBuildContainerGraphFourContainersNoneInCycle(t *testing.T) {
 	assert.Equal(t, 2, len(graph.noDepNodes))
 	assert.Equal(t, 2, len(graph.notDependedOnNodes))
 }
+
+func TestContainerGraphDependencyOrder(t *testing.T) {
+	manager, err := lock.NewInMemoryManager(16)
+	if err != nil {
+		t.Fatalf("Error setting up locks: %v", err)
+	}
+
+	ctr1, err := getTestCtr1(manager)
+	assert.NoError(t, err)
+	ctr2, err := getTestCtr2(manager)
+	assert.NoError(t, err)
+	ctr3, err := getTestCtrN("3", manager)
+	assert.NoError(t, err)
+	ctr4, err := getTestCtrN("4", manager)
+	assert.NoError(t, err)
+	ctr1.config.UserNsCtr = ctr2.config.ID
+	ctr1.config.NetNsCtr = ctr3.config.ID
+	ctr2.config.IPCNsCtr = ctr3.config.ID
+
+	graph, err := BuildContainerGraph([]*Container{ctr4, ctr1, ctr2, ctr3})
+	assert.NoError(t, err)
+
+	order := []string{}
+	for _, ctr := range graph.dependencyOrder() {
+		order = append(order, ctr.ID())
+	}
+	assert.Equal(t, []string{ctr3.ID(), ctr2.ID(), ctr1.ID(), ctr4.ID()}, order)
+}
+
+func TestStartGraphParallel(t *testing.T) {
+	manager, err := lock.NewInMemoryManager(16)
+	if err != nil {
+		t.Fatalf("Error setting up locks: %v", err)
+	}
+
+	// ctr1 <- ctr2 <- ctr4, ctr1 <- ctr3, ctr5 independent
+	ctrs := make([]*Container, 0, 5)
+	for _, n := range []string{"1", "2", "3", "4", "5"} {
+		ctr, err := getTestCtrN(n, manager)
+		assert.NoError(t, err)
+		ctrs = append(ctrs, ctr)
+	}
+	ctrs[1].config.NetNsCtr = ctrs[0].ID()
+	ctrs[2].config.NetNsCtr = ctrs[0].ID()
+	ctrs[3].config.IPCNsCtr = ctrs[1].ID()
+
+	graph, err := BuildContainerGraph(ctrs)
+	assert.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		started = make(map[string]bool)
+	)
+	startFn := func(ctx context.Context, node *containerNode) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, dep := range node.dependsOn {
+			if !started[dep.id] {
+				return errors.Errorf("container %s started before its dependency %s", node.id, dep.id)
+			}
+		}
+		started[node.id] = true
+		if node.id == ctrs[1].ID() {
+			return errors.Errorf("failed to start")
+		}
+		return nil
+	}
+
+	results := startGraphParallel(context.Background(), graph, 2, startFn)
+	assert.Len(t, results, 5)
+	for _, i := range []int{0, 2, 4} {
+		assert.NoError(t, results[ctrs[i].ID()].Err)
+		assert.False(t, results[ctrs[i].ID()].DependencyFailed)
+	}
+	assert.Error(t, results[ctrs[1].ID()].Err)
+	assert.False(t, results[ctrs[1].ID()].DependencyFailed)
+	assert.Equal(t, define.ErrCtrStateInvalid, errors.Cause(results[ctrs[3].ID()].Err))
+	assert.True(t, results[ctrs[3].ID()].DependencyFailed)
+	assert.False(t, started[ctrs[3].ID()])
+}
@@ -2,12 +2,14 @@ package libpod
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/containers/image/manifest"
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/driver"
+	"github.com/containers/libpod/libpod/logs"
 	"github.com/containers/libpod/pkg/util"
 	"github.com/cri-o/ocicni/pkg/ocicni"
 	spec "github.com/opencontainers/runtime-spec/specs-go"
@@ -108,6 +110,7 @@ type InspectContainerData struct {
 	OCIRuntime      string                      `json:"OCIRuntime,omitempty"`
 	LogPath         string                      `json:"LogPath"`
 	ConmonPidFile   string                      `json:"ConmonPidFile"`
+	ConmonDebugLog  string                      `json:"ConmonDebugLog,omitempty"`
 	Name            string                      `json:"Name"`
 	RestartCount    int32                       `json:"RestartCount"`
 	Driver          string                      `json:"Driver"`
@@ -120,12 +123,14 @@ type InspectContainerData struct {
 	GraphDriver     *driver.Data                `json:"GraphDriver"`
 	SizeRw          int64                       `json:"SizeRw,omitempty"`
 	SizeRootFs      int64                       `json:"SizeRootFs,omitempty"`
+	Verify          *ContainerVerifyReport      `json:"Verify,omitempty"`
 	Mounts          []InspectMount              `json:"Mounts"`
 	Dependencies    []string                    `json:"Dependencies"`
 	NetworkSettings *InspectNetworkSettings     `json:"NetworkSettings"` //TODO
 	ExitCommand     []string                    `json:"ExitCommand"`
 	Namespace       string                      `json:"Namespace"`
 	IsInfra         bool                        `json:"IsInfra"`
+	Hooks           map[string][]InspectHook    `json:"Hooks,omitempty"`
 	Config          *InspectContainerConfig     `json:"Config"`
 	HostConfig      *InspectContainerHostConfig `json:"HostConfig"`
 }
@@ -174,6 +179,18 @@ type InspectContainerConfig struct {
 	StopSignal uint `json:"StopSignal"`
 	// Configured healthcheck for the container
 	Healthcheck *manifest.Schema2HealthConfig `json:"Healthcheck,omitempty"`
+	// HealthcheckOnFailure is the action taken when the container becomes
+	// unhealthy.
+	HealthcheckOnFailure string `json:"HealthcheckOnFailure,omitempty"`
+	// StartSchedule is the schedule the container is started on by the
+	// scheduler, if any.
+	StartSchedule string `json:"StartSchedule,omitempty"`
+	// StopEscalation is the container's stop signal escalation chain, if
+	// any, in the SIGNAL:TIMEOUT,... format.
+	StopEscalation string `json:"StopEscalation,omitempty"`
+	// SdNotifyMode is how the systemd unit running podman is notified
+	// that the container is ready.
+	SdNotifyMode string `json:"SdNotifyMode,omitempty"`
 }
 
 // InspectContainerHostConfig holds information used when the container was
@@ -216,6 +233,9 @@ type InspectContainerHostConfig struct {
 	// and represents the container port. A single container port may be
 	// bound to multiple host ports (on different IPs).
 	PortBindings map[string][]InspectHostPort `json:"PortBindings"`
+	// NetworkBandwidth contains the limits on the rate of the container's
+	// traffic on its CNI networks, if any were set.
+	NetworkBandwidth *InspectNetworkBandwidth `json:"NetworkBandwidth,omitempty"`
 	// RestartPolicy contains the container's restart policy.
 	RestartPolicy *InspectRestartPolicy `json:"RestartPolicy"`
 	// AutoRemove is whether the container will be automatically removed on
@@ -316,8 +336,8 @@ type InspectContainerHostConfig struct {
 	// private - The container will be run in a user namespace
 	// container:<id> - Using another container's user namespace
 	// ns:<path> - A path to a user namespace has been specified
-	// TODO Rootless has an additional 'keep-id' option, presently not
-	// reflected here.
+	// keep-id - The container keeps the UID and GID of the invoking
+	// user (rootless only)
 	UsernsMode string `json:"UsernsMode"`
 	// ShmSize is the size of the container's SHM device.
 	ShmSize int64 `json:"ShmSize"`
@@ -472,6 +492,26 @@ type InspectContainerHostConfig struct {
 type InspectLogConfig struct {
 	Type   string            `json:"Type"`
 	Config map[string]string `json:"Config"` //idk type, TODO
+	// Health is the health of the log driver, for drivers sending the logs
+	// to a remote endpoint. It is only set while the container is running.
+	Health *InspectLogHealth `json:"Health,omitempty"`
+}
+
+// InspectLogHealth holds the health of a log driver sending the logs of a
+// container to a remote endpoint
+type InspectLogHealth struct {
+	// Status is "connected" while logs are sent to the endpoint, and
+	// "buffering" while they are spilled to disk as it is unreachable.
+	Status string `json:"Status"`
+	// Error is the last error sending logs to the endpoint.
+	Error string `json:"Error,omitempty"`
+	// BufferedLines is the number of lines spilled to disk and not sent
+	// yet.
+	BufferedLines int `json:"BufferedLines"`
+	// DroppedLines is the number of lines lost as the spill file was full.
+	DroppedLines int `json:"DroppedLines,omitempty"`
+	// Updated is when the health last changed.
+	Updated time.Time `json:"Updated"`
 }
 
 // InspectRestartPolicy holds information about the container's restart policy.
@@ -575,26 +615,88 @@ type InspectHostPort struct {
 	HostPort string `json:"HostPort"`
 }
 
+// InspectNetworkBandwidth provides information on the limits on the rate of a
+// container's traffic. Rates are in bits per second and bursts in bits. A rate
+// of 0 is not limited.
+type InspectNetworkBandwidth struct {
+	IngressRate  uint64 `json:"IngressRate"`
+	IngressBurst uint64 `json:"IngressBurst"`
+	EgressRate   uint64 `json:"EgressRate"`
+	EgressBurst  uint64 `json:"EgressBurst"`
+}
+
+// InspectHook is an OCI hook run at one of the stages of a container's
+// lifecycle. It is returned as part of InspectContainerData, which holds the
+// hooks of the container by stage, whether they are set in its spec or matched
+// from the hooks directories.
+type InspectHook struct {
+	Path    string   `json:"Path"`
+	Args    []string `json:"Args,omitempty"`
+	Env     []string `json:"Env,omitempty"`
+	Timeout *int     `json:"Timeout,omitempty"`
+}
+
 // InspectContainerState provides a detailed record of a container's current
 // state. It is returned as part of InspectContainerData.
 // As with InspectContainerData, many portions of this struct are matched to
 // Docker, but here we see more fields that are unused (nonsensical in the
 // context of Libpod).
 type InspectContainerState struct {
-	OciVersion  string             `json:"OciVersion"`
-	Status      string             `json:"Status"`
-	Running     bool               `json:"Running"`
-	Paused      bool               `json:"Paused"`
-	Restarting  bool               `json:"Restarting"` // TODO
-	OOMKilled   bool               `json:"OOMKilled"`
-	Dead        bool               `json:"Dead"`
-	Pid         int                `json:"Pid"`
-	ConmonPid   int                `json:"ConmonPid,omitempty"`
-	ExitCode    int32              `json:"ExitCode"`
-	Error       string             `json:"Error"` // TODO
-	StartedAt   time.Time          `json:"StartedAt"`
-	FinishedAt  time.Time          `json:"FinishedAt"`
-	Healthcheck HealthCheckResults `json:"Healthcheck,omitempty"`
+	OciVersion  string                  `json:"OciVersion"`
+	Status      string                  `json:"Status"`
+	Running     bool                    `json:"Running"`
+	Paused      bool                    `json:"Paused"`
+	Restarting  bool                    `json:"Restarting"` // TODO
+	OOMKilled   bool                    `json:"OOMKilled"`
+	Frozen      bool                    `json:"Frozen,omitempty"`
+	Dead        bool                    `json:"Dead"`
+	Pid         int                     `json:"Pid"`
+	ConmonPid   int                     `json:"ConmonPid,omitempty"`
+	ExitCode    int32                   `json:"ExitCode"`
+	Error       string                  `json:"Error"` // TODO
+	StartedAt   time.Time               `json:"StartedAt"`
+	FinishedAt  time.Time               `json:"FinishedAt"`
+	Healthcheck HealthCheckResults      `json:"Healthcheck,omitempty"`
+	Schedule    *InspectScheduleState   `json:"Schedule,omitempty"`
+	AutoUpdate  *InspectAutoUpdateState `json:"AutoUpdate,omitempty"`
+	StopStep    *InspectStopStep        `json:"StopStep,omitempty"`
+	ExitReport  *ContainerExitReport    `json:"ExitReport,omitempty"`
+	// Checkpointed is set if the container was stopped by a checkpoint it
+	// can be restored from.
+	Checkpointed   bool      `json:"Checkpointed,omitempty"`
+	CheckpointedAt time.Time `json:"CheckpointedAt,omitempty"`
+	RestoredAt     time.Time `json:"RestoredAt,omitempty"`
+}
+
+// InspectStopStep holds the step of the stop signal escalation chain that was
+// last reached while stopping a container.
+type InspectStopStep struct {
+	// Step is the step that was reached, starting at 1.
+	Step int `json:"Step"`
+	// Signal is the signal sent at the step.
+	Signal uint `json:"Signal"`
+	// Time is when the step was reached.
+	Time time.Time `json:"Time"`
+}
+
+// InspectScheduleState holds the runs of a container with a start schedule.
+type InspectScheduleState struct {
+	// NextRun is the next time the scheduler will start the container.
+	// It is the zero time if it will not be started again.
+	NextRun time.Time `json:"NextRun"`
+	// LastRun is the last time the scheduler started the container.
+	LastRun time.Time `json:"LastRun"`
+}
+
+// InspectAutoUpdateState holds the auto-updates of a container labeled for
+// auto-update.
+type InspectAutoUpdateState struct {
+	// LastCheck is the last time the container was checked for a newer
+	// image.
+	LastCheck time.Time `json:"LastCheck"`
+	// LastUpdate is the last time the unit of the container was restarted
+	// to run a newer image.
+	LastUpdate time.Time `json:"LastUpdate"`
 }
 
 // InspectNetworkSettings holds information about the network settings of the
@@ -705,6 +807,7 @@ func (c *Container) getContainerInspectData(size bool, driverData *driver.Data)
 			Running:    runtimeInfo.State == define.ContainerStateRunning,
 			Paused:     runtimeInfo.State == define.ContainerStatePaused,
 			OOMKilled:  runtimeInfo.OOMKilled,
+			Frozen:     runtimeInfo.MemoryPressureFrozen,
 			Dead:       runtimeInfo.State.String() == "bad state",
 			Pid:        runtimeInfo.PID,
 			ConmonPid:  runtimeInfo.ConmonPID,
@@ -712,6 +815,10 @@ func (c *Container) getContainerInspectData(size bool, driverData *driver.Data)
 			Error:      "", // can't get yet
 			StartedAt:  runtimeInfo.StartedTime,
 			FinishedAt: runtimeInfo.FinishedTime,
+
+			Checkpointed:   runtimeInfo.Checkpointed,
+			CheckpointedAt: runtimeInfo.CheckpointedTime,
+			RestoredAt:     runtimeInfo.RestoredTime,
 		},
 		ImageID:         config.RootfsImageID,
 		ImageName:       config.RootfsImageName,
@@ -726,6 +833,7 @@ func (c *Container) getContainerInspectData(size bool, driverData *driver.Data)
 		LogPath:         config.LogPath,
 		OCIRuntime:      config.OCIRuntime,
 		ConmonPidFile:   config.ConmonPidFile,
+		ConmonDebugLog:  c.ConmonDebugLogPath(),
 		Name:            config.Name,
 		RestartCount:    int32(runtimeInfo.RestartCount),
 		Driver:          driverData.Name,
@@ -765,14 +873,37 @@ func (c *Container) getContainerInspectData(size bool, driverData *driver.Data)
 		data.OCIConfigPath = c.state.ConfigPath
 	}
 
+	data.Hooks = inspectHooks(ctrSpec.Hooks, c.state.ExtensionStageHooks)
+
 	if c.config.HealthCheckConfig != nil {
 		// This container has a healthcheck defined in it; we need to add it's state
-		healthCheckState, err := c.GetHealthCheckLog()
-		if err != nil {
-			// An error here is not considered fatal; no health state will be displayed
-			logrus.Error(err)
-		} else {
-			data.State.Healthcheck = healthCheckState
+		data.State.Healthcheck = c.healthCheckResults()
+	}
+
+	if runtimeInfo.ExitReport != nil {
+		report := *runtimeInfo.ExitReport
+		data.State.ExitReport = &report
+	}
+
+	if runtimeInfo.StopStep > 0 {
+		data.State.StopStep = &InspectStopStep{
+			Step:   runtimeInfo.StopStep,
+			Signal: runtimeInfo.StopStepSignal,
+			Time:   runtimeInfo.StopStepTime,
+		}
+	}
+
+	if config.StartSchedule != "" {
+		data.State.Schedule = &InspectScheduleState{
+			NextRun: runtimeInfo.ScheduleNextRun,
+			LastRun: runtimeInfo.ScheduleLastRun,
+		}
+	}
+
+	if _, ok := config.Labels[AutoUpdateLabel]; ok {
+		data.State.AutoUpdate = &InspectAutoUpdateState{
+			LastCheck:  runtimeInfo.AutoUpdateLastCheck,
+			LastUpdate: runtimeInfo.AutoUpdateLastUpdate,
 		}
 	}
 
@@ -941,6 +1072,13 @@ func (c *Container) generateInspectContainerConfig(spec *spec.Spec) (*InspectCon
 	// TODO: should JSON deep copy this to ensure internal pointers don't
 	// leak.
 	ctrConfig.Healthcheck = c.config.HealthCheckConfig
+	ctrConfig.HealthcheckOnFailure = c.config.HealthCheckOnFailure
+
+	ctrConfig.StartSchedule = c.config.StartSchedule
+	if len(c.config.StopEscalation) > 0 {
+		ctrConfig.StopEscalation = formatStopEscalation(c.config.StopEscalation)
+	}
+	ctrConfig.SdNotifyMode = string(c.SdNotifyMode())
 
 	return ctrConfig, nil
 }
@@ -952,6 +1090,30 @@ func (c *Container) generateInspectContainerHostConfig(ctrSpec *spec.Spec, named
 
 	logConfig := new(InspectLogConfig)
 	logConfig.Type = c.config.LogDriver
+	if c.config.LogTag != "" || c.config.LogAddress != "" {
+		logConfig.Config = make(map[string]string)
+		if c.config.LogTag != "" {
+			logConfig.Config["tag"] = c.config.LogTag
+		}
+		if c.config.LogAddress != "" {
+			logConfig.Config[c.config.LogDriver+"-address"] = c.config.LogAddress
+		}
+	}
+	if c.config.LogDriver == SyslogLogging || c.config.LogDriver == FluentdLogging {
+		status, err := logs.ReadShipperStatus(c.logShipperStatusPath())
+		switch {
+		case err == nil:
+			logConfig.Health = &InspectLogHealth{
+				Status:        status.State,
+				Error:         status.Error,
+				BufferedLines: status.Buffered,
+				DroppedLines:  status.Dropped,
+				Updated:       status.Updated,
+			}
+		case !os.IsNotExist(err):
+			logrus.Errorf("Error reading health of log driver of container %s: %v", c.ID(), err)
+		}
+	}
 	hostConfig.LogConfig = logConfig
 
 	restartPolicy := new(InspectRestartPolicy)
@@ -1185,6 +1347,22 @@ func (c *Container) generateInspectContainerHostConfig(ctrSpec *spec.Spec, named
 			}
 		}
 	}
+	// The tmpfs mounts of a read-only root filesystem are only added
+	// when the spec is generated, so they are not in the mounts above.
+	if ctrSpec.Root != nil && ctrSpec.Root.Readonly {
+	readOnlyTmpfs:
+		for _, dest := range c.config.ReadOnlyTmpfs {
+			if MountExists(mounts, dest) {
+				continue
+			}
+			for _, namedVol := range namedVolumes {
+				if namedVol.Dest == dest {
+					continue readOnlyTmpfs
+				}
+			}
+			tmpfs[dest] = strings.Join(readOnlyTmpfsMount(dest).Options, ",")
+		}
+	}
 	hostConfig.Binds = binds
 	hostConfig.Tmpfs = tmpfs
 
@@ -1235,6 +1413,15 @@ func (c *Container) generateInspectContainerHostConfig(ctrSpec *spec.Spec, named
 	}
 	hostConfig.PortBindings = portBindings
 
+	if c.config.Bandwidth != nil {
+		hostConfig.NetworkBandwidth = &InspectNetworkBandwidth{
+			IngressRate:  c.config.Bandwidth.IngressRate,
+			IngressBurst: c.config.Bandwidth.IngressBurst,
+			EgressRate:   c.config.Bandwidth.EgressRate,
+			EgressBurst:  c.config.Bandwidth.EgressBurst,
+		}
+	}
+
 	// Cap add and cap drop.
 	// We need a default set of capabilities to compare against.
 	// The OCI generate package has one, and is commonly used, so we'll
@@ -1310,14 +1497,7 @@ func (c *Container) generateInspectContainerHostConfig(ctrSpec *spec.Spec, named
 
 	// CGroup parent
 	// Need to check if it's the default, and not print if so.
-	defaultCgroupParent := ""
-	switch c.runtime.config.CgroupManager {
-	case CgroupfsCgroupsManager:
-		defaultCgroupParent = CgroupfsDefaultCgroupParent
-	case SystemdCgroupsManager:
-		defaultCgroupParent = SystemdDefaultCgroupParent
-	}
-	if c.config.CgroupParent != defaultCgroupParent {
+	if c.config.CgroupParent != c.runtime.getCgroupManager().defaultParent() {
 		hostConfig.CgroupParent = c.config.CgroupParent
 	}
 
@@ -1373,7 +1553,11 @@ func (c *Container) generateInspectContainerHostConfig(ctrSpec *spec.Spec, named
 
 	// User namespace mode
 	usernsMode := ""
-	if c.config.UserNsCtr != "" {
+	if c.config.KeepID != nil {
+		usernsMode = "keep-id"
+	} else if c.config.AutoUserNS {
+		usernsMode = "auto"
+	} else if c.config.UserNsCtr != "" {
 		usernsMode = fmt.Sprintf("container:%s", c.config.UserNsCtr)
 	} else {
 		// Locate the spec's user namespace.
@@ -1438,3 +1622,34 @@ func (c *Container) generateInspectContainerHostConfig(ctrSpec *spec.Spec, named
 
 	return hostConfig, nil
 }
+
+// inspectHooks returns the hooks of a container by stage, from the hooks of its
+// generated spec and the hooks of the stages executed by libpod. Returns nil if
+// the container has no hooks.
+func inspectHooks(specHooks *spec.Hooks, extensionStageHooks map[string][]spec.Hook) map[string][]InspectHook {
+	hooks := make(map[string][]InspectHook)
+	addHooks := func(stage string, stageHooks []spec.Hook) {
+		for _, hook := range stageHooks {
+			hooks[stage] = append(hooks[stage], InspectHook{
+				Path:    hook.Path,
+				Args:    hook.Args,
+				Env:     hook.Env,
+				Timeout: hook.Timeout,
+			})
+		}
+	}
+
+	if specHooks != nil {
+		addHooks("prestart", specHooks.Prestart)
+		addHooks("poststart", specHooks.Poststart)
+		addHooks("poststop", specHooks.Poststop)
+	}
+	for stage, stageHooks := range extensionStageHooks {
+		addHooks(stage, stageHooks)
+	}
+
+	if len(hooks) == 0 {
+		return nil
+	}
+	return hooks
+}
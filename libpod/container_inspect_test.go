@@ -0,0 +1,41 @@
+package libpod
+
+import (
+	"testing"
+
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectHooks(t *testing.T) {
+	timeout := 5
+	specHooks := &spec.Hooks{
+		Prestart: []spec.Hook{
+			{Path: "/usr/libexec/oci/hooks.d/oci-systemd-hook", Args: []string{"oci-systemd-hook", "prestart"}},
+		},
+		Poststop: []spec.Hook{
+			{Path: "/usr/bin/true", Timeout: &timeout},
+		},
+	}
+	extensionStageHooks := map[string][]spec.Hook{
+		"precreate": {
+			{Path: "/usr/bin/env", Env: []string{"FOO=bar"}},
+		},
+	}
+
+	hooks := inspectHooks(specHooks, extensionStageHooks)
+	assert.Equal(t, map[string][]InspectHook{
+		"prestart": {
+			{Path: "/usr/libexec/oci/hooks.d/oci-systemd-hook", Args: []string{"oci-systemd-hook", "prestart"}},
+		},
+		"poststop": {
+			{Path: "/usr/bin/true", Timeout: &timeout},
+		},
+		"precreate": {
+			{Path: "/usr/bin/env", Env: []string{"FOO=bar"}},
+		},
+	}, hooks)
+
+	assert.Nil(t, inspectHooks(nil, nil))
+	assert.Nil(t, inspectHooks(&spec.Hooks{}, nil))
+}
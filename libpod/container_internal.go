@@ -21,18 +21,26 @@ import (
 	"github.com/containers/storage"
 	"github.com/containers/storage/pkg/archive"
 	"github.com/containers/storage/pkg/mount"
+	"github.com/containers/storage/pkg/stringid"
+	securejoin "github.com/cyphar/filepath-securejoin"
 	spec "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/opencontainers/runtime-tools/generate"
 	"github.com/opencontainers/selinux/go-selinux/label"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
 )
 
 const (
 	// name of the directory holding the artifacts
 	artifactsDir      = "artifacts"
 	execDirPermission = 0755
+
+	// exitCodeTTL is how long the exit codes of containers are kept in the
+	// state after they are recorded, whether or not the containers still
+	// exist.
+	exitCodeTTL = 5 * time.Minute
 )
 
 // rootFsSize gets the size of the container's root filesystem
@@ -128,14 +136,42 @@ func (c *Container) CheckpointPath() string {
 	return filepath.Join(c.bundlePath(), "checkpoint")
 }
 
+// ConmonDebugLogPath returns the path to the file conmon's own output is
+// captured to, or an empty string if the container was not created with
+// conmon's debug log enabled
+func (c *Container) ConmonDebugLogPath() string {
+	if !c.config.ConmonDebugLog {
+		return ""
+	}
+	return filepath.Join(c.bundlePath(), "conmon-debug.log")
+}
+
+// exitsDir returns the directory conmon writes the container's exit files to.
+// It is in the temporary files directory of the container's namespace.
+func (c *Container) exitsDir() string {
+	if tmpDir := c.runtime.namespaceTmpDir(c.config.Namespace); tmpDir != "" {
+		return filepath.Join(tmpDir, "exits")
+	}
+	return filepath.Join(c.runtime.config.TmpDir, "exits")
+}
+
+// socketsDir returns the directory holding the container's attach sockets.
+// It is in the temporary files directory of the container's namespace.
+func (c *Container) socketsDir() string {
+	if tmpDir := c.runtime.namespaceTmpDir(c.config.Namespace); tmpDir != "" {
+		return filepath.Join(tmpDir, "socket")
+	}
+	return filepath.Join(c.runtime.config.TmpDir, "socket")
+}
+
 // AttachSocketPath retrieves the path of the container's attach socket
 func (c *Container) AttachSocketPath() string {
-	return filepath.Join(c.ociRuntime.socketsDir, c.ID(), "attach")
+	return filepath.Join(c.socketsDir(), c.ID(), "attach")
 }
 
 // exitFilePath gets the path to the container's exit file
 func (c *Container) exitFilePath() string {
-	return filepath.Join(c.ociRuntime.exitsDir, c.ID())
+	return filepath.Join(c.exitsDir(), c.ID())
 }
 
 // create a bundle path and associated files for an exec session
@@ -160,6 +196,62 @@ func (c *Container) createExecBundle(sessionID string) (err error) {
 	return
 }
 
+// newExecSessionID generates an ID for a new exec session that does not
+// conflict with the existing sessions of the container
+func (c *Container) newExecSessionID() string {
+	for {
+		sessionID := stringid.GenerateNonCryptoID()
+		if _, ok := c.state.ExecSessions[sessionID]; !ok {
+			return sessionID
+		}
+	}
+}
+
+// removeExecSession removes an exec session from the container's state and
+// from the registry of exec sessions. Errors are logged, not returned, as the
+// session has already exited.
+func (c *Container) removeExecSession(sessionID string) {
+	delete(c.state.ExecSessions, sessionID)
+	if err := c.save(); err != nil {
+		logrus.Errorf("Error removing exec session %s from container %s state: %v", sessionID, c.ID(), err)
+	}
+	if err := c.runtime.state.RemoveExecSession(sessionID); err != nil && errors.Cause(err) != define.ErrNoSuchExecSession {
+		logrus.Errorf("Error removing registration of exec session %s of container %s: %v", sessionID, c.ID(), err)
+	}
+}
+
+// execSessionExited returns whether the process of an exec session has exited
+func (c *Container) execSessionExited(session *ExecSession) bool {
+	if _, err := os.Stat(filepath.Join(c.execExitFileDir(session.ID), c.ID())); err == nil {
+		return true
+	}
+	return session.PID <= 0 || unix.Kill(session.PID, 0) == unix.ESRCH
+}
+
+// reapExecSessions removes the detached exec sessions whose processes have
+// exited, and returns their exit codes by session ID
+func (c *Container) reapExecSessions() map[string]int {
+	exitCodes := make(map[string]int)
+	for id, session := range c.state.ExecSessions {
+		if !session.Detached || !c.execSessionExited(session) {
+			continue
+		}
+
+		exitCode, err := c.readExecExitCode(id)
+		if err != nil {
+			logrus.Errorf("Error reading exit code of exec session %s of container %s: %v", id, c.ID(), err)
+			exitCode = -1
+		}
+		exitCodes[id] = exitCode
+
+		if err := c.cleanupExecBundle(id); err != nil {
+			logrus.Errorf("Error removing exec session %s bundle path for container %s: %v", id, c.ID(), err)
+		}
+		c.removeExecSession(id)
+	}
+	return exitCodes
+}
+
 // cleanup an exec session after its done
 func (c *Container) cleanupExecBundle(sessionID string) error {
 	return os.RemoveAll(c.execBundlePath(sessionID))
@@ -175,6 +267,11 @@ func (c *Container) execPidPath(sessionID string) string {
 	return filepath.Join(c.execBundlePath(sessionID), "exec_pid")
 }
 
+// Get the conmon PID file path for a container's detached exec session
+func (c *Container) execConmonPidPath(sessionID string) string {
+	return filepath.Join(c.execBundlePath(sessionID), "conmon.pid")
+}
+
 // the log path for an exec session
 func (c *Container) execLogPath(sessionID string) string {
 	return filepath.Join(c.execBundlePath(sessionID), "exec_log")
@@ -182,7 +279,7 @@ func (c *Container) execLogPath(sessionID string) string {
 
 // the socket conmon creates for an exec session
 func (c *Container) execAttachSocketPath(sessionID string) string {
-	return filepath.Join(c.ociRuntime.socketsDir, sessionID, "attach")
+	return filepath.Join(c.socketsDir(), sessionID, "attach")
 }
 
 // execExitFileDir gets the path to the container's exit file
@@ -192,7 +289,7 @@ func (c *Container) execExitFileDir(sessionID string) string {
 
 // execOCILog returns the file path for the exec sessions oci log
 func (c *Container) execOCILog(sessionID string) string {
-	if !c.ociRuntime.supportsJSON {
+	if !c.ociRuntime.SupportsJSONErrors() {
 		return ""
 	}
 	return filepath.Join(c.execBundlePath(sessionID), "oci-log")
@@ -234,7 +331,9 @@ func (c *Container) waitForExitFileAndSync() error {
 		// Reset our state
 		c.state.ExitCode = -1
 		c.state.FinishedTime = time.Now()
-		c.state.State = define.ContainerStateStopped
+		if err2 := c.setState(define.ContainerStateStopped); err2 != nil {
+			logrus.Errorf("Error resetting container %s state: %v", c.ID(), err2)
+		}
 
 		if err2 := c.save(); err2 != nil {
 			logrus.Errorf("Error saving container %s state: %v", c.ID(), err2)
@@ -243,7 +342,7 @@ func (c *Container) waitForExitFileAndSync() error {
 		return err
 	}
 
-	if err := c.ociRuntime.updateContainerStatus(c, false); err != nil {
+	if err := c.ociRuntime.UpdateContainerStatus(c, false); err != nil {
 		return err
 	}
 
@@ -273,12 +372,33 @@ func (c *Container) handleExitFile(exitFile string, fi os.FileInfo) error {
 
 	c.state.Exited = true
 
+	report, err := c.collectExitReport()
+	if err != nil {
+		logrus.Debugf("Unable to collect exit report of container %s: %v", c.ID(), err)
+	}
+	c.state.ExitReport = report
+
+	// Keep the exit code apart from the container, so it can be retrieved
+	// even if the container is removed before its exit is waited for
+	c.recordExitCode()
+
 	// Write an event for the container's death
 	c.newContainerExitedEvent(c.state.ExitCode)
 
 	return nil
 }
 
+// recordExitCode records the exit code of the container in the state, and
+// prunes the exit codes recorded long enough ago
+func (c *Container) recordExitCode() {
+	if err := c.runtime.state.AddContainerExitCode(c.ID(), c.state.ExitCode); err != nil {
+		logrus.Errorf("Error recording exit code of container %s: %v", c.ID(), err)
+	}
+	if err := c.runtime.state.PruneContainerExitCodes(time.Now().Add(-exitCodeTTL)); err != nil {
+		logrus.Errorf("Error pruning recorded exit codes of containers: %v", err)
+	}
+}
+
 // Handle container restart policy.
 // This is called when a container has exited, and was not explicitly stopped by
 // an API call to stop the container or pod it is in.
@@ -310,6 +430,16 @@ func (c *Container) handleRestartPolicy(ctx context.Context) (restarted bool, er
 		}
 	}
 
+	// Back off between restarts, so a container that keeps exiting is not
+	// restarted in a tight loop
+	if err := c.waitRestartBackoff(ctx); err != nil {
+		return false, err
+	}
+	// The container may have been started or stopped while we waited
+	if !c.state.RestartPolicyMatch || c.state.StoppedByUser {
+		return false, nil
+	}
+
 	logrus.Debugf("Restarting container %s due to restart policy %s", c.ID(), c.config.RestartPolicy)
 
 	// Need to check if dependencies are alive.
@@ -363,6 +493,46 @@ func (c *Container) handleRestartPolicy(ctx context.Context) (restarted bool, er
 	return true, nil
 }
 
+// waitRestartBackoff waits before the container is restarted by its restart
+// policy. The first restart waits restartBackoffInitial, and each consecutive
+// restart waits twice as long as the previous one, up to restartBackoffMax. The
+// delay is reset once the container runs for restartBackoffReset before
+// exiting.
+// The container is unlocked while waiting, and synced again afterwards.
+func (c *Container) waitRestartBackoff(ctx context.Context) error {
+	delay := restartBackoffInitial
+	if c.state.RestartBackoff > 0 && c.state.FinishedTime.Sub(c.state.StartedTime) < restartBackoffReset {
+		delay = c.state.RestartBackoff * 2
+		if delay > restartBackoffMax {
+			delay = restartBackoffMax
+		}
+	}
+	c.state.RestartBackoff = delay
+	if err := c.save(); err != nil {
+		return err
+	}
+
+	logrus.Debugf("Waiting %s before restarting container %s", delay, c.ID())
+
+	if !c.batched {
+		c.lock.Unlock()
+	}
+	var waitErr error
+	select {
+	case <-ctx.Done():
+		waitErr = ctx.Err()
+	case <-time.After(delay):
+	}
+	if !c.batched {
+		c.lock.Lock()
+	}
+	if waitErr != nil {
+		return waitErr
+	}
+
+	return c.syncContainer()
+}
+
 // Sync this container with on-disk state and runtime status
 // Should only be called with container lock held
 // This function should suffice to ensure a container's state is accurate and
@@ -375,10 +545,11 @@ func (c *Container) syncContainer() error {
 	// And then save back to disk
 	if (c.state.State != define.ContainerStateUnknown) &&
 		(c.state.State != define.ContainerStateConfigured) &&
-		(c.state.State != define.ContainerStateExited) {
+		(c.state.State != define.ContainerStateExited) &&
+		(c.state.State != define.ContainerStateRemoving) {
 		oldState := c.state.State
 		// TODO: optionally replace this with a stat for the exit file
-		if err := c.ociRuntime.updateContainerStatus(c, false); err != nil {
+		if err := c.ociRuntime.UpdateContainerStatus(c, false); err != nil {
 			return err
 		}
 		// Only save back to DB if state changed
@@ -535,23 +706,68 @@ func (c *Container) teardownStorage() error {
 	return nil
 }
 
+// summary generates a summary of the container's current configuration and
+// state.
+func (c *Container) summary() *ContainerSummary {
+	return &ContainerSummary{
+		ID:           c.config.ID,
+		Name:         c.config.Name,
+		Pod:          c.config.Pod,
+		Namespace:    c.config.Namespace,
+		ImageID:      c.config.RootfsImageID,
+		ImageName:    c.config.RootfsImageName,
+		State:        c.state.State,
+		IsInfra:      c.config.IsInfra,
+		PortMappings: c.config.PortMappings,
+		CreatedTime:  c.config.CreatedTime,
+	}
+}
+
+// setState moves the container to the given state, calling any state hooks
+// registered with the runtime.
+// An error wrapping ErrCtrStateInvalid is returned, and the state is left
+// unchanged, if the transition is not permitted.
+// It does not save the results - callers must do that themselves.
+func (c *Container) setState(newState define.ContainerStatus) error {
+	oldState := c.state.State
+	if err := define.CheckStateTransition(oldState, newState); err != nil {
+		return errors.Wrapf(err, "container %s", c.ID())
+	}
+
+	c.state.State = newState
+
+	if oldState != newState && c.runtime != nil {
+		for _, hook := range c.runtime.stateHooks {
+			hook(c, oldState, newState)
+		}
+		c.runtime.stateWatch.notify(c.ID())
+	}
+
+	return nil
+}
+
 // Reset resets state fields to default values
 // It is performed before a refresh and clears the state after a reboot
 // It does not save the results - assumes the database will do that for us
 func resetState(state *ContainerState) error {
 	state.PID = 0
 	state.ConmonPID = 0
+	state.Unmonitored = false
 	state.Mountpoint = ""
 	state.Mounted = false
-	if state.State != define.ContainerStateExited {
+	if state.State != define.ContainerStateExited && state.State != define.ContainerStateRemoving {
 		state.State = define.ContainerStateConfigured
 	}
 	state.ExecSessions = make(map[string]*ExecSession)
 	state.NetworkStatus = nil
+	state.NetworkInterfaces = nil
+	state.RootlessNetworkPIDs = nil
+	state.RootlessNetworkIPv6 = false
 	state.BindMounts = make(map[string]string)
 	state.StoppedByUser = false
 	state.RestartPolicyMatch = false
 	state.RestartCount = 0
+	state.RestartBackoff = 0
 
 	return nil
 }
@@ -601,7 +817,7 @@ func (c *Container) refresh() error {
 	if err != nil {
 		return errors.Wrapf(err, "error acquiring lock %d for container %s", c.config.LockID, c.ID())
 	}
-	c.lock = lock
+	c.lock = ctrLocker(lock, c.ID())
 
 	if err := c.save(); err != nil {
 		return errors.Wrapf(err, "error refreshing state for container %s", c.ID())
@@ -635,7 +851,7 @@ func (c *Container) removeConmonFiles() error {
 	}
 
 	// Remove the exit file so we don't leak memory in tmpfs
-	exitFile := filepath.Join(c.ociRuntime.exitsDir, c.ID())
+	exitFile := c.exitFilePath()
 	if _, err := os.Stat(exitFile); err != nil {
 		if !os.IsNotExist(err) {
 			return errors.Wrapf(err, "error running stat on container %s exit file", c.ID())
@@ -650,6 +866,9 @@ func (c *Container) removeConmonFiles() error {
 }
 
 func (c *Container) export(path string) error {
+	if c.state.State == define.ContainerStateRemoving {
+		return errors.Wrapf(define.ErrCtrStateInvalid, "cannot export container %s as it is being removed", c.ID())
+	}
 	mountPoint := c.state.Mountpoint
 	if !c.state.Mounted {
 		containerMount, err := c.runtime.store.Mount(c.ID(), c.config.MountLabel)
@@ -717,6 +936,11 @@ func (c *Container) prepareToStart(ctx context.Context, recursive bool) (err err
 		return errors.Wrapf(define.ErrCtrStateInvalid, "container %s must be in Created or Stopped state to be started", c.ID())
 	}
 
+	// Fail early, rather than with exec format errors from the runtime
+	if err := c.checkArchitecture(ctx); err != nil {
+		return err
+	}
+
 	if !recursive {
 		if err := c.checkDependenciesAndHandleError(ctx); err != nil {
 			return err
@@ -756,13 +980,13 @@ func (c *Container) prepareToStart(ctx context.Context, recursive bool) (err err
 
 // checks dependencies are running and prints a helpful message
 func (c *Container) checkDependenciesAndHandleError(ctx context.Context) error {
-	notRunning, err := c.checkDependenciesRunning()
+	unmet, err := c.checkDependenciesRunning()
 	if err != nil {
 		return errors.Wrapf(err, "error checking dependencies for container %s", c.ID())
 	}
-	if len(notRunning) > 0 {
-		depString := strings.Join(notRunning, ",")
-		return errors.Wrapf(define.ErrCtrStateInvalid, "some dependencies of container %s are not started: %s", c.ID(), depString)
+	if len(unmet) > 0 {
+		depString := strings.Join(unmet, ",")
+		return errors.Wrapf(define.ErrCtrStateInvalid, "some dependencies of container %s are not ready: %s", c.ID(), depString)
 	}
 
 	return nil
@@ -863,11 +1087,8 @@ func (c *Container) getAllDependencies(visited map[string]*Container) error {
 // Returns a []string containing the IDs of dependencies that are not running
 func (c *Container) checkDependenciesRunning() ([]string, error) {
 	deps := c.Dependencies()
-	notRunning := []string{}
+	unmet := []string{}
 
-	// We were not passed a set of dependency containers
-	// Make it ourselves
-	depCtrs := make(map[string]*Container, len(deps))
 	for _, dep := range deps {
 		// Get the dependency container
 		depCtr, err := c.runtime.state.Container(dep)
@@ -875,18 +1096,104 @@ func (c *Container) checkDependenciesRunning() ([]string, error) {
 			return nil, errors.Wrapf(err, "error retrieving dependency %s of container %s from state", dep, c.ID())
 		}
 
-		// Check the status
-		state, err := depCtr.State()
+		condition := c.dependencyCondition(dep)
+		met, err := depCtr.meetsDependencyCondition(condition)
 		if err != nil {
-			return nil, errors.Wrapf(err, "error retrieving state of dependency %s of container %s", dep, c.ID())
+			return nil, errors.Wrapf(err, "error checking dependency %s of container %s", dep, c.ID())
 		}
-		if state != define.ContainerStateRunning {
-			notRunning = append(notRunning, dep)
+		if !met {
+			unmet = append(unmet, fmt.Sprintf("%s (%s)", dep, condition))
 		}
-		depCtrs[dep] = depCtr
 	}
 
-	return notRunning, nil
+	return unmet, nil
+}
+
+// dependencyCondition returns the condition the given dependency must meet
+// before the container can start.
+func (c *Container) dependencyCondition(depID string) define.DependencyCondition {
+	if condition, ok := c.config.DependencyConditions[depID]; ok {
+		return condition
+	}
+	return define.DependencyConditionRunning
+}
+
+// meetsDependencyCondition checks whether the container satisfies the given
+// condition for containers that depend on it.
+// The container does not need to be locked; it will be locked here.
+func (c *Container) meetsDependencyCondition(condition define.DependencyCondition) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.syncContainer(); err != nil {
+		return false, err
+	}
+
+	switch condition {
+	case define.DependencyConditionCreated:
+		return c.state.State != define.ContainerStateConfigured && c.state.State != define.ContainerStateUnknown, nil
+	case define.DependencyConditionRunning, "":
+		return c.state.State == define.ContainerStateRunning, nil
+	case define.DependencyConditionHealthy:
+		if c.state.State != define.ContainerStateRunning {
+			return false, nil
+		}
+		if !c.HasHealthCheck() {
+			return false, errors.Wrapf(define.ErrInvalidArg, "container %s has no healthcheck, cannot wait for it to become healthy", c.ID())
+		}
+		return c.healthCheckResults().Status == HealthCheckHealthy, nil
+	case define.DependencyConditionExitedSuccessfully:
+		if c.state.State != define.ContainerStateStopped && c.state.State != define.ContainerStateExited {
+			return false, nil
+		}
+		return c.state.ExitCode == 0, nil
+	default:
+		return false, errors.Wrapf(define.ErrInvalidArg, "unknown dependency condition %q", condition)
+	}
+}
+
+// waitForDependencyConditions waits for dependencies that have just been
+// started to meet conditions that take time to be satisfied - becoming healthy
+// or exiting. Dependencies that must only be created or running are not waited
+// on. Changes to the dependencies are watched for, for at most the dependency
+// condition timeout of the runtime.
+func (c *Container) waitForDependencyConditions(ctx context.Context) error {
+	if timeout := c.runtime.config.DependencyConditionTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	for dep, condition := range c.config.DependencyConditions {
+		var waitCondition WaitCondition
+		switch condition {
+		case define.DependencyConditionHealthy:
+			waitCondition = WaitConditionHealthy
+		case define.DependencyConditionExitedSuccessfully:
+			waitCondition = WaitConditionStopped
+		default:
+			continue
+		}
+
+		depCtr, err := c.runtime.state.Container(dep)
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving dependency %s of container %s from state", dep, c.ID())
+		}
+
+		exitCode, _, err := depCtr.WaitWithCondition(ctx, waitCondition)
+		if err != nil {
+			if errors.Cause(err) == context.DeadlineExceeded {
+				return errors.Wrapf(define.ErrCtrStateInvalid, "timed out waiting for dependency %s of container %s to become %s", dep, c.ID(), condition)
+			}
+			return err
+		}
+		// Waiting longer will not change the exit code
+		if condition == define.DependencyConditionExitedSuccessfully && exitCode != 0 {
+			return errors.Wrapf(define.ErrCtrStateInvalid, "dependency %s of container %s exited with code %d", dep, c.ID(), exitCode)
+		}
+	}
+
+	return nil
 }
 
 func (c *Container) completeNetworkSetup() error {
@@ -900,7 +1207,7 @@ func (c *Container) completeNetworkSetup() error {
 	if err := c.syncContainer(); err != nil {
 		return err
 	}
-	if c.config.NetMode == "slirp4netns" {
+	if c.config.NetMode.IsSlirp4netns() || c.config.NetMode.IsPasta() {
 		return c.runtime.setupRootlessNetNS(c)
 	}
 	return c.runtime.setupNetNS(c)
@@ -924,7 +1231,7 @@ func (c *Container) init(ctx context.Context, retainRetries bool) error {
 	}
 
 	// With the spec complete, do an OCI create
-	if err := c.ociRuntime.createContainer(c, nil); err != nil {
+	if err := c.ociRuntime.CreateContainer(c, nil); err != nil {
 		return err
 	}
 
@@ -932,22 +1239,27 @@ func (c *Container) init(ctx context.Context, retainRetries bool) error {
 
 	c.state.ExitCode = 0
 	c.state.Exited = false
-	c.state.State = define.ContainerStateCreated
+	if err := c.setState(define.ContainerStateCreated); err != nil {
+		return err
+	}
 	c.state.StoppedByUser = false
 	c.state.RestartPolicyMatch = false
+	// The checkpoint images no longer match the container's processes
+	c.state.Checkpointed = false
 
 	if !retainRetries {
 		c.state.RestartCount = 0
+		c.state.RestartBackoff = 0
 	}
 
-	if err := c.save(); err != nil {
-		return err
-	}
 	if c.config.HealthCheckConfig != nil {
 		if err := c.createTimer(); err != nil {
-			logrus.Error(err)
+			return err
 		}
 	}
+	if err := c.save(); err != nil {
+		return err
+	}
 
 	defer c.newContainerEvent(events.Init)
 	return c.completeNetworkSetup()
@@ -980,9 +1292,13 @@ func (c *Container) cleanupRuntime(ctx context.Context) error {
 	// from the runtime.
 	// If we were Created, we are now Configured.
 	if c.state.State == define.ContainerStateStopped {
-		c.state.State = define.ContainerStateExited
+		if err := c.setState(define.ContainerStateExited); err != nil {
+			return err
+		}
 	} else if c.state.State == define.ContainerStateCreated {
-		c.state.State = define.ContainerStateConfigured
+		if err := c.setState(define.ContainerStateConfigured); err != nil {
+			return err
+		}
 	}
 
 	if c.valid {
@@ -1071,24 +1387,61 @@ func (c *Container) start() error {
 		logrus.Debugf("Starting container %s with command %v", c.ID(), c.config.Spec.Process.Args)
 	}
 
-	if err := c.ociRuntime.startContainer(c); err != nil {
+	if err := c.ociRuntime.StartContainer(c); err != nil {
 		return err
 	}
 	logrus.Debugf("Started container %s", c.ID())
 
-	c.state.State = define.ContainerStateRunning
+	// Queue auto-remove containers for removal, so they are removed even
+	// if the process meant to remove them dies. Containers that failed to
+	// start are kept for inspection.
+	if c.autoRemove() {
+		if err := c.runtime.state.AddPendingRemoval(c); err != nil {
+			return errors.Wrapf(err, "error queueing container %s for removal", c.ID())
+		}
+	}
+
+	if err := c.setState(define.ContainerStateRunning); err != nil {
+		return err
+	}
+	c.state.MemoryPressureFrozen = false
+	c.state.StopStep = 0
+	c.state.StopStepSignal = 0
+	c.state.StopStepTime = time.Time{}
+	c.state.ExitReport = nil
 
+	// The container runs even if its healthchecks cannot be scheduled, so
+	// its state is saved before the error is returned
+	var timerErr error
 	if c.config.HealthCheckConfig != nil {
-		if err := c.updateHealthStatus(HealthCheckStarting); err != nil {
-			logrus.Error(err)
-		}
-		if err := c.startTimer(); err != nil {
-			logrus.Error(err)
-		}
+		c.resetHealthStatus()
+		timerErr = c.startTimer()
 	}
 
+	c.notifyReady()
+
 	defer c.newContainerEvent(events.Start)
 
+	if err := c.save(); err != nil {
+		if timerErr != nil {
+			logrus.Errorf("%v", timerErr)
+		}
+		return err
+	}
+	return timerErr
+}
+
+// stopCheckpointed stops a container whose checkpoint left it running, as a
+// checkpoint that does not would have, so it can be restored from the
+// checkpoint.
+func (c *Container) stopCheckpointed(ctx context.Context) error {
+	if err := c.stop(0); err != nil {
+		return err
+	}
+	if err := c.cleanup(ctx); err != nil {
+		return err
+	}
+	c.state.Checkpointed = true
 	return c.save()
 }
 
@@ -1096,10 +1449,24 @@ func (c *Container) start() error {
 func (c *Container) stop(timeout uint) error {
 	logrus.Debugf("Stopping ctr %s (timeout %d)", c.ID(), timeout)
 
-	if err := c.ociRuntime.stopContainer(c, timeout); err != nil {
+	if err := c.ociRuntime.StopContainer(c, timeout); err != nil {
 		return err
 	}
 
+	// No exit file will be written for a container without a conmon, so
+	// take its status from the OCI runtime instead
+	if c.state.Unmonitored {
+		c.state.StoppedByUser = true
+		if err := c.ociRuntime.UpdateContainerStatus(c, true); err != nil {
+			return err
+		}
+		if err := c.save(); err != nil {
+			return errors.Wrapf(err, "error saving container %s state after stopping", c.ID())
+		}
+		c.newContainerEvent(events.Stop)
+		return nil
+	}
+
 	c.state.PID = 0
 	c.state.ConmonPID = 0
 	c.state.StoppedByUser = true
@@ -1119,26 +1486,30 @@ func (c *Container) stop(timeout uint) error {
 
 // Internal, non-locking function to pause a container
 func (c *Container) pause() error {
-	if err := c.ociRuntime.pauseContainer(c); err != nil {
+	if err := c.ociRuntime.PauseContainer(c); err != nil {
 		return err
 	}
 
 	logrus.Debugf("Paused container %s", c.ID())
 
-	c.state.State = define.ContainerStatePaused
+	if err := c.setState(define.ContainerStatePaused); err != nil {
+		return err
+	}
 
 	return c.save()
 }
 
 // Internal, non-locking function to unpause a container
 func (c *Container) unpause() error {
-	if err := c.ociRuntime.unpauseContainer(c); err != nil {
+	if err := c.ociRuntime.UnpauseContainer(c); err != nil {
 		return err
 	}
 
 	logrus.Debugf("Unpaused container %s", c.ID())
 
-	c.state.State = define.ContainerStateRunning
+	if err := c.setState(define.ContainerStateRunning); err != nil {
+		return err
+	}
 
 	return c.save()
 }
@@ -1236,9 +1607,61 @@ func (c *Container) mountStorage() (string, error) {
 		}
 	}
 
+	if err := c.mountNamedVolumes(mountPoint); err != nil {
+		return "", err
+	}
+
 	return mountPoint, nil
 }
 
+// mountNamedVolumes mounts the container's named volumes that are managed by a
+// volume plugin, and copies up the contents of the container's root filesystem,
+// mounted at rootfs, into the volumes mounted for the first time. If any cannot
+// be mounted, those already mounted are unmounted again.
+func (c *Container) mountNamedVolumes(rootfs string) (err error) {
+	mounted := make([]*Volume, 0, len(c.config.NamedVolumes))
+	defer func() {
+		if err != nil {
+			for _, vol := range mounted {
+				if err2 := vol.unmount(c.ID()); err2 != nil {
+					logrus.Errorf("Error unmounting volume %s of container %s: %v", vol.Name(), c.ID(), err2)
+				}
+			}
+		}
+	}()
+
+	for _, namedVol := range c.config.NamedVolumes {
+		vol, err := c.runtime.state.Volume(namedVol.Name)
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving volume %s to mount into container %s", namedVol.Name, c.ID())
+		}
+		if err := vol.mount(c.ID()); err != nil {
+			return err
+		}
+		mounted = append(mounted, vol)
+		if err := c.copyUpToVolume(vol, rootfs, namedVol.Dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmountNamedVolumes unmounts the container's named volumes that are managed
+// by a volume plugin. Failures are only logged, so that a missing volume or
+// plugin does not prevent the container from being cleaned up.
+func (c *Container) unmountNamedVolumes() {
+	for _, namedVol := range c.config.NamedVolumes {
+		vol, err := c.runtime.state.Volume(namedVol.Name)
+		if err != nil {
+			logrus.Errorf("Error retrieving volume %s to unmount from container %s: %v", namedVol.Name, c.ID(), err)
+			continue
+		}
+		if err := vol.unmount(c.ID()); err != nil {
+			logrus.Errorf("Error unmounting volume %s of container %s: %v", vol.Name(), c.ID(), err)
+		}
+	}
+}
+
 // cleanupStorage unmounts and cleans up the container's root filesystem
 func (c *Container) cleanupStorage() error {
 	if !c.state.Mounted {
@@ -1253,7 +1676,16 @@ func (c *Container) cleanupStorage() error {
 		}
 	}
 
+	c.unmountNamedVolumes()
+
 	if c.config.Rootfs != "" {
+		// Nothing to unmount, but the next mountStorage must mount
+		// the SHM and named volumes again
+		c.state.Mountpoint = ""
+		c.state.Mounted = false
+		if c.valid {
+			return c.save()
+		}
 		return nil
 	}
 
@@ -1329,7 +1761,7 @@ func (c *Container) delete(ctx context.Context) (err error) {
 	span.SetTag("struct", "container")
 	defer span.Finish()
 
-	if err := c.ociRuntime.deleteContainer(c); err != nil {
+	if err := c.ociRuntime.DeleteContainer(c); err != nil {
 		return errors.Wrapf(err, "error removing container %s from runtime", c.ID())
 	}
 
@@ -1517,6 +1949,9 @@ func (c *Container) setupOCIHooks(ctx context.Context, config *spec.Spec) (exten
 
 // mount mounts the container's root filesystem
 func (c *Container) mount() (string, error) {
+	if c.state.State == define.ContainerStateRemoving {
+		return "", errors.Wrapf(define.ErrCtrStateInvalid, "cannot mount container %s as it is being removed", c.ID())
+	}
 	mountPoint, err := c.runtime.storageService.MountContainerImage(c.ID())
 	if err != nil {
 		return "", errors.Wrapf(err, "error mounting storage for container %s", c.ID())
@@ -1541,19 +1976,48 @@ func (c *Container) unmount(force bool) error {
 	return nil
 }
 
-// this should be from chrootarchive.
-func (c *Container) copyWithTarFromImage(src, dest string) error {
-	mountpoint, err := c.mount()
-	if err != nil {
+// copyUpToVolume populates a named volume mounted into the container at dest
+// with the contents of the container's root filesystem, mounted at rootfs, at
+// that path. As with Docker, this is only done the first time the volume is
+// mounted into a container, and only if the volume is empty.
+// The volume must already be mounted for the container, and not be locked.
+func (c *Container) copyUpToVolume(vol *Volume, rootfs, dest string) error {
+	vol.lock.Lock()
+	defer vol.lock.Unlock()
+
+	if err := vol.update(); err != nil {
 		return err
 	}
-	a := archive.NewDefaultArchiver()
-	source := filepath.Join(mountpoint, src)
+	if !vol.state.NeedsCopyUp {
+		return nil
+	}
 
-	if err = c.copyOwnerAndPerms(source, dest); err != nil {
-		return err
+	volPath := vol.config.MountPoint
+	if vol.UsesVolumeDriver() {
+		volPath = vol.state.MountPoint
+	}
+	source, err := securejoin.SecureJoin(rootfs, dest)
+	if err != nil {
+		return errors.Wrapf(err, "error resolving %s in container %s", dest, c.ID())
 	}
-	return a.CopyWithTar(source, dest)
+	empty, err := isDirEmpty(volPath)
+	if err != nil {
+		return errors.Wrapf(err, "error checking contents of volume %s", vol.Name())
+	}
+	if _, err := os.Stat(source); err == nil && empty {
+		logrus.Debugf("Copying up contents of %s in container %s to volume %s", dest, c.ID(), vol.Name())
+		if err := c.copyOwnerAndPerms(source, volPath); err != nil {
+			return err
+		}
+		if err := archive.NewDefaultArchiver().CopyWithTar(source, volPath); err != nil {
+			return errors.Wrapf(err, "error copying up contents of %s in container %s to volume %s", dest, c.ID(), vol.Name())
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error accessing %s in container %s", dest, c.ID())
+	}
+
+	vol.state.NeedsCopyUp = false
+	return vol.save()
 }
 
 // checkReadyForRemoval checks whether the given container is ready to be
@@ -1645,3 +2109,27 @@ func (c *Container) sortUserVolumes(ctrSpec *spec.Spec) ([]*ContainerNamedVolume
 	}
 	return namedUserVolumes, userMounts
 }
+
+// addHostProxyEnv adds the proxy environment variables of the host to the
+// container's spec. Variables already set in the spec take precedence.
+func (c *Container) addHostProxyEnv() {
+	proxyEnv := hostProxyEnv()
+	if len(proxyEnv) == 0 {
+		return
+	}
+	if c.config.Spec.Process == nil {
+		c.config.Spec.Process = new(spec.Process)
+	}
+
+	existing := make(map[string]bool)
+	for _, env := range c.config.Spec.Process.Env {
+		existing[strings.SplitN(env, "=", 2)[0]] = true
+	}
+	for _, key := range proxyEnvVars {
+		val, ok := proxyEnv[key]
+		if !ok || existing[key] {
+			continue
+		}
+		c.config.Spec.Process.Env = append(c.config.Spec.Process.Env, fmt.Sprintf("%s=%s", key, val))
+	}
+}
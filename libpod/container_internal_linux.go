@@ -9,7 +9,6 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
-	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -87,6 +86,18 @@ func (c *Container) prepare() (err error) {
 				c.state.NetNS = netNS
 				c.state.NetworkStatus = networkStatus
 			}
+		} else if c.config.NetNsPath != "" && c.state.NetNS == nil {
+			// Join the network namespace given by path. It is
+			// opened now so a missing namespace is reported at
+			// start rather than by the OCI runtime.
+			netNS, createNetNSErr = joinNetNS(c.config.NetNsPath)
+
+			tmpStateLock.Lock()
+			defer tmpStateLock.Unlock()
+
+			if createNetNSErr == nil {
+				c.state.NetNS = netNS
+			}
 		}
 	}()
 	// Mount storage if not mounted
@@ -148,6 +159,15 @@ func (c *Container) cleanupNetwork() error {
 	if netDisabled {
 		return nil
 	}
+	// The network namespace of rootless containers is not managed by
+	// libpod, only the processes of their network stack need stopping
+	if len(c.state.RootlessNetworkPIDs) > 0 {
+		c.runtime.teardownRootlessNetNS(c)
+		if c.valid {
+			return c.save()
+		}
+		return nil
+	}
 	if c.state.NetNS == nil {
 		logrus.Debugf("Network is already cleaned up, skipping...")
 		return nil
@@ -160,6 +180,7 @@ func (c *Container) cleanupNetwork() error {
 
 	c.state.NetNS = nil
 	c.state.NetworkStatus = nil
+	c.state.NetworkInterfaces = nil
 
 	if c.valid {
 		return c.save()
@@ -182,6 +203,40 @@ func (c *Container) generateSpec(ctx context.Context) (*spec.Spec, error) {
 
 	g := generate.NewFromSpec(c.config.Spec)
 
+	// Give the keep-id user its home and working directories, and point
+	// HOME at the former unless the user set it explicitly
+	if c.config.KeepID != nil {
+		if err := c.prepareKeepIDDirs(); err != nil {
+			return nil, err
+		}
+		hasHome := false
+		for _, env := range g.Config.Process.Env {
+			if strings.HasPrefix(env, "HOME=") {
+				hasHome = true
+				break
+			}
+		}
+		if !hasHome {
+			g.AddProcessEnv("HOME", c.config.KeepID.HomeDir)
+		}
+	}
+
+	// The mappings of an automatic user namespace are only known once the
+	// container is created
+	if c.config.AutoUserNS {
+		if err := g.AddOrReplaceLinuxNamespace(string(spec.UserNamespace), ""); err != nil {
+			return nil, err
+		}
+		g.ClearLinuxUIDMappings()
+		for _, uidmap := range c.config.IDMappings.UIDMap {
+			g.AddLinuxUIDMapping(uint32(uidmap.HostID), uint32(uidmap.ContainerID), uint32(uidmap.Size))
+		}
+		g.ClearLinuxGIDMappings()
+		for _, gidmap := range c.config.IDMappings.GIDMap {
+			g.AddLinuxGIDMapping(uint32(gidmap.HostID), uint32(gidmap.ContainerID), uint32(gidmap.Size))
+		}
+	}
+
 	// If network namespace was requested, add it now
 	if c.config.CreateNetNS {
 		if c.config.PostConfigureNetNS {
@@ -193,6 +248,10 @@ func (c *Container) generateSpec(ctx context.Context) (*spec.Spec, error) {
 				return nil, err
 			}
 		}
+	} else if c.config.NetNsPath != "" {
+		if err := g.AddOrReplaceLinuxNamespace(string(spec.NetworkNamespace), c.config.NetNsPath); err != nil {
+			return nil, err
+		}
 	}
 
 	// Apply AppArmor checks and load the default profile if needed.
@@ -266,6 +325,15 @@ func (c *Container) generateSpec(ctx context.Context) (*spec.Spec, error) {
 		}
 	}
 
+	// Add the tmpfs mounts of a read-only root filesystem
+	tmpfsMounts, err := c.readOnlyTmpfsMounts(g.Mounts())
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range tmpfsMounts {
+		g.AddMount(m)
+	}
+
 	if c.config.User != "" {
 		// User and Group must go together
 		g.SetProcessUID(uint32(execUser.Uid))
@@ -294,50 +362,14 @@ func (c *Container) generateSpec(ctx context.Context) (*spec.Spec, error) {
 	}
 
 	// Add shared namespaces from other containers
-	if c.config.IPCNsCtr != "" {
-		if err := c.addNamespaceContainer(&g, IPCNS, c.config.IPCNsCtr, spec.IPCNamespace); err != nil {
-			return nil, err
-		}
-	}
-	if c.config.MountNsCtr != "" {
-		if err := c.addNamespaceContainer(&g, MountNS, c.config.MountNsCtr, spec.MountNamespace); err != nil {
-			return nil, err
-		}
-	}
-	if c.config.NetNsCtr != "" {
-		if err := c.addNamespaceContainer(&g, NetNS, c.config.NetNsCtr, spec.NetworkNamespace); err != nil {
-			return nil, err
-		}
-	}
-	if c.config.PIDNsCtr != "" {
-		if err := c.addNamespaceContainer(&g, PIDNS, c.config.PIDNsCtr, spec.PIDNamespace); err != nil {
-			return nil, err
-		}
-	}
-	if c.config.UserNsCtr != "" {
-		if err := c.addNamespaceContainer(&g, UserNS, c.config.UserNsCtr, spec.UserNamespace); err != nil {
-			return nil, err
-		}
-		if len(g.Config.Linux.UIDMappings) == 0 {
-			// runc complains if no mapping is specified, even if we join another ns.  So provide a dummy mapping
-			g.AddLinuxUIDMapping(uint32(0), uint32(0), uint32(1))
-			g.AddLinuxGIDMapping(uint32(0), uint32(0), uint32(1))
-		}
-	}
-	if c.config.UTSNsCtr != "" {
-		if err := c.addNamespaceContainer(&g, UTSNS, c.config.UTSNsCtr, spec.UTSNamespace); err != nil {
-			return nil, err
-		}
-	}
-	if c.config.CgroupNsCtr != "" {
-		if err := c.addNamespaceContainer(&g, CgroupNS, c.config.CgroupNsCtr, spec.CgroupNamespace); err != nil {
-			return nil, err
-		}
+	if err := c.addSharedNamespaces(&g); err != nil {
+		return nil, err
 	}
 
 	g.SetRootPath(c.state.Mountpoint)
 	g.AddAnnotation(crioAnnotations.Created, c.config.CreatedTime.Format(time.RFC3339Nano))
 	g.AddAnnotation("org.opencontainers.image.stopSignal", fmt.Sprintf("%d", c.config.StopSignal))
+	c.addVMAnnotations(&g)
 
 	for _, i := range c.config.Spec.Linux.Namespaces {
 		if i.Type == spec.UTSNamespace {
@@ -360,24 +392,14 @@ func (c *Container) generateSpec(ctx context.Context) (*spec.Spec, error) {
 		g.AddProcessEnv("container", "libpod")
 	}
 
-	unified, err := cgroups.IsCgroup2UnifiedMode()
+	noCgroups, err := noRootlessCgroups()
 	if err != nil {
 		return nil, err
 	}
-	if rootless.IsRootless() && !unified {
+	if noCgroups {
 		g.SetLinuxCgroupsPath("")
-	} else if c.runtime.config.CgroupManager == SystemdCgroupsManager {
-		// When runc is set to use Systemd as a cgroup manager, it
-		// expects cgroups to be passed as follows:
-		// slice:prefix:name
-		systemdCgroups := fmt.Sprintf("%s:libpod:%s", path.Base(c.config.CgroupParent), c.ID())
-		logrus.Debugf("Setting CGroups for container %s to %s", c.ID(), systemdCgroups)
-		g.SetLinuxCgroupsPath(systemdCgroups)
 	} else {
-		cgroupPath, err := c.CGroupPath()
-		if err != nil {
-			return nil, err
-		}
+		cgroupPath := c.runtime.getCgroupManager().specCgroupsPath(c)
 		logrus.Debugf("Setting CGroup path for container %s to %s", c.ID(), cgroupPath)
 		g.SetLinuxCgroupsPath(cgroupPath)
 	}
@@ -495,6 +517,53 @@ func (c *Container) setupSystemd(mounts []spec.Mount, g generate.Generator) erro
 	return nil
 }
 
+// addSharedNamespaces adds the namespaces the container shares with other
+// containers to the spec, joining the namespaces of their current processes
+func (c *Container) addSharedNamespaces(g *generate.Generator) error {
+	if c.config.IPCNsCtr != "" {
+		if err := c.addNamespaceContainer(g, IPCNS, c.config.IPCNsCtr, spec.IPCNamespace); err != nil {
+			return err
+		}
+	}
+	if c.config.MountNsCtr != "" {
+		if err := c.addNamespaceContainer(g, MountNS, c.config.MountNsCtr, spec.MountNamespace); err != nil {
+			return err
+		}
+	}
+	if c.config.NetNsCtr != "" {
+		if err := c.addNamespaceContainer(g, NetNS, c.config.NetNsCtr, spec.NetworkNamespace); err != nil {
+			return err
+		}
+	}
+	if c.config.PIDNsCtr != "" {
+		if err := c.addNamespaceContainer(g, PIDNS, c.config.PIDNsCtr, spec.PIDNamespace); err != nil {
+			return err
+		}
+	}
+	if c.config.UserNsCtr != "" {
+		if err := c.addNamespaceContainer(g, UserNS, c.config.UserNsCtr, spec.UserNamespace); err != nil {
+			return err
+		}
+		if len(g.Config.Linux.UIDMappings) == 0 {
+			// runc complains if no mapping is specified, even if we join another ns.  So provide a dummy mapping
+			g.AddLinuxUIDMapping(uint32(0), uint32(0), uint32(1))
+			g.AddLinuxGIDMapping(uint32(0), uint32(0), uint32(1))
+		}
+	}
+	if c.config.UTSNsCtr != "" {
+		if err := c.addNamespaceContainer(g, UTSNS, c.config.UTSNsCtr, spec.UTSNamespace); err != nil {
+			return err
+		}
+	}
+	if c.config.CgroupNsCtr != "" {
+		if err := c.addNamespaceContainer(g, CgroupNS, c.config.CgroupNsCtr, spec.CgroupNamespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Add an existing container's namespace to the spec
 func (c *Container) addNamespaceContainer(g *generate.Generator, ns LinuxNS, ctr string, specNS spec.LinuxNamespaceType) error {
 	nsCtr, err := c.runtime.state.Container(ctr)
@@ -515,9 +584,13 @@ func (c *Container) addNamespaceContainer(g *generate.Generator, ns LinuxNS, ctr
 	return nil
 }
 
-func (c *Container) exportCheckpoint(dest string, ignoreRootfs bool) (err error) {
-	if (len(c.config.NamedVolumes) > 0) || (len(c.Dependencies()) > 0) {
-		return errors.Errorf("Cannot export checkpoints of containers with named volumes or dependencies")
+// checkpointVolumesDir is the directory of checkpoint archives holding the
+// contents of the container's named volumes, as one tar archive per volume
+const checkpointVolumesDir = "volumes"
+
+func (c *Container) exportCheckpoint(ctx context.Context, dest string, ignoreRootfs bool) (err error) {
+	if len(c.Dependencies()) > 0 {
+		return errors.Errorf("Cannot export checkpoints of containers with dependencies")
 	}
 	logrus.Debugf("Exporting checkpoint image of container %q to %q", c.ID(), dest)
 
@@ -549,6 +622,17 @@ func (c *Container) exportCheckpoint(dest string, ignoreRootfs bool) (err error)
 		includeFiles = append(includeFiles, "rootfs-diff.tar")
 	}
 
+	// Get the contents of the named volumes included in the checkpoint
+	// archive, so the container can be restored on another host
+	if len(c.config.NamedVolumes) > 0 {
+		volumesDir := filepath.Join(c.bundlePath(), checkpointVolumesDir)
+		defer os.RemoveAll(volumesDir)
+		if err := c.exportCheckpointVolumes(volumesDir); err != nil {
+			return err
+		}
+		includeFiles = append(includeFiles, checkpointVolumesDir)
+	}
+
 	input, err := archive.TarWithOptions(c.bundlePath(), &archive.TarOptions{
 		Compression:      archive.Gzip,
 		IncludeSourceDir: true,
@@ -559,31 +643,141 @@ func (c *Container) exportCheckpoint(dest string, ignoreRootfs bool) (err error)
 		return errors.Wrapf(err, "error reading checkpoint directory %q", c.ID())
 	}
 
-	outFile, err := os.Create(dest)
+	defer input.Close()
+
+	storage, location, err := c.runtime.checkpointStorage(dest)
 	if err != nil {
-		return errors.Wrapf(err, "error creating checkpoint export file %q", dest)
+		return err
+	}
+	if err := storage.Export(ctx, location, input); err != nil {
+		return err
 	}
-	defer outFile.Close()
 
-	if err := os.Chmod(dest, 0600); err != nil {
-		return errors.Wrapf(err, "cannot chmod %q", dest)
+	os.Remove(rootfsDiffPath)
+
+	return nil
+}
+
+// exportCheckpointVolumes writes the contents of the container's named volumes
+// to the given directory
+func (c *Container) exportCheckpointVolumes(volumesDir string) error {
+	if err := os.MkdirAll(volumesDir, 0700); err != nil {
+		return errors.Wrapf(err, "error creating volumes directory %q", volumesDir)
+	}
+	for _, namedVol := range c.config.NamedVolumes {
+		vol, err := c.runtime.state.Volume(namedVol.Name)
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving volume %s of container %s", namedVol.Name, c.ID())
+		}
+		volPath := filepath.Join(volumesDir, vol.Name()+".tar")
+		volFile, err := os.OpenFile(volPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return errors.Wrapf(err, "error creating volume archive %q", volPath)
+		}
+		err = vol.Export(volFile)
+		volFile.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importCheckpointVolumes restores the contents of the container's named
+// volumes from an imported checkpoint archive. Volumes also used by other
+// containers are left as they are, as their contents are not the container's
+// to overwrite.
+func (c *Container) importCheckpointVolumes() error {
+	volumesDir := filepath.Join(c.bundlePath(), checkpointVolumesDir)
+	if _, err := os.Stat(volumesDir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "error accessing volumes directory %q", volumesDir)
+	}
+	defer os.RemoveAll(volumesDir)
+
+	for _, namedVol := range c.config.NamedVolumes {
+		volFile, err := os.Open(filepath.Join(volumesDir, namedVol.Name+".tar"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.Wrapf(err, "error opening archive of volume %s", namedVol.Name)
+		}
+		err = c.importCheckpointVolume(namedVol.Name, volFile)
+		volFile.Close()
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	_, err = io.Copy(outFile, input)
+// importCheckpointVolume extracts the archive read from reader into the named
+// volume with the given name, unless other containers use it
+func (c *Container) importCheckpointVolume(name string, reader io.Reader) error {
+	vol, err := c.runtime.state.Volume(name)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "error retrieving volume %s of container %s", name, c.ID())
+	}
+	users, err := c.runtime.state.VolumeInUse(vol)
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving users of volume %s", name)
+	}
+	for _, user := range users {
+		if user != c.ID() {
+			logrus.Warnf("Not restoring contents of volume %s, which is used by container %s", name, user)
+			return nil
+		}
 	}
 
-	os.Remove(rootfsDiffPath)
+	vol.lock.Lock()
+	defer vol.lock.Unlock()
+	if err := vol.update(); err != nil {
+		return err
+	}
+	return vol.importContents(reader)
+}
 
-	return nil
+// collectExitReport reads the resources used by the container from its cgroup.
+// It must be called after the container exited, but before its cgroup is
+// removed.
+func (c *Container) collectExitReport() (*ContainerExitReport, error) {
+	unified, err := cgroups.IsCgroup2UnifiedMode()
+	if err != nil {
+		return nil, err
+	}
+	if !unified {
+		return nil, nil
+	}
+	cgroupPath, err := c.CGroupPath()
+	if err != nil {
+		return nil, err
+	}
+	cgroup, err := cgroups.Load(cgroupPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading cgroup %s", cgroupPath)
+	}
+	metrics, err := cgroup.Accounting()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading accounting of cgroup %s", cgroupPath)
+	}
+	return &ContainerExitReport{
+		PeakMemory:    metrics.MemoryPeak,
+		CPUTime:       time.Duration(metrics.CPUUsage),
+		CPUUserTime:   time.Duration(metrics.CPUUser),
+		CPUSystemTime: time.Duration(metrics.CPUSystem),
+		PeakPids:      metrics.PidsPeak,
+		OOMKills:      metrics.OOMKills,
+	}, nil
 }
 
 func (c *Container) checkpointRestoreSupported() (err error) {
 	if !criu.CheckForCriu() {
 		return errors.Errorf("Checkpoint/Restore requires at least CRIU %d", criu.MinCriuVersion)
 	}
-	if !c.ociRuntime.featureCheckCheckpointing() {
+	if !hasOCIFeature(c.ociRuntime, OCIFeatureCheckpoint) {
 		return errors.Errorf("Configured runtime does not support checkpoint/restore")
 	}
 	return nil
@@ -619,7 +813,7 @@ func (c *Container) checkpoint(ctx context.Context, options ContainerCheckpointO
 		return err
 	}
 
-	if err := c.ociRuntime.checkpointContainer(c, options); err != nil {
+	if err := c.ociRuntime.CheckpointContainer(c, options); err != nil {
 		return err
 	}
 
@@ -635,15 +829,19 @@ func (c *Container) checkpoint(ctx context.Context, options ContainerCheckpointO
 	}
 
 	if options.TargetFile != "" {
-		if err = c.exportCheckpoint(options.TargetFile, options.IgnoreRootfs); err != nil {
+		if err = c.exportCheckpoint(ctx, options.TargetFile, options.IgnoreRootfs); err != nil {
 			return err
 		}
 	}
 
 	logrus.Debugf("Checkpointed container %s", c.ID())
 
+	c.state.CheckpointedTime = time.Now()
 	if !options.KeepRunning {
-		c.state.State = define.ContainerStateStopped
+		c.state.Checkpointed = true
+		if err := c.setState(define.ContainerStateStopped); err != nil {
+			return err
+		}
 
 		// Cleanup Storage and Network
 		if err := c.cleanup(ctx); err != nil {
@@ -670,10 +868,10 @@ func (c *Container) checkpoint(ctx context.Context, options ContainerCheckpointO
 	return c.save()
 }
 
-func (c *Container) importCheckpoint(input string) (err error) {
-	archiveFile, err := os.Open(input)
+func (c *Container) importCheckpoint(ctx context.Context, input string) (err error) {
+	archiveFile, err := c.runtime.OpenCheckpointArchive(ctx, input)
 	if err != nil {
-		return errors.Wrapf(err, "Failed to open checkpoint archive %s for import", input)
+		return err
 	}
 
 	defer archiveFile.Close()
@@ -690,6 +888,10 @@ func (c *Container) importCheckpoint(input string) (err error) {
 		return errors.Wrapf(err, "Unpacking of checkpoint archive %s failed", input)
 	}
 
+	if err := c.importCheckpointVolumes(); err != nil {
+		return errors.Wrapf(err, "Restoring volumes of checkpoint archive %s failed", input)
+	}
+
 	// Make sure the newly created config.json exists on disk
 	g := generate.Generator{Config: c.config.Spec}
 	if err = c.saveSpec(g.Config); err != nil {
@@ -710,7 +912,7 @@ func (c *Container) restore(ctx context.Context, options ContainerCheckpointOpti
 	}
 
 	if options.TargetFile != "" {
-		if err = c.importCheckpoint(options.TargetFile); err != nil {
+		if err = c.importCheckpoint(ctx, options.TargetFile); err != nil {
 			return err
 		}
 	}
@@ -798,6 +1000,16 @@ func (c *Container) restore(ctx context.Context, options ContainerCheckpointOpti
 		if err := g.AddOrReplaceLinuxNamespace(string(spec.NetworkNamespace), c.state.NetNS.Path()); err != nil {
 			return err
 		}
+	} else if c.config.NetNsPath != "" {
+		if err := g.AddOrReplaceLinuxNamespace(string(spec.NetworkNamespace), c.config.NetNsPath); err != nil {
+			return err
+		}
+	}
+
+	// The processes owning the namespaces shared with other containers,
+	// such as the infra container of a pod, may have been restored too
+	if err := c.addSharedNamespaces(&g); err != nil {
+		return err
 	}
 
 	if err := c.makeBindMounts(); err != nil {
@@ -847,13 +1059,17 @@ func (c *Container) restore(ctx context.Context, options ContainerCheckpointOpti
 		}
 	}
 
-	if err := c.ociRuntime.createContainer(c, &options); err != nil {
+	if err := c.ociRuntime.CreateContainer(c, &options); err != nil {
 		return err
 	}
 
 	logrus.Debugf("Restored container %s", c.ID())
 
-	c.state.State = define.ContainerStateRunning
+	c.state.Checkpointed = false
+	c.state.RestoredTime = time.Now()
+	if err := c.setState(define.ContainerStateRunning); err != nil {
+		return err
+	}
 
 	if !options.Keep {
 		// Delete all checkpoint related files. At this point, in theory, all files
@@ -1116,6 +1332,27 @@ func (c *Container) generateResolvConf() (string, error) {
 		options = c.config.DNSOption
 	}
 
+	// The infra container holds the resolv.conf of its pod, so apply the
+	// pod's DNS configuration to it
+	if c.config.IsInfra {
+		pod, err := c.runtime.state.Pod(c.config.Pod)
+		if err != nil {
+			return "", errors.Wrapf(err, "error retrieving pod of infra container %s", c.ID())
+		}
+		if pod.config.DNSConfig != nil {
+			nameservers, search, options = pod.config.DNSConfig.apply(nameservers, search, options)
+		}
+	}
+
+	// Point the container at its DNS cache, which forwards to the
+	// nameservers we would otherwise use
+	if c.config.DNSCache {
+		if err := c.startDNSCache(nameservers); err != nil {
+			return "", err
+		}
+		nameservers = []string{dnsCacheAddress}
+	}
+
 	destPath := filepath.Join(c.state.RunDir, "resolv.conf")
 
 	if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
@@ -1183,6 +1420,9 @@ func (c *Container) generatePasswd() (string, error) {
 		groupspec string
 		gid       int
 	)
+	if c.config.KeepID != nil {
+		return c.generateKeepIDPasswd()
+	}
 	if c.config.User == "" {
 		return "", nil
 	}
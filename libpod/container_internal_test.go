@@ -10,7 +10,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/containers/libpod/libpod/define"
 	rspec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -79,3 +81,89 @@ func init() {
 		panic("we need a reliable executable path on Windows")
 	}
 }
+
+func TestContainerSetState(t *testing.T) {
+	var transitions []string
+	c := Container{
+		config: &ContainerConfig{ID: "123abc"},
+		state:  &ContainerState{State: define.ContainerStateConfigured},
+		runtime: &Runtime{
+			stateHooks: []ContainerStateHook{
+				func(ctr *Container, from, to define.ContainerStatus) {
+					transitions = append(transitions, fmt.Sprintf("%s->%s", from, to))
+				},
+			},
+		},
+	}
+
+	for _, state := range []define.ContainerStatus{
+		define.ContainerStateCreated,
+		define.ContainerStateRunning,
+		define.ContainerStatePaused,
+		define.ContainerStateRunning,
+		define.ContainerStateStopped,
+		define.ContainerStateExited,
+	} {
+		assert.NoError(t, c.setState(state))
+	}
+	assert.Equal(t, []string{
+		"configured->created",
+		"created->running",
+		"running->paused",
+		"paused->running",
+		"running->stopped",
+		"stopped->exited",
+	}, transitions)
+
+	// Remaining in the same state is always permitted and calls no hooks.
+	assert.NoError(t, c.setState(define.ContainerStateExited))
+	assert.Len(t, transitions, 6)
+
+	// A container that was stopped while being initialized must not be
+	// moved back to Created or Paused.
+	c.state.State = define.ContainerStateRunning
+	err := c.setState(define.ContainerStateCreated)
+	assert.Equal(t, define.ErrCtrStateInvalid, errors.Cause(err))
+	assert.Equal(t, define.ContainerStateRunning, c.state.State)
+
+	c.state.State = define.ContainerStateExited
+	err = c.setState(define.ContainerStatePaused)
+	assert.Equal(t, define.ErrCtrStateInvalid, errors.Cause(err))
+	assert.Equal(t, define.ContainerStateExited, c.state.State)
+	assert.Len(t, transitions, 6)
+
+	// Unknown containers may move to any state.
+	c.state.State = define.ContainerStateUnknown
+	assert.NoError(t, c.setState(define.ContainerStatePaused))
+}
+
+func TestSetStateRemovingIsTerminal(t *testing.T) {
+	c := &Container{
+		config: &ContainerConfig{ID: "ctr"},
+		state:  &ContainerState{State: define.ContainerStateStopped},
+	}
+
+	assert.NoError(t, c.setState(define.ContainerStateRemoving))
+	assert.Equal(t, "removing", c.state.State.String())
+
+	// A container being removed can only finish being removed.
+	for _, state := range []define.ContainerStatus{
+		define.ContainerStateConfigured,
+		define.ContainerStateCreated,
+		define.ContainerStateRunning,
+		define.ContainerStateExited,
+	} {
+		err := c.setState(state)
+		assert.Equal(t, define.ErrCtrStateInvalid, errors.Cause(err))
+		assert.Equal(t, define.ContainerStateRemoving, c.state.State)
+	}
+
+	status, err := define.StringToContainerStatus("removing")
+	assert.NoError(t, err)
+	assert.Equal(t, define.ContainerStateRemoving, status)
+
+	// Resetting after a reboot must not lose track of a half-removed
+	// container.
+	assert.NoError(t, resetState(c.state))
+	assert.Equal(t, define.ContainerStateRemoving, c.state.State)
+}
@@ -9,6 +9,10 @@ import (
 	spec "github.com/opencontainers/runtime-spec/specs-go"
 )
 
+func (c *Container) collectExitReport() (*ContainerExitReport, error) {
+	return nil, define.ErrNotImplemented
+}
+
 func (c *Container) mountSHM(shmOptions string) error {
 	return define.ErrNotImplemented
 }
@@ -0,0 +1,63 @@
+package libpod
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/containers/libpod/libpod/logs"
+	"github.com/containers/storage/pkg/reexec"
+	"github.com/pkg/errors"
+)
+
+// jsonFileLoggerCommand is the name the json-file logger is re-executed under
+const jsonFileLoggerCommand = "libpod-json-file-logger"
+
+func init() {
+	reexec.Register(jsonFileLoggerCommand, jsonFileLoggerMain)
+}
+
+// startJSONFileLogger starts the process writing the output of the container
+// to its json-file log
+func (c *Container) startJSONFileLogger() (*exec.Cmd, error) {
+	return c.runLogger(jsonFileLoggerCommand, c.LogPath(), strconv.FormatInt(c.config.LogMaxSize, 10), strconv.FormatUint(uint64(c.config.LogMaxFiles), 10))
+}
+
+// jsonFileLoggerMain is the entry point of the json-file logger. Its arguments
+// are the FIFO to read, the log file to write, and the maximum size and number
+// of log files.
+func jsonFileLoggerMain() {
+	if err := runJSONFileLogger(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", jsonFileLoggerCommand, err)
+		os.Exit(1)
+	}
+}
+
+func runJSONFileLogger(args []string) error {
+	if len(args) != 4 {
+		return errors.Errorf("expected 4 arguments, got %d", len(args))
+	}
+	maxSize, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid log max size %q", args[2])
+	}
+	maxFiles, err := strconv.ParseUint(args[3], 10, 32)
+	if err != nil {
+		return errors.Wrapf(err, "invalid log max files %q", args[3])
+	}
+
+	writer, err := logs.NewJSONFileWriter(args[1], maxSize, uint(maxFiles))
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	fifo, err := openLoggerFIFO(args[0])
+	if err != nil {
+		return err
+	}
+	defer fifo.Close()
+
+	return logs.CopyToJSONFile(fifo, writer)
+}
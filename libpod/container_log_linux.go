@@ -4,6 +4,7 @@
 package libpod
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -28,7 +29,7 @@ const (
 	bufLen = 16384
 )
 
-func (c *Container) readFromJournal(options *logs.LogOptions, logChannel chan *logs.LogLine) error {
+func (c *Container) readFromJournal(ctx context.Context, options *logs.LogOptions, logChannel chan *logs.LogLine) error {
 	var config journal.JournalReaderConfig
 	config.NumFromTail = options.Tail
 	config.Formatter = journalFormatter
@@ -45,7 +46,6 @@ func (c *Container) readFromJournal(options *logs.LogOptions, logChannel chan *l
 		Field: "CONTAINER_ID_FULL",
 		Value: c.ID(),
 	})
-	options.WaitGroup.Add(1)
 
 	r, err := journal.NewJournalReader(config)
 	if err != nil {
@@ -58,13 +58,25 @@ func (c *Container) readFromJournal(options *logs.LogOptions, logChannel chan *l
 		r.Rewind()
 	}
 
+	follower := &FollowBuffer{ctx: ctx, logChannel: logChannel, cid: c.ID(), until: options.Until}
+	options.WaitGroup.Add(1)
 	if options.Follow {
+		// Following stops when the context is cancelled
+		stop := make(chan time.Time)
+		followed := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				close(stop)
+			case <-followed:
+			}
+		}()
 		go func() {
-			follower := FollowBuffer{logChannel}
-			err := r.Follow(nil, follower)
-			if err != nil {
+			err := r.Follow(stop, follower)
+			if err != nil && err != journal.ErrExpired {
 				logrus.Debugf(err.Error())
 			}
+			close(followed)
 			r.Close()
 			options.WaitGroup.Done()
 			return
@@ -77,15 +89,12 @@ func (c *Container) readFromJournal(options *logs.LogOptions, logChannel chan *l
 		// /me complains about no do-while in go
 		ec, err := r.Read(bytes)
 		for ec != 0 && err == nil {
-			// because we are reusing bytes, we need to make
-			// sure the old data doesn't get into the new line
-			bytestr := string(bytes[:ec])
-			logLine, err2 := logs.NewLogLine(bytestr)
-			if err2 != nil {
+			if _, err2 := follower.Write(bytes[:ec]); err2 != nil {
+				if ctx.Err() != nil {
+					break
+				}
 				logrus.Error(err2)
-				continue
 			}
-			logChannel <- logLine
 			ec, err = r.Read(bytes)
 		}
 		if err != nil && err != io.EOF {
@@ -129,16 +138,39 @@ func journalFormatter(entry *journal.JournalEntry) (string, error) {
 	return output, nil
 }
 
+// FollowBuffer sends the journal entries of a container written to it as log
+// lines, until its context is cancelled
 type FollowBuffer struct {
+	ctx        context.Context
 	logChannel chan *logs.LogLine
+	cid        string
+	until      time.Time
+	partial    string
 }
 
-func (f FollowBuffer) Write(p []byte) (int, error) {
+// Write sends the journal entry formatted in p as a log line of the container.
+// Partial entries are held back and joined to the entry completing them, and
+// entries after the until time of the buffer are dropped.
+func (f *FollowBuffer) Write(p []byte) (int, error) {
 	bytestr := string(p)
 	logLine, err := logs.NewLogLine(bytestr)
 	if err != nil {
 		return -1, err
 	}
-	f.logChannel <- logLine
+	if logLine.Partial() {
+		f.partial += logLine.Msg
+		return len(p), nil
+	}
+	logLine.Msg = f.partial + logLine.Msg
+	f.partial = ""
+	if !logLine.Until(f.until) {
+		return len(p), nil
+	}
+	logLine.CID = f.cid
+	select {
+	case f.logChannel <- logLine:
+	case <-f.ctx.Done():
+		return -1, f.ctx.Err()
+	}
 	return len(p), nil
 }
@@ -0,0 +1,80 @@
+package libpod
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/containers/libpod/libpod/logs"
+	"github.com/containers/storage/pkg/reexec"
+	"github.com/pkg/errors"
+)
+
+// logShipperCommand is the name the log shipper of the syslog and fluentd log
+// drivers is re-executed under
+const logShipperCommand = "libpod-log-shipper"
+
+func init() {
+	reexec.Register(logShipperCommand, logShipperMain)
+}
+
+// startLogShipper starts the process sending the output of the container to
+// the endpoint of its log driver. Entries are tagged with the log tag of the
+// container, or its name if it has none.
+func (c *Container) startLogShipper() (*exec.Cmd, error) {
+	tag := c.LogTag()
+	if tag == "" {
+		tag = c.Name()
+	}
+	return c.runLogger(logShipperCommand, c.LogDriver(), c.config.LogAddress, tag, c.ID(), c.Name(), c.logSpillPath(), c.logShipperStatusPath())
+}
+
+// logShipperMain is the entry point of the log shipper. Its arguments are the
+// FIFO to read, the log driver, the address of its endpoint, the tag of the
+// entries, the ID and name of the container, and the paths of the spill and
+// status files.
+func logShipperMain() {
+	if err := runLogShipper(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", logShipperCommand, err)
+		os.Exit(1)
+	}
+}
+
+func runLogShipper(args []string) error {
+	if len(args) != 8 {
+		return errors.Errorf("expected 8 arguments, got %d", len(args))
+	}
+	driver, address, tag, id, name := args[1], args[2], args[3], args[4], args[5]
+
+	var dial func() (logs.LogSender, error)
+	switch driver {
+	case SyslogLogging:
+		dial = func() (logs.LogSender, error) {
+			return logs.DialSyslog(address, tag)
+		}
+	case FluentdLogging:
+		fields := map[string]string{
+			"container_id":   id,
+			"container_name": name,
+		}
+		dial = func() (logs.LogSender, error) {
+			return logs.DialFluentd(address, tag, fields)
+		}
+	default:
+		return errors.Errorf("log driver %s does not ship logs", driver)
+	}
+
+	shipper, err := logs.NewLogShipper(dial, args[6], args[7])
+	if err != nil {
+		return err
+	}
+	defer shipper.Close()
+
+	fifo, err := openLoggerFIFO(args[0])
+	if err != nil {
+		return err
+	}
+	defer fifo.Close()
+
+	return logs.CopyToShipper(fifo, shipper)
+}
@@ -0,0 +1,47 @@
+package libpod
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/logs"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendLogLinesStopsWithContext(t *testing.T) {
+	c := &Container{config: &ContainerConfig{ID: "abc"}}
+	var wg sync.WaitGroup
+	options := &logs.LogOptions{WaitGroup: &wg}
+	lines := []*logs.LogLine{{Msg: "1"}, {Msg: "2"}}
+
+	// Nothing receives the lines, as when the client went away
+	ctx, cancel := context.WithCancel(context.Background())
+	logChannel := make(chan *logs.LogLine)
+	c.sendLogLines(ctx, nil, lines, logs.NewLogLine, options, logChannel)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("log lines were still being sent after the context was cancelled")
+	}
+}
+
+func TestLogRotationRequiresJSONFile(t *testing.T) {
+	runtime := &Runtime{}
+	for _, driver := range []string{"", KubernetesLogging, JournaldLogging, SyslogLogging} {
+		ctr := &Container{config: &ContainerConfig{ID: "abc", LogDriver: driver}}
+		ctr.config.LogMaxSize = 1024
+		_, err := runtime.setupContainer(context.Background(), ctr)
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(err), driver)
+	}
+}
@@ -0,0 +1,87 @@
+package libpod
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/pkg/reexec"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// Programs using libpod must call reexec.Init() first thing in main for the
+// log drivers running a logger process to work, as podman does.
+
+// usesLogger returns whether the log driver of the container runs a logger
+// process, which conmon writes the output of the container to
+func (c *Container) usesLogger() bool {
+	switch c.LogDriver() {
+	case JSONLogging, SyslogLogging, FluentdLogging:
+		return true
+	}
+	return false
+}
+
+// startLogger starts the logger process of the container
+func (c *Container) startLogger() (*exec.Cmd, error) {
+	switch c.LogDriver() {
+	case JSONLogging:
+		return c.startJSONFileLogger()
+	case SyslogLogging, FluentdLogging:
+		return c.startLogShipper()
+	}
+	return nil, errors.Wrapf(define.ErrInvalidArg, "log driver %s does not use a logger", c.LogDriver())
+}
+
+// loggerFIFOPath returns the path of the FIFO conmon writes the output of the
+// container to when its log driver runs a logger process
+func (c *Container) loggerFIFOPath() string {
+	return filepath.Join(c.state.RunDir, "logger")
+}
+
+// runLogger starts a logger process re-executed under the given command name.
+// The process reads the output of the container from a FIFO written by conmon,
+// given as its first argument, and exits once conmon closes it. The process is
+// detached, so it outlives this one along with conmon, and is reaped in the
+// background if it exits first.
+func (c *Container) runLogger(command string, args ...string) (*exec.Cmd, error) {
+	fifo := c.loggerFIFOPath()
+	if err := os.Remove(fifo); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "error removing logger FIFO of container %s", c.ID())
+	}
+	if err := unix.Mkfifo(fifo, 0600); err != nil {
+		return nil, errors.Wrapf(err, "error creating logger FIFO of container %s", c.ID())
+	}
+
+	cmd := reexec.Command(append([]string{command, fifo}, args...)...)
+	// Replace the parent death signal set by reexec
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "error starting %s logger of container %s", c.LogDriver(), c.ID())
+	}
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			logrus.Debugf("%s logger of container %s exited: %v", c.LogDriver(), c.ID(), err)
+		}
+	}()
+	return cmd, nil
+}
+
+// openLoggerFIFO opens the FIFO a logger process reads from. It blocks until
+// conmon opens the FIFO for writing, and removes it then.
+func openLoggerFIFO(path string) (*os.File, error) {
+	fifo, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening FIFO %s", path)
+	}
+	// Conmon holds the FIFO open from now on, so it can be removed
+	if err := os.Remove(path); err != nil {
+		fifo.Close()
+		return nil, errors.Wrapf(err, "error removing FIFO %s", path)
+	}
+	return fifo, nil
+}
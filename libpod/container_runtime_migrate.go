@@ -0,0 +1,100 @@
+package libpod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ociRuntimeName returns the name of the OCI runtime a container config refers
+// to. Legacy containers might use a literal path for their OCI runtime name;
+// the base name of the path is the name of the runtime.
+func ociRuntimeName(name string) string {
+	if strings.HasPrefix(name, "/") {
+		return filepath.Base(name)
+	}
+	return name
+}
+
+// lookupOCIRuntime returns the configured OCI runtime with the given name,
+// which may be a legacy literal path, and checks that its binary is present
+func (r *Runtime) lookupOCIRuntime(name string) (OCIRuntime, error) {
+	if name == "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "must provide the name of an OCI runtime")
+	}
+	ociRuntime, ok := r.getOCIRuntime(name)
+	if !ok {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "OCI runtime %s is not available in the current configuration", name)
+	}
+	// Runtimes provided by the embedder may not have a binary
+	if ociRuntime.Path() == "" {
+		return ociRuntime, nil
+	}
+	info, err := os.Stat(ociRuntime.Path())
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot stat OCI runtime %s binary %s", ociRuntime.Name(), ociRuntime.Path())
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "OCI runtime %s binary %s is not an executable file", ociRuntime.Name(), ociRuntime.Path())
+	}
+	return ociRuntime, nil
+}
+
+// migrateRuntime switches the container to the given OCI runtime. The container
+// cannot be created, running or paused. Stopped containers are deleted from
+// their current OCI runtime first, unless it is no longer configured.
+// Must be called with the container locked.
+func (c *Container) migrateRuntime(ctx context.Context, newRuntime string) error {
+	ociRuntime, err := c.runtime.lookupOCIRuntime(newRuntime)
+	if err != nil {
+		return err
+	}
+
+	switch c.state.State {
+	case define.ContainerStateConfigured, define.ContainerStateStopped, define.ContainerStateExited:
+	default:
+		return errors.Wrapf(define.ErrCtrStateInvalid, "container %s is %s, it must be stopped to change its OCI runtime", c.ID(), c.state.State.String())
+	}
+
+	// A legacy literal path differs from the name, and is rewritten even
+	// though the runtime does not change.
+	if c.config.OCIRuntime == ociRuntime.Name() {
+		c.ociRuntime = ociRuntime
+		return nil
+	}
+
+	if c.state.State == define.ContainerStateStopped {
+		if _, missing := c.ociRuntime.(*missingOCIRuntime); missing {
+			logrus.Warnf("Container %s cannot be deleted from OCI runtime %s, which is not available in the current configuration; its files in that runtime are left behind", c.ID(), c.config.OCIRuntime)
+			if err := c.setState(define.ContainerStateExited); err != nil {
+				return err
+			}
+			if err := c.save(); err != nil {
+				return err
+			}
+		} else if err := c.cleanupRuntime(ctx); err != nil {
+			return errors.Wrapf(err, "error deleting container %s from OCI runtime %s", c.ID(), c.config.OCIRuntime)
+		}
+	}
+
+	// Only the name of the runtime changes, so the rest of the
+	// configuration is shared with the previous one
+	newConfig := *c.config
+	newConfig.OCIRuntime = ociRuntime.Name()
+
+	if err := c.runtime.state.RewriteContainerConfig(c, &newConfig); err != nil {
+		return errors.Wrapf(err, "error saving OCI runtime %s of container %s", ociRuntime.Name(), c.ID())
+	}
+	c.config = &newConfig
+	c.ociRuntime = ociRuntime
+
+	c.newContainerEvent(events.Update)
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+// ContainerResourceUpdate holds new resource limits for an existing container.
+// Fields that are nil are left unchanged.
+type ContainerResourceUpdate struct {
+	// CPUShares is the relative CPU weight of the container.
+	CPUShares *uint64
+	// MemoryLimit is the memory limit of the container, in bytes.
+	MemoryLimit *int64
+	// MemorySwap is the limit of memory and swap combined, in bytes.
+	MemorySwap *int64
+	// PidsLimit is the maximum number of processes in the container.
+	PidsLimit *int64
+	// BlkioWeight is the relative block IO weight of the container,
+	// between 10 and 1000.
+	BlkioWeight *uint16
+}
+
+// validate checks that the requested limits are sane.
+func (u *ContainerResourceUpdate) validate() error {
+	if u.BlkioWeight != nil && (*u.BlkioWeight < 10 || *u.BlkioWeight > 1000) {
+		return errors.Wrapf(define.ErrInvalidArg, "blkio weight must be between 10 and 1000, not %d", *u.BlkioWeight)
+	}
+	if u.MemoryLimit != nil && *u.MemoryLimit < 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "memory limit cannot be negative")
+	}
+	if u.MemorySwap != nil && *u.MemorySwap < -1 {
+		return errors.Wrapf(define.ErrInvalidArg, "memory swap limit must be -1 (unlimited) or positive")
+	}
+	return nil
+}
+
+// apply sets the requested limits in the given resources, and returns
+// resources containing only the requested limits.
+func (u *ContainerResourceUpdate) apply(resources *spec.LinuxResources) *spec.LinuxResources {
+	changed := new(spec.LinuxResources)
+
+	if u.CPUShares != nil {
+		if resources.CPU == nil {
+			resources.CPU = new(spec.LinuxCPU)
+		}
+		resources.CPU.Shares = u.CPUShares
+		changed.CPU = &spec.LinuxCPU{Shares: u.CPUShares}
+	}
+	if u.MemoryLimit != nil || u.MemorySwap != nil {
+		if resources.Memory == nil {
+			resources.Memory = new(spec.LinuxMemory)
+		}
+		changed.Memory = new(spec.LinuxMemory)
+		if u.MemoryLimit != nil {
+			resources.Memory.Limit = u.MemoryLimit
+			changed.Memory.Limit = u.MemoryLimit
+		}
+		if u.MemorySwap != nil {
+			resources.Memory.Swap = u.MemorySwap
+			changed.Memory.Swap = u.MemorySwap
+		}
+	}
+	if u.PidsLimit != nil {
+		resources.Pids = &spec.LinuxPids{Limit: *u.PidsLimit}
+		changed.Pids = &spec.LinuxPids{Limit: *u.PidsLimit}
+	}
+	if u.BlkioWeight != nil {
+		if resources.BlockIO == nil {
+			resources.BlockIO = new(spec.LinuxBlockIO)
+		}
+		resources.BlockIO.Weight = u.BlkioWeight
+		changed.BlockIO = &spec.LinuxBlockIO{Weight: u.BlkioWeight}
+	}
+
+	return changed
+}
+
+// update changes the resource limits of the container.
+// If the container exists in the OCI runtime, its limits are changed
+// immediately. The new limits are saved in the container's configuration, so
+// they are kept when it is restarted.
+// Must be called with the container locked.
+func (c *Container) update(update *ContainerResourceUpdate) error {
+	if err := update.validate(); err != nil {
+		return err
+	}
+
+	if c.config.Spec.Linux == nil {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s has no Linux configuration, cannot update resources", c.ID())
+	}
+
+	newConfig := c.Config()
+	if newConfig == nil {
+		return errors.Wrapf(define.ErrInternal, "error copying configuration of container %s", c.ID())
+	}
+	if newConfig.Spec.Linux.Resources == nil {
+		newConfig.Spec.Linux.Resources = new(spec.LinuxResources)
+	}
+	changed := update.apply(newConfig.Spec.Linux.Resources)
+
+	if c.config.Pod != "" {
+		pod, err := c.runtime.state.Pod(c.config.Pod)
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving pod %s of container %s", c.config.Pod, c.ID())
+		}
+		if err := pod.checkContainerResources(c, newConfig.Spec.Linux.Resources); err != nil {
+			return err
+		}
+	}
+
+	switch c.state.State {
+	case define.ContainerStateCreated, define.ContainerStateRunning, define.ContainerStatePaused:
+		if err := c.ociRuntime.UpdateContainer(c, changed); err != nil {
+			return err
+		}
+	}
+
+	if err := c.runtime.state.RewriteContainerConfig(c, newConfig); err != nil {
+		return errors.Wrapf(err, "error saving new resource limits of container %s", c.ID())
+	}
+	c.config = newConfig
+
+	c.newContainerEvent(events.Update)
+
+	return nil
+}
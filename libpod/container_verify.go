@@ -0,0 +1,235 @@
+package libpod
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage"
+	"github.com/containers/storage/pkg/archive"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// ContainerVerifyReport describes how a container's root filesystem differs
+// from the image it was created from.
+type ContainerVerifyReport struct {
+	// Verified is true if no changes were found outside of the container's
+	// volumes and the paths Podman itself writes to.
+	Verified bool `json:"Verified"`
+	// ImageID is the ID of the image the container was compared against.
+	ImageID string `json:"ImageID"`
+	// Modified lists files present in the image that have been changed.
+	Modified []string `json:"Modified,omitempty"`
+	// Added lists files that are not present in the image.
+	Added []string `json:"Added,omitempty"`
+	// Deleted lists files present in the image that have been removed.
+	Deleted []string `json:"Deleted,omitempty"`
+	// CorruptLayers lists the image layers whose contents do not match the
+	// digests recorded in the image's manifest.
+	CorruptLayers []string `json:"CorruptLayers,omitempty"`
+}
+
+// Verify compares the container's current root filesystem against the layers
+// of the image it was created from, and reports any files that were modified,
+// added, or deleted.
+// Changes within the container's volumes and mounts, and to the files Podman
+// manages on its behalf (such as /etc/hosts), are not reported.
+// The image layers themselves are checked against the digests in the image's
+// manifest, and any that differ are reported as corrupt.
+func (c *Container) Verify(ctx context.Context) (*ContainerVerifyReport, error) {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.state.State == define.ContainerStateRemoving {
+		return nil, errors.Wrapf(define.ErrCtrStateInvalid, "cannot verify container %s as it is being removed", c.ID())
+	}
+	if c.config.Rootfs != "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "container %s was created from a root filesystem, not an image, and cannot be verified", c.ID())
+	}
+
+	storeCtr, err := c.runtime.store.Container(c.ID())
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting container from store %q", c.ID())
+	}
+	layer, err := c.runtime.store.Layer(storeCtr.LayerID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading information about layer %q", storeCtr.LayerID)
+	}
+	changes, err := c.runtime.store.Changes(layer.Parent, layer.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error comparing container %s against its image", c.ID())
+	}
+
+	report := newContainerVerifyReport(c.config.RootfsImageID, changes, c.verifyExcludedPaths())
+
+	corrupt, err := c.verifyImageLayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.CorruptLayers = corrupt
+	report.Verified = report.Verified && len(corrupt) == 0
+
+	return report, nil
+}
+
+// verifyImageLayers recomputes the digest of each layer of the container's
+// image and returns the IDs of the layers that do not match the diff IDs
+// listed in the image's configuration, which the manifest refers to by
+// digest.
+func (c *Container) verifyImageLayers(ctx context.Context) ([]string, error) {
+	img, err := c.runtime.imageRuntime.NewFromLocal(c.config.RootfsImageID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error looking up image %s of container %s", c.config.RootfsImageID, c.ID())
+	}
+	imgRef, err := img.ToImageRef(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := imgRef.OCIConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading configuration of image %s", img.ID())
+	}
+
+	// Walk from the top layer down, then reverse to match the order of the
+	// diff IDs
+	var layers []*storage.Layer
+	for layerID := img.TopLayer(); layerID != ""; {
+		layer, err := c.runtime.store.Layer(layerID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading information about layer %q", layerID)
+		}
+		layers = append([]*storage.Layer{layer}, layers...)
+		layerID = layer.Parent
+	}
+	if len(layers) != len(config.RootFS.DiffIDs) {
+		return nil, errors.Wrapf(define.ErrInternal, "image %s has %d layers but its manifest lists %d", img.ID(), len(layers), len(config.RootFS.DiffIDs))
+	}
+
+	layerIDs := make([]string, 0, len(layers))
+	digests := make([]digest.Digest, 0, len(layers))
+	uncompressed := archive.Uncompressed
+	for _, layer := range layers {
+		sum, err := c.layerDigest(layer, &storage.DiffOptions{Compression: &uncompressed})
+		if err != nil {
+			return nil, err
+		}
+		layerIDs = append(layerIDs, layer.ID)
+		digests = append(digests, sum)
+	}
+
+	return mismatchedLayers(layerIDs, digests, config.RootFS.DiffIDs), nil
+}
+
+// layerDigest computes the digest of the contents of a layer.
+func (c *Container) layerDigest(layer *storage.Layer, options *storage.DiffOptions) (digest.Digest, error) {
+	diff, err := c.runtime.store.Diff(layer.Parent, layer.ID, options)
+	if err != nil {
+		return "", errors.Wrapf(err, "error reading contents of layer %q", layer.ID)
+	}
+	defer diff.Close()
+
+	layerDigest, err := digest.Canonical.FromReader(diff)
+	if err != nil {
+		return "", errors.Wrapf(err, "error computing digest of layer %q", layer.ID)
+	}
+	return layerDigest, nil
+}
+
+// mismatchedLayers returns the IDs of the layers whose digests differ from the
+// expected diff IDs, which are in the same order.
+func mismatchedLayers(layerIDs []string, digests, diffIDs []digest.Digest) []string {
+	var mismatched []string
+	for i, layerID := range layerIDs {
+		if digests[i] != diffIDs[i] {
+			mismatched = append(mismatched, layerID)
+		}
+	}
+	return mismatched
+}
+
+// verifyExcludedPaths returns the paths in the container that are expected to
+// change, and are ignored when verifying it.
+func (c *Container) verifyExcludedPaths() []string {
+	excluded := make([]string, 0, len(containerMounts))
+	for path := range containerMounts {
+		excluded = append(excluded, path)
+	}
+	if c.config.Spec != nil {
+		for _, mount := range c.config.Spec.Mounts {
+			excluded = append(excluded, mount.Destination)
+		}
+	}
+	for _, vol := range c.config.NamedVolumes {
+		excluded = append(excluded, vol.Dest)
+	}
+	for dest := range c.state.BindMounts {
+		excluded = append(excluded, dest)
+	}
+	return excluded
+}
+
+// newContainerVerifyReport builds a report from the given changes, ignoring
+// changes to excluded paths and their contents.
+// A modified directory is only reported if nothing beneath it changed, as
+// adding or removing a file otherwise marks every parent directory modified.
+func newContainerVerifyReport(imageID string, changes []archive.Change, excluded []string) *ContainerVerifyReport {
+	report := &ContainerVerifyReport{ImageID: imageID}
+
+	for _, change := range changes {
+		if pathIsExcluded(change.Path, excluded) {
+			continue
+		}
+		switch change.Kind {
+		case archive.ChangeModify:
+			if hasChangeBeneath(change.Path, changes) {
+				continue
+			}
+			report.Modified = append(report.Modified, change.Path)
+		case archive.ChangeAdd:
+			report.Added = append(report.Added, change.Path)
+		case archive.ChangeDelete:
+			report.Deleted = append(report.Deleted, change.Path)
+		}
+	}
+
+	sort.Strings(report.Modified)
+	sort.Strings(report.Added)
+	sort.Strings(report.Deleted)
+	report.Verified = len(report.Modified) == 0 && len(report.Added) == 0 && len(report.Deleted) == 0
+
+	return report
+}
+
+// pathIsExcluded returns whether path is one of the excluded paths, or is
+// within one of them.
+func pathIsExcluded(path string, excluded []string) bool {
+	path = filepath.Clean(path)
+	for _, dest := range excluded {
+		dest = filepath.Clean(dest)
+		if path == dest || strings.HasPrefix(path, strings.TrimSuffix(dest, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasChangeBeneath returns whether any of the changes is within the directory
+// dir.
+func hasChangeBeneath(dir string, changes []archive.Change) bool {
+	prefix := strings.TrimSuffix(filepath.Clean(dir), "/") + "/"
+	for _, change := range changes {
+		if strings.HasPrefix(change.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/storage/pkg/archive"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewContainerVerifyReport(t *testing.T) {
+	changes := []archive.Change{
+		{Path: "/etc", Kind: archive.ChangeModify},
+		{Path: "/etc/passwd", Kind: archive.ChangeModify},
+		{Path: "/etc/hosts", Kind: archive.ChangeModify},
+		{Path: "/usr", Kind: archive.ChangeModify},
+		{Path: "/usr/bin", Kind: archive.ChangeModify},
+		{Path: "/usr/bin/backdoor", Kind: archive.ChangeAdd},
+		{Path: "/bin/ls", Kind: archive.ChangeDelete},
+		{Path: "/data", Kind: archive.ChangeModify},
+		{Path: "/data/db/file", Kind: archive.ChangeAdd},
+		{Path: "/run", Kind: archive.ChangeAdd},
+		{Path: "/run/.containerenv", Kind: archive.ChangeAdd},
+	}
+
+	report := newContainerVerifyReport("image", changes, []string{"/etc/hosts", "/run", "/data/"})
+	assert.False(t, report.Verified)
+	assert.Equal(t, "image", report.ImageID)
+	assert.Equal(t, []string{"/etc/passwd"}, report.Modified)
+	assert.Equal(t, []string{"/usr/bin/backdoor"}, report.Added)
+	assert.Equal(t, []string{"/bin/ls"}, report.Deleted)
+
+	report = newContainerVerifyReport("image", changes[:3], []string{"/etc/hosts", "/etc/passwd"})
+	assert.True(t, report.Verified)
+	assert.Empty(t, report.Modified)
+}
+
+func TestMismatchedLayers(t *testing.T) {
+	base := digest.FromString("base")
+	top := digest.FromString("top")
+	diffIDs := []digest.Digest{base, top}
+
+	assert.Empty(t, mismatchedLayers([]string{"l1", "l2"}, []digest.Digest{base, top}, diffIDs))
+	assert.Equal(t, []string{"l2"}, mismatchedLayers([]string{"l1", "l2"}, []digest.Digest{base, digest.FromString("tampered")}, diffIDs))
+}
@@ -0,0 +1,61 @@
+// +build linux
+
+package libpod
+
+import (
+	"fmt"
+
+	"github.com/opencontainers/runtime-tools/generate"
+)
+
+// Annotations configuring the virtual machines of Kata Containers
+const (
+	// kataSharedFSAnnotation selects how the filesystems of the container
+	// are shared with the virtual machine
+	kataSharedFSAnnotation = "io.katacontainers.config.hypervisor.shared_fs"
+	// kataMemoryAnnotation is the memory of the virtual machine in MiB.
+	// Kata hotplugs memory beyond it when the container's limit is larger.
+	kataMemoryAnnotation = "io.katacontainers.config.hypervisor.default_memory"
+	// kataVCPUsAnnotation is the number of virtual CPUs of the virtual
+	// machine
+	kataVCPUsAnnotation = "io.katacontainers.config.hypervisor.default_vcpus"
+)
+
+// kataSharedFS is the filesystem sharing mechanism requested for containers,
+// which performs far better than 9p for container root filesystems and volumes
+const kataSharedFS = "virtio-fs"
+
+// addVMAnnotations sizes the virtual machine running the container after its
+// resource limits, when the container runs in a VM-based runtime. Annotations
+// set by the user are not overridden.
+func (c *Container) addVMAnnotations(g *generate.Generator) {
+	if !hasOCIFeature(c.ociRuntime, OCIFeatureKVM) {
+		return
+	}
+
+	annotations := g.Config.Annotations
+	setDefault := func(key, value string) {
+		if _, ok := annotations[key]; !ok {
+			g.AddAnnotation(key, value)
+		}
+	}
+
+	setDefault(kataSharedFSAnnotation, kataSharedFS)
+
+	resources := g.Config.Linux.Resources
+	if resources == nil {
+		return
+	}
+	if resources.Memory != nil && resources.Memory.Limit != nil && *resources.Memory.Limit > 0 {
+		// Round up to whole MiB, the VM must fit the limit
+		const mib = 1024 * 1024
+		memory := (*resources.Memory.Limit + mib - 1) / mib
+		setDefault(kataMemoryAnnotation, fmt.Sprintf("%d", memory))
+	}
+	if resources.CPU != nil && resources.CPU.Quota != nil && resources.CPU.Period != nil &&
+		*resources.CPU.Quota > 0 && *resources.CPU.Period > 0 {
+		period := int64(*resources.CPU.Period)
+		vcpus := (*resources.CPU.Quota + period - 1) / period
+		setDefault(kataVCPUsAnnotation, fmt.Sprintf("%d", vcpus))
+	}
+}
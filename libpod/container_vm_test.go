@@ -0,0 +1,44 @@
+package libpod
+
+import (
+	"testing"
+
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/opencontainers/runtime-tools/generate"
+	"github.com/stretchr/testify/assert"
+)
+
+// probedRuntime returns an OCI runtime already probed for the given features
+func probedRuntime(name string, features ociRuntimeFeatures) *ConmonOCIRuntime {
+	r := &ConmonOCIRuntime{name: name}
+	r.featuresOnce.Do(func() {
+		r.supportedFeatures = &features
+	})
+	return r
+}
+
+func TestAddVMAnnotations(t *testing.T) {
+	limit := int64(1536*1024*1024 + 1)
+	quota := int64(150000)
+	period := uint64(100000)
+	g := generate.Generator{Config: &spec.Spec{
+		Annotations: map[string]string{kataVCPUsAnnotation: "4"},
+		Linux: &spec.Linux{Resources: &spec.LinuxResources{
+			Memory: &spec.LinuxMemory{Limit: &limit},
+			CPU:    &spec.LinuxCPU{Quota: &quota, Period: &period},
+		}},
+	}}
+
+	ctr := &Container{config: &ContainerConfig{}, ociRuntime: probedRuntime("kata", ociRuntimeFeatures{kvm: true})}
+	ctr.addVMAnnotations(&g)
+	assert.Equal(t, kataSharedFS, g.Config.Annotations[kataSharedFSAnnotation])
+	assert.Equal(t, "1537", g.Config.Annotations[kataMemoryAnnotation])
+	// Set by the user
+	assert.Equal(t, "4", g.Config.Annotations[kataVCPUsAnnotation])
+
+	// Containers not run in a VM are left alone
+	g = generate.Generator{Config: &spec.Spec{Linux: &spec.Linux{}}}
+	ctr.ociRuntime = probedRuntime("runc", ociRuntimeFeatures{})
+	ctr.addVMAnnotations(&g)
+	assert.Empty(t, g.Config.Annotations)
+}
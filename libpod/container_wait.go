@@ -0,0 +1,211 @@
+package libpod
+
+import (
+	"context"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// WaitCondition is a condition WaitWithCondition can wait for
+type WaitCondition int
+
+const (
+	// WaitConditionStopped is met when the container is not running or
+	// paused, including if it already was when the wait began
+	WaitConditionStopped WaitCondition = iota
+	// WaitConditionNextExit is met when the container exits after the
+	// wait began
+	WaitConditionNextExit
+	// WaitConditionRemoved is met when the container is removed
+	WaitConditionRemoved
+	// WaitConditionRunning is met when the container is running
+	WaitConditionRunning
+	// WaitConditionHealthy is met when the container is running and its
+	// healthcheck reports it healthy
+	WaitConditionHealthy
+)
+
+// waitRecheckInterval is the interval at which waits re-read the state of the
+// container, in case a change was missed by the watches
+const waitRecheckInterval = 5 * time.Second
+
+// String returns a string representation of the condition
+func (w WaitCondition) String() string {
+	switch w {
+	case WaitConditionStopped:
+		return "stopped"
+	case WaitConditionNextExit:
+		return "next-exit"
+	case WaitConditionRemoved:
+		return "removed"
+	case WaitConditionRunning:
+		return "running"
+	case WaitConditionHealthy:
+		return "healthy"
+	}
+	return "unknown"
+}
+
+// StringToWaitCondition converts a string to a wait condition
+func StringToWaitCondition(name string) (WaitCondition, error) {
+	for _, condition := range []WaitCondition{WaitConditionStopped, WaitConditionNextExit, WaitConditionRemoved, WaitConditionRunning, WaitConditionHealthy} {
+		if condition.String() == name {
+			return condition, nil
+		}
+	}
+	return WaitConditionStopped, errors.Wrapf(define.ErrInvalidArg, "unknown wait condition %q", name)
+}
+
+// WaitWithCondition blocks until one of the given conditions is met, and
+// returns the condition along with the exit code of the container. The exit
+// code is read together with the state satisfying the condition, so it belongs
+// to the exit that was waited for. If no conditions are given, it waits for
+// the container to stop.
+// Changes to the container are watched for rather than polled; the wait ends
+// with an error when the context is cancelled.
+func (c *Container) WaitWithCondition(ctx context.Context, conditions ...WaitCondition) (int32, WaitCondition, error) {
+	if len(conditions) == 0 {
+		conditions = []WaitCondition{WaitConditionStopped}
+	}
+	for _, condition := range conditions {
+		if condition == WaitConditionHealthy && !c.HasHealthCheck() {
+			return -1, condition, errors.Wrapf(define.ErrInvalidArg, "container %s has no defined healthcheck", c.ID())
+		}
+	}
+	if !c.valid {
+		if met, exitCode := c.removedWaitCondition(conditions); met != nil {
+			return exitCode, *met, nil
+		}
+		return -1, WaitConditionRemoved, define.ErrCtrRemoved
+	}
+
+	notify, stopWatch := c.runtime.stateWatch.watch(c.ID())
+	defer stopWatch()
+
+	recheck := waitRecheckInterval
+	var fsEvents <-chan fsnotify.Event
+	var fsErrors <-chan error
+	watcher, err := c.watchWaitFiles()
+	if err != nil {
+		logrus.Debugf("Unable to watch files of container %s, polling its state: %v", c.ID(), err)
+		recheck = DefaultWaitInterval
+	} else {
+		defer watcher.Close()
+		fsEvents = watcher.Events
+		fsErrors = watcher.Errors
+	}
+
+	// Exits before the wait began do not satisfy WaitConditionNextExit
+	lastExit, err := c.FinishedTime()
+	if err != nil && !isRemovedError(err) {
+		return -1, WaitConditionStopped, err
+	}
+
+	for {
+		met, exitCode, err := c.checkWaitConditions(conditions, lastExit)
+		if err != nil {
+			return -1, WaitConditionStopped, err
+		}
+		if met != nil {
+			return exitCode, *met, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, WaitConditionStopped, errors.Wrapf(ctx.Err(), "error waiting for container %s", c.ID())
+		case <-notify:
+		case <-fsEvents:
+		case err := <-fsErrors:
+			logrus.Debugf("Error watching files of container %s: %v", c.ID(), err)
+		case <-time.After(recheck):
+		}
+	}
+}
+
+// checkWaitConditions syncs the container and returns the first of the given
+// conditions that is met, and the exit code of the container, read under the
+// same lock. If none is met, nil is returned.
+func (c *Container) checkWaitConditions(conditions []WaitCondition, lastExit time.Time) (*WaitCondition, int32, error) {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+	}
+
+	if err := c.syncContainer(); err != nil {
+		if isRemovedError(err) {
+			if met, exitCode := c.removedWaitCondition(conditions); met != nil {
+				return met, exitCode, nil
+			}
+		}
+		return nil, -1, err
+	}
+
+	running := c.state.State == define.ContainerStateRunning
+	for _, condition := range conditions {
+		met := false
+		switch condition {
+		case WaitConditionStopped:
+			met = !running && c.state.State != define.ContainerStatePaused
+		case WaitConditionNextExit:
+			met = c.state.FinishedTime.After(lastExit)
+		case WaitConditionRunning:
+			met = running
+		case WaitConditionHealthy:
+			met = running && c.healthCheckResults().Status == HealthCheckHealthy
+		}
+		if met {
+			return &condition, c.state.ExitCode, nil
+		}
+	}
+	return nil, -1, nil
+}
+
+// removedWaitCondition returns the first of the given conditions met by the
+// container having been removed, and its exit code as recorded in the state
+// when it exited. A removed container is also stopped, so waits for it to stop
+// are satisfied if its exit code was recorded. If no condition is met, nil is
+// returned.
+func (c *Container) removedWaitCondition(conditions []WaitCondition) (*WaitCondition, int32) {
+	exitCode, err := c.runtime.state.GetContainerExitCode(c.ID())
+	recorded := err == nil
+	if !recorded {
+		logrus.Debugf("Unable to retrieve exit code of removed container %s: %v", c.ID(), err)
+		exitCode = c.state.ExitCode
+	}
+	for _, condition := range conditions {
+		if condition == WaitConditionRemoved || (condition == WaitConditionStopped && recorded) {
+			return &condition, exitCode
+		}
+	}
+	return nil, -1
+}
+
+// watchWaitFiles watches the files changed when the container changes state
+// outside this process: its exit file and the state database.
+func (c *Container) watchWaitFiles() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{c.exitsDir()}
+	if boltState, ok := c.runtime.state.(*BoltState); ok {
+		paths = append(paths, boltState.dbPath)
+	}
+	for _, path := range paths {
+		if err := watcher.Add(path); err != nil {
+			watcher.Close()
+			return nil, errors.Wrapf(err, "error watching %s", path)
+		}
+	}
+	return watcher, nil
+}
+
+func isRemovedError(err error) bool {
+	cause := errors.Cause(err)
+	return cause == define.ErrNoSuchCtr || cause == define.ErrCtrRemoved
+}
@@ -25,6 +25,10 @@ const (
 	// ContainerStateExited indicates the the container has stopped and been
 	// cleaned up
 	ContainerStateExited ContainerStatus = iota
+	// ContainerStateRemoving indicates the container is in the process of
+	// being removed. It is persisted before teardown begins so that an
+	// interrupted removal can be detected and resumed.
+	ContainerStateRemoving ContainerStatus = iota
 )
 
 // ContainerStatus returns a string representation for users
@@ -45,6 +49,8 @@ func (t ContainerStatus) String() string {
 		return "paused"
 	case ContainerStateExited:
 		return "exited"
+	case ContainerStateRemoving:
+		return "removing"
 	}
 	return "bad state"
 }
@@ -67,7 +73,47 @@ func StringToContainerStatus(status string) (ContainerStatus, error) {
 		return ContainerStatePaused, nil
 	case ContainerStateExited.String():
 		return ContainerStateExited, nil
+	case ContainerStateRemoving.String():
+		return ContainerStateRemoving, nil
 	default:
 		return ContainerStateUnknown, errors.Wrapf(ErrInvalidArg, "unknown container state: %s", status)
 	}
 }
+
+// validStateTransitions records, for each container state, the states a
+// container may move to from it. A container may always remain in its current
+// state, and a container in ContainerStateUnknown may move to any state, as
+// syncing with the OCI runtime is how such a container recovers.
+// ContainerStateRemoving is terminal: once removal has begun, the container may
+// only be removed.
+var validStateTransitions = map[ContainerStatus][]ContainerStatus{
+	ContainerStateConfigured: {ContainerStateCreated, ContainerStateRunning, ContainerStateExited, ContainerStateRemoving},
+	ContainerStateCreated:    {ContainerStateConfigured, ContainerStateRunning, ContainerStateStopped, ContainerStateExited, ContainerStateRemoving},
+	ContainerStateRunning:    {ContainerStatePaused, ContainerStateStopped, ContainerStateExited, ContainerStateRemoving},
+	ContainerStatePaused:     {ContainerStateRunning, ContainerStateStopped, ContainerStateExited, ContainerStateRemoving},
+	ContainerStateStopped:    {ContainerStateCreated, ContainerStateRunning, ContainerStateExited, ContainerStateRemoving},
+	ContainerStateExited:     {ContainerStateConfigured, ContainerStateCreated, ContainerStateRunning, ContainerStateRemoving},
+}
+
+// ValidStateTransition returns whether a container may move from state from to
+// state to.
+func ValidStateTransition(from, to ContainerStatus) bool {
+	if from == to || from == ContainerStateUnknown {
+		return true
+	}
+	for _, state := range validStateTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckStateTransition returns an error wrapping ErrCtrStateInvalid if a
+// container may not move from state from to state to.
+func CheckStateTransition(from, to ContainerStatus) error {
+	if !ValidStateTransition(from, to) {
+		return errors.Wrapf(ErrCtrStateInvalid, "invalid container state transition from %s to %s", from.String(), to.String())
+	}
+	return nil
+}
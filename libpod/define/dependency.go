@@ -0,0 +1,36 @@
+package define
+
+import "github.com/pkg/errors"
+
+// DependencyCondition is a condition that a container's dependency must meet
+// before the container can be started.
+type DependencyCondition string
+
+const (
+	// DependencyConditionCreated requires that the dependency has been
+	// created in the OCI runtime. It need not be running.
+	DependencyConditionCreated DependencyCondition = "created"
+	// DependencyConditionRunning requires that the dependency is running.
+	// This is the default for all dependencies.
+	DependencyConditionRunning DependencyCondition = "running"
+	// DependencyConditionHealthy requires that the dependency is running
+	// and its healthcheck reports it as healthy.
+	DependencyConditionHealthy DependencyCondition = "healthy"
+	// DependencyConditionExitedSuccessfully requires that the dependency
+	// has run to completion and exited with a zero exit code.
+	DependencyConditionExitedSuccessfully DependencyCondition = "exited-successfully"
+)
+
+// StringToDependencyCondition converts a string representation of a
+// dependency condition into a DependencyCondition. The empty string is
+// treated as DependencyConditionRunning.
+func StringToDependencyCondition(condition string) (DependencyCondition, error) {
+	switch DependencyCondition(condition) {
+	case "", DependencyConditionRunning:
+		return DependencyConditionRunning, nil
+	case DependencyConditionCreated, DependencyConditionHealthy, DependencyConditionExitedSuccessfully:
+		return DependencyCondition(condition), nil
+	default:
+		return "", errors.Wrapf(ErrInvalidArg, "unknown dependency condition: %s", condition)
+	}
+}
@@ -20,6 +20,21 @@ var (
 	// ErrNoSuchVolume indicates the requested volume does not exist
 	ErrNoSuchVolume = errors.New("no such volume")
 
+	// ErrNoSuchNetwork indicates the requested network does not exist
+	ErrNoSuchNetwork = errors.New("no such network")
+
+	// ErrNoSuchPodTemplate indicates the requested pod template does not
+	// exist
+	ErrNoSuchPodTemplate = errors.New("no such pod template")
+
+	// ErrNoSuchExecSession indicates the requested exec session does not
+	// exist
+	ErrNoSuchExecSession = errors.New("no such exec session")
+
+	// ErrNoSuchExitCode indicates that no exit code was recorded for the
+	// requested container, or that it has since been pruned
+	ErrNoSuchExitCode = errors.New("no recorded exit code for container")
+
 	// ErrCtrExists indicates a container with the same name or ID already
 	// exists
 	ErrCtrExists = errors.New("container already exists")
@@ -29,12 +44,27 @@ var (
 	ErrImageExists = errors.New("image already exists")
 	// ErrVolumeExists indicates a volume with the same name already exists
 	ErrVolumeExists = errors.New("volume already exists")
+	// ErrNetworkExists indicates a network with the same name already
+	// exists
+	ErrNetworkExists = errors.New("network already exists")
+	// ErrPodTemplateExists indicates a pod template with the same name
+	// already exists
+	ErrPodTemplateExists = errors.New("pod template already exists")
+	// ErrExecSessionExists indicates an exec session with the same ID
+	// already exists
+	ErrExecSessionExists = errors.New("exec session already exists")
 
 	// ErrCtrStateInvalid indicates a container is in an improper state for
 	// the requested operation
 	ErrCtrStateInvalid = errors.New("container state improper")
 	// ErrVolumeBeingUsed indicates that a volume is being used by at least one container
 	ErrVolumeBeingUsed = errors.New("volume is being used")
+	// ErrNetworkBeingUsed indicates that a network is being used by at
+	// least one container
+	ErrNetworkBeingUsed = errors.New("network is being used")
+	// ErrAddressInUse indicates that a static IP or MAC address is already
+	// reserved by another container on the same network
+	ErrAddressInUse = errors.New("address is already in use")
 
 	// ErrRuntimeFinalized indicates that the runtime has already been
 	// created and cannot be modified
@@ -48,6 +78,9 @@ var (
 	// ErrVolumeFinalized indicates that the volume has already been created and
 	// cannot be modified
 	ErrVolumeFinalized = errors.New("volume has been finalized")
+	// ErrNetworkFinalized indicates that the network has already been
+	// created and cannot be modified
+	ErrNetworkFinalized = errors.New("network has been finalized")
 
 	// ErrInvalidArg indicates that an invalid argument was passed
 	ErrInvalidArg = errors.New("invalid argument")
@@ -64,6 +97,20 @@ var (
 	// ErrRuntimeStopped indicates that the runtime has already been shut
 	// down and no further operations can be performed on it
 	ErrRuntimeStopped = errors.New("runtime has already been stopped")
+
+	// ErrLockPoolExhausted indicates that all locks in the lock pool have
+	// been allocated, and no more containers, pods, or volumes can be
+	// created until the pool is grown
+	ErrLockPoolExhausted = errors.New("lock pool exhausted")
+	// ErrUserNSExhausted indicates that the subordinate IDs available for
+	// automatic user namespaces are all allocated to other containers
+	ErrUserNSExhausted = errors.New("no free IDs for user namespace")
+	// ErrLockTimeout indicates that a lock could not be acquired before a
+	// timeout expired
+	ErrLockTimeout = errors.New("timed out waiting for lock")
+	// ErrDeadlock indicates that acquiring a lock would deadlock, or has
+	// deadlocked
+	ErrDeadlock = errors.New("deadlock detected")
 	// ErrCtrStopped indicates that the requested container is not running
 	// and the requested operation cannot be performed until it is started
 	ErrCtrStopped = errors.New("container is stopped")
@@ -77,6 +124,9 @@ var (
 	// ErrVolumeRemoved indicates that the volume has already been removed and
 	// no further operations can be performed on it
 	ErrVolumeRemoved = errors.New("volume has already been removed")
+	// ErrNetworkRemoved indicates that the network has already been removed
+	// and no further operations can be performed on it
+	ErrNetworkRemoved = errors.New("network has already been removed")
 
 	// ErrDBClosed indicates that the connection to the state database has
 	// already been closed
@@ -97,6 +147,11 @@ var (
 	// OS.
 	ErrOSNotSupported = errors.New("no support for this OS yet")
 
+	// ErrArchNotSupported indicates that the host cannot run binaries for
+	// the architecture of an image, either natively or through an emulator
+	// registered with binfmt_misc.
+	ErrArchNotSupported = errors.New("image architecture is not supported by the host")
+
 	// ErrOCIRuntime indicates a generic error from the OCI runtime
 	ErrOCIRuntime = errors.New("OCI runtime error")
 
@@ -104,10 +159,23 @@ var (
 	// a permission denied error
 	ErrOCIRuntimePermissionDenied = errors.New("OCI runtime permission denied error")
 
+	// ErrOCIRuntimeUnavailable indicates the OCI runtime of a container is
+	// not available in the current configuration
+	ErrOCIRuntimeUnavailable = errors.New("OCI runtime not available in the current configuration")
+
 	// ErrOCIRuntimeNotFound indicates the OCI runtime attempted to invoke a command
 	// that was not found
 	ErrOCIRuntimeNotFound = errors.New("OCI runtime command not found error")
 
+	// ErrOCIRuntimeMount indicates the OCI runtime could not mount a
+	// volume or bind mount into the container, usually because its source
+	// is missing or not accessible
+	ErrOCIRuntimeMount = errors.New("OCI runtime mount error")
+
+	// ErrOCIRuntimeCgroup indicates the OCI runtime could not create or
+	// configure the cgroup of the container
+	ErrOCIRuntimeCgroup = errors.New("OCI runtime cgroup error")
+
 	// ErrConmonOutdated indicates the version of conmon found (whether via the configuration or $PATH)
 	// is out of date for the current podman version
 	ErrConmonOutdated = errors.New("outdated conmon version")
@@ -0,0 +1,27 @@
+package define
+
+import "github.com/pkg/errors"
+
+// InitContainerType determines when an init container of a pod is run.
+type InitContainerType string
+
+const (
+	// AlwaysInitContainer is an init container that is run every time the
+	// pod is started.
+	AlwaysInitContainer InitContainerType = "always"
+	// OneShotInitContainer is an init container that is only run the first
+	// time the pod is started. Once it has exited successfully, it is not
+	// run again.
+	OneShotInitContainer InitContainerType = "once"
+)
+
+// StringToInitContainerType converts a string representation of an init
+// container type into an InitContainerType.
+func StringToInitContainerType(initType string) (InitContainerType, error) {
+	switch InitContainerType(initType) {
+	case AlwaysInitContainer, OneShotInitContainer:
+		return InitContainerType(initType), nil
+	default:
+		return "", errors.Wrapf(ErrInvalidArg, "unknown init container type %q, must be %q or %q", initType, AlwaysInitContainer, OneShotInitContainer)
+	}
+}
@@ -0,0 +1,31 @@
+package define
+
+import "github.com/pkg/errors"
+
+// SdNotifyMode determines how the systemd unit running podman is notified
+// that a container is ready, through the socket in $NOTIFY_SOCKET.
+type SdNotifyMode string
+
+const (
+	// SdNotifyModeContainer passes the socket to the container, whose
+	// payload notifies the unit itself.
+	SdNotifyModeContainer SdNotifyMode = "container"
+	// SdNotifyModeConmon notifies the unit once the container is started,
+	// with conmon as the main process of the unit. The socket is not passed
+	// to the container.
+	SdNotifyModeConmon SdNotifyMode = "conmon"
+	// SdNotifyModeIgnore neither passes the socket to the container nor
+	// notifies the unit.
+	SdNotifyModeIgnore SdNotifyMode = "ignore"
+)
+
+// StringToSdNotifyMode converts a string representation of a notify mode into
+// an SdNotifyMode.
+func StringToSdNotifyMode(mode string) (SdNotifyMode, error) {
+	switch SdNotifyMode(mode) {
+	case SdNotifyModeContainer, SdNotifyModeConmon, SdNotifyModeIgnore:
+		return SdNotifyMode(mode), nil
+	default:
+		return "", errors.Wrapf(ErrInvalidArg, "unknown sdnotify mode %q, must be %q, %q or %q", mode, SdNotifyModeContainer, SdNotifyModeConmon, SdNotifyModeIgnore)
+	}
+}
@@ -0,0 +1,131 @@
+// +build linux
+
+package libpod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// dnsCacheAddress is the address the DNS cache of a container listens on in
+// its network namespace.
+const dnsCacheAddress = "127.0.0.1"
+
+// dnsCachePidFile returns the path of the PID file of the container's DNS
+// cache.
+func (c *Container) dnsCachePidFile() string {
+	return filepath.Join(c.state.RunDir, "dns-cache.pid")
+}
+
+// dnsCacheLogPath returns the path of the file dnsmasq's output is written to.
+func (c *Container) dnsCacheLogPath() string {
+	return filepath.Join(c.state.RunDir, "dns-cache.log")
+}
+
+// startDNSCache starts a caching DNS resolver in the container's network
+// namespace, forwarding queries to the given upstream servers. A resolver
+// already running for the container is stopped first.
+func (c *Container) startDNSCache(upstream []string) error {
+	if c.state.NetNS == nil {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s has no network namespace to run a DNS cache in", c.ID())
+	}
+	if len(upstream) == 0 {
+		return errors.Errorf("no upstream DNS servers found for the DNS cache of container %s", c.ID())
+	}
+
+	if err := c.stopDNSCache(); err != nil {
+		return err
+	}
+
+	path := c.runtime.config.DNSCacheCmdPath
+	if path == "" {
+		var err error
+		path, err = exec.LookPath("dnsmasq")
+		if err != nil {
+			return errors.Wrapf(err, "could not find dnsmasq to run the DNS cache of container %s", c.ID())
+		}
+	}
+
+	args := []string{
+		"--conf-file=/dev/null",
+		"--no-resolv",
+		"--no-hosts",
+		"--bind-interfaces",
+		"--listen-address=" + dnsCacheAddress,
+		"--pid-file=" + c.dnsCachePidFile(),
+	}
+	for _, server := range upstream {
+		args = append(args, "--server="+server)
+	}
+
+	logFile, err := os.OpenFile(c.dnsCacheLogPath(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "error creating DNS cache log file for container %s", c.ID())
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(path, args...)
+	// Pass the file directly so we do not wait on dnsmasq's daemon process
+	// closing a pipe.
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	logrus.Debugf("Starting DNS cache for container %s: %s %s", c.ID(), path, strings.Join(args, " "))
+
+	// dnsmasq daemonizes once it is listening, so this returns when the
+	// cache is ready. It must be started from a thread in the container's
+	// network namespace.
+	return c.state.NetNS.Do(func(_ ns.NetNS) error {
+		if err := cmd.Run(); err != nil {
+			output, _ := ioutil.ReadFile(c.dnsCacheLogPath())
+			return errors.Wrapf(err, "error starting DNS cache for container %s: %s", c.ID(), strings.TrimSpace(string(output)))
+		}
+		return nil
+	})
+}
+
+// stopDNSCache stops the container's DNS cache, if it is running.
+func (c *Container) stopDNSCache() error {
+	if c.state.RunDir == "" {
+		return nil
+	}
+
+	pidFile := c.dnsCachePidFile()
+	contents, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "error reading DNS cache PID file for container %s", c.ID())
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return errors.Wrapf(err, "error parsing DNS cache PID file for container %s", c.ID())
+	}
+
+	// Make sure the PID was not reused by another process before killing
+	// it - dnsmasq was given the path of the PID file on its command line.
+	cmdline, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil && strings.Contains(string(cmdline), pidFile) {
+		if err := unix.Kill(pid, unix.SIGTERM); err != nil && err != unix.ESRCH {
+			return errors.Wrapf(err, "error stopping DNS cache for container %s", c.ID())
+		}
+	}
+
+	if err := os.Remove(pidFile); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error removing DNS cache PID file for container %s", c.ID())
+	}
+
+	return nil
+}
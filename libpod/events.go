@@ -11,8 +11,10 @@ import (
 // newEventer returns an eventer that can be used to read/write events
 func (r *Runtime) newEventer() (events.Eventer, error) {
 	options := events.EventerOptions{
-		EventerType: r.config.EventsLogger,
-		LogFilePath: r.config.EventsLogFilePath,
+		EventerType:    r.config.EventsLogger,
+		LogFilePath:    r.config.EventsLogFilePath,
+		LogFileMaxSize: r.config.EventsLogFileMaxSize,
+		WebhookURLs:    r.config.EventsWebhookURLs,
 	}
 	return events.NewEventer(options)
 }
@@ -29,6 +31,20 @@ func (c *Container) newContainerEvent(status events.Status) {
 	}
 }
 
+// newHealthStatusEvent creates a new event for a change of the health status of
+// a container
+func (c *Container) newHealthStatusEvent(status string) {
+	e := events.NewEvent(events.HealthStatus)
+	e.ID = c.ID()
+	e.Name = c.Name()
+	e.Image = c.config.RootfsImageName
+	e.Type = events.Container
+	e.Attributes = map[string]string{"health_status": status}
+	if err := c.runtime.eventer.Write(e); err != nil {
+		logrus.Errorf("unable to write pod event: %q", err)
+	}
+}
+
 // newContainerExitedEvent creates a new event for a container's death
 func (c *Container) newContainerExitedEvent(exitCode int32) {
 	e := events.NewEvent(events.Exited)
@@ -37,6 +53,9 @@ func (c *Container) newContainerExitedEvent(exitCode int32) {
 	e.Image = c.config.RootfsImageName
 	e.Type = events.Container
 	e.ContainerExitCode = int(exitCode)
+	if c.state.ExitReport != nil {
+		e.Attributes = c.state.ExitReport.eventAttributes()
+	}
 	if err := c.runtime.eventer.Write(e); err != nil {
 		logrus.Errorf("unable to write pod event: %q", err)
 	}
@@ -76,11 +95,7 @@ func (v *Volume) newVolumeEvent(status events.Status) {
 // Events is a wrapper function for everyone to begin tailing the events log
 // with options
 func (r *Runtime) Events(options events.ReadOptions) error {
-	eventer, err := r.newEventer()
-	if err != nil {
-		return err
-	}
-	return eventer.Read(options)
+	return r.eventer.Read(options)
 }
 
 // GetEvents reads the event log and returns events based on input filters
@@ -96,12 +111,8 @@ func (r *Runtime) GetEvents(filters []string) ([]*events.Event, error) {
 		FromStart:    true,
 		Stream:       false,
 	}
-	eventer, err := r.newEventer()
-	if err != nil {
-		return nil, err
-	}
 	go func() {
-		readErr = eventer.Read(options)
+		readErr = r.eventer.Read(options)
 	}()
 	if readErr != nil {
 		return nil, readErr
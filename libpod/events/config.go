@@ -20,6 +20,9 @@ const (
 
 // Event describes the attributes of a libpod event
 type Event struct {
+	// Attributes holds additional details of the event, such as the
+	// resources used by a container that exited
+	Attributes map[string]string `json:",omitempty"`
 	// ContainerExitCode is for storing the exit code of a container which can
 	// be used for "internal" event notification
 	ContainerExitCode int `json:",omitempty"`
@@ -45,6 +48,13 @@ type EventerOptions struct {
 	// LogFilePath is the path to where the log file should reside if using
 	// the file logger
 	LogFilePath string
+	// LogFileMaxSize is the size in bytes the log file may grow to before
+	// it is rotated. The rotated events are kept in a single backup file,
+	// replacing those of the previous rotation. Unlimited if not positive.
+	LogFileMaxSize int64
+	// WebhookURLs are endpoints events are also posted to, whatever the
+	// type of the eventer
+	WebhookURLs []string
 }
 
 // Eventer is the interface for journald or file event logging
@@ -96,6 +106,9 @@ const (
 	// Volume - event is related to volumes
 	Volume Type = "volume"
 
+	// Adopt indicates that a container created by another tool was
+	// adopted from the OCI runtime
+	Adopt Status = "adopt"
 	// Attach ...
 	Attach Status = "attach"
 	// Checkpoint ...
@@ -112,6 +125,11 @@ const (
 	Exited Status = "died"
 	// Export ...
 	Export Status = "export"
+	// Freeze indicates that a container was stopped with SIGSTOP because of
+	// host memory pressure
+	Freeze Status = "freeze"
+	// HealthStatus indicates that the health status of a container changed
+	HealthStatus Status = "health_status"
 	// History ...
 	History Status = "history"
 	// Import ...
@@ -124,6 +142,11 @@ const (
 	LoadFromArchive Status = "loadfromarchive"
 	// Mount ...
 	Mount Status = "mount"
+	// NetworkConnect indicates that a container was connected to a network
+	NetworkConnect Status = "connect"
+	// NetworkDisconnect indicates that a container was disconnected from a
+	// network
+	NetworkDisconnect Status = "disconnect"
 	// Pause ...
 	Pause Status = "pause"
 	// Prune ...
@@ -135,6 +158,8 @@ const (
 	// Refresh indicates that the system refreshed the state after a
 	// reboot.
 	Refresh Status = "refresh"
+	// Reload indicates that the runtime configuration was reloaded.
+	Reload Status = "reload"
 	// Remove ...
 	Remove Status = "remove"
 	// Renumber indicates that lock numbers were reallocated at user
@@ -156,10 +181,16 @@ const (
 	Tag Status = "tag"
 	// Unmount ...
 	Unmount Status = "unmount"
+	// Unfreeze indicates that a container stopped because of memory
+	// pressure was resumed
+	Unfreeze Status = "unfreeze"
 	// Unpause ...
 	Unpause Status = "unpause"
 	// Untag ...
 	Untag Status = "untag"
+	// Update indicates that the resource limits of a container were
+	// changed
+	Update Status = "update"
 )
 
 // EventFilter for filtering events
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hpcloud/tail"
@@ -69,7 +71,7 @@ func (e *Event) ToHumanReadable() string {
 	var humanFormat string
 	switch e.Type {
 	case Container, Pod:
-		humanFormat = fmt.Sprintf("%s %s %s %s (image=%s, name=%s)", e.Time, e.Type, e.Status, e.ID, e.Image, e.Name)
+		humanFormat = fmt.Sprintf("%s %s %s %s (image=%s, name=%s%s)", e.Time, e.Type, e.Status, e.ID, e.Image, e.Name, e.humanReadableAttributes())
 	case Image:
 		humanFormat = fmt.Sprintf("%s %s %s %s %s", e.Time, e.Type, e.Status, e.ID, e.Name)
 	case System:
@@ -80,6 +82,21 @@ func (e *Event) ToHumanReadable() string {
 	return humanFormat
 }
 
+// humanReadableAttributes returns the attributes of the event sorted by key,
+// each prefixed with a comma
+func (e *Event) humanReadableAttributes() string {
+	keys := make([]string, 0, len(e.Attributes))
+	for key := range e.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var attributes strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&attributes, ", %s=%s", key, e.Attributes[key])
+	}
+	return attributes.String()
+}
+
 // NewEventFromString takes stringified json and converts
 // it to an event
 func newEventFromJSONString(event string) (*Event, error) {
@@ -125,6 +142,8 @@ func StringToType(name string) (Type, error) {
 // create the switch statement
 func StringToStatus(name string) (Status, error) {
 	switch name {
+	case Adopt.String():
+		return Adopt, nil
 	case Attach.String():
 		return Attach, nil
 	case Checkpoint.String():
@@ -143,6 +162,10 @@ func StringToStatus(name string) (Status, error) {
 		return Exited, nil
 	case Export.String():
 		return Export, nil
+	case Freeze.String():
+		return Freeze, nil
+	case HealthStatus.String():
+		return HealthStatus, nil
 	case History.String():
 		return History, nil
 	case Import.String():
@@ -155,6 +178,10 @@ func StringToStatus(name string) (Status, error) {
 		return LoadFromArchive, nil
 	case Mount.String():
 		return Mount, nil
+	case NetworkConnect.String():
+		return NetworkConnect, nil
+	case NetworkDisconnect.String():
+		return NetworkDisconnect, nil
 	case Pause.String():
 		return Pause, nil
 	case Prune.String():
@@ -165,6 +192,8 @@ func StringToStatus(name string) (Status, error) {
 		return Push, nil
 	case Refresh.String():
 		return Refresh, nil
+	case Reload.String():
+		return Reload, nil
 	case Remove.String():
 		return Remove, nil
 	case Renumber.String():
@@ -185,24 +214,27 @@ func StringToStatus(name string) (Status, error) {
 		return Tag, nil
 	case Unmount.String():
 		return Unmount, nil
+	case Unfreeze.String():
+		return Unfreeze, nil
 	case Unpause.String():
 		return Unpause, nil
 	case Untag.String():
 		return Untag, nil
+	case Update.String():
+		return Update, nil
 	}
 	return "", errors.Errorf("unknown event status %q", name)
 }
 
 func (e EventLogFile) getTail(options ReadOptions) (*tail.Tail, error) {
-	reopen := true
 	seek := tail.SeekInfo{Offset: 0, Whence: os.SEEK_END}
 	if options.FromStart || !options.Stream {
 		seek.Whence = 0
-		reopen = false
 	}
 	stream := options.Stream
 	if len(options.Until) > 0 {
 		stream = false
 	}
-	return tail.TailFile(e.options.LogFilePath, tail.Config{ReOpen: reopen, Follow: stream, Location: &seek, Logger: tail.DiscardingLogger})
+	// A followed log file is reopened once rotated
+	return tail.TailFile(e.options.LogFilePath, tail.Config{ReOpen: stream, Follow: stream, Location: &seek, Logger: tail.DiscardingLogger})
 }
@@ -23,5 +23,8 @@ func NewEventer(options EventerOptions) (eventer Eventer, err error) {
 	default:
 		return nil, errors.Errorf("unknown event logger type: %s", strings.ToUpper(options.EventerType))
 	}
+	if len(options.WebhookURLs) > 0 {
+		eventer = newEventWebhook(eventer, options.WebhookURLs)
+	}
 	return eventer, nil
 }
@@ -5,6 +5,7 @@ package events
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/coreos/go-systemd/journal"
@@ -16,6 +17,9 @@ import (
 // DefaultEventerType is journald when systemd is available
 const DefaultEventerType = Journald
 
+// journalAttributePrefix prefixes the journal fields holding event attributes
+const journalAttributePrefix = "PODMAN_ATTR_"
+
 // EventJournalD is the journald implementation of an eventer
 type EventJournalD struct {
 	options EventerOptions
@@ -46,6 +50,9 @@ func (e EventJournalD) Write(ee Event) error {
 		if ee.ContainerExitCode != 0 {
 			m["PODMAN_EXIT_CODE"] = strconv.Itoa(ee.ContainerExitCode)
 		}
+		for key, value := range ee.Attributes {
+			m[journalAttributePrefix+strings.ToUpper(key)] = value
+		}
 	case Volume:
 		m["PODMAN_NAME"] = ee.Name
 	}
@@ -167,6 +174,14 @@ func newEventFromJournalEntry(entry *sdjournal.JournalEntry) (*Event, error) { /
 				newEvent.ContainerExitCode = intCode
 			}
 		}
+		for field, value := range entry.Fields {
+			if strings.HasPrefix(field, journalAttributePrefix) {
+				if newEvent.Attributes == nil {
+					newEvent.Attributes = make(map[string]string)
+				}
+				newEvent.Attributes[strings.ToLower(strings.TrimPrefix(field, journalAttributePrefix))] = value
+			}
+		}
 	case Image:
 		newEvent.ID = entry.Fields["PODMAN_ID"]
 	}
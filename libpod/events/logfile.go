@@ -1,6 +1,7 @@
 package events
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 
@@ -23,52 +24,118 @@ func (e EventLogFile) Write(ee Event) error {
 	}
 	lock.Lock()
 	defer lock.Unlock()
-	f, err := os.OpenFile(e.options.LogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0700)
+	eventJSONString, err := ee.ToJSONString()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	eventJSONString, err := ee.ToJSONString()
+	line := fmt.Sprintf("%s\n", eventJSONString)
+	if err := e.rotate(int64(len(line))); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(e.options.LogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0700)
 	if err != nil {
 		return err
 	}
-	if _, err := f.WriteString(fmt.Sprintf("%s\n", eventJSONString)); err != nil {
+	defer f.Close()
+	if _, err := f.WriteString(line); err != nil {
 		return err
 	}
 	return nil
 
 }
 
+// rotatedPath returns the path of the backup of the rotated log file
+func (e EventLogFile) rotatedPath() string {
+	return e.options.LogFilePath + ".1"
+}
+
+// rotate moves the log file to its backup if writing size more bytes would
+// grow it past its maximum size. The log file must be locked.
+func (e EventLogFile) rotate(size int64) error {
+	if e.options.LogFileMaxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(e.options.LogFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Size() == 0 || info.Size()+size <= e.options.LogFileMaxSize {
+		return nil
+	}
+	if err := os.Rename(e.options.LogFilePath, e.rotatedPath()); err != nil {
+		return errors.Wrapf(err, "error rotating events log file %s", e.options.LogFilePath)
+	}
+	return nil
+}
+
 // Reads from the log file
 func (e EventLogFile) Read(options ReadOptions) error {
 	eventOptions, err := generateEventOptions(options.Filters, options.Since, options.Until)
 	if err != nil {
 		return errors.Wrapf(err, "unable to generate event options")
 	}
+	defer close(options.EventChannel)
+	// Events read from the start include those of the rotated log file
+	if options.FromStart || !options.Stream {
+		if err := e.readRotated(eventOptions, options.EventChannel); err != nil {
+			return err
+		}
+	}
 	t, err := e.getTail(options)
 	if err != nil {
 		return err
 	}
 	for line := range t.Lines {
-		event, err := newEventFromJSONString(line.Text)
-		if err != nil {
+		if err := e.sendEvent(line.Text, eventOptions, options.EventChannel); err != nil {
 			return err
 		}
-		switch event.Type {
-		case Image, Volume, Pod, System, Container:
-		//	no-op
-		default:
-			return errors.Errorf("event type %s is not valid in %s", event.Type.String(), e.options.LogFilePath)
-		}
-		include := true
-		for _, filter := range eventOptions {
-			include = include && filter(event)
+	}
+	return nil
+}
+
+// readRotated sends the events of the rotated log file to the channel, if it
+// exists
+func (e EventLogFile) readRotated(eventOptions []EventFilter, eventChannel chan *Event) error {
+	f, err := os.Open(e.rotatedPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
 		}
-		if include {
-			options.EventChannel <- event
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if err := e.sendEvent(scanner.Text(), eventOptions, eventChannel); err != nil {
+			return err
 		}
 	}
-	close(options.EventChannel)
+	return scanner.Err()
+}
+
+// sendEvent parses the event from a line of the log file, and sends it to the
+// channel if it passes the filters
+func (e EventLogFile) sendEvent(line string, eventOptions []EventFilter, eventChannel chan *Event) error {
+	event, err := newEventFromJSONString(line)
+	if err != nil {
+		return err
+	}
+	switch event.Type {
+	case Image, Volume, Pod, System, Container:
+	//	no-op
+	default:
+		return errors.Errorf("event type %s is not valid in %s", event.Type.String(), e.options.LogFilePath)
+	}
+	include := true
+	for _, filter := range eventOptions {
+		include = include && filter(event)
+	}
+	if include {
+		eventChannel <- event
+	}
 	return nil
 }
 
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLogFile returns an events log file in a temporary directory, removed
+// by the returned function
+func newTestLogFile(t *testing.T, maxSize int64) (EventLogFile, func()) {
+	dir, err := ioutil.TempDir("", "events")
+	require.NoError(t, err)
+	return EventLogFile{options: EventerOptions{
+		LogFilePath:    filepath.Join(dir, "events.log"),
+		LogFileMaxSize: maxSize,
+	}}, func() { os.RemoveAll(dir) }
+}
+
+// testEventTime is the time of the events written by writeTestEvent. It is
+// fixed so that every line of the log file has the same size: the encoding of
+// the current time drops trailing zeros of its nanoseconds.
+var testEventTime = time.Date(2019, time.January, 2, 3, 4, 5, 123456789, time.UTC)
+
+// writeTestEvent writes a container event with the given ID
+func writeTestEvent(t *testing.T, e EventLogFile, id string) {
+	event := NewEvent(Start)
+	event.Type = Container
+	event.ID = id
+	event.Time = testEventTime
+	require.NoError(t, e.Write(event))
+}
+
+// receiveIDs returns the IDs of the next n events of the channel
+func receiveIDs(t *testing.T, eventChannel chan *Event, n int) []string {
+	var ids []string
+	for len(ids) < n {
+		select {
+		case event, ok := <-eventChannel:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("received %d events out of %d", len(ids), n)
+		}
+	}
+	return ids
+}
+
+// eventLineSize returns the size of a line of the log file holding an event
+// written by writeTestEvent
+func eventLineSize(t *testing.T) int64 {
+	e, cleanup := newTestLogFile(t, 0)
+	defer cleanup()
+	writeTestEvent(t, e, "size")
+	info, err := os.Stat(e.options.LogFilePath)
+	require.NoError(t, err)
+	return info.Size()
+}
+
+func TestLogFileRotate(t *testing.T) {
+	// The log file holds two events
+	e, cleanup := newTestLogFile(t, 2*eventLineSize(t))
+	defer cleanup()
+
+	writeTestEvent(t, e, "0001")
+	writeTestEvent(t, e, "0002")
+	_, err := os.Stat(e.rotatedPath())
+	assert.True(t, os.IsNotExist(err))
+
+	// The log file is rotated before growing past its maximum size, the
+	// backup replacing any previous one
+	for _, id := range []string{"0003", "0004", "0005"} {
+		writeTestEvent(t, e, id)
+		info, err := os.Stat(e.options.LogFilePath)
+		require.NoError(t, err)
+		assert.True(t, info.Size() <= e.options.LogFileMaxSize)
+	}
+	data, err := ioutil.ReadFile(e.rotatedPath())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "0003")
+	assert.Contains(t, string(data), "0004")
+	assert.NotContains(t, string(data), "0001")
+}
+
+func TestLogFileReadIncludesRotated(t *testing.T) {
+	e, cleanup := newTestLogFile(t, 2*eventLineSize(t))
+	defer cleanup()
+
+	for _, id := range []string{"0001", "0002", "0003"} {
+		writeTestEvent(t, e, id)
+	}
+
+	eventChannel := make(chan *Event)
+	go func() {
+		assert.NoError(t, e.Read(ReadOptions{EventChannel: eventChannel}))
+	}()
+	assert.Equal(t, []string{"0001", "0002", "0003"}, receiveIDs(t, eventChannel, 4))
+}
+
+func TestLogFileStreamFromStartFollowsRotation(t *testing.T) {
+	e, cleanup := newTestLogFile(t, 2*eventLineSize(t))
+	defer cleanup()
+
+	writeTestEvent(t, e, "0001")
+	eventChannel := make(chan *Event)
+	go func() {
+		_ = e.Read(ReadOptions{EventChannel: eventChannel, FromStart: true, Stream: true})
+	}()
+	assert.Equal(t, []string{"0001"}, receiveIDs(t, eventChannel, 1))
+
+	// Events written once the log file is rotated are still followed
+	for _, id := range []string{"0002", "0003", "0004"} {
+		writeTestEvent(t, e, id)
+		time.Sleep(100 * time.Millisecond)
+	}
+	_, err := os.Stat(e.rotatedPath())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0002", "0003", "0004"}, receiveIDs(t, eventChannel, 3))
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// webhookAttempts is the number of times an event is posted to an
+	// endpoint before giving up on it
+	webhookAttempts = 4
+	// webhookBackoffInitial is the delay before the first retry of a
+	// failed post. Each further retry waits twice as long as the previous.
+	webhookBackoffInitial = 250 * time.Millisecond
+	// webhookTimeout bounds each post to an endpoint
+	webhookTimeout = 5 * time.Second
+	// webhookQueueSize is the number of events waiting to be posted
+	// before further events are dropped
+	webhookQueueSize = 256
+	// webhookDrainTimeout bounds how long Close waits for queued events
+	// to be posted
+	webhookDrainTimeout = 5 * time.Second
+)
+
+// EventWebhook is an eventer that logs events to another eventer, and also
+// posts them as JSON to HTTP endpoints in the background
+type EventWebhook struct {
+	Eventer
+	urls   []string
+	client *http.Client
+	queue  chan string
+	done   chan struct{}
+
+	lock   sync.Mutex
+	closed bool
+}
+
+// newEventWebhook wraps an eventer so that events written to it are also
+// posted to the given endpoints
+func newEventWebhook(eventer Eventer, urls []string) *EventWebhook {
+	e := &EventWebhook{
+		Eventer: eventer,
+		urls:    urls,
+		client:  &http.Client{Timeout: webhookTimeout},
+		queue:   make(chan string, webhookQueueSize),
+		done:    make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// Write logs the event to the wrapped eventer, and queues it to be posted
+// to the endpoints. Write never waits for the endpoints: if the queue is
+// full, the event is not posted.
+func (e *EventWebhook) Write(ee Event) error {
+	err := e.Eventer.Write(ee)
+
+	eventJSONString, jsonErr := ee.ToJSONString()
+	if jsonErr != nil {
+		logrus.Errorf("error encoding event for webhook: %v", jsonErr)
+		return err
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.closed {
+		logrus.Errorf("Dropping event %s %s for webhook: eventer is closed", ee.Type, ee.Status)
+		return err
+	}
+	select {
+	case e.queue <- eventJSONString:
+	default:
+		logrus.Errorf("Dropping event %s %s for webhook: %d events are already waiting to be posted", ee.Type, ee.Status, webhookQueueSize)
+	}
+	return err
+}
+
+// Close stops queueing events, and waits a bounded time for the queued
+// events to be posted
+func (e *EventWebhook) Close() error {
+	e.lock.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.lock.Unlock()
+
+	select {
+	case <-e.done:
+	case <-time.After(webhookDrainTimeout):
+		logrus.Errorf("Timed out posting events to webhooks, %d events were dropped", len(e.queue))
+	}
+	return nil
+}
+
+// run posts queued events to every endpoint, in order, until the queue is
+// closed
+func (e *EventWebhook) run() {
+	defer close(e.done)
+	for body := range e.queue {
+		for _, url := range e.urls {
+			if err := e.post(url, []byte(body)); err != nil {
+				logrus.Errorf("%v", err)
+			}
+		}
+	}
+}
+
+// post sends the event to a single endpoint. Connection failures and server
+// errors are retried, while other responses are final.
+func (e *EventWebhook) post(url string, body []byte) error {
+	delay := webhookBackoffInitial
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		var retry bool
+		if retry, err = e.postOnce(url, body); err == nil || !retry {
+			break
+		}
+		if attempt < webhookAttempts {
+			logrus.Debugf("Retrying post of event to %s in %s: %v", url, delay, err)
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return errors.Wrapf(err, "error posting event to %s", url)
+}
+
+// postOnce sends the event to an endpoint, and reports whether a failed post
+// is worth retrying
+func (e *EventWebhook) postOnce(url string, body []byte) (bool, error) {
+	resp, err := e.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return true, errors.Errorf("endpoint returned %s", resp.Status)
+	case resp.StatusCode >= 300:
+		return false, errors.Errorf("endpoint returned %s", resp.Status)
+	}
+	return false, nil
+}
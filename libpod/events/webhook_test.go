@@ -0,0 +1,135 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEventer is an eventer remembering the events written to it
+type recordingEventer struct {
+	EventToNull
+	written []Event
+}
+
+func (e *recordingEventer) Write(ee Event) error {
+	e.written = append(e.written, ee)
+	return nil
+}
+
+func (e *recordingEventer) Read(options ReadOptions) error {
+	defer close(options.EventChannel)
+	for i := range e.written {
+		options.EventChannel <- &e.written[i]
+	}
+	return nil
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	var posts int
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		if posts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	eventer := newEventWebhook(&recordingEventer{}, []string{server.URL})
+	event := NewEvent(Start)
+	event.Type = Container
+	event.ID = "abc"
+	require.NoError(t, eventer.Write(event))
+	require.NoError(t, eventer.Close())
+	assert.Equal(t, 2, posts)
+	assert.Equal(t, Start, received.Status)
+	assert.Equal(t, "abc", received.ID)
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	eventer := newEventWebhook(&recordingEventer{}, []string{server.URL})
+	assert.NoError(t, eventer.Write(NewEvent(Refresh)))
+	require.NoError(t, eventer.Close())
+	assert.Equal(t, 1, posts)
+}
+
+func TestWebhookWritesAndReadsWrappedEventer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	backend := &recordingEventer{}
+	eventer := newEventWebhook(backend, []string{server.URL})
+	require.NoError(t, eventer.Write(NewEvent(Refresh)))
+	require.NoError(t, eventer.Close())
+	require.Len(t, backend.written, 1)
+
+	eventChannel := make(chan *Event)
+	go func() {
+		assert.NoError(t, eventer.Read(ReadOptions{EventChannel: eventChannel}))
+	}()
+	var read []*Event
+	for e := range eventChannel {
+		read = append(read, e)
+	}
+	require.Len(t, read, 1)
+	assert.Equal(t, Refresh, read[0].Status)
+	assert.Equal(t, "none", eventer.String())
+}
+
+func TestWebhookDropsEventsWhenQueueIsFull(t *testing.T) {
+	var lock sync.Mutex
+	var posts int
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+		lock.Lock()
+		posts++
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	backend := &recordingEventer{}
+	eventer := newEventWebhook(backend, []string{server.URL})
+	// One event is being posted, and the queue is filled behind it
+	total := webhookQueueSize + 10
+	for i := 0; i < total; i++ {
+		require.NoError(t, eventer.Write(NewEvent(Refresh)))
+	}
+	close(blocked)
+	require.NoError(t, eventer.Close())
+
+	assert.Len(t, backend.written, total)
+	assert.True(t, posts < total)
+	assert.True(t, posts >= webhookQueueSize)
+}
+
+func TestWebhookDropsEventsAfterClose(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+	}))
+	defer server.Close()
+
+	backend := &recordingEventer{}
+	eventer := newEventWebhook(backend, []string{server.URL})
+	require.NoError(t, eventer.Close())
+	require.NoError(t, eventer.Write(NewEvent(Refresh)))
+	assert.Len(t, backend.written, 1)
+	assert.Equal(t, 0, posts)
+}
@@ -0,0 +1,46 @@
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/containers/storage"
+)
+
+// Test helpers exported for the tests of the libpod_test package, which cannot
+// build containers, pods and states themselves.
+
+// NewTestBoltState opens the BoltDB state at the given path for a runtime
+// using the given lock manager.
+func NewTestBoltState(path string, manager lock.Manager) (State, error) {
+	runtime := new(Runtime)
+	runtime.config = new(RuntimeConfig)
+	runtime.config.StorageConfig = storage.StoreOptions{}
+	runtime.lockManager = manager
+
+	return NewBoltState(path, runtime)
+}
+
+// NewTestContainer returns a container with the given ID and name, in the
+// given pod if it is not empty, and depending on the given containers.
+// Its lock is freed once allocated, so containers share locks rather than
+// exhausting the lock manager; they must not be locked.
+func NewTestContainer(id, name, pod string, deps []string, manager lock.Manager) (*Container, error) {
+	ctr, err := getTestContainer(id, name, manager)
+	if err != nil {
+		return nil, err
+	}
+	ctr.config.Pod = pod
+	ctr.config.Dependencies = deps
+
+	return ctr, ctr.lock.Free()
+}
+
+// NewTestPod returns a pod with the given ID and name. As for
+// NewTestContainer, its lock is freed once allocated.
+func NewTestPod(id, name string, manager lock.Manager) (*Pod, error) {
+	pod, err := getTestPod(id, name, manager)
+	if err != nil {
+		return nil, err
+	}
+
+	return pod, pod.lock.Free()
+}
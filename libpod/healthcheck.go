@@ -3,10 +3,10 @@ package libpod
 import (
 	"bufio"
 	"bytes"
-	"io/ioutil"
+	"context"
 	"os"
-	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/containers/libpod/libpod/define"
@@ -53,6 +53,29 @@ const (
 	HealthCheckStarting string = "starting"
 )
 
+// Actions taken when a container becomes unhealthy
+const (
+	// HealthCheckOnFailureNone takes no action
+	HealthCheckOnFailureNone = "none"
+	// HealthCheckOnFailureRestart restarts the container
+	HealthCheckOnFailureRestart = "restart"
+	// HealthCheckOnFailureStop stops the container
+	HealthCheckOnFailureStop = "stop"
+	// HealthCheckOnFailureKill kills the container with SIGKILL
+	HealthCheckOnFailureKill = "kill"
+)
+
+// Delays applied between the actions taken on an unhealthy container.
+const (
+	// healthCheckActionBackoffInitial is the minimum delay between the
+	// first action taken on an unhealthy container and the next.
+	healthCheckActionBackoffInitial = 30 * time.Second
+	// healthCheckActionBackoffMax is the longest delay between actions.
+	// The delay doubles with each action until the container is healthy
+	// again, or it reaches this.
+	healthCheckActionBackoffMax = 10 * time.Minute
+)
+
 // HealthCheckResults describes the results/logs from a healthcheck
 type HealthCheckResults struct {
 	// Status healthy or unhealthy
@@ -176,12 +199,56 @@ func (c *Container) runHealthCheck() (HealthCheckStatus, error) {
 		hcErr = errors.Errorf("healthcheck command exceeded timeout of %s", c.HealthCheckConfig().Timeout.String())
 	}
 	hcl := newHealthCheckLog(timeStart, timeEnd, returnCode, eventLog)
-	if err := c.updateHealthCheckLog(hcl, inStartPeriod); err != nil {
-		return hcResult, errors.Wrapf(err, "unable to update health check log %s for %s", c.healthCheckLogPath(), c.ID())
+	takeAction, err := c.updateHealthCheckLog(hcl, inStartPeriod)
+	if err != nil {
+		return hcResult, errors.Wrapf(err, "unable to update health check log for %s", c.ID())
+	}
+	if takeAction {
+		if err := c.takeHealthCheckAction(); err != nil {
+			return hcResult, errors.Wrapf(err, "unable to take action %s on unhealthy container %s", c.HealthCheckOnFailure(), c.ID())
+		}
 	}
 	return hcResult, hcErr
 }
 
+// takeHealthCheckAction takes the action configured for the container becoming
+// unhealthy
+func (c *Container) takeHealthCheckAction() error {
+	logrus.Infof("Container %s is unhealthy, taking action %s", c.ID(), c.HealthCheckOnFailure())
+	switch c.HealthCheckOnFailure() {
+	case HealthCheckOnFailureRestart:
+		return c.RestartWithTimeout(context.Background(), c.StopTimeout())
+	case HealthCheckOnFailureStop:
+		return c.StopWithTimeout(c.StopTimeout())
+	case HealthCheckOnFailureKill:
+		return c.Kill(uint(syscall.SIGKILL))
+	}
+	return nil
+}
+
+// healthCheckActionDue returns whether the action configured for the container
+// becoming unhealthy must be taken now. After each action, the next one waits
+// twice as long as the previous one, starting at
+// healthCheckActionBackoffInitial, up to healthCheckActionBackoffMax.
+// The container must be locked and synced.
+func (c *Container) healthCheckActionDue() bool {
+	switch c.HealthCheckOnFailure() {
+	case "", HealthCheckOnFailureNone:
+		return false
+	}
+	if c.state.HealthCheckActionCount == 0 {
+		return true
+	}
+	backoff := healthCheckActionBackoffInitial
+	for i := uint(1); i < c.state.HealthCheckActionCount && backoff < healthCheckActionBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > healthCheckActionBackoffMax {
+		backoff = healthCheckActionBackoffMax
+	}
+	return time.Since(c.state.HealthCheckActionTime) >= backoff
+}
+
 func checkHealthCheckCanBeRun(c *Container) (HealthCheckStatus, error) {
 	cstate, err := c.State()
 	if err != nil {
@@ -205,27 +272,32 @@ func newHealthCheckLog(start, end time.Time, exitCode int, log string) HealthChe
 	}
 }
 
-// updatedHealthCheckStatus updates the health status of the container
-// in the healthcheck log
-func (c *Container) updateHealthStatus(status string) error {
-	healthCheck, err := c.GetHealthCheckLog()
-	if err != nil {
-		return err
-	}
-	healthCheck.Status = status
-	newResults, err := json.Marshal(healthCheck)
-	if err != nil {
-		return errors.Wrapf(err, "unable to marshall healthchecks for writing status")
-	}
-	return ioutil.WriteFile(c.healthCheckLogPath(), newResults, 0700)
+// resetHealthStatus marks the container as starting, with no failed
+// healthcheck yet. The container must be locked, and saved afterwards.
+func (c *Container) resetHealthStatus() {
+	healthCheck := c.healthCheckResults()
+	healthCheck.Status = HealthCheckStarting
+	healthCheck.FailingStreak = 0
+	c.state.HealthCheck = &healthCheck
 }
 
-// UpdateHealthCheckLog parses the health check results and writes the log
-func (c *Container) updateHealthCheckLog(hcl HealthCheckLog, inStartPeriod bool) error {
-	healthCheck, err := c.GetHealthCheckLog()
-	if err != nil {
-		return err
+// updateHealthCheckLog records the result of a healthcheck in the state of
+// the container, and updates its health status accordingly. An event is
+// written if the status changed. It returns whether the action configured for
+// the container becoming unhealthy must be taken, in which case it is recorded
+// as taken.
+func (c *Container) updateHealthCheckLog(hcl HealthCheckLog, inStartPeriod bool) (bool, error) {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return false, err
+		}
 	}
+
+	healthCheck := c.healthCheckResults()
+	oldStatus := healthCheck.Status
 	if hcl.ExitCode == 0 {
 		//	set status to healthy, reset failing state to 0
 		healthCheck.Status = HealthCheckHealthy
@@ -247,34 +319,51 @@ func (c *Container) updateHealthCheckLog(hcl HealthCheckLog, inStartPeriod bool)
 	if len(healthCheck.Log) > MaxHealthCheckNumberLogs {
 		healthCheck.Log = healthCheck.Log[1:]
 	}
-	newResults, err := json.Marshal(healthCheck)
-	if err != nil {
-		return errors.Wrapf(err, "unable to marshall healthchecks for writing")
+	c.state.HealthCheck = &healthCheck
+
+	takeAction := false
+	switch healthCheck.Status {
+	case HealthCheckHealthy:
+		c.state.HealthCheckActionCount = 0
+	case HealthCheckUnhealthy:
+		if takeAction = c.healthCheckActionDue(); takeAction {
+			c.state.HealthCheckActionCount++
+			c.state.HealthCheckActionTime = time.Now()
+		}
+	}
+	if err := c.save(); err != nil {
+		return false, err
+	}
+	if healthCheck.Status != oldStatus {
+		c.newHealthStatusEvent(healthCheck.Status)
 	}
-	return ioutil.WriteFile(c.healthCheckLogPath(), newResults, 0700)
+	return takeAction, nil
 }
 
-// HealthCheckLogPath returns the path for where the health check log is
-func (c *Container) healthCheckLogPath() string {
-	return filepath.Join(filepath.Dir(c.LogPath()), "healthcheck.log")
+// healthCheckResults returns a copy of the healthcheck results recorded in the
+// state of the container. The container must be locked and synced.
+func (c *Container) healthCheckResults() HealthCheckResults {
+	var healthCheck HealthCheckResults
+	if c.state.HealthCheck != nil {
+		healthCheck = *c.state.HealthCheck
+		healthCheck.Log = append([]HealthCheckLog{}, c.state.HealthCheck.Log...)
+	}
+	return healthCheck
 }
 
-// GetHealthCheckLog returns HealthCheck results by reading the container's
-// health check log file.  If the health check log file does not exist, then
-// an empty healthcheck struct is returned
+// GetHealthCheckLog returns the results of the last healthchecks of the
+// container, as recorded in its state. If no healthcheck was run yet, then an
+// empty healthcheck struct is returned
 func (c *Container) GetHealthCheckLog() (HealthCheckResults, error) {
-	var healthCheck HealthCheckResults
-	if _, err := os.Stat(c.healthCheckLogPath()); os.IsNotExist(err) {
-		return healthCheck, nil
-	}
-	b, err := ioutil.ReadFile(c.healthCheckLogPath())
-	if err != nil {
-		return healthCheck, errors.Wrapf(err, "failed to read health check log file %s", c.healthCheckLogPath())
-	}
-	if err := json.Unmarshal(b, &healthCheck); err != nil {
-		return healthCheck, errors.Wrapf(err, "failed to unmarshal existing healthcheck results in %s", c.healthCheckLogPath())
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return HealthCheckResults{}, err
+		}
 	}
-	return healthCheck, nil
+	return c.healthCheckResults(), nil
 }
 
 // HealthCheckStatus returns the current state of a container with a healthcheck
@@ -289,12 +378,14 @@ func (c *Container) HealthCheckStatus() (string, error) {
 	return results.Status, nil
 }
 
+// healthCheckScheduled returns whether the healthcheck of the container is run
+// periodically while it runs
+func (c *Container) healthCheckScheduled() bool {
+	return c.config.HealthCheckConfig != nil && c.config.HealthCheckConfig.Interval > 0
+}
+
+// disableHealthCheckSystemd returns whether the healthchecks of the container
+// must be scheduled internally rather than by systemd timers
 func (c *Container) disableHealthCheckSystemd() bool {
-	if os.Getenv("DISABLE_HC_SYSTEMD") == "true" {
-		return true
-	}
-	if c.config.HealthCheckConfig.Interval == 0 {
-		return true
-	}
-	return false
+	return os.Getenv("DISABLE_HC_SYSTEMD") == "true"
 }
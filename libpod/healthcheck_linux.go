@@ -2,17 +2,24 @@ package libpod
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/pkg/rootless"
+	"github.com/containers/storage/pkg/reexec"
+	"github.com/containers/storage/pkg/stringid"
 	"github.com/coreos/go-systemd/dbus"
 	godbus "github.com/godbus/dbus"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
 )
 
 func dbusAuthRootlessConnection(createBus func(opts ...godbus.ConnOption) (*godbus.Conn, error)) (*godbus.Conn, error) {
@@ -48,9 +55,47 @@ func getConnection() (*dbus.Conn, error) {
 	return dbus.NewSystemdConnection()
 }
 
-// createTimer systemd timers for healthchecks of a container
-func (c *Container) createTimer() error {
+// healthCheckSchedulerCommand is the name the internal healthcheck scheduler
+// is re-executed under
+const healthCheckSchedulerCommand = "libpod-healthcheck-scheduler"
+
+func init() {
+	reexec.Register(healthCheckSchedulerCommand, healthCheckSchedulerMain)
+}
+
+// healthCheckUsesSystemd returns whether the healthchecks of the container are
+// scheduled by systemd timers. If systemd cannot be reached, they are
+// scheduled internally instead.
+func (c *Container) healthCheckUsesSystemd() bool {
 	if c.disableHealthCheckSystemd() {
+		return false
+	}
+	conn, err := getConnection()
+	if err != nil {
+		logrus.Debugf("Unable to connect to systemd, scheduling healthchecks of container %s internally: %v", c.ID(), err)
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// healthCheckUnit returns the name of the systemd timer and service running
+// the healthchecks of the container. Containers initialized before the name
+// was recorded in their state use their ID.
+func (c *Container) healthCheckUnit() string {
+	if c.state.HealthCheckUnit != "" {
+		return c.state.HealthCheckUnit
+	}
+	return c.ID()
+}
+
+// createTimer systemd timers for healthchecks of a container.
+// The timer is given a new name, as the action taken on an unhealthy container
+// runs in the service of the previous timer, which is still active while the
+// container is restarted.
+// The container must be locked, and saved afterwards.
+func (c *Container) createTimer() error {
+	if !c.healthCheckScheduled() || !c.healthCheckUsesSystemd() {
 		return nil
 	}
 	podman, err := os.Executable()
@@ -58,44 +103,50 @@ func (c *Container) createTimer() error {
 		return errors.Wrapf(err, "failed to get path for podman for a health check timer")
 	}
 
+	unit := fmt.Sprintf("%s-%s", c.ID(), stringid.GenerateNonCryptoID()[:12])
 	var cmd = []string{}
 	if rootless.IsRootless() {
 		cmd = append(cmd, "--user")
 	}
-	cmd = append(cmd, "--unit", c.ID(), fmt.Sprintf("--on-unit-inactive=%s", c.HealthCheckConfig().Interval.String()), "--timer-property=AccuracySec=1s", podman, "healthcheck", "run", c.ID())
+	cmd = append(cmd, "--unit", unit, fmt.Sprintf("--on-unit-inactive=%s", c.HealthCheckConfig().Interval.String()), "--timer-property=AccuracySec=1s", podman, "healthcheck", "run", c.ID())
 
-	conn, err := getConnection()
-	if err != nil {
-		return errors.Wrapf(err, "unable to get systemd connection to add healthchecks")
-	}
-	conn.Close()
 	logrus.Debugf("creating systemd-transient files: %s %s", "systemd-run", cmd)
 	systemdRun := exec.Command("systemd-run", cmd...)
-	_, err = systemdRun.CombinedOutput()
-	if err != nil {
-		return err
+	if out, err := systemdRun.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "error creating healthcheck timer of container %s: %s", c.ID(), strings.TrimSpace(string(out)))
 	}
+	c.state.HealthCheckUnit = unit
 	return nil
 }
 
-// startTimer starts a systemd timer for the healthchecks
+// startTimer starts a systemd timer for the healthchecks, or the internal
+// scheduler if they are not scheduled by systemd
 func (c *Container) startTimer() error {
-	if c.disableHealthCheckSystemd() {
+	if !c.healthCheckScheduled() {
 		return nil
 	}
+	if !c.healthCheckUsesSystemd() {
+		return c.startHealthCheckScheduler()
+	}
 	conn, err := getConnection()
 	if err != nil {
 		return errors.Wrapf(err, "unable to get systemd connection to start healthchecks")
 	}
 	defer conn.Close()
-	_, err = conn.StartUnit(fmt.Sprintf("%s.service", c.ID()), "fail", nil)
-	return err
+	if _, err := conn.StartUnit(fmt.Sprintf("%s.service", c.healthCheckUnit()), "fail", nil); err != nil {
+		return errors.Wrapf(err, "error starting healthchecks of container %s", c.ID())
+	}
+	return nil
 }
 
 // removeTimer removes the systemd timer and unit files
-// for the container
+// for the container, or stops its internal scheduler.
+// The container must be locked, and saved afterwards.
 func (c *Container) removeTimer() error {
-	if c.disableHealthCheckSystemd() {
+	if c.state.HealthCheckSchedulerPID != 0 {
+		return c.stopHealthCheckScheduler()
+	}
+	if !c.healthCheckScheduled() || c.disableHealthCheckSystemd() {
 		return nil
 	}
 	conn, err := getConnection()
@@ -103,7 +154,8 @@ func (c *Container) removeTimer() error {
 		return errors.Wrapf(err, "unable to get systemd connection to remove healthchecks")
 	}
 	defer conn.Close()
-	timerFile := fmt.Sprintf("%s.timer", c.ID())
+	timerFile := fmt.Sprintf("%s.timer", c.healthCheckUnit())
+	c.state.HealthCheckUnit = ""
 	_, err = conn.StopUnit(timerFile, "fail", nil)
 
 	// We want to ignore errors where the timer unit has already been removed. The error
@@ -113,3 +165,129 @@ func (c *Container) removeTimer() error {
 	}
 	return err
 }
+
+// startHealthCheckScheduler starts the process running the healthchecks of
+// the container periodically. The process is detached, so it outlives this
+// one, and exits once the container is removed or stopped.
+// The container must be locked, and saved afterwards.
+func (c *Container) startHealthCheckScheduler() error {
+	if c.state.HealthCheckSchedulerPID != 0 {
+		if err := c.stopHealthCheckScheduler(); err != nil {
+			logrus.Errorf("Error stopping previous healthcheck scheduler of container %s: %v", c.ID(), err)
+		}
+	}
+	podman, err := os.Executable()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get path for podman for a health check scheduler")
+	}
+	cmd := reexec.Command(healthCheckSchedulerCommand, c.HealthCheckConfig().Interval.String(), podman, c.ID())
+	// Replace the parent death signal set by reexec
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrapf(err, "error starting healthcheck scheduler of container %s", c.ID())
+	}
+	c.state.HealthCheckSchedulerPID = cmd.Process.Pid
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
+
+// stopHealthCheckScheduler stops the internal healthcheck scheduler of the
+// container, if it still runs.
+// The container must be locked, and saved afterwards.
+func (c *Container) stopHealthCheckScheduler() error {
+	pid := c.state.HealthCheckSchedulerPID
+	c.state.HealthCheckSchedulerPID = 0
+
+	// The PID may have been reused since the scheduler exited
+	cmdline, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "error reading command line of healthcheck scheduler of container %s", c.ID())
+	}
+	args := strings.Split(string(cmdline), "\x00")
+	if len(args) < 4 || args[0] != healthCheckSchedulerCommand || args[3] != c.ID() {
+		return nil
+	}
+	if err := unix.Kill(pid, unix.SIGTERM); err != nil && err != unix.ESRCH {
+		return errors.Wrapf(err, "error stopping healthcheck scheduler of container %s", c.ID())
+	}
+	return nil
+}
+
+// healthCheckSchedulerMain is the entry point of the internal healthcheck
+// scheduler. Its arguments are the interval between healthchecks, the path of
+// podman, and the ID of the container.
+func healthCheckSchedulerMain() {
+	if err := runHealthCheckScheduler(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", healthCheckSchedulerCommand, err)
+		os.Exit(1)
+	}
+}
+
+// healthCheckPodmanFunc runs podman with the given arguments, and returns its
+// exit code and standard output. An error is returned only if podman could not
+// be run.
+type healthCheckPodmanFunc func(args ...string) (int, string, error)
+
+func runHealthCheckScheduler(args []string) error {
+	if len(args) != 3 {
+		return errors.Errorf("expected 3 arguments, got %d", len(args))
+	}
+	interval, err := time.ParseDuration(args[0])
+	if err != nil {
+		return errors.Wrapf(err, "invalid healthcheck interval %q", args[0])
+	}
+	podman := func(podmanArgs ...string) (int, string, error) {
+		out, err := exec.Command(args[1], podmanArgs...).Output()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), string(out), nil
+		}
+		return 0, string(out), err
+	}
+	for runScheduledHealthCheck(args[2], podman) {
+		time.Sleep(interval)
+	}
+	return nil
+}
+
+// runScheduledHealthCheck runs a healthcheck of the container, and returns
+// whether healthchecks must go on being scheduled. They stop only once the
+// container is gone or no longer runs: failures to run the healthcheck for
+// other reasons are retried at the next interval.
+func runScheduledHealthCheck(ctrID string, podman healthCheckPodmanFunc) bool {
+	exitCode, _, err := podman("healthcheck", "run", ctrID)
+	if err == nil && (exitCode == 0 || exitCode == 1) {
+		// The container is healthy or unhealthy
+		return true
+	}
+	if err != nil {
+		logrus.Errorf("Error running healthcheck of container %s: %v", ctrID, err)
+		return true
+	}
+
+	exitCode, _, err = podman("container", "exists", ctrID)
+	if err == nil && exitCode == 1 {
+		logrus.Debugf("Container %s was removed, no longer scheduling its healthchecks", ctrID)
+		return false
+	}
+	exitCode, out, err := podman("container", "inspect", "--format", "{{.State.Status}}", ctrID)
+	if err != nil || exitCode != 0 {
+		logrus.Errorf("Error retrieving state of container %s, retrying its healthcheck later", ctrID)
+		return true
+	}
+	status, err := define.StringToContainerStatus(strings.TrimSpace(out))
+	if err != nil {
+		logrus.Errorf("Error retrieving state of container %s, retrying its healthcheck later: %v", ctrID, err)
+		return true
+	}
+	switch status {
+	case define.ContainerStateRunning, define.ContainerStatePaused:
+		return true
+	}
+	logrus.Debugf("Container %s is %s, no longer scheduling its healthchecks", ctrID, status)
+	return false
+}
@@ -0,0 +1,62 @@
+package libpod
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHealthCheckPodman returns a podman running healthchecks that exit with
+// the given code, for a container in the given state. An empty state means the
+// container does not exist.
+func fakeHealthCheckPodman(healthCheckExitCode int, ctrState string) healthCheckPodmanFunc {
+	return func(args ...string) (int, string, error) {
+		switch strings.Join(args[:2], " ") {
+		case "healthcheck run":
+			return healthCheckExitCode, "", nil
+		case "container exists":
+			if ctrState == "" {
+				return 1, "", nil
+			}
+			return 0, "", nil
+		case "container inspect":
+			if ctrState == "" {
+				return 125, "", nil
+			}
+			return 0, ctrState + "\n", nil
+		}
+		return 0, "", errors.Errorf("unexpected command %v", args)
+	}
+}
+
+func TestRunScheduledHealthCheck(t *testing.T) {
+	// Healthy and unhealthy containers go on being checked
+	assert.True(t, runScheduledHealthCheck("abc", fakeHealthCheckPodman(0, "running")))
+	assert.True(t, runScheduledHealthCheck("abc", fakeHealthCheckPodman(1, "running")))
+
+	// Failures to run the healthcheck of a running or paused container
+	// are retried
+	assert.True(t, runScheduledHealthCheck("abc", fakeHealthCheckPodman(125, "running")))
+	assert.True(t, runScheduledHealthCheck("abc", fakeHealthCheckPodman(125, "paused")))
+	assert.True(t, runScheduledHealthCheck("abc", func(args ...string) (int, string, error) {
+		return 0, "", errors.New("cannot run podman")
+	}))
+
+	// Removed and stopped containers are no longer checked
+	assert.False(t, runScheduledHealthCheck("abc", fakeHealthCheckPodman(125, "")))
+	assert.False(t, runScheduledHealthCheck("abc", fakeHealthCheckPodman(125, "exited")))
+	assert.False(t, runScheduledHealthCheck("abc", fakeHealthCheckPodman(125, "stopped")))
+}
+
+func TestHealthCheckUnit(t *testing.T) {
+	c := Container{
+		config: &ContainerConfig{ID: "abc"},
+		state:  &ContainerState{},
+	}
+	// Containers initialized before units were named use their ID
+	assert.Equal(t, "abc", c.healthCheckUnit())
+	c.state.HealthCheckUnit = "abc-123"
+	assert.Equal(t, "abc-123", c.healthCheckUnit())
+}
@@ -0,0 +1,124 @@
+package libpod
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/containers/image/manifest"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheckActionDue(t *testing.T) {
+	c := Container{
+		config: &ContainerConfig{},
+		state:  &ContainerState{},
+	}
+	assert.False(t, c.healthCheckActionDue())
+	c.config.HealthCheckOnFailure = HealthCheckOnFailureNone
+	assert.False(t, c.healthCheckActionDue())
+
+	c.config.HealthCheckOnFailure = HealthCheckOnFailureRestart
+	assert.True(t, c.healthCheckActionDue())
+
+	// The second action waits for the initial backoff
+	c.state.HealthCheckActionCount = 1
+	c.state.HealthCheckActionTime = time.Now().Add(-healthCheckActionBackoffInitial / 2)
+	assert.False(t, c.healthCheckActionDue())
+	c.state.HealthCheckActionTime = time.Now().Add(-healthCheckActionBackoffInitial)
+	assert.True(t, c.healthCheckActionDue())
+
+	// The third one waits twice as long
+	c.state.HealthCheckActionCount = 2
+	assert.False(t, c.healthCheckActionDue())
+	c.state.HealthCheckActionTime = time.Now().Add(-2 * healthCheckActionBackoffInitial)
+	assert.True(t, c.healthCheckActionDue())
+
+	// The backoff does not grow past its maximum
+	c.state.HealthCheckActionCount = 100
+	c.state.HealthCheckActionTime = time.Now().Add(-healthCheckActionBackoffMax)
+	assert.True(t, c.healthCheckActionDue())
+}
+
+// recordingEventer is an eventer remembering the events written to it
+type recordingEventer struct {
+	events.EventToNull
+	written []events.Event
+}
+
+func (e *recordingEventer) Write(ee events.Event) error {
+	e.written = append(e.written, ee)
+	return nil
+}
+
+func TestUpdateHealthCheckLog(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	eventer := &recordingEventer{}
+	ctr, err := getTestCtr1(manager)
+	require.NoError(t, err)
+	ctr.runtime = &Runtime{
+		config:  &RuntimeConfig{},
+		state:   state,
+		eventer: eventer,
+	}
+	// Exited containers are not synced with the OCI runtime
+	ctr.state.State = define.ContainerStateExited
+	ctr.config.HealthCheckConfig = &manifest.Schema2HealthConfig{Retries: 2}
+	ctr.config.HealthCheckOnFailure = HealthCheckOnFailureRestart
+	ctr.resetHealthStatus()
+	require.NoError(t, state.AddContainer(ctr))
+
+	now := time.Now()
+	passed := newHealthCheckLog(now, now, 0, "ok")
+	failed := newHealthCheckLog(now, now, 1, "ko")
+
+	takeAction, err := ctr.updateHealthCheckLog(passed, false)
+	require.NoError(t, err)
+	assert.False(t, takeAction)
+
+	// Failures during the start period are not counted
+	_, err = ctr.updateHealthCheckLog(failed, true)
+	require.NoError(t, err)
+	// The container is unhealthy once it failed as many times as retried
+	takeAction, err = ctr.updateHealthCheckLog(failed, false)
+	require.NoError(t, err)
+	assert.False(t, takeAction)
+	takeAction, err = ctr.updateHealthCheckLog(failed, false)
+	require.NoError(t, err)
+	assert.True(t, takeAction)
+	// The next action waits for the backoff
+	takeAction, err = ctr.updateHealthCheckLog(failed, false)
+	require.NoError(t, err)
+	assert.False(t, takeAction)
+
+	// The health is tracked in the state of the container
+	fromState, err := state.Container(ctr.ID())
+	require.NoError(t, err)
+	results := fromState.healthCheckResults()
+	assert.Equal(t, HealthCheckUnhealthy, results.Status)
+	assert.Equal(t, 3, results.FailingStreak)
+	assert.Len(t, results.Log, 5)
+	assert.Equal(t, uint(1), fromState.state.HealthCheckActionCount)
+
+	_, err = ctr.updateHealthCheckLog(passed, false)
+	require.NoError(t, err)
+	status, err := ctr.HealthCheckStatus()
+	require.NoError(t, err)
+	assert.Equal(t, HealthCheckHealthy, status)
+	assert.Equal(t, uint(0), ctr.state.HealthCheckActionCount)
+
+	// An event is written each time the health status changes
+	var statuses []string
+	for _, e := range eventer.written {
+		assert.Equal(t, events.HealthStatus, e.Status)
+		assert.Equal(t, ctr.ID(), e.ID)
+		statuses = append(statuses, e.Attributes["health_status"])
+	}
+	assert.Equal(t, []string{HealthCheckHealthy, HealthCheckUnhealthy, HealthCheckHealthy}, statuses)
+}
@@ -0,0 +1,161 @@
+package image
+
+import (
+	"strings"
+
+	"github.com/containers/image/docker/reference"
+	"github.com/containers/image/transports/alltransports"
+	"github.com/opencontainers/go-digest"
+)
+
+// Presence reports whether a reference resolves to an image in local storage
+type Presence struct {
+	// Reference is the reference that was looked up
+	Reference string
+	// Exists is whether the reference resolves to a local image
+	Exists bool
+	// ID is the ID of the image the reference resolves to
+	ID string
+	// Digest is the digest of the image's manifest
+	Digest digest.Digest
+	// Digests are the digests of all manifests of the image, including
+	// Digest
+	Digests []digest.Digest
+}
+
+// ImagesExist resolves each of the given references against local storage.
+// References are resolved as NewFromLocal would, but the images are read from
+// storage once for all of them, which is much faster than looking up each
+// reference when there are many.
+// The results are in the order of the references.
+func (ir *Runtime) ImagesExist(references []string) ([]Presence, error) {
+	images, err := ir.GetImages()
+	if err != nil {
+		return nil, err
+	}
+	index := newLocalImageIndex(images)
+
+	results := make([]Presence, 0, len(references))
+	for _, ref := range references {
+		result := Presence{Reference: ref}
+		if img := index.lookup(ref); img != nil {
+			result.Exists = true
+			result.ID = img.ID()
+			result.Digest = img.Digest()
+			result.Digests = img.image.Digests
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// localImageIndex indexes local images by ID and name so that many references
+// can be resolved without going back to storage
+type localImageIndex struct {
+	images []*Image
+	byID   map[string]*Image
+	byName map[string]*Image
+}
+
+func newLocalImageIndex(images []*Image) *localImageIndex {
+	index := &localImageIndex{
+		images: images,
+		byID:   make(map[string]*Image, len(images)),
+		byName: make(map[string]*Image, len(images)),
+	}
+	for _, img := range images {
+		index.byID[img.ID()] = img
+		for _, name := range img.Names() {
+			index.byName[name] = img
+		}
+	}
+	return index
+}
+
+// lookup returns the image the reference resolves to, or nil if there is none.
+// It follows the resolution order of getLocalImage.
+func (index *localImageIndex) lookup(ref string) *Image {
+	if ref == "" {
+		return nil
+	}
+	// Strip the transport, if any
+	if dest, err := alltransports.ParseImageName(ref); err == nil && dest.DockerReference() != nil {
+		ref = dest.DockerReference().String()
+	}
+	name := stripSha256(ref)
+
+	if img := index.lookupID(name); img != nil {
+		return img
+	}
+	if img, ok := index.byName[name]; ok {
+		return img
+	}
+	if named, err := reference.ParseNormalizedNamed(name); err == nil {
+		if digested, ok := named.(reference.Digested); ok {
+			return index.lookupDigest(named.Name(), digested.Digest())
+		}
+		if img, ok := index.byName[reference.TagNameOnly(named).String()]; ok {
+			return img
+		}
+	}
+
+	decomposedImage, err := decompose(name)
+	if err != nil || decomposedImage.hasRegistry {
+		return nil
+	}
+	if localRef, err := decomposedImage.referenceWithRegistry(DefaultLocalRegistry); err == nil {
+		if img, ok := index.byName[localRef.String()]; ok {
+			return img
+		}
+	}
+	if repoImage, err := findImageInRepotags(decomposedImage, index.images); err == nil {
+		return index.byID[repoImage.ID]
+	}
+	return nil
+}
+
+// lookupID returns the image with the given ID, or the only image whose ID
+// starts with it
+func (index *localImageIndex) lookupID(id string) *Image {
+	if img, ok := index.byID[id]; ok {
+		return img
+	}
+	if strings.Trim(id, "0123456789abcdef") != "" {
+		return nil
+	}
+	var match *Image
+	for imgID, img := range index.byID {
+		if strings.HasPrefix(imgID, id) {
+			if match != nil {
+				return nil
+			}
+			match = img
+		}
+	}
+	return match
+}
+
+// lookupDigest returns the image in the given repository with a manifest
+// matching the digest
+func (index *localImageIndex) lookupDigest(repo string, dgst digest.Digest) *Image {
+	for _, img := range index.images {
+		if img.Digest() != dgst && !containsDigest(img.image.Digests, dgst) {
+			continue
+		}
+		for _, name := range img.Names() {
+			if named, err := reference.ParseNormalizedNamed(name); err == nil && named.Name() == repo {
+				return img
+			}
+		}
+	}
+	return nil
+}
+
+func containsDigest(digests []digest.Digest, dgst digest.Digest) bool {
+	for _, d := range digests {
+		if d == dgst {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/containers/storage"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalImageIndexLookup(t *testing.T) {
+	const (
+		alpineID  = "4d90542f0623c71f1f9c11be3da23167174ac9d93731cf91912922e916bab02c"
+		busyboxID = "4dd97cefde62cf2d6bcfd8f2c0300a24fbcddbe0ebcd577cc8b420c29106869a"
+	)
+	alpineDigest := digest.Digest("sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+
+	index := newLocalImageIndex([]*Image{
+		{image: &storage.Image{
+			ID:      alpineID,
+			Names:   []string{"docker.io/library/alpine:latest"},
+			Digest:  alpineDigest,
+			Digests: []digest.Digest{alpineDigest},
+		}},
+		{image: &storage.Image{
+			ID:    busyboxID,
+			Names: []string{"localhost/busybox:test"},
+		}},
+	})
+
+	for _, c := range []struct {
+		ref string
+		id  string
+	}{
+		{"alpine", alpineID},
+		{"docker.io/library/alpine:latest", alpineID},
+		{"docker://alpine:latest", alpineID},
+		{"alpine@" + alpineDigest.String(), alpineID},
+		{"sha256:" + alpineID, alpineID},
+		{"4d90", alpineID},
+		{"busybox:test", busyboxID},
+		{"4d", ""}, // Ambiguous ID prefix
+		{"alpine:edge", ""},
+		{"quay.io/busybox:test", ""},
+		{"alpine@sha256:1123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", ""},
+		{"", ""},
+	} {
+		img := index.lookup(c.ref)
+		if c.id == "" {
+			assert.Nil(t, img, c.ref)
+			continue
+		}
+		if assert.NotNil(t, img, c.ref) {
+			assert.Equal(t, c.id, img.ID(), c.ref)
+		}
+	}
+}
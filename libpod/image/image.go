@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -60,6 +61,26 @@ type Runtime struct {
 	EventsLogFilePath   string
 	EventsLogger        string
 	Eventer             events.Eventer
+
+	// configLock protects SignaturePolicyPath and EventsLogger from
+	// ReloadConfig once the runtime is in use
+	configLock sync.RWMutex
+}
+
+// ReloadConfig changes the signature policy and events logger of a runtime
+// that is already in use
+func (ir *Runtime) ReloadConfig(signaturePolicyPath, eventsLogger string) {
+	ir.configLock.Lock()
+	defer ir.configLock.Unlock()
+	ir.SignaturePolicyPath = signaturePolicyPath
+	ir.EventsLogger = eventsLogger
+}
+
+// signaturePolicyPath returns the default signature policy of the runtime
+func (ir *Runtime) signaturePolicyPath() string {
+	ir.configLock.RLock()
+	defer ir.configLock.RUnlock()
+	return ir.SignaturePolicyPath
 }
 
 // InfoImage keep information of Image along with all associated layers
@@ -157,7 +178,7 @@ func (ir *Runtime) New(ctx context.Context, name, signaturePolicyPath, authfile
 
 	// The image is not local
 	if signaturePolicyPath == "" {
-		signaturePolicyPath = ir.SignaturePolicyPath
+		signaturePolicyPath = ir.signaturePolicyPath()
 	}
 	imageName, err := ir.pullImageFromHeuristicSource(ctx, name, writer, authfile, signaturePolicyPath, signingoptions, dockeroptions, label)
 	if err != nil {
@@ -179,7 +200,7 @@ func (ir *Runtime) LoadFromArchiveReference(ctx context.Context, srcRef types.Im
 	var newImages []*Image
 
 	if signaturePolicyPath == "" {
-		signaturePolicyPath = ir.SignaturePolicyPath
+		signaturePolicyPath = ir.signaturePolicyPath()
 	}
 	imageNames, err := ir.pullImageFromReference(ctx, srcRef, writer, "", signaturePolicyPath, SigningOptions{}, &DockerRegistryOptions{})
 	if err != nil {
@@ -839,6 +860,15 @@ func (i *Image) Labels(ctx context.Context) (map[string]string, error) {
 	return imgInspect.Labels, nil
 }
 
+// Architecture returns the architecture the image was built for
+func (i *Image) Architecture(ctx context.Context) (string, error) {
+	imgInspect, err := i.imageInspectInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return imgInspect.Architecture, nil
+}
+
 // GetLabel Returns a case-insensitive match of a given label
 func (i *Image) GetLabel(ctx context.Context, label string) (string, error) {
 	imageLabels, err := i.Labels(ctx)
@@ -2,9 +2,11 @@ package libpod
 
 import (
 	"strings"
+	"time"
 
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/pkg/registrar"
+	"github.com/containers/storage/pkg/idtools"
 	"github.com/containers/storage/pkg/truncindex"
 	"github.com/pkg/errors"
 )
@@ -20,11 +22,31 @@ type InMemoryState struct {
 	// Maps container ID to container struct.
 	containers map[string]*Container
 	volumes    map[string]*Volume
+	networks   map[string]*Network
+	// Maps name to pod template.
+	podTemplates map[string]*PodTemplate
 	// Maps container ID to a list of IDs of dependencies.
-	ctrDepends    map[string][]string
-	volumeDepends map[string][]string
+	ctrDepends     map[string][]string
+	volumeDepends  map[string][]string
+	networkDepends map[string][]string
+	// Maps container ID to the conditions that the containers depending
+	// on it wait for, by ID of the depending container.
+	ctrDependConditions map[string]map[string]define.DependencyCondition
 	// Maps pod ID to a map of container ID to container struct.
 	podContainers map[string]map[string]*Container
+	// Maps ID of containers queued for removal to the time they were
+	// queued.
+	pendingRemovals map[string]time.Time
+	// Maps exec session ID to the ID of the container it runs in.
+	execSessions map[string]string
+	// Maps container ID to its recorded exit code.
+	exitCodes map[string]recordedExitCode
+	// Maps container ID to the range of IDs allocated to its automatic
+	// user namespace.
+	usernsRanges map[string]AutoUserNSRange
+	// Maps network name to a map of static address to the ID of the
+	// container that reserved it.
+	addresses map[string]map[string]string
 	// Global name registry - ensures name uniqueness and performs lookups.
 	nameIndex *registrar.Registrar
 	// Global ID registry - ensures ID uniqueness and performs lookups.
@@ -50,12 +72,25 @@ func NewInMemoryState() (State, error) {
 	state.pods = make(map[string]*Pod)
 	state.containers = make(map[string]*Container)
 	state.volumes = make(map[string]*Volume)
+	state.networks = make(map[string]*Network)
+	state.podTemplates = make(map[string]*PodTemplate)
 
 	state.ctrDepends = make(map[string][]string)
 	state.volumeDepends = make(map[string][]string)
+	state.networkDepends = make(map[string][]string)
+	state.ctrDependConditions = make(map[string]map[string]define.DependencyCondition)
 
 	state.podContainers = make(map[string]map[string]*Container)
 
+	state.pendingRemovals = make(map[string]time.Time)
+
+	state.execSessions = make(map[string]string)
+
+	state.exitCodes = make(map[string]recordedExitCode)
+	state.usernsRanges = make(map[string]AutoUserNSRange)
+
+	state.addresses = make(map[string]map[string]string)
+
 	state.nameIndex = registrar.NewRegistrar()
 	state.idIndex = truncindex.NewTruncIndex([]string{})
 
@@ -90,6 +125,12 @@ func (s *InMemoryState) ValidateDBConfig(runtime *Runtime) error {
 	return nil
 }
 
+// RewriteGraphDriver is not implemented for the in-memory state.
+// As we do not store a config, this is a no-op.
+func (s *InMemoryState) RewriteGraphDriver(driver string) error {
+	return nil
+}
+
 // SetNamespace sets the namespace for container and pod retrieval.
 func (s *InMemoryState) SetNamespace(ns string) error {
 	s.namespace = ns
@@ -214,6 +255,11 @@ func (s *InMemoryState) AddContainer(ctr *Container) error {
 		}
 	}
 
+	addresses := ctr.staticAddresses(ctr.config)
+	if err := s.checkAddresses(ctr.ID(), addresses); err != nil {
+		return err
+	}
+
 	if err := s.nameIndex.Reserve(ctr.Name(), ctr.ID()); err != nil {
 		return errors.Wrapf(err, "error registering container name %s", ctr.Name())
 	}
@@ -224,6 +270,7 @@ func (s *InMemoryState) AddContainer(ctr *Container) error {
 	}
 
 	s.containers[ctr.ID()] = ctr
+	s.reserveAddresses(ctr.ID(), addresses)
 
 	// If we're in a namespace, add us to that namespace's indexes
 	if ctr.config.Namespace != "" {
@@ -246,7 +293,7 @@ func (s *InMemoryState) AddContainer(ctr *Container) error {
 
 	// Add containers this container depends on
 	for _, depCtr := range depCtrs {
-		s.addCtrToDependsMap(ctr.ID(), depCtr)
+		s.addCtrToDependsMap(ctr.ID(), depCtr, ctr.dependencyCondition(depCtr))
 	}
 
 	// Add container to volume dependencies
@@ -254,6 +301,13 @@ func (s *InMemoryState) AddContainer(ctr *Container) error {
 		s.addCtrToVolDependsMap(ctr.ID(), vol.Name)
 	}
 
+	// Add container to network dependencies
+	for _, net := range ctr.config.Networks {
+		if _, ok := s.networks[net]; ok {
+			s.networkDepends[net] = append(s.networkDepends[net], ctr.ID())
+		}
+	}
+
 	return nil
 }
 
@@ -283,9 +337,13 @@ func (s *InMemoryState) RemoveContainer(ctr *Container) error {
 		return errors.Wrapf(err, "error removing container ID from index")
 	}
 	delete(s.containers, ctr.ID())
+	delete(s.pendingRemovals, ctr.ID())
+	s.removeCtrExecSessions(ctr.ID())
 	s.nameIndex.Release(ctr.Name())
+	s.releaseAddresses(ctr.ID(), ctr.staticAddresses(ctr.config))
 
 	delete(s.ctrDepends, ctr.ID())
+	delete(s.ctrDependConditions, ctr.ID())
 
 	if ctr.config.Namespace != "" {
 		nsIndex, ok := s.namespaceIndexes[ctr.config.Namespace]
@@ -309,6 +367,70 @@ func (s *InMemoryState) RemoveContainer(ctr *Container) error {
 		s.removeCtrFromVolDependsMap(ctr.ID(), vol.Name)
 	}
 
+	// Remove this container from network dependencies
+	for _, net := range ctr.config.Networks {
+		s.removeCtrFromNetDependsMap(ctr.ID(), net)
+	}
+
+	return nil
+}
+
+// RemoveContainers removes the given containers from the state
+// Containers in pods will also be removed from their pods
+func (s *InMemoryState) RemoveContainers(ctrs []*Container) error {
+	toRemove := make(map[string]bool, len(ctrs))
+	for _, ctr := range ctrs {
+		toRemove[ctr.ID()] = true
+	}
+
+	// Check everything before we remove anything, so a failure leaves the
+	// state untouched
+	for _, ctr := range ctrs {
+		if err := s.checkNSMatch(ctr.ID(), ctr.Namespace()); err != nil {
+			return err
+		}
+
+		if _, ok := s.containers[ctr.ID()]; !ok {
+			ctr.valid = false
+			return errors.Wrapf(define.ErrNoSuchCtr, "no container exists in state with ID %s", ctr.ID())
+		}
+
+		if ctr.config.Pod != "" {
+			if _, ok := s.podContainers[ctr.config.Pod]; !ok {
+				return errors.Wrapf(define.ErrNoSuchPod, "no pod exists in state with ID %s", ctr.config.Pod)
+			}
+		}
+
+		for _, dep := range s.ctrDepends[ctr.ID()] {
+			if !toRemove[dep] {
+				return errors.Wrapf(define.ErrCtrExists, "container %s is a dependency of container %s, which is not being removed", ctr.ID(), dep)
+			}
+		}
+	}
+
+	// Dependencies are all being removed together, so drop them now
+	for _, ctr := range ctrs {
+		delete(s.ctrDepends, ctr.ID())
+		delete(s.ctrDependConditions, ctr.ID())
+	}
+
+	for _, ctr := range ctrs {
+		if ctr.config.Pod != "" {
+			delete(s.podContainers[ctr.config.Pod], ctr.ID())
+		}
+
+		if err := s.RemoveContainer(ctr); err != nil {
+			return err
+		}
+	}
+
+	// Removing containers may have recreated dependency entries for
+	// containers removed earlier, clean them up
+	for _, ctr := range ctrs {
+		delete(s.ctrDepends, ctr.ID())
+		delete(s.ctrDependConditions, ctr.ID())
+	}
+
 	return nil
 }
 
@@ -373,6 +495,26 @@ func (s *InMemoryState) ContainerInUse(ctr *Container) ([]string, error) {
 	return arr, nil
 }
 
+// ContainerDependencyConditions returns the conditions that the containers
+// depending on the given container wait for it to meet
+func (s *InMemoryState) ContainerDependencyConditions(ctr *Container) (map[string]define.DependencyCondition, error) {
+	deps, err := s.ContainerInUse(ctr)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make(map[string]define.DependencyCondition, len(deps))
+	for _, dep := range deps {
+		condition, ok := s.ctrDependConditions[ctr.ID()][dep]
+		if !ok {
+			condition = define.DependencyConditionRunning
+		}
+		conditions[dep] = condition
+	}
+
+	return conditions, nil
+}
+
 // AllContainers retrieves all containers from the state
 func (s *InMemoryState) AllContainers() ([]*Container, error) {
 	ctrs := make([]*Container, 0, len(s.containers))
@@ -385,6 +527,200 @@ func (s *InMemoryState) AllContainers() ([]*Container, error) {
 	return ctrs, nil
 }
 
+// AllContainersSummaries returns summaries of all containers in the state
+func (s *InMemoryState) AllContainersSummaries() ([]*ContainerSummary, error) {
+	summaries := make([]*ContainerSummary, 0, len(s.containers))
+	for _, ctr := range s.containers {
+		if s.namespace == "" || ctr.config.Namespace == s.namespace {
+			summaries = append(summaries, ctr.summary())
+		}
+	}
+
+	return summaries, nil
+}
+
+// AddPendingRemoval queues a container for removal once it stops
+func (s *InMemoryState) AddPendingRemoval(ctr *Container) error {
+	if !ctr.valid {
+		return define.ErrCtrRemoved
+	}
+
+	if err := s.checkNSMatch(ctr.ID(), ctr.Namespace()); err != nil {
+		return err
+	}
+
+	if _, ok := s.containers[ctr.ID()]; !ok {
+		ctr.valid = false
+		return errors.Wrapf(define.ErrNoSuchCtr, "container with ID %s not found in state", ctr.ID())
+	}
+
+	if _, ok := s.pendingRemovals[ctr.ID()]; !ok {
+		s.pendingRemovals[ctr.ID()] = time.Now()
+	}
+
+	return nil
+}
+
+// PendingRemovals returns the containers queued for removal and the times
+// they were queued
+func (s *InMemoryState) PendingRemovals() (map[string]time.Time, error) {
+	pending := make(map[string]time.Time, len(s.pendingRemovals))
+	for id, queued := range s.pendingRemovals {
+		if ctr, ok := s.containers[id]; ok && (s.namespace == "" || ctr.config.Namespace == s.namespace) {
+			pending[id] = queued
+		}
+	}
+
+	return pending, nil
+}
+
+// AddExecSession registers an exec session of a container
+func (s *InMemoryState) AddExecSession(ctr *Container, session *ExecSession) error {
+	if !ctr.valid {
+		return define.ErrCtrRemoved
+	}
+
+	if session.ID == "" {
+		return errors.Wrapf(define.ErrEmptyID, "exec session must have an ID")
+	}
+
+	if err := s.checkNSMatch(ctr.ID(), ctr.Namespace()); err != nil {
+		return err
+	}
+
+	if _, ok := s.containers[ctr.ID()]; !ok {
+		ctr.valid = false
+		return errors.Wrapf(define.ErrNoSuchCtr, "container with ID %s not found in state", ctr.ID())
+	}
+
+	if _, ok := s.execSessions[session.ID]; ok {
+		return errors.Wrapf(define.ErrExecSessionExists, "an exec session with ID %s already exists", session.ID)
+	}
+
+	s.execSessions[session.ID] = ctr.ID()
+
+	return nil
+}
+
+// ExecSessionContainer returns the ID of the container an exec session runs in
+func (s *InMemoryState) ExecSessionContainer(id string) (string, error) {
+	if id == "" {
+		return "", define.ErrEmptyID
+	}
+
+	ctrID, ok := s.execSessions[id]
+	if !ok {
+		return "", errors.Wrapf(define.ErrNoSuchExecSession, "no exec session with ID %s found", id)
+	}
+
+	if ctr, ok := s.containers[ctrID]; ok && s.namespace != "" && ctr.config.Namespace != s.namespace {
+		return "", errors.Wrapf(define.ErrNoSuchExecSession, "no exec session with ID %s found in namespace %q", id, s.namespace)
+	}
+
+	return ctrID, nil
+}
+
+// RemoveExecSession removes the registration of an exec session
+func (s *InMemoryState) RemoveExecSession(id string) error {
+	if id == "" {
+		return define.ErrEmptyID
+	}
+
+	if _, ok := s.execSessions[id]; !ok {
+		return errors.Wrapf(define.ErrNoSuchExecSession, "no exec session with ID %s found", id)
+	}
+
+	delete(s.execSessions, id)
+
+	return nil
+}
+
+// AllExecSessions returns all registered exec sessions and the IDs of their
+// containers
+func (s *InMemoryState) AllExecSessions() (map[string]string, error) {
+	sessions := make(map[string]string, len(s.execSessions))
+	for id, ctrID := range s.execSessions {
+		if ctr, ok := s.containers[ctrID]; ok && (s.namespace == "" || ctr.config.Namespace == s.namespace) {
+			sessions[id] = ctrID
+		}
+	}
+
+	return sessions, nil
+}
+
+// AddContainerExitCode records the exit code of a container
+func (s *InMemoryState) AddContainerExitCode(id string, exitCode int32) error {
+	if id == "" {
+		return define.ErrEmptyID
+	}
+
+	s.exitCodes[id] = recordedExitCode{ExitCode: exitCode, Recorded: time.Now()}
+
+	return nil
+}
+
+// GetContainerExitCode returns the recorded exit code of a container
+func (s *InMemoryState) GetContainerExitCode(id string) (int32, error) {
+	if id == "" {
+		return -1, define.ErrEmptyID
+	}
+
+	recorded, ok := s.exitCodes[id]
+	if !ok {
+		return -1, errors.Wrapf(define.ErrNoSuchExitCode, "no exit code recorded for container %s", id)
+	}
+
+	return recorded.ExitCode, nil
+}
+
+// PruneContainerExitCodes removes the exit codes recorded before the given
+// time
+func (s *InMemoryState) PruneContainerExitCodes(before time.Time) error {
+	for id, recorded := range s.exitCodes {
+		if recorded.Recorded.Before(before) {
+			delete(s.exitCodes, id)
+		}
+	}
+
+	return nil
+}
+
+// AllocateUserNSRange allocates a range of UIDs and GIDs to a container
+func (s *InMemoryState) AllocateUserNSRange(ctrID string, size int, uidPool, gidPool []idtools.IDMap) (*AutoUserNSRange, error) {
+	if ctrID == "" {
+		return nil, define.ErrEmptyID
+	}
+
+	if _, ok := s.usernsRanges[ctrID]; ok {
+		return nil, errors.Wrapf(define.ErrCtrExists, "container %s already has a user namespace allocated", ctrID)
+	}
+
+	allocated := make([]AutoUserNSRange, 0, len(s.usernsRanges))
+	for _, r := range s.usernsRanges {
+		allocated = append(allocated, r)
+	}
+
+	newRange, err := allocateAutoUserNSRange(size, uidPool, gidPool, allocated)
+	if err != nil {
+		return nil, err
+	}
+	s.usernsRanges[ctrID] = *newRange
+
+	return newRange, nil
+}
+
+// ReleaseUserNSRange releases the range of UIDs and GIDs allocated to a
+// container
+func (s *InMemoryState) ReleaseUserNSRange(ctrID string) error {
+	if ctrID == "" {
+		return define.ErrEmptyID
+	}
+
+	delete(s.usernsRanges, ctrID)
+
+	return nil
+}
+
 // RewriteContainerConfig rewrites a container's configuration.
 // This function is DANGEROUS, even with an in-memory state.
 // Please read the full comment on it in state.go before using it.
@@ -405,6 +741,44 @@ func (s *InMemoryState) RewriteContainerConfig(ctr *Container, newCfg *Container
 	return nil
 }
 
+// RewriteContainerNetworks rewrites a container's configuration after the
+// networks it is attached to have changed, and updates the dependencies of the
+// networks in the state to match.
+// This function is DANGEROUS, even with in-memory state.
+// Please read the full comment on it in state.go before using it.
+func (s *InMemoryState) RewriteContainerNetworks(ctr *Container, newCfg *ContainerConfig) error {
+	if !ctr.valid {
+		return define.ErrCtrRemoved
+	}
+
+	// If the container does not exist, return error
+	stateCtr, ok := s.containers[ctr.ID()]
+	if !ok {
+		ctr.valid = false
+		return errors.Wrapf(define.ErrNoSuchCtr, "container with ID %s not found in state", ctr.ID())
+	}
+
+	newAddresses := ctr.staticAddresses(newCfg)
+	if err := s.checkAddresses(ctr.ID(), newAddresses); err != nil {
+		return err
+	}
+	s.releaseAddresses(ctr.ID(), ctr.staticAddresses(stateCtr.config))
+	s.reserveAddresses(ctr.ID(), newAddresses)
+
+	for _, net := range stateCtr.config.Networks {
+		s.removeCtrFromNetDependsMap(ctr.ID(), net)
+	}
+	for _, net := range newCfg.Networks {
+		if _, ok := s.networks[net]; ok {
+			s.networkDepends[net] = append(s.networkDepends[net], ctr.ID())
+		}
+	}
+
+	stateCtr.config = newCfg
+
+	return nil
+}
+
 // RewritePodConfig rewrites a pod's configuration.
 // This function is DANGEROUS, even with in-memory state.
 // Please read the full comment on it in state.go before using it.
@@ -494,7 +868,7 @@ func (s *InMemoryState) RemoveVolume(volume *Volume) error {
 	deps, ok := s.volumeDepends[volume.Name()]
 	if ok && len(deps) != 0 {
 		depsStr := strings.Join(deps, ", ")
-		return errors.Wrapf(define.ErrVolumeExists, "the following containers depend on volume %s: %s", volume.Name(), depsStr)
+		return errors.Wrapf(define.ErrVolumeBeingUsed, "the following containers depend on volume %s: %s", volume.Name(), depsStr)
 	}
 
 	if _, ok := s.volumes[volume.Name()]; !ok {
@@ -507,6 +881,36 @@ func (s *InMemoryState) RemoveVolume(volume *Volume) error {
 	return nil
 }
 
+// UpdateVolume updates a volume from the database.
+// For the in-memory state, this is a no-op.
+func (s *InMemoryState) UpdateVolume(volume *Volume) error {
+	if !volume.valid {
+		return define.ErrVolumeRemoved
+	}
+
+	if _, ok := s.volumes[volume.Name()]; !ok {
+		volume.valid = false
+		return errors.Wrapf(define.ErrNoSuchVolume, "volume with name %q not found in state", volume.Name())
+	}
+
+	return nil
+}
+
+// SaveVolume saves a volume's state to the database.
+// For the in-memory state, this is a no-op.
+func (s *InMemoryState) SaveVolume(volume *Volume) error {
+	if !volume.valid {
+		return define.ErrVolumeRemoved
+	}
+
+	if _, ok := s.volumes[volume.Name()]; !ok {
+		volume.valid = false
+		return errors.Wrapf(define.ErrNoSuchVolume, "volume with name %q not found in state", volume.Name())
+	}
+
+	return nil
+}
+
 // VolumeInUse checks if the given volume is being used by at least one container
 func (s *InMemoryState) VolumeInUse(volume *Volume) ([]string, error) {
 	if !volume.valid {
@@ -537,6 +941,163 @@ func (s *InMemoryState) AllVolumes() ([]*Volume, error) {
 	return allVols, nil
 }
 
+// Network retrieves a network from its full name
+func (s *InMemoryState) Network(name string) (*Network, error) {
+	if name == "" {
+		return nil, define.ErrEmptyID
+	}
+
+	net, ok := s.networks[name]
+	if !ok {
+		return nil, errors.Wrapf(define.ErrNoSuchNetwork, "no network with name %s found", name)
+	}
+
+	return net, nil
+}
+
+// HasNetwork checks if a network with the given name is present in the state
+func (s *InMemoryState) HasNetwork(name string) (bool, error) {
+	if name == "" {
+		return false, define.ErrEmptyID
+	}
+
+	_, ok := s.networks[name]
+	return ok, nil
+}
+
+// AddNetwork adds a network to the state
+func (s *InMemoryState) AddNetwork(network *Network) error {
+	if !network.valid {
+		return errors.Wrapf(define.ErrNetworkRemoved, "network with name %s is not valid", network.Name())
+	}
+
+	if _, ok := s.networks[network.Name()]; ok {
+		return errors.Wrapf(define.ErrNetworkExists, "network with name %s already exists in state", network.Name())
+	}
+
+	s.networks[network.Name()] = network
+
+	return nil
+}
+
+// RemoveNetwork removes a network from the state
+func (s *InMemoryState) RemoveNetwork(network *Network) error {
+	// Ensure we don't remove a network which containers depend on
+	deps, ok := s.networkDepends[network.Name()]
+	if ok && len(deps) != 0 {
+		depsStr := strings.Join(deps, ", ")
+		return errors.Wrapf(define.ErrNetworkBeingUsed, "the following containers depend on network %s: %s", network.Name(), depsStr)
+	}
+
+	if _, ok := s.networks[network.Name()]; !ok {
+		network.valid = false
+		return errors.Wrapf(define.ErrNoSuchNetwork, "no network exists in state with name %s", network.Name())
+	}
+
+	delete(s.networks, network.Name())
+	delete(s.networkDepends, network.Name())
+
+	return nil
+}
+
+// NetworkInUse checks if the given network is being used by at least one
+// container
+func (s *InMemoryState) NetworkInUse(network *Network) ([]string, error) {
+	if !network.valid {
+		return nil, define.ErrNetworkRemoved
+	}
+
+	if _, ok := s.networks[network.Name()]; !ok {
+		network.valid = false
+		return nil, errors.Wrapf(define.ErrNoSuchNetwork, "network with name %s not found in state", network.Name())
+	}
+
+	arr, ok := s.networkDepends[network.Name()]
+	if !ok {
+		return []string{}, nil
+	}
+
+	return arr, nil
+}
+
+// AllNetworks returns all networks that exist in the state
+func (s *InMemoryState) AllNetworks() ([]*Network, error) {
+	allNets := make([]*Network, 0, len(s.networks))
+	for _, n := range s.networks {
+		allNets = append(allNets, n)
+	}
+
+	return allNets, nil
+}
+
+// PodTemplate retrieves the pod template with the given name
+func (s *InMemoryState) PodTemplate(name string) (*PodTemplate, error) {
+	if name == "" {
+		return nil, define.ErrEmptyID
+	}
+
+	tmpl, ok := s.podTemplates[name]
+	if !ok {
+		return nil, errors.Wrapf(define.ErrNoSuchPodTemplate, "no pod template with name %s found", name)
+	}
+
+	newTmpl := new(PodTemplate)
+	if err := JSONDeepCopy(tmpl, newTmpl); err != nil {
+		return nil, errors.Wrapf(err, "error copying pod template %s", name)
+	}
+
+	return newTmpl, nil
+}
+
+// AddPodTemplate adds a pod template to the state
+func (s *InMemoryState) AddPodTemplate(tmpl *PodTemplate) error {
+	if tmpl.Name == "" {
+		return define.ErrEmptyID
+	}
+
+	if _, ok := s.podTemplates[tmpl.Name]; ok {
+		return errors.Wrapf(define.ErrPodTemplateExists, "pod template with name %s already exists in state", tmpl.Name)
+	}
+
+	newTmpl := new(PodTemplate)
+	if err := JSONDeepCopy(tmpl, newTmpl); err != nil {
+		return errors.Wrapf(err, "error copying pod template %s", tmpl.Name)
+	}
+	s.podTemplates[tmpl.Name] = newTmpl
+
+	return nil
+}
+
+// RemovePodTemplate removes the pod template with the given name from the
+// state
+func (s *InMemoryState) RemovePodTemplate(name string) error {
+	if name == "" {
+		return define.ErrEmptyID
+	}
+
+	if _, ok := s.podTemplates[name]; !ok {
+		return errors.Wrapf(define.ErrNoSuchPodTemplate, "no pod template exists in state with name %s", name)
+	}
+
+	delete(s.podTemplates, name)
+
+	return nil
+}
+
+// AllPodTemplates returns all pod templates that exist in the state
+func (s *InMemoryState) AllPodTemplates() ([]*PodTemplate, error) {
+	allTmpls := make([]*PodTemplate, 0, len(s.podTemplates))
+	for name, tmpl := range s.podTemplates {
+		newTmpl := new(PodTemplate)
+		if err := JSONDeepCopy(tmpl, newTmpl); err != nil {
+			return nil, errors.Wrapf(err, "error copying pod template %s", name)
+		}
+		allTmpls = append(allTmpls, newTmpl)
+	}
+
+	return allTmpls, nil
+}
+
 // Pod retrieves a pod from the state from its full ID
 func (s *InMemoryState) Pod(id string) (*Pod, error) {
 	if id == "" {
@@ -702,6 +1263,32 @@ func (s *InMemoryState) PodContainers(pod *Pod) ([]*Container, error) {
 	return ctrs, nil
 }
 
+// PodInitContainers retrieves the init containers of a pod
+func (s *InMemoryState) PodInitContainers(pod *Pod) ([]*Container, error) {
+	if !pod.valid {
+		return nil, errors.Wrapf(define.ErrPodRemoved, "pod %s is not valid", pod.ID())
+	}
+
+	if err := s.checkNSMatch(pod.ID(), pod.Namespace()); err != nil {
+		return nil, err
+	}
+
+	podCtrs, ok := s.podContainers[pod.ID()]
+	if !ok {
+		pod.valid = false
+		return nil, errors.Wrapf(define.ErrNoSuchPod, "no pod with ID %s found in state", pod.ID())
+	}
+
+	ctrs := []*Container{}
+	for _, ctr := range podCtrs {
+		if ctr.config.InitContainerType != "" {
+			ctrs = append(ctrs, ctr)
+		}
+	}
+
+	return ctrs, nil
+}
+
 // AddPod adds a given pod to the state
 func (s *InMemoryState) AddPod(pod *Pod) error {
 	if !pod.valid {
@@ -841,7 +1428,10 @@ func (s *InMemoryState) RemovePodContainers(pod *Pod) error {
 		s.nameIndex.Release(ctr.Name())
 
 		delete(s.containers, ctr.ID())
+		delete(s.pendingRemovals, ctr.ID())
+		s.removeCtrExecSessions(ctr.ID())
 		delete(s.ctrDepends, ctr.ID())
+		delete(s.ctrDependConditions, ctr.ID())
 	}
 
 	return nil
@@ -904,6 +1494,11 @@ func (s *InMemoryState) AddContainerToPod(pod *Pod, ctr *Container) error {
 		return errors.Wrapf(define.ErrCtrExists, "container with ID %s already exists in state", ctr.ID())
 	}
 
+	addresses := ctr.staticAddresses(ctr.config)
+	if err := s.checkAddresses(ctr.ID(), addresses); err != nil {
+		return err
+	}
+
 	if err := s.nameIndex.Reserve(ctr.Name(), ctr.ID()); err != nil {
 		return errors.Wrapf(err, "error reserving container name %s", ctr.Name())
 	}
@@ -914,6 +1509,7 @@ func (s *InMemoryState) AddContainerToPod(pod *Pod, ctr *Container) error {
 	}
 
 	s.containers[ctr.ID()] = ctr
+	s.reserveAddresses(ctr.ID(), addresses)
 
 	// Add container to pod containers
 	podCtrs[ctr.ID()] = ctr
@@ -939,7 +1535,7 @@ func (s *InMemoryState) AddContainerToPod(pod *Pod, ctr *Container) error {
 
 	// Add containers this container depends on
 	for _, depCtr := range depCtrs {
-		s.addCtrToDependsMap(ctr.ID(), depCtr)
+		s.addCtrToDependsMap(ctr.ID(), depCtr, ctr.dependencyCondition(depCtr))
 	}
 
 	return nil
@@ -993,7 +1589,10 @@ func (s *InMemoryState) RemoveContainerFromPod(pod *Pod, ctr *Container) error {
 		return errors.Wrapf(err, "error removing container ID from index")
 	}
 	delete(s.containers, ctr.ID())
+	delete(s.pendingRemovals, ctr.ID())
+	s.removeCtrExecSessions(ctr.ID())
 	s.nameIndex.Release(ctr.Name())
+	s.releaseAddresses(ctr.ID(), ctr.staticAddresses(ctr.config))
 
 	// Remove the container from the pod
 	delete(podCtrs, ctr.ID())
@@ -1074,8 +1673,9 @@ func (s *InMemoryState) AllPods() ([]*Pod, error) {
 
 // Internal Functions
 
-// Add a container to the dependency mappings
-func (s *InMemoryState) addCtrToDependsMap(ctrID, dependsID string) {
+// Add a container to the dependency mappings, along with the condition it
+// waits for the dependency to meet
+func (s *InMemoryState) addCtrToDependsMap(ctrID, dependsID string, condition define.DependencyCondition) {
 	if dependsID != "" {
 		arr, ok := s.ctrDepends[dependsID]
 		if !ok {
@@ -1086,12 +1686,19 @@ func (s *InMemoryState) addCtrToDependsMap(ctrID, dependsID string) {
 			arr = append(arr, ctrID)
 			s.ctrDepends[dependsID] = arr
 		}
+
+		if s.ctrDependConditions[dependsID] == nil {
+			s.ctrDependConditions[dependsID] = make(map[string]define.DependencyCondition)
+		}
+		s.ctrDependConditions[dependsID][ctrID] = condition
 	}
 }
 
 // Remove a container from dependency mappings
 func (s *InMemoryState) removeCtrFromDependsMap(ctrID, dependsID string) {
 	if dependsID != "" {
+		delete(s.ctrDependConditions[dependsID], ctrID)
+
 		arr, ok := s.ctrDepends[dependsID]
 		if !ok {
 			// Internal state seems inconsistent
@@ -1150,6 +1757,64 @@ func (s *InMemoryState) removeCtrFromVolDependsMap(depCtrID, volName string) {
 	}
 }
 
+// Remove a container from the dependency mappings for the network
+func (s *InMemoryState) removeCtrFromNetDependsMap(depCtrID, netName string) {
+	arr, ok := s.networkDepends[netName]
+	if !ok {
+		return
+	}
+
+	newArr := make([]string, 0, len(arr))
+	for _, id := range arr {
+		if id != depCtrID {
+			newArr = append(newArr, id)
+		}
+	}
+
+	s.networkDepends[netName] = newArr
+}
+
+// Check that no other container has reserved any of the given addresses
+func (s *InMemoryState) checkAddresses(ctrID string, addresses []addressReservation) error {
+	for _, address := range addresses {
+		if err := checkAddressNetwork(ctrID, address); err != nil {
+			return err
+		}
+		if owner, ok := s.addresses[address.Network][address.Address]; ok && owner != ctrID {
+			return errAddressInUse(ctrID, address, owner)
+		}
+	}
+	return nil
+}
+
+// Reserve the given addresses for a container
+func (s *InMemoryState) reserveAddresses(ctrID string, addresses []addressReservation) {
+	for _, address := range addresses {
+		if _, ok := s.addresses[address.Network]; !ok {
+			s.addresses[address.Network] = make(map[string]string)
+		}
+		s.addresses[address.Network][address.Address] = ctrID
+	}
+}
+
+// Release the reservations of the given addresses held by a container
+func (s *InMemoryState) releaseAddresses(ctrID string, addresses []addressReservation) {
+	for _, address := range addresses {
+		if s.addresses[address.Network][address.Address] == ctrID {
+			delete(s.addresses[address.Network], address.Address)
+		}
+	}
+}
+
+// Remove the registrations of the exec sessions of a container
+func (s *InMemoryState) removeCtrExecSessions(ctrID string) {
+	for id, sessionCtrID := range s.execSessions {
+		if sessionCtrID == ctrID {
+			delete(s.execSessions, id)
+		}
+	}
+}
+
 // Check if we can access a pod or container, or if that is blocked by
 // namespaces.
 func (s *InMemoryState) checkNSMatch(id, ns string) error {
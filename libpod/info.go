@@ -41,13 +41,14 @@ func (r *Runtime) hostInfo() (map[string]interface{}, error) {
 	hostDistributionInfo := r.GetHostDistributionInfo()
 	info["Conmon"] = map[string]interface{}{
 		"path":    r.conmonPath,
-		"package": r.defaultOCIRuntime.conmonPackage(),
+		"package": packageVersion(r.conmonPath),
 		"version": conmonVersion,
 	}
 	info["OCIRuntime"] = map[string]interface{}{
-		"path":    r.defaultOCIRuntime.path,
-		"package": r.defaultOCIRuntime.pathPackage(),
-		"version": ociruntimeVersion,
+		"path":     r.defaultOCIRuntime.Path(),
+		"package":  packageVersion(r.defaultOCIRuntime.Path()),
+		"version":  ociruntimeVersion,
+		"features": r.defaultOCIRuntime.Features(),
 	}
 	info["Distribution"] = map[string]interface{}{
 		"distribution": hostDistributionInfo["Distribution"],
@@ -115,6 +116,11 @@ func (r *Runtime) storeInfo() (map[string]interface{}, error) {
 	info["GraphDriverName"] = r.store.GraphDriverName()
 	info["GraphOptions"] = r.store.GraphOptions()
 	info["VolumePath"] = r.config.VolumePath
+	volumeDrivers := make(map[string]VolumeDriverCapabilities)
+	for _, driver := range r.VolumeDrivers() {
+		volumeDrivers[driver.Name()] = driver.Capabilities()
+	}
+	info["VolumeDrivers"] = volumeDrivers
 
 	configFile, err := storage.DefaultConfigFile(rootless.IsRootless())
 	if err != nil {
@@ -184,7 +190,7 @@ func (r *Runtime) GetConmonVersion() (string, error) {
 
 // GetOCIRuntimePath returns the path to the OCI Runtime Path the runtime is using
 func (r *Runtime) GetOCIRuntimePath() string {
-	return r.defaultOCIRuntime.path
+	return r.defaultOCIRuntime.Path()
 }
 
 // GetOCIRuntimeVersion returns a string representation of the oci runtimes version
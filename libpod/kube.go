@@ -59,9 +59,26 @@ func (p *Pod) GenerateForKube() (*v1.Pod, []v1.ServicePort, error) {
 			return nil, servicePorts, err
 		}
 		servicePorts = containerPortsToServicePorts(ports)
+
+		// Exposed ports are declared, but not published
+		exposed, err := ocicniPortMappingToContainerPort(p.config.InfraContainer.ExposedPorts)
+		if err != nil {
+			return nil, servicePorts, err
+		}
+		ports = append(ports, exposed...)
 	}
 	pod, err := p.podWithContainers(allContainers, ports)
-	return pod, servicePorts, err
+	if err != nil {
+		return nil, servicePorts, err
+	}
+	if p.config.UsePodPID {
+		sharePID := true
+		pod.Spec.ShareProcessNamespace = &sharePID
+	}
+	if p.config.DNSConfig != nil {
+		pod.Spec.DNSPolicy, pod.Spec.DNSConfig = podDNSConfigToKube(p.config.DNSConfig)
+	}
+	return pod, servicePorts, nil
 }
 
 func (p *Pod) getInfraContainer() (*Container, error) {
@@ -168,6 +185,29 @@ func (p *Pod) podWithContainers(containers []*Container, ports []v1.ContainerPor
 	return addContainersAndVolumesToPodObject(podContainers, podVolumes, p.Name()), nil
 }
 
+// podDNSConfigToKube converts the DNS configuration of a pod to a kubernetes
+// DNS policy and configuration
+func podDNSConfigToKube(dns *PodDNSConfig) (v1.DNSPolicy, *v1.PodDNSConfig) {
+	policy := v1.DNSDefault
+	if dns.Policy == PodDNSPolicyNone {
+		policy = v1.DNSNone
+	}
+	kubeDNS := v1.PodDNSConfig{
+		Nameservers: dns.Nameservers,
+		Searches:    dns.Searches,
+	}
+	for _, option := range dns.Options {
+		split := strings.SplitN(option, ":", 2)
+		kubeOption := v1.PodDNSConfigOption{Name: split[0]}
+		if len(split) > 1 {
+			value := split[1]
+			kubeOption.Value = &value
+		}
+		kubeDNS.Options = append(kubeDNS.Options, kubeOption)
+	}
+	return policy, &kubeDNS
+}
+
 func addContainersAndVolumesToPodObject(containers []v1.Container, volumes []v1.Volume, podName string) *v1.Pod {
 	tm := v12.TypeMeta{
 		Kind:       "Pod",
@@ -0,0 +1,39 @@
+// +build linux
+
+package file
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// LockHolder returns the PID of the process holding the given lock, or 0 if
+// it is not held by another process.
+// Locks held by this process are not reported, as they do not conflict with
+// our own locks.
+func (locks *FileLocks) LockHolder(lck uint32) (int, error) {
+	if !locks.valid {
+		return 0, errors.Wrapf(syscall.EINVAL, "locks have already been closed")
+	}
+
+	f, err := os.Open(locks.getLockPath(lck))
+	if err != nil {
+		return 0, errors.Wrapf(err, "error opening lock %d", lck)
+	}
+	defer f.Close()
+
+	lk := unix.Flock_t{
+		Type:   unix.F_WRLCK,
+		Whence: int16(os.SEEK_SET),
+	}
+	if err := unix.FcntlFlock(f.Fd(), unix.F_GETLK, &lk); err != nil {
+		return 0, errors.Wrapf(err, "error checking lock %d", lck)
+	}
+	if lk.Type == unix.F_UNLCK {
+		return 0, nil
+	}
+	return int(lk.Pid), nil
+}
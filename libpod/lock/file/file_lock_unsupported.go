@@ -0,0 +1,12 @@
+// +build !linux
+
+package file
+
+import (
+	"fmt"
+)
+
+// LockHolder is not supported on this platform
+func (locks *FileLocks) LockHolder(lck uint32) (int, error) {
+	return 0, fmt.Errorf("not supported")
+}
@@ -1,10 +1,17 @@
 package lock
 
 import (
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/lock/file"
+	"github.com/pkg/errors"
 )
 
-// FileLockManager manages shared memory locks.
+// FileLockManager manages file-based locks.
+// Each lock is a file in the manager's directory, created when the lock is
+// allocated and removed when it is freed, so there is no fixed limit on the
+// number of locks.
 type FileLockManager struct {
 	locks *file.FileLocks
 }
@@ -79,7 +86,7 @@ func (m *FileLockManager) FreeAllLocks() error {
 	return m.locks.DeallocateAllLocks()
 }
 
-// FileLock is an individual shared memory lock.
+// FileLock is an individual file-based lock.
 type FileLock struct {
 	lockID  uint32
 	manager *FileLockManager
@@ -104,6 +111,25 @@ func (l *FileLock) Unlock() {
 	}
 }
 
+// TryLockWithTimeout acquires the lock, waiting at most the given timeout for
+// it to become available.
+func (l *FileLock) TryLockWithTimeout(timeout time.Duration) error {
+	if !lockWithTimeout(l, timeout) {
+		return errors.Wrapf(define.ErrLockTimeout, "timed out after %s waiting for lock %d", timeout, l.lockID)
+	}
+	return nil
+}
+
+// Holder returns the process holding the lock.
+// Holders within the current process are not reported.
+func (l *FileLock) Holder() (*Holder, error) {
+	pid, err := l.manager.locks.LockHolder(l.lockID)
+	if err != nil {
+		return nil, err
+	}
+	return holderForPID(pid), nil
+}
+
 // Free releases the lock, allowing it to be reused.
 func (l *FileLock) Free() error {
 	return l.manager.locks.DeallocateLock(l.lockID)
@@ -1,8 +1,12 @@
 package lock
 
 import (
+	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/containers/libpod/libpod/define"
 	"github.com/pkg/errors"
 )
 
@@ -11,6 +15,8 @@ type Mutex struct {
 	id        uint32
 	lock      sync.Mutex
 	allocated bool
+	// locked is 1 while the mutex is held
+	locked int32
 }
 
 // ID retrieves the ID of the mutex
@@ -21,13 +27,33 @@ func (m *Mutex) ID() uint32 {
 // Lock locks the mutex
 func (m *Mutex) Lock() {
 	m.lock.Lock()
+	atomic.StoreInt32(&m.locked, 1)
 }
 
 // Unlock unlocks the mutex
 func (m *Mutex) Unlock() {
+	atomic.StoreInt32(&m.locked, 0)
 	m.lock.Unlock()
 }
 
+// TryLockWithTimeout locks the mutex, waiting at most the given timeout for it
+// to become available
+func (m *Mutex) TryLockWithTimeout(timeout time.Duration) error {
+	if !lockWithTimeout(m, timeout) {
+		return errors.Wrapf(define.ErrLockTimeout, "timed out after %s waiting for lock %d", timeout, m.id)
+	}
+	return nil
+}
+
+// Holder returns the holder of the mutex. As in-memory locks are not shared
+// between processes, the holder is always the current process.
+func (m *Mutex) Holder() (*Holder, error) {
+	if atomic.LoadInt32(&m.locked) == 0 {
+		return nil, nil
+	}
+	return holderForPID(os.Getpid()), nil
+}
+
 // Free deallocates the mutex to allow its reuse
 func (m *Mutex) Free() error {
 	m.allocated = false
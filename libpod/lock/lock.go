@@ -1,5 +1,18 @@
 package lock
 
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DeadlockTimeout is how long a lock acquired while holding another lock is
+// waited for before the operation gives up, assuming it is deadlocked against
+// an operation acquiring the locks in the opposite order.
+var DeadlockTimeout = 30 * time.Second
+
 // Manager provides an interface for allocating multiprocess locks.
 // Locks returned by Manager MUST be multiprocess - allocating a lock in
 // process A and retrieving that lock's ID in process B must return handles for
@@ -73,6 +86,20 @@ type Locker interface {
 	// the most part, panicking should be appropriate.
 	// This includes unlocking locks which are already unlocked.
 	Unlock()
+	// TryLockWithTimeout attempts to lock the lock, waiting at most the
+	// given duration for it to become available.
+	// If the lock could not be acquired before the timeout expired, an
+	// error wrapping define.ErrLockTimeout is returned, and the lock is
+	// not held.
+	// As with Lock(), the lock must be unlocked with Unlock() once it has
+	// been acquired.
+	TryLockWithTimeout(timeout time.Duration) error
+	// Holder returns the process currently holding the lock, for
+	// diagnosing hangs. It returns nil if the lock is not held, or if the
+	// holder cannot be determined. The holder is retrieved without taking
+	// the lock, so it may no longer hold the lock by the time it is
+	// returned.
+	Holder() (*Holder, error)
 	// Free deallocates the underlying lock, allowing its reuse by other
 	// pods and containers.
 	// The lock MUST still be usable after a Free() - some libpod instances
@@ -80,3 +107,81 @@ type Locker interface {
 	// advises the manager that the lock may be reallocated.
 	Free() error
 }
+
+// Holder describes the process holding a lock.
+type Holder struct {
+	// PID is the PID of the process holding the lock.
+	PID int `json:"pid"`
+	// Command is the command line of the process holding the lock, which
+	// describes the operation it is performing. It is empty if it could
+	// not be determined.
+	Command string `json:"command,omitempty"`
+}
+
+// String returns a human-readable description of the holder.
+func (h *Holder) String() string {
+	if h.Command == "" {
+		return fmt.Sprintf("PID %d", h.PID)
+	}
+	return fmt.Sprintf("PID %d (%s)", h.PID, h.Command)
+}
+
+// holderForPID returns a Holder for the process with the given PID.
+// A PID of 0 indicates that there is no holder, and nil is returned.
+func holderForPID(pid int) *Holder {
+	if pid <= 0 {
+		return nil
+	}
+
+	holder := &Holder{PID: pid}
+	cmdline, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil {
+		holder.Command = strings.TrimSpace(strings.Replace(string(cmdline), "\x00", " ", -1))
+	}
+	return holder
+}
+
+// lockWithTimeout locks the given lock, waiting at most the given timeout for
+// it to become available. It returns whether the lock was acquired.
+// It is intended for locks that can be locked and unlocked from different
+// goroutines. The lock is taken in a separate goroutine; if the timeout
+// expires first, that goroutine releases the lock as soon as it acquires it.
+func lockWithTimeout(locker sync.Locker, timeout time.Duration) bool {
+	var (
+		stateLock sync.Mutex
+		abandoned bool
+	)
+	acquired := make(chan struct{})
+
+	go func() {
+		locker.Lock()
+
+		stateLock.Lock()
+		defer stateLock.Unlock()
+		if abandoned {
+			locker.Unlock()
+			return
+		}
+		close(acquired)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-acquired:
+		return true
+	case <-timer.C:
+	}
+
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	select {
+	case <-acquired:
+		// Acquired while we were timing out
+		return true
+	default:
+	}
+	abandoned = true
+	return false
+}
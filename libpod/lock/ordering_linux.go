@@ -0,0 +1,151 @@
+// +build linux
+
+package lock
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// heldLocksLock protects heldLocks
+	heldLocksLock sync.Mutex
+	// heldLocks contains the ordered locks held by each thread of this
+	// process, indexed by thread ID
+	heldLocks = make(map[int][]*OrderedLocker)
+)
+
+// OrderedLocker is a Locker that enforces an order in which locks must be
+// acquired within this process.
+// Each lock has a level, and locks must be acquired in order of increasing
+// level. Acquiring a lock while holding a lock of a higher level can deadlock
+// against an operation acquiring the same locks in the correct order, and
+// acquiring a lock already held by the same thread deadlocks forever.
+// TryLockWithTimeout() returns an error describing either deadlock, and should
+// be used wherever a lock is acquired while another is held. As Lock() cannot
+// return an error, it only logs the violation before waiting for the lock.
+// Locks are tracked by thread, so the goroutine acquiring an OrderedLocker is
+// locked to its thread until the lock is released. As with SHM locks, the lock
+// must be unlocked by the goroutine that locked it.
+type OrderedLocker struct {
+	Locker
+	level uint
+	name  string
+}
+
+// NewOrderedLocker wraps the given lock to enforce the lock ordering. name
+// describes what the lock protects, for use in errors.
+func NewOrderedLocker(locker Locker, level uint, name string) *OrderedLocker {
+	return &OrderedLocker{
+		Locker: locker,
+		level:  level,
+		name:   name,
+	}
+}
+
+// Lock acquires the lock.
+func (l *OrderedLocker) Lock() {
+	runtime.LockOSThread()
+	tid := unix.Gettid()
+
+	if _, err := l.checkOrder(tid); err != nil {
+		logrus.Errorf("%v - waiting for it", err)
+	}
+	l.Locker.Lock()
+
+	l.addHeld(tid)
+}
+
+// TryLockWithTimeout acquires the lock, waiting at most the given timeout for
+// it to become available.
+func (l *OrderedLocker) TryLockWithTimeout(timeout time.Duration) error {
+	runtime.LockOSThread()
+	tid := unix.Gettid()
+
+	violation, err := l.checkOrder(tid)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	if err := l.Locker.TryLockWithTimeout(timeout); err != nil {
+		runtime.UnlockOSThread()
+		if violation != nil && errors.Cause(err) == define.ErrLockTimeout {
+			return l.deadlockError(violation, err)
+		}
+		return err
+	}
+
+	l.addHeld(tid)
+	return nil
+}
+
+// Unlock releases the lock.
+func (l *OrderedLocker) Unlock() {
+	tid := unix.Gettid()
+
+	heldLocksLock.Lock()
+	held := heldLocks[tid]
+	for i := len(held) - 1; i >= 0; i-- {
+		if held[i].ID() == l.ID() {
+			held = append(held[:i], held[i+1:]...)
+			break
+		}
+	}
+	if len(held) == 0 {
+		delete(heldLocks, tid)
+	} else {
+		heldLocks[tid] = held
+	}
+	heldLocksLock.Unlock()
+
+	l.Locker.Unlock()
+	runtime.UnlockOSThread()
+}
+
+// checkOrder checks whether acquiring the lock on the given thread would
+// violate the lock ordering. If so, it returns the held lock that should have
+// been acquired after this one. An error is returned if the thread already
+// holds the lock.
+func (l *OrderedLocker) checkOrder(tid int) (*OrderedLocker, error) {
+	heldLocksLock.Lock()
+	defer heldLocksLock.Unlock()
+
+	var violation *OrderedLocker
+	for _, held := range heldLocks[tid] {
+		if held.ID() == l.ID() {
+			return nil, errors.Wrapf(define.ErrDeadlock, "lock for %s is already held by this thread and cannot be acquired again", l.name)
+		}
+		if held.level > l.level && violation == nil {
+			violation = held
+		}
+	}
+	if violation != nil {
+		logrus.Errorf("Lock ordering violated: acquiring lock for %s while holding lock for %s, which must be acquired after it", l.name, violation.name)
+	}
+	return violation, nil
+}
+
+// deadlockError describes a deadlock on a lock acquired in violation of the
+// lock ordering.
+func (l *OrderedLocker) deadlockError(violation *OrderedLocker, cause error) error {
+	msg := fmt.Sprintf("lock for %s could not be acquired while holding lock for %s, which must be acquired after it", l.name, violation.name)
+	if holder, err := l.Locker.Holder(); err == nil && holder != nil {
+		msg = fmt.Sprintf("%s - lock is held by %s", msg, holder)
+	}
+	return errors.Wrapf(define.ErrDeadlock, "%s: %v", msg, cause)
+}
+
+// addHeld records that the lock is held by the given thread.
+func (l *OrderedLocker) addHeld(tid int) {
+	heldLocksLock.Lock()
+	defer heldLocksLock.Unlock()
+
+	heldLocks[tid] = append(heldLocks[tid], l)
+}
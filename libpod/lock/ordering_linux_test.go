@@ -0,0 +1,94 @@
+// +build linux
+
+package lock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getOrderedLocks(t *testing.T) (*OrderedLocker, *OrderedLocker) {
+	manager, err := NewInMemoryManager(16)
+	require.NoError(t, err)
+
+	low, err := manager.AllocateLock()
+	require.NoError(t, err)
+	high, err := manager.AllocateLock()
+	require.NoError(t, err)
+
+	return NewOrderedLocker(low, 1, "low"), NewOrderedLocker(high, 2, "high")
+}
+
+func TestOrderedLockerInOrderSucceeds(t *testing.T) {
+	low, high := getOrderedLocks(t)
+
+	low.Lock()
+	high.Lock()
+	high.Unlock()
+	low.Unlock()
+
+	// Once released, the locks can be acquired in any order
+	high.Lock()
+	high.Unlock()
+	low.Lock()
+	low.Unlock()
+}
+
+func TestOrderedLockerRelockErrors(t *testing.T) {
+	low, _ := getOrderedLocks(t)
+
+	low.Lock()
+	defer low.Unlock()
+
+	err := low.TryLockWithTimeout(10 * time.Millisecond)
+	assert.Equal(t, define.ErrDeadlock, errors.Cause(err))
+}
+
+func TestOrderedLockerOutOfOrderLockWaits(t *testing.T) {
+	low, high := getOrderedLocks(t)
+
+	locked := make(chan bool)
+	go func() {
+		low.Lock()
+		locked <- true
+		time.Sleep(50 * time.Millisecond)
+		low.Unlock()
+	}()
+	<-locked
+
+	high.Lock()
+	defer high.Unlock()
+
+	// The violation is logged, and the lock acquired once released
+	assert.NotPanics(t, func() { low.Lock() })
+	low.Unlock()
+}
+
+func TestOrderedLockerOutOfOrderDeadlockErrors(t *testing.T) {
+	low, high := getOrderedLocks(t)
+
+	// Hold the low lock elsewhere, as an operation acquiring the locks in
+	// order would
+	locked := make(chan bool)
+	release := make(chan bool)
+	go func() {
+		low.Lock()
+		locked <- true
+		<-release
+		low.Unlock()
+	}()
+	<-locked
+	defer close(release)
+
+	high.Lock()
+	defer high.Unlock()
+
+	err := low.TryLockWithTimeout(10 * time.Millisecond)
+	assert.Error(t, err)
+	assert.Equal(t, define.ErrDeadlock, errors.Cause(err))
+}
@@ -0,0 +1,15 @@
+// +build !linux
+
+package lock
+
+// OrderedLocker is a Locker that enforces an order in which locks must be
+// acquired. Lock ordering is not enforced on this platform.
+type OrderedLocker struct {
+	Locker
+}
+
+// NewOrderedLocker wraps the given lock. Lock ordering is not enforced on this
+// platform.
+func NewOrderedLocker(locker Locker, level uint, name string) *OrderedLocker {
+	return &OrderedLocker{Locker: locker}
+}
@@ -7,6 +7,7 @@
 #include <sys/mman.h>
 #include <sys/stat.h>
 #include <sys/types.h>
+#include <time.h>
 #include <unistd.h>
 
 #include "shm_lock.h"
@@ -44,6 +45,31 @@ static int take_mutex(pthread_mutex_t *mutex) {
   return 0;
 }
 
+// Take the given mutex, waiting no later than the given deadline.
+// Handles exceptional conditions in the same way as take_mutex().
+// Returns 0 on success, ETIMEDOUT if the deadline passed, or positive errno on
+// failure.
+static int take_mutex_timed(pthread_mutex_t *mutex, const struct timespec *deadline) {
+  int ret_code;
+
+  do {
+    ret_code = pthread_mutex_timedlock(mutex, deadline);
+  } while(ret_code == EAGAIN);
+
+  if (ret_code == EOWNERDEAD) {
+    // The previous owner of the mutex died while holding it
+    // Take it for ourselves
+    ret_code = pthread_mutex_consistent(mutex);
+    if (ret_code != 0) {
+      return ret_code;
+    }
+  } else if (ret_code != 0) {
+    return ret_code;
+  }
+
+  return 0;
+}
+
 // Release the given mutex.
 // Returns 0 on success, or positive errno on failure.
 static int release_mutex(pthread_mutex_t *mutex) {
@@ -272,6 +298,45 @@ shm_struct_t *open_lock_shm(char *path, uint32_t num_locks, int *error_code) {
   return NULL;
 }
 
+// Read the number of locks held by an existing SHM segment holding libpod
+// locks, without opening the segment for use.
+// Path is the path to the SHM segment, as for open_lock_shm().
+// Returns the number of locks on success, or negative ERRNO values on failure.
+int64_t read_num_locks_shm(char *path) {
+  int shm_fd;
+  shm_struct_t *shm;
+  int64_t num_locks;
+
+  if (path == NULL) {
+    return -1 * EINVAL;
+  }
+
+  shm_fd = shm_open(path, O_RDONLY, 0600);
+  if (shm_fd < 0) {
+    return -1 * errno;
+  }
+
+  // Only map the header, which is all we need
+  shm = mmap(NULL, sizeof(shm_struct_t), PROT_READ, MAP_SHARED, shm_fd, 0);
+  if (shm == MAP_FAILED) {
+    num_locks = -1 * errno;
+    close(shm_fd);
+    return num_locks;
+  }
+
+  close(shm_fd);
+
+  if (shm->magic != MAGIC) {
+    num_locks = -1 * EBADF;
+  } else {
+    num_locks = shm->num_locks;
+  }
+
+  munmap(shm, sizeof(shm_struct_t));
+
+  return num_locks;
+}
+
 // Close an open SHM lock struct, unmapping the backing memory.
 // The given shm_struct_t will be rendered unusable as a result.
 // On success, 0 is returned. On failure, negative ERRNO values are returned.
@@ -537,3 +602,66 @@ int32_t unlock_semaphore(shm_struct_t *shm, uint32_t sem_index) {
 
   return -1 * release_mutex(&(shm->locks[bitmap_index].locks[index_in_bitmap]));
 }
+
+// Lock a given semaphore, waiting at most timeout_ns nanoseconds for it to
+// become available.
+// Does not check if the semaphore is allocated - this ensures that, even for
+// removed containers, we can still successfully lock to check status (and
+// subsequently realize they have been removed).
+// Returns 0 on success, -1 * ETIMEDOUT if the timeout expired, or negative
+// errno on failure.
+int32_t timed_lock_semaphore(shm_struct_t *shm, uint32_t sem_index, uint64_t timeout_ns) {
+  int bitmap_index, index_in_bitmap;
+  struct timespec deadline;
+
+  if (shm == NULL) {
+    return -1 * EINVAL;
+  }
+
+  if (sem_index >= shm->num_locks) {
+    return -1 * EINVAL;
+  }
+
+  // pthread_mutex_timedlock() takes an absolute time on CLOCK_REALTIME
+  if (clock_gettime(CLOCK_REALTIME, &deadline) != 0) {
+    return -1 * errno;
+  }
+  deadline.tv_sec += timeout_ns / 1000000000;
+  deadline.tv_nsec += timeout_ns % 1000000000;
+  if (deadline.tv_nsec >= 1000000000) {
+    deadline.tv_sec++;
+    deadline.tv_nsec -= 1000000000;
+  }
+
+  bitmap_index = sem_index / BITMAP_SIZE;
+  index_in_bitmap = sem_index % BITMAP_SIZE;
+
+  return -1 * take_mutex_timed(&(shm->locks[bitmap_index].locks[index_in_bitmap]), &deadline);
+}
+
+// Get the owner of a given semaphore.
+// The owner is read without taking the semaphore, so it may be out of date by
+// the time it is returned. It is intended for diagnostics only.
+// Returns the thread ID of the thread holding the semaphore, 0 if the
+// semaphore is not held, or negative errno on failure.
+// Only supported with glibc, which records the owner of its mutexes.
+int64_t semaphore_owner(shm_struct_t *shm, uint32_t sem_index) {
+  int bitmap_index, index_in_bitmap;
+
+  if (shm == NULL) {
+    return -1 * EINVAL;
+  }
+
+  if (sem_index >= shm->num_locks) {
+    return -1 * EINVAL;
+  }
+
+  bitmap_index = sem_index / BITMAP_SIZE;
+  index_in_bitmap = sem_index % BITMAP_SIZE;
+
+#ifdef __GLIBC__
+  return (int64_t)shm->locks[bitmap_index].locks[index_in_bitmap].__data.__owner;
+#else
+  return -1 * ENOTSUP;
+#endif
+}
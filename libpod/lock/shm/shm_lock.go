@@ -12,6 +12,7 @@ import "C"
 import (
 	"runtime"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/pkg/errors"
@@ -90,6 +91,21 @@ func OpenSHMLock(path string, numLocks uint32) (*SHMLocks, error) {
 	return locks, nil
 }
 
+// ReadSHMLockCount returns the number of locks held by an existing
+// shared-memory segment, without opening the segment for use.
+func ReadSHMLockCount(path string) (uint32, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	retCode := C.read_num_locks_shm(cPath)
+	if retCode < 0 {
+		// Negative errno returned
+		return 0, errors.Wrapf(syscall.Errno(-1*retCode), "failed to read number of locks in %s", path)
+	}
+
+	return uint32(retCode), nil
+}
+
 // GetMaxLocks returns the maximum number of locks in the SHM
 func (locks *SHMLocks) GetMaxLocks() uint32 {
 	return locks.maxLocks
@@ -191,6 +207,32 @@ func (locks *SHMLocks) DeallocateAllSemaphores() error {
 	return nil
 }
 
+// AllocatedSemaphores returns the indexes of all allocated semaphores.
+// Each free semaphore is briefly allocated while looking for them.
+func (locks *SHMLocks) AllocatedSemaphores() ([]uint32, error) {
+	if !locks.valid {
+		return nil, errors.Wrapf(syscall.EINVAL, "locks have already been closed")
+	}
+
+	var allocated []uint32
+	for sem := uint32(0); sem < locks.maxLocks; sem++ {
+		retCode := C.allocate_given_semaphore(locks.lockStruct, C.uint32_t(sem))
+		if retCode == -1*C.int32_t(syscall.EEXIST) {
+			allocated = append(allocated, sem)
+			continue
+		}
+		if retCode < 0 {
+			return nil, syscall.Errno(-1 * retCode)
+		}
+		retCode = C.deallocate_semaphore(locks.lockStruct, C.uint32_t(sem))
+		if retCode < 0 {
+			return nil, syscall.Errno(-1 * retCode)
+		}
+	}
+
+	return allocated, nil
+}
+
 // LockSemaphore locks the given semaphore.
 // If the semaphore is already locked, LockSemaphore will block until the lock
 // can be acquired.
@@ -220,6 +262,61 @@ func (locks *SHMLocks) LockSemaphore(sem uint32) error {
 	return nil
 }
 
+// TimedLockSemaphore locks the given semaphore, waiting at most the given
+// timeout for it to become available. If the timeout expires before the
+// semaphore can be acquired, ETIMEDOUT is returned.
+// As with LockSemaphore, there is no requirement that the given semaphore be
+// allocated.
+func (locks *SHMLocks) TimedLockSemaphore(sem uint32, timeout time.Duration) error {
+	if !locks.valid {
+		return errors.Wrapf(syscall.EINVAL, "locks have already been closed")
+	}
+
+	if sem > locks.maxLocks {
+		return errors.Wrapf(syscall.EINVAL, "given semaphore %d is higher than maximum locks count %d", sem, locks.maxLocks)
+	}
+
+	if timeout < 0 {
+		timeout = 0
+	}
+
+	// For pthread mutexes, we have to guarantee lock and unlock happen in
+	// the same thread.
+	runtime.LockOSThread()
+
+	retCode := C.timed_lock_semaphore(locks.lockStruct, C.uint32_t(sem), C.uint64_t(timeout.Nanoseconds()))
+	if retCode < 0 {
+		// We did not get the lock, so will not be unlocking it
+		runtime.UnlockOSThread()
+		// Negative errno returned
+		return syscall.Errno(-1 * retCode)
+	}
+
+	return nil
+}
+
+// SemaphoreOwner returns the thread ID of the thread holding the given
+// semaphore, or 0 if it is not held.
+// The owner is read without taking the semaphore, and may be out of date by the
+// time it is returned. It should only be used for diagnostics.
+func (locks *SHMLocks) SemaphoreOwner(sem uint32) (int, error) {
+	if !locks.valid {
+		return 0, errors.Wrapf(syscall.EINVAL, "locks have already been closed")
+	}
+
+	if sem > locks.maxLocks {
+		return 0, errors.Wrapf(syscall.EINVAL, "given semaphore %d is higher than maximum locks count %d", sem, locks.maxLocks)
+	}
+
+	retCode := C.semaphore_owner(locks.lockStruct, C.uint32_t(sem))
+	if retCode < 0 {
+		// Negative errno returned
+		return 0, syscall.Errno(-1 * retCode)
+	}
+
+	return int(retCode), nil
+}
+
 // UnlockSemaphore unlocks the given semaphore.
 // Unlocking a semaphore that is already unlocked with return EBUSY.
 // There is no requirement that the given semaphore be allocated.
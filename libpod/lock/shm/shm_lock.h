@@ -34,6 +34,7 @@ typedef struct shm_struct {
 
 shm_struct_t *setup_lock_shm(char *path, uint32_t num_locks, int *error_code);
 shm_struct_t *open_lock_shm(char *path, uint32_t num_locks, int *error_code);
+int64_t read_num_locks_shm(char *path);
 int32_t close_lock_shm(shm_struct_t *shm);
 int64_t allocate_semaphore(shm_struct_t *shm);
 int32_t allocate_given_semaphore(shm_struct_t *shm, uint32_t sem_index);
@@ -41,5 +42,7 @@ int32_t deallocate_semaphore(shm_struct_t *shm, uint32_t sem_index);
 int32_t deallocate_all_semaphores(shm_struct_t *shm);
 int32_t lock_semaphore(shm_struct_t *shm, uint32_t sem_index);
 int32_t unlock_semaphore(shm_struct_t *shm, uint32_t sem_index);
+int32_t timed_lock_semaphore(shm_struct_t *shm, uint32_t sem_index, uint64_t timeout_ns);
+int64_t semaphore_owner(shm_struct_t *shm, uint32_t sem_index);
 
 #endif
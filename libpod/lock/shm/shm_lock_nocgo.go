@@ -3,6 +3,8 @@
 package shm
 
 import (
+	"time"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -28,6 +30,13 @@ func OpenSHMLock(path string, numLocks uint32) (*SHMLocks, error) {
 	return &SHMLocks{}, nil
 }
 
+// ReadSHMLockCount returns the number of locks held by an existing
+// shared-memory segment, without opening the segment for use.
+func ReadSHMLockCount(path string) (uint32, error) {
+	logrus.Error("locks are not supported without cgo")
+	return 0, nil
+}
+
 // GetMaxLocks returns the maximum number of locks in the SHM
 func (locks *SHMLocks) GetMaxLocks() uint32 {
 	logrus.Error("locks are not supported without cgo")
@@ -78,6 +87,13 @@ func (locks *SHMLocks) DeallocateAllSemaphores() error {
 	return nil
 }
 
+// AllocatedSemaphores returns the indexes of all allocated semaphores.
+// Each free semaphore is briefly allocated while looking for them.
+func (locks *SHMLocks) AllocatedSemaphores() ([]uint32, error) {
+	logrus.Error("locks are not supported without cgo")
+	return nil, nil
+}
+
 // LockSemaphore locks the given semaphore.
 // If the semaphore is already locked, LockSemaphore will block until the lock
 // can be acquired.
@@ -90,6 +106,20 @@ func (locks *SHMLocks) LockSemaphore(sem uint32) error {
 	return nil
 }
 
+// TimedLockSemaphore locks the given semaphore, waiting at most the given
+// timeout for it to become available.
+func (locks *SHMLocks) TimedLockSemaphore(sem uint32, timeout time.Duration) error {
+	logrus.Error("locks are not supported without cgo")
+	return nil
+}
+
+// SemaphoreOwner returns the thread ID of the thread holding the given
+// semaphore, or 0 if it is not held.
+func (locks *SHMLocks) SemaphoreOwner(sem uint32) (int, error) {
+	logrus.Error("locks are not supported without cgo")
+	return 0, nil
+}
+
 // UnlockSemaphore unlocks the given semaphore.
 // Unlocking a semaphore that is already unlocked with return EBUSY.
 // There is no requirement that the given semaphore be allocated.
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"syscall"
 	"testing"
 	"time"
 
@@ -69,6 +70,16 @@ func runLockTest(t *testing.T, testFunc func(*testing.T, *SHMLocks)) {
 	}
 }
 
+// Test that the number of locks can be read from an existing SHM
+func TestReadSHMLockCount(t *testing.T) {
+	count, err := ReadSHMLockCount(lockPath)
+	assert.NoError(t, err)
+	assert.Equal(t, numLocks, count)
+
+	_, err = ReadSHMLockCount("/libpod_test_does_not_exist")
+	assert.Error(t, err)
+}
+
 // Test that creating an SHM with a bad size rounds up to a good size
 func TestCreateNewSHMBadSizeRoundsUp(t *testing.T) {
 	// Odd number, not a power of 2, should never be a word size on a system
@@ -96,6 +107,25 @@ func TestDeallocateUnallocatedLockErrors(t *testing.T) {
 	})
 }
 
+// Test that allocated locks are listed, and free locks are left free
+func TestAllocatedSemaphores(t *testing.T) {
+	runLockTest(t, func(t *testing.T, locks *SHMLocks) {
+		allocated, err := locks.AllocatedSemaphores()
+		assert.NoError(t, err)
+		assert.Empty(t, allocated)
+
+		assert.NoError(t, locks.AllocateGivenSemaphore(2))
+		assert.NoError(t, locks.AllocateGivenSemaphore(5))
+		allocated, err = locks.AllocatedSemaphores()
+		assert.NoError(t, err)
+		assert.Equal(t, []uint32{2, 5}, allocated)
+
+		sem, err := locks.AllocateSemaphore()
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(0), sem)
+	})
+}
+
 // Test that unlocking an unlocked lock fails
 func TestUnlockingUnlockedLockFails(t *testing.T) {
 	runLockTest(t, func(t *testing.T, locks *SHMLocks) {
@@ -290,3 +320,47 @@ func TestLockAndUnlockTwoSemaphore(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+// Test that a timed lock succeeds on an unlocked semaphore and times out on a
+// locked one
+func TestTimedLockSemaphore(t *testing.T) {
+	runLockTest(t, func(t *testing.T, locks *SHMLocks) {
+		sem, err := locks.AllocateSemaphore()
+		require.NoError(t, err)
+
+		err = locks.TimedLockSemaphore(sem, time.Second)
+		assert.NoError(t, err)
+
+		// Lock from another thread so the mutex is not re-entered
+		errChan := make(chan error)
+		go func() {
+			errChan <- locks.TimedLockSemaphore(sem, 10*time.Millisecond)
+		}()
+		assert.Equal(t, syscall.ETIMEDOUT, <-errChan)
+
+		err = locks.UnlockSemaphore(sem)
+		assert.NoError(t, err)
+	})
+}
+
+// Test that the owner of a semaphore is reported while it is locked
+func TestSemaphoreOwner(t *testing.T) {
+	runLockTest(t, func(t *testing.T, locks *SHMLocks) {
+		sem, err := locks.AllocateSemaphore()
+		require.NoError(t, err)
+
+		owner, err := locks.SemaphoreOwner(sem)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, owner)
+
+		err = locks.LockSemaphore(sem)
+		assert.NoError(t, err)
+
+		owner, err = locks.SemaphoreOwner(sem)
+		assert.NoError(t, err)
+		assert.NotEqual(t, 0, owner)
+
+		err = locks.UnlockSemaphore(sem)
+		assert.NoError(t, err)
+	})
+}
@@ -3,8 +3,16 @@
 package lock
 
 import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/lock/shm"
 	"github.com/pkg/errors"
 )
@@ -43,6 +51,55 @@ func OpenSHMLockManager(path string, numLocks uint32) (Manager, error) {
 	return manager, nil
 }
 
+// SHMLockCount returns the number of locks held by the existing SHM lock
+// segment at the given path.
+func SHMLockCount(path string) (uint32, error) {
+	return shm.ReadSHMLockCount(path)
+}
+
+// Resize replaces the manager's shared memory segment, at the given path, with
+// a new one holding numLocks locks. Allocated locks remain allocated, and must
+// fit in the new segment. Locks retrieved from the manager use the new
+// segment. The previous segment is unlinked and unmapped.
+// WARNING: Other processes using the previous segment are not excluded by locks
+// taken in the new one. No locks of the manager may be held while it is
+// resized.
+func (m *SHMLockManager) Resize(path string, numLocks uint32) error {
+	allocated, err := m.locks.AllocatedSemaphores()
+	if err != nil {
+		return err
+	}
+	for _, id := range allocated {
+		if id >= numLocks {
+			return errors.Wrapf(syscall.ERANGE, "lock %d is allocated, and does not fit in %d locks", id, numLocks)
+		}
+	}
+
+	if err := os.Remove(filepath.Join("/dev/shm", path)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "error removing SHM lock segment %s", path)
+	}
+	locks, err := shm.CreateSHMLock(path, numLocks)
+	if err != nil {
+		return err
+	}
+	for _, id := range allocated {
+		if err := locks.AllocateGivenSemaphore(id); err != nil {
+			locks.Close()
+			return errors.Wrapf(err, "error reallocating lock %d", id)
+		}
+	}
+
+	oldLocks := m.locks
+	m.locks = locks
+	return oldLocks.Close()
+}
+
+// Close unmaps the manager's shared memory segment. The manager and its locks
+// cannot be used afterwards.
+func (m *SHMLockManager) Close() error {
+	return m.locks.Close()
+}
+
 // AllocateLock allocates a new lock from the manager.
 func (m *SHMLockManager) AllocateLock() (Locker, error) {
 	semIndex, err := m.locks.AllocateSemaphore()
@@ -122,6 +179,65 @@ func (l *SHMLock) Unlock() {
 	}
 }
 
+// TryLockWithTimeout acquires the lock, waiting at most the given timeout for
+// it to become available.
+func (l *SHMLock) TryLockWithTimeout(timeout time.Duration) error {
+	if err := l.manager.locks.TimedLockSemaphore(l.lockID, timeout); err != nil {
+		if err == syscall.ETIMEDOUT {
+			return errors.Wrapf(define.ErrLockTimeout, "timed out after %s waiting for lock %d", timeout, l.lockID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Holder returns the process holding the lock.
+// The holder can only be determined if the process holding the lock is in our
+// PID namespace.
+func (l *SHMLock) Holder() (*Holder, error) {
+	tid, err := l.manager.locks.SemaphoreOwner(l.lockID)
+	if err != nil {
+		return nil, err
+	}
+	if tid == 0 {
+		return nil, nil
+	}
+	pid, err := pidForThread(tid)
+	if err != nil {
+		return nil, err
+	}
+	return holderForPID(pid), nil
+}
+
+// pidForThread returns the PID of the process the thread with the given ID
+// belongs to. It returns 0 if the thread does not exist.
+func pidForThread(tid int) (int, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", tid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, errors.Wrapf(err, "error reading status of thread %d", tid)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if !strings.HasPrefix(scanner.Text(), "Tgid:") {
+			continue
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "Tgid:")))
+		if err != nil {
+			return 0, errors.Wrapf(err, "error parsing process ID of thread %d", tid)
+		}
+		return pid, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, errors.Wrapf(err, "error reading status of thread %d", tid)
+	}
+	return 0, errors.Errorf("no process ID found for thread %d", tid)
+}
+
 // Free releases the lock, allowing it to be reused.
 func (l *SHMLock) Free() error {
 	return l.manager.locks.DeallocateSemaphore(l.lockID)
@@ -0,0 +1,43 @@
+// +build linux,cgo
+
+package lock
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSHMLockManagerResize(t *testing.T) {
+	path := "/libpod_test_resize"
+	defer os.Remove("/dev/shm" + path)
+	m, err := NewSHMLockManager(path, 32)
+	require.NoError(t, err)
+	manager := m.(*SHMLockManager)
+	defer manager.Close()
+
+	first, err := manager.AllocateLock()
+	require.NoError(t, err)
+	second, err := manager.AllocateAndRetrieveLock(20)
+	require.NoError(t, err)
+
+	// Allocated locks must fit
+	assert.Error(t, manager.Resize(path, 16))
+
+	require.NoError(t, manager.Resize(path, 64))
+	count, err := SHMLockCount(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(64), count)
+
+	// Allocated locks remain allocated, and usable
+	_, err = manager.AllocateAndRetrieveLock(first.ID())
+	assert.Error(t, err)
+	_, err = manager.AllocateAndRetrieveLock(second.ID())
+	assert.Error(t, err)
+	first.Lock()
+	first.Unlock()
+	_, err = manager.AllocateAndRetrieveLock(40)
+	assert.NoError(t, err)
+}
@@ -18,6 +18,21 @@ func OpenSHMLockManager(path string, numLocks uint32) (Manager, error) {
 	return nil, fmt.Errorf("not supported")
 }
 
+// SHMLockCount is not supported on this platform
+func SHMLockCount(path string) (uint32, error) {
+	return 0, fmt.Errorf("not supported")
+}
+
+// Resize is not supported on this platform
+func (m *SHMLockManager) Resize(path string, numLocks uint32) error {
+	return fmt.Errorf("not supported")
+}
+
+// Close is not supported on this platform
+func (m *SHMLockManager) Close() error {
+	return fmt.Errorf("not supported")
+}
+
 // AllocateLock is not supported on this platform
 func (m *SHMLockManager) AllocateLock() (Locker, error) {
 	return nil, fmt.Errorf("not supported")
@@ -0,0 +1,188 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hpcloud/tail"
+	"github.com/pkg/errors"
+)
+
+// maxLogLineLen is the maximum length of a line read from a log. Conmon splits
+// the output of containers into lines much shorter than this.
+const maxLogLineLen = 1024 * 1024
+
+// jsonFileLine is a line of a log in the format of the json-file log driver of
+// Docker. Partial lines are those whose message does not end with a newline.
+type jsonFileLine struct {
+	Log    string `json:"log"`
+	Stream string `json:"stream"`
+	Time   string `json:"time"`
+}
+
+// JSONFileWriter writes log lines to a file in the format of the json-file log
+// driver of Docker, rotating the file once it reaches a maximum size
+type JSONFileWriter struct {
+	path     string
+	maxSize  int64
+	maxFiles uint
+	file     *os.File
+	size     int64
+}
+
+// NewJSONFileWriter opens the json-file log at the given path for appending.
+// If maxSize is positive, the file is rotated when a line would make it grow
+// past that size. maxFiles is the number of files kept, including the one
+// being written: rotated files are renamed with increasing numeric suffixes,
+// and the oldest removed. If maxFiles is at most one, the file is truncated
+// instead.
+func NewJSONFileWriter(path string, maxSize int64, maxFiles uint) (*JSONFileWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening log file %s", path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "error reading size of log file %s", path)
+	}
+	return &JSONFileWriter{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+		file:     file,
+		size:     info.Size(),
+	}, nil
+}
+
+// WriteLine writes a log line to the file, rotating it first if needed
+func (w *JSONFileWriter) WriteLine(line *LogLine) error {
+	msg := line.Msg
+	if !line.Partial() {
+		msg += "\n"
+	}
+	b, err := json.Marshal(jsonFileLine{
+		Log:    msg,
+		Stream: line.Device,
+		Time:   line.Time.Format(LogTimeFormat),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error marshalling log line")
+	}
+	b = append(b, '\n')
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(b)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.file.Write(b)
+	w.size += int64(n)
+	if err != nil {
+		return errors.Wrapf(err, "error writing to log file %s", w.path)
+	}
+	return nil
+}
+
+// rotate shifts the rotated files of the log, and starts a new one
+func (w *JSONFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return errors.Wrapf(err, "error closing log file %s", w.path)
+	}
+	if w.maxFiles > 1 {
+		for i := w.maxFiles - 1; i > 0; i-- {
+			if err := os.Rename(rotatedLogPath(w.path, i-1), rotatedLogPath(w.path, i)); err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "error rotating log file %s", w.path)
+			}
+		}
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "error opening log file %s", w.path)
+	}
+	w.file = file
+	w.size = 0
+	return nil
+}
+
+// Close closes the log file
+func (w *JSONFileWriter) Close() error {
+	return w.file.Close()
+}
+
+// CopyToJSONFile reads the log lines written by conmon in the k8s-file format
+// from r, and writes them to w until r is exhausted
+func CopyToJSONFile(r io.Reader, w *JSONFileWriter) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLogLineLen)
+	for scanner.Scan() {
+		line, err := NewLogLine(scanner.Text())
+		if err != nil {
+			return err
+		}
+		if err := w.WriteLine(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// rotatedLogPath returns the path of the nth rotated file of the log at the
+// given path. The log itself is the 0th.
+func rotatedLogPath(path string, n uint) string {
+	if n == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// NewJSONFileLogLine creates a logLine struct from a line of a json-file log
+func NewJSONFileLogLine(line string) (*LogLine, error) {
+	var jsonLine jsonFileLine
+	if err := json.Unmarshal([]byte(line), &jsonLine); err != nil {
+		return nil, errors.Wrapf(err, "'%s' is not a valid json-file log line", line)
+	}
+	logTime, err := time.Parse(time.RFC3339Nano, jsonLine.Time)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to convert time %s from container log", jsonLine.Time)
+	}
+	l := LogLine{
+		Time:         logTime,
+		Device:       jsonLine.Stream,
+		ParseLogType: PartialLogType,
+		Msg:          jsonLine.Log,
+	}
+	if strings.HasSuffix(l.Msg, "\n") {
+		l.ParseLogType = FullLogType
+		l.Msg = strings.TrimSuffix(l.Msg, "\n")
+	}
+	return &l, nil
+}
+
+// GetJSONFileLog returns the lines of the json-file log at the given path,
+// including those of its rotated files, oldest first. Partial lines are joined
+// with the lines completing them. Only the lines between options.Since and
+// options.Until are returned, and only the last options.Tail of them if it is
+// positive.
+// If options.Follow is set, a tail of the log is also returned, starting after
+// the lines read. It follows the log across rotations.
+func GetJSONFileLog(path string, options *LogOptions) (*tail.Tail, []*LogLine, error) {
+	var files []string
+	for n := uint(1); ; n++ {
+		rotated := rotatedLogPath(path, n)
+		if _, err := os.Stat(rotated); err != nil {
+			if os.IsNotExist(err) {
+				break
+			}
+			return nil, nil, err
+		}
+		files = append([]string{rotated}, files...)
+	}
+	files = append(files, path)
+	return getLogFiles(files, NewJSONFileLogLine, options)
+}
@@ -0,0 +1,78 @@
+package logs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ctr.log")
+
+	// Each line is a little over 80 bytes, so every file holds two
+	w, err := NewJSONFileWriter(path, 200, 3)
+	require.NoError(t, err)
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		err := w.WriteLine(&LogLine{Time: start.Add(time.Duration(i) * time.Second), Device: "stdout", ParseLogType: FullLogType, Msg: strings.Repeat("x", i)})
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	for _, rotated := range []string{path + ".1", path + ".2"} {
+		_, err := os.Stat(rotated)
+		assert.NoError(t, err)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	_, lines, err := GetJSONFileLog(path, &LogOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 5, len(lines))
+	for i, line := range lines {
+		assert.Equal(t, strings.Repeat("x", i+2), line.Msg)
+		assert.Equal(t, "stdout", line.Device)
+	}
+
+	_, lines, err = GetJSONFileLog(path, &LogOptions{Tail: 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lines))
+	assert.Equal(t, "xxxxx", lines[0].Msg)
+	assert.Equal(t, "xxxxxx", lines[1].Msg)
+}
+
+func TestCopyToJSONFileJoinsPartialLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ctr.log")
+
+	w, err := NewJSONFileWriter(path, 0, 1)
+	require.NoError(t, err)
+	k8sLog := "2019-10-01T10:00:00.000000000Z stdout P hello \n" +
+		"2019-10-01T10:00:01.000000000Z stdout F world\n" +
+		"2019-10-01T10:00:02.000000000Z stderr F oops\n"
+	require.NoError(t, CopyToJSONFile(strings.NewReader(k8sLog), w))
+	require.NoError(t, w.Close())
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"log":"hello "`)
+	assert.Contains(t, string(content), `"log":"world\n"`)
+
+	_, lines, err := GetJSONFileLog(path, &LogOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lines))
+	assert.Equal(t, "hello world", lines[0].Msg)
+	assert.Equal(t, "oops", lines[1].Msg)
+	assert.Equal(t, "stderr", lines[1].Device)
+}
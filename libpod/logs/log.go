@@ -1,8 +1,10 @@
 package logs
 
 import (
+	"bufio"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -31,6 +33,7 @@ type LogOptions struct {
 	Details    bool
 	Follow     bool
 	Since      time.Time
+	Until      time.Time
 	Tail       uint64
 	Timestamps bool
 	Multi      bool
@@ -46,71 +49,80 @@ type LogLine struct {
 	CID          string
 }
 
-// GetLogFile returns an hp tail for a container given options
+// GetLogFile returns the lines of the k8s-file log at the given path, and a
+// tail following it if options.Follow is set. Lines are selected and followed
+// as by GetJSONFileLog.
 func GetLogFile(path string, options *LogOptions) (*tail.Tail, []*LogLine, error) {
+	return getLogFiles([]string{path}, NewLogLine, options)
+}
+
+// getLogFiles returns the lines of the given log files, oldest first, parsed
+// by parse. Partial lines are joined with the lines completing them. Only the
+// lines between options.Since and options.Until are returned, and only the
+// last options.Tail of them if it is positive.
+// If options.Follow is set, a tail of the last file is also returned, starting
+// after the lines read. It follows the file across rotations.
+func getLogFiles(files []string, parse func(string) (*LogLine, error), options *LogOptions) (*tail.Tail, []*LogLine, error) {
 	var (
-		whence  int
-		err     error
-		logTail []*LogLine
+		lines   []*LogLine
+		partial string
+		offset  int64
 	)
-	// whence 0=origin, 2=end
-	if options.Tail > 0 {
-		whence = 2
-		logTail, err = getTailLog(path, int(options.Tail))
+	for _, file := range files {
+		read, err := readLogFile(file, parse, func(line *LogLine) {
+			if line.Partial() {
+				partial += line.Msg
+				return
+			}
+			line.Msg = partial + line.Msg
+			partial = ""
+			if line.Since(options.Since) && line.Until(options.Until) {
+				lines = append(lines, line)
+			}
+		})
 		if err != nil {
 			return nil, nil, err
 		}
+		offset = read
 	}
-	seek := tail.SeekInfo{
-		Offset: 0,
-		Whence: whence,
+	if options.Tail > 0 && uint64(len(lines)) > options.Tail {
+		lines = lines[uint64(len(lines))-options.Tail:]
 	}
 
-	t, err := tail.TailFile(path, tail.Config{MustExist: true, Poll: true, Follow: options.Follow, Location: &seek, Logger: tail.DiscardingLogger})
-	return t, logTail, err
+	if !options.Follow {
+		return nil, lines, nil
+	}
+	seek := tail.SeekInfo{
+		Offset: offset,
+		Whence: io.SeekStart,
+	}
+	t, err := tail.TailFile(files[len(files)-1], tail.Config{ReOpen: true, Poll: true, Follow: true, Location: &seek, Logger: tail.DiscardingLogger})
+	return t, lines, err
 }
 
-func getTailLog(path string, tail int) ([]*LogLine, error) {
-	var (
-		tailLog     []*LogLine
-		nlls        []*LogLine
-		tailCounter int
-		partial     string
-	)
-	content, err := ioutil.ReadFile(path)
+// readLogFile parses the lines of a log file, and returns the number of bytes
+// read
+func readLogFile(path string, parse func(string) (*LogLine, error), handle func(*LogLine)) (int64, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	splitContent := strings.Split(string(content), "\n")
-	// We read the content in reverse and add each nll until we have the same
-	// number of F type messages as the desired tail
-	for i := len(splitContent) - 1; i >= 0; i-- {
-		if len(splitContent[i]) == 0 {
+	defer f.Close()
+	var read int64
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxLogLineLen)
+	for scanner.Scan() {
+		read += int64(len(scanner.Bytes())) + 1
+		if len(scanner.Bytes()) == 0 {
 			continue
 		}
-		nll, err := NewLogLine(splitContent[i])
+		line, err := parse(scanner.Text())
 		if err != nil {
-			return nil, err
-		}
-		nlls = append(nlls, nll)
-		if !nll.Partial() {
-			tailCounter = tailCounter + 1
-		}
-		if tailCounter == tail {
-			break
-		}
-	}
-	// Now we iterate the results and assemble partial messages to become full messages
-	for _, nll := range nlls {
-		if nll.Partial() {
-			partial = partial + nll.Msg
-		} else {
-			nll.Msg = nll.Msg + partial
-			tailLog = append(tailLog, nll)
-			partial = ""
+			return read, err
 		}
+		handle(line)
 	}
-	return tailLog, nil
+	return read, scanner.Err()
 }
 
 // String converts a logline to a string for output given whether a detail
@@ -135,6 +147,12 @@ func (l *LogLine) Since(since time.Time) bool {
 	return l.Time.After(since)
 }
 
+// Until returns a bool as to whether a log line occurred before a given time.
+// All lines occurred before the zero time.
+func (l *LogLine) Until(until time.Time) bool {
+	return until.IsZero() || l.Time.Before(until)
+}
+
 // NewLogLine creates a logLine struct from a container log string
 func NewLogLine(line string) (*LogLine, error) {
 	splitLine := strings.Split(line, " ")
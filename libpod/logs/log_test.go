@@ -0,0 +1,55 @@
+package logs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogFileSinceUntilTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ctr.log")
+
+	start := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	var content string
+	for i := 0; i < 6; i++ {
+		logTime := start.Add(time.Duration(i) * time.Second).Format(LogTimeFormat)
+		// Every other line is split in two partial lines
+		if i%2 == 1 {
+			content += fmt.Sprintf("%s stdout %s line\n", logTime, PartialLogType)
+		}
+		content += fmt.Sprintf("%s stdout %s %d\n", logTime, FullLogType, i)
+	}
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	_, lines, err := GetLogFile(path, &LogOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 6, len(lines))
+	assert.Equal(t, "line1", lines[1].Msg)
+
+	_, lines, err = GetLogFile(path, &LogOptions{
+		Since: start.Add(time.Second / 2),
+		Until: start.Add(4 * time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(lines))
+	assert.Equal(t, "line1", lines[0].Msg)
+	assert.Equal(t, "line3", lines[2].Msg)
+
+	_, lines, err = GetLogFile(path, &LogOptions{
+		Until: start.Add(4 * time.Second),
+		Tail:  2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(lines))
+	assert.Equal(t, "2", lines[0].Msg)
+	assert.Equal(t, "line3", lines[1].Msg)
+}
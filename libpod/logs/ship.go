@@ -0,0 +1,485 @@
+package logs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ShipperConnected is the state of a log shipper sending lines to its
+	// endpoint
+	ShipperConnected = "connected"
+	// ShipperBuffering is the state of a log shipper spilling lines to disk
+	// while its endpoint is unreachable
+	ShipperBuffering = "buffering"
+
+	// shipperRetryInterval is the minimum delay between attempts to
+	// reconnect to an unreachable endpoint
+	shipperRetryInterval = 5 * time.Second
+	// shipperTimeout bounds connecting and writing to an endpoint
+	shipperTimeout = 5 * time.Second
+	// shipperStatusInterval is the minimum delay between writes of the
+	// status file while only the counts of lines change
+	shipperStatusInterval = time.Second
+	// shipperMaxSpillSize is the size the spill file may grow to. Lines
+	// logged once it is full are dropped.
+	shipperMaxSpillSize = 64 * 1024 * 1024
+)
+
+// LogSender sends log lines to a remote endpoint
+type LogSender interface {
+	// Send sends a single log line
+	Send(line *LogLine) error
+	// Close closes the connection to the endpoint
+	Close() error
+}
+
+// ShipperStatus is the health of a log shipper, as written to its status file
+type ShipperStatus struct {
+	// State is ShipperConnected or ShipperBuffering
+	State string `json:"state"`
+	// Error is the last error sending lines to the endpoint
+	Error string `json:"error,omitempty"`
+	// Buffered is the number of lines spilled to disk and not sent yet
+	Buffered int `json:"buffered"`
+	// Dropped is the number of lines lost because the spill file was full
+	Dropped int `json:"dropped,omitempty"`
+	// Updated is when the status last changed
+	Updated time.Time `json:"updated"`
+}
+
+// ReadShipperStatus reads the status file of a log shipper
+func ReadShipperStatus(path string) (*ShipperStatus, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	status := new(ShipperStatus)
+	if err := json.Unmarshal(b, status); err != nil {
+		return nil, errors.Wrapf(err, "error decoding log shipper status %s", path)
+	}
+	return status, nil
+}
+
+// dialResult is the outcome of connecting to the endpoint of a log shipper
+type dialResult struct {
+	sender LogSender
+	err    error
+}
+
+// LogShipper sends log lines to a remote endpoint. While the endpoint is
+// unreachable, lines are spilled to a file on disk, and they are sent once it
+// is reachable again, before any newer line. Connections to the endpoint are
+// made in the background, so shipping never waits for them.
+type LogShipper struct {
+	dial          func() (LogSender, error)
+	dialing       bool
+	dialed        chan dialResult
+	sender        LogSender
+	lastDial      time.Time
+	spillPath     string
+	spill         *JSONFileWriter
+	statusPath    string
+	status        ShipperStatus
+	statusWritten time.Time
+	statusStale   bool
+}
+
+// NewLogShipper creates a log shipper connecting to its endpoint with dial.
+// Lines are spilled to the json-file log at spillPath, and the status of the
+// shipper is kept in the file at statusPath. Lines spilled by a previous
+// shipper using the same spill file are sent first, once the shipper is
+// connected.
+func NewLogShipper(dial func() (LogSender, error), spillPath, statusPath string) (*LogShipper, error) {
+	s := &LogShipper{
+		dial:       dial,
+		dialed:     make(chan dialResult, 1),
+		spillPath:  spillPath,
+		statusPath: statusPath,
+		status:     ShipperStatus{State: ShipperBuffering},
+	}
+	if _, err := readLogFile(spillPath, NewJSONFileLogLine, func(*LogLine) { s.status.Buffered++ }); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "error reading log spill file %s", spillPath)
+	}
+	if err := s.writeStatus(); err != nil {
+		return nil, err
+	}
+	s.startDial()
+	return s, nil
+}
+
+// Ship sends a log line to the endpoint, or spills it to disk if the endpoint
+// is unreachable
+func (s *LogShipper) Ship(line *LogLine) error {
+	s.collectDial(false)
+	if s.sender == nil && !s.dialing && time.Since(s.lastDial) >= shipperRetryInterval {
+		s.startDial()
+	}
+	if s.sender != nil && s.status.Buffered > 0 {
+		s.flush()
+	}
+	if s.sender != nil && s.status.Buffered == 0 {
+		err := s.sender.Send(line)
+		if err == nil {
+			return nil
+		}
+		s.disconnect(err)
+	}
+	return s.spillLine(line)
+}
+
+// Close makes a last attempt to send the spilled lines, and closes the
+// connection to the endpoint. Lines still spilled are kept for the next
+// shipper.
+func (s *LogShipper) Close() error {
+	s.collectDial(true)
+	if s.status.Buffered > 0 {
+		if s.sender == nil {
+			s.startDial()
+			s.collectDial(true)
+		}
+		if s.sender != nil {
+			s.flush()
+		}
+	}
+	var lastErr error
+	if s.statusStale {
+		lastErr = s.writeStatus()
+	}
+	if s.spill != nil {
+		lastErr = s.spill.Close()
+		s.spill = nil
+	}
+	if s.sender != nil {
+		if err := s.sender.Close(); err != nil {
+			lastErr = err
+		}
+		s.sender = nil
+	}
+	return lastErr
+}
+
+// startDial starts connecting to the endpoint in the background
+func (s *LogShipper) startDial() {
+	s.dialing = true
+	s.lastDial = time.Now()
+	go func() {
+		sender, err := s.dial()
+		s.dialed <- dialResult{sender: sender, err: err}
+	}()
+}
+
+// collectDial uses the connection made in the background, if any. With wait
+// set, it waits for the connection attempt in progress to end.
+func (s *LogShipper) collectDial(wait bool) {
+	if !s.dialing {
+		return
+	}
+	var result dialResult
+	if wait {
+		result = <-s.dialed
+	} else {
+		select {
+		case result = <-s.dialed:
+		default:
+			return
+		}
+	}
+	s.dialing = false
+	if result.err != nil {
+		logrus.Debugf("Error connecting to log endpoint: %v", result.err)
+		s.setError(result.err)
+		return
+	}
+	s.sender = result.sender
+	if s.status.Buffered == 0 {
+		s.setState(ShipperConnected)
+	}
+}
+
+// disconnect drops the connection to the endpoint after sending failed
+func (s *LogShipper) disconnect(err error) {
+	logrus.Debugf("Error sending log line, spilling to %s: %v", s.spillPath, err)
+	if err := s.sender.Close(); err != nil {
+		logrus.Debugf("Error closing connection to log endpoint: %v", err)
+	}
+	s.sender = nil
+	s.lastDial = time.Now()
+	s.setError(err)
+}
+
+// flush sends the spilled lines to the endpoint. Lines which could not be
+// sent are kept in the spill file.
+func (s *LogShipper) flush() {
+	if s.spill != nil {
+		if err := s.spill.Close(); err != nil {
+			logrus.Errorf("Error closing log spill file %s: %v", s.spillPath, err)
+		}
+		s.spill = nil
+	}
+	var lines []*LogLine
+	if _, err := readLogFile(s.spillPath, NewJSONFileLogLine, func(line *LogLine) { lines = append(lines, line) }); err != nil && !os.IsNotExist(err) {
+		logrus.Errorf("Error reading log spill file %s: %v", s.spillPath, err)
+		return
+	}
+	for i, line := range lines {
+		if err := s.sender.Send(line); err != nil {
+			if err := s.rewriteSpill(lines[i:]); err != nil {
+				logrus.Errorf("Error rewriting log spill file %s: %v", s.spillPath, err)
+			}
+			s.disconnect(err)
+			return
+		}
+	}
+	if err := os.Remove(s.spillPath); err != nil && !os.IsNotExist(err) {
+		logrus.Errorf("Error removing log spill file %s: %v", s.spillPath, err)
+	}
+	s.status.Buffered = 0
+	s.setState(ShipperConnected)
+}
+
+// spillLine appends a line to the spill file, or drops it if the file is full
+func (s *LogShipper) spillLine(line *LogLine) error {
+	if s.spill == nil {
+		spill, err := NewJSONFileWriter(s.spillPath, 0, 0)
+		if err != nil {
+			return err
+		}
+		s.spill = spill
+	}
+	if s.spill.size >= shipperMaxSpillSize {
+		s.status.Dropped++
+		return s.updateStatus()
+	}
+	if err := s.spill.WriteLine(line); err != nil {
+		return err
+	}
+	s.status.Buffered++
+	return s.updateStatus()
+}
+
+// rewriteSpill replaces the content of the spill file with the given lines
+func (s *LogShipper) rewriteSpill(lines []*LogLine) error {
+	tmpPath := s.spillPath + ".tmp"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	w, err := NewJSONFileWriter(tmpPath, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if err := w.WriteLine(line); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	s.status.Buffered = len(lines)
+	return os.Rename(tmpPath, s.spillPath)
+}
+
+// setState changes the state of the shipper, and records it
+func (s *LogShipper) setState(state string) {
+	if state == ShipperConnected {
+		s.status.Error = ""
+	}
+	s.status.State = state
+	if err := s.writeStatus(); err != nil {
+		logrus.Error(err)
+	}
+}
+
+// setError records an error reaching the endpoint
+func (s *LogShipper) setError(err error) {
+	s.status.Error = err.Error()
+	s.setState(ShipperBuffering)
+}
+
+// updateStatus records a change of the counts of lines. The status file is
+// written at most once per shipperStatusInterval for such changes, so spilling
+// every line does not rewrite it.
+func (s *LogShipper) updateStatus() error {
+	if time.Since(s.statusWritten) < shipperStatusInterval {
+		s.statusStale = true
+		return nil
+	}
+	return s.writeStatus()
+}
+
+// writeStatus atomically replaces the status file of the shipper
+func (s *LogShipper) writeStatus() error {
+	s.status.Updated = time.Now()
+	s.statusWritten = s.status.Updated
+	s.statusStale = false
+	b, err := json.Marshal(s.status)
+	if err != nil {
+		return errors.Wrapf(err, "error encoding log shipper status")
+	}
+	tmpPath := s.statusPath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, b, 0600); err != nil {
+		return errors.Wrapf(err, "error writing log shipper status %s", tmpPath)
+	}
+	return errors.Wrapf(os.Rename(tmpPath, s.statusPath), "error writing log shipper status %s", s.statusPath)
+}
+
+// CopyToShipper reads the log lines written by conmon in the k8s-file format
+// from r, and ships them until r is exhausted. Partial lines are joined with
+// the lines completing them before being shipped.
+func CopyToShipper(r io.Reader, s *LogShipper) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLogLineLen)
+	var partial string
+	for scanner.Scan() {
+		line, err := NewLogLine(scanner.Text())
+		if err != nil {
+			return err
+		}
+		if line.Partial() {
+			partial += line.Msg
+			continue
+		}
+		line.Msg = partial + line.Msg
+		partial = ""
+		if err := s.Ship(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// parseLogAddress splits an address of the form [network://]address, using
+// defaultNetwork if the network is not given
+func parseLogAddress(address, defaultNetwork string, networks ...string) (string, string, error) {
+	network := defaultNetwork
+	if split := strings.SplitN(address, "://", 2); len(split) == 2 {
+		network, address = split[0], split[1]
+	}
+	for _, n := range networks {
+		if n == network {
+			if address == "" {
+				return "", "", errors.Errorf("no address given for log endpoint")
+			}
+			return network, address, nil
+		}
+	}
+	return "", "", errors.Errorf("unsupported network %q for log endpoint, expected one of %s", network, strings.Join(networks, ", "))
+}
+
+// connSender sends log lines over a connection, formatted by format
+type connSender struct {
+	conn   net.Conn
+	format func(line *LogLine) ([]byte, error)
+}
+
+// Send writes a formatted line to the connection
+func (c *connSender) Send(line *LogLine) error {
+	b, err := c.format(line)
+	if err != nil {
+		return err
+	}
+	if err := c.conn.SetWriteDeadline(time.Now().Add(shipperTimeout)); err != nil {
+		return err
+	}
+	_, err = c.conn.Write(b)
+	return err
+}
+
+// Close closes the connection
+func (c *connSender) Close() error {
+	return c.conn.Close()
+}
+
+// DialSyslog connects to a syslog endpoint. The address is either
+// host:port, tcp://host:port, udp://host:port, unix:///path or
+// unixgram:///path, and defaults to the local syslog socket if empty. Without
+// a network, TCP is used: sending over UDP or unixgram does not fail while
+// the endpoint is unreachable, so lines are lost rather than spilled. Lines are tagged with the given
+// tag, stdout lines being logged with the info severity and stderr lines with
+// the err one, in the daemon facility. Messages are formatted as per RFC 5424,
+// except for the local socket which expects RFC 3164.
+func DialSyslog(address, tag string) (LogSender, error) {
+	if address == "" {
+		address = "unixgram:///dev/log"
+	}
+	network, addr, err := parseLogAddress(address, "tcp", "tcp", "udp", "unix", "unixgram")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout(network, addr, shipperTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to syslog at %s", address)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	local := network == "unix" || network == "unixgram"
+	stream := network == "tcp" || network == "unix"
+	return &connSender{
+		conn: conn,
+		format: func(line *LogLine) ([]byte, error) {
+			// Facility daemon (3), severity info (6) or err (3)
+			priority := 3*8 + 6
+			if line.Device == "stderr" {
+				priority = 3*8 + 3
+			}
+			var msg string
+			if local {
+				msg = fmt.Sprintf("<%d>%s %s: %s", priority, line.Time.Format(time.Stamp), tag, line.Msg)
+			} else {
+				msg = fmt.Sprintf("<%d>1 %s %s %s - - - %s", priority, line.Time.Format(time.RFC3339Nano), hostname, tag, line.Msg)
+			}
+			// Stream transports separate messages with newlines
+			if stream {
+				msg += "\n"
+			}
+			return []byte(msg), nil
+		},
+	}, nil
+}
+
+// DialFluentd connects to the forward input of fluentd. The address is either
+// host:port, tcp://host:port or unix:///path, and defaults to localhost:24224
+// if empty. Events are sent in the JSON form of the forward protocol, tagged
+// with the given tag, with records holding the message in their log field, the
+// stream in their source field, and the given extra fields.
+func DialFluentd(address, tag string, fields map[string]string) (LogSender, error) {
+	if address == "" {
+		address = "localhost:24224"
+	}
+	network, addr, err := parseLogAddress(address, "tcp", "tcp", "unix")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout(network, addr, shipperTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to fluentd at %s", address)
+	}
+	return &connSender{
+		conn: conn,
+		format: func(line *LogLine) ([]byte, error) {
+			record := map[string]string{
+				"log":    line.Msg,
+				"source": line.Device,
+			}
+			for k, v := range fields {
+				record[k] = v
+			}
+			b, err := json.Marshal([]interface{}{tag, line.Time.Unix(), record})
+			return b, errors.Wrapf(err, "error encoding fluentd event")
+		},
+	}, nil
+}
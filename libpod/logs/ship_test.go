@@ -0,0 +1,147 @@
+package logs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogShipperSpillsWhileUnreachable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ship")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "fluentd.sock")
+	spillPath := filepath.Join(dir, "spill.json")
+	statusPath := filepath.Join(dir, "status")
+
+	dial := func() (LogSender, error) {
+		return DialFluentd("unix://"+socket, "ctr", map[string]string{"container_name": "ctr"})
+	}
+	shipper, err := NewLogShipper(dial, spillPath, statusPath)
+	require.NoError(t, err)
+	// Wait for the first connection to fail
+	shipper.collectDial(true)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, shipper.Ship(&LogLine{Time: start, Device: "stdout", ParseLogType: FullLogType, Msg: "spilled"}))
+	}
+	assert.Equal(t, 3, shipper.status.Buffered)
+	status, err := ReadShipperStatus(statusPath)
+	require.NoError(t, err)
+	assert.Equal(t, ShipperBuffering, status.State)
+	assert.NotEmpty(t, status.Error)
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+	received := make(chan []interface{})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		decoder := json.NewDecoder(conn)
+		for {
+			var event []interface{}
+			if err := decoder.Decode(&event); err != nil {
+				close(received)
+				return
+			}
+			received <- event
+		}
+	}()
+
+	// Closing retries the endpoint and sends the spilled lines
+	require.NoError(t, shipper.Close())
+	for i := 0; i < 3; i++ {
+		event := <-received
+		require.Equal(t, 3, len(event))
+		assert.Equal(t, "ctr", event[0])
+		record := event[2].(map[string]interface{})
+		assert.Equal(t, "spilled", record["log"])
+		assert.Equal(t, "stdout", record["source"])
+		assert.Equal(t, "ctr", record["container_name"])
+	}
+	_, err = os.Stat(spillPath)
+	assert.True(t, os.IsNotExist(err))
+	status, err = ReadShipperStatus(statusPath)
+	require.NoError(t, err)
+	assert.Equal(t, ShipperConnected, status.State)
+	assert.Equal(t, 0, status.Buffered)
+}
+
+// recordingSender is a log sender remembering the messages sent to it
+type recordingSender struct {
+	sent []string
+}
+
+func (r *recordingSender) Send(line *LogLine) error {
+	r.sent = append(r.sent, line.Msg)
+	return nil
+}
+
+func (r *recordingSender) Close() error {
+	return nil
+}
+
+func TestLogShipperDialsInBackground(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ship")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	statusPath := filepath.Join(dir, "status")
+
+	sender := &recordingSender{}
+	release := make(chan struct{})
+	dial := func() (LogSender, error) {
+		<-release
+		return sender, nil
+	}
+	shipper, err := NewLogShipper(dial, filepath.Join(dir, "spill.json"), statusPath)
+	require.NoError(t, err)
+
+	// Lines are spilled while the endpoint is being connected to, and the
+	// status file is not rewritten for every one of them
+	for _, msg := range []string{"a", "b", "c"} {
+		require.NoError(t, shipper.Ship(&LogLine{Time: time.Now(), Device: "stdout", ParseLogType: FullLogType, Msg: msg}))
+	}
+	assert.Equal(t, 3, shipper.status.Buffered)
+	status, err := ReadShipperStatus(statusPath)
+	require.NoError(t, err)
+	assert.True(t, status.Buffered < 3)
+
+	// Once connected, the spilled lines are sent before newer lines
+	close(release)
+	shipper.collectDial(true)
+	require.NoError(t, shipper.Ship(&LogLine{Time: time.Now(), Device: "stdout", ParseLogType: FullLogType, Msg: "d"}))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, sender.sent)
+
+	require.NoError(t, shipper.Close())
+	status, err = ReadShipperStatus(statusPath)
+	require.NoError(t, err)
+	assert.Equal(t, ShipperConnected, status.State)
+	assert.Equal(t, 0, status.Buffered)
+}
+
+func TestParseLogAddress(t *testing.T) {
+	network, addr, err := parseLogAddress("tcp://example.com:514", "udp", "udp", "tcp")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "example.com:514", addr)
+
+	network, addr, err = parseLogAddress("example.com:514", "tcp", "tcp", "udp")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "example.com:514", addr)
+
+	_, _, err = parseLogAddress("http://example.com", "udp", "udp", "tcp")
+	assert.Error(t, err)
+}
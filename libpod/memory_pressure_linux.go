@@ -0,0 +1,263 @@
+// +build linux
+
+package libpod
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// memoryPressureFile is the kernel's pressure stall information for memory.
+const memoryPressureFile = "/proc/pressure/memory"
+
+// readMemoryPressure returns the percentage of time in the last 10 seconds in
+// which at least one task on the host was stalled waiting for memory.
+func readMemoryPressure() (float64, error) {
+	f, err := os.Open(memoryPressureFile)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error reading memory pressure (requires a kernel with PSI enabled)")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "avg10=") {
+				continue
+			}
+			pressure, err := strconv.ParseFloat(strings.TrimPrefix(field, "avg10="), 64)
+			if err != nil {
+				return 0, errors.Wrapf(err, "error parsing %s", memoryPressureFile)
+			}
+			return pressure, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, errors.Wrapf(err, "error reading %s", memoryPressureFile)
+	}
+	return 0, errors.Wrapf(define.ErrInternal, "no memory pressure found in %s", memoryPressureFile)
+}
+
+// MemoryPressureGuard freezes low-priority containers while the host is under
+// memory pressure, so that the kernel does not need to OOM-kill arbitrary
+// processes.
+// Host memory pressure is checked every interval. While it exceeds the
+// configured threshold, one running container with the memory pressure
+// priority label is frozen with SIGSTOP per check, lowest priority first.
+// Containers without the label are never frozen. Once the pressure falls below
+// half the threshold, frozen containers are resumed with SIGCONT one per check,
+// highest priority first.
+// The guard runs until the given context is cancelled, at which point all
+// containers it froze are resumed.
+func (r *Runtime) MemoryPressureGuard(ctx context.Context, interval time.Duration) error {
+	r.lock.RLock()
+	if !r.valid {
+		r.lock.RUnlock()
+		return define.ErrRuntimeStopped
+	}
+	threshold := r.config.MemoryPressureThreshold
+	r.lock.RUnlock()
+
+	if threshold <= 0 || threshold > 100 {
+		return errors.Wrapf(define.ErrInvalidArg, "memory pressure threshold must be between 0 and 100, not %v", threshold)
+	}
+	if interval <= 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "memory pressure check interval must be greater than 0")
+	}
+
+	// Make sure pressure information is available before starting.
+	if _, err := readMemoryPressure(); err != nil {
+		return err
+	}
+
+	defer r.unfreezeAllForMemoryPressure()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		pressure, err := readMemoryPressure()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case pressure > threshold:
+			logrus.Debugf("Host memory pressure %.2f exceeds threshold %.2f", pressure, threshold)
+			if err := r.freezeNextForMemoryPressure(); err != nil {
+				logrus.Errorf("Error freezing container for memory pressure: %v", err)
+			}
+		case pressure < threshold/2:
+			if err := r.unfreezeNextForMemoryPressure(); err != nil {
+				logrus.Errorf("Error unfreezing container after memory pressure: %v", err)
+			}
+		}
+	}
+}
+
+// memoryPressureCandidates returns the containers with a memory pressure
+// priority, sorted from lowest to highest priority.
+func (r *Runtime) memoryPressureCandidates() ([]*Container, error) {
+	label := r.config.MemoryPressurePriorityLabel
+	priorities := make(map[string]int)
+	ctrs, err := r.GetContainers(func(c *Container) bool {
+		value, ok := c.config.Labels[label]
+		if !ok {
+			return false
+		}
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			logrus.Warnf("Ignoring invalid memory pressure priority %q of container %s", value, c.ID())
+			return false
+		}
+		priorities[c.ID()] = priority
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(ctrs, func(i, j int) bool {
+		return priorities[ctrs[i].ID()] < priorities[ctrs[j].ID()]
+	})
+	return ctrs, nil
+}
+
+// freezeNextForMemoryPressure freezes the running container with the lowest
+// memory pressure priority that is not yet frozen.
+func (r *Runtime) freezeNextForMemoryPressure() error {
+	ctrs, err := r.memoryPressureCandidates()
+	if err != nil {
+		return err
+	}
+	for _, ctr := range ctrs {
+		frozen, err := ctr.freezeForMemoryPressure()
+		if err != nil {
+			logrus.Errorf("Error freezing container %s: %v", ctr.ID(), err)
+			continue
+		}
+		if frozen {
+			logrus.Infof("Froze container %s because of host memory pressure", ctr.ID())
+			return nil
+		}
+	}
+	logrus.Debugf("No containers left to freeze for host memory pressure")
+	return nil
+}
+
+// unfreezeNextForMemoryPressure resumes the frozen container with the highest
+// memory pressure priority.
+func (r *Runtime) unfreezeNextForMemoryPressure() error {
+	ctrs, err := r.memoryPressureCandidates()
+	if err != nil {
+		return err
+	}
+	for i := len(ctrs) - 1; i >= 0; i-- {
+		unfrozen, err := ctrs[i].unfreezeForMemoryPressure()
+		if err != nil {
+			logrus.Errorf("Error unfreezing container %s: %v", ctrs[i].ID(), err)
+			continue
+		}
+		if unfrozen {
+			logrus.Infof("Unfroze container %s as host memory pressure cleared", ctrs[i].ID())
+			return nil
+		}
+	}
+	return nil
+}
+
+// unfreezeAllForMemoryPressure resumes all containers frozen because of memory
+// pressure.
+func (r *Runtime) unfreezeAllForMemoryPressure() {
+	ctrs, err := r.GetContainers(func(c *Container) bool {
+		return true
+	})
+	if err != nil {
+		logrus.Errorf("Error retrieving containers to unfreeze: %v", err)
+		return
+	}
+	for _, ctr := range ctrs {
+		if _, err := ctr.unfreezeForMemoryPressure(); err != nil {
+			logrus.Errorf("Error unfreezing container %s: %v", ctr.ID(), err)
+		}
+	}
+}
+
+// freezeForMemoryPressure stops all processes in the container with SIGSTOP.
+// Returns whether the container was frozen; containers that are not running or
+// are already frozen are left alone.
+func (c *Container) freezeForMemoryPressure() (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.syncContainer(); err != nil {
+		return false, err
+	}
+
+	if c.state.State != define.ContainerStateRunning || c.state.MemoryPressureFrozen {
+		return false, nil
+	}
+
+	if err := c.ociRuntime.KillContainer(c, uint(syscall.SIGSTOP), true); err != nil {
+		return false, err
+	}
+	c.state.MemoryPressureFrozen = true
+	if err := c.save(); err != nil {
+		return false, err
+	}
+	c.newContainerEvent(events.Freeze)
+
+	return true, nil
+}
+
+// unfreezeForMemoryPressure resumes the processes of a container frozen by
+// freezeForMemoryPressure with SIGCONT.
+// Returns whether a frozen container was resumed.
+func (c *Container) unfreezeForMemoryPressure() (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.syncContainer(); err != nil {
+		return false, err
+	}
+
+	if !c.state.MemoryPressureFrozen {
+		return false, nil
+	}
+
+	// If the container exited while frozen, there is nothing to resume.
+	resume := c.state.State == define.ContainerStateRunning || c.state.State == define.ContainerStatePaused
+	if resume {
+		if err := c.ociRuntime.KillContainer(c, uint(syscall.SIGCONT), true); err != nil {
+			return false, err
+		}
+	}
+	c.state.MemoryPressureFrozen = false
+	if err := c.save(); err != nil {
+		return false, err
+	}
+	if resume {
+		c.newContainerEvent(events.Unfreeze)
+	}
+
+	return resume, nil
+}
@@ -0,0 +1,15 @@
+// +build !linux
+
+package libpod
+
+import (
+	"context"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+)
+
+// MemoryPressureGuard is not supported on this platform.
+func (r *Runtime) MemoryPressureGuard(ctx context.Context, interval time.Duration) error {
+	return define.ErrOSNotSupported
+}
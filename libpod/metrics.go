@@ -0,0 +1,283 @@
+package libpod
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/containers/libpod/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+// metricsNamespace prefixes the names of all metrics exported by libpod
+const metricsNamespace = "podman"
+
+// lockContentionThreshold is how long acquiring a lock must take for it to be
+// counted as contended
+const lockContentionThreshold = time.Millisecond
+
+// Metrics of the operations performed by this process. They are updated
+// whether or not they are served.
+var (
+	stateOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "state",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of the operations on the state database, including waiting for the database",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"operation"})
+	lockWaitDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "lock",
+		Name:      "wait_seconds",
+		Help:      "Time spent waiting to acquire container, pod and volume locks",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	})
+	lockContentions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "lock",
+		Name:      "contentions_total",
+		Help:      "Number of container, pod and volume lock acquisitions which had to wait for another holder",
+	})
+)
+
+// observeStateOperation records the duration of an operation on the state
+// database. The operation is named after the first function of the call stack
+// which is not in skip.
+func observeStateOperation(start time.Time, skip ...string) {
+	duration := time.Since(start)
+	pcs := make([]uintptr, 8)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	operation := "unknown"
+	for {
+		frame, more := frames.Next()
+		name := frame.Function[strings.LastIndex(frame.Function, ".")+1:]
+		if !util.StringInSlice(name, skip) {
+			operation = name
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	stateOperationDuration.WithLabelValues(operation).Observe(duration.Seconds())
+}
+
+// instrumentedLockManager is a lock manager whose locks record how long they
+// are waited for
+type instrumentedLockManager struct {
+	lock.Manager
+}
+
+// AllocateLock allocates an instrumented lock
+func (m instrumentedLockManager) AllocateLock() (lock.Locker, error) {
+	l, err := m.Manager.AllocateLock()
+	if err != nil {
+		return nil, err
+	}
+	return instrumentedLocker{l}, nil
+}
+
+// RetrieveLock retrieves an instrumented lock
+func (m instrumentedLockManager) RetrieveLock(id uint32) (lock.Locker, error) {
+	l, err := m.Manager.RetrieveLock(id)
+	if err != nil {
+		return nil, err
+	}
+	return instrumentedLocker{l}, nil
+}
+
+// AllocateAndRetrieveLock allocates and retrieves an instrumented lock
+func (m instrumentedLockManager) AllocateAndRetrieveLock(id uint32) (lock.Locker, error) {
+	l, err := m.Manager.AllocateAndRetrieveLock(id)
+	if err != nil {
+		return nil, err
+	}
+	return instrumentedLocker{l}, nil
+}
+
+// instrumentedLocker is a lock recording how long it is waited for
+type instrumentedLocker struct {
+	lock.Locker
+}
+
+// Lock acquires the lock, recording how long it took
+func (l instrumentedLocker) Lock() {
+	start := time.Now()
+	l.Locker.Lock()
+	wait := time.Since(start)
+	lockWaitDuration.Observe(wait.Seconds())
+	if wait >= lockContentionThreshold {
+		lockContentions.Inc()
+	}
+}
+
+// runtimeCollector collects the metrics of the containers, pods and volumes of
+// a runtime at each scrape
+type runtimeCollector struct {
+	runtime *Runtime
+
+	containers      *prometheus.Desc
+	pods            *prometheus.Desc
+	volumes         *prometheus.Desc
+	cpuSeconds      *prometheus.Desc
+	memoryUsage     *prometheus.Desc
+	memoryLimit     *prometheus.Desc
+	networkReceive  *prometheus.Desc
+	networkTransmit *prometheus.Desc
+	blockRead       *prometheus.Desc
+	blockWrite      *prometheus.Desc
+	pids            *prometheus.Desc
+}
+
+func newRuntimeCollector(r *Runtime) *runtimeCollector {
+	ctrDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "container", name), help, []string{"id", "name"}, nil)
+	}
+	return &runtimeCollector{
+		runtime:         r,
+		containers:      prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "containers"), "Number of containers by state", []string{"state"}, nil),
+		pods:            prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "pods"), "Number of pods", nil, nil),
+		volumes:         prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "volumes"), "Number of volumes", nil, nil),
+		cpuSeconds:      ctrDesc("cpu_seconds_total", "CPU time consumed by the container"),
+		memoryUsage:     ctrDesc("memory_usage_bytes", "Memory used by the container"),
+		memoryLimit:     ctrDesc("memory_limit_bytes", "Memory limit of the container"),
+		networkReceive:  ctrDesc("network_receive_bytes_total", "Bytes received by the network namespace of the container"),
+		networkTransmit: ctrDesc("network_transmit_bytes_total", "Bytes sent by the network namespace of the container"),
+		blockRead:       ctrDesc("block_read_bytes_total", "Bytes read by the container from block devices"),
+		blockWrite:      ctrDesc("block_write_bytes_total", "Bytes written by the container to block devices"),
+		pids:            ctrDesc("pids", "Number of processes of the container"),
+	}
+}
+
+// Describe sends the descriptions of the metrics of the collector
+func (c *runtimeCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{c.containers, c.pods, c.volumes, c.cpuSeconds, c.memoryUsage, c.memoryLimit, c.networkReceive, c.networkTransmit, c.blockRead, c.blockWrite, c.pids} {
+		ch <- desc
+	}
+}
+
+// Collect gathers the object counts of the runtime, and the resource usage of
+// its running containers
+func (c *runtimeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctrs, err := c.runtime.state.AllContainers()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.containers, err)
+	} else {
+		states := make(map[string]int)
+		for _, ctr := range ctrs {
+			state, err := ctr.State()
+			if err != nil {
+				if !isRemovedError(err) {
+					logrus.Debugf("Error getting state of container %s for metrics: %v", ctr.ID(), err)
+				}
+				continue
+			}
+			states[state.String()]++
+		}
+		for state, count := range states {
+			ch <- prometheus.MustNewConstMetric(c.containers, prometheus.GaugeValue, float64(count), state)
+		}
+	}
+
+	// Containers which stopped since their state was read have no stats
+	if allStats, _, err := c.runtime.GetAllContainerStats(nil, 0); err != nil {
+		logrus.Debugf("Error getting stats of containers for metrics: %v", err)
+	} else {
+		for _, stats := range allStats {
+			c.collectContainer(stats, ch)
+		}
+	}
+
+	if pods, err := c.runtime.state.AllPods(); err != nil {
+		ch <- prometheus.NewInvalidMetric(c.pods, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.pods, prometheus.GaugeValue, float64(len(pods)))
+	}
+
+	if volumes, err := c.runtime.state.AllVolumes(); err != nil {
+		ch <- prometheus.NewInvalidMetric(c.volumes, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.volumes, prometheus.GaugeValue, float64(len(volumes)))
+	}
+}
+
+// collectContainer sends the resource usage of a running container
+func (c *runtimeCollector) collectContainer(stats *ContainerStats, ch chan<- prometheus.Metric) {
+	labels := []string{stats.ContainerID, stats.Name}
+	metric := func(desc *prometheus.Desc, valueType prometheus.ValueType, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, valueType, value, labels...)
+	}
+	metric(c.cpuSeconds, prometheus.CounterValue, float64(stats.CPUNano)/float64(time.Second))
+	metric(c.memoryUsage, prometheus.GaugeValue, float64(stats.MemUsage))
+	metric(c.memoryLimit, prometheus.GaugeValue, float64(stats.MemLimit))
+	metric(c.networkReceive, prometheus.CounterValue, float64(stats.NetInput))
+	metric(c.networkTransmit, prometheus.CounterValue, float64(stats.NetOutput))
+	metric(c.blockRead, prometheus.CounterValue, float64(stats.BlockInput))
+	metric(c.blockWrite, prometheus.CounterValue, float64(stats.BlockOutput))
+	metric(c.pids, prometheus.GaugeValue, float64(stats.PIDs))
+}
+
+// MetricsHandler returns an HTTP handler serving the metrics of the runtime in
+// the Prometheus exposition format. Object counts and container resource usage
+// are gathered when the metrics are scraped, while the durations of state
+// operations and lock acquisitions only cover those of this process.
+func (r *Runtime) MetricsHandler() (http.Handler, error) {
+	registry := prometheus.NewRegistry()
+	for _, collector := range []prometheus.Collector{newRuntimeCollector(r), stateOperationDuration, lockWaitDuration, lockContentions} {
+		if err := registry.Register(collector); err != nil {
+			return nil, errors.Wrapf(err, "error registering metrics")
+		}
+	}
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
+
+// ServeMetrics serves the metrics of the runtime under /metrics until the
+// context is cancelled. The address is either unix:///path, tcp://host:port or
+// host:port.
+func (r *Runtime) ServeMetrics(ctx context.Context, address string) error {
+	network := "tcp"
+	if split := strings.SplitN(address, "://", 2); len(split) == 2 {
+		network, address = split[0], split[1]
+	}
+	if network != "tcp" && network != "unix" {
+		return errors.Wrapf(define.ErrInvalidArg, "unsupported network %q for metrics endpoint, expected tcp or unix", network)
+	}
+	if network == "unix" {
+		if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "error removing metrics socket %s", address)
+		}
+	}
+
+	handler, err := r.MetricsHandler()
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return errors.Wrapf(err, "error listening on %s", address)
+	}
+	server := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		if err := server.Close(); err != nil {
+			logrus.Errorf("Error closing metrics endpoint: %v", err)
+		}
+	}()
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return errors.Wrapf(err, "error serving metrics on %s", address)
+	}
+	return nil
+}
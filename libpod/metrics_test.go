@@ -0,0 +1,91 @@
+package libpod
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/lock"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stateOperationCount(t *testing.T, operation string) uint64 {
+	var metric dto.Metric
+	require.NoError(t, stateOperationDuration.WithLabelValues(operation).(interface {
+		Write(*dto.Metric) error
+	}).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestStateOperationMetrics(t *testing.T) {
+	state, path, _, err := getEmptyBoltState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	defer state.Close()
+
+	before := stateOperationCount(t, "AllContainers")
+	_, err = state.AllContainers()
+	require.NoError(t, err)
+	assert.Equal(t, before+1, stateOperationCount(t, "AllContainers"))
+}
+
+func TestInstrumentedLocks(t *testing.T) {
+	manager, err := lock.NewInMemoryManager(4)
+	require.NoError(t, err)
+	instrumented := instrumentedLockManager{manager}
+
+	var before dto.Metric
+	require.NoError(t, lockWaitDuration.Write(&before))
+	l, err := instrumented.AllocateLock()
+	require.NoError(t, err)
+	l.Lock()
+	l.Unlock()
+	var after dto.Metric
+	require.NoError(t, lockWaitDuration.Write(&after))
+	assert.Equal(t, before.GetHistogram().GetSampleCount()+1, after.GetHistogram().GetSampleCount())
+}
+
+func TestResizedLockPoolStaysInstrumented(t *testing.T) {
+	path := "/libpod_test_metrics"
+	defer os.Remove("/dev/shm" + path)
+	manager, err := lock.NewSHMLockManager(path, 32)
+	require.NoError(t, err)
+	defer manager.(*lock.SHMLockManager).Close()
+	runtime := &Runtime{
+		config:      &RuntimeConfig{NumLocks: 32},
+		lockManager: instrumentedLockManager{manager},
+	}
+
+	require.NoError(t, runtime.resizeSHMLockPool(path, 64))
+	assert.Equal(t, uint32(64), runtime.config.NumLocks)
+	require.IsType(t, instrumentedLockManager{}, runtime.lockManager)
+	l, err := runtime.lockManager.AllocateLock()
+	require.NoError(t, err)
+	assert.IsType(t, instrumentedLocker{}, l)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	ctr, err := getTestCtr1(manager)
+	require.NoError(t, err)
+	require.NoError(t, state.AddContainer(ctr))
+	ctr.runtime.state = state
+	ctr.state.State = define.ContainerStateConfigured
+
+	handler, err := ctr.runtime.MetricsHandler()
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `podman_containers{state="configured"} 1`)
+	assert.Contains(t, string(body), "podman_pods 0")
+	assert.Contains(t, string(body), "podman_volumes 0")
+}
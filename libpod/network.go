@@ -0,0 +1,61 @@
+package libpod
+
+import (
+	"time"
+)
+
+// Network is a CNI network tracked by libpod.
+// The CNI configuration itself is stored on disk as a conflist; the Network
+// records its location and the containers that use it, so that networks in
+// use cannot be removed.
+type Network struct {
+	config *NetworkConfig
+
+	valid   bool
+	runtime *Runtime
+}
+
+// NetworkConfig holds the network's config information
+type NetworkConfig struct {
+	// Name of the network. Must match the name in the CNI configuration.
+	Name string `json:"name"`
+	// Driver is the CNI plugin type of the first plugin in the network's
+	// configuration list (e.g. bridge, macvlan).
+	Driver string `json:"driver,omitempty"`
+	// ConfigPath is the path to the CNI configuration list for the
+	// network.
+	ConfigPath string `json:"configPath"`
+	// Labels for the network.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Time the network was created.
+	CreatedTime time.Time `json:"createdAt,omitempty"`
+}
+
+// Name retrieves the network's name
+func (n *Network) Name() string {
+	return n.config.Name
+}
+
+// Driver retrieves the network's driver.
+func (n *Network) Driver() string {
+	return n.config.Driver
+}
+
+// ConfigPath returns the path to the network's CNI configuration list
+func (n *Network) ConfigPath() string {
+	return n.config.ConfigPath
+}
+
+// Labels returns the network's labels
+func (n *Network) Labels() map[string]string {
+	labels := make(map[string]string)
+	for key, value := range n.config.Labels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// CreatedTime returns the time the network was created at.
+func (n *Network) CreatedTime() time.Time {
+	return n.config.CreatedTime
+}
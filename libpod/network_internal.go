@@ -0,0 +1,11 @@
+package libpod
+
+// Creates a new network
+func newNetwork(runtime *Runtime) (*Network, error) {
+	network := new(Network)
+	network.config = new(NetworkConfig)
+	network.runtime = runtime
+	network.config.Labels = make(map[string]string)
+
+	return network, nil
+}
@@ -0,0 +1,48 @@
+package libpod
+
+import (
+	"net"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types/current"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+)
+
+// Host addresses ports without a host IP are forwarded from
+const (
+	allIPv4Addresses = "0.0.0.0"
+	allIPv6Addresses = "::"
+)
+
+// isIPv6 returns whether the given address is an IPv6 address
+func isIPv6(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.To4() == nil
+}
+
+// portHostAddresses returns the host addresses a port mapping forwards from.
+// Port mappings without a host IP forward from all IPv4 and, if ipv6 is set,
+// all IPv6 addresses of the host.
+func portHostAddresses(port ocicni.PortMapping, ipv6 bool) []string {
+	if port.HostIP != "" {
+		return []string{port.HostIP}
+	}
+	if ipv6 {
+		return []string{allIPv4Addresses, allIPv6Addresses}
+	}
+	return []string{allIPv4Addresses}
+}
+
+// networkAddresses splits the addresses of the given CNI results by address
+// family, in the order the networks were configured
+func networkAddresses(results []*cnitypes.Result) (ipv4, ipv6 []*cnitypes.IPConfig) {
+	for _, result := range results {
+		for _, ip := range result.IPs {
+			if ip.Version == "4" || (ip.Version == "" && ip.Address.IP.To4() != nil) {
+				ipv4 = append(ipv4, ip)
+			} else {
+				ipv6 = append(ipv6, ip)
+			}
+		}
+	}
+	return ipv4, ipv6
+}
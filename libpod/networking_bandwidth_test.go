@@ -0,0 +1,23 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBandwidth(t *testing.T) {
+	ctr := &Container{config: &ContainerConfig{CreateNetNS: true}}
+	assert.NoError(t, WithBandwidth(ocicni.BandwidthConfig{EgressRate: 1000000})(ctr))
+	assert.Equal(t, &ocicni.BandwidthConfig{EgressRate: 1000000, EgressBurst: 1000000}, ctr.config.Bandwidth)
+
+	// Limits require a network namespace managed by CNI
+	err := WithBandwidth(ocicni.BandwidthConfig{EgressRate: 1000000})(&Container{config: &ContainerConfig{}})
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+
+	err = WithBandwidth(ocicni.BandwidthConfig{IngressBurst: 1000})(&Container{config: &ContainerConfig{CreateNetNS: true}})
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
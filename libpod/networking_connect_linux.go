@@ -0,0 +1,442 @@
+// +build linux
+
+package libpod
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/cni/libcni"
+	cnitypes "github.com/containernetworking/cni/pkg/types/current"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/libpod/pkg/network"
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// loopbackNetworkConfig is the CNI configuration used to bring up the loopback
+// interface of network namespaces whose networks are attached one by one
+const loopbackNetworkConfig = `{
+	"cniVersion": "0.3.1",
+	"name": "cni-loopback",
+	"plugins": [{"type": "loopback"}]
+}`
+
+// cniNetworkConfig retrieves the CNI configuration list of a network.
+// Networks tracked in the state use their recorded configuration; others are
+// looked up by name in the CNI configuration directory.
+func (r *Runtime) cniNetworkConfig(netName string) (*libcni.NetworkConfigList, error) {
+	if n, err := r.state.Network(netName); err == nil {
+		conf, err := libcni.ConfListFromFile(n.ConfigPath())
+		if err != nil {
+			return nil, errors.Wrapf(err, "error loading CNI configuration of network %s", netName)
+		}
+		return conf, nil
+	}
+
+	confs, err := network.LoadCNIConfsFromDir(r.config.CNIConfigDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading CNI configurations from %s", r.config.CNIConfigDir)
+	}
+	for _, conf := range confs {
+		if conf.Name == netName {
+			return conf, nil
+		}
+	}
+	return nil, errors.Wrapf(define.ErrNoSuchNetwork, "no CNI configuration found for network %s", netName)
+}
+
+// cniRuntimeConf builds the CNI runtime configuration used to attach the
+// container to a single network, matching the one used by OCICNI.
+// Port mappings are only forwarded, and the static MAC only requested, on the
+// default network.
+func (r *Runtime) cniRuntimeConf(ctr *Container, config *ContainerConfig, nsPath, netName, ifName string, staticIP net.IP) *libcni.RuntimeConf {
+	rt := &libcni.RuntimeConf{
+		ContainerID: ctr.ID(),
+		NetNS:       nsPath,
+		IfName:      ifName,
+		Args: [][2]string{
+			{"IgnoreUnknown", "1"},
+			{"K8S_POD_NAMESPACE", ctr.Name()},
+			{"K8S_POD_NAME", ctr.Name()},
+			{"K8S_POD_INFRA_CONTAINER_ID", ctr.ID()},
+		},
+		CapabilityArgs: map[string]interface{}{},
+	}
+
+	if staticIP != nil {
+		rt.Args = append(rt.Args, [2]string{"IP", staticIP.String()})
+	}
+	if netName == r.netPlugin.GetDefaultNetworkName() {
+		if len(config.PortMappings) > 0 {
+			rt.CapabilityArgs["portMappings"] = config.PortMappings
+		}
+		if config.StaticMAC != nil {
+			rt.Args = append(rt.Args, [2]string{"MAC", config.StaticMAC.String()})
+			rt.CapabilityArgs["mac"] = config.StaticMAC.String()
+		}
+	}
+	if config.Bandwidth != nil {
+		rt.CapabilityArgs["bandwidth"] = map[string]uint64{
+			"ingressRate":  config.Bandwidth.IngressRate,
+			"ingressBurst": config.Bandwidth.IngressBurst,
+			"egressRate":   config.Bandwidth.EgressRate,
+			"egressBurst":  config.Bandwidth.EgressBurst,
+		}
+	}
+	if aliases := config.NetworkAliases[netName]; len(aliases) > 0 {
+		rt.CapabilityArgs["aliases"] = map[string][]string{netName: aliases}
+	}
+
+	return rt
+}
+
+// attachNetwork attaches the container's network namespace to a single CNI
+// network, creating the given interface
+func (r *Runtime) attachNetwork(ctr *Container, config *ContainerConfig, nsPath, netName, ifName string, staticIP net.IP) (*cnitypes.Result, error) {
+	conf, err := r.cniNetworkConfig(netName)
+	if err != nil {
+		return nil, err
+	}
+
+	cniConfig := libcni.NewCNIConfig(r.config.CNIPluginDir, nil)
+	rt := r.cniRuntimeConf(ctr, config, nsPath, netName, ifName, staticIP)
+
+	logrus.Debugf("Attaching container %s to CNI network %s as %s", ctr.ID(), netName, ifName)
+	res, err := cniConfig.AddNetworkList(context.Background(), conf, rt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error attaching container %s to network %s", ctr.ID(), netName)
+	}
+	result, err := cnitypes.GetResult(res)
+	if err != nil {
+		if err2 := cniConfig.DelNetworkList(context.Background(), conf, rt); err2 != nil {
+			logrus.Errorf("Error detaching container %s from network %s: %v", ctr.ID(), netName, err2)
+		}
+		return nil, errors.Wrapf(err, "error parsing CNI plugin result %q", res.String())
+	}
+	return result, nil
+}
+
+// detachNetwork detaches the container's network namespace from a single CNI
+// network, removing the given interface
+func (r *Runtime) detachNetwork(ctr *Container, config *ContainerConfig, nsPath, netName, ifName string, staticIP net.IP) error {
+	conf, err := r.cniNetworkConfig(netName)
+	if err != nil {
+		return err
+	}
+
+	cniConfig := libcni.NewCNIConfig(r.config.CNIPluginDir, nil)
+	rt := r.cniRuntimeConf(ctr, config, nsPath, netName, ifName, staticIP)
+
+	logrus.Debugf("Detaching container %s from CNI network %s (%s)", ctr.ID(), netName, ifName)
+	if err := cniConfig.DelNetworkList(context.Background(), conf, rt); err != nil {
+		return errors.Wrapf(err, "error detaching container %s from network %s", ctr.ID(), netName)
+	}
+	return nil
+}
+
+// usesNetworkAttachments returns whether the container's networks must be set
+// up one by one rather than through OCICNI, as they have per-network
+// configuration that OCICNI does not support
+func (c *Container) usesNetworkAttachments() bool {
+	return len(c.config.NetworkAliases) > 0 || len(c.config.NetworkStaticIPs) > 0 || c.config.StaticMAC != nil
+}
+
+// networkStaticIP returns the static IP requested for the container on the
+// given network, if any
+func (c *Container) networkStaticIP(config *ContainerConfig, netName string) net.IP {
+	if ip, ok := config.NetworkStaticIPs[netName]; ok {
+		return ip
+	}
+	if config.StaticIP != nil && netName == c.runtime.netPlugin.GetDefaultNetworkName() {
+		return config.StaticIP
+	}
+	return nil
+}
+
+// attachNetworks attaches the container's network namespace to each of its
+// networks in turn. The first network uses requestedIP, if set, in place of
+// its static IP.
+func (r *Runtime) attachNetworks(ctr *Container, nsPath string, requestedIP net.IP) (_ []*cnitypes.Result, _ map[string]string, err error) {
+	lo, err := libcni.ConfListFromBytes([]byte(loopbackNetworkConfig))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error parsing loopback CNI configuration")
+	}
+	cniConfig := libcni.NewCNIConfig(r.config.CNIPluginDir, nil)
+	loRt := &libcni.RuntimeConf{ContainerID: ctr.ID(), NetNS: nsPath, IfName: "lo"}
+	if _, err := cniConfig.AddNetworkList(context.Background(), lo, loRt); err != nil {
+		return nil, nil, errors.Wrapf(err, "error configuring loopback interface of container %s", ctr.ID())
+	}
+
+	results := make([]*cnitypes.Result, 0)
+	interfaces := make(map[string]string)
+	defer func() {
+		if err != nil {
+			if err2 := r.detachNetworks(ctr, nsPath, interfaces); err2 != nil {
+				logrus.Errorf("Error detaching partially attached networks of container %s: %v", ctr.ID(), err2)
+			}
+		}
+	}()
+
+	for i, netName := range r.containerNetworks(ctr) {
+		ifName := fmt.Sprintf("eth%d", i)
+		staticIP := ctr.networkStaticIP(ctr.config, netName)
+		if i == 0 && requestedIP != nil {
+			staticIP = requestedIP
+		}
+		result, err := r.attachNetwork(ctr, ctr.config, nsPath, netName, ifName, staticIP)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = append(results, result)
+		interfaces[netName] = ifName
+	}
+
+	return results, interfaces, nil
+}
+
+// detachNetworks detaches the container's network namespace from each network
+// it is attached to. All networks are detached even if some fail; the last
+// error is returned.
+func (r *Runtime) detachNetworks(ctr *Container, nsPath string, interfaces map[string]string) error {
+	var lastErr error
+	for netName, ifName := range interfaces {
+		if err := r.detachNetwork(ctr, ctr.config, nsPath, netName, ifName, ctr.networkStaticIP(ctr.config, netName)); err != nil {
+			if lastErr != nil {
+				logrus.Errorf("%v", lastErr)
+			}
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// networkInterfaces returns the interfaces of the container's networks in its
+// network namespace. Containers whose interfaces were not recorded had them
+// assigned by OCICNI, in the order of their networks.
+func (r *Runtime) networkInterfaces(ctr *Container) map[string]string {
+	interfaces := make(map[string]string)
+	if len(ctr.state.NetworkInterfaces) > 0 {
+		for netName, ifName := range ctr.state.NetworkInterfaces {
+			interfaces[netName] = ifName
+		}
+		return interfaces
+	}
+	for i, netName := range r.containerNetworks(ctr) {
+		interfaces[netName] = fmt.Sprintf("eth%d", i)
+	}
+	return interfaces
+}
+
+// checkNetworkChange verifies that the CNI networks of the container can be
+// changed
+func (c *Container) checkNetworkChange() error {
+	if !c.config.CreateNetNS {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s does not have a network namespace managed by libpod", c.ID())
+	}
+	if rootless.IsRootless() {
+		return errors.Wrapf(define.ErrNotImplemented, "cannot change the CNI networks of container %s in rootless mode", c.ID())
+	}
+	return nil
+}
+
+// networkConnect attaches the container to an additional CNI network.
+// If the container's network namespace is active, the network is attached to
+// it immediately; either way, the network is added to the container's
+// configuration, and is attached whenever the container is started.
+// Must be called with the container locked.
+func (c *Container) networkConnect(netName string, aliases []string, staticIP net.IP) (err error) {
+	if err := c.checkNetworkChange(); err != nil {
+		return err
+	}
+
+	networks := c.runtime.containerNetworks(c)
+	for _, n := range networks {
+		if n == netName {
+			return errors.Wrapf(define.ErrNetworkExists, "container %s is already connected to network %s", c.ID(), netName)
+		}
+	}
+	if _, err := c.runtime.cniNetworkConfig(netName); err != nil {
+		return err
+	}
+
+	newConfig := c.Config()
+	if newConfig == nil {
+		return errors.Wrapf(define.ErrInternal, "error copying configuration of container %s", c.ID())
+	}
+	// The container's single default network, including a static IP
+	// requested on it, becomes the first of its networks
+	if newConfig.StaticIP != nil {
+		if newConfig.NetworkStaticIPs == nil {
+			newConfig.NetworkStaticIPs = make(map[string]net.IP)
+		}
+		newConfig.NetworkStaticIPs[networks[0]] = newConfig.StaticIP
+		newConfig.StaticIP = nil
+	}
+	newConfig.Networks = make([]string, 0, len(networks)+1)
+	newConfig.Networks = append(newConfig.Networks, networks...)
+	newConfig.Networks = append(newConfig.Networks, netName)
+	if len(aliases) > 0 {
+		if newConfig.NetworkAliases == nil {
+			newConfig.NetworkAliases = make(map[string][]string)
+		}
+		newConfig.NetworkAliases[netName] = aliases
+	}
+	if staticIP != nil {
+		if newConfig.NetworkStaticIPs == nil {
+			newConfig.NetworkStaticIPs = make(map[string]net.IP)
+		}
+		newConfig.NetworkStaticIPs[netName] = staticIP
+	}
+
+	oldConfig := c.config
+	interfaces := c.runtime.networkInterfaces(c)
+
+	// Save the new networks first, which reserves the static IP, so
+	// conflicting addresses are rejected before the network is attached
+	if err := c.runtime.state.RewriteContainerNetworks(c, newConfig); err != nil {
+		return errors.Wrapf(err, "error saving networks of container %s", c.ID())
+	}
+	c.config = newConfig
+	defer func() {
+		if err != nil {
+			if err2 := c.runtime.state.RewriteContainerNetworks(c, oldConfig); err2 != nil {
+				logrus.Errorf("Error restoring networks of container %s: %v", c.ID(), err2)
+				return
+			}
+			c.config = oldConfig
+		}
+	}()
+
+	if c.state.NetNS != nil {
+		nsPath := c.state.NetNS.Path()
+		ifName := nextInterfaceName(interfaces)
+
+		result, err := c.runtime.attachNetwork(c, newConfig, nsPath, netName, ifName, staticIP)
+		if err != nil {
+			return err
+		}
+		if hooks, ok := c.runtime.config.NetworkHooks[netName]; ok && len(hooks.PostSetup) > 0 {
+			if err := runNetworkHook(c, netName, NetworkHookPostSetup, nsPath, hooks.PostSetup, result); err != nil {
+				if err2 := c.runtime.detachNetwork(c, newConfig, nsPath, netName, ifName, staticIP); err2 != nil {
+					logrus.Errorf("Error detaching container %s from network %s: %v", c.ID(), netName, err2)
+				}
+				return err
+			}
+		}
+
+		interfaces[netName] = ifName
+		c.state.NetworkInterfaces = interfaces
+		c.state.NetworkStatus = append(c.state.NetworkStatus, result)
+		if err := c.save(); err != nil {
+			return err
+		}
+	}
+
+	c.newContainerEvent(events.NetworkConnect)
+
+	return nil
+}
+
+// networkDisconnect detaches the container from one of its CNI networks.
+// If the container's network namespace is active, the network is detached
+// from it immediately. The network is removed from the container's
+// configuration.
+// Must be called with the container locked.
+func (c *Container) networkDisconnect(netName string) error {
+	if err := c.checkNetworkChange(); err != nil {
+		return err
+	}
+
+	networks := c.runtime.containerNetworks(c)
+	index := -1
+	for i, n := range networks {
+		if n == netName {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return errors.Wrapf(define.ErrNoSuchNetwork, "container %s is not connected to network %s", c.ID(), netName)
+	}
+	if len(networks) == 1 {
+		return errors.Wrapf(define.ErrInvalidArg, "cannot disconnect container %s from network %s, its only network", c.ID(), netName)
+	}
+
+	newConfig := c.Config()
+	if newConfig == nil {
+		return errors.Wrapf(define.ErrInternal, "error copying configuration of container %s", c.ID())
+	}
+	newConfig.Networks = make([]string, 0, len(networks)-1)
+	newConfig.Networks = append(newConfig.Networks, networks[:index]...)
+	newConfig.Networks = append(newConfig.Networks, networks[index+1:]...)
+	delete(newConfig.NetworkAliases, netName)
+	delete(newConfig.NetworkStaticIPs, netName)
+
+	if c.state.NetNS != nil {
+		nsPath := c.state.NetNS.Path()
+		interfaces := c.runtime.networkInterfaces(c)
+
+		var result *cnitypes.Result
+		if index < len(c.state.NetworkStatus) {
+			result = c.state.NetworkStatus[index]
+		}
+		if hooks, ok := c.runtime.config.NetworkHooks[netName]; ok && len(hooks.PreTeardown) > 0 {
+			if err := runNetworkHook(c, netName, NetworkHookPreTeardown, nsPath, hooks.PreTeardown, result); err != nil {
+				logrus.Errorf("Error running network hooks: %v", err)
+			}
+		}
+
+		if err := c.runtime.detachNetwork(c, c.config, nsPath, netName, interfaces[netName], c.networkStaticIP(c.config, netName)); err != nil {
+			return err
+		}
+
+		delete(interfaces, netName)
+		c.state.NetworkInterfaces = interfaces
+		if index < len(c.state.NetworkStatus) {
+			newStatus := make([]*cnitypes.Result, 0, len(c.state.NetworkStatus)-1)
+			newStatus = append(newStatus, c.state.NetworkStatus[:index]...)
+			c.state.NetworkStatus = append(newStatus, c.state.NetworkStatus[index+1:]...)
+		}
+		if err := c.save(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.runtime.state.RewriteContainerNetworks(c, newConfig); err != nil {
+		return errors.Wrapf(err, "error saving networks of container %s", c.ID())
+	}
+	c.config = newConfig
+
+	c.newContainerEvent(events.NetworkDisconnect)
+
+	return nil
+}
+
+// nextInterfaceName returns the first interface name, counting up from eth0,
+// that is not used by any of the given interfaces
+func nextInterfaceName(interfaces map[string]string) string {
+	used := make(map[string]bool)
+	for _, ifName := range interfaces {
+		used[ifName] = true
+	}
+	for i := 0; ; i++ {
+		ifName := fmt.Sprintf("eth%d", i)
+		if !used[ifName] {
+			return ifName
+		}
+	}
+}
+
+// defaultInterfaceName returns the interface of the container's first network
+func (c *Container) defaultInterfaceName() string {
+	networks := c.runtime.containerNetworks(c)
+	if ifName, ok := c.state.NetworkInterfaces[networks[0]]; ok {
+		return ifName
+	}
+	return ocicni.DefaultInterfaceName
+}
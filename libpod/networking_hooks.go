@@ -0,0 +1,30 @@
+package libpod
+
+import (
+	"path/filepath"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+)
+
+const (
+	// NetworkHookPostSetup is the stage of network hooks run after a
+	// container is attached to a network
+	NetworkHookPostSetup = "post-setup"
+	// NetworkHookPreTeardown is the stage of network hooks run before a
+	// container is detached from a network
+	NetworkHookPreTeardown = "pre-teardown"
+)
+
+// validate checks that the hook commands are absolute paths
+func (h NetworkHookConfig) validate() error {
+	for stage, cmd := range map[string][]string{
+		NetworkHookPostSetup:   h.PostSetup,
+		NetworkHookPreTeardown: h.PreTeardown,
+	} {
+		if len(cmd) > 0 && !filepath.IsAbs(cmd[0]) {
+			return errors.Wrapf(define.ErrInvalidArg, "%s network hook %q must be an absolute path", stage, cmd[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// +build linux
+
+package libpod
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	cnitypes "github.com/containernetworking/cni/pkg/types/current"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// networkHookTimeout is how long a network hook may run before it is killed
+const networkHookTimeout = 30 * time.Second
+
+// containerNetworks returns the names of the CNI networks the container is
+// attached to, in the order of its CNI results
+func (r *Runtime) containerNetworks(ctr *Container) []string {
+	if len(ctr.config.Networks) == 0 || ctr.config.StaticIP != nil {
+		return []string{r.netPlugin.GetDefaultNetworkName()}
+	}
+	return ctr.config.Networks
+}
+
+// runNetworkHooks runs the hooks of the given stage for each network the
+// container is attached to, passing the network's CNI result.
+func (r *Runtime) runNetworkHooks(ctr *Container, stage, nsPath string, results []*cnitypes.Result) error {
+	if len(r.config.NetworkHooks) == 0 {
+		return nil
+	}
+
+	for i, network := range r.containerNetworks(ctr) {
+		hooks, ok := r.config.NetworkHooks[network]
+		if !ok {
+			continue
+		}
+		cmd := hooks.PostSetup
+		if stage == NetworkHookPreTeardown {
+			cmd = hooks.PreTeardown
+		}
+		if len(cmd) == 0 {
+			continue
+		}
+
+		var result *cnitypes.Result
+		if i < len(results) {
+			result = results[i]
+		}
+		if err := runNetworkHook(ctr, network, stage, nsPath, cmd, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runNetworkHook runs a single network hook with the CNI result as JSON on
+// its standard input
+func runNetworkHook(ctr *Container, network, stage, nsPath string, command []string, result *cnitypes.Result) error {
+	input := []byte("{}")
+	if result != nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding CNI result of container %s on network %s", ctr.ID(), network)
+		}
+		input = b
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), networkHookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"PODMAN_CONTAINER_ID="+ctr.ID(),
+		"PODMAN_CONTAINER_NAME="+ctr.Name(),
+		"PODMAN_NETWORK="+network,
+		"PODMAN_NETNS="+nsPath,
+		"PODMAN_HOOK_STAGE="+stage,
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	logrus.Debugf("Running %s network hook %v for container %s on network %s", stage, command, ctr.ID(), network)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = errors.Errorf("timed out after %s", networkHookTimeout)
+		}
+		return errors.Wrapf(err, "%s network hook %s for container %s on network %s failed: %s", stage, command[0], ctr.ID(), network, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkHookConfigValidate(t *testing.T) {
+	assert.NoError(t, NetworkHookConfig{}.validate())
+	assert.NoError(t, NetworkHookConfig{
+		PostSetup:   []string{"/usr/bin/fw", "add"},
+		PreTeardown: []string{"/usr/bin/fw", "remove"},
+	}.validate())
+	assert.Error(t, NetworkHookConfig{PostSetup: []string{"fw"}}.validate())
+	assert.Error(t, NetworkHookConfig{PreTeardown: []string{"./fw"}}.validate())
+}
@@ -0,0 +1,71 @@
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+)
+
+// addressReservation is a static IP or MAC address requested by a container on
+// a CNI network. No other container may request the same address on the same
+// network.
+type addressReservation struct {
+	Network string
+	Address string
+}
+
+// defaultNetworkName returns the name of the default CNI network
+func (r *Runtime) defaultNetworkName() string {
+	if r.netPlugin != nil {
+		return r.netPlugin.GetDefaultNetworkName()
+	}
+	return r.config.CNIDefaultNetwork
+}
+
+// staticAddresses returns the static IP and MAC addresses requested by the
+// container in the given configuration, which must be reserved in the state.
+// The static IP and MAC set without naming a network are requested on the
+// network returned by staticAddressNetwork.
+func (c *Container) staticAddresses(config *ContainerConfig) []addressReservation {
+	network := c.staticAddressNetwork(config)
+
+	var addresses []addressReservation
+	if config.StaticIP != nil {
+		addresses = append(addresses, addressReservation{Network: network, Address: config.StaticIP.String()})
+	}
+	if config.StaticMAC != nil {
+		addresses = append(addresses, addressReservation{Network: network, Address: config.StaticMAC.String()})
+	}
+	for netName, ip := range config.NetworkStaticIPs {
+		addresses = append(addresses, addressReservation{Network: netName, Address: ip.String()})
+	}
+	return addresses
+}
+
+// staticAddressNetwork returns the network the static IP and MAC set without
+// naming a network are requested on: the first network the container joins,
+// or the default network if it joins none or requests a static IP, as
+// containerNetworks does
+func (c *Container) staticAddressNetwork(config *ContainerConfig) string {
+	if len(config.Networks) > 0 && config.StaticIP == nil {
+		return config.Networks[0]
+	}
+	if c.runtime != nil {
+		return c.runtime.defaultNetworkName()
+	}
+	return ""
+}
+
+// checkAddressNetwork checks that the network of an address to reserve is
+// known
+func checkAddressNetwork(ctrID string, address addressReservation) error {
+	if address.Network == "" {
+		return errors.Wrapf(define.ErrInvalidArg, "cannot reserve address %s for container %s, no network was given and there is no default network", address.Address, ctrID)
+	}
+	return nil
+}
+
+// errAddressInUse returns the error reported when an address is already
+// reserved by another container
+func errAddressInUse(ctrID string, address addressReservation, owner string) error {
+	return errors.Wrapf(define.ErrAddressInUse, "cannot reserve address %s on network %s for container %s, it is reserved by container %s", address.Address, address.Network, ctrID, owner)
+}
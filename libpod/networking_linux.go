@@ -7,16 +7,12 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
-	"strings"
-	"syscall"
-	"time"
 
 	cnitypes "github.com/containernetworking/cni/pkg/types/current"
 	"github.com/containernetworking/plugins/pkg/ns"
-	"github.com/containers/libpod/pkg/errorhandling"
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/pkg/netns"
 	"github.com/containers/libpod/pkg/rootless"
 	"github.com/cri-o/ocicni/pkg/ocicni"
@@ -27,7 +23,7 @@ import (
 )
 
 // Get an OCICNI network config
-func (r *Runtime) getPodNetwork(id, name, nsPath string, networks []string, ports []ocicni.PortMapping, staticIP net.IP) ocicni.PodNetwork {
+func (r *Runtime) getPodNetwork(id, name, nsPath string, networks []string, ports []ocicni.PortMapping, staticIP net.IP, bandwidth *ocicni.BandwidthConfig) ocicni.PodNetwork {
 	defaultNetwork := r.netPlugin.GetDefaultNetworkName()
 	network := ocicni.PodNetwork{
 		Name:      name,
@@ -47,6 +43,19 @@ func (r *Runtime) getPodNetwork(id, name, nsPath string, networks []string, port
 		}
 	}
 
+	// Bandwidth is limited on every network the container joins
+	if bandwidth != nil {
+		netNames := network.Networks
+		if len(netNames) == 0 {
+			netNames = []string{defaultNetwork}
+		}
+		for _, netName := range netNames {
+			rtConfig := network.RuntimeConfig[netName]
+			rtConfig.Bandwidth = bandwidth
+			network.RuntimeConfig[netName] = rtConfig
+		}
+	}
+
 	return network
 }
 
@@ -61,7 +70,24 @@ func (r *Runtime) configureNetNS(ctr *Container, ctrNS ns.NetNS) ([]*cnitypes.Re
 		requestedIP = ctr.config.StaticIP
 	}
 
-	podNetwork := r.getPodNetwork(ctr.ID(), ctr.Name(), ctrNS.Path(), ctr.config.Networks, ctr.config.PortMappings, requestedIP)
+	// Containers with per-network configuration, which OCICNI does not
+	// support, are attached to each of their networks in turn
+	if ctr.usesNetworkAttachments() {
+		networkStatus, interfaces, err := r.attachNetworks(ctr, ctrNS.Path(), requestedIP)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error configuring network namespace for container %s", ctr.ID())
+		}
+		ctr.state.NetworkInterfaces = interfaces
+		if err := r.runNetworkHooks(ctr, NetworkHookPostSetup, ctrNS.Path(), networkStatus); err != nil {
+			if err2 := r.detachNetworks(ctr, ctrNS.Path(), interfaces); err2 != nil {
+				logrus.Errorf("Error tearing down partially created network namespace for container %s: %v", ctr.ID(), err2)
+			}
+			return nil, err
+		}
+		return networkStatus, nil
+	}
+
+	podNetwork := r.getPodNetwork(ctr.ID(), ctr.Name(), ctrNS.Path(), ctr.config.Networks, ctr.config.PortMappings, requestedIP, ctr.config.Bandwidth)
 
 	results, err := r.netPlugin.SetUpPod(podNetwork)
 	if err != nil {
@@ -84,6 +110,13 @@ func (r *Runtime) configureNetNS(ctr *Container, ctrNS ns.NetNS) ([]*cnitypes.Re
 		}
 		networkStatus = append(networkStatus, resultCurrent)
 	}
+	ctr.state.NetworkInterfaces = nil
+
+	// A failing hook tears the network down again, as the container would
+	// not be reachable as expected.
+	if err = r.runNetworkHooks(ctr, NetworkHookPostSetup, ctrNS.Path(), networkStatus); err != nil {
+		return nil, err
+	}
 
 	return networkStatus, nil
 }
@@ -114,200 +147,6 @@ func (r *Runtime) createNetNS(ctr *Container) (n ns.NetNS, q []*cnitypes.Result,
 	return ctrNS, networkStatus, err
 }
 
-type slirp4netnsCmdArg struct {
-	Proto     string `json:"proto,omitempty"`
-	HostAddr  string `json:"host_addr"`
-	HostPort  int32  `json:"host_port"`
-	GuestAddr string `json:"guest_addr"`
-	GuestPort int32  `json:"guest_port"`
-}
-
-type slirp4netnsCmd struct {
-	Execute string            `json:"execute"`
-	Args    slirp4netnsCmdArg `json:"arguments"`
-}
-
-func checkSlirpFlags(path string) (bool, bool, error) {
-	cmd := exec.Command(path, "--help")
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return false, false, err
-	}
-	return strings.Contains(string(out), "--disable-host-loopback"), strings.Contains(string(out), "--mtu"), nil
-}
-
-// Configure the network namespace for a rootless container
-func (r *Runtime) setupRootlessNetNS(ctr *Container) (err error) {
-	defer errorhandling.CloseQuiet(ctr.rootlessSlirpSyncR)
-	defer errorhandling.CloseQuiet(ctr.rootlessSlirpSyncW)
-
-	path := r.config.NetworkCmdPath
-
-	if path == "" {
-		var err error
-		path, err = exec.LookPath("slirp4netns")
-		if err != nil {
-			logrus.Errorf("could not find slirp4netns, the network namespace won't be configured: %v", err)
-			return nil
-		}
-	}
-
-	syncR, syncW, err := os.Pipe()
-	if err != nil {
-		return errors.Wrapf(err, "failed to open pipe")
-	}
-	defer errorhandling.CloseQuiet(syncR)
-	defer errorhandling.CloseQuiet(syncW)
-
-	havePortMapping := len(ctr.Config().PortMappings) > 0
-	apiSocket := filepath.Join(ctr.ociRuntime.tmpDir, fmt.Sprintf("%s.net", ctr.config.ID))
-
-	cmdArgs := []string{}
-	if havePortMapping {
-		cmdArgs = append(cmdArgs, "--api-socket", apiSocket, fmt.Sprintf("%d", ctr.state.PID))
-	}
-	dhp, mtu, err := checkSlirpFlags(path)
-	if err != nil {
-		return errors.Wrapf(err, "error checking slirp4netns binary %s", path)
-	}
-	if dhp {
-		cmdArgs = append(cmdArgs, "--disable-host-loopback")
-	}
-	if mtu {
-		cmdArgs = append(cmdArgs, "--mtu", "65520")
-	}
-	cmdArgs = append(cmdArgs, "-c", "-e", "3", "-r", "4", fmt.Sprintf("%d", ctr.state.PID), "tap0")
-
-	cmd := exec.Command(path, cmdArgs...)
-
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
-	}
-	cmd.ExtraFiles = append(cmd.ExtraFiles, ctr.rootlessSlirpSyncR, syncW)
-
-	if err := cmd.Start(); err != nil {
-		return errors.Wrapf(err, "failed to start slirp4netns process")
-	}
-	defer func() {
-		if err := cmd.Process.Release(); err != nil {
-			logrus.Errorf("unable to release comman process: %q", err)
-		}
-	}()
-
-	b := make([]byte, 16)
-	for {
-		if err := syncR.SetDeadline(time.Now().Add(1 * time.Second)); err != nil {
-			return errors.Wrapf(err, "error setting slirp4netns pipe timeout")
-		}
-		if _, err := syncR.Read(b); err == nil {
-			break
-		} else {
-			if os.IsTimeout(err) {
-				// Check if the process is still running.
-				var status syscall.WaitStatus
-				pid, err := syscall.Wait4(cmd.Process.Pid, &status, syscall.WNOHANG, nil)
-				if err != nil {
-					return errors.Wrapf(err, "failed to read slirp4netns process status")
-				}
-				if pid != cmd.Process.Pid {
-					continue
-				}
-				if status.Exited() {
-					return errors.New("slirp4netns failed")
-				}
-				if status.Signaled() {
-					return errors.New("slirp4netns killed by signal")
-				}
-				continue
-			}
-			return errors.Wrapf(err, "failed to read from slirp4netns sync pipe")
-		}
-	}
-
-	if havePortMapping {
-		const pidWaitTimeout = 60 * time.Second
-		chWait := make(chan error)
-		go func() {
-			interval := 25 * time.Millisecond
-			for i := time.Duration(0); i < pidWaitTimeout; i += interval {
-				// Check if the process is still running.
-				var status syscall.WaitStatus
-				pid, err := syscall.Wait4(cmd.Process.Pid, &status, syscall.WNOHANG, nil)
-				if err != nil {
-					break
-				}
-				if pid != cmd.Process.Pid {
-					continue
-				}
-				if status.Exited() || status.Signaled() {
-					chWait <- fmt.Errorf("slirp4netns exited with status %d", status.ExitStatus())
-				}
-				time.Sleep(interval)
-			}
-		}()
-		defer close(chWait)
-
-		// wait that API socket file appears before trying to use it.
-		if _, err := WaitForFile(apiSocket, chWait, pidWaitTimeout*time.Millisecond); err != nil {
-			return errors.Wrapf(err, "waiting for slirp4nets to create the api socket file %s", apiSocket)
-		}
-
-		// for each port we want to add we need to open a connection to the slirp4netns control socket
-		// and send the add_hostfwd command.
-		for _, i := range ctr.config.PortMappings {
-			conn, err := net.Dial("unix", apiSocket)
-			if err != nil {
-				return errors.Wrapf(err, "cannot open connection to %s", apiSocket)
-			}
-			defer func() {
-				if err := conn.Close(); err != nil {
-					logrus.Errorf("unable to close connection: %q", err)
-				}
-			}()
-			hostIP := i.HostIP
-			if hostIP == "" {
-				hostIP = "0.0.0.0"
-			}
-			cmd := slirp4netnsCmd{
-				Execute: "add_hostfwd",
-				Args: slirp4netnsCmdArg{
-					Proto:     i.Protocol,
-					HostAddr:  hostIP,
-					HostPort:  i.HostPort,
-					GuestPort: i.ContainerPort,
-				},
-			}
-			// create the JSON payload and send it.  Mark the end of request shutting down writes
-			// to the socket, as requested by slirp4netns.
-			data, err := json.Marshal(&cmd)
-			if err != nil {
-				return errors.Wrapf(err, "cannot marshal JSON for slirp4netns")
-			}
-			if _, err := conn.Write([]byte(fmt.Sprintf("%s\n", data))); err != nil {
-				return errors.Wrapf(err, "cannot write to control socket %s", apiSocket)
-			}
-			if err := conn.(*net.UnixConn).CloseWrite(); err != nil {
-				return errors.Wrapf(err, "cannot shutdown the socket %s", apiSocket)
-			}
-			buf := make([]byte, 2048)
-			readLength, err := conn.Read(buf)
-			if err != nil {
-				return errors.Wrapf(err, "cannot read from control socket %s", apiSocket)
-			}
-			// if there is no 'error' key in the received JSON data, then the operation was
-			// successful.
-			var y map[string]interface{}
-			if err := json.Unmarshal(buf[0:readLength], &y); err != nil {
-				return errors.Wrapf(err, "error parsing error status from slirp4netns")
-			}
-			if e, found := y["error"]; found {
-				return errors.Errorf("error from slirp4netns while setting up port redirection: %v", e)
-			}
-		}
-	}
-	return nil
-}
-
 // Configure the network namespace using the container process
 func (r *Runtime) setupNetNS(ctr *Container) (err error) {
 	nsProcess := fmt.Sprintf("/proc/%d/ns/net", ctr.state.PID)
@@ -383,8 +222,20 @@ func (r *Runtime) teardownNetNS(ctr *Container) error {
 		return nil
 	}
 
+	// Namespaces joined by path were not created by libpod, they are only
+	// closed and never unmounted or removed
+	if ctr.config.NetNsPath != "" {
+		return r.closeNetNS(ctr)
+	}
+
 	logrus.Debugf("Tearing down network namespace at %s for container %s", ctr.state.NetNS.Path(), ctr.ID())
 
+	if ctr.config.DNSCache {
+		if err := ctr.stopDNSCache(); err != nil {
+			logrus.Errorf("Error stopping DNS cache for container %s: %v", ctr.ID(), err)
+		}
+	}
+
 	var requestedIP net.IP
 	if ctr.requestedIP != nil {
 		requestedIP = ctr.requestedIP
@@ -394,11 +245,24 @@ func (r *Runtime) teardownNetNS(ctr *Container) error {
 		requestedIP = ctr.config.StaticIP
 	}
 
-	podNetwork := r.getPodNetwork(ctr.ID(), ctr.Name(), ctr.state.NetNS.Path(), ctr.config.Networks, ctr.config.PortMappings, requestedIP)
+	if err := r.runNetworkHooks(ctr, NetworkHookPreTeardown, ctr.state.NetNS.Path(), ctr.state.NetworkStatus); err != nil {
+		logrus.Errorf("Error running network hooks: %v", err)
+	}
+
+	// Networks attached one by one, at creation or by connecting the
+	// container to them later, are detached from their recorded
+	// interfaces, which may not follow the order of the networks
+	if len(ctr.state.NetworkInterfaces) > 0 {
+		if err := r.detachNetworks(ctr, ctr.state.NetNS.Path(), ctr.state.NetworkInterfaces); err != nil {
+			return errors.Wrapf(err, "error tearing down CNI namespace configuration for container %s", ctr.ID())
+		}
+	} else {
+		podNetwork := r.getPodNetwork(ctr.ID(), ctr.Name(), ctr.state.NetNS.Path(), ctr.config.Networks, ctr.config.PortMappings, requestedIP, ctr.config.Bandwidth)
 
-	// The network may have already been torn down, so don't fail here, just log
-	if err := r.netPlugin.TearDownPod(podNetwork); err != nil {
-		return errors.Wrapf(err, "error tearing down CNI namespace configuration for container %s", ctr.ID())
+		// The network may have already been torn down, so don't fail here, just log
+		if err := r.netPlugin.TearDownPod(podNetwork); err != nil {
+			return errors.Wrapf(err, "error tearing down CNI namespace configuration for container %s", ctr.ID())
+		}
 	}
 
 	// First unmount the namespace
@@ -444,8 +308,12 @@ func getContainerNetIO(ctr *Container) (*netlink.LinkStatistics, error) {
 		// this is a valid state and thus return no error, nor any statistics
 		return nil, nil
 	}
+	ifName := ocicni.DefaultInterfaceName
+	if ctr.state.NetNS != nil {
+		ifName = ctr.defaultInterfaceName()
+	}
 	err := ns.WithNetNSPath(netNSPath, func(_ ns.NetNS) error {
-		link, err := netlink.LinkByName(ocicni.DefaultInterfaceName)
+		link, err := netlink.LinkByName(ifName)
 		if err != nil {
 			return err
 		}
@@ -457,24 +325,34 @@ func getContainerNetIO(ctr *Container) (*netlink.LinkStatistics, error) {
 
 func (c *Container) getContainerNetworkInfo(data *InspectContainerData) *InspectContainerData {
 	if c.state.NetNS != nil && len(c.state.NetworkStatus) > 0 {
-		// Report network settings from the first pod network
-		result := c.state.NetworkStatus[0]
-		// Go through our IP addresses
-		for _, ctrIP := range result.IPs {
-			ipWithMask := ctrIP.Address.String()
-			splitIP := strings.Split(ipWithMask, "/")
-			mask, _ := strconv.Atoi(splitIP[1])
-			if ctrIP.Version == "4" {
-				data.NetworkSettings.IPAddress = splitIP[0]
-				data.NetworkSettings.IPPrefixLen = mask
+		// Report the first address of each family as the container's
+		// address, and the addresses on other networks as secondary
+		ipv4, ipv6 := networkAddresses(c.state.NetworkStatus)
+		for i, ctrIP := range ipv4 {
+			if i > 0 {
+				data.NetworkSettings.SecondaryIPAddresses = append(data.NetworkSettings.SecondaryIPAddresses, ctrIP.Address.IP.String())
+				continue
+			}
+			data.NetworkSettings.IPAddress = ctrIP.Address.IP.String()
+			data.NetworkSettings.IPPrefixLen, _ = ctrIP.Address.Mask.Size()
+			if ctrIP.Gateway != nil {
 				data.NetworkSettings.Gateway = ctrIP.Gateway.String()
-			} else {
-				data.NetworkSettings.GlobalIPv6Address = splitIP[0]
-				data.NetworkSettings.GlobalIPv6PrefixLen = mask
+			}
+		}
+		for i, ctrIP := range ipv6 {
+			if i > 0 {
+				data.NetworkSettings.SecondaryIPv6Addresses = append(data.NetworkSettings.SecondaryIPv6Addresses, ctrIP.Address.IP.String())
+				continue
+			}
+			data.NetworkSettings.GlobalIPv6Address = ctrIP.Address.IP.String()
+			data.NetworkSettings.GlobalIPv6PrefixLen, _ = ctrIP.Address.Mask.Size()
+			if ctrIP.Gateway != nil {
 				data.NetworkSettings.IPv6Gateway = ctrIP.Gateway.String()
 			}
 		}
 
+		result := c.state.NetworkStatus[0]
+
 		// Set network namespace path
 		data.NetworkSettings.SandboxKey = c.state.NetNS.Path()
 
@@ -484,6 +362,40 @@ func (c *Container) getContainerNetworkInfo(data *InspectContainerData) *Inspect
 				data.NetworkSettings.MacAddress = i.Mac
 			}
 		}
+	} else if c.config.NetNsPath != "" {
+		// Namespaces joined by path are not configured by libpod,
+		// only their path is known
+		data.NetworkSettings.SandboxKey = c.config.NetNsPath
 	}
 	return data
 }
+
+// PortForward connects to a TCP port of a running container, dialing its
+// loopback address from inside its network namespace. This reaches ports
+// which are not published, whatever the network mode of the container.
+func (c *Container) PortForward(port uint16) (net.Conn, error) {
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.state.State != define.ContainerStateRunning {
+		return nil, errors.Wrapf(define.ErrCtrStateInvalid, "can only forward ports of running containers")
+	}
+
+	var conn net.Conn
+	nsPath := fmt.Sprintf("/proc/%d/ns/net", c.state.PID)
+	err := ns.WithNetNSPath(nsPath, func(_ ns.NetNS) error {
+		var err error
+		conn, err = net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to port %d of container %s", port, c.ID())
+	}
+	return conn, nil
+}
@@ -0,0 +1,27 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithNetNSPath(t *testing.T) {
+	ctr := &Container{config: &ContainerConfig{}}
+	assert.NoError(t, WithNetNSPath("/proc/1/ns/net")(ctr))
+	assert.Equal(t, "/proc/1/ns/net", ctr.config.NetNsPath)
+	assert.True(t, ctr.config.NetMode.IsNS())
+	assert.Equal(t, "/proc/1/ns/net", ctr.config.NetMode.NS())
+
+	// A namespace joined by path conflicts with creating one
+	err := WithNetNS(nil, false, "bridge", nil)(ctr)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+
+	err = WithNetNSPath("proc/1/ns/net")(&Container{config: &ContainerConfig{}})
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+
+	err = WithNetNSPath("/proc/1/ns/net")(&Container{config: &ContainerConfig{CreateNetNS: true}})
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
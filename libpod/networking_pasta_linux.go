@@ -0,0 +1,151 @@
+// +build linux
+
+package libpod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// pastaStopTimeout is how long to wait for pasta to exit before starting it
+// again with different ports
+const pastaStopTimeout = 5 * time.Second
+
+// pastaNetworking connects rootless containers to the host with pasta, which
+// copies the addresses and routes of the host into the container's network
+// namespace. pasta forwards the ports given when it starts, so it is started
+// again to change them.
+type pastaNetworking struct{}
+
+func (pastaNetworking) processName() string {
+	return "pasta"
+}
+
+// pastaPIDFile returns the path of the file pasta writes its PID to once it
+// has configured the container's network namespace
+func pastaPIDFile(ctr *Container) string {
+	return filepath.Join(ctr.runtime.config.TmpDir, fmt.Sprintf("%s.pasta.pid", ctr.config.ID))
+}
+
+// pastaPortArgs returns the pasta options forwarding the given ports
+func pastaPortArgs(ports []ocicni.PortMapping) []string {
+	var tcp, udp []string
+	for _, port := range ports {
+		spec := fmt.Sprintf("%d:%d", port.HostPort, port.ContainerPort)
+		if port.HostIP != "" {
+			spec = port.HostIP + "/" + spec
+		}
+		switch strings.ToLower(port.Protocol) {
+		case "udp":
+			udp = append(udp, spec)
+		default:
+			tcp = append(tcp, spec)
+		}
+	}
+
+	// pasta would forward all the ports bound on the host by default
+	if len(tcp) == 0 {
+		tcp = []string{"none"}
+	}
+	if len(udp) == 0 {
+		udp = []string{"none"}
+	}
+
+	args := make([]string, 0, 2*(len(tcp)+len(udp)))
+	for _, spec := range tcp {
+		args = append(args, "-t", spec)
+	}
+	for _, spec := range udp {
+		args = append(args, "-u", spec)
+	}
+	return args
+}
+
+func (pastaNetworking) setup(ctr *Container, ports []ocicni.PortMapping) ([]int, error) {
+	path, err := exec.LookPath("pasta")
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find pasta, the network namespace can't be configured")
+	}
+
+	pidFile := pastaPIDFile(ctr)
+	if err := os.Remove(pidFile); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "error removing pasta PID file %s", pidFile)
+	}
+
+	cmdArgs := []string{"--config-net", "--pid", pidFile}
+	cmdArgs = append(cmdArgs, pastaPortArgs(ports)...)
+	cmdArgs = append(cmdArgs, strconv.Itoa(ctr.state.PID))
+
+	// pasta runs in the background once the network namespace is
+	// configured, the command returns when it is ready
+	logrus.Debugf("Running pasta with arguments %v for container %s", cmdArgs, ctr.ID())
+	if out, err := exec.Command(path, cmdArgs...).CombinedOutput(); err != nil {
+		return nil, errors.Wrapf(err, "failed to start pasta: %s", strings.TrimSpace(string(out)))
+	}
+
+	data, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading pasta PID file %s", pidFile)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pasta PID file %s", pidFile)
+	}
+	// pasta forwards ports without a host IP from both IPv4 and IPv6
+	ctr.state.RootlessNetworkIPv6 = true
+	return []int{pid}, nil
+}
+
+func (p pastaNetworking) forwardPorts(ctr *Container, ports, added, removed []ocicni.PortMapping) ([]int, error) {
+	for _, pid := range ctr.state.RootlessNetworkPIDs {
+		stopped, err := stopRootlessNetworkProcess(pid, p.processName())
+		if err != nil {
+			return nil, err
+		}
+		if !stopped {
+			continue
+		}
+		if err := waitForProcessExit(pid, pastaStopTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	pids, err := p.setup(ctr, ports)
+	if err != nil {
+		// Restore the network of the container with the ports it had
+		if oldPids, err2 := p.setup(ctr, ctr.config.PortMappings); err2 != nil {
+			logrus.Errorf("Error restoring network of container %s: %v", ctr.ID(), err2)
+			ctr.state.RootlessNetworkPIDs = nil
+		} else {
+			ctr.state.RootlessNetworkPIDs = oldPids
+		}
+		if err2 := ctr.save(); err2 != nil {
+			logrus.Errorf("Error saving state of container %s: %v", ctr.ID(), err2)
+		}
+		return nil, err
+	}
+	return pids, nil
+}
+
+// waitForProcessExit waits until the process with the given PID has exited
+func waitForProcessExit(pid int, timeout time.Duration) error {
+	const interval = 25 * time.Millisecond
+	for waited := time.Duration(0); waited < timeout; waited += interval {
+		if err := unix.Kill(pid, 0); err == unix.ESRCH {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+	return errors.Errorf("timed out waiting for process %d to exit", pid)
+}
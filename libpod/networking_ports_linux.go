@@ -0,0 +1,200 @@
+// +build linux
+
+package libpod
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// portMappingPlugin returns the plugin of a CNI configuration list that
+// forwards ports to containers, if any
+func portMappingPlugin(conf *libcni.NetworkConfigList) *libcni.NetworkConfig {
+	for _, plugin := range conf.Plugins {
+		if plugin.Network.Capabilities["portMappings"] {
+			return plugin
+		}
+	}
+	return nil
+}
+
+// runPortMappingPlugin runs only the port mapping plugin of the default network
+// for the container, passing it the container's result on the network so it
+// can forward the given ports to the container's address.
+func (r *Runtime) runPortMappingPlugin(ctr *Container, command string, ports []ocicni.PortMapping) error {
+	defaultNetwork := r.netPlugin.GetDefaultNetworkName()
+	index := -1
+	for i, netName := range r.containerNetworks(ctr) {
+		if netName == defaultNetwork {
+			index = i
+			break
+		}
+	}
+	if index == -1 || index >= len(ctr.state.NetworkStatus) {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s is not connected to the default network %s, which publishes ports", ctr.ID(), defaultNetwork)
+	}
+
+	conf, err := r.cniNetworkConfig(defaultNetwork)
+	if err != nil {
+		return err
+	}
+	plugin := portMappingPlugin(conf)
+	if plugin == nil {
+		return errors.Wrapf(define.ErrInvalidArg, "the CNI configuration of network %s does not support port mappings", defaultNetwork)
+	}
+	pluginPath, err := invoke.FindInPath(plugin.Network.Type, r.config.CNIPluginDir)
+	if err != nil {
+		return errors.Wrapf(err, "error finding CNI plugin %s", plugin.Network.Type)
+	}
+
+	prevResult, err := ctr.state.NetworkStatus[index].GetAsVersion(conf.CNIVersion)
+	if err != nil {
+		return errors.Wrapf(err, "error converting CNI result of container %s", ctr.ID())
+	}
+	pluginConf, err := libcni.InjectConf(plugin, map[string]interface{}{
+		"name":          conf.Name,
+		"cniVersion":    conf.CNIVersion,
+		"prevResult":    prevResult,
+		"runtimeConfig": map[string]interface{}{"portMappings": ports},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error building configuration of CNI plugin %s", plugin.Network.Type)
+	}
+
+	nsPath := ctr.state.NetNS.Path()
+	ifName := r.networkInterfaces(ctr)[defaultNetwork]
+	rt := r.cniRuntimeConf(ctr, ctr.config, nsPath, defaultNetwork, ifName, ctr.networkStaticIP(ctr.config, defaultNetwork))
+	args := &invoke.Args{
+		Command:     command,
+		ContainerID: rt.ContainerID,
+		NetNS:       rt.NetNS,
+		PluginArgs:  rt.Args,
+		IfName:      rt.IfName,
+		Path:        strings.Join(r.config.CNIPluginDir, string(os.PathListSeparator)),
+	}
+
+	logrus.Debugf("Running CNI plugin %s %s for container %s with %d ports", plugin.Network.Type, command, ctr.ID(), len(ports))
+	if command == "ADD" {
+		_, err = invoke.ExecPluginWithResult(context.Background(), pluginPath, pluginConf.Bytes, args, nil)
+	} else {
+		err = invoke.ExecPluginWithoutResult(context.Background(), pluginPath, pluginConf.Bytes, args, nil)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error running CNI plugin %s for container %s", plugin.Network.Type, ctr.ID())
+	}
+	return nil
+}
+
+// forwardPorts changes the ports forwarded to the container's active network
+// namespace from oldPorts to newPorts
+func (r *Runtime) forwardPorts(ctr *Container, oldPorts, newPorts, added, removed []ocicni.PortMapping) error {
+	if backend := ctr.rootlessNetworking(); backend != nil {
+		return r.forwardRootlessPorts(ctr, backend, newPorts, added, removed)
+	}
+
+	// The port mapping plugin replaces all the container's forwarded
+	// ports at once: remove the old ones and forward the new ones.
+	if len(oldPorts) > 0 {
+		if err := r.runPortMappingPlugin(ctr, "DEL", oldPorts); err != nil {
+			return err
+		}
+	}
+	if len(newPorts) > 0 {
+		if err := r.runPortMappingPlugin(ctr, "ADD", newPorts); err != nil {
+			if len(oldPorts) > 0 {
+				if err2 := r.runPortMappingPlugin(ctr, "ADD", oldPorts); err2 != nil {
+					logrus.Errorf("Error restoring forwarded ports of container %s: %v", ctr.ID(), err2)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// samePort returns whether two port mappings forward the same host port.
+// Port mappings without a protocol use TCP.
+func samePort(a, b ocicni.PortMapping) bool {
+	protoA, protoB := a.Protocol, b.Protocol
+	if protoA == "" {
+		protoA = "tcp"
+	}
+	if protoB == "" {
+		protoB = "tcp"
+	}
+	return a.HostPort == b.HostPort && a.HostIP == b.HostIP && strings.EqualFold(protoA, protoB)
+}
+
+// updatePorts publishes and unpublishes ports of the container.
+// If the container's network is active, the ports are forwarded or no longer
+// forwarded immediately. The new ports are saved in the container's
+// configuration, so they are kept when it is restarted. Ports published this
+// way are not reserved by conmon until the container is restarted.
+// Must be called with the container locked.
+func (c *Container) updatePorts(add, remove []ocicni.PortMapping) error {
+	if !c.config.CreateNetNS {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s does not have a network namespace managed by libpod, cannot publish ports", c.ID())
+	}
+
+	newPorts := make([]ocicni.PortMapping, 0, len(c.config.PortMappings)+len(add))
+	for _, port := range c.config.PortMappings {
+		removed := false
+		for _, r := range remove {
+			if samePort(port, r) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			newPorts = append(newPorts, port)
+		}
+	}
+	if len(newPorts) != len(c.config.PortMappings)-len(remove) {
+		return errors.Wrapf(define.ErrInvalidArg, "not all of the ports to unpublish are published by container %s", c.ID())
+	}
+	for _, port := range add {
+		if port.HostPort <= 0 || port.ContainerPort <= 0 {
+			return errors.Wrapf(define.ErrInvalidArg, "invalid port mapping %d:%d", port.HostPort, port.ContainerPort)
+		}
+		if port.Protocol == "" {
+			port.Protocol = "tcp"
+		}
+		for _, existing := range newPorts {
+			if samePort(port, existing) {
+				return errors.Wrapf(define.ErrInvalidArg, "host port %d/%s is already published by container %s", port.HostPort, port.Protocol, c.ID())
+			}
+		}
+		newPorts = append(newPorts, port)
+	}
+
+	newConfig := c.Config()
+	if newConfig == nil {
+		return errors.Wrapf(define.ErrInternal, "error copying configuration of container %s", c.ID())
+	}
+	newConfig.PortMappings = newPorts
+
+	networkActive := c.state.NetNS != nil
+	if c.rootlessNetworking() != nil {
+		networkActive = c.state.State == define.ContainerStateRunning || c.state.State == define.ContainerStatePaused
+	}
+	if networkActive {
+		if err := c.runtime.forwardPorts(c, c.config.PortMappings, newPorts, newPorts[len(newPorts)-len(add):], remove); err != nil {
+			return err
+		}
+	}
+
+	if err := c.runtime.state.RewriteContainerConfig(c, newConfig); err != nil {
+		return errors.Wrapf(err, "error saving published ports of container %s", c.ID())
+	}
+	c.config = newConfig
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// +build linux
+
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamePort(t *testing.T) {
+	port := ocicni.PortMapping{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}
+	assert.True(t, samePort(port, ocicni.PortMapping{HostPort: 8080, ContainerPort: 8000}))
+	assert.True(t, samePort(port, ocicni.PortMapping{HostPort: 8080, Protocol: "TCP"}))
+	assert.False(t, samePort(port, ocicni.PortMapping{HostPort: 8080, Protocol: "udp"}))
+	assert.False(t, samePort(port, ocicni.PortMapping{HostPort: 8080, HostIP: "127.0.0.1"}))
+	assert.False(t, samePort(port, ocicni.PortMapping{HostPort: 8081}))
+}
+
+func TestPortMappingPlugin(t *testing.T) {
+	conf, err := libcni.ConfListFromBytes([]byte(`{
+		"cniVersion": "0.4.0",
+		"name": "podman",
+		"plugins": [
+			{"type": "bridge", "bridge": "cni-podman0"},
+			{"type": "portmap", "capabilities": {"portMappings": true}}
+		]
+	}`))
+	assert.NoError(t, err)
+	plugin := portMappingPlugin(conf)
+	if assert.NotNil(t, plugin) {
+		assert.Equal(t, "portmap", plugin.Network.Type)
+	}
+
+	conf.Plugins = conf.Plugins[:1]
+	assert.Nil(t, portMappingPlugin(conf))
+}
+
+func TestPastaPortArgs(t *testing.T) {
+	assert.Equal(t, []string{"-t", "none", "-u", "none"}, pastaPortArgs(nil))
+
+	args := pastaPortArgs([]ocicni.PortMapping{
+		{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+		{HostPort: 5353, ContainerPort: 53, Protocol: "udp", HostIP: "127.0.0.1"},
+	})
+	assert.Equal(t, []string{"-t", "8080:80", "-u", "127.0.0.1/5353:53"}, args)
+}
+
+func TestPortHostAddresses(t *testing.T) {
+	port := ocicni.PortMapping{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"}
+	assert.Equal(t, []string{"0.0.0.0"}, portHostAddresses(port, false))
+	assert.Equal(t, []string{"0.0.0.0", "::"}, portHostAddresses(port, true))
+
+	port.HostIP = "::1"
+	assert.Equal(t, []string{"::1"}, portHostAddresses(port, true))
+	assert.True(t, isIPv6(port.HostIP))
+	assert.False(t, isIPv6("127.0.0.1"))
+}
@@ -0,0 +1,125 @@
+// +build linux
+
+package libpod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// rootlessNetworking is a user-mode network stack connecting the network
+// namespace of a rootless container to the host. It runs as processes outside
+// of the container, which are tracked in the container's state so they can be
+// stopped when the container stops, and started again when it restarts.
+type rootlessNetworking interface {
+	// processName is the name of the processes started by the network
+	// stack, used to recognize them before stopping them.
+	processName() string
+	// setup connects the network namespace of the container's running
+	// process to the host and forwards the given ports to it. It returns
+	// the PIDs of the processes it started.
+	setup(ctr *Container, ports []ocicni.PortMapping) ([]int, error)
+	// forwardPorts changes the ports forwarded to a running container to
+	// the given ports, of which added were not forwarded before and removed
+	// are no longer forwarded. If the network stack had to start new
+	// processes to do so, it returns their PIDs, which replace the ones
+	// tracked in the container's state.
+	forwardPorts(ctr *Container, ports, added, removed []ocicni.PortMapping) ([]int, error)
+}
+
+// rootlessNetworkingBackends are the available rootless network stacks, by the
+// network mode selecting them
+var rootlessNetworkingBackends = map[string]rootlessNetworking{
+	"slirp4netns": slirp4netnsNetworking{},
+	"pasta":       pastaNetworking{},
+}
+
+// rootlessNetworking returns the rootless network stack used by the container,
+// or nil if its network mode does not select one
+func (c *Container) rootlessNetworking() rootlessNetworking {
+	return rootlessNetworkingBackends[string(c.config.NetMode)]
+}
+
+// Configure the network namespace for a rootless container
+func (r *Runtime) setupRootlessNetNS(ctr *Container) error {
+	backend := ctr.rootlessNetworking()
+	if backend == nil {
+		return errors.Wrapf(define.ErrInvalidArg, "network mode %q of container %s does not use a rootless network stack", ctr.config.NetMode, ctr.ID())
+	}
+
+	// Processes left over by a previous run of the container that was not
+	// cleaned up would still hold the container's ports
+	r.teardownRootlessNetNS(ctr)
+
+	pids, err := backend.setup(ctr, ctr.config.PortMappings)
+	if err != nil {
+		return err
+	}
+	ctr.state.RootlessNetworkPIDs = pids
+	return nil
+}
+
+// Stop the processes of the rootless network stack of a container.
+// Errors are logged, not returned, as the processes usually exit by themselves
+// when the container does.
+func (r *Runtime) teardownRootlessNetNS(ctr *Container) {
+	backend := ctr.rootlessNetworking()
+	if backend == nil {
+		return
+	}
+	for _, pid := range ctr.state.RootlessNetworkPIDs {
+		if _, err := stopRootlessNetworkProcess(pid, backend.processName()); err != nil {
+			logrus.Errorf("Error stopping rootless network process of container %s: %v", ctr.ID(), err)
+		}
+	}
+	ctr.state.RootlessNetworkPIDs = nil
+	ctr.state.RootlessNetworkIPv6 = false
+}
+
+// stopRootlessNetworkProcess stops the process with the given PID, if it is
+// still running and has the given name, and returns whether it was signaled.
+// PIDs recorded by an earlier run of the container may have been reused by
+// unrelated processes since.
+func stopRootlessNetworkProcess(pid int, name string) (bool, error) {
+	comm, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "error reading name of process %d", pid)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(comm)), name) {
+		return false, nil
+	}
+
+	logrus.Debugf("Stopping rootless network process %s (PID %d)", name, pid)
+	if err := unix.Kill(pid, unix.SIGTERM); err != nil {
+		if err == unix.ESRCH {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "error stopping process %d", pid)
+	}
+	return true, nil
+}
+
+// forwardRootlessPorts changes the ports forwarded to a running rootless
+// container, tracking any processes the network stack had to start again
+func (r *Runtime) forwardRootlessPorts(ctr *Container, backend rootlessNetworking, ports, added, removed []ocicni.PortMapping) error {
+	pids, err := backend.forwardPorts(ctr, ports, added, removed)
+	if err != nil {
+		return err
+	}
+	if pids == nil {
+		return nil
+	}
+	ctr.state.RootlessNetworkPIDs = pids
+	return ctr.save()
+}
@@ -0,0 +1,354 @@
+// +build linux
+
+package libpod
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/errorhandling"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type slirp4netnsCmdArg struct {
+	Proto     string `json:"proto,omitempty"`
+	HostAddr  string `json:"host_addr"`
+	HostPort  int32  `json:"host_port"`
+	GuestAddr string `json:"guest_addr"`
+	GuestPort int32  `json:"guest_port"`
+}
+
+type slirp4netnsCmd struct {
+	Execute string            `json:"execute"`
+	Args    slirp4netnsCmdArg `json:"arguments"`
+}
+
+// slirp4netnsFeatures are the optional features supported by a slirp4netns
+// binary
+type slirp4netnsFeatures struct {
+	HasDisableHostLoopback bool
+	HasMTU                 bool
+	HasIPv6                bool
+}
+
+func checkSlirpFlags(path string) (*slirp4netnsFeatures, error) {
+	cmd := exec.Command(path, "--help")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, err
+	}
+	return &slirp4netnsFeatures{
+		HasDisableHostLoopback: strings.Contains(string(out), "--disable-host-loopback"),
+		HasMTU:                 strings.Contains(string(out), "--mtu"),
+		HasIPv6:                strings.Contains(string(out), "--enable-ipv6"),
+	}, nil
+}
+
+// slirp4netnsNetworking connects rootless containers to the host with
+// slirp4netns. Ports are forwarded by slirp4netns itself, through its API
+// socket.
+type slirp4netnsNetworking struct{}
+
+func (slirp4netnsNetworking) processName() string {
+	return "slirp4netns"
+}
+
+func (slirp4netnsNetworking) setup(ctr *Container, ports []ocicni.PortMapping) ([]int, error) {
+	defer errorhandling.CloseQuiet(ctr.rootlessSlirpSyncR)
+	defer errorhandling.CloseQuiet(ctr.rootlessSlirpSyncW)
+
+	path := ctr.runtime.config.NetworkCmdPath
+
+	if path == "" {
+		var err error
+		path, err = exec.LookPath("slirp4netns")
+		if err != nil {
+			logrus.Errorf("could not find slirp4netns, the network namespace won't be configured: %v", err)
+			return nil, nil
+		}
+	}
+
+	syncR, syncW, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open pipe")
+	}
+	defer errorhandling.CloseQuiet(syncR)
+	defer errorhandling.CloseQuiet(syncW)
+
+	havePortMapping := len(ports) > 0
+	apiSocket := slirp4netnsAPISocket(ctr)
+
+	cmdArgs := []string{}
+	if havePortMapping {
+		cmdArgs = append(cmdArgs, "--api-socket", apiSocket, fmt.Sprintf("%d", ctr.state.PID))
+	}
+	features, err := checkSlirpFlags(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error checking slirp4netns binary %s", path)
+	}
+	if features.HasDisableHostLoopback {
+		cmdArgs = append(cmdArgs, "--disable-host-loopback")
+	}
+	if features.HasMTU {
+		cmdArgs = append(cmdArgs, "--mtu", "65520")
+	}
+	if features.HasIPv6 {
+		cmdArgs = append(cmdArgs, "--enable-ipv6")
+	}
+	ctr.state.RootlessNetworkIPv6 = features.HasIPv6
+	cmdArgs = append(cmdArgs, "-c", "-e", "3", "-r", "4", fmt.Sprintf("%d", ctr.state.PID), "tap0")
+
+	cmd := exec.Command(path, cmdArgs...)
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, ctr.rootlessSlirpSyncR, syncW)
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "failed to start slirp4netns process")
+	}
+	defer func() {
+		if err := cmd.Process.Release(); err != nil {
+			logrus.Errorf("unable to release comman process: %q", err)
+		}
+	}()
+
+	b := make([]byte, 16)
+	for {
+		if err := syncR.SetDeadline(time.Now().Add(1 * time.Second)); err != nil {
+			return nil, errors.Wrapf(err, "error setting slirp4netns pipe timeout")
+		}
+		if _, err := syncR.Read(b); err == nil {
+			break
+		} else {
+			if os.IsTimeout(err) {
+				// Check if the process is still running.
+				var status syscall.WaitStatus
+				pid, err := syscall.Wait4(cmd.Process.Pid, &status, syscall.WNOHANG, nil)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to read slirp4netns process status")
+				}
+				if pid != cmd.Process.Pid {
+					continue
+				}
+				if status.Exited() {
+					return nil, errors.New("slirp4netns failed")
+				}
+				if status.Signaled() {
+					return nil, errors.New("slirp4netns killed by signal")
+				}
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to read from slirp4netns sync pipe")
+		}
+	}
+
+	if havePortMapping {
+		const pidWaitTimeout = 60 * time.Second
+		chWait := make(chan error)
+		go func() {
+			interval := 25 * time.Millisecond
+			for i := time.Duration(0); i < pidWaitTimeout; i += interval {
+				// Check if the process is still running.
+				var status syscall.WaitStatus
+				pid, err := syscall.Wait4(cmd.Process.Pid, &status, syscall.WNOHANG, nil)
+				if err != nil {
+					break
+				}
+				if pid != cmd.Process.Pid {
+					continue
+				}
+				if status.Exited() || status.Signaled() {
+					chWait <- fmt.Errorf("slirp4netns exited with status %d", status.ExitStatus())
+				}
+				time.Sleep(interval)
+			}
+		}()
+		defer close(chWait)
+
+		// wait that API socket file appears before trying to use it.
+		if _, err := WaitForFile(apiSocket, chWait, pidWaitTimeout*time.Millisecond); err != nil {
+			return nil, errors.Wrapf(err, "waiting for slirp4nets to create the api socket file %s", apiSocket)
+		}
+
+		for _, i := range ports {
+			if err := addSlirp4netnsHostFwd(apiSocket, i, features.HasIPv6); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return []int{cmd.Process.Pid}, nil
+}
+
+// slirp4netnsRemoveCmdArg is the argument of the slirp4netns remove_hostfwd
+// command
+type slirp4netnsRemoveCmdArg struct {
+	ID int `json:"id"`
+}
+
+type slirp4netnsRemoveCmd struct {
+	Execute string                  `json:"execute"`
+	Args    slirp4netnsRemoveCmdArg `json:"arguments"`
+}
+
+// slirp4netnsHostFwd is a port forwarded by slirp4netns, as reported by its
+// list_hostfwd command
+type slirp4netnsHostFwd struct {
+	ID        int    `json:"id"`
+	Proto     string `json:"proto"`
+	HostAddr  string `json:"host_addr"`
+	HostPort  int32  `json:"host_port"`
+	GuestPort int32  `json:"guest_port"`
+}
+
+// slirp4netnsAPISocket returns the path of the API socket of the container's
+// slirp4netns process. The socket is only created if the container was started
+// with ports to forward.
+func slirp4netnsAPISocket(ctr *Container) string {
+	return filepath.Join(ctr.runtime.config.TmpDir, fmt.Sprintf("%s.net", ctr.config.ID))
+}
+
+// slirp4netnsRequest sends a command to the slirp4netns API socket and returns
+// the decoded response
+func slirp4netnsRequest(apiSocket string, cmd interface{}) (map[string]interface{}, error) {
+	conn, err := net.Dial("unix", apiSocket)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open connection to %s", apiSocket)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logrus.Errorf("unable to close connection: %q", err)
+		}
+	}()
+
+	// create the JSON payload and send it.  Mark the end of request shutting down writes
+	// to the socket, as requested by slirp4netns.
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot marshal JSON for slirp4netns")
+	}
+	if _, err := conn.Write([]byte(fmt.Sprintf("%s\n", data))); err != nil {
+		return nil, errors.Wrapf(err, "cannot write to control socket %s", apiSocket)
+	}
+	if err := conn.(*net.UnixConn).CloseWrite(); err != nil {
+		return nil, errors.Wrapf(err, "cannot shutdown the socket %s", apiSocket)
+	}
+	buf, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read from control socket %s", apiSocket)
+	}
+	// if there is no 'error' key in the received JSON data, then the operation was
+	// successful.
+	var y map[string]interface{}
+	if err := json.Unmarshal(buf, &y); err != nil {
+		return nil, errors.Wrapf(err, "error parsing error status from slirp4netns")
+	}
+	if e, found := y["error"]; found {
+		return nil, errors.Errorf("error from slirp4netns: %v", e)
+	}
+	return y, nil
+}
+
+// addSlirp4netnsHostFwd forwards a port to the container through slirp4netns.
+// If ipv6 is set, ports without a host IP are forwarded from both the IPv4 and
+// IPv6 addresses of the host.
+func addSlirp4netnsHostFwd(apiSocket string, port ocicni.PortMapping, ipv6 bool) error {
+	for _, hostIP := range portHostAddresses(port, ipv6) {
+		cmd := slirp4netnsCmd{
+			Execute: "add_hostfwd",
+			Args: slirp4netnsCmdArg{
+				Proto:     port.Protocol,
+				HostAddr:  hostIP,
+				HostPort:  port.HostPort,
+				GuestPort: port.ContainerPort,
+			},
+		}
+		if _, err := slirp4netnsRequest(apiSocket, &cmd); err != nil {
+			return errors.Wrapf(err, "error setting up port redirection of host port %d on %s", port.HostPort, hostIP)
+		}
+	}
+	return nil
+}
+
+// removeSlirp4netnsHostFwd stops forwarding a port to the container through
+// slirp4netns
+func removeSlirp4netnsHostFwd(apiSocket string, port ocicni.PortMapping) error {
+	resp, err := slirp4netnsRequest(apiSocket, map[string]string{"execute": "list_hostfwd"})
+	if err != nil {
+		return errors.Wrapf(err, "error listing forwarded ports")
+	}
+	var list struct {
+		Entries []slirp4netnsHostFwd `json:"entries"`
+	}
+	// Round-trip the response to decode the entries
+	b, err := json.Marshal(resp["return"])
+	if err != nil {
+		return errors.Wrapf(err, "error encoding forwarded ports")
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return errors.Wrapf(err, "error decoding forwarded ports")
+	}
+
+	hostIPs := portHostAddresses(port, true)
+	proto := port.Protocol
+	if proto == "" {
+		proto = "tcp"
+	}
+	removed := false
+	for _, fwd := range list.Entries {
+		if fwd.HostPort != port.HostPort || !strings.EqualFold(fwd.Proto, proto) {
+			continue
+		}
+		matches := false
+		for _, hostIP := range hostIPs {
+			if net.ParseIP(fwd.HostAddr).Equal(net.ParseIP(hostIP)) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		cmd := slirp4netnsRemoveCmd{
+			Execute: "remove_hostfwd",
+			Args:    slirp4netnsRemoveCmdArg{ID: fwd.ID},
+		}
+		if _, err := slirp4netnsRequest(apiSocket, &cmd); err != nil {
+			return errors.Wrapf(err, "error removing port redirection of host port %d", port.HostPort)
+		}
+		removed = true
+	}
+	if !removed {
+		logrus.Debugf("Host port %d is not forwarded by slirp4netns, nothing to remove", port.HostPort)
+	}
+	return nil
+}
+
+func (slirp4netnsNetworking) forwardPorts(ctr *Container, ports, added, removed []ocicni.PortMapping) ([]int, error) {
+	apiSocket := slirp4netnsAPISocket(ctr)
+	if _, err := os.Stat(apiSocket); err != nil {
+		return nil, errors.Wrapf(define.ErrCtrStateInvalid, "container %s was started without published ports, it must be restarted to publish ports", ctr.ID())
+	}
+	for _, port := range removed {
+		if err := removeSlirp4netnsHostFwd(apiSocket, port); err != nil {
+			return nil, err
+		}
+	}
+	for _, port := range added {
+		if err := addSlirp4netnsHostFwd(apiSocket, port, ctr.state.RootlessNetworkIPv6); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
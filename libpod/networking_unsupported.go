@@ -2,7 +2,12 @@
 
 package libpod
 
-import "github.com/containers/libpod/libpod/define"
+import (
+	"net"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+)
 
 func (r *Runtime) setupRootlessNetNS(ctr *Container) (err error) {
 	return define.ErrNotImplemented
@@ -23,3 +28,19 @@ func (r *Runtime) createNetNS(ctr *Container) (err error) {
 func (c *Container) getContainerNetworkInfo(data *InspectContainerData) *InspectContainerData {
 	return nil
 }
+
+func (c *Container) networkConnect(netName string, aliases []string, staticIP net.IP) error {
+	return define.ErrNotImplemented
+}
+
+func (c *Container) networkDisconnect(netName string) error {
+	return define.ErrNotImplemented
+}
+
+func (c *Container) updatePorts(add, remove []ocicni.PortMapping) error {
+	return define.ErrNotImplemented
+}
+
+func (c *Container) PortForward(port uint16) (net.Conn, error) {
+	return nil, define.ErrNotImplemented
+}
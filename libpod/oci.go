@@ -8,7 +8,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/containers/libpod/libpod/define"
@@ -38,6 +40,9 @@ const (
 	// Timeout before declaring that runtime has failed to kill a given
 	// container
 	killContainerTimeout = 5 * time.Second
+	// Timeout before declaring that a VM-based runtime has failed to kill a
+	// given container, which includes shutting down its virtual machine
+	vmKillContainerTimeout = 30 * time.Second
 	// DefaultShmSize is the default shm size
 	DefaultShmSize = 64 * 1024 * 1024
 	// NsRunDir is the default directory in which running network namespaces
@@ -45,9 +50,9 @@ const (
 	NsRunDir = "/var/run/netns"
 )
 
-// OCIRuntime represents an OCI-compatible runtime that libpod can call into
-// to perform container operations
-type OCIRuntime struct {
+// ConmonOCIRuntime is an OCI runtime following the command line interface of
+// runc, such as runc, crun or kata-runtime. Containers are monitored by conmon.
+type ConmonOCIRuntime struct {
 	name          string
 	path          string
 	conmonPath    string
@@ -61,6 +66,9 @@ type OCIRuntime struct {
 	reservePorts  bool
 	supportsJSON  bool
 	sdNotify      bool
+
+	featuresOnce      sync.Once
+	supportedFeatures *ociRuntimeFeatures
 }
 
 // ociError is used to parse the OCI runtime JSON log.  It is not part of the
@@ -73,12 +81,12 @@ type ociError struct {
 
 // Make a new OCI runtime with provided options.
 // The first path that points to a valid executable will be used.
-func newOCIRuntime(name string, paths []string, conmonPath string, runtimeCfg *RuntimeConfig, supportsJSON bool) (*OCIRuntime, error) {
+func newConmonOCIRuntime(name string, paths []string, conmonPath string, runtimeCfg *RuntimeConfig, supportsJSON bool) (*ConmonOCIRuntime, error) {
 	if name == "" {
 		return nil, errors.Wrapf(define.ErrInvalidArg, "the OCI runtime must be provided a non-empty name")
 	}
 
-	runtime := new(OCIRuntime)
+	runtime := new(ConmonOCIRuntime)
 	runtime.name = name
 	runtime.conmonPath = conmonPath
 
@@ -151,6 +159,40 @@ func newOCIRuntime(name string, paths []string, conmonPath string, runtimeCfg *R
 	return runtime, nil
 }
 
+// Name returns the name of the runtime
+func (r *ConmonOCIRuntime) Name() string {
+	return r.name
+}
+
+// Path returns the path of the runtime's executable
+func (r *ConmonOCIRuntime) Path() string {
+	return r.path
+}
+
+// SupportsJSONErrors returns whether the runtime writes its errors to a JSON
+// log
+func (r *ConmonOCIRuntime) SupportsJSONErrors() bool {
+	return r.supportsJSON
+}
+
+// readConmonPidFile attempts to read conmon's pid from its pid file
+func readConmonPidFile(pidFile string) (int, error) {
+	// Let's try reading the Conmon pid at the same time.
+	if pidFile != "" {
+		contents, err := ioutil.ReadFile(pidFile)
+		if err != nil {
+			return -1, err
+		}
+		// Convert it to an int
+		conmonPID, err := strconv.Atoi(string(contents))
+		if err != nil {
+			return -1, err
+		}
+		return conmonPID, nil
+	}
+	return 0, nil
+}
+
 // Create systemd unit name for cgroup scopes
 func createUnitName(prefix string, name string) string {
 	return fmt.Sprintf("%s-%s.scope", prefix, name)
@@ -160,14 +202,25 @@ func bindPorts(ports []ocicni.PortMapping) ([]*os.File, error) {
 	var files []*os.File
 	notifySCTP := false
 	for _, i := range ports {
+		// Ports without a host IP are reserved on all IPv4 and IPv6
+		// addresses at once
+		family := ""
+		if i.HostIP != "" {
+			family = "4"
+			if isIPv6(i.HostIP) {
+				family = "6"
+			}
+		}
+		hostAddr := net.JoinHostPort(i.HostIP, fmt.Sprintf("%d", i.HostPort))
+
 		switch i.Protocol {
 		case "udp":
-			addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", i.HostIP, i.HostPort))
+			addr, err := net.ResolveUDPAddr("udp"+family, hostAddr)
 			if err != nil {
 				return nil, errors.Wrapf(err, "cannot resolve the UDP address")
 			}
 
-			server, err := net.ListenUDP("udp", addr)
+			server, err := net.ListenUDP("udp"+family, addr)
 			if err != nil {
 				return nil, errors.Wrapf(err, "cannot listen on the UDP port")
 			}
@@ -178,12 +231,12 @@ func bindPorts(ports []ocicni.PortMapping) ([]*os.File, error) {
 			files = append(files, f)
 
 		case "tcp":
-			addr, err := net.ResolveTCPAddr("tcp4", fmt.Sprintf("%s:%d", i.HostIP, i.HostPort))
+			addr, err := net.ResolveTCPAddr("tcp"+family, hostAddr)
 			if err != nil {
 				return nil, errors.Wrapf(err, "cannot resolve the TCP address")
 			}
 
-			server, err := net.ListenTCP("tcp4", addr)
+			server, err := net.ListenTCP("tcp"+family, addr)
 			if err != nil {
 				return nil, errors.Wrapf(err, "cannot listen on the TCP port")
 			}
@@ -205,12 +258,12 @@ func bindPorts(ports []ocicni.PortMapping) ([]*os.File, error) {
 	return files, nil
 }
 
-// updateContainerStatus retrieves the current status of the container from the
+// UpdateContainerStatus retrieves the current status of the container from the
 // runtime. It updates the container's state but does not save it.
 // If useRunc is false, we will not directly hit runc to see the container's
 // status, but will instead only check for the existence of the conmon exit file
 // and update state to stopped if it exists.
-func (r *OCIRuntime) updateContainerStatus(ctr *Container, useRuntime bool) error {
+func (r *ConmonOCIRuntime) UpdateContainerStatus(ctr *Container, useRuntime bool) error {
 	exitFile := ctr.exitFilePath()
 
 	runtimeDir, err := util.GetRuntimeDir()
@@ -225,6 +278,11 @@ func (r *OCIRuntime) updateContainerStatus(ctr *Container, useRuntime bool) erro
 			return nil
 		}
 
+		// No exit file will ever be written without a conmon
+		if ctr.state.Unmonitored {
+			return nil
+		}
+
 		// Check for the exit file conmon makes
 		info, err := os.Stat(exitFile)
 		if err != nil {
@@ -237,7 +295,9 @@ func (r *OCIRuntime) updateContainerStatus(ctr *Container, useRuntime bool) erro
 		}
 
 		// Alright, it exists. Transition to Stopped state.
-		ctr.state.State = define.ContainerStateStopped
+		if err := ctr.setState(define.ContainerStateStopped); err != nil {
+			return err
+		}
 		ctr.state.PID = 0
 		ctr.state.ConmonPID = 0
 
@@ -273,8 +333,7 @@ func (r *OCIRuntime) updateContainerStatus(ctr *Container, useRuntime bool) erro
 			}
 			ctr.state.ExitCode = -1
 			ctr.state.FinishedTime = time.Now()
-			ctr.state.State = define.ContainerStateExited
-			return nil
+			return ctr.setState(define.ContainerStateExited)
 		}
 		return errors.Wrapf(err, "error getting container %s state. stderr/out: %s", ctr.ID(), out)
 	}
@@ -294,23 +353,34 @@ func (r *OCIRuntime) updateContainerStatus(ctr *Container, useRuntime bool) erro
 	}
 	ctr.state.PID = state.Pid
 
+	var newState define.ContainerStatus
 	switch state.Status {
 	case "created":
-		ctr.state.State = define.ContainerStateCreated
+		newState = define.ContainerStateCreated
 	case "paused":
-		ctr.state.State = define.ContainerStatePaused
+		newState = define.ContainerStatePaused
 	case "running":
-		ctr.state.State = define.ContainerStateRunning
+		newState = define.ContainerStateRunning
 	case "stopped":
-		ctr.state.State = define.ContainerStateStopped
+		newState = define.ContainerStateStopped
 	default:
 		return errors.Wrapf(define.ErrInternal, "unrecognized status returned by runtime for container %s: %s",
 			ctr.ID(), state.Status)
 	}
+	if err := ctr.setState(newState); err != nil {
+		return err
+	}
 
 	// Only grab exit status if we were not already stopped
 	// If we were, it should already be in the database
 	if ctr.state.State == define.ContainerStateStopped && oldState != define.ContainerStateStopped {
+		// Without a conmon, the exit code was not recorded anywhere
+		if ctr.state.Unmonitored {
+			ctr.state.ExitCode = -1
+			ctr.state.FinishedTime = time.Now()
+			return nil
+		}
+
 		var fi os.FileInfo
 		chWait := make(chan error)
 		defer close(chWait)
@@ -332,44 +402,96 @@ func (r *OCIRuntime) updateContainerStatus(ctr *Container, useRuntime bool) erro
 	return nil
 }
 
-// startContainer starts the given container
+// OCIContainerState is the state of a container as reported by the OCI
+// runtime. runc and crun report when the container was created in addition to
+// the fields required by the runtime spec.
+type OCIContainerState struct {
+	spec.State
+	Created time.Time `json:"created,omitempty"`
+}
+
+// ContainerState retrieves the state of the container with the given ID
+// directly from the OCI runtime. Unlike updateContainerStatus, it works for
+// containers libpod does not know about.
+func (r *ConmonOCIRuntime) ContainerState(id string) (*OCIContainerState, error) {
+	runtimeDir, err := util.GetRuntimeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(r.path, "state", id)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("XDG_RUNTIME_DIR=%s", runtimeDir))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "does not exist") {
+			return nil, errors.Wrapf(define.ErrNoSuchCtr, "no container with ID %s found in OCI runtime %s", id, r.name)
+		}
+		return nil, errors.Wrapf(err, "error getting container %s state from OCI runtime %s: %s", id, r.name, strings.TrimSpace(stderr.String()))
+	}
+
+	state := new(OCIContainerState)
+	if err := json.Unmarshal(out, state); err != nil {
+		return nil, errors.Wrapf(err, "error decoding state of container %s", id)
+	}
+	return state, nil
+}
+
+// StartContainer starts the given container
 // Sets time the container was started, but does not save it.
-func (r *OCIRuntime) startContainer(ctr *Container) error {
+func (r *ConmonOCIRuntime) StartContainer(ctr *Container) error {
 	// TODO: streams should probably *not* be our STDIN/OUT/ERR - redirect to buffers?
 	runtimeDir, err := util.GetRuntimeDir()
 	if err != nil {
 		return err
 	}
 	env := []string{fmt.Sprintf("XDG_RUNTIME_DIR=%s", runtimeDir)}
-	if notify, ok := os.LookupEnv("NOTIFY_SOCKET"); ok {
-		env = append(env, fmt.Sprintf("NOTIFY_SOCKET=%s", notify))
-	}
-	if err := utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, os.Stderr, env, r.path, "start", ctr.ID()); err != nil {
+	env = append(env, ctr.notifySocketEnv()...)
+	// Capture what the runtime prints, to classify failures
+	stderr := new(bytes.Buffer)
+	if err := utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, stderr, env, r.path, "start", ctr.ID()); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.Wrapf(getOCIRuntimeError(msg), "error starting container %s", ctr.ID())
+		}
 		return err
 	}
+	if stderr.Len() > 0 {
+		if _, err := stderr.WriteTo(os.Stderr); err != nil {
+			logrus.Debugf("Error writing OCI runtime output for container %s: %v", ctr.ID(), err)
+		}
+	}
 
 	ctr.state.StartedTime = time.Now()
 
 	return nil
 }
 
-// killContainer sends the given signal to the given container
-func (r *OCIRuntime) killContainer(ctr *Container, signal uint) error {
+// KillContainer sends the given signal to the given container
+// If all is set, the signal is sent to all processes in the container, not just
+// its init process.
+func (r *ConmonOCIRuntime) KillContainer(ctr *Container, signal uint, all bool) error {
 	logrus.Debugf("Sending signal %d to container %s", signal, ctr.ID())
 	runtimeDir, err := util.GetRuntimeDir()
 	if err != nil {
 		return err
 	}
 	env := []string{fmt.Sprintf("XDG_RUNTIME_DIR=%s", runtimeDir)}
-	if err := utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, os.Stderr, env, r.path, "kill", ctr.ID(), fmt.Sprintf("%d", signal)); err != nil {
+	args := []string{"kill"}
+	if all {
+		args = append(args, "--all")
+	}
+	args = append(args, ctr.ID(), fmt.Sprintf("%d", signal))
+	if err := utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, os.Stderr, env, r.path, args...); err != nil {
 		return errors.Wrapf(err, "error sending signal to container %s", ctr.ID())
 	}
 
 	return nil
 }
 
-// deleteContainer deletes a container from the OCI runtime
-func (r *OCIRuntime) deleteContainer(ctr *Container) error {
+// DeleteContainer deletes a container from the OCI runtime
+func (r *ConmonOCIRuntime) DeleteContainer(ctr *Container) error {
 	runtimeDir, err := util.GetRuntimeDir()
 	if err != nil {
 		return err
@@ -378,8 +500,26 @@ func (r *OCIRuntime) deleteContainer(ctr *Container) error {
 	return utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, os.Stderr, env, r.path, "delete", "--force", ctr.ID())
 }
 
-// pauseContainer pauses the given container
-func (r *OCIRuntime) pauseContainer(ctr *Container) error {
+// UpdateContainer changes the resource limits of the given container to the
+// given resources
+func (r *ConmonOCIRuntime) UpdateContainer(ctr *Container, resources *spec.LinuxResources) error {
+	runtimeDir, err := util.GetRuntimeDir()
+	if err != nil {
+		return err
+	}
+	env := []string{fmt.Sprintf("XDG_RUNTIME_DIR=%s", runtimeDir)}
+	resourcesJSON, err := json.Marshal(resources)
+	if err != nil {
+		return errors.Wrapf(err, "error encoding resources of container %s", ctr.ID())
+	}
+	if err := utils.ExecCmdWithStdStreams(bytes.NewReader(resourcesJSON), os.Stdout, os.Stderr, env, r.path, "update", "--resources", "-", ctr.ID()); err != nil {
+		return errors.Wrapf(err, "error updating resources of container %s", ctr.ID())
+	}
+	return nil
+}
+
+// PauseContainer pauses the given container
+func (r *ConmonOCIRuntime) PauseContainer(ctr *Container) error {
 	runtimeDir, err := util.GetRuntimeDir()
 	if err != nil {
 		return err
@@ -388,8 +528,8 @@ func (r *OCIRuntime) pauseContainer(ctr *Container) error {
 	return utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, os.Stderr, env, r.path, "pause", ctr.ID())
 }
 
-// unpauseContainer unpauses the given container
-func (r *OCIRuntime) unpauseContainer(ctr *Container) error {
+// UnpauseContainer unpauses the given container
+func (r *ConmonOCIRuntime) UnpauseContainer(ctr *Container) error {
 	runtimeDir, err := util.GetRuntimeDir()
 	if err != nil {
 		return err
@@ -398,8 +538,8 @@ func (r *OCIRuntime) unpauseContainer(ctr *Container) error {
 	return utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, os.Stderr, env, r.path, "resume", ctr.ID())
 }
 
-// checkpointContainer checkpoints the given container
-func (r *OCIRuntime) checkpointContainer(ctr *Container, options ContainerCheckpointOptions) error {
+// CheckpointContainer checkpoints the given container
+func (r *ConmonOCIRuntime) CheckpointContainer(ctr *Container, options ContainerCheckpointOptions) error {
 	if err := label.SetSocketLabel(ctr.ProcessLabel()); err != nil {
 		return err
 	}
@@ -424,16 +564,3 @@ func (r *OCIRuntime) checkpointContainer(ctr *Container, options ContainerCheckp
 	args = append(args, ctr.ID())
 	return utils.ExecCmdWithStdStreams(os.Stdin, os.Stdout, os.Stderr, nil, r.path, args...)
 }
-
-func (r *OCIRuntime) featureCheckCheckpointing() bool {
-	// Check if the runtime implements checkpointing. Currently only
-	// runc's checkpoint/restore implementation is supported.
-	cmd := exec.Command(r.path, "checkpoint", "-h")
-	if err := cmd.Start(); err != nil {
-		return false
-	}
-	if err := cmd.Wait(); err == nil {
-		return true
-	}
-	return false
-}
@@ -138,6 +138,39 @@ func (c *Container) attachToExec(streams *AttachStreams, keys string, resize <-c
 	return readStdio(streams, receiveStdoutError, stdinDone)
 }
 
+// Attach to a detached exec session of the given container.
+// Unlike attachToExec, the session is already running, so only output produced
+// after attaching is received.
+func (c *Container) attachToExecSession(streams *AttachStreams, keys string, resize <-chan remotecommand.TerminalSize, sessionID string) error {
+	if !streams.AttachOutput && !streams.AttachError && !streams.AttachInput {
+		return errors.Wrapf(define.ErrInvalidArg, "must provide at least one stream to attach to")
+	}
+
+	detachKeys, err := processDetachKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	logrus.Debugf("Attaching to container %s detached exec session %s", c.ID(), sessionID)
+
+	registerResizeFunc(resize, c.execBundlePath(sessionID))
+
+	socketPath := buildSocketPath(c.execAttachSocketPath(sessionID))
+
+	conn, err := net.DialUnix("unixpacket", nil, &net.UnixAddr{Name: socketPath, Net: "unixpacket"})
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to exec session's attach socket: %v", socketPath)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logrus.Errorf("unable to close socket: %q", err)
+		}
+	}()
+
+	receiveStdoutError, stdinDone := setupStdioChannels(streams, conn, detachKeys)
+	return readStdio(streams, receiveStdoutError, stdinDone)
+}
+
 func processDetachKeys(keys string) ([]byte, error) {
 	// Check the validity of the provided keys first
 	if len(keys) == 0 {
@@ -152,21 +185,29 @@ func processDetachKeys(keys string) ([]byte, error) {
 
 func registerResizeFunc(resize <-chan remotecommand.TerminalSize, bundlePath string) {
 	kubeutils.HandleResizing(resize, func(size remotecommand.TerminalSize) {
-		controlPath := filepath.Join(bundlePath, "ctl")
-		controlFile, err := os.OpenFile(controlPath, unix.O_WRONLY, 0)
-		if err != nil {
-			logrus.Debugf("Could not open ctl file: %v", err)
-			return
-		}
-		defer controlFile.Close()
-
 		logrus.Debugf("Received a resize event: %+v", size)
-		if _, err = fmt.Fprintf(controlFile, "%d %d %d\n", 1, size.Height, size.Width); err != nil {
-			logrus.Warnf("Failed to write to control file to resize terminal: %v", err)
+		if err := resizeTerminal(bundlePath, size); err != nil {
+			logrus.Warnf("Failed to resize terminal: %v", err)
 		}
 	})
 }
 
+// resizeTerminal asks the conmon of the bundle to resize its terminal,
+// through its control file
+func resizeTerminal(bundlePath string, size remotecommand.TerminalSize) error {
+	controlPath := filepath.Join(bundlePath, "ctl")
+	controlFile, err := os.OpenFile(controlPath, unix.O_WRONLY, 0)
+	if err != nil {
+		return errors.Wrapf(err, "could not open ctl file %s", controlPath)
+	}
+	defer controlFile.Close()
+
+	if _, err = fmt.Fprintf(controlFile, "%d %d %d\n", 1, size.Height, size.Width); err != nil {
+		return errors.Wrapf(err, "failed to write to control file %s", controlPath)
+	}
+	return nil
+}
+
 func buildSocketPath(socketPath string) string {
 	maxUnixLength := unixPathLength()
 	if maxUnixLength < len(socketPath) {
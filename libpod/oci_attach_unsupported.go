@@ -16,3 +16,11 @@ func (c *Container) attach(streams *AttachStreams, keys string, resize <-chan re
 func (c *Container) attachToExec(streams *AttachStreams, keys string, resize <-chan remotecommand.TerminalSize, sessionID string, startFd *os.File, attachFd *os.File) error {
 	return define.ErrNotImplemented
 }
+
+func (c *Container) attachToExecSession(streams *AttachStreams, keys string, resize <-chan remotecommand.TerminalSize, sessionID string) error {
+	return define.ErrNotImplemented
+}
+
+func resizeTerminal(bundlePath string, size remotecommand.TerminalSize) error {
+	return define.ErrNotImplemented
+}
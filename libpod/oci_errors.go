@@ -0,0 +1,55 @@
+package libpod
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+)
+
+// ociErrorSignature matches a class of OCI runtime failure messages to the
+// error they are wrapped in, with a hint on how to fix them
+type ociErrorSignature struct {
+	match *regexp.Regexp
+	cause error
+	hint  string
+}
+
+// ociErrorSignatures are checked in order, so more specific signatures come
+// first: a mount failure also reads as permission denied or a missing file.
+var ociErrorSignatures = []ociErrorSignature{
+	{
+		match: regexp.MustCompile(`cgroup`),
+		cause: define.ErrOCIRuntimeCgroup,
+		hint:  "check that the cgroup controllers the container needs are enabled, and delegated to the user for rootless containers, and that cgroup_manager in libpod.conf matches the host",
+	},
+	{
+		match: regexp.MustCompile(`mount.*(permission denied|operation not permitted|no such file or directory)`),
+		cause: define.ErrOCIRuntimeMount,
+		hint:  "check that the source of each volume exists and is accessible to the container's user; with SELinux, relabel it using the z or Z volume option",
+	},
+	{
+		match: regexp.MustCompile(`permission denied|operation not permitted`),
+		cause: define.ErrOCIRuntimePermissionDenied,
+		hint:  "check that the command is executable in the container",
+	},
+	{
+		match: regexp.MustCompile(`executable file not found in|no such file or directory`),
+		cause: define.ErrOCIRuntimeNotFound,
+		hint:  "check that the command exists in the image, and is in its $PATH if not given as a path",
+	},
+}
+
+// getOCIRuntimeError classifies an error message printed by the OCI runtime,
+// wrapping it in the matching define error with a remediation hint
+func getOCIRuntimeError(runtimeMsg string) error {
+	msg := strings.Trim(runtimeMsg, "\n")
+	r := strings.ToLower(msg)
+	for _, sig := range ociErrorSignatures {
+		if sig.match.MatchString(r) {
+			return errors.Wrapf(sig.cause, "%s (%s)", msg, sig.hint)
+		}
+	}
+	return errors.Wrapf(define.ErrOCIRuntime, "%s", msg)
+}
@@ -0,0 +1,27 @@
+package libpod
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOCIRuntimeError(t *testing.T) {
+	for _, tc := range []struct {
+		msg   string
+		cause error
+	}{
+		{`container_linux.go:345: starting container process caused "exec: \"foo\": executable file not found in $PATH"`, define.ErrOCIRuntimeNotFound},
+		{`container_linux.go:345: starting container process caused "exec: \"/etc\": permission denied"`, define.ErrOCIRuntimePermissionDenied},
+		{`container_linux.go:345: starting container process caused "process_linux.go:430: container init caused \"rootfs_linux.go:58: mounting \\\"/srv/data\\\" to rootfs \\\"/var/lib/containers\\\" caused \\\"permission denied\\\"\""`, define.ErrOCIRuntimeMount},
+		{`container_linux.go:345: starting container process caused "process_linux.go:297: applying cgroup configuration for process caused \"open /sys/fs/cgroup/pids/tasks: permission denied\""`, define.ErrOCIRuntimeCgroup},
+		{"something unexpected happened\n", define.ErrOCIRuntime},
+	} {
+		err := getOCIRuntimeError(tc.msg)
+		assert.Equal(t, tc.cause, errors.Cause(err), tc.msg)
+		assert.Contains(t, err.Error(), strings.TrimSpace(tc.msg))
+	}
+}
@@ -0,0 +1,144 @@
+package libpod
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/cgroups"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Optional features of OCI runtimes, as reported by podman info
+const (
+	// OCIFeatureCheckpoint is the ability to checkpoint and restore
+	// containers with CRIU
+	OCIFeatureCheckpoint = "checkpoint"
+	// OCIFeatureCgroupsV2 is the ability to run containers on hosts using
+	// the cgroups v2 unified hierarchy
+	OCIFeatureCgroupsV2 = "cgroupv2"
+	// OCIFeatureNoPivot is the ability to set up the root filesystem of
+	// containers without pivot_root
+	OCIFeatureNoPivot = "no-pivot"
+	// OCIFeatureKVM is the runtime running containers in KVM virtual
+	// machines
+	OCIFeatureKVM = "kvm"
+)
+
+// The first runc release supporting cgroups v2
+const runcCgroupsV2MinRC = 91
+
+var runcVersionRegexp = regexp.MustCompile(`runc version (\d+)\.(\d+)\.(\d+)(?:-rc(\d+))?`)
+
+// ociRuntimeFeatures are the optional features supported by an OCI runtime
+type ociRuntimeFeatures struct {
+	checkpoint bool
+	cgroupsV2  bool
+	noPivot    bool
+	kvm        bool
+}
+
+// names returns the names of the supported features
+func (f *ociRuntimeFeatures) names() []string {
+	names := []string{}
+	if f.checkpoint {
+		names = append(names, OCIFeatureCheckpoint)
+	}
+	if f.cgroupsV2 {
+		names = append(names, OCIFeatureCgroupsV2)
+	}
+	if f.noPivot {
+		names = append(names, OCIFeatureNoPivot)
+	}
+	if f.kvm {
+		names = append(names, OCIFeatureKVM)
+	}
+	return names
+}
+
+// features returns the optional features supported by the runtime. The runtime
+// is only probed the first time, the result is cached for the lifetime of the
+// libpod runtime.
+func (r *ConmonOCIRuntime) features() *ociRuntimeFeatures {
+	r.featuresOnce.Do(func() {
+		r.supportedFeatures = r.probeFeatures()
+		logrus.Debugf("OCI runtime %s supports features %v", r.name, r.supportedFeatures.names())
+	})
+	return r.supportedFeatures
+}
+
+// Features returns the names of the optional features the runtime supports
+func (r *ConmonOCIRuntime) Features() []string {
+	return r.features().names()
+}
+
+// probeFeatures runs the runtime to find the optional features it supports
+func (r *ConmonOCIRuntime) probeFeatures() *ociRuntimeFeatures {
+	features := new(ociRuntimeFeatures)
+
+	version, err := exec.Command(r.path, "--version").Output()
+	if err != nil {
+		logrus.Debugf("Error retrieving version of OCI runtime %s: %v", r.name, err)
+	}
+	features.cgroupsV2 = versionSupportsCgroupsV2(string(version))
+	features.kvm = strings.Contains(strings.ToLower(string(version)), "kata")
+
+	// The runtime only accepts --no-pivot if it implements it
+	createHelp, err := exec.Command(r.path, "create", "--help").CombinedOutput()
+	if err != nil {
+		logrus.Debugf("Error retrieving create options of OCI runtime %s: %v", r.name, err)
+	}
+	features.noPivot = strings.Contains(string(createHelp), "--no-pivot")
+
+	// Check if the runtime implements checkpointing. Currently only
+	// runc's checkpoint/restore implementation is supported.
+	features.checkpoint = exec.Command(r.path, "checkpoint", "-h").Run() == nil
+
+	return features
+}
+
+// versionSupportsCgroupsV2 returns whether the runtime with the given version
+// output supports cgroups v2. Only runc releases older than 1.0.0-rc91 are
+// known not to, other runtimes are assumed to.
+func versionSupportsCgroupsV2(version string) bool {
+	matches := runcVersionRegexp.FindStringSubmatch(version)
+	if matches == nil {
+		return true
+	}
+	major, _ := strconv.Atoi(matches[1])
+	if major != 1 || matches[4] == "" {
+		return major >= 1
+	}
+	rc, _ := strconv.Atoi(matches[4])
+	return rc >= runcCgroupsV2MinRC
+}
+
+// validateOCIRuntimeFeatures checks that the runtime supports the features
+// required by the host and the libpod configuration, so containers it cannot
+// run are refused when they are created rather than failing when they are
+// started
+func validateOCIRuntimeFeatures(ociRuntime OCIRuntime, noPivot bool) error {
+	cgroupsV2, err := cgroups.IsCgroup2UnifiedMode()
+	if err != nil {
+		return errors.Wrapf(err, "error determining cgroups version of the host")
+	}
+	if cgroupsV2 && !hasOCIFeature(ociRuntime, OCIFeatureCgroupsV2) {
+		return errors.Wrapf(define.ErrInvalidArg, "OCI runtime %s does not support cgroups v2, which the host uses", ociRuntime.Name())
+	}
+
+	if noPivot && !hasOCIFeature(ociRuntime, OCIFeatureNoPivot) {
+		return errors.Wrapf(define.ErrInvalidArg, "OCI runtime %s does not support running containers without pivot_root", ociRuntime.Name())
+	}
+
+	if hasOCIFeature(ociRuntime, OCIFeatureKVM) {
+		if _, err := os.Stat("/dev/kvm"); err != nil {
+			return errors.Wrapf(define.ErrInvalidArg, "OCI runtime %s runs containers in virtual machines, which requires /dev/kvm: %v", ociRuntime.Name(), err)
+		}
+	}
+
+	return nil
+}
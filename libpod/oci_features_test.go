@@ -0,0 +1,23 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionSupportsCgroupsV2(t *testing.T) {
+	assert.False(t, versionSupportsCgroupsV2("runc version 1.0.0-rc10\nspec: 1.0.1-dev"))
+	assert.False(t, versionSupportsCgroupsV2("runc version 0.1.1"))
+	assert.True(t, versionSupportsCgroupsV2("runc version 1.0.0-rc92\nspec: 1.0.2-dev"))
+	assert.True(t, versionSupportsCgroupsV2("runc version 1.0.0"))
+	assert.True(t, versionSupportsCgroupsV2("crun version 0.10.6\nspec: 1.0.0\n+SYSTEMD +SELINUX +APPARMOR +CAP +SECCOMP +EBPF +YAJL"))
+	// Runtimes that cannot be identified are not refused
+	assert.True(t, versionSupportsCgroupsV2(""))
+}
+
+func TestOCIRuntimeFeatureNames(t *testing.T) {
+	features := &ociRuntimeFeatures{checkpoint: true, noPivot: true}
+	assert.Equal(t, []string{OCIFeatureCheckpoint, OCIFeatureNoPivot}, features.names())
+	assert.Empty(t, (&ociRuntimeFeatures{}).names())
+}
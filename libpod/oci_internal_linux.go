@@ -10,19 +10,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
-	"strconv"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/containers/libpod/libpod/define"
-	"github.com/containers/libpod/pkg/cgroups"
 	"github.com/containers/libpod/pkg/errorhandling"
 	"github.com/containers/libpod/pkg/lookup"
 	"github.com/containers/libpod/pkg/util"
-	"github.com/containers/libpod/utils"
 	"github.com/coreos/go-systemd/activation"
 	spec "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/opencontainers/selinux/go-selinux"
@@ -33,7 +28,7 @@ import (
 )
 
 // createOCIContainer generates this container's main conmon instance and prepares it for starting
-func (r *OCIRuntime) createOCIContainer(ctr *Container, restoreOptions *ContainerCheckpointOptions) (err error) {
+func (r *ConmonOCIRuntime) createOCIContainer(ctr *Container, restoreOptions *ContainerCheckpointOptions) (err error) {
 	var stderrBuf bytes.Buffer
 
 	runtimeDir, err := util.GetRuntimeDir()
@@ -58,7 +53,22 @@ func (r *OCIRuntime) createOCIContainer(ctr *Container, restoreOptions *Containe
 	if logrus.GetLevel() != logrus.DebugLevel && r.supportsJSON {
 		ociLog = filepath.Join(ctr.state.RunDir, "oci-log")
 	}
-	args := r.sharedConmonArgs(ctr, ctr.ID(), ctr.bundlePath(), filepath.Join(ctr.state.RunDir, "pidfile"), ctr.LogPath(), r.exitsDir, ociLog)
+	logPath := ctr.LogPath()
+	if ctr.usesLogger() {
+		var logger *exec.Cmd
+		if logger, err = ctr.startLogger(); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				if err2 := logger.Process.Kill(); err2 != nil {
+					logrus.Errorf("Error stopping %s logger of container %s: %v", ctr.LogDriver(), ctr.ID(), err2)
+				}
+			}
+		}()
+		logPath = ctr.loggerFIFOPath()
+	}
+	args := r.sharedConmonArgs(ctr, ctr.ID(), ctr.bundlePath(), filepath.Join(ctr.state.RunDir, "pidfile"), logPath, ctr.exitsDir(), ociLog)
 
 	if ctr.config.Spec.Process.Terminal {
 		args = append(args, "-t")
@@ -105,9 +115,21 @@ func (r *OCIRuntime) createOCIContainer(ctr *Container, restoreOptions *Containe
 	if ctr.config.Spec.Process.Terminal {
 		cmd.Stderr = &stderrBuf
 	}
+	if debugLogPath := ctr.ConmonDebugLogPath(); debugLogPath != "" {
+		// Pass the file directly, rather than copying from a pipe, as
+		// conmon outlives this process
+		debugLog, err := os.OpenFile(debugLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return errors.Wrapf(err, "error opening conmon debug log for container %s", ctr.ID())
+		}
+		defer errorhandling.CloseQuiet(debugLog)
+		fmt.Fprintf(debugLog, "%s: starting conmon for container %s\n", time.Now().Format(time.RFC3339Nano), ctr.ID())
+		cmd.Stdout = debugLog
+		cmd.Stderr = debugLog
+	}
 
 	// 0, 1 and 2 are stdin, stdout and stderr
-	conmonEnv, envFiles, err := r.configureConmonEnv(runtimeDir)
+	conmonEnv, envFiles, err := r.configureConmonEnv(ctr, runtimeDir)
 	if err != nil {
 		return err
 	}
@@ -117,7 +139,7 @@ func (r *OCIRuntime) createOCIContainer(ctr *Container, restoreOptions *Containe
 	cmd.ExtraFiles = append(cmd.ExtraFiles, childSyncPipe, childStartPipe)
 	cmd.ExtraFiles = append(cmd.ExtraFiles, envFiles...)
 
-	if r.reservePorts && !ctr.config.NetMode.IsSlirp4netns() {
+	if r.reservePorts && !ctr.config.NetMode.IsSlirp4netns() && !ctr.config.NetMode.IsPasta() {
 		ports, err := bindPorts(ctr.config.PortMappings)
 		if err != nil {
 			return err
@@ -156,12 +178,13 @@ func (r *OCIRuntime) createOCIContainer(ctr *Container, restoreOptions *Containe
 
 	pid, err := readConmonPipeData(parentSyncPipe, ociLog)
 	if err != nil {
-		if err2 := r.deleteContainer(ctr); err2 != nil {
+		if err2 := r.DeleteContainer(ctr); err2 != nil {
 			logrus.Errorf("Error removing container %s from runtime after creation failed", ctr.ID())
 		}
 		return err
 	}
 	ctr.state.PID = pid
+	ctr.state.Unmonitored = false
 
 	conmonPID, err := readConmonPidFile(ctr.config.ConmonPidFile)
 	if err != nil {
@@ -232,7 +255,7 @@ func prepareProcessExec(c *Container, cmd, env []string, tty bool, cwd, user, se
 
 // configureConmonEnv gets the environment values to add to conmon's exec struct
 // TODO this may want to be less hardcoded/more configurable in the future
-func (r *OCIRuntime) configureConmonEnv(runtimeDir string) ([]string, []*os.File, error) {
+func (r *ConmonOCIRuntime) configureConmonEnv(ctr *Container, runtimeDir string) ([]string, []*os.File, error) {
 	env := make([]string, 0, 6)
 	env = append(env, fmt.Sprintf("XDG_RUNTIME_DIR=%s", runtimeDir))
 	env = append(env, fmt.Sprintf("_CONTAINERS_USERNS_CONFIGURED=%s", os.Getenv("_CONTAINERS_USERNS_CONFIGURED")))
@@ -244,9 +267,7 @@ func (r *OCIRuntime) configureConmonEnv(runtimeDir string) ([]string, []*os.File
 	env = append(env, fmt.Sprintf("HOME=%s", home))
 
 	extraFiles := make([]*os.File, 0)
-	if notify, ok := os.LookupEnv("NOTIFY_SOCKET"); ok {
-		env = append(env, fmt.Sprintf("NOTIFY_SOCKET=%s", notify))
-	}
+	env = append(env, ctr.notifySocketEnv()...)
 	if !r.sdNotify {
 		if listenfds, ok := os.LookupEnv("LISTEN_FDS"); ok {
 			env = append(env, fmt.Sprintf("LISTEN_FDS=%s", listenfds), "LISTEN_PID=1")
@@ -260,7 +281,7 @@ func (r *OCIRuntime) configureConmonEnv(runtimeDir string) ([]string, []*os.File
 }
 
 // sharedConmonArgs takes common arguments for exec and create/restore and formats them for the conmon CLI
-func (r *OCIRuntime) sharedConmonArgs(ctr *Container, cuuid, bundlePath, pidPath, logPath, exitDir, ociLogPath string) []string {
+func (r *ConmonOCIRuntime) sharedConmonArgs(ctr *Container, cuuid, bundlePath, pidPath, logPath, exitDir, ociLogPath string) []string {
 	// set the conmon API version to be able to use the correct sync struct keys
 	args := []string{"--api-version", "1"}
 	if r.cgroupManager == SystemdCgroupsManager {
@@ -276,10 +297,11 @@ func (r *OCIRuntime) sharedConmonArgs(ctr *Container, cuuid, bundlePath, pidPath
 	switch ctr.LogDriver() {
 	case JournaldLogging:
 		logDriver = JournaldLogging
-	case JSONLogging:
-		fallthrough
+	case JSONLogging, SyslogLogging, FluentdLogging:
+		// Conmon writes k8s-file formatted logs to the logger
+		logDriver = fmt.Sprintf("%s:%s", KubernetesLogging, logPath)
 	default: //nolint-stylecheck
-		// No case here should happen except JSONLogging, but keep this here in case the options are extended
+		// No case here should happen, but keep this here in case the options are extended
 		logrus.Errorf("%s logging specified but not supported. Choosing k8s-file logging instead", ctr.LogDriver())
 		fallthrough
 	case "":
@@ -291,16 +313,32 @@ func (r *OCIRuntime) sharedConmonArgs(ctr *Container, cuuid, bundlePath, pidPath
 	}
 
 	args = append(args, "-l", logDriver)
+	if logDriver == JournaldLogging {
+		// Name and tag the entries of the container in the journal
+		args = append(args, "-n", ctr.Name())
+		if tag := ctr.LogTag(); tag != "" {
+			args = append(args, "--log-tag", tag)
+		}
+	}
 	args = append(args, "--exit-dir", exitDir)
-	args = append(args, "--socket-dir-path", r.socketsDir)
-	if r.logSizeMax >= 0 {
+	args = append(args, "--socket-dir-path", ctr.socketsDir())
+	// Conmon must not replace the FIFO leading to the logger
+	if r.logSizeMax >= 0 && !ctr.usesLogger() {
 		args = append(args, "--log-size-max", fmt.Sprintf("%v", r.logSizeMax))
 	}
 
 	logLevel := logrus.GetLevel()
+	if ctr.config.ConmonLogLevel != "" {
+		level, err := logrus.ParseLevel(ctr.config.ConmonLogLevel)
+		if err != nil {
+			logrus.Errorf("Invalid conmon log level %q for container %s, using %s", ctr.config.ConmonLogLevel, ctr.ID(), logLevel.String())
+		} else {
+			logLevel = level
+		}
+	}
 	args = append(args, "--log-level", logLevel.String())
 
-	if logLevel == logrus.DebugLevel {
+	if logLevel == logrus.DebugLevel || ctr.config.ConmonSyslog {
 		logrus.Debugf("%s messages will be logged to syslog", r.conmonPath)
 		args = append(args, "--syslog")
 	}
@@ -354,33 +392,9 @@ func startCommandGivenSelinux(cmd *exec.Cmd) error {
 
 // moveConmonToCgroupAndSignal gets a container's cgroupParent and moves the conmon process to that cgroup
 // it then signals for conmon to start by sending nonse data down the start fd
-func (r *OCIRuntime) moveConmonToCgroupAndSignal(ctr *Container, cmd *exec.Cmd, startFd *os.File, uuid string) error {
-	cgroupParent := ctr.CgroupParent()
-	if r.cgroupManager == SystemdCgroupsManager {
-		unitName := createUnitName("libpod-conmon", ctr.ID())
-
-		realCgroupParent := cgroupParent
-		splitParent := strings.Split(cgroupParent, "/")
-		if strings.HasSuffix(cgroupParent, ".slice") && len(splitParent) > 1 {
-			realCgroupParent = splitParent[len(splitParent)-1]
-		}
-
-		logrus.Infof("Running conmon under slice %s and unitName %s", realCgroupParent, unitName)
-		if err := utils.RunUnderSystemdScope(cmd.Process.Pid, realCgroupParent, unitName); err != nil {
-			logrus.Warnf("Failed to add conmon to systemd sandbox cgroup: %v", err)
-		}
-	} else {
-		cgroupPath := filepath.Join(ctr.config.CgroupParent, "conmon")
-		control, err := cgroups.New(cgroupPath, &spec.LinuxResources{})
-		if err != nil {
-			logrus.Warnf("Failed to add conmon to cgroupfs sandbox cgroup: %v", err)
-		} else {
-			// we need to remove this defer and delete the cgroup once conmon exits
-			// maybe need a conmon monitor?
-			if err := control.AddPid(cmd.Process.Pid); err != nil {
-				logrus.Warnf("Failed to add conmon to cgroupfs sandbox cgroup: %v", err)
-			}
-		}
+func (r *ConmonOCIRuntime) moveConmonToCgroupAndSignal(ctr *Container, cmd *exec.Cmd, startFd *os.File, uuid string) error {
+	if err := ctr.runtime.getCgroupManager().moveConmon(ctr, cmd.Process.Pid); err != nil {
+		logrus.Warnf("Failed to add conmon to %s sandbox cgroup: %v", ctr.runtime.getCgroupManager().name(), err)
 	}
 
 	/* We set the cgroup, now the child can start creating children */
@@ -399,24 +413,6 @@ func newPipe() (parent *os.File, child *os.File, err error) {
 	return os.NewFile(uintptr(fds[1]), "parent"), os.NewFile(uintptr(fds[0]), "child"), nil
 }
 
-// readConmonPidFile attempts to read conmon's pid from its pid file
-func readConmonPidFile(pidFile string) (int, error) {
-	// Let's try reading the Conmon pid at the same time.
-	if pidFile != "" {
-		contents, err := ioutil.ReadFile(pidFile)
-		if err != nil {
-			return -1, err
-		}
-		// Convert it to an int
-		conmonPID, err := strconv.Atoi(string(contents))
-		if err != nil {
-			return -1, err
-		}
-		return conmonPID, nil
-	}
-	return 0, nil
-}
-
 // readConmonPipeData attempts to read a syncInfo struct from the pipe
 func readConmonPipeData(pipe *os.File, ociLog string) (int, error) {
 	// syncInfo is used to return data from monitor process to daemon
@@ -484,17 +480,6 @@ func readConmonPipeData(pipe *os.File, ociLog string) (int, error) {
 	return data, nil
 }
 
-func getOCIRuntimeError(runtimeMsg string) error {
-	r := strings.ToLower(runtimeMsg)
-	if match, _ := regexp.MatchString(".*permission denied.*|.*operation not permitted.*", r); match {
-		return errors.Wrapf(define.ErrOCIRuntimePermissionDenied, "%s", strings.Trim(runtimeMsg, "\n"))
-	}
-	if match, _ := regexp.MatchString(".*executable file not found in.*|.*no such file or directory.*", r); match {
-		return errors.Wrapf(define.ErrOCIRuntimeNotFound, "%s", strings.Trim(runtimeMsg, "\n"))
-	}
-	return errors.Wrapf(define.ErrOCIRuntime, "%s", strings.Trim(runtimeMsg, "\n"))
-}
-
 // writeConmonPipeData writes nonse data to a pipe
 func writeConmonPipeData(pipe *os.File) error {
 	someData := []byte{0}
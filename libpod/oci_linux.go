@@ -51,7 +51,7 @@ func makeAccessible(path string, uid, gid int) error {
 // CreateContainer creates a container in the OCI runtime
 // TODO terminal support for container
 // Presently just ignoring conmon opts related to it
-func (r *OCIRuntime) createContainer(ctr *Container, restoreOptions *ContainerCheckpointOptions) (err error) {
+func (r *ConmonOCIRuntime) CreateContainer(ctr *Container, restoreOptions *ContainerCheckpointOptions) (err error) {
 	if len(ctr.config.IDMappings.UIDMap) != 0 || len(ctr.config.IDMappings.GIDMap) != 0 {
 		for _, i := range []string{ctr.state.RunDir, ctr.runtime.config.TmpDir, ctr.config.StaticDir, ctr.state.Mountpoint, ctr.runtime.config.VolumePath} {
 			if err := makeAccessible(i, ctr.RootUID(), ctr.RootGID()); err != nil {
@@ -132,25 +132,21 @@ func dpkgVersion(path string) string {
 	return strings.Trim(output, "\n")
 }
 
-func (r *OCIRuntime) pathPackage() string {
-	if out := rpmVersion(r.path); out != unknownPackage {
+// packageVersion returns the package providing the file at the given path
+func packageVersion(path string) string {
+	if out := rpmVersion(path); out != unknownPackage {
 		return out
 	}
-	return dpkgVersion(r.path)
+	return dpkgVersion(path)
 }
 
-func (r *OCIRuntime) conmonPackage() string {
-	if out := rpmVersion(r.conmonPath); out != unknownPackage {
-		return out
-	}
-	return dpkgVersion(r.conmonPath)
-}
-
-// execContainer executes a command in a running container
-// TODO: Add --detach support
+// ExecContainer executes a command in a running container
 // TODO: Convert to use conmon
 // TODO: add --pid-file and use that to generate exec session tracking
-func (r *OCIRuntime) execContainer(c *Container, cmd, capAdd, env []string, tty bool, cwd, user, sessionID string, streams *AttachStreams, preserveFDs int, resize chan remotecommand.TerminalSize, detachKeys string) (int, chan error, error) {
+// If detach is set, no streams are attached and conmon keeps monitoring the
+// session in the background, writing its PID to the session's conmon PID file.
+// The returned channel is nil for detached sessions.
+func (r *ConmonOCIRuntime) ExecContainer(c *Container, cmd, capAdd, env []string, tty bool, cwd, user, sessionID string, streams *AttachStreams, preserveFDs int, resize chan remotecommand.TerminalSize, detachKeys string, detach bool) (int, chan error, error) {
 	if len(cmd) == 0 {
 		return -1, nil, errors.Wrapf(define.ErrInvalidArg, "must provide a command to execute")
 	}
@@ -238,8 +234,11 @@ func (r *OCIRuntime) execContainer(c *Container, cmd, capAdd, env []string, tty
 
 	// Append container ID and command
 	args = append(args, "-e")
-	// TODO make this optional when we can detach
-	args = append(args, "--exec-attach")
+	if detach {
+		args = append(args, "--conmon-pidfile", c.execConmonPidPath(sessionID))
+	} else {
+		args = append(args, "--exec-attach")
+	}
 	args = append(args, "--exec-process-spec", processFile.Name())
 
 	logrus.WithFields(logrus.Fields{
@@ -247,17 +246,19 @@ func (r *OCIRuntime) execContainer(c *Container, cmd, capAdd, env []string, tty
 	}).Debugf("running conmon: %s", r.conmonPath)
 	execCmd := exec.Command(r.conmonPath, args...)
 
-	if streams.AttachInput {
-		execCmd.Stdin = streams.InputStream
-	}
-	if streams.AttachOutput {
-		execCmd.Stdout = streams.OutputStream
-	}
-	if streams.AttachError {
-		execCmd.Stderr = streams.ErrorStream
+	if !detach {
+		if streams.AttachInput {
+			execCmd.Stdin = streams.InputStream
+		}
+		if streams.AttachOutput {
+			execCmd.Stdout = streams.OutputStream
+		}
+		if streams.AttachError {
+			execCmd.Stderr = streams.ErrorStream
+		}
 	}
 
-	conmonEnv, extraFiles, err := r.configureConmonEnv(runtimeDir)
+	conmonEnv, extraFiles, err := r.configureConmonEnv(c, runtimeDir)
 	if err != nil {
 		return -1, nil, err
 	}
@@ -305,7 +306,16 @@ func (r *OCIRuntime) execContainer(c *Container, cmd, capAdd, env []string, tty
 		}
 	}
 
-	// TODO Only create if !detach
+	if detach {
+		// Wait for conmon to fork into the background, it runs the
+		// session without waiting for an attach
+		if err := execCmd.Wait(); err != nil {
+			return -1, nil, errors.Wrapf(err, "error running conmon for exec session %s", sessionID)
+		}
+		pid, err := readConmonPipeData(parentSyncPipe, ociLog)
+		return pid, nil, err
+	}
+
 	// Attach to the container before starting it
 	attachChan := make(chan error)
 	go func() {
@@ -331,8 +341,7 @@ func waitContainerStop(ctr *Container, timeout time.Duration) error {
 				return
 			default:
 				// Check if the process is still around
-				err := unix.Kill(ctr.state.PID, 0)
-				if err == unix.ESRCH {
+				if containerProcessExited(ctr) {
 					close(done)
 					return
 				}
@@ -350,6 +359,25 @@ func waitContainerStop(ctr *Container, timeout time.Duration) error {
 	}
 }
 
+// containerProcessExited returns whether the process of the container has
+// exited. VM-based runtimes run the process inside a virtual machine, the PID
+// on the host belongs to their shim, so the runtime is asked for the state of
+// the container instead.
+func containerProcessExited(ctr *Container) bool {
+	if hasOCIFeature(ctr.ociRuntime, OCIFeatureKVM) {
+		state, err := ctr.ociRuntime.ContainerState(ctr.ID())
+		if err != nil {
+			if errors.Cause(err) == define.ErrNoSuchCtr {
+				return true
+			}
+			logrus.Debugf("Error retrieving state of container %s from OCI runtime: %v", ctr.ID(), err)
+		} else if state.Status == "stopped" {
+			return true
+		}
+	}
+	return unix.Kill(ctr.state.PID, 0) == unix.ESRCH
+}
+
 // Wait for a set of given PIDs to stop
 func waitPidsStop(pids []int, timeout time.Duration) error {
 	done := make(chan struct{})
@@ -384,13 +412,16 @@ func waitPidsStop(pids []int, timeout time.Duration) error {
 	}
 }
 
-// stopContainer stops a container, first using its given stop signal (or
+// StopContainer stops a container, first using its given stop signal (or
 // SIGTERM if no signal was specified), then using SIGKILL
+// If the container has a stop signal escalation chain, its signals are sent
+// in order instead of the stop signal, each followed by the step's timeout.
+// The progress is recorded in the container's state.
 // Timeout is given in seconds. If timeout is 0, the container will be
 // immediately kill with SIGKILL
 // Does not set finished time for container, assumes you will run updateStatus
 // after to pull the exit code
-func (r *OCIRuntime) stopContainer(ctr *Container, timeout uint) error {
+func (r *ConmonOCIRuntime) StopContainer(ctr *Container, timeout uint) error {
 	logrus.Debugf("Stopping container %s (PID %d)", ctr.ID(), ctr.state.PID)
 
 	// Ping the container to see if it's alive
@@ -400,13 +431,10 @@ func (r *OCIRuntime) stopContainer(ctr *Container, timeout uint) error {
 		return nil
 	}
 
-	stopSignal := ctr.config.StopSignal
-	if stopSignal == 0 {
-		stopSignal = uint(syscall.SIGTERM)
-	}
-
-	if timeout > 0 {
-		if err := r.killContainer(ctr, stopSignal); err != nil {
+	steps := ctr.stopSteps(timeout)
+	for i, step := range steps {
+		ctr.recordStopStep(i+1, step.Signal)
+		if err := r.KillContainer(ctr, step.Signal, false); err != nil {
 			// Is the container gone?
 			// If so, it probably died between the first check and
 			// our sending the signal
@@ -419,14 +447,20 @@ func (r *OCIRuntime) stopContainer(ctr *Container, timeout uint) error {
 			return err
 		}
 
-		if err := waitContainerStop(ctr, time.Duration(timeout)*time.Second); err != nil {
-			logrus.Warnf("Timed out stopping container %s, resorting to SIGKILL", ctr.ID())
+		if err := waitContainerStop(ctr, step.Timeout); err != nil {
+			if i < len(steps)-1 {
+				logrus.Warnf("Timed out stopping container %s with signal %d, escalating to signal %d", ctr.ID(), step.Signal, steps[i+1].Signal)
+			} else {
+				logrus.Warnf("Timed out stopping container %s, resorting to SIGKILL", ctr.ID())
+			}
 		} else {
 			// No error, the container is dead
 			return nil
 		}
 	}
 
+	ctr.recordStopStep(len(steps)+1, uint(syscall.SIGKILL))
+
 	var args []string
 	if rootless.IsRootless() {
 		// we don't use --all for rootless containers as the OCI runtime might use
@@ -452,19 +486,24 @@ func (r *OCIRuntime) stopContainer(ctr *Container, timeout uint) error {
 		return errors.Wrapf(err, "error sending SIGKILL to container %s", ctr.ID())
 	}
 
-	// Give runtime a few seconds to make it happen
-	if err := waitContainerStop(ctr, killContainerTimeout); err != nil {
+	// Give runtime a few seconds to make it happen, VM-based runtimes
+	// also have to shut the virtual machine down
+	killTimeout := killContainerTimeout
+	if r.features().kvm {
+		killTimeout = vmKillContainerTimeout
+	}
+	if err := waitContainerStop(ctr, killTimeout); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// execStopContainer stops all active exec sessions in a container
+// ExecStopContainer stops all active exec sessions in a container
 // It will also stop all other processes in the container. It is only intended
 // to be used to assist in cleanup when removing a container.
 // SIGTERM is used by default to stop processes. If SIGTERM fails, SIGKILL will be used.
-func (r *OCIRuntime) execStopContainer(ctr *Container, timeout uint) error {
+func (r *ConmonOCIRuntime) ExecStopContainer(ctr *Container, timeout uint) error {
 	// Do we have active exec sessions?
 	if len(ctr.state.ExecSessions) == 0 {
 		return nil
@@ -0,0 +1,91 @@
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/define"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// missingOCIRuntime stands in for the OCI runtime of containers created with a
+// runtime that is no longer configured. Such containers can be inspected and
+// moved to another runtime, but not run.
+type missingOCIRuntime struct {
+	name string
+}
+
+// unavailable returns the error of every operation on the container
+func (r *missingOCIRuntime) unavailable(ctr *Container) error {
+	return errors.Wrapf(define.ErrOCIRuntimeUnavailable, "OCI runtime %s of container %s is not available in the current configuration; move the container to another runtime with podman system migrate --new-runtime", r.name, ctr.ID())
+}
+
+func (r *missingOCIRuntime) Name() string {
+	return r.name
+}
+
+func (r *missingOCIRuntime) Path() string {
+	return ""
+}
+
+func (r *missingOCIRuntime) Features() []string {
+	return nil
+}
+
+func (r *missingOCIRuntime) SupportsJSONErrors() bool {
+	return false
+}
+
+func (r *missingOCIRuntime) CreateContainer(ctr *Container, restoreOptions *ContainerCheckpointOptions) error {
+	return r.unavailable(ctr)
+}
+
+// The status of the container cannot be refreshed, so the last recorded one is
+// kept
+func (r *missingOCIRuntime) UpdateContainerStatus(ctr *Container, useRuntime bool) error {
+	return nil
+}
+
+func (r *missingOCIRuntime) ContainerState(id string) (*OCIContainerState, error) {
+	return nil, errors.Wrapf(define.ErrOCIRuntimeUnavailable, "OCI runtime %s is not available in the current configuration", r.name)
+}
+
+func (r *missingOCIRuntime) StartContainer(ctr *Container) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) KillContainer(ctr *Container, signal uint, all bool) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) StopContainer(ctr *Container, timeout uint) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) DeleteContainer(ctr *Container) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) UpdateContainer(ctr *Container, resources *spec.LinuxResources) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) PauseContainer(ctr *Container) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) UnpauseContainer(ctr *Container) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) CheckpointContainer(ctr *Container, options ContainerCheckpointOptions) error {
+	return r.unavailable(ctr)
+}
+
+func (r *missingOCIRuntime) ExecContainer(ctr *Container, cmd, capAdd, env []string, tty bool, cwd, user, sessionID string, streams *AttachStreams, preserveFDs int, resize chan remotecommand.TerminalSize, detachKeys string, detach bool) (int, chan error, error) {
+	return 0, nil, r.unavailable(ctr)
+}
+
+// No exec session can be running in the container
+func (r *missingOCIRuntime) ExecStopContainer(ctr *Container, timeout uint) error {
+	return nil
+}
@@ -0,0 +1,138 @@
+package libpod
+
+import (
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// OCIRuntime is an OCI runtime libpod can call into to perform container
+// operations. Runtimes configured in libpod.conf are ConmonOCIRuntimes.
+// Embedders can provide other implementations, such as runtimes that are not
+// driven through a runc-compatible command line, with WithOCIRuntimeBackend.
+// Runtimes are registered by name, and containers select the runtime they are
+// run with by that name.
+// The methods are called with the container locked. They record their effects
+// on the container through its OCI state methods, such as SetOCIPID, and the
+// container is saved by the caller.
+type OCIRuntime interface {
+	// Name returns the name of the runtime, by which containers select
+	// it.
+	Name() string
+	// Path returns the path of the runtime's executable, if it has one.
+	Path() string
+	// Features returns the names of the optional features the runtime
+	// supports, such as OCIFeatureCheckpoint.
+	Features() []string
+	// SupportsJSONErrors returns whether the runtime writes its errors to
+	// a JSON log.
+	SupportsJSONErrors() bool
+
+	// CreateContainer creates the container in the runtime, restoring it
+	// from a checkpoint if restoreOptions is set.
+	// Sets the PID of the container's process in its state, but does not
+	// save it.
+	CreateContainer(ctr *Container, restoreOptions *ContainerCheckpointOptions) error
+	// UpdateContainerStatus updates the state of the container from the
+	// runtime, without saving it. If useRuntime is false, the runtime
+	// itself is not queried, only whether the container has exited.
+	UpdateContainerStatus(ctr *Container, useRuntime bool) error
+	// ContainerState retrieves the state of the container with the given
+	// ID from the runtime, including containers unknown to libpod.
+	ContainerState(id string) (*OCIContainerState, error)
+	// StartContainer starts the created container.
+	StartContainer(ctr *Container) error
+	// KillContainer sends the given signal to the container's process, or
+	// to all of its processes if all is set.
+	KillContainer(ctr *Container, signal uint, all bool) error
+	// StopContainer stops the container, killing it if it has not stopped
+	// after timeout seconds.
+	StopContainer(ctr *Container, timeout uint) error
+	// DeleteContainer removes the stopped container from the runtime.
+	DeleteContainer(ctr *Container) error
+	// UpdateContainer changes the resource limits of the running
+	// container.
+	UpdateContainer(ctr *Container, resources *spec.LinuxResources) error
+	// PauseContainer pauses the container.
+	PauseContainer(ctr *Container) error
+	// UnpauseContainer unpauses the container.
+	UnpauseContainer(ctr *Container) error
+	// CheckpointContainer checkpoints the container to its checkpoint
+	// path.
+	CheckpointContainer(ctr *Container, options ContainerCheckpointOptions) error
+
+	// ExecContainer runs a command in the running container as the exec
+	// session with the given ID. It returns the PID of the command, and
+	// unless detach is set, a channel receiving the result of the attach
+	// to its streams once it exits.
+	ExecContainer(ctr *Container, cmd, capAdd, env []string, tty bool, cwd, user, sessionID string, streams *AttachStreams, preserveFDs int, resize chan remotecommand.TerminalSize, detachKeys string, detach bool) (int, chan error, error)
+	// ExecStopContainer stops all the exec sessions of the container.
+	ExecStopContainer(ctr *Container, timeout uint) error
+}
+
+// hasOCIFeature returns whether the given OCI runtime supports the feature
+// with the given name
+func hasOCIFeature(ociRuntime OCIRuntime, feature string) bool {
+	for _, f := range ociRuntime.Features() {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// getOCIRuntime returns the OCI runtime registered with the given name. The
+// default runtime is returned for an empty name. Legacy containers might use a
+// literal path for their OCI runtime name; the base name of the path is the
+// name of the runtime.
+func (r *Runtime) getOCIRuntime(name string) (OCIRuntime, bool) {
+	if name == "" {
+		return r.defaultOCIRuntime, true
+	}
+	ociRuntime, ok := r.ociRuntimes[ociRuntimeName(name)]
+	return ociRuntime, ok
+}
+
+// BundlePath returns the path of the OCI bundle of the container, holding the
+// config.json of its spec once it is created
+func (c *Container) BundlePath() string {
+	return c.bundlePath()
+}
+
+// OCIState returns the state of the container, as last recorded. Unlike State,
+// it neither locks nor syncs the container, so that OCIRuntime
+// implementations can call it.
+func (c *Container) OCIState() define.ContainerStatus {
+	return c.state.State
+}
+
+// SetOCIState records the state of the container reported by its OCI runtime.
+// An error is returned if the container cannot move to the state.
+// It is meant to be called by OCIRuntime implementations.
+func (c *Container) SetOCIState(state define.ContainerStatus) error {
+	return c.setState(state)
+}
+
+// SetOCIPID records the PID of the main process of the container, or 0 once
+// it exited.
+// It is meant to be called by OCIRuntime implementations.
+func (c *Container) SetOCIPID(pid int) {
+	c.state.PID = pid
+}
+
+// SetOCIStartedTime records when the container was started.
+// It is meant to be called by OCIRuntime implementations.
+func (c *Container) SetOCIStartedTime(started time.Time) {
+	c.state.StartedTime = started
+}
+
+// SetOCIExited records that the main process of the container exited with the
+// given code at the given time.
+// It is meant to be called by OCIRuntime implementations.
+func (c *Container) SetOCIExited(exitCode int32, finished time.Time) {
+	c.state.ExitCode = exitCode
+	c.state.FinishedTime = finished
+	c.state.Exited = true
+}
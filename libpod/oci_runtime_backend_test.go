@@ -0,0 +1,171 @@
+package libpod_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/opencontainers/runtime-tools/generate"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// fakeOCIRuntime is an OCI runtime implemented outside of libpod, running no
+// process. Killed containers exit with 128 plus the signal.
+type fakeOCIRuntime struct {
+	lock   sync.Mutex
+	exited map[string]int32
+}
+
+var _ libpod.OCIRuntime = &fakeOCIRuntime{}
+
+func (r *fakeOCIRuntime) Name() string             { return "fake" }
+func (r *fakeOCIRuntime) Path() string             { return "" }
+func (r *fakeOCIRuntime) Features() []string       { return nil }
+func (r *fakeOCIRuntime) SupportsJSONErrors() bool { return false }
+
+func (r *fakeOCIRuntime) CreateContainer(ctr *libpod.Container, restoreOptions *libpod.ContainerCheckpointOptions) error {
+	if _, err := os.Stat(filepath.Join(ctr.BundlePath(), "config.json")); err != nil {
+		return err
+	}
+	ctr.SetOCIPID(4242)
+	return nil
+}
+
+func (r *fakeOCIRuntime) UpdateContainerStatus(ctr *libpod.Container, useRuntime bool) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	exitCode, ok := r.exited[ctr.ID()]
+	if !ok || ctr.OCIState() != define.ContainerStateRunning {
+		return nil
+	}
+	if err := ctr.SetOCIState(define.ContainerStateStopped); err != nil {
+		return err
+	}
+	ctr.SetOCIPID(0)
+	ctr.SetOCIExited(exitCode, time.Now())
+	return nil
+}
+
+func (r *fakeOCIRuntime) ContainerState(id string) (*libpod.OCIContainerState, error) {
+	return nil, errors.Wrapf(define.ErrNoSuchCtr, "no container with ID %s", id)
+}
+
+func (r *fakeOCIRuntime) StartContainer(ctr *libpod.Container) error {
+	ctr.SetOCIStartedTime(time.Now())
+	return nil
+}
+
+func (r *fakeOCIRuntime) KillContainer(ctr *libpod.Container, signal uint, all bool) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.exited[ctr.ID()] = 128 + int32(signal)
+	return nil
+}
+
+func (r *fakeOCIRuntime) StopContainer(ctr *libpod.Container, timeout uint) error {
+	return r.KillContainer(ctr, uint(syscall.SIGKILL), false)
+}
+
+func (r *fakeOCIRuntime) DeleteContainer(ctr *libpod.Container) error {
+	return nil
+}
+
+func (r *fakeOCIRuntime) UpdateContainer(ctr *libpod.Container, resources *spec.LinuxResources) error {
+	return define.ErrNotImplemented
+}
+
+func (r *fakeOCIRuntime) PauseContainer(ctr *libpod.Container) error {
+	return define.ErrNotImplemented
+}
+
+func (r *fakeOCIRuntime) UnpauseContainer(ctr *libpod.Container) error {
+	return define.ErrNotImplemented
+}
+
+func (r *fakeOCIRuntime) CheckpointContainer(ctr *libpod.Container, options libpod.ContainerCheckpointOptions) error {
+	return define.ErrNotImplemented
+}
+
+func (r *fakeOCIRuntime) ExecContainer(ctr *libpod.Container, cmd, capAdd, env []string, tty bool, cwd, user, sessionID string, streams *libpod.AttachStreams, preserveFDs int, resize chan remotecommand.TerminalSize, detachKeys string, detach bool) (int, chan error, error) {
+	return 0, nil, define.ErrNotImplemented
+}
+
+func (r *fakeOCIRuntime) ExecStopContainer(ctr *libpod.Container, timeout uint) error {
+	return nil
+}
+
+func TestOCIRuntimeBackend(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Test not running as root")
+	}
+	if _, err := exec.LookPath("conmon"); err != nil {
+		t.Skip("conmon is not installed")
+	}
+	dir, err := ioutil.TempDir("", "oci-backend")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	config := fmt.Sprintf("lock_type = \"file\"\nevents_logger = \"none\"\nstatic_dir = %q\ntmp_dir = %q\nvolume_path = %q\ncni_config_dir = %q\n",
+		filepath.Join(dir, "static"), filepath.Join(dir, "tmp"), filepath.Join(dir, "volumes"), filepath.Join(dir, "cni"))
+	configPath := filepath.Join(dir, "libpod.conf")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config), 0644))
+
+	backend := &fakeOCIRuntime{exited: make(map[string]int32)}
+	runtime, err := libpod.NewRuntimeFromConfig(context.Background(), configPath,
+		libpod.WithStateType(libpod.InMemoryStateStore),
+		libpod.WithStorageConfig(storage.StoreOptions{
+			RunRoot:         filepath.Join(dir, "run"),
+			GraphRoot:       filepath.Join(dir, "root"),
+			GraphDriverName: "vfs",
+		}),
+		libpod.WithOCIRuntimeBackend(backend),
+		libpod.WithOCIRuntime(backend.Name()),
+	)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, runtime.Shutdown(true))
+	}()
+
+	rootfs := filepath.Join(dir, "rootfs")
+	require.NoError(t, os.MkdirAll(rootfs, 0755))
+	g, err := generate.New("linux")
+	require.NoError(t, err)
+	ctr, err := runtime.NewContainer(context.Background(), g.Config, libpod.WithRootFS(rootfs))
+	require.NoError(t, err)
+	assert.Equal(t, "fake", ctr.RuntimeName())
+
+	require.NoError(t, ctr.Start(context.Background(), false))
+	state, err := ctr.State()
+	require.NoError(t, err)
+	assert.Equal(t, define.ContainerStateRunning, state)
+	pid, err := ctr.PID()
+	require.NoError(t, err)
+	assert.Equal(t, 4242, pid)
+	started, err := ctr.StartedTime()
+	require.NoError(t, err)
+	assert.False(t, started.IsZero())
+
+	require.NoError(t, ctr.Kill(uint(syscall.SIGKILL)))
+	exitCode, err := ctr.Wait()
+	require.NoError(t, err)
+	assert.Equal(t, int32(128+syscall.SIGKILL), exitCode)
+	state, err = ctr.State()
+	require.NoError(t, err)
+	assert.Equal(t, define.ContainerStateStopped, state)
+	pid, err = ctr.PID()
+	require.NoError(t, err)
+	assert.Equal(t, 0, pid)
+}
@@ -0,0 +1,75 @@
+package libpod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOCIRuntimeBackend(t *testing.T) {
+	runtime := &Runtime{}
+	require.NoError(t, WithOCIRuntimeBackend(probedRuntime("nspawn", ociRuntimeFeatures{}))(runtime))
+	assert.Contains(t, runtime.ociRuntimeBackends, "nspawn")
+
+	err := WithOCIRuntimeBackend(nil)(runtime)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+	err = WithOCIRuntimeBackend(probedRuntime("", ociRuntimeFeatures{}))(runtime)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
+
+func TestGetOCIRuntime(t *testing.T) {
+	runc := probedRuntime("runc", ociRuntimeFeatures{})
+	wasm := probedRuntime("wasm", ociRuntimeFeatures{})
+	runtime := &Runtime{
+		defaultOCIRuntime: runc,
+		ociRuntimes:       map[string]OCIRuntime{"runc": runc, "wasm": wasm},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		ociRuntime OCIRuntime
+	}{
+		{"", runc},
+		{"wasm", wasm},
+		// Legacy containers refer to their runtime by path
+		{"/usr/bin/runc", runc},
+	} {
+		ociRuntime, ok := runtime.getOCIRuntime(tt.name)
+		require.True(t, ok, tt.name)
+		assert.Equal(t, tt.ociRuntime, ociRuntime, tt.name)
+	}
+
+	_, ok := runtime.getOCIRuntime("kata")
+	assert.False(t, ok)
+}
+
+func TestHasOCIFeature(t *testing.T) {
+	ociRuntime := probedRuntime("runc", ociRuntimeFeatures{checkpoint: true})
+	assert.True(t, hasOCIFeature(ociRuntime, OCIFeatureCheckpoint))
+	assert.False(t, hasOCIFeature(ociRuntime, OCIFeatureKVM))
+}
+
+func TestOCIStateSetters(t *testing.T) {
+	c := &Container{
+		config: &ContainerConfig{ID: "abc"},
+		state:  &ContainerState{State: define.ContainerStateCreated},
+	}
+	require.NoError(t, c.SetOCIState(define.ContainerStateRunning))
+	assert.Equal(t, define.ContainerStateRunning, c.OCIState())
+	c.SetOCIPID(1234)
+	assert.Equal(t, 1234, c.state.PID)
+
+	finished := time.Now()
+	c.SetOCIExited(2, finished)
+	assert.Equal(t, int32(2), c.state.ExitCode)
+	assert.Equal(t, finished, c.state.FinishedTime)
+	assert.True(t, c.state.Exited)
+
+	// Removal is final
+	require.NoError(t, c.SetOCIState(define.ContainerStateRemoving))
+	assert.Error(t, c.SetOCIState(define.ContainerStateRunning))
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"syscall"
 
 	"github.com/containers/image/manifest"
@@ -12,11 +13,13 @@ import (
 	"github.com/containers/libpod/libpod/events"
 	"github.com/containers/libpod/pkg/namespaces"
 	"github.com/containers/libpod/pkg/rootless"
+	"github.com/containers/libpod/pkg/schedule"
 	"github.com/containers/libpod/pkg/util"
 	"github.com/containers/storage"
 	"github.com/containers/storage/pkg/idtools"
 	"github.com/cri-o/ocicni/pkg/ocicni"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -215,6 +218,20 @@ func WithNetworkCmdPath(path string) RuntimeOption {
 	}
 }
 
+// WithDNSCacheCmdPath specifies the path to the dnsmasq binary used to run the
+// DNS caches of pods.
+func WithDNSCacheCmdPath(path string) RuntimeOption {
+	return func(rt *Runtime) error {
+		if rt.valid {
+			return define.ErrRuntimeFinalized
+		}
+
+		rt.config.DNSCacheCmdPath = path
+
+		return nil
+	}
+}
+
 // WithCgroupManager specifies the manager implementation name which is used to
 // handle cgroups for containers.
 // Current valid values are "cgroupfs" and "systemd".
@@ -384,6 +401,51 @@ func WithNamespace(ns string) RuntimeOption {
 	}
 }
 
+// WithNamespaceDirs sets separate directories for the files of the given libpod
+// namespace. Directories that are not set use the runtime's defaults.
+func WithNamespaceDirs(ns string, dirs NamespaceDirConfig) RuntimeOption {
+	return func(rt *Runtime) error {
+		if rt.valid {
+			return define.ErrRuntimeFinalized
+		}
+
+		if ns == "" {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a namespace to set directories for")
+		}
+
+		if rt.config.NamespaceDirs == nil {
+			rt.config.NamespaceDirs = make(map[string]NamespaceDirConfig)
+		}
+		rt.config.NamespaceDirs[ns] = dirs
+
+		return nil
+	}
+}
+
+// WithNetworkHooks sets the commands run when containers join or leave the
+// given CNI network.
+func WithNetworkHooks(network string, hooks NetworkHookConfig) RuntimeOption {
+	return func(rt *Runtime) error {
+		if rt.valid {
+			return define.ErrRuntimeFinalized
+		}
+
+		if network == "" {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a network to set hooks for")
+		}
+		if err := hooks.validate(); err != nil {
+			return err
+		}
+
+		if rt.config.NetworkHooks == nil {
+			rt.config.NetworkHooks = make(map[string]NetworkHookConfig)
+		}
+		rt.config.NetworkHooks[network] = hooks
+
+		return nil
+	}
+}
+
 // WithVolumePath sets the path under which all named volumes
 // should be created.
 // The path changes based on whethe rthe user is running as root
@@ -448,16 +510,92 @@ func WithRenumber() RuntimeOption {
 	}
 }
 
-// WithMigrate instructs libpod to migrate container configurations to account
-// for changes between Libpod versions. All running containers will be stopped
-// during a migration, then restarted after the migration is complete.
-func WithMigrate() RuntimeOption {
+// WithStorageReadoption permits the runtime to be initialized with a storage
+// graph driver that differs from the one the database was created with.
+// Containers whose storage is still present under the new graph driver are
+// re-adopted; if any are not, initialization fails with an error listing the
+// containers that must be removed and recreated, and the database is left
+// unchanged.
+func WithStorageReadoption() RuntimeOption {
+	return func(rt *Runtime) error {
+		if rt.valid {
+			return define.ErrRuntimeFinalized
+		}
+
+		rt.doReadoptStorage = true
+
+		return nil
+	}
+}
+
+// WithContainerStateHook adds a hook that will be called each time a
+// container managed by the runtime moves from one state to another.
+func WithContainerStateHook(hook ContainerStateHook) RuntimeOption {
+	return func(rt *Runtime) error {
+		if rt.valid {
+			return define.ErrRuntimeFinalized
+		}
+
+		if hook == nil {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a non-nil state hook")
+		}
+
+		rt.stateHooks = append(rt.stateHooks, hook)
+
+		return nil
+	}
+}
+
+// WithCheckpointStorage adds a backend storing exported checkpoint archives.
+// It is used for checkpoint locations prefixed with the given scheme, e.g.
+// "s3" for s3://bucket/checkpoint.tar.gz.
+func WithCheckpointStorage(scheme string, storage CheckpointStorage) RuntimeOption {
+	return func(rt *Runtime) error {
+		if rt.valid {
+			return define.ErrRuntimeFinalized
+		}
+
+		if storage == nil {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a non-nil checkpoint storage")
+		}
+		if scheme == "" || strings.Contains(scheme, ":") {
+			return errors.Wrapf(define.ErrInvalidArg, "%q is not a valid checkpoint storage scheme", scheme)
+		}
+		if scheme == LocalCheckpointStorage || scheme == RegistryCheckpointStorage {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot replace built-in checkpoint storage %q", scheme)
+		}
+
+		if rt.checkpointStorages == nil {
+			rt.checkpointStorages = make(map[string]CheckpointStorage)
+		}
+		rt.checkpointStorages[scheme] = storage
+
+		return nil
+	}
+}
+
+// WithOCIRuntimeBackend registers an OCI runtime implemented by the caller,
+// under the name it returns. Containers run with it by selecting it with
+// WithCtrOCIRuntime, or by default if it is named by WithOCIRuntime.
+// Its name must not conflict with a runtime of the configuration.
+func WithOCIRuntimeBackend(ociRuntime OCIRuntime) RuntimeOption {
 	return func(rt *Runtime) error {
 		if rt.valid {
 			return define.ErrRuntimeFinalized
 		}
 
-		rt.doMigrate = true
+		if ociRuntime == nil {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a non-nil OCI runtime")
+		}
+		name := ociRuntime.Name()
+		if name == "" || strings.Contains(name, "/") {
+			return errors.Wrapf(define.ErrInvalidArg, "%q is not a valid OCI runtime name", name)
+		}
+
+		if rt.ociRuntimeBackends == nil {
+			rt.ociRuntimeBackends = make(map[string]OCIRuntime)
+		}
+		rt.ociRuntimeBackends[name] = ociRuntime
 
 		return nil
 	}
@@ -703,6 +841,50 @@ func WithIDMappings(idmappings storage.IDMappingOptions) CtrCreateOption {
 	}
 }
 
+// WithUserNSKeepID sets the user whose UID and GID are kept inside the
+// container's user namespace. The user is added to the container's passwd file
+// if the image lacks it, HOME is set to its home directory, and the home and
+// working directories of the container are owned by it.
+// The ID mappings of the container must map the user's IDs to themselves, as
+// those created for --userns=keep-id do.
+func WithUserNSKeepID(keepID KeepIDUser) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if err := keepID.validate(); err != nil {
+			return err
+		}
+		ctr.config.KeepID = &keepID
+		return nil
+	}
+}
+
+// WithAutoUserNS gives the container a user namespace mapping size UIDs and
+// GIDs, allocated from the subordinate IDs of the host when the container is
+// created. The IDs are used by no other container created with this option
+// until the container is removed. If size is 0, AutoUserNSDefaultSize IDs are
+// allocated.
+// Cannot be used with WithUserNSFrom, or with ID mappings set explicitly.
+func WithAutoUserNS(size int) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if size < 0 {
+			return errors.Wrapf(define.ErrInvalidArg, "user namespace size must not be negative")
+		}
+		if size == 0 {
+			size = AutoUserNSDefaultSize
+		}
+		ctr.config.AutoUserNS = true
+		ctr.config.AutoUserNSSize = size
+		return nil
+	}
+}
+
 // WithExitCommand sets the ExitCommand for the container, appending on the ctr.ID() to the end
 func WithExitCommand(exitCommand []string) CtrCreateOption {
 	return func(ctr *Container) error {
@@ -715,6 +897,18 @@ func WithExitCommand(exitCommand []string) CtrCreateOption {
 	}
 }
 
+// WithCreateCommand records the command line the container is created with
+func WithCreateCommand(cmd []string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.CreateCommand = cmd
+		return nil
+	}
+}
+
 // WithUTSNSFromPod indicates the the container should join the UTS namespace of
 // its pod
 func WithUTSNSFromPod(p *Pod) CtrCreateOption {
@@ -815,6 +1009,10 @@ func WithNetNSFrom(nsCtr *Container) CtrCreateOption {
 			return errors.Wrapf(define.ErrInvalidArg, "cannot join another container's net ns as we are making a new net ns")
 		}
 
+		if ctr.config.NetNsPath != "" {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot join another container's net ns as we are joining the net ns at %s", ctr.config.NetNsPath)
+		}
+
 		if ctr.config.Pod != "" && nsCtr.config.Pod != ctr.config.Pod {
 			return errors.Wrapf(define.ErrInvalidArg, "container has joined pod %s and dependency container %s is not a member of the pod", ctr.config.Pod, nsCtr.ID())
 		}
@@ -970,6 +1168,29 @@ func WithDependencyCtrs(ctrs []*Container) CtrCreateOption {
 	}
 }
 
+// WithDependencyConditions sets the conditions that the given dependencies must
+// meet before the container can be started. The map is keyed by dependency
+// container ID. Each container must also be passed to WithDependencyCtrs, or
+// be a namespace dependency of the container.
+func WithDependencyConditions(conditions map[string]define.DependencyCondition) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.DependencyConditions = make(map[string]define.DependencyCondition, len(conditions))
+		for id, condition := range conditions {
+			validated, err := define.StringToDependencyCondition(string(condition))
+			if err != nil {
+				return errors.Wrapf(err, "invalid condition for dependency %s", id)
+			}
+			ctr.config.DependencyConditions[id] = validated
+		}
+
+		return nil
+	}
+}
+
 // WithNetNS indicates that the container should be given a new network
 // namespace with a minimal configuration.
 // An optional array of port mappings can be provided.
@@ -984,6 +1205,10 @@ func WithNetNS(portMappings []ocicni.PortMapping, postConfigureNetNS bool, netmo
 			return errors.Wrapf(define.ErrInvalidArg, "container is already set to join another container's net ns, cannot create a new net ns")
 		}
 
+		if ctr.config.NetNsPath != "" {
+			return errors.Wrapf(define.ErrInvalidArg, "container is already set to join the net ns at %s, cannot create a new net ns", ctr.config.NetNsPath)
+		}
+
 		ctr.config.PostConfigureNetNS = postConfigureNetNS
 		ctr.config.NetMode = namespaces.NetworkMode(netmode)
 		ctr.config.CreateNetNS = true
@@ -994,6 +1219,36 @@ func WithNetNS(portMappings []ocicni.PortMapping, postConfigureNetNS bool, netmo
 	}
 }
 
+// WithNetNSPath indicates that the container should join the existing network
+// namespace at the given path, such as /proc/<pid>/ns/net. The path is checked
+// when the container is started. Libpod neither configures the namespace nor
+// removes it when the container stops.
+// Conflicts with WithNetNS() and WithNetNSFrom().
+func WithNetNSPath(path string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if !filepath.IsAbs(path) {
+			return errors.Wrapf(define.ErrInvalidArg, "network namespace path %q must be absolute", path)
+		}
+
+		if ctr.config.CreateNetNS {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot join the net ns at %s as we are making a new net ns", path)
+		}
+
+		if ctr.config.NetNsCtr != "" {
+			return errors.Wrapf(define.ErrInvalidArg, "container is already set to join another container's net ns, cannot join the net ns at %s", path)
+		}
+
+		ctr.config.NetNsPath = path
+		ctr.config.NetMode = namespaces.NetworkMode("ns:" + path)
+
+		return nil
+	}
+}
+
 // WithStaticIP indicates that the container should request a static IP from
 // the CNI plugins.
 // It cannot be set unless WithNetNS has already been passed.
@@ -1019,6 +1274,121 @@ func WithStaticIP(ip net.IP) CtrCreateOption {
 	}
 }
 
+// WithStaticMAC indicates that the container should request a static MAC
+// address from the CNI plugins.
+// It cannot be set unless WithNetNS has already been passed.
+// Further, it cannot be set if additional CNI networks to join have been
+// specified.
+func WithStaticMAC(mac net.HardwareAddr) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if !ctr.config.CreateNetNS {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot set a static MAC if the container is not creating a network namespace")
+		}
+
+		if len(ctr.config.Networks) != 0 {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot set a static MAC if joining additional CNI networks")
+		}
+
+		ctr.config.StaticMAC = mac
+
+		return nil
+	}
+}
+
+// WithBandwidth limits the rate of the container's incoming and outgoing
+// traffic on its CNI networks. Rates are in bits per second and bursts in bits.
+// A rate without a burst may burst up to one second worth of traffic.
+// The limits are applied by the CNI bandwidth plugin, which must be configured
+// for the container's networks, and so are not available to containers using
+// rootless networking.
+// It cannot be set unless WithNetNS has already been passed.
+func WithBandwidth(bandwidth ocicni.BandwidthConfig) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if !ctr.config.CreateNetNS {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot limit bandwidth if the container is not creating a network namespace")
+		}
+
+		if ctr.config.NetMode.IsSlirp4netns() || ctr.config.NetMode.IsPasta() {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot limit bandwidth when using rootless networking")
+		}
+
+		if bandwidth.IngressRate == 0 && bandwidth.IngressBurst != 0 {
+			return errors.Wrapf(define.ErrInvalidArg, "an ingress burst requires an ingress rate")
+		}
+		if bandwidth.EgressRate == 0 && bandwidth.EgressBurst != 0 {
+			return errors.Wrapf(define.ErrInvalidArg, "an egress burst requires an egress rate")
+		}
+		if bandwidth.IngressRate == 0 && bandwidth.EgressRate == 0 {
+			ctr.config.Bandwidth = nil
+			return nil
+		}
+
+		// The bandwidth plugin requires a burst for each rate
+		if bandwidth.IngressBurst == 0 {
+			bandwidth.IngressBurst = bandwidth.IngressRate
+		}
+		if bandwidth.EgressBurst == 0 {
+			bandwidth.EgressBurst = bandwidth.EgressRate
+		}
+
+		ctr.config.Bandwidth = &bandwidth
+
+		return nil
+	}
+}
+
+// WithNetworkAliases sets additional names the container can be resolved by
+// from other containers on each of the CNI networks it joins. If no networks
+// were given to WithNetNS, the aliases apply to the default network.
+// Aliases are resolved by the dnsname CNI plugin, which must be configured
+// for the networks in question.
+// It cannot be set unless WithNetNS has already been passed.
+func WithNetworkAliases(aliases []string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if !ctr.config.CreateNetNS {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot set network aliases if the container is not creating a network namespace")
+		}
+
+		if ctr.config.NetMode.IsSlirp4netns() || ctr.config.NetMode.IsPasta() {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot set network aliases when using rootless networking")
+		}
+
+		if len(aliases) == 0 {
+			return nil
+		}
+
+		for _, alias := range aliases {
+			if alias == "" {
+				return errors.Wrapf(define.ErrInvalidArg, "network alias must not be empty")
+			}
+		}
+
+		networks := ctr.config.Networks
+		if len(networks) == 0 {
+			networks = []string{ctr.runtime.defaultNetworkName()}
+		}
+
+		ctr.config.NetworkAliases = make(map[string][]string, len(networks))
+		for _, netName := range networks {
+			ctr.config.NetworkAliases[netName] = append([]string{}, aliases...)
+		}
+
+		return nil
+	}
+}
+
 // WithLogDriver sets the log driver for the container
 func WithLogDriver(driver string) CtrCreateOption {
 	return func(ctr *Container) error {
@@ -1028,7 +1398,7 @@ func WithLogDriver(driver string) CtrCreateOption {
 		switch driver {
 		case "":
 			return errors.Wrapf(define.ErrInvalidArg, "log driver must be set")
-		case JournaldLogging, KubernetesLogging, JSONLogging:
+		case JournaldLogging, KubernetesLogging, JSONLogging, SyslogLogging, FluentdLogging:
 			break
 		default:
 			return errors.Wrapf(define.ErrInvalidArg, "invalid log driver")
@@ -1040,6 +1410,54 @@ func WithLogDriver(driver string) CtrCreateOption {
 	}
 }
 
+// WithLogTag sets the tag given to the log entries of the container in the
+// journal, or by the syslog and fluentd log drivers.
+func WithLogTag(tag string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.LogTag = tag
+
+		return nil
+	}
+}
+
+// WithLogAddress sets the address of the endpoint the logs of the container are
+// sent to. It is only used by the syslog and fluentd log drivers.
+func WithLogAddress(address string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.LogAddress = address
+
+		return nil
+	}
+}
+
+// WithLogRotation sets the size in bytes the log file of the container may
+// grow to before it is rotated, and the number of log files kept, including
+// the one being written. It is only used by the json-file log driver.
+func WithLogRotation(maxSize int64, maxFiles uint) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if maxSize < 0 {
+			return errors.Wrapf(define.ErrInvalidArg, "log max size must not be negative")
+		}
+
+		ctr.config.LogMaxSize = maxSize
+		ctr.config.LogMaxFiles = maxFiles
+
+		return nil
+	}
+}
+
 // WithLogPath sets the path to the log file.
 func WithLogPath(path string) CtrCreateOption {
 	return func(ctr *Container) error {
@@ -1151,23 +1569,86 @@ func WithConmonPidFile(path string) CtrCreateOption {
 	}
 }
 
-// WithGroups sets additional groups for the container, which are defined by
-// the user.
-func WithGroups(groups []string) CtrCreateOption {
+// WithConmonLogLevel sets the log level conmon will use when monitoring the
+// container. Level must be a valid logrus log level.
+func WithConmonLogLevel(level string) CtrCreateOption {
 	return func(ctr *Container) error {
 		if ctr.valid {
 			return define.ErrCtrFinalized
 		}
-		ctr.config.Groups = groups
+
+		if _, err := logrus.ParseLevel(level); err != nil {
+			return errors.Wrapf(define.ErrInvalidArg, "invalid conmon log level %q", level)
+		}
+
+		ctr.config.ConmonLogLevel = level
+
 		return nil
 	}
 }
 
-// WithUserVolumes sets the user-added volumes of the container.
-// These are not added to the container's spec, but will instead be used during
-// commit to populate the volumes of the new image, and to trigger some OCI
-// hooks that are only added if volume mounts are present.
-// Furthermore, they are used in the output of inspect, to filter volumes -
+// WithHTTPProxy sets whether the proxy environment variables of the host
+// (HTTP_PROXY and similar) are added to the container's environment,
+// overriding the http_proxy setting of the runtime. Variables set explicitly in
+// the container's spec are never replaced.
+func WithHTTPProxy(enable bool) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.HTTPProxy = enable
+
+		return nil
+	}
+}
+
+// WithConmonSyslog instructs conmon to log to syslog when monitoring the
+// container, regardless of its log level.
+func WithConmonSyslog() CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.ConmonSyslog = true
+
+		return nil
+	}
+}
+
+// WithConmonDebugLog captures conmon's own output when monitoring the
+// container to a file in the container's static directory. The path to the
+// file is given by the container's inspect output.
+func WithConmonDebugLog() CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.ConmonDebugLog = true
+
+		return nil
+	}
+}
+
+// WithGroups sets additional groups for the container, which are defined by
+// the user.
+func WithGroups(groups []string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+		ctr.config.Groups = groups
+		return nil
+	}
+}
+
+// WithUserVolumes sets the user-added volumes of the container.
+// These are not added to the container's spec, but will instead be used during
+// commit to populate the volumes of the new image, and to trigger some OCI
+// hooks that are only added if volume mounts are present.
+// Furthermore, they are used in the output of inspect, to filter volumes -
 // only volumes included in this list will be included in the output.
 // Unless explicitly set, committed images will have no volumes.
 // The given volumes slice must not be nil.
@@ -1187,6 +1668,30 @@ func WithUserVolumes(volumes []string) CtrCreateOption {
 	}
 }
 
+// WithReadOnlyRootfs makes the container's root filesystem read-only, and
+// mounts a writable tmpfs on each of the given paths when the container starts.
+// Paths that are already the destination of a mount or volume are skipped.
+// A nil or empty list mounts no tmpfs.
+func WithReadOnlyRootfs(tmpfs []string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if err := validateReadOnlyTmpfs(tmpfs); err != nil {
+			return err
+		}
+
+		ctr.config.Spec.Root.Readonly = true
+		ctr.config.ReadOnlyTmpfs = nil
+		if len(tmpfs) > 0 {
+			ctr.config.ReadOnlyTmpfs = make([]string, len(tmpfs))
+			copy(ctr.config.ReadOnlyTmpfs, tmpfs)
+		}
+		return nil
+	}
+}
+
 // WithEntrypoint sets the entrypoint of the container.
 // This is not used to change the container's spec, but will instead be used
 // during commit to populate the entrypoint of the new image.
@@ -1255,6 +1760,26 @@ func WithCtrNamespace(ns string) CtrCreateOption {
 	}
 }
 
+// WithCtrOCIRuntime sets the OCI runtime the container will be run with,
+// overriding the default runtime of libpod. The runtime must be one of the
+// runtimes configured in libpod, such as a VM-based runtime like Kata
+// Containers for containers that need stronger isolation.
+func WithCtrOCIRuntime(runtime string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if runtime == "" {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a valid OCI runtime name")
+		}
+
+		ctr.config.OCIRuntime = runtime
+
+		return nil
+	}
+}
+
 // WithUseImageResolvConf tells the container not to bind-mount resolv.conf in.
 // This conflicts with other DNS-related options.
 func WithUseImageResolvConf() CtrCreateOption {
@@ -1303,7 +1828,7 @@ func WithRestartPolicy(policy string) CtrCreateOption {
 		}
 
 		switch policy {
-		case RestartPolicyNone, RestartPolicyNo, RestartPolicyOnFailure, RestartPolicyAlways:
+		case RestartPolicyNone, RestartPolicyNo, RestartPolicyOnFailure, RestartPolicyAlways, RestartPolicyUnlessStopped:
 			ctr.config.RestartPolicy = policy
 		default:
 			return errors.Wrapf(define.ErrInvalidArg, "%q is not a valid restart policy", policy)
@@ -1342,6 +1867,111 @@ func withIsInfra() CtrCreateOption {
 	}
 }
 
+// WithInitCtrType makes the container an init container of its pod, of the
+// given type. Init containers are run to completion when the pod is started,
+// before the pod's other containers are started.
+// The container must also join a pod.
+func WithInitCtrType(initType define.InitContainerType) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		validated, err := define.StringToInitContainerType(string(initType))
+		if err != nil {
+			return err
+		}
+		ctr.config.InitContainerType = validated
+
+		return nil
+	}
+}
+
+// WithSdNotifyMode sets how the systemd unit running podman is notified that
+// the container is ready
+func WithSdNotifyMode(mode define.SdNotifyMode) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		validated, err := define.StringToSdNotifyMode(string(mode))
+		if err != nil {
+			return err
+		}
+		ctr.config.SdNotifyMode = validated
+
+		return nil
+	}
+}
+
+// WithStopEscalation sets the ordered list of signals sent to stop the
+// container, each followed by a wait for the container to exit, replacing its
+// stop signal and timeout. If the container is still running after the last
+// step, it is killed with SIGKILL.
+func WithStopEscalation(steps []StopSignalStep) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if err := validateStopEscalation(steps); err != nil {
+			return err
+		}
+		ctr.config.StopEscalation = make([]StopSignalStep, len(steps))
+		copy(ctr.config.StopEscalation, steps)
+
+		return nil
+	}
+}
+
+// WithExternallyManaged marks the container's conmon and OCI runtime processes
+// as possibly managed outside of this Podman, so the runtime's state sync
+// periodically reconciles the container's status with the OCI runtime.
+func WithExternallyManaged() CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.ExternallyManaged = true
+
+		return nil
+	}
+}
+
+// WithStartSchedule sets a schedule on which the runtime's scheduler starts
+// the container. The schedule is either a time in RFC 3339 format, or a cron
+// expression.
+func WithStartSchedule(startSchedule string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		if _, err := schedule.Parse(startSchedule); err != nil {
+			return errors.Wrapf(define.ErrInvalidArg, "%v", err)
+		}
+		ctr.config.StartSchedule = startSchedule
+
+		return nil
+	}
+}
+
+// withDNSCache runs a caching DNS resolver in the container's network
+// namespace. It is used for the infra containers of pods with a DNS cache.
+func withDNSCache() CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		ctr.config.DNSCache = true
+
+		return nil
+	}
+}
+
 // WithNamedVolumes adds the given named volumes to the container.
 func WithNamedVolumes(volumes []*ContainerNamedVolume) CtrCreateOption {
 	return func(ctr *Container) error {
@@ -1387,6 +2017,26 @@ func WithHealthCheck(healthCheck *manifest.Schema2HealthConfig) CtrCreateOption
 	}
 }
 
+// WithHealthCheckOnFailure sets the action taken when the container becomes
+// unhealthy. Valid values are "none", "restart", "stop" and "kill". The empty
+// string is allowed, and will be equivalent to "none".
+func WithHealthCheckOnFailure(action string) CtrCreateOption {
+	return func(ctr *Container) error {
+		if ctr.valid {
+			return define.ErrCtrFinalized
+		}
+
+		switch action {
+		case "", HealthCheckOnFailureNone, HealthCheckOnFailureRestart, HealthCheckOnFailureStop, HealthCheckOnFailureKill:
+			ctr.config.HealthCheckOnFailure = action
+		default:
+			return errors.Wrapf(define.ErrInvalidArg, "%q is not a valid healthcheck failure action", action)
+		}
+
+		return nil
+	}
+}
+
 // Volume Creation Options
 
 // WithVolumeName sets the name of the volume.
@@ -1407,15 +2057,20 @@ func WithVolumeName(name string) VolumeCreateOption {
 }
 
 // WithVolumeDriver sets the volume's driver.
-// It is presently not implemented, but will be supported in a future Podman
-// release.
+// Besides the local driver, the name of any volume plugin configured in the
+// runtime may be given.
 func WithVolumeDriver(driver string) VolumeCreateOption {
 	return func(volume *Volume) error {
 		if volume.valid {
 			return define.ErrVolumeFinalized
 		}
 
-		return define.ErrNotImplemented
+		if _, err := volume.runtime.getVolumeDriver(driver); err != nil {
+			return err
+		}
+		volume.config.Driver = driver
+
+		return nil
 	}
 }
 
@@ -1493,6 +2148,69 @@ func withSetCtrSpecific() VolumeCreateOption {
 	}
 }
 
+// Network Creation Options
+
+// WithNetworkName sets the name of the network.
+func WithNetworkName(name string) NetworkCreateOption {
+	return func(network *Network) error {
+		if network.valid {
+			return define.ErrNetworkFinalized
+		}
+
+		// Check the name against a regex
+		if !nameRegex.MatchString(name) {
+			return regexError
+		}
+		network.config.Name = name
+
+		return nil
+	}
+}
+
+// WithNetworkDriver sets the network's driver, the type of the CNI plugin
+// that provides the network.
+func WithNetworkDriver(driver string) NetworkCreateOption {
+	return func(network *Network) error {
+		if network.valid {
+			return define.ErrNetworkFinalized
+		}
+
+		network.config.Driver = driver
+
+		return nil
+	}
+}
+
+// WithNetworkConfigPath sets the path to the network's CNI configuration
+// list.
+func WithNetworkConfigPath(path string) NetworkCreateOption {
+	return func(network *Network) error {
+		if network.valid {
+			return define.ErrNetworkFinalized
+		}
+
+		network.config.ConfigPath = path
+
+		return nil
+	}
+}
+
+// WithNetworkLabels sets the labels of the network.
+func WithNetworkLabels(labels map[string]string) NetworkCreateOption {
+	return func(network *Network) error {
+		if network.valid {
+			return define.ErrNetworkFinalized
+		}
+
+		network.config.Labels = make(map[string]string)
+		for key, value := range labels {
+			network.config.Labels[key] = value
+		}
+
+		return nil
+	}
+}
+
 // Pod Creation Options
 
 // WithPodName sets the name of the pod.
@@ -1576,6 +2294,25 @@ func WithPodCgroups() PodCreateOption {
 	}
 }
 
+// WithPodResourceLimits sets resource limits on the pod's cgroup, which are
+// shared by all containers in the pod. Containers in the pod cannot be given
+// higher limits. The pod must be created with its own cgroup.
+func WithPodResourceLimits(limits PodResourceLimits) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+
+		if err := limits.validate(); err != nil {
+			return err
+		}
+
+		pod.config.ResourceLimits = &limits
+
+		return nil
+	}
+}
+
 // WithPodNamespace sets the namespace for the created pod.
 // Namespaces are used to create separate views of Podman's state - runtimes can
 // join a specific namespace and see only containers and pods in that namespace.
@@ -1716,3 +2453,154 @@ func WithInfraContainerPorts(bindings []ocicni.PortMapping) PodCreateOption {
 		return nil
 	}
 }
+
+// WithInfraContainerImage sets the image of the pod's infra container,
+// overriding the runtime's default infra image.
+func WithInfraContainerImage(img string) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+		if img == "" {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a non-empty infra image")
+		}
+		pod.config.InfraContainer.InfraImage = img
+		return nil
+	}
+}
+
+// WithInfraContainerCommand sets the command of the pod's infra container,
+// overriding the entrypoint and command of its image.
+func WithInfraContainerCommand(cmd []string) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+		if len(cmd) == 0 || cmd[0] == "" {
+			return errors.Wrapf(define.ErrInvalidArg, "must provide a non-empty infra command")
+		}
+		pod.config.InfraContainer.InfraCommand = append([]string{}, cmd...)
+		return nil
+	}
+}
+
+// WithInfraContainerExposedPorts sets the ports the pod's containers listen
+// on without publishing them on the host. Only the container port and
+// protocol of each mapping may be set.
+func WithInfraContainerExposedPorts(ports []ocicni.PortMapping) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+		for _, port := range ports {
+			if port.ContainerPort <= 0 || port.ContainerPort > 65535 {
+				return errors.Wrapf(define.ErrInvalidArg, "invalid exposed port %d", port.ContainerPort)
+			}
+			if port.HostPort != 0 || port.HostIP != "" {
+				return errors.Wrapf(define.ErrInvalidArg, "exposed port %d cannot have a host port or IP, publish it instead", port.ContainerPort)
+			}
+			switch port.Protocol {
+			case "tcp", "udp":
+			default:
+				return errors.Wrapf(define.ErrInvalidArg, "invalid protocol %q for exposed port %d", port.Protocol, port.ContainerPort)
+			}
+		}
+		pod.config.InfraContainer.ExposedPorts = append([]ocicni.PortMapping{}, ports...)
+		return nil
+	}
+}
+
+// WithInfraContainerExitCommand sets the exit command of the pod's infra
+// container. It is needed for the pod's restart policy to act when the infra
+// container exits.
+func WithInfraContainerExitCommand(exitCommand []string) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+		pod.config.InfraContainer.ExitCommand = exitCommand
+		return nil
+	}
+}
+
+// WithPodRestartPolicy sets the restart policy of the pod. When a container in
+// the pod, or its infra container, exits without being stopped and the policy
+// matches, all containers in the pod are restarted in dependency order.
+// Retries are used only with the "on-failure" policy; 0 means unlimited
+// retries.
+func WithPodRestartPolicy(policy string, retries uint) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+
+		switch policy {
+		case RestartPolicyNone, RestartPolicyNo, RestartPolicyAlways, RestartPolicyUnlessStopped:
+			if retries > 0 {
+				return errors.Wrapf(define.ErrInvalidArg, "restart retries can only be set with the %q restart policy", RestartPolicyOnFailure)
+			}
+		case RestartPolicyOnFailure:
+		default:
+			return errors.Wrapf(define.ErrInvalidArg, "%q is not a valid restart policy", policy)
+		}
+
+		pod.config.RestartPolicy = policy
+		pod.config.RestartRetries = retries
+
+		return nil
+	}
+}
+
+// WithPodVolumes sets named volumes to mount into every container of the pod.
+// Volumes that do not exist are created with the pod, and are not removed with
+// it.
+func WithPodVolumes(volumes []*ContainerNamedVolume) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+		if err := validatePodVolumes(volumes); err != nil {
+			return err
+		}
+
+		podVolumes := make([]*ContainerNamedVolume, 0, len(volumes))
+		for _, vol := range volumes {
+			podVolumes = append(podVolumes, &ContainerNamedVolume{
+				Name:    vol.Name,
+				Dest:    vol.Dest,
+				Options: append([]string{}, vol.Options...),
+			})
+		}
+		pod.config.Volumes = podVolumes
+		return nil
+	}
+}
+
+// WithPodDNSConfig sets the DNS configuration of the pod, which is written into
+// the resolv.conf of its infra container. The pod must have an infra container.
+func WithPodDNSConfig(dns PodDNSConfig) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+		if err := dns.validate(); err != nil {
+			return err
+		}
+		pod.config.DNSConfig = &dns
+		return nil
+	}
+}
+
+// WithPodDNSCache runs a caching DNS resolver in the network namespace of the
+// pod's infra container, which forwards to the DNS servers the pod would
+// otherwise use. Containers sharing the pod's network namespace will use the
+// resolver. The pod must have an infra container.
+func WithPodDNSCache() PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+		pod.config.InfraContainer.DNSCache = true
+		return nil
+	}
+}
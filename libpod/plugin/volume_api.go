@@ -0,0 +1,256 @@
+// Package plugin implements a client for the Docker volume plugin HTTP API,
+// which volume plugins serve over a local UNIX socket.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// volumePluginType is the plugin type implemented by volume plugins.
+	volumePluginType = "VolumeDriver"
+	// pluginContentType is the content type of plugin API requests.
+	pluginContentType = "application/vnd.docker.plugins.v1.2+json"
+	// pluginTimeout is how long a plugin is given to answer a request.
+	// Creating and mounting volumes on remote storage can be slow.
+	pluginTimeout = 2 * time.Minute
+
+	activatePath     = "/Plugin.Activate"
+	createPath       = "/VolumeDriver.Create"
+	removePath       = "/VolumeDriver.Remove"
+	mountPath        = "/VolumeDriver.Mount"
+	unmountPath      = "/VolumeDriver.Unmount"
+	getPath          = "/VolumeDriver.Get"
+	capabilitiesPath = "/VolumeDriver.Capabilities"
+)
+
+var (
+	// ErrNotVolumePlugin indicates that the plugin does not implement the
+	// volume plugin API.
+	ErrNotVolumePlugin = errors.New("plugin is not a volume plugin")
+
+	// plugins caches activated plugins by name, as each is only activated
+	// once.
+	plugins     = make(map[string]*VolumePlugin)
+	pluginsLock sync.Mutex
+)
+
+// VolumePlugin is a client of a single volume plugin.
+type VolumePlugin struct {
+	// Name is the name of the plugin, used as the driver of its volumes.
+	Name string
+	// SocketPath is the path of the UNIX socket the plugin listens on.
+	SocketPath string
+
+	client *http.Client
+}
+
+// VolumeCapabilities are the capabilities reported by a volume plugin.
+type VolumeCapabilities struct {
+	// Scope is "local" if volumes are only available on this host, or
+	// "global" if they are available cluster-wide.
+	Scope string `json:"Scope"`
+}
+
+// Volume describes a volume as reported by a volume plugin.
+type Volume struct {
+	// Name is the name of the volume.
+	Name string `json:"Name"`
+	// Mountpoint is the path on the host the volume is mounted at, if it is
+	// mounted.
+	Mountpoint string `json:"Mountpoint,omitempty"`
+	// Status is the plugin-specific status of the volume.
+	Status map[string]interface{} `json:"Status,omitempty"`
+}
+
+type activateResponse struct {
+	Implements []string `json:"Implements"`
+}
+
+type volumeRequest struct {
+	Name string            `json:"Name"`
+	ID   string            `json:"ID,omitempty"`
+	Opts map[string]string `json:"Opts,omitempty"`
+}
+
+type volumeResponse struct {
+	Mountpoint   string             `json:"Mountpoint,omitempty"`
+	Volume       *Volume            `json:"Volume,omitempty"`
+	Capabilities VolumeCapabilities `json:"Capabilities,omitempty"`
+	Err          string             `json:"Err,omitempty"`
+}
+
+// GetVolumePlugin returns a client of the volume plugin with the given name,
+// listening on the given socket. The plugin is activated the first time it is
+// requested, and an error is returned if it is not a volume plugin.
+func GetVolumePlugin(name, socketPath string) (*VolumePlugin, error) {
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+
+	if plugin, ok := plugins[name]; ok && plugin.SocketPath == socketPath {
+		return plugin, nil
+	}
+
+	plugin := &VolumePlugin{
+		Name:       name,
+		SocketPath: socketPath,
+		client: &http.Client{
+			Timeout: pluginTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+
+	var resp activateResponse
+	if err := plugin.call(activatePath, nil, &resp); err != nil {
+		return nil, errors.Wrapf(err, "error activating volume plugin %s", name)
+	}
+	implementsVolumes := false
+	for _, implements := range resp.Implements {
+		if implements == volumePluginType {
+			implementsVolumes = true
+			break
+		}
+	}
+	if !implementsVolumes {
+		return nil, errors.Wrapf(ErrNotVolumePlugin, "plugin %s implements %v", name, resp.Implements)
+	}
+	logrus.Debugf("Activated volume plugin %s at %s", name, socketPath)
+
+	plugins[name] = plugin
+	return plugin, nil
+}
+
+// Capabilities returns the capabilities of the plugin.
+func (p *VolumePlugin) Capabilities() (VolumeCapabilities, error) {
+	resp, err := p.volumeCall(capabilitiesPath, nil)
+	if err != nil {
+		return VolumeCapabilities{}, err
+	}
+	return resp.Capabilities, nil
+}
+
+// CreateVolume creates a volume with the given name and options.
+func (p *VolumePlugin) CreateVolume(name string, options map[string]string) error {
+	_, err := p.volumeCall(createPath, &volumeRequest{Name: name, Opts: options})
+	return err
+}
+
+// RemoveVolume removes the volume with the given name.
+func (p *VolumePlugin) RemoveVolume(name string) error {
+	_, err := p.volumeCall(removePath, &volumeRequest{Name: name})
+	return err
+}
+
+// MountVolume mounts the volume with the given name for the caller with the
+// given ID, and returns the path on the host it is mounted at.
+// The plugin is asked to mount the volume for each caller, and is responsible
+// for only mounting it once.
+func (p *VolumePlugin) MountVolume(name, id string) (string, error) {
+	resp, err := p.volumeCall(mountPath, &volumeRequest{Name: name, ID: id})
+	if err != nil {
+		return "", err
+	}
+	if resp.Mountpoint == "" {
+		return "", errors.Errorf("volume plugin %s did not return a mount point for volume %s", p.Name, name)
+	}
+	return resp.Mountpoint, nil
+}
+
+// UnmountVolume releases the mount of the volume with the given name made
+// for the caller with the given ID.
+func (p *VolumePlugin) UnmountVolume(name, id string) error {
+	_, err := p.volumeCall(unmountPath, &volumeRequest{Name: name, ID: id})
+	return err
+}
+
+// GetVolume retrieves the volume with the given name.
+func (p *VolumePlugin) GetVolume(name string) (*Volume, error) {
+	resp, err := p.volumeCall(getPath, &volumeRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Volume == nil {
+		return nil, errors.Errorf("volume plugin %s did not return volume %s", p.Name, name)
+	}
+	return resp.Volume, nil
+}
+
+// volumeCall makes a volume API request, and turns an error reported by the
+// plugin into a Go error.
+func (p *VolumePlugin) volumeCall(path string, req *volumeRequest) (*volumeResponse, error) {
+	resp := new(volumeResponse)
+	var body interface{}
+	if req != nil {
+		body = req
+	}
+	if err := p.call(path, body, resp); err != nil {
+		return nil, err
+	}
+	if resp.Err != "" {
+		return nil, errors.Errorf("volume plugin %s: %s", p.Name, resp.Err)
+	}
+	return resp, nil
+}
+
+// call POSTs the given request to the plugin, and decodes its response into
+// resp.
+func (p *VolumePlugin) call(path string, req interface{}, resp interface{}) error {
+	var body []byte
+	if req != nil {
+		var err error
+		body, err = json.Marshal(req)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding request to %s", path)
+		}
+	}
+
+	// The host is ignored when dialing the socket
+	httpReq, err := http.NewRequest(http.MethodPost, "http://plugin"+path, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "error creating request to %s", path)
+	}
+	httpReq.Header.Set("Accept", pluginContentType)
+	httpReq.Header.Set("Content-Type", pluginContentType)
+
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return errors.Wrapf(err, "error calling %s on volume plugin %s", path, p.Name)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "error reading response to %s from volume plugin %s", path, p.Name)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		// Plugins report errors as JSON with an Err key, but fall back
+		// to the raw body if it is anything else
+		var errResp volumeResponse
+		if err := json.Unmarshal(data, &errResp); err == nil && errResp.Err != "" {
+			return errors.Errorf("volume plugin %s: %s", p.Name, errResp.Err)
+		}
+		return errors.Errorf("volume plugin %s returned status %d for %s: %s", p.Name, httpResp.StatusCode, path, bytes.TrimSpace(data))
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return errors.Wrapf(err, "error decoding response to %s from volume plugin %s", path, p.Name)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// servePlugin serves a fake volume plugin on a socket in a temporary
+// directory, and returns the socket path and a function stopping it.
+func servePlugin(t *testing.T, implements []string) (string, func()) {
+	dir, err := ioutil.TempDir("", "plugin_test_")
+	require.NoError(t, err)
+	socketPath := filepath.Join(dir, "test.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	volumes := make(map[string]bool)
+	reply := func(w http.ResponseWriter, resp interface{}) {
+		w.Header().Set("Content-Type", pluginContentType)
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(activatePath, func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]interface{}{"Implements": implements})
+	})
+	mux.HandleFunc(createPath, func(w http.ResponseWriter, r *http.Request) {
+		var req volumeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		volumes[req.Name] = true
+		reply(w, map[string]string{})
+	})
+	mux.HandleFunc(mountPath, func(w http.ResponseWriter, r *http.Request) {
+		var req volumeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if !volumes[req.Name] {
+			reply(w, map[string]string{"Err": "no such volume"})
+			return
+		}
+		reply(w, map[string]string{"Mountpoint": "/mnt/" + req.Name})
+	})
+	mux.HandleFunc(getPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		reply(w, map[string]string{"Err": "get failed"})
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener) //nolint
+	return socketPath, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestVolumePlugin(t *testing.T) {
+	socketPath, stop := servePlugin(t, []string{"VolumeDriver"})
+	defer stop()
+
+	volPlugin, err := GetVolumePlugin("test", socketPath)
+	require.NoError(t, err)
+
+	cached, err := GetVolumePlugin("test", socketPath)
+	require.NoError(t, err)
+	assert.True(t, volPlugin == cached)
+
+	require.NoError(t, volPlugin.CreateVolume("vol", map[string]string{"size": "1G"}))
+	mountPoint, err := volPlugin.MountVolume("vol", "ctr")
+	require.NoError(t, err)
+	assert.Equal(t, "/mnt/vol", mountPoint)
+
+	_, err = volPlugin.MountVolume("missing", "ctr")
+	assert.EqualError(t, err, "volume plugin test: no such volume")
+
+	_, err = volPlugin.GetVolume("vol")
+	assert.EqualError(t, err, "volume plugin test: get failed")
+}
+
+func TestGetVolumePluginNotVolumePlugin(t *testing.T) {
+	socketPath, stop := servePlugin(t, []string{"NetworkDriver"})
+	defer stop()
+
+	_, err := GetVolumePlugin("network", socketPath)
+	assert.Error(t, err)
+}
@@ -47,6 +47,26 @@ type PodConfig struct {
 	// If true, all containers joined to the pod will use the pod cgroup as
 	// their cgroup parent, and cannot set a different cgroup parent
 	UsePodCgroup bool `json:"sharesCgroup,omitempty"`
+	// ResourceLimits are the resource limits set on the pod's cgroup.
+	// They require the pod to have its own cgroup.
+	ResourceLimits *PodResourceLimits `json:"resourceLimits,omitempty"`
+
+	// RestartPolicy indicates whether the pod's containers are restarted
+	// when one of them, or the infra container, exits without being
+	// stopped. It accepts the same policies as containers.
+	RestartPolicy string `json:"restartPolicy,omitempty"`
+	// RestartRetries is the number of times the pod's containers are
+	// restarted. Used only if RestartPolicy is "on-failure"; 0 means
+	// unlimited retries.
+	RestartRetries uint `json:"restartRetries,omitempty"`
+
+	// DNSConfig is the DNS configuration written into the resolv.conf of
+	// the pod's infra container, which the pod's containers share.
+	DNSConfig *PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// Volumes are named volumes mounted into every container of the pod,
+	// including its infra container.
+	Volumes []*ContainerNamedVolume `json:"volumes,omitempty"`
 
 	// The following UsePod{kernelNamespace} indicate whether the containers
 	// in the pod will inherit the namespace from the first container in the pod.
@@ -73,6 +93,15 @@ type podState struct {
 	// InfraContainerID is the container that holds pod namespace information
 	// Most often an infra container
 	InfraContainerID string
+	// RestartCount is how many times the pod's containers were restarted
+	// by its restart policy
+	RestartCount uint `json:"restartCount,omitempty"`
+	// RestartBackoff is the delay that was applied before the last restart
+	// of the pod's containers by its restart policy
+	RestartBackoff time.Duration `json:"restartBackoff,omitempty"`
+	// LastRestart is when the pod's containers were last restarted by its
+	// restart policy
+	LastRestart time.Time `json:"lastRestart,omitempty"`
 }
 
 // PodInspect represents the data we want to display for
@@ -85,8 +114,12 @@ type PodInspect struct {
 
 // PodInspectState contains inspect data on the pod's state
 type PodInspectState struct {
-	CgroupPath       string `json:"cgroupPath"`
-	InfraContainerID string `json:"infraContainerID"`
+	CgroupPath       string    `json:"cgroupPath"`
+	InfraContainerID string    `json:"infraContainerID"`
+	InfraImage       string    `json:"infraImage,omitempty"`
+	InfraCommand     []string  `json:"infraCommand,omitempty"`
+	RestartCount     uint      `json:"restartCount,omitempty"`
+	LastRestart      time.Time `json:"lastRestart,omitempty"`
 }
 
 // PodContainerInfo keeps information on a container in a pod
@@ -99,6 +132,19 @@ type PodContainerInfo struct {
 type InfraContainerConfig struct {
 	HasInfraContainer bool                 `json:"makeInfraContainer"`
 	PortBindings      []ocicni.PortMapping `json:"infraPortBindings"`
+	DNSCache          bool                 `json:"dnsCache,omitempty"`
+	ExitCommand       []string             `json:"exitCommand,omitempty"`
+	// InfraImage is the image of the infra container. If not set, the
+	// runtime's default infra image is used.
+	InfraImage string `json:"infraImage,omitempty"`
+	// InfraCommand is the command of the infra container, overriding the
+	// entrypoint and command of its image. If not set, the image's are
+	// used, or the runtime's default infra command if it has neither.
+	InfraCommand []string `json:"infraCommand,omitempty"`
+	// ExposedPorts are ports the pod's containers listen on, which are
+	// not published on the host. Only the container port and protocol of
+	// each mapping are set.
+	ExposedPorts []ocicni.PortMapping `json:"infraExposedPorts,omitempty"`
 }
 
 // ID retrieves the pod's ID
@@ -249,6 +295,17 @@ func (p *Pod) InfraContainerID() (string, error) {
 	return p.state.InfraContainerID, nil
 }
 
+// LockHolder returns the process currently holding the pod's lock, or nil if it
+// is not held or the holder cannot be determined. It does not take the lock, so
+// it can be used to diagnose operations blocked on the pod.
+func (p *Pod) LockHolder() (*lock.Holder, error) {
+	holder, err := p.lock.Holder()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving holder of lock for pod %s", p.ID())
+	}
+	return holder, nil
+}
+
 // TODO add pod batching
 // Lock pod to avoid lock contention
 // Store and lock all containers (no RemoveContainer in batch guarantees cache will not become stale)
@@ -258,37 +315,3 @@ type PodContainerStats struct {
 	Pod            *Pod
 	ContainerStats map[string]*ContainerStats
 }
-
-// GetPodStats returns the stats for each of its containers
-func (p *Pod) GetPodStats(previousContainerStats map[string]*ContainerStats) (map[string]*ContainerStats, error) {
-	var (
-		ok       bool
-		prevStat *ContainerStats
-	)
-	p.lock.Lock()
-	defer p.lock.Unlock()
-
-	if err := p.updatePod(); err != nil {
-		return nil, err
-	}
-	containers, err := p.runtime.state.PodContainers(p)
-	if err != nil {
-		return nil, err
-	}
-	newContainerStats := make(map[string]*ContainerStats)
-	for _, c := range containers {
-		if prevStat, ok = previousContainerStats[c.ID()]; !ok {
-			prevStat = &ContainerStats{}
-		}
-		newStats, err := c.GetContainerStats(prevStat)
-		// If the container wasn't running, don't include it
-		// but also suppress the error
-		if err != nil && errors.Cause(err) != define.ErrCtrStateInvalid {
-			return nil, err
-		}
-		if err == nil {
-			newContainerStats[c.ID()] = newStats
-		}
-	}
-	return newContainerStats, nil
-}
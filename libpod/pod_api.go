@@ -2,6 +2,7 @@ package libpod
 
 import (
 	"context"
+	"runtime"
 
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/events"
@@ -24,6 +25,32 @@ import (
 // set to ErrCtrExists
 // If both error and the map are nil, all containers were started successfully
 func (p *Pod) Start(ctx context.Context) (map[string]error, error) {
+	results, err := p.StartWithResults(ctx)
+	if err != nil && results == nil {
+		return nil, err
+	}
+
+	ctrErrors := make(map[string]error)
+	for id, result := range results {
+		if result.Err != nil {
+			ctrErrors[id] = result.Err
+		}
+	}
+	if len(ctrErrors) > 0 {
+		return ctrErrors, err
+	}
+	return nil, nil
+}
+
+// StartWithResults starts all containers within a pod, like Start, and returns
+// the outcome of starting each of them.
+// Containers are started once their dependencies have started. Containers that
+// do not depend on each other are started in parallel, up to the
+// pod_start_parallelism set in libpod.conf.
+// If an error is returned with a non-nil map, some containers failed to start,
+// and their results hold the errors. If an error is returned with a nil map,
+// the pod could not be started.
+func (p *Pod) StartWithResults(ctx context.Context) (map[string]*ContainerStartResult, error) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -31,36 +58,54 @@ func (p *Pod) Start(ctx context.Context) (map[string]error, error) {
 		return nil, define.ErrPodRemoved
 	}
 
+	if err := p.applyResourceLimits(); err != nil {
+		return nil, err
+	}
+
+	// Init containers must run to completion before the other containers
+	// in the pod are started
+	if err := p.runInitContainers(ctx); err != nil {
+		return nil, err
+	}
+
 	allCtrs, err := p.runtime.state.PodContainers(p)
 	if err != nil {
 		return nil, err
 	}
+	ctrs := make([]*Container, 0, len(allCtrs))
+	for _, ctr := range allCtrs {
+		if !ctr.IsInitCtr() {
+			ctrs = append(ctrs, ctr)
+		}
+	}
 
 	// Build a dependency graph of containers in the pod
-	graph, err := BuildContainerGraph(allCtrs)
+	graph, err := BuildContainerGraph(ctrs)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error generating dependency graph for pod %s", p.ID())
 	}
 
-	ctrErrors := make(map[string]error)
-	ctrsVisited := make(map[string]bool)
-
 	// If there are no containers without dependencies, we can't start
 	// Error out
 	if len(graph.noDepNodes) == 0 {
 		return nil, errors.Wrapf(define.ErrNoSuchCtr, "no containers in pod %s have no dependencies, cannot start pod", p.ID())
 	}
 
-	// Traverse the graph beginning at nodes with no dependencies
-	for _, node := range graph.noDepNodes {
-		startNode(ctx, node, false, ctrErrors, ctrsVisited, false)
+	parallelism := int(p.runtime.config.PodStartParallelism)
+	if parallelism == 0 {
+		parallelism = runtime.NumCPU()
 	}
+	results := startGraphParallel(ctx, graph, parallelism, func(ctx context.Context, node *containerNode) error {
+		return startGraphNode(ctx, node, false)
+	})
 
-	if len(ctrErrors) > 0 {
-		return ctrErrors, errors.Wrapf(define.ErrCtrExists, "error starting some containers")
+	for _, result := range results {
+		if result.Err != nil {
+			return results, errors.Wrapf(define.ErrCtrExists, "error starting some containers")
+		}
 	}
 	defer p.newPodEvent(events.Start)
-	return nil, nil
+	return results, nil
 }
 
 // Stop stops all containers within a pod without a timeout.  It assumes -1 for
@@ -104,7 +149,10 @@ func (p *Pod) StopWithTimeout(ctx context.Context, cleanup bool, timeout int) (m
 
 	// Stop to all containers
 	for _, ctr := range allCtrs {
-		ctr.lock.Lock()
+		if err := p.lockContainer(ctr); err != nil {
+			ctrErrors[ctr.ID()] = err
+			continue
+		}
 
 		if err := ctr.syncContainer(); err != nil {
 			ctr.lock.Unlock()
@@ -146,8 +194,10 @@ func (p *Pod) StopWithTimeout(ctx context.Context, cleanup bool, timeout int) (m
 // Pause pauses all containers within a pod that are running.
 // Only running containers will be paused. Paused, stopped, or created
 // containers will be ignored.
-// All containers are paused independently. An error pausing one container
-// will not prevent other containers being paused.
+// If the pod has its own cgroup, it is frozen as a whole, so every process in
+// the pod is suspended at the same instant. Otherwise, containers are paused
+// independently, and an error pausing one container will not prevent other
+// containers being paused.
 // An error and a map[string]error are returned
 // If the error is not nil and the map is nil, an error was encountered before
 // any containers were paused
@@ -168,31 +218,50 @@ func (p *Pod) Pause() (map[string]error, error) {
 		return nil, err
 	}
 
+	// Hold every container's lock until we are done, so no container can
+	// change state while the pod is being frozen
+	for _, ctr := range allCtrs {
+		if err := p.lockContainer(ctr); err != nil {
+			return nil, err
+		}
+		defer ctr.lock.Unlock()
+	}
+
 	ctrErrors := make(map[string]error)
 
-	// Pause to all containers
+	running := make([]*Container, 0, len(allCtrs))
 	for _, ctr := range allCtrs {
-		ctr.lock.Lock()
-
 		if err := ctr.syncContainer(); err != nil {
-			ctr.lock.Unlock()
 			ctrErrors[ctr.ID()] = err
 			continue
 		}
 
 		// Ignore containers that are not running
 		if ctr.state.State != define.ContainerStateRunning {
-			ctr.lock.Unlock()
 			continue
 		}
+		running = append(running, ctr)
+	}
 
-		if err := ctr.pause(); err != nil {
-			ctr.lock.Unlock()
-			ctrErrors[ctr.ID()] = err
-			continue
+	if p.canFreeze() && len(running) > 0 {
+		if err := p.setFrozen(true); err != nil {
+			return nil, err
+		}
+		for _, ctr := range running {
+			if err := ctr.setState(define.ContainerStatePaused); err != nil {
+				ctrErrors[ctr.ID()] = err
+				continue
+			}
+			if err := ctr.save(); err != nil {
+				ctrErrors[ctr.ID()] = err
+			}
+		}
+	} else {
+		for _, ctr := range running {
+			if err := ctr.pause(); err != nil {
+				ctrErrors[ctr.ID()] = err
+			}
 		}
-
-		ctr.lock.Unlock()
 	}
 
 	if len(ctrErrors) > 0 {
@@ -205,8 +274,11 @@ func (p *Pod) Pause() (map[string]error, error) {
 // Unpause unpauses all containers within a pod that are running.
 // Only paused containers will be unpaused. Running, stopped, or created
 // containers will be ignored.
-// All containers are unpaused independently. An error unpausing one container
-// will not prevent other containers being unpaused.
+// If the pod has its own cgroup, it is thawed first, resuming the containers
+// frozen by Pause() at the same instant. Any containers that remain paused,
+// such as those paused individually, are then unpaused independently. An
+// error unpausing one container will not prevent other containers being
+// unpaused.
 // An error and a map[string]error are returned
 // If the error is not nil and the map is nil, an error was encountered before
 // any containers were unpaused
@@ -227,38 +299,233 @@ func (p *Pod) Unpause() (map[string]error, error) {
 		return nil, err
 	}
 
+	for _, ctr := range allCtrs {
+		if err := p.lockContainer(ctr); err != nil {
+			return nil, err
+		}
+		defer ctr.lock.Unlock()
+	}
+
+	// Only thaw the pod if it may have been frozen. The cgroup may not
+	// exist yet if none of the pod's containers have run.
+	if p.canFreeze() {
+		frozen := false
+		for _, ctr := range allCtrs {
+			if err := p.runtime.state.UpdateContainer(ctr); err != nil {
+				return nil, err
+			}
+			if ctr.state.State == define.ContainerStatePaused {
+				frozen = true
+			}
+		}
+		if frozen {
+			if err := p.setFrozen(false); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	ctrErrors := make(map[string]error)
 
-	// Pause to all containers
 	for _, ctr := range allCtrs {
-		ctr.lock.Lock()
-
+		// Syncing picks up containers resumed by thawing the pod
 		if err := ctr.syncContainer(); err != nil {
-			ctr.lock.Unlock()
 			ctrErrors[ctr.ID()] = err
 			continue
 		}
 
 		// Ignore containers that are not paused
 		if ctr.state.State != define.ContainerStatePaused {
-			ctr.lock.Unlock()
 			continue
 		}
 
 		if err := ctr.unpause(); err != nil {
+			ctrErrors[ctr.ID()] = err
+		}
+	}
+
+	if len(ctrErrors) > 0 {
+		return ctrErrors, errors.Wrapf(define.ErrCtrExists, "error unpausing some containers")
+	}
+
+	defer p.newPodEvent(events.Unpause)
+	return nil, nil
+}
+
+// Checkpoint checkpoints all running containers within a pod.
+// Each container is frozen by the OCI runtime while it is checkpointed, and
+// left running until every container has been checkpointed. Containers are
+// checkpointed before the containers whose namespaces they share, so the infra
+// container is checkpointed last.
+// Unless options.KeepRunning is set, the containers are then stopped, and can
+// be restored with Restore(). If the pod has its own cgroup, it is frozen while
+// the checkpointed processes are killed, so none of them runs on alone.
+// Checkpoints of pods cannot be exported, so options.TargetFile and
+// options.Name must not be set.
+// An error and a map[string]error are returned
+// If the error is not nil and the map is nil, an error was encountered before
+// any containers were checkpointed
+// If map is not nil, an error was encountered when checkpointing one or more
+// containers. The container ID is mapped to the error encountered. The error is
+// set to ErrCtrExists
+// If both error and the map are nil, all containers were checkpointed without
+// error
+func (p *Pod) Checkpoint(ctx context.Context, options ContainerCheckpointOptions) (map[string]error, error) {
+	if options.TargetFile != "" || options.Name != "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "checkpoints of pods cannot be exported")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.valid {
+		return nil, define.ErrPodRemoved
+	}
+
+	ctrs, err := p.dependencyOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold every container's lock until we are done, so no container can
+	// change state between its checkpoint and its stop
+	for _, ctr := range ctrs {
+		if err := p.lockContainer(ctr); err != nil {
+			return nil, err
+		}
+		defer ctr.lock.Unlock()
+	}
+
+	ctrErrors := make(map[string]error)
+
+	// Containers sharing the namespaces of other containers go first
+	running := make([]*Container, 0, len(ctrs))
+	for i := len(ctrs) - 1; i >= 0; i-- {
+		ctr := ctrs[i]
+		if err := ctr.syncContainer(); err != nil {
+			ctrErrors[ctr.ID()] = err
+			continue
+		}
+
+		// Ignore containers that are not running
+		if ctr.state.State != define.ContainerStateRunning {
+			continue
+		}
+		running = append(running, ctr)
+	}
+
+	// The containers are left running by the checkpoint itself, and
+	// stopped once all of them have been checkpointed. The pod must not be
+	// frozen yet, as frozen containers appear paused and cannot be
+	// checkpointed.
+	leaveRunning := options
+	leaveRunning.KeepRunning = true
+	checkpointed := make([]*Container, 0, len(running))
+	for _, ctr := range running {
+		if err := ctr.checkpoint(ctx, leaveRunning); err != nil {
+			ctrErrors[ctr.ID()] = err
+			continue
+		}
+		ctr.newContainerEvent(events.Checkpoint)
+		checkpointed = append(checkpointed, ctr)
+	}
+
+	if !options.KeepRunning && len(checkpointed) > 0 {
+		if err := p.killCheckpointed(checkpointed); err != nil {
+			return nil, err
+		}
+	}
+
+	if !options.KeepRunning {
+		for _, ctr := range checkpointed {
+			if err := ctr.stopCheckpointed(ctx); err != nil {
+				ctrErrors[ctr.ID()] = err
+			}
+		}
+	}
+
+	if len(ctrErrors) > 0 {
+		return ctrErrors, errors.Wrapf(define.ErrCtrExists, "error checkpointing some containers")
+	}
+	defer p.newPodEvent(events.Checkpoint)
+	return nil, nil
+}
+
+// Restore restores all containers within a pod that were stopped by
+// Checkpoint(). Containers owning namespaces shared with other containers,
+// such as the infra container, are restored first, so the containers sharing
+// them join the restored namespaces. Containers whose dependencies could not
+// be restored are not restored either.
+// Checkpoints of pods cannot be imported, so options.TargetFile and
+// options.Name must not be set.
+// An error and a map[string]error are returned
+// If the error is not nil and the map is nil, an error was encountered before
+// any containers were restored
+// If map is not nil, an error was encountered when restoring one or more
+// containers. The container ID is mapped to the error encountered. The error is
+// set to ErrCtrExists
+// If both error and the map are nil, all containers were restored without error
+func (p *Pod) Restore(ctx context.Context, options ContainerCheckpointOptions) (map[string]error, error) {
+	if options.TargetFile != "" || options.Name != "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "checkpoints of pods cannot be imported")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.valid {
+		return nil, define.ErrPodRemoved
+	}
+
+	ctrs, err := p.dependencyOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	ctrErrors := make(map[string]error)
+
+	for _, ctr := range ctrs {
+		failedDep := ""
+		for _, dep := range ctr.Dependencies() {
+			if _, failed := ctrErrors[dep]; failed {
+				failedDep = dep
+				break
+			}
+		}
+		if failedDep != "" {
+			ctrErrors[ctr.ID()] = errors.Wrapf(define.ErrCtrStateInvalid, "dependency %s of container %s could not be restored", failedDep, ctr.ID())
+			continue
+		}
+
+		if err := p.lockContainer(ctr); err != nil {
+			ctrErrors[ctr.ID()] = err
+			continue
+		}
+		if err := ctr.syncContainer(); err != nil {
 			ctr.lock.Unlock()
 			ctrErrors[ctr.ID()] = err
 			continue
 		}
 
+		// Ignore containers that were not checkpointed
+		if !ctr.state.Checkpointed {
+			ctr.lock.Unlock()
+			continue
+		}
+
+		if err := ctr.restore(ctx, options); err != nil {
+			ctr.lock.Unlock()
+			ctrErrors[ctr.ID()] = err
+			continue
+		}
+		ctr.newContainerEvent(events.Restore)
 		ctr.lock.Unlock()
 	}
 
 	if len(ctrErrors) > 0 {
-		return ctrErrors, errors.Wrapf(define.ErrCtrExists, "error unpausing some containers")
+		return ctrErrors, errors.Wrapf(define.ErrCtrExists, "error restoring some containers")
 	}
-
-	defer p.newPodEvent(events.Unpause)
+	defer p.newPodEvent(events.Restore)
 	return nil, nil
 }
 
@@ -344,7 +611,10 @@ func (p *Pod) Kill(signal uint) (map[string]error, error) {
 
 	// Send a signal to all containers
 	for _, ctr := range allCtrs {
-		ctr.lock.Lock()
+		if err := p.lockContainer(ctr); err != nil {
+			ctrErrors[ctr.ID()] = err
+			continue
+		}
 
 		if err := ctr.syncContainer(); err != nil {
 			ctr.lock.Unlock()
@@ -358,7 +628,7 @@ func (p *Pod) Kill(signal uint) (map[string]error, error) {
 			continue
 		}
 
-		if err := ctr.ociRuntime.killContainer(ctr, signal); err != nil {
+		if err := ctr.ociRuntime.KillContainer(ctr, signal, false); err != nil {
 			ctr.lock.Unlock()
 			ctrErrors[ctr.ID()] = err
 			continue
@@ -398,7 +668,9 @@ func (p *Pod) Status() (map[string]define.ContainerStatus, error) {
 
 	// We need to lock all the containers
 	for _, ctr := range allCtrs {
-		ctr.lock.Lock()
+		if err := p.lockContainer(ctr); err != nil {
+			return nil, err
+		}
 		defer ctr.lock.Unlock()
 	}
 
@@ -446,6 +718,20 @@ func (p *Pod) Inspect() (*PodInspect, error) {
 		podContainers = append(podContainers, pc)
 	}
 	infraContainerID := p.state.InfraContainerID
+	var (
+		infraImage   string
+		infraCommand []string
+	)
+	if infraContainerID != "" {
+		infra, err := p.runtime.state.Container(infraContainerID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error retrieving infra container of pod %s", p.ID())
+		}
+		infraImage = infra.config.RootfsImageName
+		if infra.config.Spec.Process != nil {
+			infraCommand = infra.config.Spec.Process.Args
+		}
+	}
 
 	config := new(PodConfig)
 	if err := JSONDeepCopy(p.config, config); err != nil {
@@ -456,6 +742,10 @@ func (p *Pod) Inspect() (*PodInspect, error) {
 		State: &PodInspectState{
 			CgroupPath:       p.state.CgroupPath,
 			InfraContainerID: infraContainerID,
+			InfraImage:       infraImage,
+			InfraCommand:     infraCommand,
+			RestartCount:     p.state.RestartCount,
+			LastRestart:      p.state.LastRestart,
 		},
 		Containers: podContainers,
 	}
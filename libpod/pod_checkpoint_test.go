@@ -0,0 +1,164 @@
+package libpod
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/criu"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkpointOCIRuntime is an OCI runtime able to checkpoint, recording the
+// checkpointed and killed containers. Other operations are not supported.
+type checkpointOCIRuntime struct {
+	OCIRuntime
+	calls []string
+}
+
+func (r *checkpointOCIRuntime) Features() []string {
+	return []string{OCIFeatureCheckpoint}
+}
+
+func (r *checkpointOCIRuntime) UpdateContainerStatus(ctr *Container, useRuntime bool) error {
+	return nil
+}
+
+func (r *checkpointOCIRuntime) CheckpointContainer(ctr *Container, options ContainerCheckpointOptions) error {
+	if ctr.state.State != define.ContainerStateRunning {
+		return errors.Wrapf(define.ErrCtrStateInvalid, "container %s is %s", ctr.ID(), ctr.state.State)
+	}
+	r.calls = append(r.calls, "checkpoint "+ctr.ID())
+	return nil
+}
+
+func (r *checkpointOCIRuntime) KillContainer(ctr *Container, signal uint, all bool) error {
+	r.calls = append(r.calls, fmt.Sprintf("kill %s %d %t", ctr.ID(), signal, all))
+	return nil
+}
+
+// getTestPodWithCtrs returns a pod holding two containers, the second sharing
+// the network namespace of the first, in the given state
+func getTestPodWithCtrs(t *testing.T, ociRuntime OCIRuntime, ctrState define.ContainerStatus) (*Pod, *Container, *Container, func()) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	runtime := &Runtime{
+		config:  &RuntimeConfig{},
+		state:   state,
+		eventer: &recordingEventer{},
+	}
+
+	pod, err := getTestPodN("9", manager)
+	require.NoError(t, err)
+	pod.state.CgroupPath = ""
+	pod.runtime = runtime
+	require.NoError(t, state.AddPod(pod))
+
+	infra, err := getTestCtr1(manager)
+	require.NoError(t, err)
+	ctr, err := getTestCtr2(manager)
+	require.NoError(t, err)
+	ctr.config.NetNsCtr = infra.ID()
+	for _, c := range []*Container{infra, ctr} {
+		c.config.Pod = pod.ID()
+		c.config.StaticDir = path
+		c.state.State = ctrState
+		c.runtime = runtime
+		c.ociRuntime = ociRuntime
+		require.NoError(t, state.AddContainerToPod(pod, c))
+	}
+
+	return pod, infra, ctr, func() { os.RemoveAll(path) }
+}
+
+func TestPodCheckpointRejectsExports(t *testing.T) {
+	pod := &Pod{config: &PodConfig{ID: "pod"}}
+
+	_, err := pod.Checkpoint(context.Background(), ContainerCheckpointOptions{TargetFile: "/tmp/pod.tar.gz"})
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+	_, err = pod.Restore(context.Background(), ContainerCheckpointOptions{Name: "restored"})
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
+
+func TestPodCheckpointRemovedPod(t *testing.T) {
+	pod, _, _, cleanup := getTestPodWithCtrs(t, &checkpointOCIRuntime{}, define.ContainerStateExited)
+	defer cleanup()
+	pod.valid = false
+
+	_, err := pod.Checkpoint(context.Background(), ContainerCheckpointOptions{})
+	assert.Equal(t, define.ErrPodRemoved, errors.Cause(err))
+	_, err = pod.Restore(context.Background(), ContainerCheckpointOptions{})
+	assert.Equal(t, define.ErrPodRemoved, errors.Cause(err))
+}
+
+func TestPodCheckpointSkipsStoppedContainers(t *testing.T) {
+	ociRuntime := &checkpointOCIRuntime{}
+	pod, infra, ctr, cleanup := getTestPodWithCtrs(t, ociRuntime, define.ContainerStateExited)
+	defer cleanup()
+
+	ctrErrors, err := pod.Checkpoint(context.Background(), ContainerCheckpointOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, ctrErrors)
+	assert.Empty(t, ociRuntime.calls)
+	assert.False(t, infra.state.Checkpointed)
+	assert.False(t, ctr.state.Checkpointed)
+}
+
+func TestPodCheckpointRunningContainers(t *testing.T) {
+	if !criu.CheckForCriu() {
+		t.Skip("CRIU is not installed")
+	}
+	ociRuntime := &checkpointOCIRuntime{}
+	pod, infra, ctr, cleanup := getTestPodWithCtrs(t, ociRuntime, define.ContainerStateRunning)
+	defer cleanup()
+
+	// The containers are checkpointed while running, the infra container
+	// last
+	ctrErrors, err := pod.Checkpoint(context.Background(), ContainerCheckpointOptions{KeepRunning: true})
+	require.NoError(t, err)
+	assert.Empty(t, ctrErrors)
+	assert.Equal(t, []string{"checkpoint " + ctr.ID(), "checkpoint " + infra.ID()}, ociRuntime.calls)
+	assert.Equal(t, define.ContainerStateRunning, infra.state.State)
+	assert.False(t, infra.state.CheckpointedTime.IsZero())
+}
+
+func TestPodKillCheckpointed(t *testing.T) {
+	ociRuntime := &checkpointOCIRuntime{}
+	pod, infra, ctr, cleanup := getTestPodWithCtrs(t, ociRuntime, define.ContainerStateRunning)
+	defer cleanup()
+
+	require.NoError(t, pod.killCheckpointed([]*Container{ctr, infra}))
+	assert.Equal(t, []string{
+		fmt.Sprintf("kill %s %d true", ctr.ID(), syscall.SIGKILL),
+		fmt.Sprintf("kill %s %d true", infra.ID(), syscall.SIGKILL),
+	}, ociRuntime.calls)
+}
+
+func TestPodRestore(t *testing.T) {
+	ociRuntime := &checkpointOCIRuntime{}
+	pod, infra, ctr, cleanup := getTestPodWithCtrs(t, ociRuntime, define.ContainerStateExited)
+	defer cleanup()
+
+	// Containers that were not checkpointed are left alone
+	ctrErrors, err := pod.Restore(context.Background(), ContainerCheckpointOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, ctrErrors)
+
+	// The infra container cannot be restored, as it is not stopped, so the
+	// container sharing its namespace is not restored either
+	infra.state.Checkpointed = true
+	infra.state.State = define.ContainerStatePaused
+	ctr.state.Checkpointed = true
+	ctrErrors, err = pod.Restore(context.Background(), ContainerCheckpointOptions{})
+	assert.Equal(t, define.ErrCtrExists, errors.Cause(err))
+	require.Len(t, ctrErrors, 2)
+	assert.Error(t, ctrErrors[infra.ID()])
+	assert.Equal(t, define.ErrCtrStateInvalid, errors.Cause(ctrErrors[ctr.ID()]))
+	assert.Contains(t, ctrErrors[ctr.ID()].Error(), "dependency "+infra.ID())
+	assert.Empty(t, ociRuntime.calls)
+}
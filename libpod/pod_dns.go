@@ -0,0 +1,106 @@
+package libpod
+
+import (
+	"net"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// PodDNSPolicyDefault merges the pod's DNS configuration into the
+	// resolv.conf the pod would otherwise use.
+	PodDNSPolicyDefault = "Default"
+	// PodDNSPolicyNone ignores the host's resolv.conf, so the pod only
+	// uses the DNS configuration it is given.
+	PodDNSPolicyNone = "None"
+)
+
+// PodDNSConfig is the DNS configuration of a pod, written into the resolv.conf
+// shared by the containers of the pod through its infra container.
+type PodDNSConfig struct {
+	// Policy is how the configuration is combined with the resolv.conf
+	// the pod would otherwise use. Defaults to PodDNSPolicyDefault.
+	Policy string `json:"policy,omitempty"`
+	// Nameservers are added after the pod's other nameservers.
+	Nameservers []string `json:"nameservers,omitempty"`
+	// Searches are added after the pod's other search domains.
+	Searches []string `json:"searches,omitempty"`
+	// Options are added to the pod's other resolver options, replacing
+	// options of the same name. They are given as name or name:value.
+	Options []string `json:"options,omitempty"`
+}
+
+// validate checks the policy and nameservers of the configuration
+func (d *PodDNSConfig) validate() error {
+	switch d.Policy {
+	case "", PodDNSPolicyDefault, PodDNSPolicyNone:
+	default:
+		return errors.Wrapf(define.ErrInvalidArg, "invalid pod DNS policy %q, must be %q or %q", d.Policy, PodDNSPolicyDefault, PodDNSPolicyNone)
+	}
+	for _, server := range d.Nameservers {
+		if net.ParseIP(server) == nil {
+			return errors.Wrapf(define.ErrInvalidArg, "invalid pod nameserver %q", server)
+		}
+	}
+	for _, option := range d.Options {
+		if option == "" || strings.HasPrefix(option, ":") {
+			return errors.Wrapf(define.ErrInvalidArg, "invalid pod DNS option %q", option)
+		}
+	}
+	return nil
+}
+
+// apply returns the nameservers, search domains and options of a resolv.conf
+// after applying the configuration to the given ones
+func (d *PodDNSConfig) apply(nameservers, searches, options []string) ([]string, []string, []string) {
+	if d.Policy == PodDNSPolicyNone {
+		nameservers, searches, options = nil, nil, nil
+	}
+	nameservers = appendUnique(nameservers, d.Nameservers)
+	searches = appendUnique(searches, d.Searches)
+
+	optionName := func(option string) string {
+		return strings.SplitN(option, ":", 2)[0]
+	}
+	merged := make([]string, 0, len(options)+len(d.Options))
+	for _, option := range options {
+		overridden := false
+		for _, podOption := range d.Options {
+			if optionName(podOption) == optionName(option) {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, option)
+		}
+	}
+	merged = appendUnique(merged, d.Options)
+	return nameservers, searches, merged
+}
+
+// appendUnique appends the values not already in the slice
+func appendUnique(slice, values []string) []string {
+	for _, value := range values {
+		if !util.StringInSlice(value, slice) {
+			slice = append(slice, value)
+		}
+	}
+	return slice
+}
+
+// DNSConfig returns the DNS configuration of the pod, or nil if it has none
+func (p *Pod) DNSConfig() *PodDNSConfig {
+	if p.config.DNSConfig == nil {
+		return nil
+	}
+	dns := new(PodDNSConfig)
+	*dns = *p.config.DNSConfig
+	dns.Nameservers = append([]string{}, p.config.DNSConfig.Nameservers...)
+	dns.Searches = append([]string{}, p.config.DNSConfig.Searches...)
+	dns.Options = append([]string{}, p.config.DNSConfig.Options...)
+	return dns
+}
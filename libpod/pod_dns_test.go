@@ -0,0 +1,41 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodDNSConfigValidate(t *testing.T) {
+	dns := PodDNSConfig{Nameservers: []string{"1.1.1.1", "fd00::1"}, Options: []string{"ndots:2", "edns0"}}
+	assert.NoError(t, dns.validate())
+
+	for _, bad := range []PodDNSConfig{
+		{Policy: "ClusterFirst"},
+		{Nameservers: []string{"dns.example.com"}},
+		{Options: []string{":2"}},
+	} {
+		err := bad.validate()
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(err), "%+v", bad)
+	}
+}
+
+func TestPodDNSConfigApply(t *testing.T) {
+	dns := PodDNSConfig{
+		Nameservers: []string{"10.0.0.10", "1.1.1.1"},
+		Searches:    []string{"svc.example.com"},
+		Options:     []string{"ndots:2", "edns0"},
+	}
+	nameservers, searches, options := dns.apply([]string{"1.1.1.1"}, []string{"example.com"}, []string{"ndots:5", "timeout:1"})
+	assert.Equal(t, []string{"1.1.1.1", "10.0.0.10"}, nameservers)
+	assert.Equal(t, []string{"example.com", "svc.example.com"}, searches)
+	assert.Equal(t, []string{"timeout:1", "ndots:2", "edns0"}, options)
+
+	dns.Policy = PodDNSPolicyNone
+	nameservers, searches, options = dns.apply([]string{"1.1.1.1"}, []string{"example.com"}, []string{"ndots:5", "timeout:1"})
+	assert.Equal(t, []string{"10.0.0.10", "1.1.1.1"}, nameservers)
+	assert.Equal(t, []string{"svc.example.com"}, searches)
+	assert.Equal(t, []string{"ndots:2", "edns0"}, options)
+}
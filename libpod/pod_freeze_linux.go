@@ -0,0 +1,38 @@
+// +build linux
+
+package libpod
+
+import (
+	"github.com/containers/libpod/pkg/cgroups"
+	"github.com/pkg/errors"
+)
+
+// canFreeze returns whether the pod has a cgroup of its own that can be
+// frozen. Rootless users cannot manage cgroups v1.
+func (p *Pod) canFreeze() bool {
+	if p.state.CgroupPath == "" {
+		return false
+	}
+	noCgroups, err := noRootlessCgroups()
+	return err == nil && !noCgroups
+}
+
+// setFrozen freezes or thaws the pod's cgroup, suspending or resuming every
+// process in the pod at once. This includes the pod's conmon processes.
+func (p *Pod) setFrozen(frozen bool) error {
+	cgroupPath := p.runtime.getCgroupManager().podCgroup(p)
+	control, err := cgroups.Load(cgroupPath)
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving cgroup %s of pod %s", cgroupPath, p.ID())
+	}
+	if frozen {
+		if err := control.Freeze(); err != nil {
+			return errors.Wrapf(err, "error freezing pod %s", p.ID())
+		}
+		return nil
+	}
+	if err := control.Thaw(); err != nil {
+		return errors.Wrapf(err, "error thawing pod %s", p.ID())
+	}
+	return nil
+}
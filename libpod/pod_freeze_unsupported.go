@@ -0,0 +1,13 @@
+// +build !linux
+
+package libpod
+
+import "github.com/containers/libpod/libpod/define"
+
+func (p *Pod) canFreeze() bool {
+	return false
+}
+
+func (p *Pod) setFrozen(frozen bool) error {
+	return define.ErrNotImplemented
+}
@@ -1,11 +1,14 @@
 package libpod
 
 import (
+	"context"
 	"fmt"
-	"path/filepath"
+	"sort"
+	"syscall"
 	"time"
 
 	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/lock"
 	"github.com/containers/storage/pkg/stringid"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -43,6 +46,36 @@ func (p *Pod) save() error {
 	return nil
 }
 
+// lockContainer acquires the lock of one of the pod's containers while the pod
+// is locked. Rather than hang against an operation acquiring the locks in the
+// opposite order, it gives up after lock.DeadlockTimeout, returning an error
+// wrapping define.ErrDeadlock.
+func (p *Pod) lockContainer(ctr *Container) error {
+	err := ctr.lock.TryLockWithTimeout(lock.DeadlockTimeout)
+	if err == nil || errors.Cause(err) != define.ErrLockTimeout {
+		return err
+	}
+	msg := fmt.Sprintf("could not acquire lock for container %s within %s while holding lock for pod %s", ctr.ID(), lock.DeadlockTimeout, p.ID())
+	if holder, err := ctr.lock.Holder(); err == nil && holder != nil {
+		msg = fmt.Sprintf("%s - lock is held by %s", msg, holder)
+	}
+	return errors.Wrapf(define.ErrDeadlock, "%s", msg)
+}
+
+// dependencyOrder returns the containers of the pod ordered so that every
+// container comes after the containers it depends on
+func (p *Pod) dependencyOrder() ([]*Container, error) {
+	allCtrs, err := p.runtime.state.PodContainers(p)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := BuildContainerGraph(allCtrs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error generating dependency graph for pod %s", p.ID())
+	}
+	return graph.dependencyOrder(), nil
+}
+
 // Refresh a pod's state after restart
 // This cannot lock any other pod, but may lock individual containers, as those
 // will have refreshed by the time pod refresh runs.
@@ -61,26 +94,137 @@ func (p *Pod) refresh() error {
 	if err != nil {
 		return errors.Wrapf(err, "error retrieving lock %d for pod %s", p.config.LockID, p.ID())
 	}
-	p.lock = lock
+	p.lock = podLocker(lock, p.ID())
 
 	// We need to recreate the pod's cgroup
 	if p.config.UsePodCgroup {
-		switch p.runtime.config.CgroupManager {
-		case SystemdCgroupsManager:
-			cgroupPath, err := systemdSliceFromPath(p.config.CgroupParent, fmt.Sprintf("libpod_pod_%s", p.ID()))
+		cgroupPath, err := p.runtime.getCgroupManager().createPodCgroup(p)
+		if err != nil {
+			logrus.Errorf("Error creating CGroup for pod %s: %v", p.ID(), err)
+		}
+		p.state.CgroupPath = cgroupPath
+
+		logrus.Debugf("setting pod cgroup to %s", p.state.CgroupPath)
+	}
+
+	// Save changes
+	return p.save()
+}
+
+// runInitContainers runs the init containers of the pod to completion, one at a
+// time and in dependency order. The infra container is started first, as init
+// containers join its namespaces. One-shot init containers that have already
+// exited successfully are not run again.
+// An error is returned if any init container exits with a non-zero exit code.
+// The pod must be locked.
+func (p *Pod) runInitContainers(ctx context.Context) error {
+	initCtrs, err := p.runtime.state.PodInitContainers(p)
+	if err != nil {
+		return err
+	}
+	if len(initCtrs) == 0 {
+		return nil
+	}
+
+	if p.state.InfraContainerID != "" {
+		infra, err := p.runtime.state.Container(p.state.InfraContainerID)
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving infra container of pod %s", p.ID())
+		}
+		infraState, err := infra.State()
+		if err != nil {
+			return err
+		}
+		if infraState != define.ContainerStateRunning {
+			if err := infra.Start(ctx, false); err != nil {
+				return errors.Wrapf(err, "error starting infra container of pod %s", p.ID())
+			}
+		}
+	}
+
+	for _, ctr := range sortInitContainers(initCtrs) {
+		if ctr.InitContainerType() == define.OneShotInitContainer {
+			done, err := ctr.meetsDependencyCondition(define.DependencyConditionExitedSuccessfully)
 			if err != nil {
-				logrus.Errorf("Error creating CGroup for pod %s: %v", p.ID(), err)
+				return err
+			}
+			if done {
+				logrus.Debugf("Init container %s of pod %s has already run", ctr.ID(), p.ID())
+				continue
 			}
-			p.state.CgroupPath = cgroupPath
-		case CgroupfsCgroupsManager:
-			p.state.CgroupPath = filepath.Join(p.config.CgroupParent, p.ID())
+		}
 
-			logrus.Debugf("setting pod cgroup to %s", p.state.CgroupPath)
-		default:
-			return errors.Wrapf(define.ErrInvalidArg, "unknown cgroups manager %s specified", p.runtime.config.CgroupManager)
+		logrus.Debugf("Running init container %s of pod %s", ctr.ID(), p.ID())
+		if err := ctr.Start(ctx, false); err != nil {
+			return errors.Wrapf(err, "error starting init container %s of pod %s", ctr.ID(), p.ID())
+		}
+		exitCode, err := ctr.Wait()
+		if err != nil {
+			return errors.Wrapf(err, "error waiting for init container %s of pod %s", ctr.ID(), p.ID())
+		}
+		if exitCode != 0 {
+			return errors.Wrapf(define.ErrCtrStateInvalid, "init container %s of pod %s exited with code %d", ctr.ID(), p.ID(), exitCode)
 		}
 	}
 
-	// Save changes
-	return p.save()
+	return nil
+}
+
+// sortInitContainers orders init containers so that each runs after the init
+// containers it depends on. Otherwise, init containers run in the order they
+// were created.
+func sortInitContainers(ctrs []*Container) []*Container {
+	sort.SliceStable(ctrs, func(i, j int) bool {
+		return ctrs[i].config.CreatedTime.Before(ctrs[j].config.CreatedTime)
+	})
+
+	byID := make(map[string]*Container, len(ctrs))
+	for _, ctr := range ctrs {
+		byID[ctr.ID()] = ctr
+	}
+
+	sorted := make([]*Container, 0, len(ctrs))
+	visited := make(map[string]bool, len(ctrs))
+	var visit func(ctr *Container)
+	visit = func(ctr *Container) {
+		if visited[ctr.ID()] {
+			return
+		}
+		visited[ctr.ID()] = true
+		for _, dep := range ctr.Dependencies() {
+			if depCtr, ok := byID[dep]; ok {
+				visit(depCtr)
+			}
+		}
+		sorted = append(sorted, ctr)
+	}
+	for _, ctr := range ctrs {
+		visit(ctr)
+	}
+
+	return sorted
+}
+
+// killCheckpointed kills the processes of checkpointed containers, so they do
+// not run past their checkpoints. If the pod has its own cgroup, it is frozen
+// while the processes are killed, so they all stop at the same instant.
+func (p *Pod) killCheckpointed(ctrs []*Container) error {
+	frozen := false
+	if p.canFreeze() {
+		if err := p.setFrozen(true); err != nil {
+			return err
+		}
+		frozen = true
+	}
+
+	for _, ctr := range ctrs {
+		if err := ctr.ociRuntime.KillContainer(ctr, uint(syscall.SIGKILL), true); err != nil {
+			logrus.Errorf("Error killing container %s after checkpointing it: %v", ctr.ID(), err)
+		}
+	}
+
+	if frozen {
+		return p.setFrozen(false)
+	}
+	return nil
 }
@@ -0,0 +1,49 @@
+package libpod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPodLockContainerGivesUp(t *testing.T) {
+	manager, err := lock.NewInMemoryManager(16)
+	require.NoError(t, err)
+	pod, err := getTestPodN("1", manager)
+	require.NoError(t, err)
+	ctr, err := getTestCtrN("1", manager)
+	require.NoError(t, err)
+	pod.lock = podLocker(pod.lock, pod.ID())
+	ctr.lock = ctrLocker(ctr.lock, ctr.ID())
+
+	oldTimeout := lock.DeadlockTimeout
+	lock.DeadlockTimeout = 10 * time.Millisecond
+	defer func() { lock.DeadlockTimeout = oldTimeout }()
+
+	pod.lock.Lock()
+	defer pod.lock.Unlock()
+
+	require.NoError(t, pod.lockContainer(ctr))
+	ctr.lock.Unlock()
+
+	// Hold the container's lock elsewhere, as an operation waiting for the
+	// pod's lock would
+	locked := make(chan bool)
+	release := make(chan bool)
+	go func() {
+		ctr.lock.Lock()
+		locked <- true
+		<-release
+		ctr.lock.Unlock()
+	}()
+	<-locked
+	defer close(release)
+
+	err = pod.lockContainer(ctr)
+	assert.Equal(t, define.ErrDeadlock, errors.Cause(err))
+}
@@ -0,0 +1,122 @@
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/define"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+// PodResourceLimits are the resource limits of a pod's cgroup. They are shared
+// by all containers in the pod, and no container in the pod may be given a
+// higher limit. Limits that are 0 are not set.
+type PodResourceLimits struct {
+	// CPUQuota is the CPU time, in microseconds, the pod's containers may
+	// use together in each CPU period
+	CPUQuota int64 `json:"cpuQuota,omitempty"`
+	// CPUPeriod is the length of the CPU period, in microseconds. If it is
+	// not set but CPUQuota is, a period of 100000 is used.
+	CPUPeriod uint64 `json:"cpuPeriod,omitempty"`
+	// MemoryLimit is the memory limit of the pod, in bytes
+	MemoryLimit int64 `json:"memoryLimit,omitempty"`
+	// PidsLimit is the maximum number of processes in the pod
+	PidsLimit int64 `json:"pidsLimit,omitempty"`
+}
+
+// defaultCPUPeriod is the CPU period used when only a quota is set
+const defaultCPUPeriod = uint64(100000)
+
+// validate checks that the limits are sane
+func (l *PodResourceLimits) validate() error {
+	if l.CPUQuota < 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "pod CPU quota cannot be negative")
+	}
+	if l.CPUPeriod != 0 && (l.CPUPeriod < 1000 || l.CPUPeriod > 1000000) {
+		return errors.Wrapf(define.ErrInvalidArg, "pod CPU period must be between 1000 and 1000000 microseconds")
+	}
+	if l.CPUPeriod != 0 && l.CPUQuota == 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "pod CPU period requires a CPU quota")
+	}
+	if l.MemoryLimit < 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "pod memory limit cannot be negative")
+	}
+	if l.PidsLimit < 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "pod pids limit cannot be negative")
+	}
+	return nil
+}
+
+// cpuPeriod returns the CPU period of the limits
+func (l *PodResourceLimits) cpuPeriod() uint64 {
+	if l.CPUPeriod == 0 {
+		return defaultCPUPeriod
+	}
+	return l.CPUPeriod
+}
+
+// linuxResources returns the limits as resources to apply to the pod cgroup
+func (l *PodResourceLimits) linuxResources() *spec.LinuxResources {
+	resources := new(spec.LinuxResources)
+	if l.CPUQuota > 0 {
+		quota := l.CPUQuota
+		period := l.cpuPeriod()
+		resources.CPU = &spec.LinuxCPU{Quota: &quota, Period: &period}
+	}
+	if l.MemoryLimit > 0 {
+		limit := l.MemoryLimit
+		resources.Memory = &spec.LinuxMemory{Limit: &limit}
+	}
+	if l.PidsLimit > 0 {
+		resources.Pids = &spec.LinuxPids{Limit: l.PidsLimit}
+	}
+	return resources
+}
+
+// checkResources returns an error if the given container resources exceed
+// the limits of the pod
+func (l *PodResourceLimits) checkResources(resources *spec.LinuxResources) error {
+	if resources == nil {
+		return nil
+	}
+	if l.MemoryLimit > 0 && resources.Memory != nil && resources.Memory.Limit != nil && *resources.Memory.Limit > l.MemoryLimit {
+		return errors.Wrapf(define.ErrInvalidArg, "memory limit %d exceeds the pod memory limit %d", *resources.Memory.Limit, l.MemoryLimit)
+	}
+	if l.PidsLimit > 0 && resources.Pids != nil && resources.Pids.Limit > l.PidsLimit {
+		return errors.Wrapf(define.ErrInvalidArg, "pids limit %d exceeds the pod pids limit %d", resources.Pids.Limit, l.PidsLimit)
+	}
+	if l.CPUQuota > 0 && resources.CPU != nil && resources.CPU.Quota != nil && *resources.CPU.Quota > 0 {
+		period := defaultCPUPeriod
+		if resources.CPU.Period != nil && *resources.CPU.Period > 0 {
+			period = *resources.CPU.Period
+		}
+		// Compare quota/period ratios without losing precision
+		if uint64(*resources.CPU.Quota)*l.cpuPeriod() > uint64(l.CPUQuota)*period {
+			return errors.Wrapf(define.ErrInvalidArg, "CPU quota %d per period %d exceeds the pod CPU quota %d per period %d", *resources.CPU.Quota, period, l.CPUQuota, l.cpuPeriod())
+		}
+	}
+	return nil
+}
+
+// ResourceLimits returns the resource limits of the pod's cgroup, or nil if
+// the pod has none
+func (p *Pod) ResourceLimits() *PodResourceLimits {
+	if p.config.ResourceLimits == nil {
+		return nil
+	}
+	limits := *p.config.ResourceLimits
+	return &limits
+}
+
+// checkContainerResources returns an error if the container would not be
+// bound by the pod's resource limits, or its own limits exceed them
+func (p *Pod) checkContainerResources(ctr *Container, resources *spec.LinuxResources) error {
+	if p.config.ResourceLimits == nil {
+		return nil
+	}
+	if ctr.config.CgroupParent != p.state.CgroupPath {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s must use the cgroup of pod %s, which has resource limits", ctr.ID(), p.ID())
+	}
+	if err := p.config.ResourceLimits.checkResources(resources); err != nil {
+		return errors.Wrapf(err, "container %s does not fit in the resource limits of pod %s", ctr.ID(), p.ID())
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+// +build linux
+
+package libpod
+
+import (
+	"github.com/pkg/errors"
+)
+
+// applyResourceLimits creates the pod's cgroup if needed and sets its resource
+// limits. The cgroup may be gone after a reboot when using cgroupfs, so this
+// is done when the pod is started as well as when it is created.
+func (p *Pod) applyResourceLimits() error {
+	if p.config.ResourceLimits == nil || p.state.CgroupPath == "" {
+		return nil
+	}
+	if err := p.runtime.getCgroupManager().setPodResources(p, p.config.ResourceLimits.linuxResources()); err != nil {
+		return errors.Wrapf(err, "error setting resource limits of pod %s", p.ID())
+	}
+	return nil
+}
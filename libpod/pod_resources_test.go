@@ -0,0 +1,35 @@
+package libpod
+
+import (
+	"testing"
+
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodResourceLimitsCheckResources(t *testing.T) {
+	limits := PodResourceLimits{CPUQuota: 50000, MemoryLimit: 1 << 20, PidsLimit: 100}
+	assert.NoError(t, limits.validate())
+
+	memory := int64(1 << 19)
+	quota := int64(100000)
+	period := uint64(200000)
+	fits := &spec.LinuxResources{
+		Memory: &spec.LinuxMemory{Limit: &memory},
+		CPU:    &spec.LinuxCPU{Quota: &quota, Period: &period},
+		Pids:   &spec.LinuxPids{Limit: 100},
+	}
+	assert.NoError(t, limits.checkResources(nil))
+	assert.NoError(t, limits.checkResources(fits))
+
+	tooMuchMemory := int64(1 << 21)
+	assert.Error(t, limits.checkResources(&spec.LinuxResources{Memory: &spec.LinuxMemory{Limit: &tooMuchMemory}}))
+	assert.Error(t, limits.checkResources(&spec.LinuxResources{Pids: &spec.LinuxPids{Limit: 101}}))
+	assert.Error(t, limits.checkResources(&spec.LinuxResources{CPU: &spec.LinuxCPU{Quota: &quota}}))
+}
+
+func TestPodResourceLimitsValidate(t *testing.T) {
+	assert.Error(t, (&PodResourceLimits{CPUPeriod: 100000}).validate())
+	assert.Error(t, (&PodResourceLimits{CPUQuota: 1000, CPUPeriod: 10}).validate())
+	assert.Error(t, (&PodResourceLimits{MemoryLimit: -1}).validate())
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package libpod
+
+import "github.com/containers/libpod/libpod/define"
+
+func (p *Pod) applyResourceLimits() error {
+	if p.config.ResourceLimits == nil {
+		return nil
+	}
+	return define.ErrNotImplemented
+}
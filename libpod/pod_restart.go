@@ -0,0 +1,164 @@
+package libpod
+
+import (
+	"context"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// podRestartMatch returns whether the pod's restart policy should restart the
+// pod's containers now that the container has exited. Init containers are
+// expected to exit, and containers stopped through the API or restarted by
+// their own restart policy never trigger the pod's policy.
+// Must be called with the container locked.
+func (c *Container) podRestartMatch() (bool, error) {
+	if c.config.Pod == "" || c.IsInitCtr() || c.state.StoppedByUser {
+		return false, nil
+	}
+	if c.state.State != define.ContainerStateStopped && c.state.State != define.ContainerStateExited {
+		return false, nil
+	}
+	if c.state.StartedTime.IsZero() || c.state.FinishedTime.Before(c.state.StartedTime) {
+		return false, nil
+	}
+
+	pod, err := c.runtime.state.Pod(c.config.Pod)
+	if err != nil {
+		return false, errors.Wrapf(err, "error retrieving pod %s of container %s", c.config.Pod, c.ID())
+	}
+	switch pod.config.RestartPolicy {
+	case RestartPolicyAlways, RestartPolicyUnlessStopped:
+		return true, nil
+	case RestartPolicyOnFailure:
+		return c.state.ExitCode != 0, nil
+	}
+	return false, nil
+}
+
+// handleRestartPolicy restarts the pod's containers in dependency order after
+// the given container exited at the given time, if the pod's restart policy
+// allows it. Consecutive restarts are delayed like those of containers. If
+// several containers exit together, the containers are restarted only once.
+// The pod must not be locked, nor any of its containers.
+func (p *Pod) handleRestartPolicy(ctx context.Context, exited *Container, finished time.Time) error {
+	p.lock.Lock()
+	delay, restart, err := p.restartBackoff(finished)
+	p.lock.Unlock()
+	if err != nil || !restart {
+		return err
+	}
+
+	logrus.Debugf("Waiting %s before restarting the containers of pod %s", delay, p.ID())
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.valid {
+		return nil
+	}
+	if err := p.updatePod(); err != nil {
+		return err
+	}
+	// Another container's exit may have restarted the pod while we waited
+	if p.state.LastRestart.After(finished) {
+		return nil
+	}
+
+	// The container may have been started or stopped while we waited
+	if err := p.lockContainer(exited); err != nil {
+		return err
+	}
+	err = exited.syncContainer()
+	stillExited := err == nil && !exited.state.StoppedByUser &&
+		(exited.state.State == define.ContainerStateStopped || exited.state.State == define.ContainerStateExited)
+	exited.lock.Unlock()
+	if err != nil || !stillExited {
+		return err
+	}
+
+	p.state.RestartCount++
+	p.state.LastRestart = time.Now()
+	if err := p.save(); err != nil {
+		return err
+	}
+	logrus.Debugf("Restarting containers of pod %s due to restart policy %s after container %s exited", p.ID(), p.config.RestartPolicy, exited.ID())
+
+	allCtrs, err := p.runtime.state.PodContainers(p)
+	if err != nil {
+		return err
+	}
+	ctrs := make([]*Container, 0, len(allCtrs))
+	for _, ctr := range allCtrs {
+		if !ctr.IsInitCtr() {
+			ctrs = append(ctrs, ctr)
+		}
+	}
+
+	graph, err := BuildContainerGraph(ctrs)
+	if err != nil {
+		return errors.Wrapf(err, "error generating dependency graph for pod %s", p.ID())
+	}
+	if len(graph.noDepNodes) == 0 {
+		return errors.Wrapf(define.ErrNoSuchCtr, "no containers in pod %s have no dependencies, cannot restart pod", p.ID())
+	}
+
+	ctrErrors := make(map[string]error)
+	ctrsVisited := make(map[string]bool)
+	for _, node := range graph.noDepNodes {
+		startNode(ctx, node, false, ctrErrors, ctrsVisited, true)
+	}
+
+	p.newPodEvent(events.Restart)
+
+	for id, ctrErr := range ctrErrors {
+		logrus.Errorf("Error restarting container %s of pod %s: %v", id, p.ID(), ctrErr)
+	}
+	if len(ctrErrors) > 0 {
+		return errors.Wrapf(define.ErrCtrExists, "error restarting some containers of pod %s", p.ID())
+	}
+	return nil
+}
+
+// restartBackoff checks whether the pod's containers should be restarted after
+// a container exited at the given time, and returns how long to wait first.
+// The first restart waits restartBackoffInitial, and each restart within
+// restartBackoffReset of the previous one waits twice as long, up to
+// restartBackoffMax.
+// Must be called with the pod locked.
+func (p *Pod) restartBackoff(finished time.Time) (time.Duration, bool, error) {
+	if !p.valid {
+		return 0, false, nil
+	}
+	if err := p.updatePod(); err != nil {
+		return 0, false, err
+	}
+	if p.state.LastRestart.After(finished) {
+		return 0, false, nil
+	}
+	if p.config.RestartPolicy == RestartPolicyOnFailure && p.config.RestartRetries > 0 && p.state.RestartCount >= p.config.RestartRetries {
+		logrus.Debugf("Pod %s restart policy trigger: retries exhausted", p.ID())
+		return 0, false, nil
+	}
+
+	delay := restartBackoffInitial
+	if p.state.RestartBackoff > 0 && finished.Sub(p.state.LastRestart) < restartBackoffReset {
+		delay = p.state.RestartBackoff * 2
+		if delay > restartBackoffMax {
+			delay = restartBackoffMax
+		}
+	}
+	p.state.RestartBackoff = delay
+	if err := p.save(); err != nil {
+		return 0, false, err
+	}
+	return delay, true, nil
+}
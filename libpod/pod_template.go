@@ -0,0 +1,418 @@
+package libpod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// PodTemplate is a reusable description of a pod and the containers in it,
+// from which any number of identical pods can be created.
+type PodTemplate struct {
+	// Name is the unique name of the template.
+	Name string `json:"name"`
+	// CreatedTime is when the template was saved.
+	CreatedTime time.Time `json:"created"`
+	// Pod is the configuration of the pod, without its ID, name and lock.
+	Pod *PodConfig `json:"pod"`
+	// InfraID is the ID of the infra container of the pod the template was
+	// saved from. The configurations of the template's containers refer to
+	// the infra container by this ID.
+	InfraID string `json:"infraID,omitempty"`
+	// Containers are the pod's containers, excluding its infra container.
+	Containers []*PodTemplateContainer `json:"containers,omitempty"`
+}
+
+// PodTemplateContainer is a container of a pod template.
+type PodTemplateContainer struct {
+	// Config is the configuration of the container. Its ID is that of the
+	// container the template was saved from, and is used only to resolve
+	// dependencies between the template's containers. Its name does not
+	// include the name of the pod.
+	Config *ContainerConfig `json:"config"`
+	// ShmCtr is the ID of the container, in the pod the template was saved
+	// from, whose /dev/shm the container shares.
+	ShmCtr string `json:"shmCtr,omitempty"`
+}
+
+// PodTemplateOverrides are settings that differ between the pods created
+// from a template.
+type PodTemplateOverrides struct {
+	// Name is the name of the new pod. If not set, a name is generated.
+	Name string
+	// Hostname is the hostname of the new pod. If not set, the template's
+	// hostname is used, or the name of the pod if the template has none.
+	Hostname string
+	// Labels are added to the template's labels, replacing any with the
+	// same key.
+	Labels map[string]string
+	// PortBindings, if not nil, replace the ports the template's infra
+	// container publishes on the host, which would otherwise conflict
+	// between the pods created from it.
+	PortBindings []ocicni.PortMapping
+}
+
+// SavePodTemplate saves the configuration of the given pod and its containers
+// as a template with the given name, from which copies of the pod can be made
+// with NewPodFromTemplate.
+func (r *Runtime) SavePodTemplate(pod *Pod, name string) (*PodTemplate, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	if !nameRegex.MatchString(name) {
+		return nil, regexError
+	}
+
+	tmpl, err := pod.template(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.state.AddPodTemplate(tmpl); err != nil {
+		return nil, errors.Wrapf(err, "error saving pod template %s", name)
+	}
+
+	return tmpl, nil
+}
+
+// LookupPodTemplate retrieves the pod template with the given name
+func (r *Runtime) LookupPodTemplate(name string) (*PodTemplate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	return r.state.PodTemplate(name)
+}
+
+// GetAllPodTemplates retrieves all pod templates
+func (r *Runtime) GetAllPodTemplates() ([]*PodTemplate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	return r.state.AllPodTemplates()
+}
+
+// RemovePodTemplate removes the pod template with the given name. Pods
+// created from the template are not affected.
+func (r *Runtime) RemovePodTemplate(name string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return define.ErrRuntimeStopped
+	}
+
+	return r.state.RemovePodTemplate(name)
+}
+
+// NewPodFromTemplate creates a new pod, with new containers, from the pod
+// template with the given name. Overrides may be nil.
+// Containers are named after the new pod, followed by the name of the
+// container in the template.
+func (r *Runtime) NewPodFromTemplate(ctx context.Context, name string, overrides *PodTemplateOverrides) (_ *Pod, Err error) {
+	tmpl, err := r.LookupPodTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := r.NewPod(ctx, withPodTemplate(tmpl, overrides))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating pod from template %s", name)
+	}
+	defer func() {
+		if Err != nil {
+			if err := r.RemovePod(ctx, pod, true, true); err != nil {
+				logrus.Errorf("Error removing pod %s after creation from template failed: %v", pod.ID(), err)
+			}
+		}
+	}()
+
+	// Map the IDs of the template's containers to those of the new pod,
+	// so namespaces and dependencies can be resolved
+	ids := make(map[string]string)
+	if tmpl.InfraID != "" && pod.state.InfraContainerID != "" {
+		ids[tmpl.InfraID] = pod.state.InfraContainerID
+	}
+
+	pending, err := orderPodTemplateContainers(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	for _, tmplCtr := range pending {
+		ctr, err := r.newContainerFromTemplate(ctx, pod, tmplCtr, ids)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating container %s of pod template %s", tmplCtr.Config.Name, name)
+		}
+		ids[tmplCtr.Config.ID] = ctr.ID()
+	}
+
+	return pod, nil
+}
+
+// template generates a template of the pod with the given name.
+func (p *Pod) template(name string) (*PodTemplate, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.valid {
+		return nil, define.ErrPodRemoved
+	}
+
+	if err := p.updatePod(); err != nil {
+		return nil, err
+	}
+
+	ctrs, err := p.runtime.state.PodContainers(p)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := &PodTemplate{
+		Name:        name,
+		CreatedTime: time.Now(),
+		Pod:         new(PodConfig),
+		InfraID:     p.state.InfraContainerID,
+	}
+	if err := JSONDeepCopy(p.config, tmpl.Pod); err != nil {
+		return nil, errors.Wrapf(err, "error copying configuration of pod %s", p.ID())
+	}
+	tmpl.Pod.ID = ""
+	tmpl.Pod.Name = ""
+	tmpl.Pod.Namespace = ""
+	tmpl.Pod.LockID = 0
+	tmpl.Pod.CreatedTime = time.Time{}
+	// The hostname defaults to the pod's name
+	if tmpl.Pod.Hostname == p.config.Name {
+		tmpl.Pod.Hostname = ""
+	}
+
+	// Map the /dev/shm directories created by the pod's containers to the
+	// containers that own them
+	shmCtrs := make(map[string]string, len(ctrs))
+	for _, ctr := range ctrs {
+		if ctr.config.ShmDir != "" && ctr.config.StaticDir != "" && strings.HasPrefix(ctr.config.ShmDir, ctr.config.StaticDir) {
+			shmCtrs[ctr.config.ShmDir] = ctr.ID()
+		}
+	}
+
+	for _, ctr := range ctrs {
+		if ctr.ID() == p.state.InfraContainerID {
+			continue
+		}
+
+		config := new(ContainerConfig)
+		if err := JSONDeepCopy(ctr.config, config); err != nil {
+			return nil, errors.Wrapf(err, "error copying configuration of container %s", ctr.ID())
+		}
+		config.Name = strings.TrimPrefix(config.Name, p.config.Name+"-")
+		config.Pod = ""
+		config.Namespace = ""
+		config.LockID = 0
+		config.CreatedTime = time.Time{}
+		config.StaticDir = ""
+		config.LogPath = ""
+		// Fresh SELinux labels are allocated for every copy
+		config.ProcessLabel = ""
+		config.MountLabel = ""
+		// Static addresses are reserved by a single container
+		config.StaticIP = nil
+		config.StaticMAC = nil
+		config.NetworkStaticIPs = nil
+		if strings.HasPrefix(config.ConmonPidFile, p.runtime.config.StorageConfig.RunRoot) {
+			config.ConmonPidFile = ""
+		}
+
+		// Each copy gets its own /dev/shm, unless it shares that of
+		// another container of the pod or of the host
+		tmplCtr := &PodTemplateContainer{Config: config}
+		if owner, ok := shmCtrs[config.ShmDir]; ok {
+			if owner != ctr.ID() {
+				tmplCtr.ShmCtr = owner
+			}
+			mounts := make([]string, 0, len(config.Mounts))
+			for _, mount := range config.Mounts {
+				if mount != config.ShmDir {
+					mounts = append(mounts, mount)
+				}
+			}
+			config.Mounts = mounts
+			config.ShmDir = ""
+		}
+
+		tmpl.Containers = append(tmpl.Containers, tmplCtr)
+	}
+
+	return tmpl, nil
+}
+
+// withPodTemplate sets the configuration of a new pod from a pod template,
+// applying the given overrides.
+func withPodTemplate(tmpl *PodTemplate, overrides *PodTemplateOverrides) PodCreateOption {
+	return func(pod *Pod) error {
+		if pod.valid {
+			return define.ErrPodFinalized
+		}
+
+		if tmpl.Pod == nil {
+			return errors.Wrapf(define.ErrInvalidArg, "pod template %s has no pod configuration", tmpl.Name)
+		}
+
+		id := pod.config.ID
+		namespace := pod.config.Namespace
+		created := pod.config.CreatedTime
+		if err := JSONDeepCopy(tmpl.Pod, pod.config); err != nil {
+			return errors.Wrapf(err, "error copying configuration of pod template %s", tmpl.Name)
+		}
+		pod.config.ID = id
+		pod.config.Namespace = namespace
+		pod.config.CreatedTime = created
+		pod.config.LockID = 0
+		if pod.config.Labels == nil {
+			pod.config.Labels = make(map[string]string)
+		}
+		if pod.config.InfraContainer == nil {
+			pod.config.InfraContainer = new(InfraContainerConfig)
+		}
+
+		if overrides == nil {
+			return nil
+		}
+		if overrides.Name != "" {
+			if !nameRegex.MatchString(overrides.Name) {
+				return regexError
+			}
+			pod.config.Name = overrides.Name
+		}
+		if overrides.Hostname != "" {
+			pod.config.Hostname = overrides.Hostname
+		}
+		for key, value := range overrides.Labels {
+			pod.config.Labels[key] = value
+		}
+		if overrides.PortBindings != nil {
+			pod.config.InfraContainer.PortBindings = overrides.PortBindings
+		}
+
+		return nil
+	}
+}
+
+// orderPodTemplateContainers orders the containers of a pod template so that
+// every container follows the containers it depends on.
+func orderPodTemplateContainers(tmpl *PodTemplate) ([]*PodTemplateContainer, error) {
+	inTemplate := make(map[string]bool, len(tmpl.Containers))
+	for _, tmplCtr := range tmpl.Containers {
+		inTemplate[tmplCtr.Config.ID] = true
+	}
+
+	ordered := make([]*PodTemplateContainer, 0, len(tmpl.Containers))
+	created := make(map[string]bool, len(tmpl.Containers))
+	pending := tmpl.Containers
+	for len(pending) > 0 {
+		var remaining []*PodTemplateContainer
+		for _, tmplCtr := range pending {
+			ready := true
+			for _, dep := range (&Container{config: tmplCtr.Config}).Dependencies() {
+				if inTemplate[dep] && !created[dep] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				remaining = append(remaining, tmplCtr)
+				continue
+			}
+			ordered = append(ordered, tmplCtr)
+			created[tmplCtr.Config.ID] = true
+		}
+		if len(remaining) == len(pending) {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "pod template %s has a dependency cycle between its containers", tmpl.Name)
+		}
+		pending = remaining
+	}
+
+	return ordered, nil
+}
+
+// newContainerFromTemplate creates a container in the given pod from a
+// container of a pod template. ids maps the IDs of the template's containers
+// to those of the containers already created in the pod.
+func (r *Runtime) newContainerFromTemplate(ctx context.Context, pod *Pod, tmplCtr *PodTemplateContainer, ids map[string]string) (*Container, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	ctr, err := r.initContainerVariables(tmplCtr.Config.Spec, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error initializing container variables")
+	}
+	id := ctr.config.ID
+	created := ctr.config.CreatedTime
+	if err := JSONDeepCopy(tmplCtr.Config, ctr.config); err != nil {
+		return nil, errors.Wrapf(err, "error copying container configuration")
+	}
+	oldID := ctr.config.ID
+	ctr.config.ID = id
+	ctr.config.CreatedTime = created
+	ctr.config.Name = fmt.Sprintf("%s-%s", pod.Name(), tmplCtr.Config.Name)
+	ctr.config.Pod = pod.ID()
+	ctr.config.Namespace = pod.config.Namespace
+	if n := len(ctr.config.ExitCommand); n > 0 && ctr.config.ExitCommand[n-1] == oldID {
+		ctr.config.ExitCommand[n-1] = id
+	}
+
+	remap := func(oldID string) string {
+		if newID, ok := ids[oldID]; ok {
+			return newID
+		}
+		return oldID
+	}
+	ctr.config.IPCNsCtr = remap(ctr.config.IPCNsCtr)
+	ctr.config.MountNsCtr = remap(ctr.config.MountNsCtr)
+	ctr.config.NetNsCtr = remap(ctr.config.NetNsCtr)
+	ctr.config.PIDNsCtr = remap(ctr.config.PIDNsCtr)
+	ctr.config.UserNsCtr = remap(ctr.config.UserNsCtr)
+	ctr.config.UTSNsCtr = remap(ctr.config.UTSNsCtr)
+	ctr.config.CgroupNsCtr = remap(ctr.config.CgroupNsCtr)
+	for i, dep := range ctr.config.Dependencies {
+		ctr.config.Dependencies[i] = remap(dep)
+	}
+	if len(ctr.config.DependencyConditions) > 0 {
+		conditions := make(map[string]define.DependencyCondition, len(ctr.config.DependencyConditions))
+		for dep, condition := range ctr.config.DependencyConditions {
+			conditions[remap(dep)] = condition
+		}
+		ctr.config.DependencyConditions = conditions
+	}
+
+	if tmplCtr.ShmCtr != "" {
+		shmCtr, err := r.state.Container(remap(tmplCtr.ShmCtr))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error retrieving container sharing its /dev/shm")
+		}
+		ctr.config.ShmDir = shmCtr.config.ShmDir
+		ctr.config.Mounts = append(ctr.config.Mounts, shmCtr.config.ShmDir)
+	}
+
+	return r.setupContainer(ctx, ctr)
+}
@@ -0,0 +1,33 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderPodTemplateContainers(t *testing.T) {
+	tmpl := &PodTemplate{
+		Name:    "web",
+		InfraID: "infra",
+		Containers: []*PodTemplateContainer{
+			{Config: &ContainerConfig{ID: "app", NetNsCtr: "infra", Dependencies: []string{"db"}}},
+			{Config: &ContainerConfig{ID: "sidecar", IPCNsCtr: "app"}},
+			{Config: &ContainerConfig{ID: "db", NetNsCtr: "infra"}},
+		},
+	}
+
+	ordered, err := orderPodTemplateContainers(tmpl)
+	assert.NoError(t, err)
+	ids := make([]string, 0, len(ordered))
+	for _, tmplCtr := range ordered {
+		ids = append(ids, tmplCtr.Config.ID)
+	}
+	assert.Equal(t, []string{"db", "app", "sidecar"}, ids)
+
+	tmpl.Containers[2].Config.Dependencies = []string{"sidecar"}
+	_, err = orderPodTemplateContainers(tmpl)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
@@ -29,7 +29,9 @@ func (p *Pod) GetPodPidInformation(descriptors []string) ([]string, error) {
 		return nil, err
 	}
 	for _, c := range ctrsInPod {
-		c.lock.Lock()
+		if err := p.lockContainer(c); err != nil {
+			return nil, err
+		}
 
 		if err := c.syncContainer(); err != nil {
 			c.lock.Unlock()
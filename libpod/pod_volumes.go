@@ -0,0 +1,81 @@
+package libpod
+
+import (
+	"path/filepath"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+)
+
+// validatePodVolumes checks that the named volumes of a pod have a name and an
+// absolute, clean destination, and that no two share a destination
+func validatePodVolumes(volumes []*ContainerNamedVolume) error {
+	dests := make(map[string]string, len(volumes))
+	for _, vol := range volumes {
+		if vol.Name == "" {
+			return errors.Wrapf(define.ErrInvalidArg, "pod volumes must have a name")
+		}
+		if !filepath.IsAbs(vol.Dest) || filepath.Clean(vol.Dest) != vol.Dest {
+			return errors.Wrapf(define.ErrInvalidArg, "destination %q of pod volume %s must be an absolute, clean path", vol.Dest, vol.Name)
+		}
+		if other, ok := dests[vol.Dest]; ok {
+			return errors.Wrapf(define.ErrInvalidArg, "pod volumes %s and %s are both mounted at %s", other, vol.Name, vol.Dest)
+		}
+		dests[vol.Dest] = vol.Name
+	}
+	return nil
+}
+
+// Volumes returns the named volumes mounted into every container of the pod
+func (p *Pod) Volumes() []*ContainerNamedVolume {
+	volumes := make([]*ContainerNamedVolume, 0, len(p.config.Volumes))
+	for _, vol := range p.config.Volumes {
+		newVol := new(ContainerNamedVolume)
+		newVol.Name = vol.Name
+		newVol.Dest = vol.Dest
+		newVol.Options = append([]string{}, vol.Options...)
+		volumes = append(volumes, newVol)
+	}
+	return volumes
+}
+
+// addVolumesToContainer adds the pod's volumes to the named volumes of a
+// container being added to it. A pod volume the container already mounts at
+// the same destination is not added again; any other mount of the container at
+// the destination of a pod volume is a conflict.
+func (p *Pod) addVolumesToContainer(ctr *Container) error {
+	for _, vol := range p.Volumes() {
+		if ctr.config.Spec != nil {
+			for _, m := range ctr.config.Spec.Mounts {
+				if filepath.Clean(m.Destination) == vol.Dest {
+					return errors.Wrapf(define.ErrInvalidArg, "mount at %s of container %s conflicts with volume %s of pod %s", vol.Dest, ctr.ID(), vol.Name, p.ID())
+				}
+			}
+		}
+
+		present := false
+		for _, ctrVol := range ctr.config.NamedVolumes {
+			if filepath.Clean(ctrVol.Dest) != vol.Dest {
+				continue
+			}
+			if ctrVol.Name != vol.Name {
+				return errors.Wrapf(define.ErrInvalidArg, "volume %s at %s of container %s conflicts with volume %s of pod %s", ctrVol.Name, vol.Dest, ctr.ID(), vol.Name, p.ID())
+			}
+			present = true
+		}
+		if !present {
+			ctr.config.NamedVolumes = append(ctr.config.NamedVolumes, vol)
+		}
+	}
+	return nil
+}
+
+// hasVolume returns whether the named volume is one of the pod's volumes
+func (p *Pod) hasVolume(name string) bool {
+	for _, vol := range p.config.Volumes {
+		if vol.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePodVolumes(t *testing.T) {
+	assert.NoError(t, validatePodVolumes([]*ContainerNamedVolume{
+		{Name: "data", Dest: "/data"},
+		{Name: "cache", Dest: "/var/cache"},
+	}))
+
+	for _, volumes := range [][]*ContainerNamedVolume{
+		{{Dest: "/data"}},
+		{{Name: "data", Dest: "data"}},
+		{{Name: "data", Dest: "/data/"}},
+		{{Name: "data", Dest: "/data"}, {Name: "other", Dest: "/data"}},
+	} {
+		err := validatePodVolumes(volumes)
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+	}
+}
+
+func TestPodAddVolumesToContainer(t *testing.T) {
+	pod := &Pod{config: &PodConfig{ID: "pod", Volumes: []*ContainerNamedVolume{
+		{Name: "data", Dest: "/data", Options: []string{"ro"}},
+	}}}
+
+	ctr := &Container{config: &ContainerConfig{ID: "ctr", Spec: &spec.Spec{}}}
+	assert.NoError(t, pod.addVolumesToContainer(ctr))
+	assert.Equal(t, []*ContainerNamedVolume{{Name: "data", Dest: "/data", Options: []string{"ro"}}}, ctr.config.NamedVolumes)
+
+	// Already mounted by the container
+	assert.NoError(t, pod.addVolumesToContainer(ctr))
+	assert.Len(t, ctr.config.NamedVolumes, 1)
+
+	ctr = &Container{config: &ContainerConfig{ID: "ctr", Spec: &spec.Spec{}, NamedVolumes: []*ContainerNamedVolume{{Name: "other", Dest: "/data"}}}}
+	err := pod.addVolumesToContainer(ctr)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+
+	ctr = &Container{config: &ContainerConfig{ID: "ctr", Spec: &spec.Spec{Mounts: []spec.Mount{{Destination: "/data/", Type: "bind", Source: "/srv"}}}}}
+	err = pod.addVolumesToContainer(ctr)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
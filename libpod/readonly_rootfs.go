@@ -0,0 +1,91 @@
+package libpod
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/containers/libpod/libpod/define"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+)
+
+// validateReadOnlyTmpfs checks that the paths given for the tmpfs mounts of a
+// read-only container are absolute, clean and unique
+func validateReadOnlyTmpfs(paths []string) error {
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			return errors.Wrapf(define.ErrInvalidArg, "read-only tmpfs path %q must be absolute", path)
+		}
+		if filepath.Clean(path) != path {
+			return errors.Wrapf(define.ErrInvalidArg, "read-only tmpfs path %q must be clean (use %q)", path, filepath.Clean(path))
+		}
+		if path == "/" {
+			return errors.Wrapf(define.ErrInvalidArg, "cannot mount a read-only tmpfs over the root filesystem")
+		}
+		if seen[path] {
+			return errors.Wrapf(define.ErrInvalidArg, "read-only tmpfs path %q given more than once", path)
+		}
+		seen[path] = true
+	}
+	return nil
+}
+
+// readOnlyTmpfsMount returns the tmpfs mount for the given path in a read-only
+// container. /run is not executable and limited in size, as it only holds
+// sockets and PID files.
+func readOnlyTmpfsMount(dest string) spec.Mount {
+	options := []string{"rw", "rprivate", "nosuid", "nodev", "tmpcopyup"}
+	if dest == "/run" {
+		options = append(options, "noexec", "size=65536k")
+	} else {
+		options = append(options, "exec")
+	}
+	return spec.Mount{
+		Destination: dest,
+		Type:        "tmpfs",
+		Source:      "tmpfs",
+		Options:     options,
+	}
+}
+
+// readOnlyTmpfsMounts returns the tmpfs mounts to add to the spec of a
+// container with a read-only root filesystem. Paths already used as mount or
+// named volume destinations are skipped. The container must be mounted, as the
+// paths are checked against its root filesystem: the tmpfs cannot be mounted
+// over anything but a directory, and a missing directory is created by the OCI
+// runtime before the root filesystem is made read-only.
+func (c *Container) readOnlyTmpfsMounts(existing []spec.Mount) ([]spec.Mount, error) {
+	if !c.IsReadOnly() || len(c.config.ReadOnlyTmpfs) == 0 {
+		return nil, nil
+	}
+
+	used := make(map[string]bool, len(existing)+len(c.config.NamedVolumes))
+	for _, m := range existing {
+		used[filepath.Clean(m.Destination)] = true
+	}
+	for _, vol := range c.config.NamedVolumes {
+		used[filepath.Clean(vol.Dest)] = true
+	}
+
+	mounts := make([]spec.Mount, 0, len(c.config.ReadOnlyTmpfs))
+	for _, dest := range c.config.ReadOnlyTmpfs {
+		if used[dest] {
+			continue
+		}
+		path, err := securejoin.SecureJoin(c.state.Mountpoint, dest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error resolving read-only tmpfs path %s in container %s", dest, c.ID())
+		}
+		info, err := os.Stat(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "error checking read-only tmpfs path %s in container %s", dest, c.ID())
+		}
+		if err == nil && !info.IsDir() {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "read-only tmpfs path %s is not a directory in the image of container %s", dest, c.ID())
+		}
+		mounts = append(mounts, readOnlyTmpfsMount(dest))
+	}
+	return mounts, nil
+}
@@ -0,0 +1,35 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReadOnlyTmpfs(t *testing.T) {
+	assert.NoError(t, validateReadOnlyTmpfs(nil))
+	assert.NoError(t, validateReadOnlyTmpfs([]string{"/run", "/tmp", "/var/tmp"}))
+
+	for _, paths := range [][]string{
+		{"tmp"},
+		{"/var/tmp/"},
+		{"/"},
+		{"/tmp", "/tmp"},
+	} {
+		err := validateReadOnlyTmpfs(paths)
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(err), "paths %v", paths)
+	}
+}
+
+func TestReadOnlyTmpfsMount(t *testing.T) {
+	run := readOnlyTmpfsMount("/run")
+	assert.Equal(t, "tmpfs", run.Type)
+	assert.Contains(t, run.Options, "noexec")
+	assert.Contains(t, run.Options, "size=65536k")
+
+	tmp := readOnlyTmpfsMount("/tmp")
+	assert.Contains(t, tmp.Options, "exec")
+	assert.NotContains(t, tmp.Options, "noexec")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -84,6 +85,17 @@ var (
 	// DefaultDetachKeys is the default keys sequence for detaching a
 	// container
 	DefaultDetachKeys = "ctrl-p,ctrl-q"
+
+	// DefaultMemoryPressureThreshold is the default host memory pressure
+	// above which the memory pressure guard freezes containers
+	DefaultMemoryPressureThreshold = 40.0
+	// DefaultMemoryPressurePriorityLabel is the default label holding the
+	// priority of containers for the memory pressure guard
+	DefaultMemoryPressurePriorityLabel = "io.podman.memory-pressure.priority"
+
+	// DefaultDependencyConditionTimeout is the default number of seconds
+	// a container waits for its dependencies to become healthy or exit
+	DefaultDependencyConditionTimeout uint = 300
 )
 
 // A RuntimeOption is a functional option which alters the Runtime created by
@@ -98,21 +110,55 @@ type Runtime struct {
 	store             storage.Store
 	storageService    *storageService
 	imageContext      *types.SystemContext
-	defaultOCIRuntime *OCIRuntime
-	ociRuntimes       map[string]*OCIRuntime
+	defaultOCIRuntime OCIRuntime
+	ociRuntimes       map[string]OCIRuntime
 	netPlugin         ocicni.CNIPlugin
 	conmonPath        string
 	imageRuntime      *image.Runtime
 	lockManager       lock.Manager
 	configuredFrom    *runtimeConfiguredFrom
 
+	// cgroups manages the cgroups of containers and pods, as set by the
+	// cgroup_manager option.
+	cgroups cgroupManager
+
+	// configPath is the configuration file the runtime was created from,
+	// if any.
+	configPath string
+	// fileConfig is the configuration as read from configPath, before
+	// runtime options were applied. ReloadConfig compares against it to
+	// find the settings that were overridden.
+	fileConfig *RuntimeConfig
+
 	// doRenumber indicates that the runtime should perform a lock renumber
 	// during initialization.
 	// Once the runtime has been initialized and returned, this variable is
 	// unused.
 	doRenumber bool
 
-	doMigrate bool
+	// doReadoptStorage indicates that a change of storage graph driver from
+	// the one recorded in the database is permitted, and that existing
+	// containers should be re-adopted under the new driver during
+	// initialization.
+	doReadoptStorage bool
+	// storageReadoption holds the results of storage re-adoption, if one
+	// was performed during initialization.
+	storageReadoption *StorageReadoption
+
+	// stateHooks are called each time a container moves from one state to
+	// another.
+	stateHooks []ContainerStateHook
+	// stateWatch wakes containers waiting for state changes in this
+	// process.
+	stateWatch containerStateWatch
+
+	// checkpointStorages are the additional checkpoint storage backends,
+	// indexed by the scheme selecting them.
+	checkpointStorages map[string]CheckpointStorage
+	// ociRuntimeBackends are the OCI runtimes provided by the embedder of
+	// libpod, indexed by their names. They are registered alongside the
+	// runtimes of the configuration.
+	ociRuntimeBackends map[string]OCIRuntime
 
 	// valid indicates whether the runtime is ready to use.
 	// valid is set to true when a runtime is returned from GetRuntime(),
@@ -180,6 +226,18 @@ type RuntimeConfig struct {
 	// StaticDir is the path to a persistent directory to store container
 	// files
 	StaticDir string `toml:"static_dir"`
+	// StateSyncMode determines when the state database is synced to disk:
+	// "full" on every change, "hybrid" only when containers and pods are
+	// added or removed, and "none" never.
+	StateSyncMode string `toml:"state_sync_mode,omitempty"`
+	// StateInitialMmapSize is the initial size, in bytes, of the memory
+	// mapping of the state database. A size larger than the database avoids
+	// remapping it as it grows, which blocks all access to the database.
+	StateInitialMmapSize int `toml:"state_initial_mmap_size,omitempty"`
+	// StateFreelistType is the type of the freelist of the state database,
+	// "array" or "hashmap". The hashmap freelist is faster for large and
+	// fragmented databases.
+	StateFreelistType string `toml:"state_freelist_type,omitempty"`
 	// TmpDir is the path to a temporary directory to store per-boot
 	// container files
 	// Must be stored in a tmpfs
@@ -232,11 +290,18 @@ type RuntimeConfig struct {
 	EnableLabeling bool `toml:"label"`
 	// NetworkCmdPath is the path to the slirp4netns binary
 	NetworkCmdPath string `toml:"network_cmd_path"`
+	// DNSCacheCmdPath is the path to the dnsmasq binary used to run the
+	// DNS caches of pods
+	DNSCacheCmdPath string `toml:"dns_cache_cmd_path"`
 
 	// NumLocks is the number of locks to make available for containers and
 	// pods.
 	NumLocks uint32 `toml:"num_locks,omitempty"`
 
+	// AutoUserNSUser is the user whose subordinate IDs, in /etc/subuid and
+	// /etc/subgid, are allocated to containers created with --userns=auto.
+	AutoUserNSUser string `toml:"auto_userns_user,omitempty"`
+
 	// LockType is the type of locking to use.
 	LockType string `toml:"lock_type,omitempty"`
 
@@ -244,12 +309,96 @@ type RuntimeConfig struct {
 	EventsLogger string `toml:"events_logger"`
 	// EventsLogFilePath is where the events log is stored.
 	EventsLogFilePath string `toml:"-events_logfile_path"`
+	// EventsLogFileMaxSize is the size in bytes the events log file may
+	// grow to before it is rotated. Unlimited if not positive.
+	EventsLogFileMaxSize int64 `toml:"events_logfile_max_size,omitempty"`
+	// EventsWebhookURLs are endpoints events are also posted to, in
+	// addition to being logged by EventsLogger.
+	EventsWebhookURLs []string `toml:"events_webhook_urls,omitempty"`
 	//DetachKeys is the sequence of keys used to detach a container
 	DetachKeys string `toml:"detach_keys"`
+	// ReadOnlyTmpfsPaths are the paths a tmpfs is mounted on in containers
+	// with a read-only root filesystem
+	ReadOnlyTmpfsPaths []string `toml:"read_only_tmpfs_paths"`
+	// PodStartParallelism is the number of containers of a pod that are
+	// started at once, among those that do not depend on each other.
+	// 0 uses the number of CPUs.
+	PodStartParallelism uint `toml:"pod_start_parallelism,omitempty"`
+	// DependencyConditionTimeout is the number of seconds a container
+	// being started waits for its dependencies to become healthy or exit,
+	// when it was created with such conditions on them.
+	// 0 waits indefinitely.
+	DependencyConditionTimeout uint `toml:"dependency_condition_timeout"`
+	// HTTPProxy determines whether the proxy environment variables of the
+	// host (HTTP_PROXY and similar) are used.
+	// When enabled, they are added to the environment of new containers
+	// unless overridden by the container, and are used to pull images.
+	// When disabled, they are not added to containers by default, and
+	// image pulls will not use a proxy.
+	HTTPProxy bool `toml:"http_proxy"`
+
+	// BinfmtRegister indicates whether a binfmt_misc handler for a qemu
+	// user-mode emulator is registered when a container for a foreign
+	// architecture is started and no handler exists. This requires root.
+	BinfmtRegister bool `toml:"binfmt_register"`
+
+	// MemoryPressureThreshold is the host memory pressure, as the percentage
+	// of time in the last 10 seconds that tasks were stalled on memory
+	// (the "some avg10" value of /proc/pressure/memory), above which the
+	// memory pressure guard freezes low-priority containers.
+	MemoryPressureThreshold float64 `toml:"memory_pressure_threshold"`
+	// MemoryPressurePriorityLabel is the label holding the integer priority
+	// of a container for the memory pressure guard. Only containers with
+	// this label are frozen, lowest priority first.
+	MemoryPressurePriorityLabel string `toml:"memory_pressure_priority_label"`
 
 	// SDNotify tells Libpod to allow containers to notify the host
 	// systemd of readiness using the SD_NOTIFY mechanism
 	SDNotify bool
+
+	// NamespaceDirs configures separate directories for the files of each
+	// libpod namespace, indexed by namespace. This allows different
+	// quotas and permissions to be applied to each namespace's files.
+	// Namespaces not present use the directories configured above.
+	NamespaceDirs map[string]NamespaceDirConfig `toml:"namespace_dirs,omitempty"`
+
+	// NetworkHooks configures commands run when containers join or leave
+	// a CNI network, indexed by network name. They allow external
+	// firewalls and load balancers to follow container addresses.
+	NetworkHooks map[string]NetworkHookConfig `toml:"network_hooks,omitempty"`
+
+	// VolumePlugins are the volume plugins available as volume drivers,
+	// mapping the name of each to the path of the UNIX socket it serves
+	// the Docker volume plugin API on.
+	VolumePlugins map[string]string `toml:"volume_plugins,omitempty"`
+}
+
+// NetworkHookConfig configures the commands run for a single CNI network.
+// Each command is a path followed by its arguments. The CNI result of the
+// container's attachment to the network is given as JSON on standard input.
+type NetworkHookConfig struct {
+	// PostSetup is run after a container is attached to the network. If it
+	// fails, the container's network is torn down and the container fails
+	// to start.
+	PostSetup []string `toml:"post_setup,omitempty"`
+	// PreTeardown is run before a container is detached from the network.
+	// Failures are logged, but do not prevent the teardown.
+	PreTeardown []string `toml:"pre_teardown,omitempty"`
+}
+
+// NamespaceDirConfig configures the directories used for the files of a single
+// libpod namespace. Directories that are not set use the runtime's defaults.
+type NamespaceDirConfig struct {
+	// TmpDir is the directory holding temporary files, such as exit files
+	// and attach sockets, of containers in the namespace.
+	// It must not be changed while containers exist in the namespace.
+	TmpDir string `toml:"tmp_dir,omitempty"`
+	// EventsLogFilePath is where events are logged by runtimes joined to
+	// the namespace, when the file events logger is used.
+	EventsLogFilePath string `toml:"events_logfile_path,omitempty"`
+	// LogDir is the directory holding the logs of containers in the
+	// namespace which do not set a log path.
+	LogDir string `toml:"log_dir,omitempty"`
 }
 
 // runtimeConfiguredFrom is a struct used during early runtime init to help
@@ -287,6 +436,7 @@ func defaultRuntimeConfig() (RuntimeConfig, error) {
 		VolumePath:            filepath.Join(storeOpts.GraphRoot, "volumes"),
 		ImageDefaultTransport: DefaultTransport,
 		StateType:             BoltDBStateStore,
+		StateSyncMode:         StateSyncFull,
 		OCIRuntime:            "runc",
 		OCIRuntimes: map[string][]string{
 			"runc": {
@@ -325,9 +475,16 @@ func defaultRuntimeConfig() (RuntimeConfig, error) {
 		EnablePortReservation: true,
 		EnableLabeling:        true,
 		NumLocks:              2048,
+		AutoUserNSUser:        "containers",
 		EventsLogger:          events.DefaultEventerType.String(),
 		DetachKeys:            DefaultDetachKeys,
+		ReadOnlyTmpfsPaths:    []string{"/run", "/tmp", "/var/tmp"},
 		LockType:              "shm",
+		HTTPProxy:             true,
+
+		MemoryPressureThreshold:     DefaultMemoryPressureThreshold,
+		MemoryPressurePriorityLabel: DefaultMemoryPressurePriorityLabel,
+		DependencyConditionTimeout:  DefaultDependencyConditionTimeout,
 	}, nil
 }
 
@@ -524,15 +681,12 @@ func newRuntimeFromConfig(ctx context.Context, userConfigPath string, options ..
 		return nil, err
 	}
 	if rootless.IsRootless() {
-		home, err := homeDir()
-		if err != nil {
-			return nil, err
-		}
 		if runtime.config.SignaturePolicyPath == "" {
-			newPath := filepath.Join(home, ".config/containers/policy.json")
-			if _, err := os.Stat(newPath); err == nil {
-				runtime.config.SignaturePolicyPath = newPath
+			policyPath, err := rootlessSignaturePolicyPath()
+			if err != nil {
+				return nil, err
 			}
+			runtime.config.SignaturePolicyPath = policyPath
 		}
 	}
 
@@ -603,6 +757,12 @@ func newRuntimeFromConfig(ctx context.Context, userConfigPath string, options ..
 		if _, err := toml.Decode(string(contents), runtime.config); err != nil {
 			return nil, errors.Wrapf(err, "error decoding configuration file %s", configPath)
 		}
+
+		runtime.configPath = configPath
+		runtime.fileConfig = new(RuntimeConfig)
+		if err := JSONDeepCopy(runtime.config, runtime.fileConfig); err != nil {
+			return nil, errors.Wrapf(err, "error copying runtime config")
+		}
 	} else if rootless.IsRootless() {
 		// If the configuration file was not found but we are running in rootless, a subset of the
 		// global config file is used.
@@ -699,13 +859,72 @@ func newRuntimeFromConfig(ctx context.Context, userConfigPath string, options ..
 	return runtime, nil
 }
 
+// shmLockPath returns the path of the SHM segment holding libpod's locks.
+func shmLockPath() string {
+	if rootless.IsRootless() {
+		return fmt.Sprintf("%s_%d", DefaultRootlessSHMLockPath, rootless.GetRootlessUID())
+	}
+	return DefaultSHMLockPath
+}
+
+// migrateFileLocks moves the file locks allocated in oldPath, where earlier
+// versions kept them, to newPath. Nothing is done if newPath already exists.
+func migrateFileLocks(oldPath, newPath string) error {
+	if _, err := os.Stat(newPath); err == nil || !os.IsNotExist(err) {
+		return nil
+	}
+	files, err := ioutil.ReadDir(oldPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "error reading file locks directory %s", oldPath)
+	}
+
+	logrus.Infof("Moving file locks from %s to %s", oldPath, newPath)
+	if err := os.MkdirAll(filepath.Dir(newPath), 0700); err != nil {
+		return errors.Wrapf(err, "error creating directory %s", filepath.Dir(newPath))
+	}
+	if err := os.Rename(oldPath, newPath); err == nil {
+		return nil
+	}
+
+	// The directories may be on different filesystems. Lock files are
+	// empty, so allocating the same locks in the new directory suffices.
+	manager, err := lock.NewFileLockManager(newPath)
+	if err != nil {
+		return errors.Wrapf(err, "error creating file locks directory %s", newPath)
+	}
+	for _, f := range files {
+		id, err := strconv.ParseUint(f.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		if _, err := manager.AllocateAndRetrieveLock(uint32(id)); err != nil {
+			return errors.Wrapf(err, "error moving file lock %d", id)
+		}
+	}
+	if err := os.RemoveAll(oldPath); err != nil {
+		logrus.Warnf("Error removing old file locks directory %s: %v", oldPath, err)
+	}
+	return nil
+}
+
 func getLockManager(runtime *Runtime) (lock.Manager, error) {
 	var err error
 	var manager lock.Manager
 
 	switch runtime.config.LockType {
 	case "file":
-		lockPath := filepath.Join(runtime.config.TmpDir, "locks")
+		// File locks are kept in the static directory, so they are not
+		// limited by the size of a tmpfs, and persist across reboots
+		// along with the containers and pods they belong to.
+		// Lock files are created as locks are allocated, so the number
+		// of locks is not fixed.
+		lockPath := filepath.Join(runtime.config.StaticDir, "locks")
+		if err := migrateFileLocks(filepath.Join(runtime.config.TmpDir, "locks"), lockPath); err != nil {
+			return nil, err
+		}
 		manager, err = lock.OpenFileLockManager(lockPath)
 		if err != nil {
 			if os.IsNotExist(errors.Cause(err)) {
@@ -719,10 +938,7 @@ func getLockManager(runtime *Runtime) (lock.Manager, error) {
 		}
 
 	case "", "shm":
-		lockPath := DefaultSHMLockPath
-		if rootless.IsRootless() {
-			lockPath = fmt.Sprintf("%s_%d", DefaultRootlessSHMLockPath, rootless.GetRootlessUID())
-		}
+		lockPath := shmLockPath()
 		// Set up the lock manager
 		manager, err = lock.OpenSHMLockManager(lockPath, runtime.config.NumLocks)
 		if err != nil {
@@ -745,6 +961,22 @@ func getLockManager(runtime *Runtime) (lock.Manager, error) {
 				if err != nil {
 					return nil, err
 				}
+			} else if errors.Cause(err) == syscall.ERANGE {
+				// The lock pool may have been grown by
+				// RenumberLocks beyond the configured number
+				// of locks. If so, use the larger pool.
+				numLocks, countErr := lock.SHMLockCount(lockPath)
+				if countErr != nil || numLocks <= runtime.config.NumLocks {
+					return nil, errors.Wrapf(err, "number of locks configured does not match SHM lock pool, run 'podman system renumber' to resize it")
+				}
+
+				logrus.Debugf("SHM lock pool has %d locks, more than the %d configured - using existing pool", numLocks, runtime.config.NumLocks)
+
+				manager, err = lock.OpenSHMLockManager(lockPath, numLocks)
+				if err != nil {
+					return nil, err
+				}
+				runtime.config.NumLocks = numLocks
 			} else {
 				return nil, err
 			}
@@ -834,6 +1066,21 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 			runtime.config.ConmonPath)
 	}
 
+	for network, hooks := range runtime.config.NetworkHooks {
+		if err := hooks.validate(); err != nil {
+			return errors.Wrapf(err, "invalid hooks for network %s", network)
+		}
+	}
+
+	for name, socketPath := range runtime.config.VolumePlugins {
+		if name == "" || name == LocalVolumeDriver {
+			return errors.Wrapf(define.ErrInvalidArg, "invalid volume plugin name %q", name)
+		}
+		if !filepath.IsAbs(socketPath) {
+			return errors.Wrapf(define.ErrInvalidArg, "socket path %q of volume plugin %s must be absolute", socketPath, name)
+		}
+	}
+
 	// Make the static files directory if it does not exist
 	if err := os.MkdirAll(runtime.config.StaticDir, 0700); err != nil {
 		// The directory is allowed to exist
@@ -872,6 +1119,7 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 	}
 
 	// Reset defaults if they were not explicitly set
+	oldGraphDriver := dbConfig.GraphDriver
 	if !runtime.configuredFrom.storageGraphDriverSet && dbConfig.GraphDriver != "" {
 		if runtime.config.StorageConfig.GraphDriverName != dbConfig.GraphDriver &&
 			runtime.config.StorageConfig.GraphDriverName != "" {
@@ -916,6 +1164,11 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 	}
 
 	runtime.config.EventsLogFilePath = filepath.Join(runtime.config.TmpDir, "events", "events.log")
+	if runtime.config.Namespace != "" {
+		if nsDirs := runtime.config.NamespaceDirs[runtime.config.Namespace]; nsDirs.EventsLogFilePath != "" {
+			runtime.config.EventsLogFilePath = nsDirs.EventsLogFilePath
+		}
+	}
 
 	logrus.Debugf("Using graph driver %s", runtime.config.StorageConfig.GraphDriverName)
 	logrus.Debugf("Using graph root %s", runtime.config.StorageConfig.GraphRoot)
@@ -935,6 +1188,13 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 	}
 	logrus.Debugf("Set libpod namespace to %q", runtime.config.Namespace)
 
+	// Image pulls use the proxy configured in the environment of this
+	// process, so we must remove it before anything is pulled if proxies
+	// are disabled.
+	if !runtime.config.HTTPProxy {
+		unsetHostProxyEnv()
+	}
+
 	// Set up containers/storage
 	var store storage.Store
 	if os.Geteuid() != 0 {
@@ -962,9 +1222,9 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 	if err != nil {
 		return err
 	}
-	runtime.eventer = eventer
+	runtime.eventer = &reloadableEventer{eventer: eventer}
 	if runtime.imageRuntime != nil {
-		runtime.imageRuntime.Eventer = eventer
+		runtime.imageRuntime.Eventer = runtime.eventer
 	}
 
 	// Set up containers/image
@@ -988,8 +1248,20 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 		}
 	}
 
+	// Create the directories of all namespaces, as a runtime not joined
+	// to a namespace can operate on containers in any of them
+	if err := runtime.makeNamespaceDirs(); err != nil {
+		return err
+	}
+
+	manager, err := newCgroupManager(runtime.config.CgroupManager)
+	if err != nil {
+		return err
+	}
+	runtime.cgroups = manager
+
 	// Get us at least one working OCI runtime.
-	runtime.ociRuntimes = make(map[string]*OCIRuntime)
+	runtime.ociRuntimes = make(map[string]OCIRuntime)
 
 	// Is the old runtime_path defined?
 	if runtime.config.RuntimePath != nil {
@@ -1013,7 +1285,7 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 			}
 		}
 
-		ociRuntime, err := newOCIRuntime(name, runtime.config.RuntimePath, runtime.conmonPath, runtime.config, supportsJSON)
+		ociRuntime, err := newConmonOCIRuntime(name, runtime.config.RuntimePath, runtime.conmonPath, runtime.config, supportsJSON)
 		if err != nil {
 			return err
 		}
@@ -1032,7 +1304,7 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 			}
 		}
 
-		ociRuntime, err := newOCIRuntime(name, paths, runtime.conmonPath, runtime.config, supportsJSON)
+		ociRuntime, err := newConmonOCIRuntime(name, paths, runtime.conmonPath, runtime.config, supportsJSON)
 		if err != nil {
 			// Don't fatally error.
 			// This will allow us to ship configs including optional
@@ -1045,6 +1317,15 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 		runtime.ociRuntimes[name] = ociRuntime
 	}
 
+	// Register the runtimes provided by the embedder, which may be
+	// selected as the default
+	for name, ociRuntime := range runtime.ociRuntimeBackends {
+		if _, ok := runtime.ociRuntimes[name]; ok {
+			return errors.Wrapf(define.ErrInvalidArg, "OCI runtime %s is already configured", name)
+		}
+		runtime.ociRuntimes[name] = ociRuntime
+	}
+
 	// Do we have a default OCI runtime?
 	if runtime.config.OCIRuntime != "" {
 		// If the string starts with / it's a path to a runtime
@@ -1060,7 +1341,7 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 				}
 			}
 
-			ociRuntime, err := newOCIRuntime(name, []string{runtime.config.OCIRuntime}, runtime.conmonPath, runtime.config, supportsJSON)
+			ociRuntime, err := newConmonOCIRuntime(name, []string{runtime.config.OCIRuntime}, runtime.conmonPath, runtime.config, supportsJSON)
 			if err != nil {
 				return err
 			}
@@ -1156,16 +1437,31 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 		}
 	}
 
-	runtime.lockManager, err = getLockManager(runtime)
+	lockManager, err := getLockManager(runtime)
 	if err != nil {
 		return err
 	}
+	runtime.lockManager = instrumentedLockManager{lockManager}
 
 	// If we're renumbering locks, do it now.
 	// It breaks out of normal runtime init, and will not return a valid
 	// runtime.
 	if runtime.doRenumber {
-		if err := runtime.renumberLocks(); err != nil {
+		if _, err := runtime.renumberLocks(false); err != nil {
+			return err
+		}
+	}
+
+	// If the graph driver changed and we were asked to re-adopt existing
+	// containers, do so now, before the state is refreshed.
+	if runtime.doReadoptStorage && oldGraphDriver != "" && oldGraphDriver != runtime.config.StorageConfig.GraphDriverName {
+		if runtime.store == nil {
+			if err := runtime.configureStore(); err != nil {
+				return err
+			}
+		}
+
+		if err := runtime.readoptStorage(oldGraphDriver); err != nil {
 			return err
 		}
 	}
@@ -1189,9 +1485,11 @@ func makeRuntime(ctx context.Context, runtime *Runtime) (err error) {
 	// further
 	runtime.valid = true
 
-	if runtime.doMigrate {
-		if err := runtime.migrate(ctx); err != nil {
-			return err
+	// Remove auto-remove containers left behind by processes that died
+	// before removing them
+	if runtime.store != nil {
+		if _, err := runtime.ReapPendingRemovals(ctx); err != nil {
+			logrus.Errorf("Error removing containers queued for removal: %v", err)
 		}
 	}
 
@@ -1267,6 +1565,13 @@ func (r *Runtime) Shutdown(force bool) error {
 		lastError = err
 	}
 
+	// Give events still queued for webhooks a chance to be posted
+	if closer, ok := r.eventer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logrus.Errorf("Error closing eventer: %v", err)
+		}
+	}
+
 	return lastError
 }
 
@@ -1281,6 +1586,15 @@ func (r *Runtime) refresh(alivePath string) error {
 		return err
 	}
 
+	// File locks persist across reboots, unlike SHM locks.
+	// Free them all, so containers, pods, and volumes can reacquire their
+	// locks below.
+	if r.config.LockType == "file" {
+		if err := r.lockManager.FreeAllLocks(); err != nil {
+			return errors.Wrapf(err, "error freeing file locks")
+		}
+	}
+
 	// Next refresh the state of all containers to recreate dirs and
 	// namespaces, and all the pods to recreate cgroups
 	ctrs, err := r.state.AllContainers()
@@ -1291,6 +1605,10 @@ func (r *Runtime) refresh(alivePath string) error {
 	if err != nil {
 		return errors.Wrapf(err, "error retrieving all pods from state")
 	}
+	vols, err := r.state.AllVolumes()
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving all volumes from state")
+	}
 	// No locks are taken during pod and container refresh.
 	// Furthermore, the pod and container refresh() functions are not
 	// allowed to take locks themselves.
@@ -1308,6 +1626,11 @@ func (r *Runtime) refresh(alivePath string) error {
 			logrus.Errorf("Error refreshing pod %s: %v", pod.ID(), err)
 		}
 	}
+	for _, vol := range vols {
+		if err := vol.refresh(); err != nil {
+			logrus.Errorf("Error refreshing volume %s: %v", vol.Name(), err)
+		}
+	}
 
 	// Create a file indicating the runtime is alive and ready
 	file, err := os.OpenFile(alivePath, os.O_RDONLY|os.O_CREATE, 0644)
@@ -1424,3 +1747,41 @@ func (r *Runtime) ImageRuntime() *image.Runtime {
 func (r *Runtime) SystemContext() *types.SystemContext {
 	return r.imageContext
 }
+
+// namespaceTmpDir returns the temporary files directory configured for the
+// given libpod namespace, or "" if the namespace uses the runtime's.
+func (r *Runtime) namespaceTmpDir(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return r.config.NamespaceDirs[namespace].TmpDir
+}
+
+// namespaceLogDir returns the container log directory configured for the given
+// libpod namespace, or "" if containers in the namespace log to their static
+// directories.
+func (r *Runtime) namespaceLogDir(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return r.config.NamespaceDirs[namespace].LogDir
+}
+
+// makeNamespaceDirs creates the directories configured for libpod namespaces.
+func (r *Runtime) makeNamespaceDirs() error {
+	for namespace, nsDirs := range r.config.NamespaceDirs {
+		var dirs []string
+		if nsDirs.TmpDir != "" {
+			dirs = append(dirs, filepath.Join(nsDirs.TmpDir, "exits"), filepath.Join(nsDirs.TmpDir, "socket"))
+		}
+		if nsDirs.LogDir != "" {
+			dirs = append(dirs, nsDirs.LogDir)
+		}
+		for _, dir := range dirs {
+			if err := os.MkdirAll(dir, 0750); err != nil && !os.IsExist(err) {
+				return errors.Wrapf(err, "error creating directory %s for namespace %s", dir, namespace)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package libpod
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/storage"
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// AdoptContainer registers a container that was created in the OCI runtime by
+// another tool, so it can be managed through libpod.
+// The container's configuration is reconstructed from its state in the OCI
+// runtime and the runtime spec in its bundle. The container keeps its ID, and
+// its root filesystem is used in place, as for containers created from a root
+// filesystem. Options are applied before the configuration is reconstructed,
+// and may be used to set the container's name and labels, or the OCI runtime
+// it is found in; the runtime spec is always taken from the bundle.
+// Only created, running and paused containers can be adopted.
+// The adopted processes are not monitored by conmon, so their output is not
+// logged, and their status is only updated from the OCI runtime, by Sync() and
+// the runtime's state sync. Once restarted through libpod, the container is
+// monitored by conmon like any other.
+func (r *Runtime) AdoptContainer(ctx context.Context, ociID string, options ...CtrCreateOption) (c *Container, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	if ociID == "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "must provide the ID of the container to adopt")
+	}
+
+	ctr, err := r.initContainerVariables(new(spec.Spec), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error initializing container variables")
+	}
+	ctr.config.ID = ociID
+
+	for _, option := range options {
+		if err := option(ctr); err != nil {
+			return nil, errors.Wrapf(err, "error running container create option")
+		}
+	}
+	if ctr.config.Rootfs != "" || ctr.config.RootfsImageID != "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "the root filesystem of an adopted container cannot be set")
+	}
+
+	ociRuntime, ok := r.getOCIRuntime(ctr.config.OCIRuntime)
+	if !ok {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "requested OCI runtime %s is not available", ctr.config.OCIRuntime)
+	}
+
+	state, err := ociRuntime.ContainerState(ociID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := adoptedStateTransitions(state.Status); err != nil {
+		return nil, errors.Wrapf(err, "cannot adopt container %s", ociID)
+	}
+
+	rSpec, err := readBundleSpec(state.Bundle)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading runtime spec of container %s", ociID)
+	}
+	if rSpec.Root == nil || rSpec.Root.Path == "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "container %s has no root filesystem", ociID)
+	}
+
+	ctr.config.Spec = rSpec
+	ctr.config.Rootfs = rSpec.Root.Path
+	if !filepath.IsAbs(ctr.config.Rootfs) {
+		ctr.config.Rootfs = filepath.Join(state.Bundle, ctr.config.Rootfs)
+	}
+	if rSpec.Process != nil {
+		ctr.config.Command = rSpec.Process.Args
+	}
+	if !state.Created.IsZero() {
+		ctr.config.CreatedTime = state.Created
+	}
+	ctr.config.ExternallyManaged = true
+
+	ctr, err = r.setupContainer(ctx, ctr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctr.setAdoptedState(state); err != nil {
+		if err2 := r.forgetContainer(ctr); err2 != nil {
+			logrus.Errorf("Error removing container %s after adoption failed: %v", ctr.ID(), err2)
+		}
+		return nil, err
+	}
+
+	return ctr, nil
+}
+
+// forgetContainer rolls back the adoption of a container. Only the records of
+// the container kept by libpod, in the state and in storage, are removed; its
+// processes, in the OCI runtime, and its root filesystem are left alone.
+func (r *Runtime) forgetContainer(ctr *Container) error {
+	var lastErr error
+	if err := r.state.RemoveContainer(ctr); err != nil {
+		lastErr = errors.Wrapf(err, "error removing container %s from state", ctr.ID())
+	}
+	if err := r.storageService.DeleteContainer(ctr.ID()); err != nil && errors.Cause(err) != storage.ErrNotAContainer && errors.Cause(err) != storage.ErrContainerUnknown {
+		if lastErr != nil {
+			logrus.Errorf("%v", lastErr)
+		}
+		lastErr = errors.Wrapf(err, "error removing storage of container %s", ctr.ID())
+	}
+	if err := ctr.lock.Free(); err != nil {
+		if lastErr != nil {
+			logrus.Errorf("%v", lastErr)
+		}
+		lastErr = errors.Wrapf(err, "error freeing lock of container %s", ctr.ID())
+	}
+	ctr.valid = false
+
+	return lastErr
+}
+
+// setAdoptedState moves a newly adopted container to the status reported by
+// the OCI runtime
+func (c *Container) setAdoptedState(state *OCIContainerState) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	transitions, err := adoptedStateTransitions(state.Status)
+	if err != nil {
+		return err
+	}
+	for _, newState := range transitions {
+		if err := c.setState(newState); err != nil {
+			return err
+		}
+	}
+
+	c.state.PID = state.Pid
+	c.state.Unmonitored = true
+	c.state.Mounted = true
+	c.state.Mountpoint = c.config.Rootfs
+	if c.state.State != define.ContainerStateCreated {
+		c.state.StartedTime = c.config.CreatedTime
+	}
+	if err := c.save(); err != nil {
+		return err
+	}
+
+	c.newContainerEvent(events.Adopt)
+	return nil
+}
+
+// adoptedStateTransitions returns the states a newly configured container
+// passes through to reach the given OCI runtime status
+func adoptedStateTransitions(status string) ([]define.ContainerStatus, error) {
+	switch status {
+	case "created":
+		return []define.ContainerStatus{define.ContainerStateCreated}, nil
+	case "running":
+		return []define.ContainerStatus{define.ContainerStateCreated, define.ContainerStateRunning}, nil
+	case "paused":
+		return []define.ContainerStatus{define.ContainerStateCreated, define.ContainerStateRunning, define.ContainerStatePaused}, nil
+	default:
+		return nil, errors.Wrapf(define.ErrCtrStateInvalid, "only created, running and paused containers can be adopted, not %s ones", status)
+	}
+}
+
+// readBundleSpec reads the runtime spec of the OCI bundle at the given path
+func readBundleSpec(bundle string) (*spec.Spec, error) {
+	data, err := ioutil.ReadFile(filepath.Join(bundle, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+	rSpec := new(spec.Spec)
+	if err := json.Unmarshal(data, rSpec); err != nil {
+		return nil, errors.Wrapf(err, "error decoding runtime spec")
+	}
+	return rSpec, nil
+}
@@ -0,0 +1,97 @@
+package libpod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/storage"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptedStateTransitions(t *testing.T) {
+	for _, status := range []string{"created", "running", "paused"} {
+		transitions, err := adoptedStateTransitions(status)
+		require.NoError(t, err)
+
+		// Every transition must be permitted from a configured container
+		state := define.ContainerStateConfigured
+		for _, newState := range transitions {
+			assert.NoError(t, define.CheckStateTransition(state, newState))
+			state = newState
+		}
+		assert.Equal(t, status, state.String())
+	}
+
+	_, err := adoptedStateTransitions("stopped")
+	assert.Equal(t, define.ErrCtrStateInvalid, errors.Cause(err))
+}
+
+func TestReadBundleSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "libpod_test_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = readBundleSpec(dir)
+	assert.True(t, os.IsNotExist(errors.Cause(err)))
+
+	config := `{"ociVersion": "1.0.1", "process": {"args": ["sleep", "100"]}, "root": {"path": "rootfs"}}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644))
+
+	rSpec, err := readBundleSpec(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sleep", "100"}, rSpec.Process.Args)
+	assert.Equal(t, "rootfs", rSpec.Root.Path)
+}
+
+// recordingStore is a container store recording the containers deleted from it
+type recordingStore struct {
+	storage.Store
+	deleted []string
+}
+
+func (s *recordingStore) Container(id string) (*storage.Container, error) {
+	return &storage.Container{ID: id}, nil
+}
+
+func (s *recordingStore) DeleteContainer(id string) error {
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+func TestForgetAdoptedContainer(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	store := &recordingStore{}
+	ociRuntime := &checkpointOCIRuntime{}
+	runtime := &Runtime{
+		config:         &RuntimeConfig{},
+		state:          state,
+		eventer:        events.NewNullEventer(),
+		storageService: &storageService{store: store},
+		valid:          true,
+	}
+	ctr, err := getTestCtr1(manager)
+	require.NoError(t, err)
+	ctr.runtime = runtime
+	ctr.ociRuntime = ociRuntime
+	ctr.state.State = define.ContainerStateRunning
+	require.NoError(t, state.AddContainer(ctr))
+
+	// The adopted processes are left alone
+	require.NoError(t, runtime.forgetContainer(ctr))
+	assert.Empty(t, ociRuntime.calls)
+	assert.Equal(t, []string{ctr.ID()}, store.deleted)
+	assert.False(t, ctr.valid)
+	_, err = state.Container(ctr.ID())
+	assert.Equal(t, define.ErrNoSuchCtr, errors.Cause(err))
+	_, err = manager.AllocateAndRetrieveLock(ctr.config.LockID)
+	assert.NoError(t, err)
+}
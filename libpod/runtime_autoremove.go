@@ -0,0 +1,117 @@
+package libpod
+
+import (
+	"context"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// PendingRemovalGracePeriod is how long a container queued for removal must
+// have been stopped before it is removed by the reaper. It leaves time for the
+// processes that normally remove auto-remove containers to do so.
+const PendingRemovalGracePeriod = time.Minute
+
+// autoRemove returns whether the container is removed once it exits
+func (c *Container) autoRemove() bool {
+	if c.config.Spec == nil {
+		return false
+	}
+	return c.config.Spec.Annotations[InspectAnnotationAutoremove] == InspectResponseTrue
+}
+
+// RunReaper removes containers queued for removal until the context is
+// cancelled. The queue is checked every interval.
+func (r *Runtime) RunReaper(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "reaper interval must be greater than 0")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.ReapPendingRemovals(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReapPendingRemovals removes the containers queued for removal that have been
+// stopped for longer than PendingRemovalGracePeriod, and returns their IDs.
+// Containers that cannot be removed yet stay queued.
+func (r *Runtime) ReapPendingRemovals(ctx context.Context) ([]string, error) {
+	r.lock.RLock()
+	if !r.valid {
+		r.lock.RUnlock()
+		return nil, define.ErrRuntimeStopped
+	}
+	pending, err := r.state.PendingRemovals()
+	r.lock.RUnlock()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving containers queued for removal")
+	}
+
+	removed := []string{}
+	now := time.Now()
+	for id, queued := range pending {
+		ctr, err := r.LookupContainer(id)
+		if err != nil {
+			if errors.Cause(err) != define.ErrNoSuchCtr {
+				logrus.Errorf("Error retrieving container %s queued for removal: %v", id, err)
+			}
+			continue
+		}
+
+		reap, err := ctr.reapable(queued, now)
+		if err != nil {
+			if !isRemovedError(err) {
+				logrus.Errorf("Error checking container %s queued for removal: %v", id, err)
+			}
+			continue
+		}
+		if !reap {
+			continue
+		}
+
+		logrus.Debugf("Removing container %s queued for removal", id)
+		if err := r.RemoveContainer(ctx, ctr, false, false); err != nil {
+			cause := errors.Cause(err)
+			if cause != define.ErrNoSuchCtr && cause != define.ErrCtrRemoved && cause != define.ErrCtrStateInvalid {
+				logrus.Errorf("Error removing container %s queued for removal: %v", id, err)
+			}
+			continue
+		}
+		removed = append(removed, id)
+	}
+	return removed, nil
+}
+
+// reapable returns whether the container, queued for removal at the given
+// time, has been stopped long enough to be removed
+func (c *Container) reapable(queued, now time.Time) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.syncContainer(); err != nil {
+		return false, err
+	}
+
+	switch c.state.State {
+	case define.ContainerStateRunning, define.ContainerStatePaused:
+		return false, nil
+	}
+
+	since := queued
+	if c.state.FinishedTime.After(since) {
+		since = c.state.FinishedTime
+	}
+	return now.Sub(since) >= PendingRemovalGracePeriod, nil
+}
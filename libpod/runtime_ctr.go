@@ -2,15 +2,16 @@ package libpod
 
 import (
 	"context"
+	"fmt"
 	"os"
-	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	config2 "github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/events"
-	"github.com/containers/libpod/pkg/rootless"
+	"github.com/containers/libpod/pkg/schedule"
 	"github.com/containers/storage/pkg/stringid"
 	spec "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/opencontainers/runtime-tools/generate"
@@ -54,6 +55,9 @@ func (r *Runtime) RestoreContainer(ctx context.Context, rSpec *spec.Spec, config
 	}
 	// For an imported checkpoint no one has ever set the StartedTime. Set it now.
 	ctr.state.StartedTime = time.Now()
+	// The processes of an imported checkpoint were not started by us, so
+	// keep its status in sync in the background
+	ctr.config.ExternallyManaged = true
 
 	// If the path to ConmonPidFile starts with the default value (RunRoot), then
 	// the user has not specified '--conmon-pidfile' during run or create (probably).
@@ -76,6 +80,7 @@ func (r *Runtime) initContainerVariables(rSpec *spec.Spec, config *ContainerConf
 	if config == nil {
 		ctr.config.ID = stringid.GenerateNonCryptoID()
 		ctr.config.ShmSize = DefaultShmSize
+		ctr.config.HTTPProxy = r.config.HTTPProxy
 	} else {
 		// This is a restore from an imported checkpoint
 		ctr.restoreFromCheckpoint = true
@@ -102,7 +107,7 @@ func (r *Runtime) initContainerVariables(rSpec *spec.Spec, config *ContainerConf
 
 	ctr.config.StopTimeout = config2.CtrRemoveTimeout
 
-	ctr.config.OCIRuntime = r.defaultOCIRuntime.name
+	ctr.config.OCIRuntime = r.defaultOCIRuntime.Name()
 
 	// Set namespace based on current runtime namespace
 	// Do so before options run so they can override it
@@ -130,16 +135,26 @@ func (r *Runtime) newContainer(ctx context.Context, rSpec *spec.Spec, options ..
 			return nil, errors.Wrapf(err, "error running container create option")
 		}
 	}
+
+	if ctr.config.HTTPProxy {
+		ctr.addHostProxyEnv()
+	}
+
 	return r.setupContainer(ctx, ctr)
 }
 
 func (r *Runtime) setupContainer(ctx context.Context, ctr *Container) (c *Container, err error) {
+	// Only the json-file log driver rotates the log
+	if (ctr.config.LogMaxSize != 0 || ctr.config.LogMaxFiles != 0) && ctr.config.LogDriver != JSONLogging {
+		return nil, errors.Wrapf(config2.ErrInvalidArg, "log max-size and max-file are only supported by the %s log driver", JSONLogging)
+	}
+
 	// Allocate a lock for the container
 	lock, err := r.lockManager.AllocateLock()
 	if err != nil {
-		return nil, errors.Wrapf(err, "error allocating lock for new container")
+		return nil, errors.Wrapf(r.lockAllocationError(err), "error allocating lock for new container")
 	}
-	ctr.lock = lock
+	ctr.lock = ctrLocker(lock, ctr.ID())
 	ctr.config.LockID = ctr.lock.ID()
 	logrus.Debugf("Allocated lock %d for container %s", ctr.lock.ID(), ctr.ID())
 
@@ -155,14 +170,15 @@ func (r *Runtime) setupContainer(ctx context.Context, ctr *Container) (c *Contai
 	ctr.state.State = config2.ContainerStateConfigured
 	ctr.runtime = r
 
-	if ctr.config.OCIRuntime == "" {
-		ctr.ociRuntime = r.defaultOCIRuntime
-	} else {
-		ociRuntime, ok := r.ociRuntimes[ctr.config.OCIRuntime]
-		if !ok {
-			return nil, errors.Wrapf(config2.ErrInvalidArg, "requested OCI runtime %s is not available", ctr.config.OCIRuntime)
-		}
-		ctr.ociRuntime = ociRuntime
+	ociRuntime, ok := r.getOCIRuntime(ctr.config.OCIRuntime)
+	if !ok {
+		return nil, errors.Wrapf(config2.ErrInvalidArg, "requested OCI runtime %s is not available", ctr.config.OCIRuntime)
+	}
+	ctr.ociRuntime = ociRuntime
+
+	// Refuse containers the runtime cannot run before anything is created
+	if err := validateOCIRuntimeFeatures(ctr.ociRuntime, r.config.NoPivotRoot); err != nil {
+		return nil, err
 	}
 
 	var pod *Pod
@@ -172,6 +188,51 @@ func (r *Runtime) setupContainer(ctx context.Context, ctr *Container) (c *Contai
 		if err != nil {
 			return nil, errors.Wrapf(err, "cannot add container %s to pod %s", ctr.ID(), ctr.config.Pod)
 		}
+		if err := pod.addVolumesToContainer(ctr); err != nil {
+			return nil, err
+		}
+	}
+
+	if ctr.config.StartSchedule != "" {
+		if ctr.config.IsInfra || ctr.config.InitContainerType != "" {
+			return nil, errors.Wrapf(config2.ErrInvalidArg, "infra and init containers cannot have a start schedule")
+		}
+		startSchedule, err := schedule.Parse(ctr.config.StartSchedule)
+		if err != nil {
+			return nil, errors.Wrapf(config2.ErrInvalidArg, "%v", err)
+		}
+		ctr.state.ScheduleNextRun = startSchedule.Next(ctr.config.CreatedTime)
+	}
+
+	if ctr.config.InitContainerType != "" {
+		if err := r.validateInitCtr(ctr, pod); err != nil {
+			return nil, err
+		}
+	} else if pod != nil {
+		// Init containers have exited by the time other containers in
+		// the pod start, so nothing can depend on them
+		for _, dep := range ctr.Dependencies() {
+			depCtr, err := r.state.Container(dep)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error retrieving dependency %s of container %s", dep, ctr.ID())
+			}
+			if depCtr.IsInitCtr() {
+				return nil, errors.Wrapf(config2.ErrInvalidArg, "container %s cannot depend on init container %s", ctr.ID(), dep)
+			}
+		}
+	}
+
+	// Conditions can only be placed on actual dependencies
+	if len(ctr.config.DependencyConditions) > 0 {
+		deps := make(map[string]bool)
+		for _, dep := range ctr.Dependencies() {
+			deps[dep] = true
+		}
+		for dep := range ctr.config.DependencyConditions {
+			if !deps[dep] {
+				return nil, errors.Wrapf(config2.ErrInvalidArg, "a condition was set for container %s, which is not a dependency", dep)
+			}
+		}
 	}
 
 	if ctr.config.Name == "" {
@@ -184,42 +245,31 @@ func (r *Runtime) setupContainer(ctx context.Context, ctr *Container) (c *Contai
 	}
 
 	// Check CGroup parent sanity, and set it if it was not set
-	switch r.config.CgroupManager {
-	case CgroupfsCgroupsManager:
-		if ctr.config.CgroupParent == "" {
-			if pod != nil && pod.config.UsePodCgroup {
-				podCgroup, err := pod.CgroupPath()
-				if err != nil {
-					return nil, errors.Wrapf(err, "error retrieving pod %s cgroup", pod.ID())
-				}
-				if podCgroup == "" {
-					return nil, errors.Wrapf(config2.ErrInternal, "pod %s cgroup is not set", pod.ID())
-				}
-				ctr.config.CgroupParent = podCgroup
-			} else {
-				ctr.config.CgroupParent = CgroupfsDefaultCgroupParent
+	if ctr.config.CgroupParent == "" {
+		if pod != nil && pod.config.UsePodCgroup {
+			podCgroup, err := pod.CgroupPath()
+			if err != nil {
+				return nil, errors.Wrapf(err, "error retrieving pod %s cgroup", pod.ID())
 			}
-		} else if strings.HasSuffix(path.Base(ctr.config.CgroupParent), ".slice") {
-			return nil, errors.Wrapf(config2.ErrInvalidArg, "systemd slice received as cgroup parent when using cgroupfs")
-		}
-	case SystemdCgroupsManager:
-		if ctr.config.CgroupParent == "" {
-			if pod != nil && pod.config.UsePodCgroup {
-				podCgroup, err := pod.CgroupPath()
-				if err != nil {
-					return nil, errors.Wrapf(err, "error retrieving pod %s cgroup", pod.ID())
-				}
-				ctr.config.CgroupParent = podCgroup
-			} else if rootless.IsRootless() {
-				ctr.config.CgroupParent = SystemdDefaultRootlessCgroupParent
-			} else {
-				ctr.config.CgroupParent = SystemdDefaultCgroupParent
+			if podCgroup == "" {
+				return nil, errors.Wrapf(config2.ErrInternal, "pod %s cgroup is not set", pod.ID())
 			}
-		} else if len(ctr.config.CgroupParent) < 6 || !strings.HasSuffix(path.Base(ctr.config.CgroupParent), ".slice") {
-			return nil, errors.Wrapf(config2.ErrInvalidArg, "did not receive systemd slice as cgroup parent when using systemd to manage cgroups")
+			ctr.config.CgroupParent = podCgroup
+		} else {
+			ctr.config.CgroupParent = r.getCgroupManager().defaultParent()
+		}
+	} else if err := r.getCgroupManager().validateParent(ctr.config.CgroupParent); err != nil {
+		return nil, err
+	}
+
+	if pod != nil {
+		var resources *spec.LinuxResources
+		if ctr.config.Spec.Linux != nil {
+			resources = ctr.config.Spec.Linux.Resources
+		}
+		if err := pod.checkContainerResources(ctr, resources); err != nil {
+			return nil, err
 		}
-	default:
-		return nil, errors.Wrapf(config2.ErrInvalidArg, "unsupported CGroup manager: %s - cannot validate cgroup parent", r.config.CgroupManager)
 	}
 
 	if ctr.restoreFromCheckpoint {
@@ -235,6 +285,21 @@ func (r *Runtime) setupContainer(ctx context.Context, ctr *Container) (c *Contai
 		g.RemoveMount("/run/secrets")
 	}
 
+	// The user namespace must be allocated before storage is set up, as
+	// the files of the container are owned by its IDs
+	if ctr.config.AutoUserNS {
+		if err := r.allocateAutoUserNS(ctr); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				if err2 := r.state.ReleaseUserNSRange(ctr.ID()); err2 != nil {
+					logrus.Errorf("Error releasing user namespace of partially-created container %s: %v", ctr.ID(), err2)
+				}
+			}
+		}()
+	}
+
 	// Set up storage for the container
 	if err := ctr.setupStorage(ctx); err != nil {
 		return nil, err
@@ -269,21 +334,26 @@ func (r *Runtime) setupContainer(ctx context.Context, ctr *Container) (c *Contai
 		logrus.Debugf("Creating new volume %s for container", vol.Name)
 
 		// The volume does not exist, so we need to create it.
-		newVol, err := r.newVolume(ctx, WithVolumeName(vol.Name), withSetCtrSpecific(),
-			WithVolumeUID(ctr.RootUID()), WithVolumeGID(ctr.RootGID()))
+		// Volumes of the pod are shared with its other containers, so
+		// they are not removed with this one.
+		volOptions := []VolumeCreateOption{WithVolumeName(vol.Name), WithVolumeUID(ctr.RootUID()), WithVolumeGID(ctr.RootGID())}
+		if pod == nil || !pod.hasVolume(vol.Name) {
+			volOptions = append(volOptions, withSetCtrSpecific())
+		}
+		newVol, err := r.newVolume(ctx, volOptions...)
 		if err != nil {
 			return nil, errors.Wrapf(err, "error creating named volume %q", vol.Name)
 		}
 
-		if err := ctr.copyWithTarFromImage(vol.Dest, newVol.MountPoint()); err != nil && !os.IsNotExist(err) {
-			return nil, errors.Wrapf(err, "Failed to copy content into new volume mount %q", vol.Name)
-		}
-
 		ctrNamedVolumes = append(ctrNamedVolumes, newVol)
 	}
 
 	if ctr.config.LogPath == "" && ctr.config.LogDriver != JournaldLogging {
-		ctr.config.LogPath = filepath.Join(ctr.config.StaticDir, "ctr.log")
+		if logDir := r.namespaceLogDir(ctr.config.Namespace); logDir != "" {
+			ctr.config.LogPath = filepath.Join(logDir, ctr.ID()+".log")
+		} else {
+			ctr.config.LogPath = filepath.Join(ctr.config.StaticDir, "ctr.log")
+		}
 	}
 
 	if !MountExists(ctr.config.Spec.Mounts, "/dev/shm") && ctr.config.ShmDir == "" {
@@ -324,6 +394,46 @@ func (r *Runtime) setupContainer(ctx context.Context, ctr *Container) (c *Contai
 	return ctr, nil
 }
 
+// validateInitCtr checks that an init container can be added to its pod.
+// Dependencies on other init containers are given a condition requiring that
+// they have exited successfully, as init containers run to completion one
+// after another.
+func (r *Runtime) validateInitCtr(ctr *Container, pod *Pod) error {
+	if pod == nil {
+		return errors.Wrapf(config2.ErrInvalidArg, "init container %s must be part of a pod", ctr.ID())
+	}
+	if ctr.config.IsInfra {
+		return errors.Wrapf(config2.ErrInvalidArg, "infra container %s cannot be an init container", ctr.ID())
+	}
+	if ctr.config.RestartPolicy != RestartPolicyNone && ctr.config.RestartPolicy != RestartPolicyNo {
+		return errors.Wrapf(config2.ErrInvalidArg, "init container %s cannot have a restart policy", ctr.ID())
+	}
+
+	for _, dep := range ctr.Dependencies() {
+		depCtr, err := r.state.Container(dep)
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving dependency %s of init container %s", dep, ctr.ID())
+		}
+		if depCtr.IsInfra() {
+			continue
+		}
+		if !depCtr.IsInitCtr() {
+			return errors.Wrapf(config2.ErrInvalidArg, "init container %s can only depend on the infra container and other init containers of its pod, not %s", ctr.ID(), dep)
+		}
+		condition, ok := ctr.config.DependencyConditions[dep]
+		if !ok {
+			if ctr.config.DependencyConditions == nil {
+				ctr.config.DependencyConditions = make(map[string]config2.DependencyCondition)
+			}
+			ctr.config.DependencyConditions[dep] = config2.DependencyConditionExitedSuccessfully
+		} else if condition != config2.DependencyConditionExitedSuccessfully {
+			return errors.Wrapf(config2.ErrInvalidArg, "init container %s must wait for init container %s to exit successfully, not be %s", ctr.ID(), dep, condition)
+		}
+	}
+
+	return nil
+}
+
 // RemoveContainer removes the given container
 // If force is specified, the container will be stopped first
 // If removeVolume is specified, named volumes used by the container will
@@ -335,6 +445,67 @@ func (r *Runtime) RemoveContainer(ctx context.Context, c *Container, force bool,
 	return r.removeContainer(ctx, c, force, removeVolume, false)
 }
 
+// UpdateContainer changes the resource limits of a container.
+// Running containers are updated immediately through the OCI runtime. The new
+// limits are also saved in the container's configuration, so they are kept
+// when the container is restarted.
+func (r *Runtime) UpdateContainer(c *Container, update *ContainerResourceUpdate) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return config2.ErrRuntimeStopped
+	}
+
+	if !c.valid {
+		return config2.ErrCtrRemoved
+	}
+
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return err
+		}
+	}
+
+	return c.update(update)
+}
+
+// MigrateContainerRuntime moves a stopped container to another OCI runtime,
+// such as from runc to crun, without recreating it. The new runtime must be
+// present in the current configuration; it may be given as a literal path, as
+// used by legacy containers, in which case the runtime of the same name is
+// used. Containers whose runtime is a literal path are rewritten to use the
+// runtime's name.
+// Stopped containers are deleted from their current runtime first. Containers
+// whose current runtime is no longer configured can be moved as well, leaving
+// their files in the missing runtime behind.
+func (r *Runtime) MigrateContainerRuntime(ctx context.Context, c *Container, newRuntime string) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return config2.ErrRuntimeStopped
+	}
+
+	if !c.valid {
+		return config2.ErrCtrRemoved
+	}
+
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if err := c.syncContainer(); err != nil {
+			return err
+		}
+	}
+
+	return c.migrateRuntime(ctx, newRuntime)
+}
+
 // Internal function to remove a container.
 // Locks the container, but does not lock the runtime.
 // removePod is used only when removing pods. It instructs Podman to ignore
@@ -394,16 +565,24 @@ func (r *Runtime) removeContainer(ctx context.Context, c *Container, force bool,
 		return err
 	}
 
+	// A container already in the Removing state had a previous removal
+	// interrupted partway through teardown. It is no longer running, so
+	// skip straight to resuming cleanup.
+	resuming := c.state.State == config2.ContainerStateRemoving
+	if resuming {
+		logrus.Infof("Resuming interrupted removal of container %s", c.ID())
+	}
+
 	// If we're not force-removing, we need to check if we're in a good
 	// state to remove.
-	if !force {
+	if !force && !resuming {
 		if err := c.checkReadyForRemoval(); err != nil {
 			return err
 		}
 	}
 
 	if c.state.State == config2.ContainerStatePaused {
-		if err := c.ociRuntime.killContainer(c, 9); err != nil {
+		if err := c.ociRuntime.KillContainer(c, 9, false); err != nil {
 			return err
 		}
 		if err := c.unpause(); err != nil {
@@ -423,8 +602,8 @@ func (r *Runtime) removeContainer(ctx context.Context, c *Container, force bool,
 	}
 
 	// Check that all of our exec sessions have finished
-	if len(c.state.ExecSessions) != 0 {
-		if err := c.ociRuntime.execStopContainer(c, c.StopTimeout()); err != nil {
+	if len(c.state.ExecSessions) != 0 && !resuming {
+		if err := c.ociRuntime.ExecStopContainer(c, c.StopTimeout()); err != nil {
 			return err
 		}
 	}
@@ -433,41 +612,58 @@ func (r *Runtime) removeContainer(ctx context.Context, c *Container, force bool,
 	// Only used if not removing a pod - pods guarantee that all
 	// deps will be evicted at the same time.
 	if !removePod {
-		deps, err := r.state.ContainerInUse(c)
+		deps, err := r.state.ContainerDependencyConditions(c)
 		if err != nil {
 			return err
 		}
 		if len(deps) != 0 {
-			depsStr := strings.Join(deps, ", ")
+			depsList := make([]string, 0, len(deps))
+			for dep, condition := range deps {
+				depsList = append(depsList, fmt.Sprintf("%s (waiting for it to be %s)", dep, condition))
+			}
+			sort.Strings(depsList)
+			depsStr := strings.Join(depsList, ", ")
 			return errors.Wrapf(config2.ErrCtrExists, "container %s has dependent containers which must be removed before it: %s", c.ID(), depsStr)
 		}
 	}
 
-	var cleanupErr error
-	// Remove the container from the state
-	if c.config.Pod != "" {
-		// If we're removing the pod, the container will be evicted
-		// from the state elsewhere
-		if !removePod {
-			if err := r.state.RemoveContainerFromPod(pod, c); err != nil {
-				cleanupErr = err
-			}
+	// Record that removal has begun before tearing anything down, so an
+	// interrupted removal leaves the container visibly half-removed rather
+	// than in a state that no longer matches reality.
+	prevState := c.state.State
+	if !resuming {
+		if err := c.setState(config2.ContainerStateRemoving); err != nil {
+			return err
 		}
-	} else {
-		if err := r.state.RemoveContainer(c); err != nil {
-			cleanupErr = err
+		if err := c.save(); err != nil {
+			return err
 		}
 	}
 
-	// Set container as invalid so it can no longer be used
-	c.valid = false
+	var cleanupErr error
 
 	// Clean up network namespace, cgroups, mounts
 	if err := c.cleanup(ctx); err != nil {
-		if cleanupErr == nil {
-			cleanupErr = errors.Wrapf(err, "error cleaning up container %s", c.ID())
-		} else {
-			logrus.Errorf("cleanup network, cgroups, mounts: %v", err)
+		cleanupErr = errors.Wrapf(err, "error cleaning up container %s", c.ID())
+	}
+
+	// Delete the container.
+	// Not needed in Configured and Exited states, where the container
+	// doesn't exist in the runtime. When resuming we cannot tell whether
+	// the previous attempt got this far, so failures are not fatal.
+	if prevState != config2.ContainerStateConfigured &&
+		prevState != config2.ContainerStateExited {
+		if err := c.removeConmonFiles(); err != nil {
+			logrus.Debugf("Error removing conmon files for container %s: %v", c.ID(), err)
+		}
+		if err := c.delete(ctx); err != nil {
+			if resuming {
+				logrus.Debugf("Error deleting container %s from runtime during resumed removal: %v", c.ID(), err)
+			} else if cleanupErr == nil {
+				cleanupErr = err
+			} else {
+				logrus.Errorf("delete container: %v", err)
+			}
 		}
 	}
 
@@ -480,20 +676,45 @@ func (r *Runtime) removeContainer(ctx context.Context, c *Container, force bool,
 		}
 	}
 
-	// Delete the container.
-	// Not needed in Configured and Exited states, where the container
-	// doesn't exist in the runtime
-	if c.state.State != config2.ContainerStateConfigured &&
-		c.state.State != config2.ContainerStateExited {
-		if err := c.delete(ctx); err != nil {
+	// Logs in the namespace's log directory are not removed with the
+	// container's storage
+	if logDir := r.namespaceLogDir(c.config.Namespace); logDir != "" && filepath.Dir(c.config.LogPath) == filepath.Clean(logDir) {
+		if err := os.Remove(c.config.LogPath); err != nil && !os.IsNotExist(err) {
+			if cleanupErr == nil {
+				cleanupErr = errors.Wrapf(err, "error removing log file for container %s", c.ID())
+			} else {
+				logrus.Errorf("remove container log: %v", err)
+			}
+		}
+	}
+
+	// Remove the container from the state now that teardown is complete
+	if c.config.Pod != "" {
+		// If we're removing the pod, the container will be evicted
+		// from the state elsewhere
+		if !removePod {
+			if err := r.state.RemoveContainerFromPod(pod, c); err != nil {
+				if cleanupErr == nil {
+					cleanupErr = err
+				} else {
+					logrus.Errorf("remove container from pod: %v", err)
+				}
+			}
+		}
+	} else {
+		if err := r.state.RemoveContainer(c); err != nil {
 			if cleanupErr == nil {
 				cleanupErr = err
 			} else {
-				logrus.Errorf("delete container: %v", err)
+				logrus.Errorf("remove container from state: %v", err)
 			}
 		}
 	}
 
+	// Set container as invalid so it can no longer be used
+	c.valid = false
+	r.stateWatch.notify(c.ID())
+
 	// Deallocate the container's lock
 	if err := c.lock.Free(); err != nil {
 		if cleanupErr == nil {
@@ -503,6 +724,17 @@ func (r *Runtime) removeContainer(ctx context.Context, c *Container, force bool,
 		}
 	}
 
+	// Release the container's user namespace, now that its files are gone
+	if c.config.AutoUserNS {
+		if err := r.state.ReleaseUserNSRange(c.ID()); err != nil {
+			if cleanupErr == nil {
+				cleanupErr = errors.Wrapf(err, "error releasing user namespace of container %s", c.ID())
+			} else {
+				logrus.Errorf("release container user namespace: %v", err)
+			}
+		}
+	}
+
 	c.newContainerEvent(events.Remove)
 
 	if !removeVolume {
@@ -547,6 +779,20 @@ func (r *Runtime) HasContainer(id string) (bool, error) {
 	return r.state.HasContainer(id)
 }
 
+// GetContainerExitCode returns the exit code recorded when the container with
+// the given full ID last exited. Exit codes are kept for a while after being
+// recorded, even if their containers are removed in the meantime.
+func (r *Runtime) GetContainerExitCode(id string) (int32, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return -1, config2.ErrRuntimeStopped
+	}
+
+	return r.state.GetContainerExitCode(id)
+}
+
 // LookupContainer looks up a container by its name or a partial ID
 // If a partial ID is not unique, an error will be returned
 func (r *Runtime) LookupContainer(idOrName string) (*Container, error) {
@@ -597,6 +843,20 @@ func (r *Runtime) GetAllContainers() ([]*Container, error) {
 	return r.state.AllContainers()
 }
 
+// GetAllContainersSummaries retrieves summaries of all containers.
+// Summaries are retrieved without locking the containers, and may not reflect
+// changes in container state that have not yet been synced.
+func (r *Runtime) GetAllContainersSummaries() ([]*ContainerSummary, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, config2.ErrRuntimeStopped
+	}
+
+	return r.state.AllContainersSummaries()
+}
+
 // GetRunningContainers is a helper function for GetContainers
 func (r *Runtime) GetRunningContainers() ([]*Container, error) {
 	running := func(c *Container) bool {
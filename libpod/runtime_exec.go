@@ -0,0 +1,65 @@
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+)
+
+// GetExecSessionContainer returns the container the exec session with the
+// given ID runs in
+func (r *Runtime) GetExecSessionContainer(id string) (*Container, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	ctrID, err := r.state.ExecSessionContainer(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.state.Container(ctrID)
+}
+
+// ReapExecSessions removes the detached exec sessions of all containers whose
+// processes have exited, and returns their exit codes by session ID
+func (r *Runtime) ReapExecSessions() (map[string]int, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	sessions, err := r.state.AllExecSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions are reaped once per container
+	ctrIDs := make(map[string]bool)
+	for _, ctrID := range sessions {
+		ctrIDs[ctrID] = true
+	}
+
+	exitCodes := make(map[string]int)
+	for ctrID := range ctrIDs {
+		ctr, err := r.state.Container(ctrID)
+		if err != nil {
+			if errors.Cause(err) == define.ErrNoSuchCtr {
+				continue
+			}
+			return nil, err
+		}
+		reaped, err := ctr.ReapExecSessions()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reaping exec sessions of container %s", ctrID)
+		}
+		for id, exitCode := range reaped {
+			exitCodes[id] = exitCode
+		}
+	}
+
+	return exitCodes, nil
+}
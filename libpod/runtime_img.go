@@ -145,6 +145,24 @@ func removeStorageContainers(ctrIDs []string, store storage.Store) error {
 	return nil
 }
 
+// ImagesExist reports, for each of the given references, whether it resolves
+// to a local image, and if so the image's ID and digests.
+// Local storage is read once for all references, so orchestrators can check
+// large numbers of images without a lookup per image.
+func (r *Runtime) ImagesExist(references []string) ([]image.Presence, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+	if r.imageRuntime == nil {
+		return nil, errors.Wrapf(define.ErrInternal, "runtime has no image storage")
+	}
+
+	return r.imageRuntime.ImagesExist(references)
+}
+
 // Build adds the runtime to the imagebuildah call
 func (r *Runtime) Build(ctx context.Context, options imagebuildah.BuildOptions, dockerfiles ...string) error {
 	_, _, err := imagebuildah.BuildDockerfiles(ctx, r.store, options, dockerfiles...)
@@ -0,0 +1,80 @@
+package libpod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateFileLocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-locks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	oldPath := filepath.Join(dir, "tmp", "locks")
+	newPath := filepath.Join(dir, "static", "locks")
+
+	// Nothing to move
+	require.NoError(t, migrateFileLocks(oldPath, newPath))
+	_, err = os.Stat(newPath)
+	assert.True(t, os.IsNotExist(err))
+
+	manager, err := lock.NewFileLockManager(oldPath)
+	require.NoError(t, err)
+	_, err = manager.AllocateAndRetrieveLock(3)
+	require.NoError(t, err)
+
+	require.NoError(t, migrateFileLocks(oldPath, newPath))
+	_, err = os.Stat(oldPath)
+	assert.True(t, os.IsNotExist(err))
+	manager, err = lock.OpenFileLockManager(newPath)
+	require.NoError(t, err)
+	_, err = manager.AllocateAndRetrieveLock(3)
+	assert.Error(t, err)
+
+	// Locks already in the new directory are kept
+	_, err = lock.NewFileLockManager(oldPath)
+	require.NoError(t, err)
+	require.NoError(t, migrateFileLocks(oldPath, newPath))
+	_, err = os.Stat(oldPath)
+	assert.NoError(t, err)
+}
+
+func TestRefreshReallocatesVolumeLocks(t *testing.T) {
+	state, path, _, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	manager, err := lock.NewFileLockManager(filepath.Join(path, "file-locks"))
+	require.NoError(t, err)
+	runtime := &Runtime{
+		config:      &RuntimeConfig{LockType: "file"},
+		state:       state,
+		eventer:     events.NewNullEventer(),
+		lockManager: manager,
+	}
+
+	volLock, err := manager.AllocateLock()
+	require.NoError(t, err)
+	vol, err := newVolume(runtime)
+	require.NoError(t, err)
+	vol.config.Name = "test"
+	vol.config.LockID = volLock.ID()
+	vol.lock = volLock
+	vol.valid = true
+	require.NoError(t, state.AddVolume(vol))
+
+	require.NoError(t, runtime.refresh(filepath.Join(path, "alive")))
+
+	// The lock of the volume is allocated again
+	_, err = manager.AllocateAndRetrieveLock(vol.config.LockID)
+	assert.Error(t, err)
+	newLock, err := manager.AllocateLock()
+	require.NoError(t, err)
+	assert.NotEqual(t, vol.config.LockID, newLock.ID())
+}
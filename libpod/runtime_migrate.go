@@ -1,78 +1,186 @@
-// +build linux
-
 package libpod
 
 import (
 	"context"
-	"fmt"
-	"github.com/containers/libpod/pkg/util"
-	"io/ioutil"
-	"os"
 	"path/filepath"
-	"strconv"
-	"syscall"
+	"strings"
 
-	"github.com/containers/libpod/pkg/rootless"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
-func stopPauseProcess() error {
-	if rootless.IsRootless() {
-		pausePidPath, err := util.GetRootlessPauseProcessPidPath()
+// MigrateOptions are the options of Migrate
+type MigrateOptions struct {
+	// NewRuntime is the name of the OCI runtime all containers are moved
+	// to. Containers keep their OCI runtime if empty.
+	NewRuntime string
+	// RenumberLocks reassigns lock numbers for all containers, pods, and
+	// volumes, as RenumberLocks does.
+	RenumberLocks bool
+	// KeepPauseProcess keeps the pause process of rootless users running.
+	// It is stopped by default, so that the next command creates the user
+	// namespace anew. Migrating does not change the mappings of the user
+	// namespace itself; the new namespace uses whatever /etc/subuid and
+	// /etc/subgid configure at that time.
+	KeepPauseProcess bool
+}
+
+// MigrateReport describes the changes made by a migration.
+type MigrateReport struct {
+	// StoppedContainers are the IDs of the containers stopped to be
+	// migrated. They are not restarted.
+	StoppedContainers []string
+	// RewrittenContainers are the IDs of the containers whose
+	// configuration was rewritten.
+	RewrittenContainers []string
+	// FailedContainers gives the error of each container that could not
+	// be migrated, by container ID.
+	FailedContainers map[string]error
+	// Locks gives the lock numbers assigned, if locks were renumbered.
+	Locks *LockRenumberReport
+	// PauseProcessStopped indicates that the rootless pause process was
+	// stopped.
+	PauseProcessStopped bool
+}
+
+// Migrate migrates containers to account for changes between Libpod versions.
+// This is the equivalent of 'podman system migrate', performed on a running
+// runtime.
+// Running and created containers are stopped, and are not restarted. The
+// deprecated configuration of containers is rewritten, such as OCI runtimes
+// given as literal paths, which are replaced by the name of the runtime.
+// Migrating fails if any container is paused. A container that cannot be
+// migrated does not prevent the others from being migrated; its error is
+// given in the report, and an error is returned once all containers have been
+// handled.
+// WARNING: As with RenumberLocks, no other libpod instances may be running
+// when locks are renumbered. Containers, pods, and volumes retrieved from the
+// runtime before migrating must be retrieved again afterwards.
+func (r *Runtime) Migrate(ctx context.Context, options MigrateOptions) (*MigrateReport, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	return r.migrate(ctx, options)
+}
+
+// migrate performs a migration. The runtime must be locked, or not yet
+// returned by NewRuntime.
+func (r *Runtime) migrate(ctx context.Context, options MigrateOptions) (*MigrateReport, error) {
+	report := &MigrateReport{FailedContainers: make(map[string]error)}
+
+	allCtrs, err := r.state.AllContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	var toStop []*Container
+	for _, ctr := range allCtrs {
+		state, err := ctr.State()
 		if err != nil {
-			return errors.Wrapf(err, "could not get pause process pid file path")
+			return nil, err
+		}
+		switch state {
+		case define.ContainerStatePaused:
+			return nil, errors.Wrapf(define.ErrCtrStateInvalid, "container %s is paused, it must be unpaused to be migrated", ctr.ID())
+		case define.ContainerStateRunning, define.ContainerStateCreated:
+			toStop = append(toStop, ctr)
+		}
+	}
+
+	logrus.Infof("stopping all containers")
+	for _, ctr := range toStop {
+		if err := ctr.Stop(); err != nil && errors.Cause(err) != define.ErrCtrStopped {
+			return report, errors.Wrapf(err, "cannot stop container %s", ctr.ID())
+		}
+		report.StoppedContainers = append(report.StoppedContainers, ctr.ID())
+	}
+
+	for _, ctr := range allCtrs {
+		rewritten, err := ctr.migrate(ctx, options.NewRuntime)
+		if rewritten {
+			report.RewrittenContainers = append(report.RewrittenContainers, ctr.ID())
 		}
-		data, err := ioutil.ReadFile(pausePidPath)
 		if err != nil {
-			if os.IsNotExist(err) {
-				return nil
-			}
-			return errors.Wrapf(err, "cannot read pause process pid file %s", pausePidPath)
+			logrus.Errorf("Error migrating container %s: %v", ctr.ID(), err)
+			report.FailedContainers[ctr.ID()] = err
 		}
-		pausePid, err := strconv.Atoi(string(data))
+	}
+
+	if options.RenumberLocks {
+		// Exclude any libpod instance being initialized while we work
+		aliveLock, err := storage.GetLockfile(filepath.Join(r.config.TmpDir, "alive.lck"))
 		if err != nil {
-			return errors.Wrapf(err, "cannot parse pause pid file %s", pausePidPath)
+			return report, errors.Wrapf(err, "error acquiring runtime init lock")
 		}
-		if err := os.Remove(pausePidPath); err != nil {
-			return errors.Wrapf(err, "cannot delete pause pid file %s", pausePidPath)
+		aliveLock.Lock()
+		report.Locks, err = r.renumberLocks(false)
+		aliveLock.Unlock()
+		if err != nil {
+			return report, err
 		}
-		if err := syscall.Kill(pausePid, syscall.SIGKILL); err != nil {
-			return err
+		if r.config.LockType == "" || r.config.LockType == "shm" {
+			report.Locks.NumLocks = r.config.NumLocks
 		}
 	}
-	return nil
-}
 
-func (r *Runtime) migrate(ctx context.Context) error {
-	runningContainers, err := r.GetRunningContainers()
-	if err != nil {
-		return err
+	if !options.KeepPauseProcess {
+		report.PauseProcessStopped, err = stopPauseProcess()
+		if err != nil {
+			return report, err
+		}
 	}
 
-	allCtrs, err := r.state.AllContainers()
-	if err != nil {
-		return err
+	if len(report.FailedContainers) > 0 {
+		return report, errors.Wrapf(define.ErrCtrExists, "error migrating %d containers", len(report.FailedContainers))
 	}
 
-	logrus.Infof("stopping all containers")
-	for _, ctr := range runningContainers {
-		fmt.Printf("stopped %s\n", ctr.ID())
-		if err := ctr.Stop(); err != nil {
-			return errors.Wrapf(err, "cannot stop container %s", ctr.ID())
+	return report, nil
+}
+
+// migrate rewrites the deprecated configuration of the container, and moves it
+// to the given OCI runtime if one is given. It returns whether the
+// configuration was rewritten.
+// The container must be stopped.
+func (c *Container) migrate(ctx context.Context, newRuntime string) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.syncContainer(); err != nil {
+		return false, err
+	}
+
+	rewritten := false
+	oldLocation := filepath.Join(c.state.RunDir, "conmon.pid")
+	if c.config.ConmonPidFile == oldLocation {
+		logrus.Infof("changing conmon PID file for %s", c.ID())
+		c.config.ConmonPidFile = filepath.Join(c.config.StaticDir, "conmon.pid")
+		if err := c.runtime.state.RewriteContainerConfig(c, c.config); err != nil {
+			return false, errors.Wrapf(err, "error rewriting config for container %s", c.ID())
 		}
+		rewritten = true
 	}
 
-	for _, ctr := range allCtrs {
-		oldLocation := filepath.Join(ctr.state.RunDir, "conmon.pid")
-		if ctr.config.ConmonPidFile == oldLocation {
-			logrus.Infof("changing conmon PID file for %s", ctr.ID())
-			ctr.config.ConmonPidFile = filepath.Join(ctr.config.StaticDir, "conmon.pid")
-			if err := r.state.RewriteContainerConfig(ctr, ctr.config); err != nil {
-				return errors.Wrapf(err, "error rewriting config for container %s", ctr.ID())
-			}
+	// Legacy containers use a literal path for their OCI runtime, which is
+	// rewritten to the name of the runtime.
+	if newRuntime == "" && strings.HasPrefix(c.config.OCIRuntime, "/") {
+		newRuntime = c.config.OCIRuntime
+	}
+	if newRuntime != "" {
+		oldRuntime := c.config.OCIRuntime
+		if err := c.migrateRuntime(ctx, newRuntime); err != nil {
+			return rewritten, errors.Wrapf(err, "error migrating container %s", c.ID())
+		}
+		if c.config.OCIRuntime != oldRuntime {
+			logrus.Infof("changing OCI runtime of %s from %s to %s", c.ID(), oldRuntime, c.config.OCIRuntime)
+			rewritten = true
 		}
 	}
 
-	return stopPauseProcess()
+	return rewritten, nil
 }
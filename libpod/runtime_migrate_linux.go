@@ -0,0 +1,44 @@
+// +build linux
+
+package libpod
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/containers/libpod/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// stopPauseProcess stops the pause process of rootless users, holding their
+// user namespace, and returns whether it was running
+func stopPauseProcess() (bool, error) {
+	if rootless.IsRootless() {
+		pausePidPath, err := util.GetRootlessPauseProcessPidPath()
+		if err != nil {
+			return false, errors.Wrapf(err, "could not get pause process pid file path")
+		}
+		data, err := ioutil.ReadFile(pausePidPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, errors.Wrapf(err, "cannot read pause process pid file %s", pausePidPath)
+		}
+		pausePid, err := strconv.Atoi(string(data))
+		if err != nil {
+			return false, errors.Wrapf(err, "cannot parse pause pid file %s", pausePidPath)
+		}
+		if err := os.Remove(pausePidPath); err != nil {
+			return false, errors.Wrapf(err, "cannot delete pause pid file %s", pausePidPath)
+		}
+		if err := syscall.Kill(pausePid, syscall.SIGKILL); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
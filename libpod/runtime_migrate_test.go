@@ -0,0 +1,182 @@
+package libpod
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerMigrate(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	runc := probedRuntime("runc", ociRuntimeFeatures{})
+	runtime := &Runtime{
+		config:            &RuntimeConfig{},
+		state:             state,
+		eventer:           events.NewNullEventer(),
+		defaultOCIRuntime: runc,
+		ociRuntimes:       map[string]OCIRuntime{"runc": runc},
+	}
+
+	ctr, err := getTestCtr1(manager)
+	require.NoError(t, err)
+	ctr.runtime = runtime
+	ctr.ociRuntime = runc
+	ctr.state.State = define.ContainerStateExited
+	ctr.config.OCIRuntime = "runc"
+	// Legacy containers kept their conmon PID file in the run directory
+	ctr.config.ConmonPidFile = filepath.Join(ctr.state.RunDir, "conmon.pid")
+	require.NoError(t, state.AddContainer(ctr))
+
+	rewritten, err := ctr.migrate(context.Background(), "")
+	require.NoError(t, err)
+	assert.True(t, rewritten)
+	assert.Equal(t, filepath.Join(ctr.config.StaticDir, "conmon.pid"), ctr.config.ConmonPidFile)
+
+	rewritten, err = ctr.migrate(context.Background(), "runc")
+	require.NoError(t, err)
+	assert.False(t, rewritten)
+
+	_, err = ctr.migrate(context.Background(), "kata")
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
+
+// namedOCIRuntime is an OCI runtime without a binary, recording the containers
+// deleted from it. Other operations are not supported.
+type namedOCIRuntime struct {
+	OCIRuntime
+	name    string
+	deleted []string
+}
+
+func (r *namedOCIRuntime) Name() string {
+	return r.name
+}
+
+func (r *namedOCIRuntime) Path() string {
+	return ""
+}
+
+func (r *namedOCIRuntime) UpdateContainerStatus(ctr *Container, useRuntime bool) error {
+	return nil
+}
+
+func (r *namedOCIRuntime) DeleteContainer(ctr *Container) error {
+	r.deleted = append(r.deleted, ctr.ID())
+	return nil
+}
+
+func TestMigrateContainerRuntime(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	runc := &namedOCIRuntime{name: "runc"}
+	crun := &namedOCIRuntime{name: "crun"}
+	runtime := &Runtime{
+		config:            &RuntimeConfig{TmpDir: path},
+		state:             state,
+		eventer:           events.NewNullEventer(),
+		defaultOCIRuntime: runc,
+		ociRuntimes:       map[string]OCIRuntime{"runc": runc, "crun": crun},
+		valid:             true,
+	}
+
+	ctrs := make(map[define.ContainerStatus]*Container)
+	for i, ctrState := range []define.ContainerStatus{define.ContainerStateStopped, define.ContainerStateRunning} {
+		ctr, err := getTestCtrN(fmt.Sprint(i+1), manager)
+		require.NoError(t, err)
+		ctr.runtime = runtime
+		ctr.ociRuntime = runc
+		ctr.config.OCIRuntime = "runc"
+		ctr.config.StaticDir = path
+		ctr.state.RunDir = path
+		ctr.state.State = ctrState
+		require.NoError(t, state.AddContainer(ctr))
+		ctrs[ctrState] = ctr
+	}
+
+	// Stopped containers are deleted from their runtime before moving
+	ctr := ctrs[define.ContainerStateStopped]
+	require.NoError(t, runtime.MigrateContainerRuntime(context.Background(), ctr, "crun"))
+	assert.Equal(t, []string{ctr.ID()}, runc.deleted)
+	assert.Equal(t, define.ContainerStateExited, ctr.state.State)
+	assert.Equal(t, "crun", ctr.config.OCIRuntime)
+	assert.Equal(t, crun, ctr.ociRuntime)
+	stateCtr, err := state.Container(ctr.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "crun", stateCtr.config.OCIRuntime)
+
+	err = runtime.MigrateContainerRuntime(context.Background(), ctrs[define.ContainerStateRunning], "crun")
+	assert.Equal(t, define.ErrCtrStateInvalid, errors.Cause(err))
+	err = runtime.MigrateContainerRuntime(context.Background(), ctr, "kata")
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+
+	// Containers whose runtime was removed from the configuration can be
+	// moved, though they cannot be deleted from it
+	ctr.ociRuntime = &missingOCIRuntime{name: "gone"}
+	ctr.config.OCIRuntime = "gone"
+	ctr.state.State = define.ContainerStateStopped
+	err = ctr.ociRuntime.StartContainer(ctr)
+	assert.Equal(t, define.ErrOCIRuntimeUnavailable, errors.Cause(err))
+	require.NoError(t, runtime.MigrateContainerRuntime(context.Background(), ctr, "runc"))
+	assert.Equal(t, define.ContainerStateExited, ctr.state.State)
+	assert.Equal(t, "runc", ctr.config.OCIRuntime)
+	assert.Equal(t, runc, ctr.ociRuntime)
+	assert.Len(t, runc.deleted, 1)
+}
+
+func TestMigrateContinuesAfterFailedContainer(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	runc := &namedOCIRuntime{name: "runc"}
+	runtime := &Runtime{
+		config:            &RuntimeConfig{TmpDir: path},
+		state:             state,
+		eventer:           events.NewNullEventer(),
+		defaultOCIRuntime: runc,
+		ociRuntimes:       map[string]OCIRuntime{"runc": runc},
+		valid:             true,
+	}
+
+	var ctrs []*Container
+	for i := 1; i <= 2; i++ {
+		ctr, err := getTestCtrN(fmt.Sprint(i), manager)
+		require.NoError(t, err)
+		ctr.runtime = runtime
+		ctr.ociRuntime = runc
+		ctr.config.OCIRuntime = "runc"
+		ctr.config.StaticDir = path
+		ctr.state.RunDir = path
+		ctr.state.State = define.ContainerStateExited
+		ctrs = append(ctrs, ctr)
+	}
+	// The first container uses a legacy runtime path matching no
+	// configured runtime, the second a legacy conmon PID file
+	ctrs[0].config.OCIRuntime = "/usr/local/bin/unknown"
+	ctrs[1].state.RunDir = filepath.Join(path, "run")
+	ctrs[1].config.ConmonPidFile = filepath.Join(path, "run", "conmon.pid")
+	for _, ctr := range ctrs {
+		require.NoError(t, state.AddContainer(ctr))
+	}
+
+	report, err := runtime.Migrate(context.Background(), MigrateOptions{KeepPauseProcess: true})
+	assert.Equal(t, define.ErrCtrExists, errors.Cause(err))
+	require.NotNil(t, report)
+	require.Len(t, report.FailedContainers, 1)
+	assert.Error(t, report.FailedContainers[ctrs[0].ID()])
+	assert.Equal(t, []string{ctrs[1].ID()}, report.RewrittenContainers)
+	assert.Equal(t, filepath.Join(path, "conmon.pid"), ctrs[1].config.ConmonPidFile)
+}
@@ -2,10 +2,6 @@
 
 package libpod
 
-import (
-	"context"
-)
-
-func (r *Runtime) migrate(ctx context.Context) error {
-	return nil
+func stopPauseProcess() (bool, error) {
+	return false, nil
 }
@@ -0,0 +1,274 @@
+package libpod
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Contains the public Runtime API for networks
+
+// A NetworkCreateOption is a functional option which alters the Network
+// created by NewNetwork
+type NetworkCreateOption func(*Network) error
+
+// NetworkFilter is a function to determine whether a network is included in
+// command output. Networks to be outputted are tested using the function. A
+// true return will include the network, a false return will exclude it.
+type NetworkFilter func(*Network) bool
+
+// NewNetwork adds a network to the state.
+// The CNI configuration for the network must already exist on disk at the
+// configured path.
+func (r *Runtime) NewNetwork(ctx context.Context, options ...NetworkCreateOption) (*Network, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	network, err := newNetwork(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating network")
+	}
+
+	for _, option := range options {
+		if err := option(network); err != nil {
+			return nil, errors.Wrapf(err, "error running network create option")
+		}
+	}
+
+	if network.config.Name == "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "networks must be given a name")
+	}
+	if network.config.ConfigPath == "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "network %s has no CNI configuration path", network.config.Name)
+	}
+	if _, err := os.Stat(network.config.ConfigPath); err != nil {
+		return nil, errors.Wrapf(err, "error accessing CNI configuration for network %s", network.config.Name)
+	}
+	network.config.CreatedTime = time.Now()
+
+	network.valid = true
+
+	if err := r.state.AddNetwork(network); err != nil {
+		return nil, errors.Wrapf(err, "error adding network to state")
+	}
+
+	logrus.Debugf("Added network %s to state", network.Name())
+	return network, nil
+}
+
+// RemoveNetwork removes a network from the state.
+// Networks that are in use by containers cannot be removed, unless force is
+// set, in which case the containers are disconnected from the network first.
+// Containers for which the network is the only one cannot be disconnected, and
+// prevent its removal. The network's CNI configuration is not removed; that is
+// left to the caller.
+func (r *Runtime) RemoveNetwork(ctx context.Context, n *Network, force bool) error {
+	if force && n.valid {
+		if err := r.disconnectNetworkContainers(n); err != nil {
+			return err
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return define.ErrRuntimeStopped
+	}
+
+	if !n.valid {
+		if ok, _ := r.state.HasNetwork(n.Name()); !ok {
+			// Network probably already removed
+			return nil
+		}
+	}
+
+	deps, err := r.state.NetworkInUse(n)
+	if err != nil {
+		return err
+	}
+	if len(deps) != 0 {
+		return errors.Wrapf(define.ErrNetworkBeingUsed, "network %s is being used by the following container(s): %s", n.Name(), strings.Join(deps, ", "))
+	}
+
+	// Set network as invalid so it can no longer be used
+	n.valid = false
+
+	if err := r.state.RemoveNetwork(n); err != nil {
+		return errors.Wrapf(err, "error removing network %s", n.Name())
+	}
+
+	logrus.Debugf("Removed network %s", n.Name())
+	return nil
+}
+
+// NetworkUsers returns the IDs of the containers configured to join the CNI
+// network with the given name. Unlike NetworkContainers, it also finds the
+// users of networks that are not tracked in the state, by going through the
+// configuration of all containers.
+func (r *Runtime) NetworkUsers(name string) ([]string, error) {
+	ctrs, err := r.GetContainers(func(c *Container) bool {
+		for _, netName := range c.config.Networks {
+			if netName == name {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(ctrs))
+	for _, ctr := range ctrs {
+		ids = append(ids, ctr.ID())
+	}
+	return ids, nil
+}
+
+// disconnectNetworkContainers disconnects all the containers using the given
+// network from it
+func (r *Runtime) disconnectNetworkContainers(n *Network) error {
+	deps, err := r.NetworkContainers(n)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range deps {
+		ctr, err := r.GetContainer(id)
+		if err != nil {
+			if errors.Cause(err) == define.ErrNoSuchCtr {
+				continue
+			}
+			return err
+		}
+		if err := ctr.NetworkDisconnect(n.Name()); err != nil {
+			return errors.Wrapf(err, "error disconnecting container %s from network %s", id, n.Name())
+		}
+	}
+	return nil
+}
+
+// PruneNetworks removes the networks not used by any container that match all
+// of the given filters, along with their CNI configuration. It returns the
+// names of the removed networks.
+// The default network is never pruned. A network that starts to be used while
+// it is pruned is kept, as the state checks that it is unused in the same
+// transaction that removes it.
+func (r *Runtime) PruneNetworks(ctx context.Context, filters ...NetworkFilter) ([]string, []error) {
+	var (
+		pruned      []string
+		pruneErrors []error
+	)
+	nets, err := r.Networks(filters...)
+	if err != nil {
+		pruneErrors = append(pruneErrors, err)
+		return nil, pruneErrors
+	}
+
+	defaultNetwork := r.defaultNetworkName()
+	for _, n := range nets {
+		if n.Name() == defaultNetwork {
+			continue
+		}
+		inUse, err := r.NetworkContainers(n)
+		if err != nil {
+			if errors.Cause(err) != define.ErrNetworkRemoved && errors.Cause(err) != define.ErrNoSuchNetwork {
+				pruneErrors = append(pruneErrors, err)
+			}
+			continue
+		}
+		if len(inUse) > 0 {
+			continue
+		}
+
+		if err := r.RemoveNetwork(ctx, n, false); err != nil {
+			if errors.Cause(err) != define.ErrNetworkBeingUsed && errors.Cause(err) != define.ErrNetworkRemoved && errors.Cause(err) != define.ErrNoSuchNetwork {
+				pruneErrors = append(pruneErrors, err)
+			}
+			continue
+		}
+		if err := os.Remove(n.ConfigPath()); err != nil && !os.IsNotExist(err) {
+			pruneErrors = append(pruneErrors, errors.Wrapf(err, "error removing CNI configuration of network %s", n.Name()))
+		}
+		pruned = append(pruned, n.Name())
+	}
+	return pruned, pruneErrors
+}
+
+// GetNetwork retrieves a network given its full name.
+func (r *Runtime) GetNetwork(name string) (*Network, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	return r.state.Network(name)
+}
+
+// HasNetwork checks to see if a network with the given name exists
+func (r *Runtime) HasNetwork(name string) (bool, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return false, define.ErrRuntimeStopped
+	}
+
+	return r.state.HasNetwork(name)
+}
+
+// Networks retrieves all networks
+// Filters can be provided which will determine which networks are included
+// in the output. Multiple filters are handled by ANDing their output, so only
+// networks matching all filters are returned
+func (r *Runtime) Networks(filters ...NetworkFilter) ([]*Network, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	nets, err := r.state.AllNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	netsFiltered := make([]*Network, 0, len(nets))
+	for _, net := range nets {
+		include := true
+		for _, filter := range filters {
+			include = include && filter(net)
+		}
+
+		if include {
+			netsFiltered = append(netsFiltered, net)
+		}
+	}
+
+	return netsFiltered, nil
+}
+
+// NetworkContainers returns the IDs of all containers using the given network
+func (r *Runtime) NetworkContainers(n *Network) ([]string, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	return r.state.NetworkInUse(n)
+}
@@ -0,0 +1,111 @@
+package libpod
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getNetworkTestRuntime returns a runtime holding the given networks, each
+// with a CNI configuration file, and a container for each given list of
+// networks
+func getNetworkTestRuntime(t *testing.T, networks []string, ctrNetworks ...[]string) (*Runtime, []*Container, string) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	runtime := &Runtime{
+		config:  &RuntimeConfig{CNIDefaultNetwork: "podman"},
+		state:   state,
+		eventer: events.NewNullEventer(),
+		valid:   true,
+	}
+
+	for _, name := range networks {
+		n, err := newNetwork(runtime)
+		require.NoError(t, err)
+		n.config.Name = name
+		n.config.ConfigPath = filepath.Join(path, name+".conflist")
+		n.config.Labels["name"] = name
+		n.valid = true
+		require.NoError(t, ioutil.WriteFile(n.config.ConfigPath, []byte("{}"), 0644))
+		require.NoError(t, state.AddNetwork(n))
+	}
+
+	var ctrs []*Container
+	for i, nets := range ctrNetworks {
+		ctr, err := getTestCtrN(fmt.Sprint(i+1), manager)
+		require.NoError(t, err)
+		ctr.runtime = runtime
+		ctr.config.CreateNetNS = true
+		ctr.config.Networks = nets
+		ctr.state.State = define.ContainerStateConfigured
+		require.NoError(t, state.AddContainer(ctr))
+		ctrs = append(ctrs, ctr)
+	}
+	return runtime, ctrs, path
+}
+
+func TestPruneNetworks(t *testing.T) {
+	runtime, _, path := getNetworkTestRuntime(t, []string{"podman", "used", "unused", "other"}, []string{"used"})
+	defer os.RemoveAll(path)
+
+	// Networks not matching the filters are kept
+	pruned, errs := runtime.PruneNetworks(context.Background(), func(n *Network) bool {
+		return n.Labels()["name"] != "other"
+	})
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"unused"}, pruned)
+	_, err := os.Stat(filepath.Join(path, "unused.conflist"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Networks in use and the default network are never pruned
+	pruned, errs = runtime.PruneNetworks(context.Background())
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"other"}, pruned)
+	nets, err := runtime.Networks()
+	require.NoError(t, err)
+	var names []string
+	for _, n := range nets {
+		names = append(names, n.Name())
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"podman", "used"}, names)
+}
+
+func TestRemoveNetworkInUse(t *testing.T) {
+	runtime, ctrs, path := getNetworkTestRuntime(t, []string{"first", "second"}, []string{"first", "second"}, []string{"second"})
+	defer os.RemoveAll(path)
+
+	first, err := runtime.GetNetwork("first")
+	require.NoError(t, err)
+	err = runtime.RemoveNetwork(context.Background(), first, false)
+	assert.Equal(t, define.ErrNetworkBeingUsed, errors.Cause(err))
+
+	// Forcing the removal disconnects the containers using the network
+	if os.Geteuid() != 0 {
+		t.Skip("Test not running as root")
+	}
+	skipIfJSONDeepCopyBroken(t)
+	require.NoError(t, runtime.RemoveNetwork(context.Background(), first, true))
+	assert.Equal(t, []string{"second"}, ctrs[0].config.Networks)
+	_, err = runtime.GetNetwork("first")
+	assert.Equal(t, define.ErrNoSuchNetwork, errors.Cause(err))
+
+	// Containers cannot be disconnected from their only network
+	second, err := runtime.GetNetwork("second")
+	require.NoError(t, err)
+	err = runtime.RemoveNetwork(context.Background(), second, true)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+	users, err := runtime.NetworkContainers(second)
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+}
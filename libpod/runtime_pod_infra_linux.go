@@ -70,6 +70,10 @@ func (r *Runtime) makeInfraContainer(ctx context.Context, p *Pod, imgName, imgID
 		}
 	}
 
+	if len(p.config.InfraContainer.InfraCommand) > 0 {
+		entryCmd = p.config.InfraContainer.InfraCommand
+	}
+
 	g.SetRootReadonly(true)
 	g.SetProcessArgs(entryCmd)
 
@@ -92,6 +96,12 @@ func (r *Runtime) makeInfraContainer(ctx context.Context, p *Pod, imgName, imgID
 	options = append(options, WithRootFSFromImage(imgID, imgName, false))
 	options = append(options, WithName(containerName))
 	options = append(options, withIsInfra())
+	if len(p.config.InfraContainer.ExitCommand) > 0 {
+		options = append(options, WithExitCommand(append([]string{}, p.config.InfraContainer.ExitCommand...)))
+	}
+	if p.config.InfraContainer.DNSCache {
+		options = append(options, withDNSCache())
+	}
 
 	// Since user namespace sharing is not implemented, we only need to check if it's rootless
 	networks := make([]string, 0)
@@ -112,7 +122,12 @@ func (r *Runtime) createInfraContainer(ctx context.Context, p *Pod) (*Container,
 		return nil, define.ErrRuntimeStopped
 	}
 
-	newImage, err := r.ImageRuntime().New(ctx, r.config.InfraImage, "", "", nil, nil, image.SigningOptions{}, nil, util.PullImageMissing)
+	infraImage := r.config.InfraImage
+	if p.config.InfraContainer.InfraImage != "" {
+		infraImage = p.config.InfraContainer.InfraImage
+	}
+
+	newImage, err := r.ImageRuntime().New(ctx, infraImage, "", "", nil, nil, image.SigningOptions{}, nil, util.PullImageMissing)
 	if err != nil {
 		return nil, err
 	}
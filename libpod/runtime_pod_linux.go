@@ -4,16 +4,11 @@ package libpod
 
 import (
 	"context"
-	"fmt"
-	"path"
-	"path/filepath"
-	"strings"
 
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/events"
 	"github.com/containers/libpod/pkg/cgroups"
 	"github.com/containers/libpod/pkg/rootless"
-	spec "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -56,12 +51,25 @@ func (r *Runtime) NewPod(ctx context.Context, options ...PodCreateOption) (_ *Po
 		pod.config.Hostname = pod.config.Name
 	}
 
+	if pod.config.DNSConfig != nil && !pod.config.InfraContainer.HasInfraContainer {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "a pod must have an infra container to set its DNS configuration")
+	}
+
+	if pod.config.InfraContainer.DNSCache {
+		if !pod.config.InfraContainer.HasInfraContainer {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "a pod must have an infra container to use a DNS cache")
+		}
+		if rootless.IsRootless() {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "a DNS cache is not supported for pods created by rootless users")
+		}
+	}
+
 	// Allocate a lock for the pod
 	lock, err := r.lockManager.AllocateLock()
 	if err != nil {
-		return nil, errors.Wrapf(err, "error allocating lock for new pod")
+		return nil, errors.Wrapf(r.lockAllocationError(err), "error allocating lock for new pod")
 	}
-	pod.lock = lock
+	pod.lock = podLocker(lock, pod.ID())
 	pod.config.LockID = pod.lock.ID()
 
 	defer func() {
@@ -75,46 +83,38 @@ func (r *Runtime) NewPod(ctx context.Context, options ...PodCreateOption) (_ *Po
 	pod.valid = true
 
 	// Check CGroup parent sanity, and set it if it was not set
-	switch r.config.CgroupManager {
-	case CgroupfsCgroupsManager:
-		if pod.config.CgroupParent == "" {
-			pod.config.CgroupParent = CgroupfsDefaultCgroupParent
-		} else if strings.HasSuffix(path.Base(pod.config.CgroupParent), ".slice") {
-			return nil, errors.Wrapf(define.ErrInvalidArg, "systemd slice received as cgroup parent when using cgroupfs")
-		}
-		// If we are set to use pod cgroups, set the cgroup parent that
-		// all containers in the pod will share
-		// No need to create it with cgroupfs - the first container to
-		// launch should do it for us
-		if pod.config.UsePodCgroup {
-			pod.state.CgroupPath = filepath.Join(pod.config.CgroupParent, pod.ID())
-		}
-	case SystemdCgroupsManager:
-		if pod.config.CgroupParent == "" {
-			if rootless.IsRootless() {
-				pod.config.CgroupParent = SystemdDefaultRootlessCgroupParent
-			} else {
-				pod.config.CgroupParent = SystemdDefaultCgroupParent
-			}
-		} else if len(pod.config.CgroupParent) < 6 || !strings.HasSuffix(path.Base(pod.config.CgroupParent), ".slice") {
-			return nil, errors.Wrapf(define.ErrInvalidArg, "did not receive systemd slice as cgroup parent when using systemd to manage cgroups")
-		}
-		// If we are set to use pod cgroups, set the cgroup parent that
-		// all containers in the pod will share
-		if pod.config.UsePodCgroup {
-			cgroupPath, err := systemdSliceFromPath(pod.config.CgroupParent, fmt.Sprintf("libpod_pod_%s", pod.ID()))
-			if err != nil {
-				return nil, errors.Wrapf(err, "unable to create pod cgroup for pod %s", pod.ID())
-			}
-			pod.state.CgroupPath = cgroupPath
+	if pod.config.CgroupParent == "" {
+		pod.config.CgroupParent = r.getCgroupManager().defaultParent()
+	} else if err := r.getCgroupManager().validateParent(pod.config.CgroupParent); err != nil {
+		return nil, err
+	}
+	// If we are set to use pod cgroups, set the cgroup parent that
+	// all containers in the pod will share
+	if pod.config.UsePodCgroup {
+		cgroupPath, err := r.getCgroupManager().createPodCgroup(pod)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to create pod cgroup for pod %s", pod.ID())
 		}
-	default:
-		return nil, errors.Wrapf(define.ErrInvalidArg, "unsupported CGroup manager: %s - cannot validate cgroup parent", r.config.CgroupManager)
+		pod.state.CgroupPath = cgroupPath
 	}
 
 	if pod.config.UsePodCgroup {
 		logrus.Debugf("Got pod cgroup as %s", pod.state.CgroupPath)
 	}
+	if pod.config.ResourceLimits != nil {
+		if !pod.config.UsePodCgroup {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "pod resource limits require the pod to have its own cgroup")
+		}
+		if rootless.IsRootless() {
+			cgroupv2, err := cgroups.IsCgroup2UnifiedMode()
+			if err != nil {
+				return nil, err
+			}
+			if !cgroupv2 {
+				return nil, errors.Wrapf(define.ErrInvalidArg, "pod resource limits are not supported for rootless users with cgroup v1")
+			}
+		}
+	}
 	if !pod.HasInfraContainer() && pod.SharesNamespaces() {
 		return nil, errors.Errorf("Pods must have an infra container to share namespaces")
 	}
@@ -122,6 +122,20 @@ func (r *Runtime) NewPod(ctx context.Context, options ...PodCreateOption) (_ *Po
 		logrus.Warnf("Pod has an infra container, but shares no namespaces")
 	}
 
+	// Create missing pod volumes now, so they outlive the containers
+	// they are mounted into
+	for _, vol := range pod.config.Volumes {
+		if _, err := r.state.Volume(vol.Name); err == nil {
+			continue
+		} else if errors.Cause(err) != define.ErrNoSuchVolume {
+			return nil, errors.Wrapf(err, "error retrieving volume %s of pod", vol.Name)
+		}
+		logrus.Debugf("Creating new volume %s for pod", vol.Name)
+		if _, err := r.newVolume(ctx, WithVolumeName(vol.Name)); err != nil {
+			return nil, errors.Wrapf(err, "error creating volume %s of pod", vol.Name)
+		}
+	}
+
 	if err := r.state.AddPod(pod); err != nil {
 		return nil, errors.Wrapf(err, "error adding pod to state")
 	}
@@ -133,6 +147,10 @@ func (r *Runtime) NewPod(ctx context.Context, options ...PodCreateOption) (_ *Po
 		}
 	}()
 
+	if err := pod.applyResourceLimits(); err != nil {
+		return nil, err
+	}
+
 	if pod.HasInfraContainer() {
 		ctr, err := r.createInfraContainer(ctx, pod)
 		if err != nil {
@@ -173,9 +191,10 @@ func (r *Runtime) removePod(ctx context.Context, p *Pod, removeCtrs, force bool)
 	// once.
 	// First loop also checks that we are ready to go ahead and remove.
 	for _, ctr := range ctrs {
-		ctrLock := ctr.lock
-		ctrLock.Lock()
-		defer ctrLock.Unlock()
+		if err := p.lockContainer(ctr); err != nil {
+			return err
+		}
+		defer ctr.lock.Unlock()
 
 		// If we're force-removing, no need to check status.
 		if force {
@@ -196,32 +215,12 @@ func (r *Runtime) removePod(ctx context.Context, p *Pod, removeCtrs, force bool)
 	var removalErr error
 
 	// We're going to be removing containers.
-	// If we are CGroupfs cgroup driver, to avoid races, we need to hit
-	// the pod and conmon CGroups with a PID limit to prevent them from
-	// spawning any further processes (particularly cleanup processes) which
-	// would prevent removing the CGroups.
-	if p.runtime.config.CgroupManager == CgroupfsCgroupsManager {
-		// Get the conmon CGroup
-		conmonCgroupPath := filepath.Join(p.state.CgroupPath, "conmon")
-		conmonCgroup, err := cgroups.Load(conmonCgroupPath)
-		if err != nil && err != cgroups.ErrCgroupDeleted && err != cgroups.ErrCgroupV1Rootless {
-			removalErr = errors.Wrapf(err, "error retrieving pod %s conmon cgroup %s", p.ID(), conmonCgroupPath)
-		}
-
-		// New resource limits
-		resLimits := new(spec.LinuxResources)
-		resLimits.Pids = new(spec.LinuxPids)
-		resLimits.Pids.Limit = 1 // Inhibit forks with very low pids limit
-
-		// Don't try if we failed to retrieve the cgroup
-		if err == nil {
-			if err := conmonCgroup.Update(resLimits); err != nil {
-				if removalErr == nil {
-					removalErr = errors.Wrapf(err, "error updating pod %s conmon group", p.ID())
-				} else {
-					logrus.Errorf("Error updating pod %s conmon cgroup %s: %v", p.ID(), conmonCgroupPath, err)
-				}
-			}
+	// To avoid races, the conmon processes of the pod must be kept from
+	// spawning any further processes (particularly cleanup processes)
+	// which would prevent removing the CGroups.
+	if p.state.CgroupPath != "" {
+		if err := p.runtime.getCgroupManager().limitPodConmon(p); err != nil {
+			removalErr = err
 		}
 	}
 
@@ -239,7 +238,8 @@ func (r *Runtime) removePod(ctx context.Context, p *Pod, removeCtrs, force bool)
 	}
 
 	// Remove all containers in the pod from the state.
-	if err := r.state.RemovePodContainers(p); err != nil {
+	// This is done in a single operation, rather than one per container.
+	if err := r.state.RemoveContainers(ctrs); err != nil {
 		// If this fails, there isn't much more we can do.
 		// The containers in the pod are unusable, but they still exist,
 		// so pod removal will fail.
@@ -250,63 +250,13 @@ func (r *Runtime) removePod(ctx context.Context, p *Pod, removeCtrs, force bool)
 	if p.state.CgroupPath != "" {
 		logrus.Debugf("Removing pod cgroup %s", p.state.CgroupPath)
 
-		switch p.runtime.config.CgroupManager {
-		case SystemdCgroupsManager:
-			if err := deleteSystemdCgroup(p.state.CgroupPath); err != nil {
-				if removalErr == nil {
-					removalErr = errors.Wrapf(err, "error removing pod %s cgroup", p.ID())
-				} else {
-					logrus.Errorf("Error deleting pod %s cgroup %s: %v", p.ID(), p.state.CgroupPath, err)
-				}
-			}
-		case CgroupfsCgroupsManager:
-			// Delete the cgroupfs cgroup
-			// Make sure the conmon cgroup is deleted first
-			// Since the pod is almost gone, don't bother failing
-			// hard - instead, just log errors.
-			conmonCgroupPath := filepath.Join(p.state.CgroupPath, "conmon")
-			conmonCgroup, err := cgroups.Load(conmonCgroupPath)
-			if err != nil && err != cgroups.ErrCgroupDeleted && err != cgroups.ErrCgroupV1Rootless {
-				if removalErr == nil {
-					removalErr = errors.Wrapf(err, "error retrieving pod %s conmon cgroup", p.ID())
-				} else {
-					logrus.Debugf("Error retrieving pod %s conmon cgroup %s: %v", p.ID(), conmonCgroupPath, err)
-				}
-			}
-			if err == nil {
-				if err := conmonCgroup.Delete(); err != nil {
-					if removalErr == nil {
-						removalErr = errors.Wrapf(err, "error removing pod %s conmon cgroup", p.ID())
-					} else {
-						logrus.Errorf("Error deleting pod %s conmon cgroup %s: %v", p.ID(), conmonCgroupPath, err)
-					}
-				}
-			}
-			cgroup, err := cgroups.Load(p.state.CgroupPath)
-			if err != nil && err != cgroups.ErrCgroupDeleted && err != cgroups.ErrCgroupV1Rootless {
-				if removalErr == nil {
-					removalErr = errors.Wrapf(err, "error retrieving pod %s cgroup", p.ID())
-				} else {
-					logrus.Errorf("Error retrieving pod %s cgroup %s: %v", p.ID(), p.state.CgroupPath, err)
-				}
-			}
-			if err == nil {
-				if err := cgroup.Delete(); err != nil {
-					if removalErr == nil {
-						removalErr = errors.Wrapf(err, "error removing pod %s cgroup", p.ID())
-					} else {
-						logrus.Errorf("Error deleting pod %s cgroup %s: %v", p.ID(), p.state.CgroupPath, err)
-					}
-				}
-			}
-		default:
-			// This should be caught much earlier, but let's still
-			// keep going so we make sure to evict the pod before
-			// ending up with an inconsistent state.
+		// Since the pod is almost gone, don't bother failing hard -
+		// instead, just log errors.
+		if err := p.runtime.getCgroupManager().removePodCgroup(p); err != nil {
 			if removalErr == nil {
-				removalErr = errors.Wrapf(define.ErrInternal, "unrecognized cgroup manager %s when removing pod %s cgroups", p.runtime.config.CgroupManager, p.ID())
+				removalErr = err
 			} else {
-				logrus.Errorf("Unknown cgroups manager %s specified - cannot remove pod %s cgroup", p.runtime.config.CgroupManager, p.ID())
+				logrus.Errorf("Error deleting pod %s cgroup %s: %v", p.ID(), p.state.CgroupPath, err)
 			}
 		}
 	}
@@ -0,0 +1,95 @@
+package libpod
+
+import (
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// StorageReadoption describes the result of re-adopting existing containers
+// after the storage graph driver was changed.
+type StorageReadoption struct {
+	// OldGraphDriver is the graph driver the database was created with.
+	OldGraphDriver string `json:"oldGraphDriver"`
+	// NewGraphDriver is the graph driver now in use.
+	NewGraphDriver string `json:"newGraphDriver"`
+	// Adopted contains the IDs of containers whose storage was found under
+	// the new graph driver.
+	Adopted []string `json:"adopted,omitempty"`
+	// Recreate contains the IDs of containers with no storage under the new
+	// graph driver. These must be removed and recreated.
+	Recreate []string `json:"recreate,omitempty"`
+}
+
+// StorageReadoption returns the result of the storage re-adoption performed
+// when the runtime was initialized. If no re-adoption was performed, nil is
+// returned.
+func (r *Runtime) StorageReadoption() *StorageReadoption {
+	return r.storageReadoption
+}
+
+// readoptStorage checks every container in the state for storage under the
+// current graph driver. If all containers are present, the new graph driver is
+// recorded in the database. Otherwise, an error listing the containers that
+// must be recreated is returned and the database is not altered.
+// Must be run during runtime initialization, while holding the alive lock.
+func (r *Runtime) readoptStorage(oldGraphDriver string) error {
+	result := &StorageReadoption{
+		OldGraphDriver: oldGraphDriver,
+		NewGraphDriver: r.config.StorageConfig.GraphDriverName,
+	}
+
+	// All namespaces must be checked, not just our own
+	if err := r.state.SetNamespace(""); err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.state.SetNamespace(r.config.Namespace); err != nil {
+			logrus.Errorf("Error resetting libpod namespace in state: %v", err)
+		}
+	}()
+
+	ctrs, err := r.state.AllContainers()
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving containers to re-adopt")
+	}
+
+	for _, ctr := range ctrs {
+		storageCtr, err := r.store.Container(ctr.ID())
+		if err != nil {
+			if errors.Cause(err) == storage.ErrContainerUnknown || errors.Cause(err) == storage.ErrNotAContainer {
+				result.Recreate = append(result.Recreate, ctr.ID())
+				continue
+			}
+			return errors.Wrapf(err, "error looking up storage for container %s", ctr.ID())
+		}
+
+		if _, err := r.store.Layer(storageCtr.LayerID); err != nil {
+			if errors.Cause(err) == storage.ErrLayerUnknown {
+				result.Recreate = append(result.Recreate, ctr.ID())
+				continue
+			}
+			return errors.Wrapf(err, "error looking up layer %s for container %s", storageCtr.LayerID, ctr.ID())
+		}
+
+		result.Adopted = append(result.Adopted, ctr.ID())
+	}
+
+	r.storageReadoption = result
+
+	if len(result.Recreate) > 0 {
+		return errors.Wrapf(define.ErrDBBadConfig, "storage graph driver changed from %q to %q but the following containers have no storage under the new driver and must be removed or recreated: %s",
+			result.OldGraphDriver, result.NewGraphDriver, strings.Join(result.Recreate, ", "))
+	}
+
+	if err := r.state.RewriteGraphDriver(result.NewGraphDriver); err != nil {
+		return errors.Wrapf(err, "error recording new graph driver %q in database", result.NewGraphDriver)
+	}
+
+	logrus.Infof("Re-adopted %d containers under storage graph driver %s", len(result.Adopted), result.NewGraphDriver)
+
+	return nil
+}
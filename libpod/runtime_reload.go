@@ -0,0 +1,232 @@
+package libpod
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containers/image/pkg/sysregistriesv2"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// reloadableConfigFields are the fields of the runtime configuration that
+// ReloadConfig updates. They are read each time they are used, so changing
+// them does not affect existing containers or other runtime state. Code
+// reading them must hold the runtime lock, or use GetConfig.
+var reloadableConfigFields = []string{
+	"ImageDefaultTransport",
+	"SignaturePolicyPath",
+	"ConmonEnvVars",
+	"MaxLogSize",
+	"DetachKeys",
+	"InfraImage",
+	"InfraCommand",
+	"EventsLogger",
+}
+
+// ReloadConfig re-reads the configuration file the runtime was created from,
+// and applies the settings that can safely change while the runtime is in use:
+// image transport and signature policy, conmon environment, log size, detach
+// keys, infra image and command, and the events backend. Registries
+// configuration is re-read on the next pull.
+// Settings overridden by runtime options when the runtime was created keep
+// their values. Other settings require a new runtime to change.
+// The names of the settings that changed are returned.
+func (r *Runtime) ReloadConfig() ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	sysregistriesv2.InvalidateCache()
+
+	if r.configPath == "" {
+		logrus.Debugf("Runtime was not created from a configuration file, only reloading registries")
+		return nil, nil
+	}
+
+	fileConfig, err := readReloadableConfig(r.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	oldEventsLogger := r.config.EventsLogger
+	changed := reloadConfigFields(r.config, r.fileConfig, fileConfig)
+	r.fileConfig = fileConfig
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	var eventerErr error
+	if r.config.EventsLogger != oldEventsLogger {
+		if err := r.reloadEventer(); err != nil {
+			// Keep the working backend rather than losing events
+			r.config.EventsLogger = oldEventsLogger
+			eventerErr = errors.Wrapf(err, "error switching events backend to %s", fileConfig.EventsLogger)
+		}
+	}
+	if r.imageRuntime != nil {
+		r.imageRuntime.ReloadConfig(r.config.SignaturePolicyPath, r.config.EventsLogger)
+	}
+	if eventerErr != nil {
+		return changed, eventerErr
+	}
+
+	logrus.Infof("Reloaded configuration file %s, changed: %s", r.configPath, strings.Join(changed, ", "))
+	r.newSystemEvent(events.Reload)
+
+	return changed, nil
+}
+
+// reloadEventer replaces the events backend of the runtime with one for the
+// configured events logger.
+func (r *Runtime) reloadEventer() error {
+	current, ok := r.eventer.(*reloadableEventer)
+	if !ok {
+		return errors.Wrapf(define.ErrInternal, "events backend %s cannot be replaced", r.eventer.String())
+	}
+	eventer, err := r.newEventer()
+	if err != nil {
+		return err
+	}
+	current.replace(eventer)
+	return nil
+}
+
+// reloadableEventer is the eventer of a runtime. It passes events to a
+// backend that ReloadConfig can replace while events are being written.
+type reloadableEventer struct {
+	lock    sync.RWMutex
+	eventer events.Eventer
+}
+
+// Write writes the event to the current backend
+func (e *reloadableEventer) Write(ee events.Event) error {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.eventer.Write(ee)
+}
+
+// Read reads events from the backend in use when it is called. Reading does
+// not hold up replacing the backend, since reads can follow events for as
+// long as the caller wants.
+func (e *reloadableEventer) Read(options events.ReadOptions) error {
+	e.lock.RLock()
+	eventer := e.eventer
+	e.lock.RUnlock()
+	return eventer.Read(options)
+}
+
+// String returns the type of the current backend
+func (e *reloadableEventer) String() string {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.eventer.String()
+}
+
+// Close closes the current backend
+func (e *reloadableEventer) Close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return closeEventer(e.eventer)
+}
+
+// replace switches to a new backend once the events being written to the
+// current one are done, and then closes the current one.
+func (e *reloadableEventer) replace(eventer events.Eventer) {
+	e.lock.Lock()
+	old := e.eventer
+	e.eventer = eventer
+	e.lock.Unlock()
+
+	if err := closeEventer(old); err != nil {
+		logrus.Errorf("Error closing events backend %s: %v", old.String(), err)
+	}
+}
+
+// closeEventer closes backends that need it, giving events still queued for
+// webhooks a chance to be posted
+func closeEventer(eventer events.Eventer) error {
+	if closer, ok := eventer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// readReloadableConfig reads the configuration file at the given path on top
+// of the default configuration, as the runtime does when it is created.
+func readReloadableConfig(configPath string) (*RuntimeConfig, error) {
+	defaultConfig, err := defaultRuntimeConfig()
+	if err != nil {
+		return nil, err
+	}
+	config := &defaultConfig
+	if rootless.IsRootless() && config.SignaturePolicyPath == "" {
+		policyPath, err := rootlessSignaturePolicyPath()
+		if err != nil {
+			return nil, err
+		}
+		config.SignaturePolicyPath = policyPath
+	}
+
+	contents, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading configuration file %s", configPath)
+	}
+	if _, err := toml.Decode(string(contents), config); err != nil {
+		return nil, errors.Wrapf(err, "error decoding configuration file %s", configPath)
+	}
+	return config, nil
+}
+
+// rootlessSignaturePolicyPath returns the signature policy in the home
+// directory of the user, or "" if they have none.
+func rootlessSignaturePolicyPath() (string, error) {
+	home, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+	policyPath := filepath.Join(home, ".config/containers/policy.json")
+	if _, err := os.Stat(policyPath); err != nil {
+		return "", nil
+	}
+	return policyPath, nil
+}
+
+// reloadConfigFields copies the reloadable fields that differ between the old
+// and new file configurations into the runtime configuration. Fields whose
+// runtime value no longer matches the old file configuration were overridden
+// and are left alone. The names of the fields that changed are returned.
+func reloadConfigFields(config, oldFileConfig, newFileConfig *RuntimeConfig) []string {
+	current := reflect.ValueOf(config).Elem()
+	oldFile := reflect.ValueOf(oldFileConfig).Elem()
+	newFile := reflect.ValueOf(newFileConfig).Elem()
+
+	var changed []string
+	for _, name := range reloadableConfigFields {
+		currentValue := current.FieldByName(name)
+		oldValue := oldFile.FieldByName(name)
+		newValue := newFile.FieldByName(name)
+		if !reflect.DeepEqual(currentValue.Interface(), oldValue.Interface()) {
+			logrus.Debugf("Not reloading %s, it was overridden when the runtime was created", name)
+			continue
+		}
+		if reflect.DeepEqual(currentValue.Interface(), newValue.Interface()) {
+			continue
+		}
+		currentValue.Set(newValue)
+		changed = append(changed, name)
+	}
+	return changed
+}
@@ -0,0 +1,65 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadConfigFieldsKeepsOverrides(t *testing.T) {
+	oldFile := &RuntimeConfig{
+		DetachKeys: "ctrl-p,ctrl-q",
+		InfraImage: "k8s.gcr.io/pause:3.1",
+		MaxLogSize: -1,
+		OCIRuntime: "runc",
+	}
+	newFile := &RuntimeConfig{
+		DetachKeys: "ctrl-a",
+		InfraImage: "k8s.gcr.io/pause:3.2",
+		MaxLogSize: 1024,
+		OCIRuntime: "crun",
+	}
+	// The infra image was overridden by a runtime option
+	config := &RuntimeConfig{
+		DetachKeys: "ctrl-p,ctrl-q",
+		InfraImage: "localhost/pause",
+		MaxLogSize: -1,
+		OCIRuntime: "runc",
+	}
+
+	changed := reloadConfigFields(config, oldFile, newFile)
+
+	assert.Equal(t, []string{"MaxLogSize", "DetachKeys"}, changed)
+	assert.Equal(t, "ctrl-a", config.DetachKeys)
+	assert.Equal(t, int64(1024), config.MaxLogSize)
+	assert.Equal(t, "localhost/pause", config.InfraImage)
+	// Not reloadable
+	assert.Equal(t, "runc", config.OCIRuntime)
+}
+
+// closingEventer is a recording eventer remembering whether it was closed
+type closingEventer struct {
+	recordingEventer
+	closed bool
+}
+
+func (e *closingEventer) Close() error {
+	e.closed = true
+	return nil
+}
+
+func TestReloadableEventerReplace(t *testing.T) {
+	old := &closingEventer{}
+	eventer := &reloadableEventer{eventer: old}
+	assert.NoError(t, eventer.Write(events.Event{Status: events.Create}))
+
+	replacement := &recordingEventer{}
+	eventer.replace(replacement)
+	assert.True(t, old.closed)
+
+	assert.NoError(t, eventer.Write(events.Event{Status: events.Remove}))
+	assert.Len(t, old.written, 1)
+	assert.Len(t, replacement.written, 1)
+	assert.Equal(t, events.Remove, replacement.written[0].Status)
+}
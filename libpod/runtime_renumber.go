@@ -1,77 +1,271 @@
 package libpod
 
 import (
+	"path/filepath"
+	"syscall"
+
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/containers/storage"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+// LockRenumberReport describes the lock numbers assigned to containers, pods,
+// and volumes by a lock renumber.
+type LockRenumberReport struct {
+	// DryRun indicates that no changes were made, and the lock numbers
+	// given are those that would have been assigned.
+	DryRun bool
+	// NumLocks is the number of locks in the lock pool after the
+	// renumber. It is 0 for lock types without a fixed number of locks.
+	NumLocks uint32
+	// Containers maps container IDs to their lock numbers.
+	Containers map[string]uint32
+	// Pods maps pod IDs to their lock numbers.
+	Pods map[string]uint32
+	// Volumes maps volume names to their lock numbers.
+	Volumes map[string]uint32
+}
+
+// RenumberLocks reassigns lock numbers for all containers, pods, and volumes
+// in the state. This is the equivalent of 'podman system renumber', performed
+// on a running runtime.
+// If numLocks is not 0, the lock pool is resized to hold that many locks before
+// renumbering, which can be used to grow the pool once all locks have been
+// allocated. Only SHM locks have a fixed number of locks; numLocks is ignored
+// for other lock types. A pool grown this way will continue to be used by later
+// libpod instances configured with fewer locks.
+// If dryRun is set, no changes are made, and the returned report gives the lock
+// numbers that would have been assigned.
+// WARNING: As with 'podman system renumber', no other libpod instances may be
+// running when locks are renumbered. Containers, pods, and volumes retrieved
+// from the runtime before renumbering must be retrieved again afterwards.
+func (r *Runtime) RenumberLocks(numLocks uint32, dryRun bool) (*LockRenumberReport, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	fixedPool := r.config.LockType == "" || r.config.LockType == "shm"
+	if !fixedPool {
+		numLocks = 0
+	}
+	newNumLocks := r.config.NumLocks
+	if numLocks != 0 {
+		newNumLocks = numLocks
+	}
+
+	if fixedPool {
+		required, err := r.numLocksRequired()
+		if err != nil {
+			return nil, err
+		}
+		if required > newNumLocks {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "%d locks are required, but the lock pool would only hold %d", required, newNumLocks)
+		}
+	}
+
+	if dryRun {
+		report, err := r.renumberLocks(true)
+		if err != nil {
+			return nil, err
+		}
+		if fixedPool {
+			report.NumLocks = newNumLocks
+		}
+		return report, nil
+	}
+
+	// Exclude any libpod instance being initialized while we work
+	aliveLock, err := storage.GetLockfile(filepath.Join(r.config.TmpDir, "alive.lck"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error acquiring runtime init lock")
+	}
+	aliveLock.Lock()
+	defer aliveLock.Unlock()
+
+	if numLocks != 0 && numLocks != r.config.NumLocks {
+		// All locks are reassigned below, so none need to fit in the
+		// resized pool
+		if err := r.lockManager.FreeAllLocks(); err != nil {
+			return nil, err
+		}
+		if err := r.resizeSHMLockPool(shmLockPath(), numLocks); err != nil {
+			return nil, err
+		}
+	}
+
+	report, err := r.renumberLocks(false)
+	if err != nil {
+		return nil, err
+	}
+	if fixedPool {
+		report.NumLocks = r.config.NumLocks
+	}
+	return report, nil
+}
+
+// numLocksRequired returns the number of locks required by all containers,
+// pods, and volumes in the state.
+func (r *Runtime) numLocksRequired() (uint32, error) {
+	ctrs, err := r.state.AllContainers()
+	if err != nil {
+		return 0, err
+	}
+	pods, err := r.state.AllPods()
+	if err != nil {
+		return 0, err
+	}
+	vols, err := r.state.AllVolumes()
+	if err != nil {
+		return 0, err
+	}
+	return uint32(len(ctrs) + len(pods) + len(vols)), nil
+}
+
+// resizeSHMLockPool resizes the SHM lock pool, at the given path, to hold the
+// given number of locks. Allocated locks remain allocated, and must fit in the
+// new pool. The lock manager of the runtime is kept, so its locks remain
+// instrumented.
+func (r *Runtime) resizeSHMLockPool(path string, numLocks uint32) error {
+	m := r.lockManager
+	if instrumented, ok := m.(instrumentedLockManager); ok {
+		m = instrumented.Manager
+	}
+	manager, ok := m.(*lock.SHMLockManager)
+	if !ok {
+		return errors.Wrapf(define.ErrInvalidArg, "only SHM lock pools can be resized")
+	}
+
+	logrus.Debugf("Resizing SHM lock pool from %d to %d locks", r.config.NumLocks, numLocks)
+
+	if err := manager.Resize(path, numLocks); err != nil {
+		return errors.Wrapf(err, "error resizing SHM lock pool to %d locks", numLocks)
+	}
+	r.config.NumLocks = numLocks
+
+	return nil
+}
+
+// lockAllocationError adds a hint on how to recover to errors caused by the
+// lock pool being exhausted.
+func (r *Runtime) lockAllocationError(err error) error {
+	if r.config.LockType != "file" && errors.Cause(err) == syscall.ENOSPC {
+		return errors.Wrapf(define.ErrLockPoolExhausted, "all %d locks are allocated - increase num_locks in libpod.conf and run 'podman system renumber'", r.config.NumLocks)
+	}
+	return err
+}
+
 // renumberLocks reassigns lock numbers for all containers and pods in the
 // state.
+// If dryRun is set, no changes are made, and the returned report gives the
+// lock numbers that would have been assigned. Locks are allocated in order
+// from an empty pool, so these match the numbers a real renumber assigns.
 // TODO: It would be desirable to make it impossible to call this until all
 // other libpod sessions are dead.
 // Possibly use a read-write file lock, with all non-renumber podmans owning the
 // lock as read, renumber attempting to take a write lock?
 // The alternative is some sort of session tracking, and I don't know how
 // reliable that can be.
-func (r *Runtime) renumberLocks() error {
+func (r *Runtime) renumberLocks(dryRun bool) (*LockRenumberReport, error) {
+	report := &LockRenumberReport{
+		DryRun:     dryRun,
+		Containers: make(map[string]uint32),
+		Pods:       make(map[string]uint32),
+		Volumes:    make(map[string]uint32),
+	}
+
+	var nextLock uint32
+	allocateLock := func() (uint32, error) {
+		if dryRun {
+			nextLock++
+			return nextLock - 1, nil
+		}
+		lock, err := r.lockManager.AllocateLock()
+		if err != nil {
+			return 0, r.lockAllocationError(err)
+		}
+		return lock.ID(), nil
+	}
+
 	// Start off by deallocating all locks
-	if err := r.lockManager.FreeAllLocks(); err != nil {
-		return err
+	if !dryRun {
+		if err := r.lockManager.FreeAllLocks(); err != nil {
+			return nil, err
+		}
 	}
 
 	allCtrs, err := r.state.AllContainers()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, ctr := range allCtrs {
-		lock, err := r.lockManager.AllocateLock()
+		lockID, err := allocateLock()
 		if err != nil {
-			return errors.Wrapf(err, "error allocating lock for container %s", ctr.ID())
+			return nil, errors.Wrapf(err, "error allocating lock for container %s", ctr.ID())
+		}
+		report.Containers[ctr.ID()] = lockID
+		if dryRun {
+			continue
 		}
 
-		ctr.config.LockID = lock.ID()
+		ctr.config.LockID = lockID
 
 		// Write the new lock ID
 		if err := r.state.RewriteContainerConfig(ctr, ctr.config); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	allPods, err := r.state.AllPods()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, pod := range allPods {
-		lock, err := r.lockManager.AllocateLock()
+		lockID, err := allocateLock()
 		if err != nil {
-			return errors.Wrapf(err, "error allocating lock for pod %s", pod.ID())
+			return nil, errors.Wrapf(err, "error allocating lock for pod %s", pod.ID())
+		}
+		report.Pods[pod.ID()] = lockID
+		if dryRun {
+			continue
 		}
 
-		pod.config.LockID = lock.ID()
+		pod.config.LockID = lockID
 
 		// Write the new lock ID
 		if err := r.state.RewritePodConfig(pod, pod.config); err != nil {
-			return err
+			return nil, err
 		}
 	}
 	allVols, err := r.state.AllVolumes()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, vol := range allVols {
-		lock, err := r.lockManager.AllocateLock()
+		lockID, err := allocateLock()
 		if err != nil {
-			return errors.Wrapf(err, "error allocating lock for volume %s", vol.Name())
+			return nil, errors.Wrapf(err, "error allocating lock for volume %s", vol.Name())
+		}
+		report.Volumes[vol.Name()] = lockID
+		if dryRun {
+			continue
 		}
 
-		vol.config.LockID = lock.ID()
+		vol.config.LockID = lockID
 
 		// Write the new lock ID
 		if err := r.state.RewriteVolumeConfig(vol, vol.config); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	r.newSystemEvent(events.Renumber)
+	if !dryRun {
+		r.newSystemEvent(events.Renumber)
+	}
 
-	return nil
+	return report, nil
 }
@@ -2,6 +2,8 @@ package libpod
 
 import (
 	"context"
+	goruntime "runtime"
+	"sync"
 
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/events"
@@ -148,27 +150,110 @@ func (r *Runtime) GetAllVolumes() ([]*Volume, error) {
 	return r.state.AllVolumes()
 }
 
-// PruneVolumes removes unused volumes from the system
-func (r *Runtime) PruneVolumes(ctx context.Context) ([]string, []error) {
+// PruneVolumes removes the volumes not used by any container that match all
+// of the given filters. It returns the names of the removed volumes and the
+// disk space reclaimed by removing them.
+// Volumes in use are skipped. A volume that starts to be used while it is
+// pruned is kept, as the state checks that it is unused in the same
+// transaction that removes it.
+func (r *Runtime) PruneVolumes(ctx context.Context, filters ...VolumeFilter) ([]string, uint64, []error) {
 	var (
 		prunedIDs   []string
+		reclaimed   uint64
 		pruneErrors []error
 	)
-	vols, err := r.GetAllVolumes()
+	vols, err := r.Volumes(filters...)
 	if err != nil {
 		pruneErrors = append(pruneErrors, err)
-		return nil, pruneErrors
+		return nil, 0, pruneErrors
 	}
 
 	for _, vol := range vols {
+		inUse, err := r.volumeInUse(vol)
+		if err != nil {
+			if errors.Cause(err) != define.ErrVolumeRemoved && errors.Cause(err) != define.ErrNoSuchVolume {
+				pruneErrors = append(pruneErrors, err)
+			}
+			continue
+		}
+		if len(inUse) > 0 {
+			continue
+		}
+
+		var size uint64
+		usage, err := vol.DiskUsage()
+		if err != nil {
+			logrus.Debugf("Unable to compute disk usage of volume %s: %v", vol.Name(), err)
+		} else {
+			size = usage.Used
+		}
+
 		if err := r.RemoveVolume(ctx, vol, false); err != nil {
-			if errors.Cause(err) != define.ErrVolumeBeingUsed && errors.Cause(err) != define.ErrVolumeRemoved {
+			if errors.Cause(err) != define.ErrVolumeBeingUsed && errors.Cause(err) != define.ErrVolumeRemoved && errors.Cause(err) != define.ErrNoSuchVolume {
 				pruneErrors = append(pruneErrors, err)
 			}
 			continue
 		}
 		vol.newVolumeEvent(events.Prune)
 		prunedIDs = append(prunedIDs, vol.Name())
+		reclaimed += size
+	}
+	return prunedIDs, reclaimed, pruneErrors
+}
+
+// VolumeDiskUsage returns the disk space used by the given volumes, or by all
+// volumes if none are given, in the same order. The contents of several volumes
+// are walked at once, and usage computed recently is reused, as described in
+// Volume.DiskUsage. Volumes removed meanwhile are left out.
+func (r *Runtime) VolumeDiskUsage(volumes ...*Volume) ([]*VolumeDiskUsage, error) {
+	if len(volumes) == 0 {
+		var err error
+		volumes, err = r.GetAllVolumes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	usages := make([]*VolumeDiskUsage, len(volumes))
+	errs := make([]error, len(volumes))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < goruntime.NumCPU() && i < len(volumes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				usages[index], errs[index] = volumes[index].DiskUsage()
+			}
+		}()
+	}
+	for index := range volumes {
+		work <- index
 	}
-	return prunedIDs, pruneErrors
+	close(work)
+	wg.Wait()
+
+	result := make([]*VolumeDiskUsage, 0, len(volumes))
+	for index, err := range errs {
+		if err != nil {
+			if errors.Cause(err) == define.ErrVolumeRemoved || errors.Cause(err) == define.ErrNoSuchVolume {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, usages[index])
+	}
+	return result, nil
+}
+
+// volumeInUse returns the IDs of the containers using the volume.
+func (r *Runtime) volumeInUse(vol *Volume) ([]string, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	return r.state.VolumeInUse(vol)
 }
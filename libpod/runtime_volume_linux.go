@@ -48,29 +48,55 @@ func (r *Runtime) newVolume(ctx context.Context, options ...VolumeCreateOption)
 	}
 	volume.config.CreatedTime = time.Now()
 
-	// Create the mountpoint of this volume
-	volPathRoot := filepath.Join(r.config.VolumePath, volume.config.Name)
-	if err := os.MkdirAll(volPathRoot, 0700); err != nil {
-		return nil, errors.Wrapf(err, "error creating volume directory %q", volPathRoot)
-	}
-	if err := os.Chown(volPathRoot, volume.config.UID, volume.config.GID); err != nil {
-		return nil, errors.Wrapf(err, "error chowning volume directory %q to %d:%d", volPathRoot, volume.config.UID, volume.config.GID)
-	}
-	fullVolPath := filepath.Join(volPathRoot, "_data")
-	if err := os.Mkdir(fullVolPath, 0755); err != nil {
-		return nil, errors.Wrapf(err, "error creating volume directory %q", fullVolPath)
-	}
-	if err := os.Chown(fullVolPath, volume.config.UID, volume.config.GID); err != nil {
-		return nil, errors.Wrapf(err, "error chowning volume directory %q to %d:%d", fullVolPath, volume.config.UID, volume.config.GID)
-	}
-	if err := LabelVolumePath(fullVolPath, true); err != nil {
-		return nil, err
+	if volume.UsesVolumeDriver() {
+		// The plugin provides the volume's storage, and mounts it
+		// when it is used
+		volPlugin, err := r.getVolumePlugin(volume.config.Driver)
+		if err != nil {
+			return nil, err
+		}
+		if err := volPlugin.CreateVolume(volume.config.Name, volume.config.Options); err != nil {
+			return nil, errors.Wrapf(err, "error creating volume %s", volume.config.Name)
+		}
+		defer func() {
+			if Err != nil {
+				if err := volPlugin.RemoveVolume(volume.config.Name); err != nil {
+					logrus.Errorf("Error removing volume %s from plugin %s after failed creation: %v", volume.config.Name, volPlugin.Name, err)
+				}
+			}
+		}()
+		volume.refreshDriverStatus(volPlugin)
+	} else {
+		size, err := parseVolumeSize(volume.config.Options)
+		if err != nil {
+			return nil, err
+		}
+		volume.config.Size = size
+		if err := volume.parseVolumeMount(); err != nil {
+			return nil, err
+		}
+
+		if err := volume.parseVolumeOwnership(); err != nil {
+			return nil, err
+		}
+
+		// Create the mountpoint of this volume
+		volPathRoot := filepath.Join(r.config.VolumePath, volume.config.Name)
+		defer func() {
+			if Err != nil {
+				if err := os.RemoveAll(volPathRoot); err != nil {
+					logrus.Errorf("Error removing volume directory %q after failed creation: %v", volPathRoot, err)
+				}
+			}
+		}()
+		if err := volume.createDirectories(); err != nil {
+			return nil, err
+		}
 	}
-	volume.config.MountPoint = fullVolPath
 
 	lock, err := r.lockManager.AllocateLock()
 	if err != nil {
-		return nil, errors.Wrapf(err, "error allocating lock for new volume")
+		return nil, errors.Wrapf(r.lockAllocationError(err), "error allocating lock for new volume")
 	}
 	volume.lock = lock
 	volume.config.LockID = volume.lock.ID()
@@ -84,6 +110,9 @@ func (r *Runtime) newVolume(ctx context.Context, options ...VolumeCreateOption)
 	}()
 
 	volume.valid = true
+	// The volume is empty, so the contents of the image of the first
+	// container it is mounted into are copied into it
+	volume.state.NeedsCopyUp = true
 
 	// Add the volume to state
 	if err := r.state.AddVolume(volume); err != nil {
@@ -137,14 +166,16 @@ func (r *Runtime) removeVolume(ctx context.Context, v *Volume, force bool) error
 		}
 	}
 
-	// Set volume as invalid so it can no longer be used
-	v.valid = false
-
-	// Remove the volume from the state
+	// Remove the volume from the state. The state checks again that no
+	// container uses the volume when removing it, in case one started to
+	// since the check above.
 	if err := r.state.RemoveVolume(v); err != nil {
 		return errors.Wrapf(err, "error removing volume %s", v.Name())
 	}
 
+	// Set volume as invalid so it can no longer be used
+	v.valid = false
+
 	var removalErr error
 
 	// Free the volume's lock
@@ -0,0 +1,104 @@
+package libpod
+
+import (
+	"context"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/schedule"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// RunScheduler starts containers according to their start schedules until the
+// context is cancelled.
+// Containers are checked every interval. A container that is due is started
+// unless it is already running or paused, and its next run is computed from
+// the current time. Runs that were missed while the scheduler was not running
+// are therefore made up once, not once per missed run. The next and last runs
+// are saved in the container's state, so they survive restarts of the
+// scheduler and of the host.
+func (r *Runtime) RunScheduler(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "scheduler interval must be greater than 0")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := r.startScheduledContainers(ctx, time.Now()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// startScheduledContainers starts all containers that are due at the given
+// time.
+func (r *Runtime) startScheduledContainers(ctx context.Context, now time.Time) error {
+	ctrs, err := r.GetContainers(func(c *Container) bool {
+		return c.StartSchedule() != ""
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ctr := range ctrs {
+		due, err := ctr.advanceSchedule(now)
+		if err != nil {
+			logrus.Errorf("Error updating start schedule of container %s: %v", ctr.ID(), err)
+			continue
+		}
+		if !due {
+			continue
+		}
+
+		logrus.Debugf("Starting scheduled container %s", ctr.ID())
+		if err := ctr.Start(ctx, ctr.PodID() != ""); err != nil {
+			logrus.Errorf("Error starting scheduled container %s: %v", ctr.ID(), err)
+		}
+	}
+	return nil
+}
+
+// advanceSchedule checks whether the container's start schedule is due at the
+// given time. If it is, the next run is computed and saved, and true is
+// returned if the container needs to be started.
+func (c *Container) advanceSchedule(now time.Time) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.syncContainer(); err != nil {
+		return false, err
+	}
+
+	nextRun := c.state.ScheduleNextRun
+	if nextRun.IsZero() || now.Before(nextRun) {
+		return false, nil
+	}
+
+	startSchedule, err := schedule.Parse(c.config.StartSchedule)
+	if err != nil {
+		return false, err
+	}
+	c.state.ScheduleNextRun = startSchedule.Next(now)
+
+	start := true
+	switch c.state.State {
+	case define.ContainerStateRunning, define.ContainerStatePaused:
+		logrus.Infof("Scheduled container %s is already %s, not starting it", c.ID(), c.state.State.String())
+		start = false
+	default:
+		c.state.ScheduleLastRun = now
+	}
+
+	if err := c.save(); err != nil {
+		return false, err
+	}
+	return start, nil
+}
@@ -0,0 +1,58 @@
+package libpod
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// notifySocketEnv returns the environment passing the notify socket of the
+// systemd unit running podman to the OCI runtime, which passes it on to the
+// container. Empty unless the container notifies the unit itself.
+func (c *Container) notifySocketEnv() []string {
+	if c.SdNotifyMode() != define.SdNotifyModeContainer {
+		return nil
+	}
+	if notify, ok := os.LookupEnv("NOTIFY_SOCKET"); ok {
+		return []string{fmt.Sprintf("NOTIFY_SOCKET=%s", notify)}
+	}
+	return nil
+}
+
+// notifyReady notifies the systemd unit running podman that the container is
+// ready, with conmon as the main process of the unit, if the container is not
+// to notify the unit itself
+func (c *Container) notifyReady() {
+	if c.SdNotifyMode() != define.SdNotifyModeConmon {
+		return
+	}
+	message := "READY=1"
+	if c.state.ConmonPID > 0 {
+		message = fmt.Sprintf("MAINPID=%d\n%s", c.state.ConmonPID, message)
+	}
+	if err := sdNotify(message); err != nil {
+		logrus.Errorf("Error notifying systemd that container %s is ready: %v", c.ID(), err)
+	}
+}
+
+// sdNotify sends the message to the notify socket of the systemd unit running
+// podman. Nothing is sent if podman is not run by a unit expecting it.
+func sdNotify(message string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return errors.Wrapf(err, "error connecting to notify socket %s", socket)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(message)); err != nil {
+		return errors.Wrapf(err, "error writing to notify socket %s", socket)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package libpod
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyReady(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sdnotify")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	notify, ok := os.LookupEnv("NOTIFY_SOCKET")
+	defer func() {
+		if ok {
+			os.Setenv("NOTIFY_SOCKET", notify)
+		} else {
+			os.Unsetenv("NOTIFY_SOCKET")
+		}
+	}()
+	os.Setenv("NOTIFY_SOCKET", path)
+
+	c := Container{
+		config: &ContainerConfig{},
+		state:  &ContainerState{ConmonPID: 1234},
+	}
+	assert.Equal(t, define.SdNotifyModeContainer, c.SdNotifyMode())
+	assert.Equal(t, []string{"NOTIFY_SOCKET=" + path}, c.notifySocketEnv())
+
+	c.config.SdNotifyMode = define.SdNotifyModeIgnore
+	assert.Empty(t, c.notifySocketEnv())
+
+	c.config.SdNotifyMode = define.SdNotifyModeConmon
+	assert.Empty(t, c.notifySocketEnv())
+	c.notifyReady()
+	b := make([]byte, 64)
+	n, err := conn.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, "MAINPID=1234\nREADY=1", string(b[:n]))
+}
@@ -1,5 +1,12 @@
 package libpod
 
+import (
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/pkg/idtools"
+)
+
 // DBConfig is a set of Libpod runtime configuration settings that are saved
 // in a State when it is first created, and can subsequently be retrieved.
 type DBConfig struct {
@@ -11,6 +18,13 @@ type DBConfig struct {
 	VolumePath  string
 }
 
+// recordedExitCode is the exit code of a container as recorded in a State,
+// along with the time it was recorded
+type recordedExitCode struct {
+	ExitCode int32     `json:"exitCode"`
+	Recorded time.Time `json:"recorded"`
+}
+
 // State is a storage backend for libpod's current state.
 // A State is only initialized once per instance of libpod.
 // As such, initialization methods for State implementations may safely assume
@@ -48,6 +62,15 @@ type State interface {
 	// the program.
 	ValidateDBConfig(runtime *Runtime) error
 
+	// RewriteGraphDriver replaces the storage graph driver recorded in the
+	// database when it was created.
+	// This must only be used during runtime initialization while holding
+	// the alive lock, once all containers in the state have been verified
+	// to have storage under the new graph driver.
+	// This is not implemented by the in-memory state, which does not
+	// record the graph driver.
+	RewriteGraphDriver(driver string) error
+
 	// SetNamespace() sets the namespace for the store, and will determine
 	// what containers are retrieved with container and pod retrieval calls.
 	// A namespace of "", the empty string, acts as no namespace, and
@@ -79,6 +102,17 @@ type State interface {
 	// Containers that are part of pods must use RemoveContainerFromPod.
 	// The container must be part of the set namespace.
 	RemoveContainer(ctr *Container) error
+	// RemoveContainers removes a number of containers from the state in a
+	// single operation.
+	// Unlike RemoveContainer, containers that are part of pods may be
+	// given, and will also be removed from their pods.
+	// Dependencies between the given containers are ignored, but the
+	// removal will fail, and no containers will be removed, if any
+	// container not given depends on one of them.
+	// Validity of the given containers is not checked, so containers
+	// already marked invalid by the caller may be removed.
+	// All containers must be part of the set namespace.
+	RemoveContainers(ctrs []*Container) error
 	// UpdateContainer updates a container's state from the backing store.
 	// The container must be part of the set namespace.
 	UpdateContainer(ctr *Container) error
@@ -93,10 +127,75 @@ type State interface {
 	// A container cannot be removed if other containers depend on it.
 	// The container being checked must be part of the set namespace.
 	ContainerInUse(ctr *Container) ([]string, error)
+	// ContainerDependencyConditions returns the conditions that the
+	// containers depending on a given container wait for it to meet
+	// before they start, keyed by the IDs of the depending containers.
+	// It holds the same containers as ContainerInUse.
+	// The container being checked must be part of the set namespace.
+	ContainerDependencyConditions(ctr *Container) (map[string]define.DependencyCondition, error)
 	// Retrieves all containers presently in state.
 	// If a namespace is set, only containers within the namespace will be
 	// returned.
 	AllContainers() ([]*Container, error)
+	// AllContainersSummaries returns a summary of every container in the
+	// state.
+	// Summaries are retrieved without locking or fully retrieving the
+	// containers, and reflect each container's state as of the last time
+	// it was saved.
+	// If a namespace is set, only summaries of containers within the
+	// namespace will be returned.
+	AllContainersSummaries() ([]*ContainerSummary, error)
+	// AddPendingRemoval queues the given container to be removed once it
+	// is no longer running. The queue is persistent, so the container is
+	// eventually removed even if the process meant to remove it dies.
+	// Entries are dropped when their containers are removed.
+	// The container must be part of the set namespace.
+	AddPendingRemoval(ctr *Container) error
+	// PendingRemovals returns the IDs of the containers queued for removal,
+	// mapped to the time each was queued.
+	// If a namespace is set, only containers within the namespace will be
+	// returned.
+	PendingRemovals() (map[string]time.Time, error)
+	// AddExecSession registers an exec session of the given container, so
+	// that processes other than the one that started it can find it by
+	// ID. Registrations are dropped when their containers are removed.
+	// The container must be part of the set namespace.
+	AddExecSession(ctr *Container, session *ExecSession) error
+	// ExecSessionContainer returns the ID of the container the exec
+	// session with the given ID runs in.
+	// If a namespace is set, only sessions of containers within the
+	// namespace will be found.
+	ExecSessionContainer(id string) (string, error)
+	// RemoveExecSession removes the registration of the exec session with
+	// the given ID.
+	RemoveExecSession(id string) error
+	// AllExecSessions returns the IDs of all registered exec sessions,
+	// mapped to the IDs of the containers they run in.
+	// If a namespace is set, only sessions of containers within the
+	// namespace will be returned.
+	AllExecSessions() (map[string]string, error)
+	// AddContainerExitCode records the exit code of the container with the
+	// given ID, replacing any previously recorded one.
+	// Exit codes are kept independently of containers, so they can still
+	// be retrieved after their containers are removed. They are not
+	// subject to the set namespace.
+	AddContainerExitCode(id string, exitCode int32) error
+	// GetContainerExitCode returns the last exit code recorded for the
+	// container with the given ID.
+	GetContainerExitCode(id string) (int32, error)
+	// PruneContainerExitCodes removes the exit codes recorded before the
+	// given time.
+	PruneContainerExitCodes(before time.Time) error
+	// AllocateUserNSRange allocates a range of size UIDs and GIDs from the
+	// given pools to the container with the given ID, overlapping none of
+	// the ranges allocated to other containers. The container need not be
+	// in the state yet, so IDs can be allocated before it is created.
+	// Ranges are not subject to the set namespace.
+	AllocateUserNSRange(ctrID string, size int, uidPool, gidPool []idtools.IDMap) (*AutoUserNSRange, error)
+	// ReleaseUserNSRange releases the range allocated to the container with
+	// the given ID, if any, so its IDs can be allocated to other
+	// containers.
+	ReleaseUserNSRange(ctrID string) error
 
 	// PLEASE READ FULL DESCRIPTION BEFORE USING.
 	// Rewrite a container's configuration.
@@ -116,6 +215,12 @@ type State interface {
 	// know what you're doing.
 	RewriteContainerConfig(ctr *Container, newCfg *ContainerConfig) error
 	// PLEASE READ THE DESCRIPTION FOR RewriteContainerConfig BEFORE USING.
+	// This function is identical to RewriteContainerConfig, save that the
+	// CNI networks of the container may differ between the old and new
+	// configurations. The dependencies of the networks in the state are
+	// updated to match the new configuration.
+	RewriteContainerNetworks(ctr *Container, newCfg *ContainerConfig) error
+	// PLEASE READ THE DESCRIPTION FOR RewriteContainerConfig BEFORE USING.
 	// This function is identical to RewriteContainerConfig, save for the
 	// fact that it is used with pods instead.
 	// It is subject to the same conditions as RewriteContainerConfig.
@@ -149,6 +254,9 @@ type State interface {
 	// Get all the containers in a pod.
 	// The pod must be part of the set namespace.
 	PodContainers(pod *Pod) ([]*Container, error)
+	// Get the init containers of a pod.
+	// The pod must be part of the set namespace.
+	PodInitContainers(pod *Pod) ([]*Container, error)
 	// Adds pod to state.
 	// The pod must be part of the set namespace.
 	// The pod's name and ID must be globally unique.
@@ -203,6 +311,39 @@ type State interface {
 	// RemoveVolume removes the specified volume.
 	// Only volumes that have no container dependencies can be removed
 	RemoveVolume(volume *Volume) error
+	// UpdateVolume updates the volume's state from the database.
+	UpdateVolume(volume *Volume) error
+	// SaveVolume saves a volume's state to the database.
+	SaveVolume(volume *Volume) error
 	// AllVolumes returns all the volumes available in the state
 	AllVolumes() ([]*Volume, error)
+
+	// Network accepts full name of network
+	// If the network doesn't exist, an error will be returned
+	Network(netName string) (*Network, error)
+	// HasNetwork returns true if netName exists in the state,
+	// otherwise it returns false
+	HasNetwork(netName string) (bool, error)
+	// NetworkInUse goes through the container dependencies of a network
+	// and checks if the network is being used by any container. If it is
+	// a slice of container IDs using the network is returned
+	NetworkInUse(network *Network) ([]string, error)
+	// AddNetwork adds the specified network to state. The network's name
+	// must be unique within the list of existing networks
+	AddNetwork(network *Network) error
+	// RemoveNetwork removes the specified network.
+	// Only networks that have no container dependencies can be removed
+	RemoveNetwork(network *Network) error
+	// AllNetworks returns all the networks available in the state
+	AllNetworks() ([]*Network, error)
+
+	// PodTemplate retrieves the pod template with the given name.
+	PodTemplate(name string) (*PodTemplate, error)
+	// AddPodTemplate adds the given pod template to the state. Its name must
+	// be unique among pod templates.
+	AddPodTemplate(tmpl *PodTemplate) error
+	// RemovePodTemplate removes the pod template with the given name.
+	RemovePodTemplate(name string) error
+	// AllPodTemplates returns all the pod templates in the state.
+	AllPodTemplates() ([]*PodTemplate, error)
 }
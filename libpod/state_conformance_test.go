@@ -0,0 +1,99 @@
+package libpod_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/containers/libpod/libpod/statetest"
+)
+
+// stateBackends returns the State implementations of libpod to run the state
+// test suite against.
+func stateBackends() []statetest.Backend {
+	// Objects share the locks of a single manager, see NewTestContainer.
+	// The manager does not synchronize freeing locks, so objects are built
+	// one at a time.
+	manager, err := lock.NewInMemoryManager(16)
+	if err != nil {
+		panic(err)
+	}
+	var managerLock sync.Mutex
+	newContainer := func(id, name, pod string, deps []string) (*libpod.Container, error) {
+		managerLock.Lock()
+		defer managerLock.Unlock()
+		return libpod.NewTestContainer(id, name, pod, deps, manager)
+	}
+	newPod := func(id, name string) (*libpod.Pod, error) {
+		managerLock.Lock()
+		defer managerLock.Unlock()
+		return libpod.NewTestPod(id, name, manager)
+	}
+
+	return []statetest.Backend{
+		{
+			Name: "in-memory",
+			New: func() (*statetest.Instance, error) {
+				state, err := libpod.NewInMemoryState()
+				if err != nil {
+					return nil, err
+				}
+				return &statetest.Instance{State: state, Cleanup: func() {}}, nil
+			},
+			NewContainer: newContainer,
+			NewPod:       newPod,
+		},
+		{
+			Name:       "boltdb",
+			Concurrent: true,
+			New: func() (*statetest.Instance, error) {
+				tmpDir, err := ioutil.TempDir("", "libpod_statetest_")
+				if err != nil {
+					return nil, err
+				}
+				dbPath := filepath.Join(tmpDir, "bolt_state.db")
+				state, err := libpod.NewTestBoltState(dbPath, manager)
+				if err != nil {
+					os.RemoveAll(tmpDir)
+					return nil, err
+				}
+
+				var reopened []libpod.State
+				return &statetest.Instance{
+					State: state,
+					Reopen: func() (libpod.State, error) {
+						state, err := libpod.NewTestBoltState(dbPath, manager)
+						if err == nil {
+							reopened = append(reopened, state)
+						}
+						return state, err
+					},
+					Cleanup: func() {
+						for _, state := range reopened {
+							state.Close()
+						}
+						os.RemoveAll(tmpDir)
+					},
+				}, nil
+			},
+			NewContainer: newContainer,
+			NewPod:       newPod,
+		},
+	}
+}
+
+func TestStateConformance(t *testing.T) {
+	for _, backend := range stateBackends() {
+		statetest.Run(t, backend)
+	}
+}
+
+func BenchmarkState(b *testing.B) {
+	for _, backend := range stateBackends() {
+		statetest.Benchmark(b, backend)
+	}
+}
@@ -0,0 +1,97 @@
+package libpod
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// stateSyncJitter is the fraction of each wait of the state sync by which it
+// is randomly lengthened or shortened, so that several processes syncing the
+// same containers do not query the OCI runtime in lockstep.
+const stateSyncJitter = 0.2
+
+// RunStateSync reconciles the status of externally-managed containers in the
+// database with the OCI runtime until the context is cancelled, so commands
+// such as ps report their actual status.
+// Each container is synced about once per interval. Syncs are spread evenly
+// across the interval rather than made all at once, and every wait between
+// them is jittered, so the OCI runtime is not queried in bursts.
+func (r *Runtime) RunStateSync(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "state sync interval must be greater than 0")
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		ctrs, err := r.GetContainers(func(c *Container) bool {
+			return c.ExternallyManaged()
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(ctrs) == 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(jitterDuration(interval, stateSyncJitter, rng)):
+			}
+			continue
+		}
+
+		gap := interval / time.Duration(len(ctrs))
+		for _, ctr := range ctrs {
+			if err := ctr.syncExternalState(); err != nil && !isRemovedError(err) {
+				logrus.Errorf("Error syncing state of container %s: %v", ctr.ID(), err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(jitterDuration(gap, stateSyncJitter, rng)):
+			}
+		}
+	}
+}
+
+// syncExternalState updates the container's status in the database from the
+// OCI runtime.
+// Unlike syncContainer, the OCI runtime is always queried, as the processes
+// of the container may not be monitored by a conmon that writes an exit file.
+func (c *Container) syncExternalState() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.runtime.state.UpdateContainer(c); err != nil {
+		return err
+	}
+	if !c.valid {
+		return errors.Wrapf(define.ErrCtrRemoved, "container %s is not valid", c.ID())
+	}
+
+	switch c.state.State {
+	case define.ContainerStateUnknown, define.ContainerStateConfigured, define.ContainerStateExited, define.ContainerStateRemoving:
+		return nil
+	}
+
+	oldState := c.state.State
+	if err := c.ociRuntime.UpdateContainerStatus(c, true); err != nil {
+		return err
+	}
+	if c.state.State == oldState {
+		return nil
+	}
+	logrus.Debugf("Synced container %s state from %s to %s", c.ID(), oldState.String(), c.state.State.String())
+	return c.save()
+}
+
+// jitterDuration randomly lengthens or shortens d by up to the given fraction
+// of it
+func jitterDuration(d time.Duration, fraction float64, rng *rand.Rand) time.Duration {
+	return d + time.Duration((rng.Float64()*2-1)*fraction*float64(d))
+}
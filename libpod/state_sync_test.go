@@ -0,0 +1,20 @@
+package libpod
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJitterDuration(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		d := jitterDuration(10*time.Second, 0.2, rng)
+		assert.True(t, d >= 8*time.Second, "%s shorter than allowed", d)
+		assert.True(t, d <= 12*time.Second, "%s longer than allowed", d)
+	}
+
+	assert.Equal(t, 10*time.Second, jitterDuration(10*time.Second, 0, rng))
+}
@@ -2,6 +2,7 @@ package libpod
 
 import (
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +12,8 @@ import (
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/lock"
 	"github.com/containers/storage"
+	"github.com/containers/storage/pkg/idtools"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -181,6 +184,89 @@ func TestAddDuplicateCtrIDFails(t *testing.T) {
 	})
 }
 
+func TestAddCtrDuplicateStaticAddressFails(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		runtime := &Runtime{config: &RuntimeConfig{CNIDefaultNetwork: "podman"}}
+
+		testCtr1, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+		testCtr1.runtime = runtime
+		testCtr1.config.StaticIP = net.ParseIP("10.88.0.5")
+		testCtr1.config.StaticMAC, err = net.ParseMAC("92:d0:c6:0a:29:33")
+		assert.NoError(t, err)
+
+		testCtr2, err := getTestCtr2(manager)
+		assert.NoError(t, err)
+		testCtr2.runtime = runtime
+		testCtr2.config.StaticIP = net.ParseIP("10.88.0.5")
+
+		testCtr3, err := getTestCtrN("3", manager)
+		assert.NoError(t, err)
+		testCtr3.runtime = runtime
+		testCtr3.config.StaticMAC = testCtr1.config.StaticMAC
+
+		err = state.AddContainer(testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr2)
+		assert.Error(t, err)
+		assert.Equal(t, define.ErrAddressInUse, errors.Cause(err))
+
+		err = state.AddContainer(testCtr3)
+		assert.Error(t, err)
+		assert.Equal(t, define.ErrAddressInUse, errors.Cause(err))
+
+		ctrs, err := state.AllContainers()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(ctrs))
+
+		// Removing the container releases its addresses
+		err = state.RemoveContainer(testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr2)
+		assert.NoError(t, err)
+	})
+}
+
+func TestStaticAddressOnJoinedNetwork(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		runtime := &Runtime{config: &RuntimeConfig{CNIDefaultNetwork: "podman"}}
+		mac, err := net.ParseMAC("92:d0:c6:0a:29:33")
+		assert.NoError(t, err)
+
+		// The same MAC may be requested on different networks
+		testCtr1, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+		testCtr1.runtime = runtime
+		testCtr1.config.StaticMAC = mac
+
+		testCtr2, err := getTestCtr2(manager)
+		assert.NoError(t, err)
+		testCtr2.runtime = runtime
+		testCtr2.config.Networks = []string{"net1"}
+		testCtr2.config.StaticMAC = mac
+
+		err = state.AddContainer(testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr2)
+		assert.NoError(t, err)
+	})
+}
+
+func TestStaticAddressWithoutNetworkFails(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+		testCtr.runtime = &Runtime{config: &RuntimeConfig{}}
+		testCtr.config.StaticIP = net.ParseIP("10.88.0.5")
+
+		err = state.AddContainer(testCtr)
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+	})
+}
+
 func TestAddDuplicateCtrNameFails(t *testing.T) {
 	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
 		testCtr1, err := getTestCtr1(manager)
@@ -934,6 +1020,259 @@ func TestGetAllContainersNoContainerInNamespace(t *testing.T) {
 	})
 }
 
+func TestGetAllContainersSummariesTracksSavedState(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		summaries, err := state.AllContainersSummaries()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(summaries))
+		assert.Equal(t, testCtr.ID(), summaries[0].ID)
+		assert.Equal(t, testCtr.Name(), summaries[0].Name)
+		assert.Equal(t, "testimg", summaries[0].ImageName)
+		assert.Equal(t, define.ContainerStateRunning, summaries[0].State)
+
+		testCtr.state.State = define.ContainerStateStopped
+		err = state.SaveContainer(testCtr)
+		assert.NoError(t, err)
+
+		summaries, err = state.AllContainersSummaries()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(summaries))
+		assert.Equal(t, define.ContainerStateStopped, summaries[0].State)
+	})
+}
+
+func TestGetAllContainersSummariesNoContainerInNamespace(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		testCtr.config.Namespace = "test1"
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		state.SetNamespace("test2")
+
+		summaries, err := state.AllContainersSummaries()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(summaries))
+	})
+}
+
+func TestPendingRemovalsDroppedWithContainer(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		err = state.AddPendingRemoval(testCtr)
+		assert.NoError(t, err)
+
+		pending, err := state.PendingRemovals()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(pending))
+		queued, ok := pending[testCtr.ID()]
+		assert.True(t, ok)
+
+		// Queueing again keeps the original time
+		err = state.AddPendingRemoval(testCtr)
+		assert.NoError(t, err)
+		pending, err = state.PendingRemovals()
+		assert.NoError(t, err)
+		assert.True(t, queued.Equal(pending[testCtr.ID()]))
+
+		err = state.RemoveContainer(testCtr)
+		assert.NoError(t, err)
+
+		pending, err = state.PendingRemovals()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(pending))
+	})
+}
+
+func TestPendingRemovalsNoContainerInNamespace(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		testCtr.config.Namespace = "test1"
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		err = state.AddPendingRemoval(testCtr)
+		assert.NoError(t, err)
+
+		state.SetNamespace("test2")
+
+		pending, err := state.PendingRemovals()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(pending))
+	})
+}
+
+func TestExecSessionsDroppedWithContainer(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		session := &ExecSession{ID: "session1", PID: 1234, Detached: true}
+		err = state.AddExecSession(testCtr, session)
+		assert.NoError(t, err)
+
+		err = state.AddExecSession(testCtr, session)
+		assert.Equal(t, define.ErrExecSessionExists, errors.Cause(err))
+
+		ctrID, err := state.ExecSessionContainer(session.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, testCtr.ID(), ctrID)
+
+		sessions, err := state.AllExecSessions()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{session.ID: testCtr.ID()}, sessions)
+
+		err = state.RemoveContainer(testCtr)
+		assert.NoError(t, err)
+
+		_, err = state.ExecSessionContainer(session.ID)
+		assert.Equal(t, define.ErrNoSuchExecSession, errors.Cause(err))
+	})
+}
+
+func TestRemoveExecSession(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		err = state.AddExecSession(testCtr, &ExecSession{ID: "session1"})
+		assert.NoError(t, err)
+
+		err = state.RemoveExecSession("session1")
+		assert.NoError(t, err)
+
+		err = state.RemoveExecSession("session1")
+		assert.Equal(t, define.ErrNoSuchExecSession, errors.Cause(err))
+
+		sessions, err := state.AllExecSessions()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(sessions))
+	})
+}
+
+func TestContainerExitCodeOutlivesContainer(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		_, err = state.GetContainerExitCode(testCtr.ID())
+		assert.Equal(t, define.ErrNoSuchExitCode, errors.Cause(err))
+
+		err = state.AddContainerExitCode(testCtr.ID(), 1)
+		assert.NoError(t, err)
+
+		err = state.AddContainerExitCode(testCtr.ID(), 137)
+		assert.NoError(t, err)
+
+		err = state.RemoveContainer(testCtr)
+		assert.NoError(t, err)
+
+		exitCode, err := state.GetContainerExitCode(testCtr.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, int32(137), exitCode)
+	})
+}
+
+func TestPruneContainerExitCodes(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		err := state.AddContainerExitCode("ctr1", 0)
+		assert.NoError(t, err)
+
+		before := time.Now()
+
+		err = state.AddContainerExitCode("ctr2", 2)
+		assert.NoError(t, err)
+
+		err = state.PruneContainerExitCodes(before)
+		assert.NoError(t, err)
+
+		_, err = state.GetContainerExitCode("ctr1")
+		assert.Equal(t, define.ErrNoSuchExitCode, errors.Cause(err))
+
+		exitCode, err := state.GetContainerExitCode("ctr2")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), exitCode)
+	})
+}
+
+func TestUserNSRangesNotReused(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		pool := []idtools.IDMap{{HostID: 100000, Size: 3000}}
+
+		first, err := state.AllocateUserNSRange("ctr1", 1000, pool, pool)
+		assert.NoError(t, err)
+		assert.Equal(t, 100000, first.HostUID)
+
+		_, err = state.AllocateUserNSRange("ctr1", 1000, pool, pool)
+		assert.Equal(t, define.ErrCtrExists, errors.Cause(err))
+
+		second, err := state.AllocateUserNSRange("ctr2", 1000, pool, pool)
+		assert.NoError(t, err)
+		assert.Equal(t, 101000, second.HostUID)
+		assert.Equal(t, 101000, second.HostGID)
+
+		_, err = state.AllocateUserNSRange("ctr3", 2000, pool, pool)
+		assert.Equal(t, define.ErrUserNSExhausted, errors.Cause(err))
+
+		err = state.ReleaseUserNSRange("ctr1")
+		assert.NoError(t, err)
+
+		third, err := state.AllocateUserNSRange("ctr3", 1000, pool, pool)
+		assert.NoError(t, err)
+		assert.Equal(t, 100000, third.HostUID)
+	})
+}
+
+func TestExecSessionsNoContainerInNamespace(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+
+		testCtr.config.Namespace = "test1"
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		err = state.AddExecSession(testCtr, &ExecSession{ID: "session1"})
+		assert.NoError(t, err)
+
+		state.SetNamespace("test2")
+
+		_, err = state.ExecSessionContainer("session1")
+		assert.Equal(t, define.ErrNoSuchExecSession, errors.Cause(err))
+
+		sessions, err := state.AllExecSessions()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(sessions))
+	})
+}
+
 func TestGetContainerOneContainerInNamespace(t *testing.T) {
 	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
 		testCtr1, err := getTestCtr1(manager)
@@ -1065,6 +1404,48 @@ func TestContainerInUseOneContainerMultipleDependencies(t *testing.T) {
 	})
 }
 
+func TestContainerDependencyConditions(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr1, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+		testCtr2, err := getTestCtr2(manager)
+		assert.NoError(t, err)
+		testCtr3, err := getTestCtrN("3", manager)
+		assert.NoError(t, err)
+
+		testCtr2.config.UserNsCtr = testCtr1.config.ID
+		testCtr2.config.DependencyConditions = map[string]define.DependencyCondition{
+			testCtr1.config.ID: define.DependencyConditionHealthy,
+		}
+		testCtr3.config.IPCNsCtr = testCtr1.config.ID
+
+		err = state.AddContainer(testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr2)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr3)
+		assert.NoError(t, err)
+
+		conditions, err := state.ContainerDependencyConditions(testCtr1)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]define.DependencyCondition{
+			testCtr2.config.ID: define.DependencyConditionHealthy,
+			testCtr3.config.ID: define.DependencyConditionRunning,
+		}, conditions)
+
+		err = state.RemoveContainer(testCtr2)
+		assert.NoError(t, err)
+
+		conditions, err = state.ContainerDependencyConditions(testCtr1)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]define.DependencyCondition{
+			testCtr3.config.ID: define.DependencyConditionRunning,
+		}, conditions)
+	})
+}
+
 func TestContainerInUseGenericDependency(t *testing.T) {
 	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
 		testCtr1, err := getTestCtr1(manager)
@@ -1335,6 +1716,40 @@ func TestRewriteContainerConfigRewritesConfig(t *testing.T) {
 	})
 }
 
+func TestRewriteContainerNetworksUpdatesDependencies(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testNet1 := &Network{config: &NetworkConfig{Name: "net1", ConfigPath: "/etc/cni/net.d/net1.conflist"}, valid: true}
+		testNet2 := &Network{config: &NetworkConfig{Name: "net2", ConfigPath: "/etc/cni/net.d/net2.conflist"}, valid: true}
+		assert.NoError(t, state.AddNetwork(testNet1))
+		assert.NoError(t, state.AddNetwork(testNet2))
+
+		testCtr, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+		testCtr.config.Networks = []string{"net1"}
+
+		err = state.AddContainer(testCtr)
+		assert.NoError(t, err)
+
+		newCfg := *testCtr.config
+		newCfg.Networks = []string{"net2", "unmanaged"}
+
+		err = state.RewriteContainerNetworks(testCtr, &newCfg)
+		assert.NoError(t, err)
+
+		ctrs, err := state.NetworkInUse(testNet1)
+		assert.NoError(t, err)
+		assert.Empty(t, ctrs)
+
+		ctrs, err = state.NetworkInUse(testNet2)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{testCtr.ID()}, ctrs)
+
+		testCtrFromState, err := state.Container(testCtr.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, newCfg.Networks, testCtrFromState.config.Networks)
+	})
+}
+
 func TestRewritePodConfigDoesNotExist(t *testing.T) {
 	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
 		err := state.RewritePodConfig(&Pod{}, &PodConfig{})
@@ -2486,6 +2901,47 @@ func TestPodContainersPodNotInNamespace(t *testing.T) {
 	})
 }
 
+func TestPodInitContainers(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testPod, err := getTestPod1(manager)
+		assert.NoError(t, err)
+
+		testCtr1, err := getTestCtr2(manager)
+		assert.NoError(t, err)
+		testCtr1.config.Pod = testPod.ID()
+
+		testCtr2, err := getTestCtrN("3", manager)
+		assert.NoError(t, err)
+		testCtr2.config.Pod = testPod.ID()
+		testCtr2.config.InitContainerType = define.AlwaysInitContainer
+
+		err = state.AddPod(testPod)
+		assert.NoError(t, err)
+
+		initCtrs, err := state.PodInitContainers(testPod)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(initCtrs))
+
+		err = state.AddContainerToPod(testPod, testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainerToPod(testPod, testCtr2)
+		assert.NoError(t, err)
+
+		initCtrs, err = state.PodInitContainers(testPod)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(initCtrs))
+		testContainersEqual(t, initCtrs[0], testCtr2, true)
+
+		err = state.RemoveContainerFromPod(testPod, testCtr2)
+		assert.NoError(t, err)
+
+		initCtrs, err = state.PodInitContainers(testPod)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(initCtrs))
+	})
+}
+
 func TestRemovePodContainersInvalidPod(t *testing.T) {
 	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
 		err := state.RemovePodContainers(&Pod{config: &PodConfig{}})
@@ -2659,6 +3115,102 @@ func TestRemoveContainersNotInNamespace(t *testing.T) {
 	})
 }
 
+func TestRemoveContainersWithDependencyBetween(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr1, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+		testCtr2, err := getTestCtr2(manager)
+		assert.NoError(t, err)
+
+		testCtr2.config.UserNsCtr = testCtr1.config.ID
+
+		err = state.AddContainer(testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr2)
+		assert.NoError(t, err)
+
+		err = state.RemoveContainers([]*Container{testCtr1, testCtr2})
+		assert.NoError(t, err)
+
+		ctrs, err := state.AllContainers()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(ctrs))
+
+		exists, err := state.HasContainer(testCtr1.ID())
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestRemoveContainersWithDependencyOutsideFails(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testCtr1, err := getTestCtr1(manager)
+		assert.NoError(t, err)
+		testCtr2, err := getTestCtr2(manager)
+		assert.NoError(t, err)
+		testCtr3, err := getTestCtrN("3", manager)
+		assert.NoError(t, err)
+
+		testCtr2.config.UserNsCtr = testCtr1.config.ID
+
+		err = state.AddContainer(testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr2)
+		assert.NoError(t, err)
+
+		err = state.AddContainer(testCtr3)
+		assert.NoError(t, err)
+
+		err = state.RemoveContainers([]*Container{testCtr3, testCtr1})
+		assert.Error(t, err)
+
+		ctrs, err := state.AllContainers()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(ctrs))
+	})
+}
+
+func TestRemoveContainersInPod(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		testPod, err := getTestPod1(manager)
+		assert.NoError(t, err)
+
+		testCtr1, err := getTestCtr2(manager)
+		assert.NoError(t, err)
+		testCtr1.config.Pod = testPod.ID()
+
+		testCtr2, err := getTestCtrN("3", manager)
+		assert.NoError(t, err)
+		testCtr2.config.Pod = testPod.ID()
+		testCtr2.config.IPCNsCtr = testCtr1.ID()
+
+		err = state.AddPod(testPod)
+		assert.NoError(t, err)
+
+		err = state.AddContainerToPod(testPod, testCtr1)
+		assert.NoError(t, err)
+
+		err = state.AddContainerToPod(testPod, testCtr2)
+		assert.NoError(t, err)
+
+		err = state.RemoveContainers([]*Container{testCtr1, testCtr2})
+		assert.NoError(t, err)
+
+		ctrs, err := state.PodContainersByID(testPod)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(ctrs))
+
+		allCtrs, err := state.AllContainers()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(allCtrs))
+
+		err = state.RemovePod(testPod)
+		assert.NoError(t, err)
+	})
+}
+
 func TestAddContainerToPodInvalidPod(t *testing.T) {
 	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
 		testCtr, err := getTestCtr1(manager)
@@ -3599,3 +4151,45 @@ func TestSaveAndUpdatePodSameNamespace(t *testing.T) {
 		testPodsEqual(t, testPod, statePod, false)
 	})
 }
+
+func TestAddGetAndRemovePodTemplate(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		tmpl := &PodTemplate{
+			Name: "web",
+			Pod:  &PodConfig{Hostname: "web", Labels: map[string]string{"tier": "frontend"}},
+			Containers: []*PodTemplateContainer{
+				{Config: &ContainerConfig{ID: "ctr1", Name: "app"}},
+			},
+		}
+
+		_, err := state.PodTemplate("web")
+		assert.Equal(t, define.ErrNoSuchPodTemplate, errors.Cause(err))
+
+		err = state.AddPodTemplate(tmpl)
+		assert.NoError(t, err)
+
+		err = state.AddPodTemplate(tmpl)
+		assert.Equal(t, define.ErrPodTemplateExists, errors.Cause(err))
+
+		stateTmpl, err := state.PodTemplate("web")
+		assert.NoError(t, err)
+		assert.Equal(t, "web", stateTmpl.Pod.Hostname)
+		assert.Equal(t, "frontend", stateTmpl.Pod.Labels["tier"])
+		assert.Len(t, stateTmpl.Containers, 1)
+		assert.Equal(t, "app", stateTmpl.Containers[0].Config.Name)
+
+		allTmpls, err := state.AllPodTemplates()
+		assert.NoError(t, err)
+		assert.Len(t, allTmpls, 1)
+
+		err = state.RemovePodTemplate("web")
+		assert.NoError(t, err)
+
+		err = state.RemovePodTemplate("web")
+		assert.Equal(t, define.ErrNoSuchPodTemplate, errors.Cause(err))
+
+		allTmpls, err = state.AllPodTemplates()
+		assert.NoError(t, err)
+		assert.Len(t, allTmpls, 0)
+	})
+}
@@ -0,0 +1,56 @@
+package libpod
+
+import (
+	"sync"
+)
+
+// containerStateWatch notifies waiters in this process when the state of a
+// container changes. Changes made by other processes are not seen here; they
+// must be detected through the files the changes touch.
+type containerStateWatch struct {
+	lock     sync.Mutex
+	watchers map[string]map[chan struct{}]bool
+}
+
+// watch returns a channel receiving a value after each change to the state of
+// the container with the given ID, and a function that stops the watch.
+// Notifications are coalesced: a waiter that falls behind receives a single
+// value, and should re-read the state of the container.
+func (w *containerStateWatch) watch(id string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.watchers == nil {
+		w.watchers = make(map[string]map[chan struct{}]bool)
+	}
+	if w.watchers[id] == nil {
+		w.watchers[id] = make(map[chan struct{}]bool)
+	}
+	w.watchers[id][ch] = true
+
+	return ch, func() {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+
+		delete(w.watchers[id], ch)
+		if len(w.watchers[id]) == 0 {
+			delete(w.watchers, id)
+		}
+	}
+}
+
+// notify wakes all waiters watching the container with the given ID.
+// It never blocks.
+func (w *containerStateWatch) notify(id string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for ch := range w.watchers[id] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerStateWatchCoalescesNotifications(t *testing.T) {
+	var watch containerStateWatch
+
+	ch, stop := watch.watch("ctr1")
+	other, stopOther := watch.watch("ctr2")
+	defer stopOther()
+
+	watch.notify("ctr1")
+	watch.notify("ctr1")
+
+	assert.Len(t, ch, 1)
+	assert.Len(t, other, 0)
+
+	<-ch
+	stop()
+	watch.notify("ctr1")
+	assert.Len(t, ch, 0)
+	assert.NotContains(t, watch.watchers, "ctr1")
+}
+
+func TestStringToWaitCondition(t *testing.T) {
+	for _, condition := range []WaitCondition{WaitConditionStopped, WaitConditionNextExit, WaitConditionRemoved, WaitConditionRunning, WaitConditionHealthy} {
+		parsed, err := StringToWaitCondition(condition.String())
+		assert.NoError(t, err)
+		assert.Equal(t, condition, parsed)
+	}
+
+	_, err := StringToWaitCondition("exited")
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package statetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/storage/pkg/stringid"
+)
+
+// benchmarkContainers is the number of containers in the State during the
+// lookup benchmarks.
+const benchmarkContainers = 128
+
+// Benchmark runs the benchmarks of the suite against the backend, as
+// sub-benchmarks named after it.
+func Benchmark(b *testing.B, backend Backend) {
+	b.Run(backend.Name, func(b *testing.B) {
+		b.Run("AddContainer", func(b *testing.B) {
+			benchmarkWithInstance(b, backend, benchmarkAddContainer)
+		})
+		b.Run("RemoveContainer", func(b *testing.B) {
+			benchmarkWithInstance(b, backend, benchmarkRemoveContainer)
+		})
+		b.Run("LookupContainer", func(b *testing.B) {
+			benchmarkWithInstance(b, backend, benchmarkLookupContainer)
+		})
+		b.Run("ParallelLookupContainer", func(b *testing.B) {
+			benchmarkWithInstance(b, backend, benchmarkParallelLookupContainer)
+		})
+		b.Run("AllContainers", func(b *testing.B) {
+			benchmarkWithInstance(b, backend, benchmarkAllContainers)
+		})
+		b.Run("Load", func(b *testing.B) {
+			benchmarkWithInstance(b, backend, benchmarkLoad)
+		})
+	})
+}
+
+// benchmarkWithInstance runs the benchmark against a new, empty State of the
+// backend.
+func benchmarkWithInstance(b *testing.B, backend Backend, bench func(*testing.B, Backend, *Instance)) {
+	inst, err := backend.New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer inst.Cleanup()
+	defer inst.State.Close()
+
+	bench(b, backend, inst)
+}
+
+// newContainers creates n containers, which are not added to any State.
+func newContainers(b *testing.B, backend Backend, n int) []*libpod.Container {
+	ctrs := make([]*libpod.Container, 0, n)
+	for i := 0; i < n; i++ {
+		id := stringid.GenerateNonCryptoID()
+		ctr, err := backend.NewContainer(id, fmt.Sprintf("bench-%d-%s", i, id[:12]), "", nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ctrs = append(ctrs, ctr)
+	}
+	return ctrs
+}
+
+// addContainers creates n containers and adds them to the State.
+func addContainers(b *testing.B, backend Backend, state libpod.State, n int) []*libpod.Container {
+	ctrs := newContainers(b, backend, n)
+	for _, ctr := range ctrs {
+		if err := state.AddContainer(ctr); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return ctrs
+}
+
+func benchmarkAddContainer(b *testing.B, backend Backend, inst *Instance) {
+	ctrs := newContainers(b, backend, b.N)
+	b.ResetTimer()
+	for _, ctr := range ctrs {
+		if err := inst.State.AddContainer(ctr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkRemoveContainer(b *testing.B, backend Backend, inst *Instance) {
+	ctrs := addContainers(b, backend, inst.State, b.N)
+	b.ResetTimer()
+	for _, ctr := range ctrs {
+		if err := inst.State.RemoveContainer(ctr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkLookupContainer(b *testing.B, backend Backend, inst *Instance) {
+	ctrs := addContainers(b, backend, inst.State, benchmarkContainers)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := inst.State.LookupContainer(ctrs[i%len(ctrs)].Name()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkParallelLookupContainer(b *testing.B, backend Backend, inst *Instance) {
+	ctrs := addContainers(b, backend, inst.State, benchmarkContainers)
+	lock := backend.locker()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			lock.Lock()
+			_, err := inst.State.LookupContainer(ctrs[i%len(ctrs)].Name())
+			lock.Unlock()
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func benchmarkAllContainers(b *testing.B, backend Backend, inst *Instance) {
+	addContainers(b, backend, inst.State, benchmarkContainers)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := inst.State.AllContainers(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkLoad measures a parallel workload adding, looking up and removing
+// b.N containers. Building the containers is included in the measure.
+func benchmarkLoad(b *testing.B, backend Backend, inst *Instance) {
+	const workers = 4
+	b.ResetTimer()
+	_, err := GenerateLoad(backend, inst.State, LoadConfig{
+		Workers:     workers,
+		Containers:  b.N/workers + 1,
+		Lookups:     2,
+		RemoveRatio: 1,
+		Seed:        int64(b.N),
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+}
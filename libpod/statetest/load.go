@@ -0,0 +1,171 @@
+package statetest
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/storage/pkg/stringid"
+	"github.com/pkg/errors"
+)
+
+// LoadConfig describes the workload made by GenerateLoad.
+type LoadConfig struct {
+	// Workers is the number of goroutines making operations in parallel.
+	Workers int
+	// Containers is the number of containers each worker adds.
+	Containers int
+	// Lookups is the number of lookups of its own containers each worker
+	// makes after adding each container. Lookups are made by ID, by name
+	// and by existence, in turn.
+	Lookups int
+	// RemoveRatio is the fraction of its containers, between 0 and 1, that
+	// each worker removes once it has added all of them.
+	RemoveRatio float64
+	// Seed seeds the random choices of the workers, so that a workload can
+	// be repeated.
+	Seed int64
+}
+
+// LoadStats reports the operations made by GenerateLoad.
+type LoadStats struct {
+	// Adds is the number of containers added.
+	Adds int
+	// Lookups is the number of containers looked up.
+	Lookups int
+	// Removes is the number of containers removed.
+	Removes int
+	// Duration is the time taken by the workload.
+	Duration time.Duration
+	// Remaining holds the sorted IDs of the containers added and not
+	// removed.
+	Remaining []string
+}
+
+// GenerateLoad adds, looks up and removes containers in the State from
+// several goroutines at once, as described by the config. Every lookup is
+// verified to return the expected container.
+// The first error met by a worker is returned, after all workers are done.
+func GenerateLoad(backend Backend, state libpod.State, config LoadConfig) (*LoadStats, error) {
+	if config.Workers < 1 || config.Containers < 0 || config.Lookups < 0 {
+		return nil, errors.Errorf("invalid load config %+v", config)
+	}
+	if config.RemoveRatio < 0 || config.RemoveRatio > 1 {
+		return nil, errors.Errorf("remove ratio %f must be between 0 and 1", config.RemoveRatio)
+	}
+
+	var (
+		lock    = backend.locker()
+		wg      sync.WaitGroup
+		results = make([]*LoadStats, config.Workers)
+		errs    = make([]error, config.Workers)
+	)
+	start := time.Now()
+	for i := 0; i < config.Workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(config.Seed + int64(worker)))
+			results[worker], errs[worker] = runWorker(backend, state, lock, config, rng)
+		}(i)
+	}
+	wg.Wait()
+
+	stats := new(LoadStats)
+	stats.Duration = time.Since(start)
+	for i := 0; i < config.Workers; i++ {
+		if errs[i] != nil {
+			return nil, errors.Wrapf(errs[i], "load worker %d failed", i)
+		}
+		stats.Adds += results[i].Adds
+		stats.Lookups += results[i].Lookups
+		stats.Removes += results[i].Removes
+		stats.Remaining = append(stats.Remaining, results[i].Remaining...)
+	}
+	sort.Strings(stats.Remaining)
+	return stats, nil
+}
+
+// runWorker makes the operations of a single worker of GenerateLoad.
+func runWorker(backend Backend, state libpod.State, lock sync.Locker, config LoadConfig, rng *rand.Rand) (*LoadStats, error) {
+	stats := new(LoadStats)
+	ctrs := make([]*libpod.Container, 0, config.Containers)
+
+	for i := 0; i < config.Containers; i++ {
+		id := stringid.GenerateNonCryptoID()
+		ctr, err := backend.NewContainer(id, "load-"+id[:12], "", nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating container %s", id)
+		}
+		lock.Lock()
+		err = state.AddContainer(ctr)
+		lock.Unlock()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error adding container %s", id)
+		}
+		ctrs = append(ctrs, ctr)
+		stats.Adds++
+
+		for j := 0; j < config.Lookups; j++ {
+			want := ctrs[rng.Intn(len(ctrs))]
+			if err := lookupContainer(state, lock, want, stats.Lookups); err != nil {
+				return nil, err
+			}
+			stats.Lookups++
+		}
+	}
+
+	rng.Shuffle(len(ctrs), func(i, j int) {
+		ctrs[i], ctrs[j] = ctrs[j], ctrs[i]
+	})
+	toRemove := int(config.RemoveRatio * float64(len(ctrs)))
+	for _, ctr := range ctrs[:toRemove] {
+		lock.Lock()
+		err := state.RemoveContainer(ctr)
+		lock.Unlock()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error removing container %s", ctr.ID())
+		}
+		stats.Removes++
+	}
+	for _, ctr := range ctrs[toRemove:] {
+		stats.Remaining = append(stats.Remaining, ctr.ID())
+	}
+	return stats, nil
+}
+
+// lookupContainer looks the container up in the State, in the way selected by
+// n, and verifies that it is found.
+func lookupContainer(state libpod.State, lock sync.Locker, want *libpod.Container, n int) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	var (
+		got *libpod.Container
+		err error
+	)
+	switch n % 3 {
+	case 0:
+		got, err = state.Container(want.ID())
+	case 1:
+		got, err = state.LookupContainer(want.Name())
+	default:
+		exists, err := state.HasContainer(want.ID())
+		if err != nil {
+			return errors.Wrapf(err, "error checking container %s exists", want.ID())
+		}
+		if !exists {
+			return errors.Errorf("container %s does not exist", want.ID())
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error looking up container %s", want.ID())
+	}
+	if got.ID() != want.ID() {
+		return errors.Errorf("looking up container %s returned container %s", want.ID(), got.ID())
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Package statetest provides a conformance and benchmark suite, with a load
+// generator, for implementations of the libpod State interface, so that
+// different state backends can be validated and compared in the same way.
+//
+// Containers and pods can only be built with libpod internals, so a Backend
+// provides the suite with constructors for them as well as for the State
+// under test. This package is only meant to be used by tests.
+package statetest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/pkg/errors"
+)
+
+// Instance is a State under test.
+type Instance struct {
+	// State is the State under test.
+	State libpod.State
+	// Reopen opens a new State from the storage backing State, as a
+	// process started after the process using State crashed would. State
+	// is not closed first. It is nil if the State does not persist its
+	// contents.
+	Reopen func() (libpod.State, error)
+	// Cleanup removes all resources used by the State. The suite closes
+	// the State, and any reopened State, before calling it.
+	Cleanup func()
+}
+
+// Backend is a State implementation to run the suite against.
+type Backend struct {
+	// Name identifies the backend in test and benchmark names.
+	Name string
+	// Concurrent is whether the State can be used by several goroutines at
+	// once. Parallel workloads are serialized for backends that cannot.
+	Concurrent bool
+	// New returns a new, empty State.
+	New func() (*Instance, error)
+	// NewContainer returns a valid container with the given ID and name
+	// that can be added to a State returned by New. If pod is not empty,
+	// the container is a member of the pod with that ID. The container
+	// depends on the containers with the IDs in deps.
+	// It may be called from several goroutines at once.
+	NewContainer func(id, name, pod string, deps []string) (*libpod.Container, error)
+	// NewPod returns a valid pod with the given ID and name that can be
+	// added to a State returned by New.
+	NewPod func(id, name string) (*libpod.Pod, error)
+}
+
+// locker returns the lock serializing the calls made to a State of the
+// backend by parallel workloads.
+func (b *Backend) locker() sync.Locker {
+	if b.Concurrent {
+		return noopLocker{}
+	}
+	return new(sync.Mutex)
+}
+
+// noopLocker is a sync.Locker that does nothing, for backends that can be
+// used concurrently.
+type noopLocker struct{}
+
+func (noopLocker) Lock()   {}
+func (noopLocker) Unlock() {}
+
+// CheckConsistency verifies that every container and pod in the State can be
+// retrieved by its ID and its name, and that the dependencies and pod
+// memberships of the containers agree with each other.
+func CheckConsistency(state libpod.State) error {
+	ctrs, err := state.AllContainers()
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving all containers")
+	}
+	for _, ctr := range ctrs {
+		byID, err := state.Container(ctr.ID())
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving container %s by ID", ctr.ID())
+		}
+		byName, err := state.LookupContainer(ctr.Name())
+		if err != nil {
+			return errors.Wrapf(err, "error looking up container %s by name %s", ctr.ID(), ctr.Name())
+		}
+		if byID.ID() != ctr.ID() || byName.ID() != ctr.ID() {
+			return errors.Errorf("container %s was retrieved as %s by ID and as %s by name", ctr.ID(), byID.ID(), byName.ID())
+		}
+
+		for _, dep := range ctr.Dependencies() {
+			exists, err := state.HasContainer(dep)
+			if err != nil {
+				return errors.Wrapf(err, "error checking dependency %s of container %s", dep, ctr.ID())
+			}
+			if !exists {
+				return errors.Errorf("dependency %s of container %s does not exist", dep, ctr.ID())
+			}
+			depCtr, err := state.Container(dep)
+			if err != nil {
+				return errors.Wrapf(err, "error retrieving dependency %s of container %s", dep, ctr.ID())
+			}
+			users, err := state.ContainerInUse(depCtr)
+			if err != nil {
+				return errors.Wrapf(err, "error retrieving users of container %s", dep)
+			}
+			if !contains(users, ctr.ID()) {
+				return errors.Errorf("container %s depends on container %s, which is not in use by it", ctr.ID(), dep)
+			}
+		}
+	}
+
+	pods, err := state.AllPods()
+	if err != nil {
+		return errors.Wrapf(err, "error retrieving all pods")
+	}
+	for _, pod := range pods {
+		byName, err := state.LookupPod(pod.Name())
+		if err != nil {
+			return errors.Wrapf(err, "error looking up pod %s by name %s", pod.ID(), pod.Name())
+		}
+		if byName.ID() != pod.ID() {
+			return errors.Errorf("pod %s was retrieved as %s by name", pod.ID(), byName.ID())
+		}
+		members, err := state.PodContainersByID(pod)
+		if err != nil {
+			return errors.Wrapf(err, "error retrieving containers of pod %s", pod.ID())
+		}
+		for _, id := range members {
+			ctr, err := state.Container(id)
+			if err != nil {
+				return errors.Wrapf(err, "error retrieving container %s of pod %s", id, pod.ID())
+			}
+			if ctr.PodID() != pod.ID() {
+				return errors.Errorf("container %s of pod %s is a member of pod %q", id, pod.ID(), ctr.PodID())
+			}
+		}
+	}
+	return nil
+}
+
+// containerIDs returns the sorted IDs of all containers in the State.
+func containerIDs(state libpod.State) ([]string, error) {
+	ctrs, err := state.AllContainers()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(ctrs))
+	for _, ctr := range ctrs {
+		ids = append(ids, ctr.ID())
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
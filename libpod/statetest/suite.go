@@ -0,0 +1,300 @@
+package statetest
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/pkg/stringid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run runs the conformance suite against the backend, as a subtest named
+// after it.
+func Run(t *testing.T, backend Backend) {
+	t.Run(backend.Name, func(t *testing.T) {
+		t.Run("ParallelAddRemoveLookup", func(t *testing.T) {
+			withInstance(t, backend, testParallelAddRemoveLookup)
+		})
+		t.Run("DependencyGraph", func(t *testing.T) {
+			withInstance(t, backend, testDependencyGraph)
+		})
+		t.Run("PodDependencies", func(t *testing.T) {
+			withInstance(t, backend, testPodDependencies)
+		})
+		t.Run("CrashRecovery", func(t *testing.T) {
+			withInstance(t, backend, testCrashRecovery)
+		})
+		t.Run("CrashRecoveryAfterFailedOperations", func(t *testing.T) {
+			withInstance(t, backend, testCrashRecoveryAfterFailedOperations)
+		})
+	})
+}
+
+// withInstance runs the test against a new, empty State of the backend.
+func withInstance(t *testing.T, backend Backend, test func(*testing.T, Backend, *Instance)) {
+	inst, err := backend.New()
+	require.NoError(t, err)
+	defer inst.Cleanup()
+	defer inst.State.Close()
+
+	test(t, backend, inst)
+}
+
+// reopen opens the State of the instance again, simulating a crash of the
+// process using it, or skips the test if the State does not persist.
+// The reopened State is closed when the test ends.
+func reopen(t *testing.T, inst *Instance) libpod.State {
+	if inst.Reopen == nil {
+		t.Skip("state does not persist its contents")
+	}
+	state, err := inst.Reopen()
+	require.NoError(t, err)
+	return state
+}
+
+func testParallelAddRemoveLookup(t *testing.T, backend Backend, inst *Instance) {
+	stats, err := GenerateLoad(backend, inst.State, LoadConfig{
+		Workers:     8,
+		Containers:  16,
+		Lookups:     4,
+		RemoveRatio: 0.5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8*16, stats.Adds)
+	assert.Equal(t, 8*16*4, stats.Lookups)
+	assert.Equal(t, 8*8, stats.Removes)
+
+	ids, err := containerIDs(inst.State)
+	require.NoError(t, err)
+	assert.Equal(t, stats.Remaining, ids)
+	assert.NoError(t, CheckConsistency(inst.State))
+}
+
+// addDependencyGraph adds containers to the State that each depend on up to
+// maxDeps randomly chosen containers added before them, and returns them in
+// the order they were added.
+func addDependencyGraph(backend Backend, state libpod.State, pod *libpod.Pod, n, maxDeps int, rng *rand.Rand) ([]*libpod.Container, error) {
+	podID := ""
+	if pod != nil {
+		podID = pod.ID()
+	}
+
+	ctrs := make([]*libpod.Container, 0, n)
+	for i := 0; i < n; i++ {
+		deps := make(map[string]bool)
+		if i > 0 {
+			for j := rng.Intn(maxDeps + 1); j > 0; j-- {
+				deps[ctrs[rng.Intn(i)].ID()] = true
+			}
+		}
+		depIDs := make([]string, 0, len(deps))
+		for dep := range deps {
+			depIDs = append(depIDs, dep)
+		}
+
+		id := stringid.GenerateNonCryptoID()
+		ctr, err := backend.NewContainer(id, fmt.Sprintf("graph-%d-%s", i, id[:12]), podID, depIDs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating container %s", id)
+		}
+		if pod != nil {
+			err = state.AddContainerToPod(pod, ctr)
+		} else {
+			err = state.AddContainer(ctr)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "error adding container %s", id)
+		}
+		ctrs = append(ctrs, ctr)
+	}
+	return ctrs, nil
+}
+
+// dependents maps the ID of each container to the IDs of the containers that
+// depend on it.
+func dependents(ctrs []*libpod.Container) map[string][]string {
+	users := make(map[string][]string)
+	for _, ctr := range ctrs {
+		for _, dep := range ctr.Dependencies() {
+			users[dep] = append(users[dep], ctr.ID())
+		}
+	}
+	return users
+}
+
+func testDependencyGraph(t *testing.T, backend Backend, inst *Instance) {
+	ctrs, err := addDependencyGraph(backend, inst.State, nil, 48, 3, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	require.NoError(t, CheckConsistency(inst.State))
+
+	users := dependents(ctrs)
+	for _, ctr := range ctrs {
+		inUse, err := inst.State.ContainerInUse(ctr)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, users[ctr.ID()], inUse, "users of container %s", ctr.ID())
+
+		// Containers that are in use cannot be removed, and their
+		// failed removal must not change the State
+		if len(users[ctr.ID()]) > 0 {
+			err := inst.State.RemoveContainer(ctr)
+			assert.Equal(t, define.ErrCtrExists, errors.Cause(err), "removing container %s in use", ctr.ID())
+		}
+	}
+
+	ids, err := containerIDs(inst.State)
+	require.NoError(t, err)
+	assert.Len(t, ids, len(ctrs))
+	require.NoError(t, CheckConsistency(inst.State))
+
+	// Removing the containers in the reverse order they were added in
+	// always removes users before their dependencies
+	for i := len(ctrs) - 1; i >= 0; i-- {
+		require.NoError(t, inst.State.RemoveContainer(ctrs[i]))
+	}
+	ids, err = containerIDs(inst.State)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func testPodDependencies(t *testing.T, backend Backend, inst *Instance) {
+	pod, err := backend.NewPod(stringid.GenerateNonCryptoID(), "graph-pod")
+	require.NoError(t, err)
+	require.NoError(t, inst.State.AddPod(pod))
+
+	ctrs, err := addDependencyGraph(backend, inst.State, pod, 16, 2, rand.New(rand.NewSource(2)))
+	require.NoError(t, err)
+	require.NoError(t, CheckConsistency(inst.State))
+
+	members, err := inst.State.PodContainersByID(pod)
+	require.NoError(t, err)
+	assert.Len(t, members, len(ctrs))
+
+	// A pod with containers cannot be removed
+	err = inst.State.RemovePod(pod)
+	assert.Equal(t, define.ErrCtrExists, errors.Cause(err))
+
+	users := dependents(ctrs)
+	for _, ctr := range ctrs {
+		if len(users[ctr.ID()]) > 0 {
+			err := inst.State.RemoveContainerFromPod(pod, ctr)
+			assert.Equal(t, define.ErrCtrExists, errors.Cause(err), "removing container %s in use", ctr.ID())
+			break
+		}
+	}
+
+	// All containers of a pod can be removed at once regardless of their
+	// dependencies on each other
+	require.NoError(t, inst.State.RemovePodContainers(pod))
+	members, err = inst.State.PodContainersByID(pod)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+	require.NoError(t, inst.State.RemovePod(pod))
+
+	ids, err := containerIDs(inst.State)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func testCrashRecovery(t *testing.T, backend Backend, inst *Instance) {
+	if inst.Reopen == nil {
+		t.Skip("state does not persist its contents")
+	}
+
+	stats, err := GenerateLoad(backend, inst.State, LoadConfig{
+		Workers:     4,
+		Containers:  8,
+		RemoveRatio: 0.25,
+	})
+	require.NoError(t, err)
+	graph, err := addDependencyGraph(backend, inst.State, nil, 16, 3, rand.New(rand.NewSource(3)))
+	require.NoError(t, err)
+	pod, err := backend.NewPod(stringid.GenerateNonCryptoID(), "recovery-pod")
+	require.NoError(t, err)
+	require.NoError(t, inst.State.AddPod(pod))
+	podCtrs, err := addDependencyGraph(backend, inst.State, pod, 8, 2, rand.New(rand.NewSource(4)))
+	require.NoError(t, err)
+
+	want, err := containerIDs(inst.State)
+	require.NoError(t, err)
+	assert.Len(t, want, len(stats.Remaining)+len(graph)+len(podCtrs))
+
+	state := reopen(t, inst)
+	defer state.Close()
+
+	got, err := containerIDs(state)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	require.NoError(t, CheckConsistency(state))
+
+	// Dependencies are still enforced by the reopened State
+	users := dependents(graph)
+	for _, ctr := range graph {
+		if len(users[ctr.ID()]) == 0 {
+			continue
+		}
+		recovered, err := state.Container(ctr.ID())
+		require.NoError(t, err)
+		err = state.RemoveContainer(recovered)
+		assert.Equal(t, define.ErrCtrExists, errors.Cause(err), "removing container %s in use", ctr.ID())
+	}
+
+	recoveredPod, err := state.LookupPod(pod.Name())
+	require.NoError(t, err)
+	members, err := state.PodContainersByID(recoveredPod)
+	require.NoError(t, err)
+	assert.Len(t, members, len(podCtrs))
+}
+
+func testCrashRecoveryAfterFailedOperations(t *testing.T, backend Backend, inst *Instance) {
+	if inst.Reopen == nil {
+		t.Skip("state does not persist its contents")
+	}
+
+	ctr, err := backend.NewContainer(stringid.GenerateNonCryptoID(), "existing", "", nil)
+	require.NoError(t, err)
+	require.NoError(t, inst.State.AddContainer(ctr))
+
+	// Adding a container with a missing dependency fails
+	missingDepCtr, err := backend.NewContainer(stringid.GenerateNonCryptoID(), "missing-dep", "", []string{stringid.GenerateNonCryptoID()})
+	require.NoError(t, err)
+	assert.Error(t, inst.State.AddContainer(missingDepCtr))
+
+	// Adding a container with a name in use fails
+	dupNameCtr, err := backend.NewContainer(stringid.GenerateNonCryptoID(), ctr.Name(), "", nil)
+	require.NoError(t, err)
+	assert.Error(t, inst.State.AddContainer(dupNameCtr))
+
+	// Adding a container to a missing pod fails
+	missingPod, err := backend.NewPod(stringid.GenerateNonCryptoID(), "missing-pod")
+	require.NoError(t, err)
+	missingPodCtr, err := backend.NewContainer(stringid.GenerateNonCryptoID(), "missing-pod-ctr", missingPod.ID(), nil)
+	require.NoError(t, err)
+	assert.Error(t, inst.State.AddContainerToPod(missingPod, missingPodCtr))
+
+	state := reopen(t, inst)
+	defer state.Close()
+
+	// None of the failed operations left anything behind
+	got, err := containerIDs(state)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ctr.ID()}, got)
+	for _, failed := range []*libpod.Container{missingDepCtr, dupNameCtr, missingPodCtr} {
+		exists, err := state.HasContainer(failed.ID())
+		require.NoError(t, err)
+		assert.False(t, exists, "container %s of failed operation exists", failed.ID())
+	}
+	exists, err := state.HasPod(missingPod.ID())
+	require.NoError(t, err)
+	assert.False(t, exists)
+	require.NoError(t, CheckConsistency(state))
+
+	// The names of the containers that could not be added can be used
+	reuseCtr, err := backend.NewContainer(stringid.GenerateNonCryptoID(), missingDepCtr.Name(), "", nil)
+	require.NoError(t, err)
+	assert.NoError(t, state.AddContainer(reuseCtr))
+}
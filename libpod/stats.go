@@ -3,8 +3,9 @@
 package libpod
 
 import (
-	"runtime"
+	goruntime "runtime"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -27,6 +28,13 @@ func (c *Container) GetContainerStats(previousStats *ContainerStats) (*Container
 		}
 	}
 
+	return c.getContainerStats(stats, previousStats)
+}
+
+// getContainerStats fills the stats of the container from its cgroup and
+// network namespace. The container's state must be current, but the container
+// does not need to be locked.
+func (c *Container) getContainerStats(stats *ContainerStats, previousStats *ContainerStats) (*ContainerStats, error) {
 	if c.state.State != define.ContainerStateRunning {
 		return stats, define.ErrCtrStateInvalid
 	}
@@ -63,7 +71,7 @@ func (c *Container) GetContainerStats(previousStats *ContainerStats) (*Container
 	}
 	stats.BlockInput, stats.BlockOutput = calculateBlockIO(cgroupStats)
 	stats.CPUNano = cgroupStats.CPU.Usage.Total
-	stats.SystemNano = cgroupStats.CPU.Usage.Kernel
+	stats.SystemNano = uint64(time.Now().UnixNano())
 	// Handle case where the container is not in a network namespace
 	if netStats != nil {
 		stats.NetInput = netStats.TxBytes
@@ -76,6 +84,137 @@ func (c *Container) GetContainerStats(previousStats *ContainerStats) (*Container
 	return stats, nil
 }
 
+// GetAllContainerStats returns the stats of every running container, and the
+// errors getting the stats of some of them keyed by container ID. The state of
+// each container is synced to find the running ones, then their cgroups are
+// read concurrently by up to parallelism workers, or one per CPU if
+// parallelism is not positive, without holding their locks.
+// The previous stats of each container, keyed by container ID, are used to
+// compute its CPU usage.
+func (r *Runtime) GetAllContainerStats(previousStats map[string]*ContainerStats, parallelism int) ([]*ContainerStats, map[string]error, error) {
+	r.lock.RLock()
+	if !r.valid {
+		r.lock.RUnlock()
+		return nil, nil, define.ErrRuntimeStopped
+	}
+	ctrs, err := r.state.AllContainers()
+	r.lock.RUnlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	// Containers retrieved from the state have only their configuration
+	errs := make(map[string]error)
+	var running []*Container
+	for _, ctr := range ctrs {
+		state, err := ctr.State()
+		if err != nil {
+			// The container may have been removed since it was
+			// retrieved
+			if cause := errors.Cause(err); cause != define.ErrCtrRemoved && cause != define.ErrNoSuchCtr {
+				errs[ctr.ID()] = err
+			}
+			continue
+		}
+		if state == define.ContainerStateRunning {
+			running = append(running, ctr)
+		}
+	}
+
+	if parallelism < 1 {
+		parallelism = goruntime.NumCPU()
+	}
+	if parallelism > len(running) {
+		parallelism = len(running)
+	}
+	allStats := make([]*ContainerStats, len(running))
+	ctrErrors := make([]error, len(running))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				ctr := running[index]
+				prevStats, ok := previousStats[ctr.ID()]
+				if !ok {
+					prevStats = &ContainerStats{}
+				}
+				stats := &ContainerStats{ContainerID: ctr.ID(), Name: ctr.Name()}
+				allStats[index], ctrErrors[index] = ctr.getContainerStats(stats, prevStats)
+			}
+		}()
+	}
+	for index := range running {
+		work <- index
+	}
+	close(work)
+	wg.Wait()
+
+	var results []*ContainerStats
+	for index, ctr := range running {
+		if ctrErrors[index] != nil {
+			// The container may have stopped since the database was read
+			if cause := errors.Cause(ctrErrors[index]); cause == cgroups.ErrCgroupDeleted {
+				ctrErrors[index] = errors.Wrapf(define.ErrCtrStateInvalid, "container %s is not running", ctr.ID())
+			}
+			errs[ctr.ID()] = ctrErrors[index]
+			continue
+		}
+		results = append(results, allStats[index])
+	}
+	return results, errs, nil
+}
+
+// GetPodStats returns the stats of the running containers of the pod, merged
+// and per container. Stopped containers are left out.
+func (p *Pod) GetPodStats(previousContainerStats map[string]*ContainerStats) (*PodStats, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := p.updatePod(); err != nil {
+		return nil, err
+	}
+	containers, err := p.runtime.state.PodContainers(p)
+	if err != nil {
+		return nil, err
+	}
+
+	podStats := &PodStats{
+		PodID:      p.ID(),
+		Name:       p.Name(),
+		Containers: make(map[string]*ContainerStats),
+	}
+	for _, c := range containers {
+		prevStat, ok := previousContainerStats[c.ID()]
+		if !ok {
+			prevStat = &ContainerStats{}
+		}
+		newStats, err := c.GetContainerStats(prevStat)
+		// If the container wasn't running, don't include it
+		// but also suppress the error
+		if err != nil && errors.Cause(err) != define.ErrCtrStateInvalid {
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+		// Containers joining another's network namespace report
+		// its traffic, which is already counted
+		podStats.AddContainerStats(newStats, c.config.NetNsCtr == "")
+	}
+	if len(podStats.Containers) == 0 {
+		return podStats, nil
+	}
+
+	memLimit := podStats.MemLimit
+	if p.config.ResourceLimits != nil && p.config.ResourceLimits.MemoryLimit > 0 {
+		memLimit = uint64(p.config.ResourceLimits.MemoryLimit)
+	}
+	podStats.SetMemLimit(getMemLimit(memLimit))
+	return podStats, nil
+}
+
 // getMemory limit returns the memory limit for a given cgroup
 // If the configured memory limit is larger than the total memory on the sys, the
 // physical system memory size is returned
@@ -94,6 +233,10 @@ func getMemLimit(cgroupLimit uint64) uint64 {
 	return cgroupLimit
 }
 
+// calculateCPUPercent returns the CPU time used since the previous stats as a
+// percentage of the time elapsed since them. A container using 4 CPUs fully
+// uses 400%. cgroups v2 does not account the usage per CPU, so the elapsed
+// time is used instead of the system CPU time.
 func calculateCPUPercent(stats *cgroups.Metrics, previousCPU, previousSystem uint64) float64 {
 	var (
 		cpuPercent  = 0.0
@@ -101,13 +244,7 @@ func calculateCPUPercent(stats *cgroups.Metrics, previousCPU, previousSystem uin
 		systemDelta = float64(uint64(time.Now().UnixNano()) - previousSystem)
 	)
 	if systemDelta > 0.0 && cpuDelta > 0.0 {
-		// gets a ratio of container cpu usage total, multiplies it by the number of cores (4 cores running
-		// at 100% utilization should be 400% utilization), and multiplies that by 100 to get a percentage
-		nCPUS := len(stats.CPU.Usage.PerCPU)
-		if nCPUS == 0 {
-			nCPUS = runtime.NumCPU()
-		}
-		cpuPercent = (cpuDelta / systemDelta) * float64(nCPUS) * 100
+		cpuPercent = (cpuDelta / systemDelta) * 100
 	}
 	return cpuPercent
 }
@@ -16,3 +16,58 @@ type ContainerStats struct {
 	BlockOutput uint64
 	PIDs        uint64
 }
+
+// PodStats contains the statistics of the running containers of a pod, both
+// merged and per container
+type PodStats struct {
+	PodID string
+	Name  string
+	// CPU is the sum of the CPU usage percentages of the containers.
+	CPU float64
+	// MemLimit is the memory limit of the pod if it has one, or else the
+	// sum of the limits of its containers, capped at the system memory.
+	MemUsage uint64
+	MemLimit uint64
+	MemPerc  float64
+	// NetInput and NetOutput count each network namespace of the pod
+	// once, however many containers share it.
+	NetInput    uint64
+	NetOutput   uint64
+	BlockInput  uint64
+	BlockOutput uint64
+	PIDs        uint64
+	// Containers holds the stats of each running container, by ID.
+	Containers map[string]*ContainerStats
+}
+
+// AddContainerStats merges the stats of a running container of the pod into
+// the pod's stats. Network traffic is only added if countNetwork is set, so
+// that a network namespace shared by several containers is counted once.
+// The memory limits of the containers are summed.
+func (s *PodStats) AddContainerStats(stats *ContainerStats, countNetwork bool) {
+	if s.Containers == nil {
+		s.Containers = make(map[string]*ContainerStats)
+	}
+	s.Containers[stats.ContainerID] = stats
+
+	s.CPU += stats.CPU
+	s.MemUsage += stats.MemUsage
+	s.MemLimit += stats.MemLimit
+	s.BlockInput += stats.BlockInput
+	s.BlockOutput += stats.BlockOutput
+	s.PIDs += stats.PIDs
+	if countNetwork {
+		s.NetInput += stats.NetInput
+		s.NetOutput += stats.NetOutput
+	}
+}
+
+// SetMemLimit sets the memory limit of the pod's stats, and the percentage of
+// it in use
+func (s *PodStats) SetMemLimit(limit uint64) {
+	s.MemLimit = limit
+	s.MemPerc = 0
+	if limit > 0 {
+		s.MemPerc = (float64(s.MemUsage) / float64(limit)) * 100
+	}
+}
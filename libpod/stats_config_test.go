@@ -0,0 +1,32 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodStatsAddContainerStats(t *testing.T) {
+	podStats := &PodStats{PodID: "pod"}
+	infra := &ContainerStats{ContainerID: "infra", CPU: 0.5, MemUsage: 100, MemLimit: 1000, NetInput: 10, NetOutput: 20, PIDs: 1}
+	app := &ContainerStats{ContainerID: "app", CPU: 25, MemUsage: 300, MemLimit: 1000, NetInput: 10, NetOutput: 20, BlockInput: 5, BlockOutput: 7, PIDs: 4}
+
+	podStats.AddContainerStats(infra, true)
+	podStats.AddContainerStats(app, false)
+	podStats.SetMemLimit(podStats.MemLimit)
+
+	assert.Len(t, podStats.Containers, 2)
+	assert.Equal(t, app, podStats.Containers["app"])
+	assert.Equal(t, 25.5, podStats.CPU)
+	assert.Equal(t, uint64(400), podStats.MemUsage)
+	assert.Equal(t, uint64(2000), podStats.MemLimit)
+	assert.Equal(t, 20.0, podStats.MemPerc)
+	assert.Equal(t, uint64(10), podStats.NetInput)
+	assert.Equal(t, uint64(20), podStats.NetOutput)
+	assert.Equal(t, uint64(5), podStats.BlockInput)
+	assert.Equal(t, uint64(7), podStats.BlockOutput)
+	assert.Equal(t, uint64(5), podStats.PIDs)
+
+	podStats.SetMemLimit(0)
+	assert.Equal(t, 0.0, podStats.MemPerc)
+}
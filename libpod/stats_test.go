@@ -0,0 +1,51 @@
+// +build linux
+
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAllContainerStats(t *testing.T) {
+	runForAllStates(t, func(t *testing.T, state State, manager lock.Manager) {
+		// Containers retrieved from a BoltState belong to its runtime
+		runtime := &Runtime{config: &RuntimeConfig{}}
+		if boltState, ok := state.(*BoltState); ok {
+			runtime = boltState.runtime
+		}
+		runc := probedRuntime("runc", ociRuntimeFeatures{})
+		runtime.state = state
+		runtime.defaultOCIRuntime = runc
+		runtime.ociRuntimes = map[string]OCIRuntime{"runc": runc}
+		runtime.valid = true
+
+		running, err := getTestCtr1(manager)
+		require.NoError(t, err)
+		stopped, err := getTestCtr2(manager)
+		require.NoError(t, err)
+		stopped.state.State = define.ContainerStateStopped
+		for _, ctr := range []*Container{running, stopped} {
+			ctr.runtime = runtime
+			ctr.ociRuntime = runc
+			require.NoError(t, state.AddContainer(ctr))
+		}
+
+		// Stopped containers are left out, and the error of the
+		// running container, whose cgroup cannot be found, is reported
+		// separately
+		stats, errs, err := runtime.GetAllContainerStats(nil, 4)
+		require.NoError(t, err)
+		assert.Empty(t, stats)
+		assert.Equal(t, 1, len(errs))
+		assert.Error(t, errs[running.ID()])
+
+		runtime.valid = false
+		_, _, err = runtime.GetAllContainerStats(nil, 4)
+		assert.Equal(t, define.ErrRuntimeStopped, err)
+	})
+}
@@ -8,3 +8,15 @@ import "github.com/containers/libpod/libpod/define"
 func (c *Container) GetContainerStats(previousStats *ContainerStats) (*ContainerStats, error) {
 	return nil, define.ErrOSNotSupported
 }
+
+// GetAllContainerStats returns the stats of every running container, and the
+// errors getting the stats of some of them
+func (r *Runtime) GetAllContainerStats(previousStats map[string]*ContainerStats, parallelism int) ([]*ContainerStats, map[string]error, error) {
+	return nil, nil, define.ErrOSNotSupported
+}
+
+// GetPodStats returns the stats of the running containers of the pod, merged
+// and per container
+func (p *Pod) GetPodStats(previousContainerStats map[string]*ContainerStats) (*PodStats, error) {
+	return nil, define.ErrOSNotSupported
+}
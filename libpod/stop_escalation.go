@@ -0,0 +1,120 @@
+package libpod
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// StopSignalStep is a step of a container's stop signal escalation chain: a
+// signal sent to the container, and how long to wait for the container to exit
+// before moving on to the next step.
+type StopSignalStep struct {
+	// Signal is the signal sent to the container
+	Signal uint `json:"signal"`
+	// Timeout is how long to wait for the container to exit after sending
+	// the signal. It is unused for SIGKILL, which is always the last step.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// String returns the step in the SIGNAL:TIMEOUT format used on the command line
+func (s StopSignalStep) String() string {
+	name := unix.SignalName(syscall.Signal(s.Signal))
+	if name == "" {
+		name = fmt.Sprintf("%d", s.Signal)
+	}
+	if s.Signal == uint(syscall.SIGKILL) {
+		return name
+	}
+	return fmt.Sprintf("%s:%s", name, s.Timeout)
+}
+
+// validateStopEscalation checks that a stop signal escalation chain is usable
+func validateStopEscalation(steps []StopSignalStep) error {
+	if len(steps) == 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "stop signal escalation chain must have at least one step")
+	}
+	for i, step := range steps {
+		if step.Signal == 0 || step.Signal > 64 {
+			return errors.Wrapf(define.ErrInvalidArg, "invalid signal %d in stop signal escalation chain", step.Signal)
+		}
+		if step.Signal == uint(syscall.SIGKILL) {
+			if i != len(steps)-1 {
+				return errors.Wrapf(define.ErrInvalidArg, "SIGKILL must be the last step of the stop signal escalation chain")
+			}
+			continue
+		}
+		if step.Timeout <= 0 {
+			return errors.Wrapf(define.ErrInvalidArg, "step %s of the stop signal escalation chain must have a timeout", step)
+		}
+	}
+	return nil
+}
+
+// stopSteps returns the signals to send, in order, to stop the container
+// within the given timeout (in seconds), not including the final SIGKILL.
+// A timeout of 0 means the container is killed immediately. Otherwise, the
+// container's stop signal escalation chain is used if it has one; if not, its
+// stop signal is sent and given the timeout.
+func (c *Container) stopSteps(timeout uint) []StopSignalStep {
+	if timeout == 0 {
+		return nil
+	}
+
+	if len(c.config.StopEscalation) > 0 {
+		steps := make([]StopSignalStep, 0, len(c.config.StopEscalation))
+		for _, step := range c.config.StopEscalation {
+			if step.Signal != uint(syscall.SIGKILL) {
+				steps = append(steps, step)
+			}
+		}
+		return steps
+	}
+
+	stopSignal := c.config.StopSignal
+	if stopSignal == 0 {
+		stopSignal = uint(syscall.SIGTERM)
+	}
+	return []StopSignalStep{{Signal: stopSignal, Timeout: time.Duration(timeout) * time.Second}}
+}
+
+// ValidateStopTimeout checks that the given timeout (in seconds), requested
+// explicitly by the user, can be used to stop the container. A container with
+// a stop signal escalation chain uses the chain's timeouts, and so only accepts
+// a timeout of 0, which kills it immediately.
+func (c *Container) ValidateStopTimeout(timeout uint) error {
+	if timeout != 0 && len(c.config.StopEscalation) > 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "container %s has a stop signal escalation chain (%s) and cannot be stopped with a timeout other than 0", c.ID(), formatStopEscalation(c.config.StopEscalation))
+	}
+	return nil
+}
+
+// recordStopStep records that the given step of stopping the container, which
+// starts at 1, was reached by sending the given signal.
+// Must be called with the container locked.
+func (c *Container) recordStopStep(step int, signal uint) {
+	logrus.Debugf("Stopping container %s: step %d, sending signal %d", c.ID(), step, signal)
+
+	c.state.StopStep = step
+	c.state.StopStepSignal = signal
+	c.state.StopStepTime = time.Now()
+	if err := c.save(); err != nil {
+		logrus.Errorf("Error saving stop progress of container %s: %v", c.ID(), err)
+	}
+}
+
+// formatStopEscalation formats a stop signal escalation chain in the format
+// used on the command line
+func formatStopEscalation(steps []StopSignalStep) string {
+	formatted := make([]string, 0, len(steps))
+	for _, step := range steps {
+		formatted = append(formatted, step.String())
+	}
+	return strings.Join(formatted, ",")
+}
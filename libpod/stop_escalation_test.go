@@ -0,0 +1,24 @@
+package libpod
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStopTimeout(t *testing.T) {
+	ctr := &Container{config: &ContainerConfig{ID: "test"}}
+	assert.NoError(t, ctr.ValidateStopTimeout(5))
+
+	ctr.config.StopEscalation = []StopSignalStep{
+		{Signal: uint(syscall.SIGTERM), Timeout: 10 * time.Second},
+		{Signal: uint(syscall.SIGKILL)},
+	}
+	assert.NoError(t, ctr.ValidateStopTimeout(0))
+	err := ctr.ValidateStopTimeout(5)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
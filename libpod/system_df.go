@@ -0,0 +1,347 @@
+package libpod
+
+import (
+	"context"
+	goruntime "runtime"
+	"sync"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/image"
+	"github.com/pkg/errors"
+)
+
+// containerDiskUsageMaxAge is how long the disk space used by the writable
+// layer of a container is reused once computed.
+const containerDiskUsageMaxAge = time.Minute
+
+// SystemDiskUsage is the disk space used by images, containers and volumes.
+type SystemDiskUsage struct {
+	// Images is the disk usage of each image, leaving out intermediate
+	// images.
+	Images []*ImageDiskUsage `json:"images"`
+	// Containers is the disk usage of each container.
+	Containers []*ContainerDiskUsage `json:"containers"`
+	// Volumes is the disk usage of each volume.
+	Volumes []*SystemVolumeDiskUsage `json:"volumes"`
+	// ImagesSummary sums up the disk usage of the images.
+	ImagesSummary DiskUsageSummary `json:"imagesSummary"`
+	// ContainersSummary sums up the disk usage of the containers.
+	ContainersSummary DiskUsageSummary `json:"containersSummary"`
+	// VolumesSummary sums up the disk usage of the volumes.
+	VolumesSummary DiskUsageSummary `json:"volumesSummary"`
+}
+
+// DiskUsageSummary sums up the disk space used by a type of objects.
+type DiskUsageSummary struct {
+	// Total is the number of objects.
+	Total int `json:"total"`
+	// Active is the number of objects in use.
+	Active int `json:"active"`
+	// Size is the disk space used by the objects, in bytes.
+	Size uint64 `json:"size"`
+	// Reclaimable is the disk space which removing the objects not in use
+	// would free, in bytes.
+	Reclaimable uint64 `json:"reclaimable"`
+}
+
+// ImageDiskUsage is the disk space used by an image.
+type ImageDiskUsage struct {
+	// ID is the ID of the image.
+	ID string `json:"id"`
+	// Names are the names of the image.
+	Names []string `json:"names"`
+	// Created is the time the image was created.
+	Created time.Time `json:"created"`
+	// Size is the size of all the layers of the image, in bytes.
+	Size uint64 `json:"size"`
+	// SharedSize is the size of the layers the image shares with its base
+	// image, in bytes.
+	SharedSize uint64 `json:"sharedSize"`
+	// UniqueSize is the size of the layers only used by the image, in
+	// bytes.
+	UniqueSize uint64 `json:"uniqueSize"`
+	// Containers is the number of containers using the image.
+	Containers int `json:"containers"`
+}
+
+// ContainerDiskUsage is the disk space used by a container.
+type ContainerDiskUsage struct {
+	// ID is the ID of the container.
+	ID string `json:"id"`
+	// Name is the name of the container.
+	Name string `json:"name"`
+	// ImageID is the ID of the image of the container.
+	ImageID string `json:"imageID"`
+	// Command is the command of the container.
+	Command []string `json:"command"`
+	// LocalVolumes is the number of volumes used by the container.
+	LocalVolumes int `json:"localVolumes"`
+	// Size is the size of the writable layer of the container, in bytes.
+	Size uint64 `json:"size"`
+	// Created is the time the container was created.
+	Created time.Time `json:"created"`
+	// State is the state of the container.
+	State define.ContainerStatus `json:"state"`
+	// ComputedAt is the time Size was computed at.
+	ComputedAt time.Time `json:"computedAt"`
+}
+
+// SystemVolumeDiskUsage is the disk space used by a volume, and how many
+// containers use it.
+type SystemVolumeDiskUsage struct {
+	*VolumeDiskUsage
+	// Containers is the number of containers using the volume.
+	Containers int `json:"containers"`
+}
+
+// active returns whether the container is running or paused, in which case
+// its writable layer cannot be reclaimed
+func (u *ContainerDiskUsage) active() bool {
+	return u.State == define.ContainerStateRunning || u.State == define.ContainerStatePaused
+}
+
+// DiskUsage returns the disk space used by the writable layer of the
+// container. Computing it requires walking the layer's contents, so the result
+// is kept in the container's state and reused for containerDiskUsageMaxAge.
+func (c *Container) DiskUsage() (*ContainerDiskUsage, error) {
+	if !c.valid {
+		return nil, define.ErrCtrRemoved
+	}
+
+	usage := &ContainerDiskUsage{
+		ID:           c.ID(),
+		Name:         c.Name(),
+		ImageID:      c.config.RootfsImageID,
+		Command:      c.Command(),
+		LocalVolumes: len(c.config.NamedVolumes),
+		Created:      c.CreatedTime(),
+	}
+
+	if !c.batched {
+		c.lock.Lock()
+		if err := c.syncContainer(); err != nil {
+			c.lock.Unlock()
+			return nil, err
+		}
+	}
+	usage.State = c.state.State
+	if !c.state.DiskUsageTime.IsZero() && time.Since(c.state.DiskUsageTime) < containerDiskUsageMaxAge {
+		usage.Size = c.state.DiskUsed
+		usage.ComputedAt = c.state.DiskUsageTime
+		if !c.batched {
+			c.lock.Unlock()
+		}
+		return usage, nil
+	}
+	if !c.batched {
+		// The container is not kept locked while its writable layer is
+		// walked, which can take a while
+		c.lock.Unlock()
+	}
+
+	usage.ComputedAt = time.Now()
+	size, err := c.rwSize()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error computing disk usage of container %s", c.ID())
+	}
+	usage.Size = uint64(size)
+
+	if !c.batched {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if err := c.syncContainer(); err != nil {
+			return nil, err
+		}
+	}
+	c.state.DiskUsed = usage.Size
+	c.state.DiskUsageTime = usage.ComputedAt
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// SystemDiskUsage returns the disk space used by every image, container and
+// volume, and how much of it removing the objects not in use would free.
+// Images are in use if a container uses them, containers if they are running
+// or paused, and volumes if a container uses them.
+// The writable layers of containers and the contents of volumes are walked
+// concurrently, and usage computed recently is reused, as described in
+// Container.DiskUsage and Volume.DiskUsage. Containers and volumes removed
+// meanwhile are left out.
+func (r *Runtime) SystemDiskUsage(ctx context.Context) (*SystemDiskUsage, error) {
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	ctrs, err := r.GetAllContainers()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving containers")
+	}
+
+	usage := new(SystemDiskUsage)
+	usage.Containers, err = containersDiskUsage(ctrs)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := r.VolumeDiskUsage()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error retrieving disk usage of volumes")
+	}
+
+	imageContainers := make(map[string]int)
+	volumeContainers := make(map[string]int)
+	for _, ctr := range ctrs {
+		imageContainers[ctr.config.RootfsImageID]++
+		for _, vol := range ctr.config.NamedVolumes {
+			volumeContainers[vol.Name]++
+		}
+	}
+	for _, vol := range volumes {
+		usage.Volumes = append(usage.Volumes, &SystemVolumeDiskUsage{
+			VolumeDiskUsage: vol,
+			Containers:      volumeContainers[vol.Name],
+		})
+	}
+
+	if r.imageRuntime != nil {
+		usage.Images, usage.ImagesSummary, err = imagesDiskUsage(ctx, r.imageRuntime, imageContainers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ctr := range usage.Containers {
+		usage.ContainersSummary.Total++
+		usage.ContainersSummary.Size += ctr.Size
+		if ctr.active() {
+			usage.ContainersSummary.Active++
+		} else {
+			usage.ContainersSummary.Reclaimable += ctr.Size
+		}
+	}
+	for _, vol := range usage.Volumes {
+		usage.VolumesSummary.Total++
+		usage.VolumesSummary.Size += vol.Used
+		if vol.Containers > 0 {
+			usage.VolumesSummary.Active++
+		} else {
+			usage.VolumesSummary.Reclaimable += vol.Used
+		}
+	}
+	return usage, nil
+}
+
+// containersDiskUsage returns the disk usage of the given containers, in the
+// same order, walking the writable layers of several containers at once
+func containersDiskUsage(ctrs []*Container) ([]*ContainerDiskUsage, error) {
+	usages := make([]*ContainerDiskUsage, len(ctrs))
+	errs := make([]error, len(ctrs))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < goruntime.NumCPU() && i < len(ctrs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range work {
+				usages[index], errs[index] = ctrs[index].DiskUsage()
+			}
+		}()
+	}
+	for index := range ctrs {
+		work <- index
+	}
+	close(work)
+	wg.Wait()
+
+	result := make([]*ContainerDiskUsage, 0, len(ctrs))
+	for index, err := range errs {
+		if err != nil {
+			if errors.Cause(err) == define.ErrCtrRemoved || errors.Cause(err) == define.ErrNoSuchCtr {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, usages[index])
+	}
+	return result, nil
+}
+
+// imagesDiskUsage returns the disk usage of the images, leaving out
+// intermediate images, and sums it up. The layers an image shares with its
+// base image are only counted once, as part of the base image. Removing the
+// images no container uses would free their unique size.
+func imagesDiskUsage(ctx context.Context, imageRuntime *image.Runtime, imageContainers map[string]int) ([]*ImageDiskUsage, DiskUsageSummary, error) {
+	var summary DiskUsageSummary
+	images, err := imageRuntime.GetImages()
+	if err != nil {
+		return nil, summary, errors.Wrapf(err, "error retrieving images")
+	}
+
+	var usages []*ImageDiskUsage
+	for _, img := range images {
+		isParent, err := img.IsParent(ctx)
+		if err != nil {
+			return nil, summary, errors.Wrapf(err, "error checking if image %s is a parent image", img.ID())
+		}
+		parent, err := img.GetParent(ctx)
+		if err != nil {
+			return nil, summary, errors.Wrapf(err, "error getting parent of image %s", img.ID())
+		}
+		if isParent && parent != nil {
+			continue
+		}
+
+		size, err := img.Size(ctx)
+		if err != nil {
+			return nil, summary, errors.Wrapf(err, "error getting size of image %s", img.ID())
+		}
+		usage := &ImageDiskUsage{
+			ID:         img.ID(),
+			Names:      img.Names(),
+			Created:    img.Created(),
+			Size:       *size,
+			UniqueSize: *size,
+			Containers: imageContainers[img.ID()],
+		}
+		if parent != nil {
+			base := parent
+			for {
+				next, err := base.GetParent(ctx)
+				if err != nil {
+					return nil, summary, errors.Wrapf(err, "error getting parent of image %s", base.ID())
+				}
+				if next == nil {
+					break
+				}
+				base = next
+			}
+			baseSize, err := base.Size(ctx)
+			if err != nil {
+				return nil, summary, errors.Wrapf(err, "error getting size of image %s", base.ID())
+			}
+			if *baseSize < *size {
+				usage.SharedSize = *baseSize
+				usage.UniqueSize = *size - *baseSize
+			}
+		}
+		usages = append(usages, usage)
+
+		summary.Total++
+		if !isParent {
+			summary.Size += usage.UniqueSize
+		} else {
+			summary.Size += usage.Size
+		}
+		if usage.Containers > 0 {
+			summary.Active++
+		} else {
+			summary.Reclaimable += usage.UniqueSize
+		}
+	}
+	if summary.Reclaimable > summary.Size {
+		summary.Reclaimable = summary.Size
+	}
+	return usages, summary, nil
+}
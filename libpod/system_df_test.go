@@ -0,0 +1,63 @@
+package libpod
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemDiskUsage(t *testing.T) {
+	state, path, manager, err := getEmptyInMemoryState()
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	runtime := &Runtime{state: state, config: &RuntimeConfig{}, valid: true}
+	for i, n := range []string{"1", "2"} {
+		ctr, err := getTestCtrN(n, manager)
+		require.NoError(t, err)
+		ctr.config.Rootfs = filepath.Join(path, "rootfs"+n)
+		require.NoError(t, os.MkdirAll(ctr.config.Rootfs, 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(ctr.config.Rootfs, "file"), make([]byte, 100*(i+1)), 0644))
+		ctr.state.State = define.ContainerStateExited
+		ctr.runtime = runtime
+		require.NoError(t, state.AddContainer(ctr))
+	}
+
+	usage, err := runtime.SystemDiskUsage(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(usage.Containers))
+	assert.Empty(t, usage.Volumes)
+	assert.Equal(t, 2, usage.ContainersSummary.Total)
+	assert.Equal(t, 0, usage.ContainersSummary.Active)
+	assert.Equal(t, usage.ContainersSummary.Size, usage.ContainersSummary.Reclaimable)
+	for _, ctrUsage := range usage.Containers {
+		assert.False(t, ctrUsage.ComputedAt.IsZero())
+		assert.Equal(t, define.ContainerStateExited, ctrUsage.State)
+	}
+
+	// The size of the writable layer is reused until it gets too old
+	ctr, err := state.LookupContainer("test1")
+	require.NoError(t, err)
+	before, err := ctr.DiskUsage()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(ctr.config.Rootfs, "other"), make([]byte, 1000), 0644))
+	cached, err := ctr.DiskUsage()
+	require.NoError(t, err)
+	assert.Equal(t, before.Size, cached.Size)
+	assert.Equal(t, before.ComputedAt, cached.ComputedAt)
+
+	ctr.state.DiskUsageTime = ctr.state.DiskUsageTime.Add(-containerDiskUsageMaxAge)
+	recomputed, err := ctr.DiskUsage()
+	require.NoError(t, err)
+	assert.True(t, recomputed.Size > before.Size)
+
+	runtime.valid = false
+	_, err = runtime.SystemDiskUsage(context.Background())
+	assert.Equal(t, define.ErrRuntimeStopped, err)
+}
@@ -0,0 +1,216 @@
+package libpod
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/systemdgen"
+	"github.com/pkg/errors"
+)
+
+// SystemdUnitOptions are the options of the systemd units generated for
+// containers and pods
+type SystemdUnitOptions struct {
+	// UseName refers to containers and pods by name instead of ID, in the
+	// units and their names
+	UseName bool
+	// New generates units creating a new container when started, and
+	// removing it when stopped, instead of starting an existing container.
+	// Requires the container to be created by the podman CLI.
+	New bool
+	// RestartPolicy is the systemd restart policy of the units. Mapped from
+	// the restart policy of the container if empty.
+	RestartPolicy string
+	// StopTimeout overrides the stop timeout of the containers if set
+	StopTimeout *uint
+	// Timestamp adds the time the units were generated at to their header
+	Timestamp bool
+}
+
+// SystemdUnit is a systemd service unit running a container
+type SystemdUnit struct {
+	// Name of the unit, e.g. container-web.service
+	Name string
+	// ContainerID is the ID of the container run by the unit
+	ContainerID string
+	// BindsTo are the units the unit is bound to, and started after
+	BindsTo []string
+	// Wants are the units the unit wants, and is started before
+	Wants []string
+	// Content of the unit file
+	Content string
+}
+
+// GenerateSystemdUnit generates a systemd unit running the container
+func (r *Runtime) GenerateSystemdUnit(ctr *Container, options SystemdUnitOptions) (*SystemdUnit, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	info, err := systemdContainerInfo(ctr, nil, options)
+	if err != nil {
+		return nil, err
+	}
+	return renderSystemdUnit(ctr, info)
+}
+
+// GeneratePodSystemdUnits generates the systemd units of a pod. The unit of
+// the infra container is the unit of the pod, and comes first. It wants the
+// units of the other containers, which are bound to it and to the units of
+// the containers they depend on.
+func (r *Runtime) GeneratePodSystemdUnits(pod *Pod, options SystemdUnitOptions) ([]*SystemdUnit, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if !r.valid {
+		return nil, define.ErrRuntimeStopped
+	}
+
+	if options.New {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "units creating new containers cannot be generated for pod %s", pod.ID())
+	}
+	if !pod.HasInfraContainer() {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "pod %s has no infra container to run as the unit of the pod", pod.ID())
+	}
+	infraID, err := pod.InfraContainerID()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := pod.AllContainers()
+	if err != nil {
+		return nil, err
+	}
+	graph, err := BuildContainerGraph(containers)
+	if err != nil {
+		return nil, err
+	}
+
+	var infra *Container
+	infos := make(map[string]*systemdgen.ContainerInfo, len(containers))
+	for _, ctr := range containers {
+		info, err := systemdContainerInfo(ctr, pod, options)
+		if err != nil {
+			return nil, err
+		}
+		infos[ctr.ID()] = info
+		if ctr.ID() == infraID {
+			infra = ctr
+		}
+	}
+	if infra == nil {
+		return nil, errors.Wrapf(define.ErrNoSuchCtr, "infra container %s of pod %s", infraID, pod.ID())
+	}
+
+	podInfo := infos[infraID]
+	var ctrs []*Container
+	for ctr, dependencies := range graph.DependencyMap() {
+		if ctr.ID() == infraID {
+			continue
+		}
+		info := infos[ctr.ID()]
+		for _, dep := range dependencies {
+			info.BoundToServices = append(info.BoundToServices, infos[dep.ID()].ServiceName)
+		}
+		if len(info.BoundToServices) == 0 {
+			info.BoundToServices = append(info.BoundToServices, podInfo.ServiceName)
+		}
+		podInfo.WantedServices = append(podInfo.WantedServices, info.ServiceName)
+		ctrs = append(ctrs, ctr)
+	}
+	sort.Slice(ctrs, func(i, j int) bool {
+		return infos[ctrs[i].ID()].ServiceName < infos[ctrs[j].ID()].ServiceName
+	})
+
+	units := make([]*SystemdUnit, 0, len(containers))
+	for _, ctr := range append([]*Container{infra}, ctrs...) {
+		unit, err := renderSystemdUnit(ctr, infos[ctr.ID()])
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// systemdContainerInfo returns the description of the unit of a container,
+// named after the pod if it is the infra container of the pod
+func systemdContainerInfo(ctr *Container, pod *Pod, options SystemdUnitOptions) (*systemdgen.ContainerInfo, error) {
+	kind, name := "container", ctr.ID()
+	if options.UseName {
+		name = ctr.Name()
+	}
+	if pod != nil && ctr.IsInfra() {
+		kind, name = "pod", pod.ID()
+		if options.UseName {
+			name = pod.Name()
+		}
+	}
+
+	info := &systemdgen.ContainerInfo{
+		ServiceName:       fmt.Sprintf("%s-%s", kind, name),
+		ContainerName:     ctr.ID(),
+		RestartPolicy:     options.RestartPolicy,
+		StopTimeout:       int(ctr.StopTimeout()),
+		PIDFile:           ctr.config.ConmonPidFile,
+		GenerateTimestamp: options.Timestamp,
+		New:               options.New,
+		CreateCommand:     ctr.CreateCommand(),
+	}
+	if options.UseName || options.New {
+		// New containers get a new ID, so they are always referred to by name
+		info.ContainerName = ctr.Name()
+	}
+	if options.StopTimeout != nil {
+		info.StopTimeout = int(*options.StopTimeout)
+	}
+	if info.RestartPolicy == "" {
+		info.RestartPolicy = systemdRestartPolicy(ctr.RestartPolicy())
+	}
+
+	if options.New {
+		if len(info.CreateCommand) == 0 {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "container %s was not created by the podman CLI and cannot be recreated", ctr.ID())
+		}
+	} else if info.PIDFile == "" {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "container %s has no conmon PID file, recreate it with --conmon-pidfile", ctr.ID())
+	}
+	return info, nil
+}
+
+// systemdRestartPolicy maps the restart policy of a container to the restart
+// policy of its unit. Containers without a restart policy are restarted on
+// failure.
+func systemdRestartPolicy(policy string) string {
+	switch policy {
+	case RestartPolicyAlways, RestartPolicyUnlessStopped:
+		return "always"
+	case RestartPolicyNo:
+		return "no"
+	default:
+		return "on-failure"
+	}
+}
+
+// renderSystemdUnit renders the unit of the container
+func renderSystemdUnit(ctr *Container, info *systemdgen.ContainerInfo) (*SystemdUnit, error) {
+	content, err := systemdgen.CreateContainerSystemdUnit(info, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error generating systemd unit of container %s", ctr.ID())
+	}
+	unit := &SystemdUnit{
+		Name:        info.ServiceName + ".service",
+		ContainerID: ctr.ID(),
+		Content:     content,
+	}
+	for _, service := range info.BoundToServices {
+		unit.BindsTo = append(unit.BindsTo, service+".service")
+	}
+	for _, service := range info.WantedServices {
+		unit.Wants = append(unit.Wants, service+".service")
+	}
+	return unit, nil
+}
@@ -0,0 +1,19 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemdRestartPolicy(t *testing.T) {
+	for policy, expected := range map[string]string{
+		RestartPolicyNone:          "on-failure",
+		RestartPolicyNo:            "no",
+		RestartPolicyOnFailure:     "on-failure",
+		RestartPolicyAlways:        "always",
+		RestartPolicyUnlessStopped: "always",
+	} {
+		assert.Equal(t, expected, systemdRestartPolicy(policy), policy)
+	}
+}
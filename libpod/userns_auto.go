@@ -0,0 +1,112 @@
+package libpod
+
+import (
+	"sort"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/rootless"
+	"github.com/containers/storage"
+	"github.com/containers/storage/pkg/idtools"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// AutoUserNSDefaultSize is the number of UIDs and GIDs allocated to a
+// container created with --userns=auto when no size is requested.
+const AutoUserNSDefaultSize = 65536
+
+// AutoUserNSRange is the range of host IDs allocated to a container created
+// with --userns=auto. The IDs 0 to Size-1 of the container are mapped to the
+// Size host UIDs starting at HostUID, and GIDs starting at HostGID.
+type AutoUserNSRange struct {
+	// HostUID is the first host UID of the range.
+	HostUID int `json:"hostUID"`
+	// HostGID is the first host GID of the range.
+	HostGID int `json:"hostGID"`
+	// Size is the number of IDs in the range.
+	Size int `json:"size"`
+}
+
+// idMappings returns the ID mappings of a container using the range
+func (r *AutoUserNSRange) idMappings() storage.IDMappingOptions {
+	return storage.IDMappingOptions{
+		UIDMap: []idtools.IDMap{{ContainerID: 0, HostID: r.HostUID, Size: r.Size}},
+		GIDMap: []idtools.IDMap{{ContainerID: 0, HostID: r.HostGID, Size: r.Size}},
+	}
+}
+
+// allocateAutoUserNSRange allocates a range of size UIDs and GIDs from the
+// pools, overlapping none of the ranges already allocated
+func allocateAutoUserNSRange(size int, uidPool, gidPool []idtools.IDMap, allocated []AutoUserNSRange) (*AutoUserNSRange, error) {
+	if size <= 0 {
+		return nil, errors.Wrapf(define.ErrInvalidArg, "user namespace size must be positive, not %d", size)
+	}
+	usedUIDs := make([]idtools.IDMap, 0, len(allocated))
+	usedGIDs := make([]idtools.IDMap, 0, len(allocated))
+	for _, r := range allocated {
+		usedUIDs = append(usedUIDs, idtools.IDMap{HostID: r.HostUID, Size: r.Size})
+		usedGIDs = append(usedGIDs, idtools.IDMap{HostID: r.HostGID, Size: r.Size})
+	}
+
+	hostUID, err := allocateIDRange(size, uidPool, usedUIDs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error allocating %d UIDs", size)
+	}
+	hostGID, err := allocateIDRange(size, gidPool, usedGIDs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error allocating %d GIDs", size)
+	}
+	return &AutoUserNSRange{HostUID: hostUID, HostGID: hostGID, Size: size}, nil
+}
+
+// allocateIDRange returns the lowest host ID starting a range of size IDs
+// that lies within one of the ranges of the pool, and overlaps none of the
+// used ranges
+func allocateIDRange(size int, pool, used []idtools.IDMap) (int, error) {
+	pool = append([]idtools.IDMap(nil), pool...)
+	sort.Slice(pool, func(i, j int) bool { return pool[i].HostID < pool[j].HostID })
+	used = append([]idtools.IDMap(nil), used...)
+	sort.Slice(used, func(i, j int) bool { return used[i].HostID < used[j].HostID })
+
+	for _, r := range pool {
+		start := r.HostID
+		// As used ranges are sorted, those overlapping the candidate
+		// range can be skipped over in a single pass
+		for _, u := range used {
+			if u.HostID < start+size && u.HostID+u.Size > start {
+				start = u.HostID + u.Size
+			}
+		}
+		if start+size <= r.HostID+r.Size {
+			return start, nil
+		}
+	}
+	return 0, errors.Wrapf(define.ErrUserNSExhausted, "no range of %d free IDs", size)
+}
+
+// allocateAutoUserNS allocates the ID mappings of a container created with
+// --userns=auto from the subordinate IDs of the configured user
+func (r *Runtime) allocateAutoUserNS(ctr *Container) error {
+	if rootless.IsRootless() {
+		return errors.Wrapf(define.ErrNotImplemented, "automatic user namespaces are not supported for rootless users")
+	}
+	if len(ctr.config.IDMappings.UIDMap) > 0 || len(ctr.config.IDMappings.GIDMap) > 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "cannot set ID mappings for a container with an automatic user namespace")
+	}
+	if ctr.config.UserNsCtr != "" {
+		return errors.Wrapf(define.ErrInvalidArg, "cannot join the user namespace of another container with an automatic user namespace")
+	}
+
+	mappings, err := idtools.NewIDMappings(r.config.AutoUserNSUser, r.config.AutoUserNSUser)
+	if err != nil {
+		return errors.Wrapf(err, "error reading subordinate IDs of user %s", r.config.AutoUserNSUser)
+	}
+	allocated, err := r.state.AllocateUserNSRange(ctr.ID(), ctr.config.AutoUserNSSize, mappings.UIDs(), mappings.GIDs())
+	if err != nil {
+		return errors.Wrapf(err, "error allocating user namespace for container %s", ctr.ID())
+	}
+	logrus.Debugf("Allocated UIDs %d and GIDs %d, %d of each, to container %s", allocated.HostUID, allocated.HostGID, allocated.Size, ctr.ID())
+
+	ctr.config.IDMappings = allocated.idMappings()
+	return nil
+}
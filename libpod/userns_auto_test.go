@@ -0,0 +1,65 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/pkg/idtools"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllocateIDRange(t *testing.T) {
+	pool := []idtools.IDMap{
+		{HostID: 300000, Size: 20000},
+		{HostID: 100000, Size: 65536},
+	}
+
+	start, err := allocateIDRange(1000, pool, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 100000, start)
+
+	used := []idtools.IDMap{
+		{HostID: 101000, Size: 500},
+		{HostID: 100000, Size: 1000},
+	}
+	start, err = allocateIDRange(1000, pool, used)
+	assert.NoError(t, err)
+	assert.Equal(t, 101500, start)
+
+	// A gap too small for the range is skipped
+	used = []idtools.IDMap{
+		{HostID: 100000, Size: 1000},
+		{HostID: 101500, Size: 1000},
+	}
+	start, err = allocateIDRange(1000, pool, used)
+	assert.NoError(t, err)
+	assert.Equal(t, 102500, start)
+
+	// Ranges do not span the ranges of the pool
+	_, err = allocateIDRange(65000, pool, used)
+	assert.Error(t, err)
+	used = append(used, idtools.IDMap{HostID: 102500, Size: 50000})
+	start, err = allocateIDRange(20000, pool, used)
+	assert.NoError(t, err)
+	assert.Equal(t, 300000, start)
+
+	_, err = allocateIDRange(30000, pool, []idtools.IDMap{{HostID: 100000, Size: 65536}})
+	assert.Equal(t, define.ErrUserNSExhausted, errors.Cause(err))
+}
+
+func TestAllocateAutoUserNSRange(t *testing.T) {
+	uidPool := []idtools.IDMap{{HostID: 100000, Size: 65536}}
+	gidPool := []idtools.IDMap{{HostID: 200000, Size: 65536}}
+
+	allocated, err := allocateAutoUserNSRange(1024, uidPool, gidPool, []AutoUserNSRange{{HostUID: 100000, HostGID: 200000, Size: 1024}})
+	assert.NoError(t, err)
+	assert.Equal(t, AutoUserNSRange{HostUID: 101024, HostGID: 201024, Size: 1024}, *allocated)
+
+	mappings := allocated.idMappings()
+	assert.Equal(t, []idtools.IDMap{{ContainerID: 0, HostID: 101024, Size: 1024}}, mappings.UIDMap)
+	assert.Equal(t, []idtools.IDMap{{ContainerID: 0, HostID: 201024, Size: 1024}}, mappings.GIDMap)
+
+	_, err = allocateAutoUserNSRange(0, uidPool, gidPool, nil)
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(err))
+}
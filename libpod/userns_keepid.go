@@ -0,0 +1,50 @@
+package libpod
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+)
+
+// KeepIDUser is the user a container created with --userns=keep-id runs as.
+// The UID and GID are those of the invoking user on the host, and are mapped
+// to the same IDs inside the container.
+type KeepIDUser struct {
+	// UID is the UID of the user, on the host and in the container.
+	UID uint32 `json:"uid"`
+	// GID is the primary GID of the user, on the host and in the
+	// container.
+	GID uint32 `json:"gid"`
+	// Username is the name of the user.
+	Username string `json:"username"`
+	// HomeDir is the home directory of the user in the container. It is
+	// created if it does not exist in the image.
+	HomeDir string `json:"homeDir"`
+	// Shell is the login shell of the user in the container.
+	Shell string `json:"shell,omitempty"`
+}
+
+// validate checks that the user can be added to the container's passwd file
+func (u *KeepIDUser) validate() error {
+	if u.Username == "" {
+		return errors.Wrapf(define.ErrInvalidArg, "must provide a username for keep-id")
+	}
+	if !filepath.IsAbs(u.HomeDir) {
+		return errors.Wrapf(define.ErrInvalidArg, "home directory %q of keep-id user must be absolute", u.HomeDir)
+	}
+	if u.Shell != "" && !filepath.IsAbs(u.Shell) {
+		return errors.Wrapf(define.ErrInvalidArg, "shell %q of keep-id user must be absolute", u.Shell)
+	}
+	return nil
+}
+
+// passwdEntry returns the /etc/passwd line for the user
+func (u *KeepIDUser) passwdEntry() string {
+	shell := u.Shell
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return fmt.Sprintf("%s:x:%d:%d:%s:%s:%s\n", u.Username, u.UID, u.GID, u.Username, u.HomeDir, shell)
+}
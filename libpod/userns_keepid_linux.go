@@ -0,0 +1,88 @@
+// +build linux
+
+package libpod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/containers/libpod/pkg/lookup"
+	"github.com/containers/storage/pkg/idtools"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/opencontainers/runc/libcontainer/user"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// generateKeepIDPasswd generates a passwd file for a keep-id container,
+// adding an entry for the invoking user if the image does not have one.
+func (c *Container) generateKeepIDPasswd() (string, error) {
+	keepID := c.config.KeepID
+	_, err := lookup.GetUser(c.state.Mountpoint, strconv.FormatUint(uint64(keepID.UID), 10))
+	if err != nil && err != user.ErrNoPasswdEntries {
+		return "", err
+	}
+	if err == nil {
+		return "", nil
+	}
+
+	originPasswdFile := filepath.Join(c.state.Mountpoint, "/etc/passwd")
+	orig, err := ioutil.ReadFile(originPasswdFile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", errors.Wrapf(err, "unable to read passwd file %s", originPasswdFile)
+	}
+	if len(orig) > 0 && orig[len(orig)-1] != '\n' {
+		orig = append(orig, '\n')
+	}
+
+	passwdFile, err := c.writeStringToRundir("passwd", string(orig)+keepID.passwdEntry())
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create temporary passwd file")
+	}
+	if err := os.Chmod(passwdFile, 0644); err != nil {
+		return "", err
+	}
+	return passwdFile, nil
+}
+
+// prepareKeepIDDirs creates the home directory of the keep-id user in the
+// container's root filesystem if it is missing, and gives the user ownership
+// of it and of the container's working directory.
+func (c *Container) prepareKeepIDDirs() error {
+	keepID := c.config.KeepID
+
+	mappings := idtools.NewIDMappingsFromMaps(c.config.IDMappings.UIDMap, c.config.IDMappings.GIDMap)
+	owner, err := mappings.ToHost(idtools.IDPair{UID: int(keepID.UID), GID: int(keepID.GID)})
+	if err != nil {
+		return errors.Wrapf(err, "error mapping keep-id user of container %s to the host", c.ID())
+	}
+
+	dirs := []string{keepID.HomeDir}
+	if workDir := c.WorkingDir(); workDir != "/" && workDir != keepID.HomeDir {
+		dirs = append(dirs, workDir)
+	}
+	for _, dir := range dirs {
+		path, err := securejoin.SecureJoin(c.state.Mountpoint, dir)
+		if err != nil {
+			return errors.Wrapf(err, "error resolving %s in container %s", dir, c.ID())
+		}
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			logrus.Debugf("Creating %s for keep-id user of container %s", dir, c.ID())
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return errors.Wrapf(err, "error creating %s in container %s", dir, c.ID())
+			}
+		case err != nil:
+			return errors.Wrapf(err, "error checking %s in container %s", dir, c.ID())
+		case !info.IsDir():
+			return errors.Errorf("%s in container %s is not a directory", dir, c.ID())
+		}
+		if err := os.Lchown(path, owner.UID, owner.GID); err != nil {
+			return errors.Wrapf(err, "error changing owner of %s in container %s", dir, c.ID())
+		}
+	}
+	return nil
+}
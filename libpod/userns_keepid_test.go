@@ -0,0 +1,31 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeepIDUserValidate(t *testing.T) {
+	u := KeepIDUser{UID: 1000, GID: 1000, Username: "jdoe", HomeDir: "/home/jdoe"}
+	assert.NoError(t, u.validate())
+
+	for _, bad := range []KeepIDUser{
+		{UID: 1000, GID: 1000, HomeDir: "/home/jdoe"},
+		{UID: 1000, GID: 1000, Username: "jdoe", HomeDir: "home/jdoe"},
+		{UID: 1000, GID: 1000, Username: "jdoe", HomeDir: "/home/jdoe", Shell: "bash"},
+	} {
+		err := bad.validate()
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(err), "%+v", bad)
+	}
+}
+
+func TestKeepIDUserPasswdEntry(t *testing.T) {
+	u := KeepIDUser{UID: 1000, GID: 100, Username: "jdoe", HomeDir: "/home/jdoe"}
+	assert.Equal(t, "jdoe:x:1000:100:jdoe:/home/jdoe:/bin/sh\n", u.passwdEntry())
+
+	u.Shell = "/bin/bash"
+	assert.Equal(t, "jdoe:x:1000:100:jdoe:/home/jdoe:/bin/bash\n", u.passwdEntry())
+}
@@ -2,17 +2,21 @@ package libpod
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/lock"
 	"github.com/fsnotify/fsnotify"
 	spec "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // Runtime API constants
@@ -152,3 +156,84 @@ func JSONDeepCopy(from, to interface{}) error {
 	}
 	return json.Unmarshal(tmp, to)
 }
+
+// proxyEnvVars are the host environment variables used to configure HTTP,
+// HTTPS, and FTP proxies.
+var proxyEnvVars = []string{
+	"http_proxy",
+	"HTTP_PROXY",
+	"https_proxy",
+	"HTTPS_PROXY",
+	"ftp_proxy",
+	"FTP_PROXY",
+	"no_proxy",
+	"NO_PROXY",
+}
+
+var (
+	hostProxyEnvOnce sync.Once
+	hostProxyEnvVals map[string]string
+)
+
+// hostProxyEnv returns the proxy environment variables set on the host.
+// They are read once, the first time this is called, so they remain available
+// for containers after unsetHostProxyEnv removes them from our environment.
+func hostProxyEnv() map[string]string {
+	hostProxyEnvOnce.Do(func() {
+		hostProxyEnvVals = make(map[string]string)
+		for _, key := range proxyEnvVars {
+			if val := os.Getenv(key); val != "" {
+				hostProxyEnvVals[key] = val
+			}
+		}
+	})
+	return hostProxyEnvVals
+}
+
+// unsetHostProxyEnv removes the proxy environment variables from the
+// environment of this process, preventing image pulls from using them.
+func unsetHostProxyEnv() {
+	// Make sure the values are saved for containers that request them
+	hostProxyEnv()
+	for _, key := range proxyEnvVars {
+		if err := os.Unsetenv(key); err != nil {
+			logrus.Warnf("Error unsetting environment variable %s: %v", key, err)
+		}
+	}
+}
+
+// Lock levels of pods and containers. A pod's lock must be acquired before the
+// locks of its containers.
+const (
+	podLockLevel uint = iota + 1
+	ctrLockLevel
+)
+
+// podLocker wraps the lock of the pod with the given ID to enforce the lock
+// ordering.
+func podLocker(locker lock.Locker, id string) lock.Locker {
+	return lock.NewOrderedLocker(locker, podLockLevel, fmt.Sprintf("pod %s", id))
+}
+
+// ctrLocker wraps the lock of the container with the given ID to enforce the
+// lock ordering.
+func ctrLocker(locker lock.Locker, id string) lock.Locker {
+	return lock.NewOrderedLocker(locker, ctrLockLevel, fmt.Sprintf("container %s", id))
+}
+
+// isDirEmpty returns whether the directory at the given path has no entries.
+func isDirEmpty(path string) (bool, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer dir.Close()
+
+	if _, err := dir.Readdirnames(1); err != nil {
+		if err == io.EOF {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
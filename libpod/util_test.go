@@ -2,6 +2,9 @@ package libpod
 
 import (
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -14,3 +17,21 @@ func TestRemoveScientificNotationFromFloat(t *testing.T) {
 		assert.Equal(t, result, results[i])
 	}
 }
+
+func TestIsDirEmpty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "libpod-util-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	empty, err := isDirEmpty(dir)
+	assert.NoError(t, err)
+	assert.True(t, empty)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte("data"), 0644))
+	empty, err = isDirEmpty(dir)
+	assert.NoError(t, err)
+	assert.False(t, empty)
+
+	_, err = isDirEmpty(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
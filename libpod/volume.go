@@ -3,13 +3,16 @@ package libpod
 import (
 	"time"
 
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/libpod/lock"
+	"github.com/sirupsen/logrus"
 )
 
 // Volume is the type used to create named volumes
 // TODO: all volumes should be created using this and the Volume API
 type Volume struct {
 	config *VolumeConfig
+	state  *VolumeState
 
 	valid   bool
 	runtime *Runtime
@@ -25,7 +28,8 @@ type VolumeConfig struct {
 	// Labels for the volume.
 	Labels map[string]string `json:"labels"`
 	// The volume driver. Empty string or local does not activate a volume
-	// driver, all other volumes will.
+	// driver, all other volumes will. Any other driver is the name of the
+	// volume plugin managing the volume.
 	Driver string `json:"driver"`
 	// The location the volume is mounted at.
 	MountPoint string `json:"mountPoint"`
@@ -42,6 +46,49 @@ type VolumeConfig struct {
 	UID int `json:"uid"`
 	// GID the volume will be created as.
 	GID int `json:"gid"`
+	// Size is the size in bytes the volume is limited to, set by the size
+	// option of the local driver. 0 if the size is not limited.
+	Size uint64 `json:"size,omitempty"`
+	// MountType is the type of the filesystem mounted as the volume by the
+	// local driver, set by the type option. Empty if the volume is a plain
+	// directory.
+	MountType string `json:"mountType,omitempty"`
+	// MountDevice is the device mounted as the volume by the local driver,
+	// set by the device option.
+	MountDevice string `json:"mountDevice,omitempty"`
+	// MountOptions are the options used to mount the volume's filesystem,
+	// set by the o option.
+	MountOptions string `json:"mountOptions,omitempty"`
+	// Chown is whether the root of the filesystem mounted as the volume is
+	// chowned to UID and GID when it is mounted, set by the chown option.
+	Chown bool `json:"chown,omitempty"`
+}
+
+// VolumeState holds the volume's mutable state.
+// Volumes created by older versions of libpod will not have a state stored in
+// the database; for these, an empty state will be used.
+type VolumeState struct {
+	// MountCount is the number of times this volume has been requested to
+	// be mounted.
+	// It is reset to 0 when the state is refreshed after a reboot.
+	MountCount uint `json:"mountCount"`
+	// MountPoint is the location on the host where the volume is mounted,
+	// if it is presently mounted. For volumes using the local driver, this
+	// will be the same as the configured mount point.
+	MountPoint string `json:"mountPoint,omitempty"`
+	// DriverStatus is the status of the volume reported by its volume
+	// plugin when it was last created or mounted.
+	DriverStatus map[string]interface{} `json:"driverStatus,omitempty"`
+	// NeedsCopyUp indicates that the next time the volume is mounted into
+	// a container, the contents of the container's image at the mount
+	// point should be copied into the volume.
+	NeedsCopyUp bool `json:"needsCopyUp,omitempty"`
+	// DiskUsed is the disk space used by the volume in bytes, as last
+	// computed at DiskUsageTime.
+	DiskUsed uint64 `json:"diskUsed,omitempty"`
+	// DiskUsageTime is the time the disk space used by the volume was last
+	// computed. It is zero if it never was.
+	DiskUsageTime time.Time `json:"diskUsageTime,omitempty"`
 }
 
 // Name retrieves the volume's name
@@ -54,11 +101,28 @@ func (v *Volume) Driver() string {
 	return v.config.Driver
 }
 
-// Scope retrieves the volume's scope.
-// Libpod does not implement volume scoping, and this is provided solely for
-// Docker compatibility. It returns only "local".
+// Scope retrieves the volume's scope, as reported by the volume's driver.
+// If the driver is not available, "local" is returned.
 func (v *Volume) Scope() string {
-	return "local"
+	driver, err := v.runtime.getVolumeDriver(v.config.Driver)
+	if err != nil {
+		return "local"
+	}
+	return driver.Capabilities().Scope
+}
+
+// Status retrieves the current capacity and health of the volume from the
+// volume's driver.
+func (v *Volume) Status() (*VolumeStatus, error) {
+	if !v.valid {
+		return nil, define.ErrVolumeRemoved
+	}
+
+	driver, err := v.runtime.getVolumeDriver(v.config.Driver)
+	if err != nil {
+		return nil, err
+	}
+	return driver.Status(v)
 }
 
 // Labels returns the volume's labels
@@ -70,9 +134,27 @@ func (v *Volume) Labels() map[string]string {
 	return labels
 }
 
-// MountPoint returns the volume's mountpoint on the host
+// UsesVolumeDriver returns whether the volume is managed by a volume plugin
+// rather than the local driver.
+func (v *Volume) UsesVolumeDriver() bool {
+	return v.config.Driver != "" && v.config.Driver != LocalVolumeDriver
+}
+
+// MountPoint returns the volume's mountpoint on the host.
+// Volumes managed by a volume plugin only have a mountpoint while they are
+// mounted into a container.
 func (v *Volume) MountPoint() string {
-	return v.config.MountPoint
+	if !v.UsesVolumeDriver() {
+		return v.config.MountPoint
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if err := v.update(); err != nil {
+		logrus.Errorf("Error updating volume %s state: %v", v.Name(), err)
+	}
+	return v.state.MountPoint
 }
 
 // Options return the volume's options
@@ -102,8 +184,36 @@ func (v *Volume) GID() int {
 	return v.config.GID
 }
 
+// SizeLimit returns the size in bytes the volume is limited to, or 0 if the
+// size of the volume is not limited.
+func (v *Volume) SizeLimit() uint64 {
+	return v.config.Size
+}
+
+// Size returns the disk space used by the volume in bytes.
+// It may be up to a minute old, see DiskUsage.
+func (v *Volume) Size() (uint64, error) {
+	usage, err := v.DiskUsage()
+	if err != nil {
+		return 0, err
+	}
+	return usage.Used, nil
+}
+
 // CreatedTime returns the time the volume was created at. It was not tracked
 // for some time, so older volumes may not contain one.
 func (v *Volume) CreatedTime() time.Time {
 	return v.config.CreatedTime
 }
+
+// MountCount returns the volume's current mount count.
+func (v *Volume) MountCount() (uint, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if err := v.update(); err != nil {
+		return 0, err
+	}
+
+	return v.state.MountCount, nil
+}
@@ -0,0 +1,150 @@
+package libpod
+
+import (
+	"context"
+	"io"
+
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/storage/pkg/archive"
+	"github.com/containers/storage/pkg/stringid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Export writes an uncompressed tar archive of the contents of the volume to
+// w, for backups or to move the volume to another host with ImportVolume.
+// The volume is locked until the archive is written, so it cannot be removed
+// meanwhile. Containers using the volume are not paused, and their writes
+// during the export may or may not be included.
+func (v *Volume) Export(w io.Writer) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if err := v.update(); err != nil {
+		return err
+	}
+
+	mountPoint, release, err := v.acquireContents()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	input, err := archive.Tar(mountPoint, archive.Uncompressed)
+	if err != nil {
+		return errors.Wrapf(err, "error reading volume %s directory %q", v.Name(), mountPoint)
+	}
+	defer input.Close()
+
+	if _, err := io.Copy(w, input); err != nil {
+		return errors.Wrapf(err, "error exporting volume %s", v.Name())
+	}
+	defer v.newVolumeEvent(events.Export)
+	return nil
+}
+
+// ImportVolume creates a new volume with the given name and the given
+// options, and populates it with the contents of the tar archive read from r,
+// as written by Volume.Export. If name is empty, a random name is used.
+// The runtime is only locked while the volume is created, the volume itself
+// being locked while it is populated. If the archive cannot be extracted, the
+// volume is removed.
+func (r *Runtime) ImportVolume(ctx context.Context, name string, reader io.Reader, options ...VolumeCreateOption) (_ *Volume, Err error) {
+	if name != "" {
+		options = append(options, WithVolumeName(name))
+	}
+	vol, err := r.NewVolume(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if Err != nil {
+			if err := r.RemoveVolume(ctx, vol, false); err != nil {
+				logrus.Errorf("Error removing volume %s after failed import: %v", vol.Name(), err)
+			}
+		}
+	}()
+
+	if err := vol.importArchive(reader); err != nil {
+		return nil, err
+	}
+	return vol, nil
+}
+
+// importArchive locks the volume and extracts the tar archive read from
+// reader into it
+func (v *Volume) importArchive(reader io.Reader) error {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	// The volume may have been removed since it was created
+	if err := v.update(); err != nil {
+		return err
+	}
+
+	if err := v.importContents(reader); err != nil {
+		return err
+	}
+	defer v.newVolumeEvent(events.Import)
+	return nil
+}
+
+// importContents extracts the tar archive read from reader into the volume,
+// over its current contents.
+// The volume must be locked.
+func (v *Volume) importContents(reader io.Reader) error {
+	mountPoint, release, err := v.acquireContents()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := archive.Untar(reader, mountPoint, nil); err != nil {
+		return errors.Wrapf(err, "error importing volume %s", v.Name())
+	}
+	return nil
+}
+
+// acquireContents returns the directory on the host holding the contents of
+// the volume, and a function to call once the contents are no longer used.
+// Volumes managed by a volume plugin are mounted by their plugin, and the
+// filesystem of tmpfs and device-backed volumes mounted, until the function is
+// called, unless they are already mounted.
+// The volume must be locked.
+func (v *Volume) acquireContents() (string, func(), error) {
+	if v.needsMount() && v.state.MountCount == 0 {
+		if err := v.mountLocal(); err != nil {
+			return "", nil, err
+		}
+		release := func() {
+			if err := v.unmountLocal(); err != nil {
+				logrus.Errorf("Error unmounting volume %s: %v", v.Name(), err)
+			}
+		}
+		return v.config.MountPoint, release, nil
+	}
+	if !v.UsesVolumeDriver() {
+		return v.config.MountPoint, func() {}, nil
+	}
+	if v.state.MountPoint != "" {
+		return v.state.MountPoint, func() {}, nil
+	}
+
+	volPlugin, err := v.runtime.getVolumePlugin(v.config.Driver)
+	if err != nil {
+		return "", nil, err
+	}
+	// Plugins track the users of a volume by mount ID, so the volume is
+	// mounted under an ID no container can have
+	mountID := "archive-" + stringid.GenerateNonCryptoID()
+	mountPoint, err := volPlugin.MountVolume(v.Name(), mountID)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "error mounting volume %s", v.Name())
+	}
+	release := func() {
+		if err := volPlugin.UnmountVolume(v.Name(), mountID); err != nil {
+			logrus.Errorf("Error unmounting volume %s: %v", v.Name(), err)
+		}
+	}
+	return mountPoint, release, nil
+}
@@ -0,0 +1,68 @@
+package libpod
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/libpod/libpod/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getVolumeTestRuntime(t *testing.T, volumePath string) *Runtime {
+	state, err := NewInMemoryState()
+	require.NoError(t, err)
+	manager, err := lock.NewInMemoryManager(16)
+	require.NoError(t, err)
+
+	return &Runtime{
+		config: &RuntimeConfig{
+			VolumePath: volumePath,
+		},
+		state:       state,
+		lockManager: manager,
+		eventer:     events.NewNullEventer(),
+		valid:       true,
+	}
+}
+
+func TestVolumeExportImport(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("volumes are created owned by root")
+	}
+	tmpDir, err := ioutil.TempDir("", "libpod_volume_test_")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	runtime := getVolumeTestRuntime(t, tmpDir)
+	ctx := context.Background()
+
+	vol, err := runtime.NewVolume(ctx, WithVolumeName("source"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(vol.MountPoint(), "dir"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(vol.MountPoint(), "dir", "file"), []byte("contents"), 0644))
+
+	var archive bytes.Buffer
+	require.NoError(t, vol.Export(&archive))
+
+	imported, err := runtime.ImportVolume(ctx, "imported", &archive)
+	require.NoError(t, err)
+	assert.Equal(t, "imported", imported.Name())
+	contents, err := ioutil.ReadFile(filepath.Join(imported.MountPoint(), "dir", "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "contents", string(contents))
+
+	// A volume that cannot be populated is removed
+	_, err = runtime.ImportVolume(ctx, "invalid", strings.NewReader("not a tar archive"))
+	assert.Error(t, err)
+	exists, err := runtime.state.HasVolume("invalid")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = os.Stat(filepath.Join(tmpDir, "invalid"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,169 @@
+package libpod
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/plugin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// LocalVolumeDriver is the name of the driver for volumes stored in
+// directories on the local host.
+const LocalVolumeDriver = "local"
+
+// VolumeDriver is a driver responsible for managing the storage backing named
+// volumes.
+// Besides the local driver, every volume plugin configured in the runtime is a
+// volume driver.
+type VolumeDriver interface {
+	// Name is the name of the driver, as given to volume create.
+	Name() string
+	// Capabilities returns the capabilities of the driver.
+	Capabilities() VolumeDriverCapabilities
+	// Status returns the current capacity and health of the given volume.
+	Status(v *Volume) (*VolumeStatus, error)
+}
+
+// VolumeDriverCapabilities describes the capabilities of a volume driver.
+type VolumeDriverCapabilities struct {
+	// Scope is "local" if volumes created by the driver are only available
+	// on this host, or "global" if they are available cluster-wide.
+	Scope string `json:"Scope"`
+}
+
+// VolumeStatus is the current state of a volume as reported by its driver.
+type VolumeStatus struct {
+	// Healthy is whether the volume can presently be used.
+	Healthy bool `json:"Healthy"`
+	// Message explains why the volume is not healthy.
+	Message string `json:"Message,omitempty"`
+	// CapacityBytes is the total size of the storage backing the volume.
+	// 0 if the driver cannot determine it.
+	CapacityBytes uint64 `json:"CapacityBytes,omitempty"`
+	// AvailableBytes is the free space in the storage backing the volume
+	// available to unprivileged users.
+	AvailableBytes uint64 `json:"AvailableBytes,omitempty"`
+	// ServerReachable is whether the server providing the volume's storage
+	// can be reached. It is nil for drivers without a mount server, such
+	// as the local driver.
+	ServerReachable *bool `json:"ServerReachable,omitempty"`
+	// Driver is the status of the volume specific to its volume plugin.
+	Driver map[string]interface{} `json:"Driver,omitempty"`
+}
+
+// localVolumeDriver is the driver for volumes in the local volume path.
+type localVolumeDriver struct{}
+
+// Name returns the name of the local driver.
+func (d *localVolumeDriver) Name() string {
+	return LocalVolumeDriver
+}
+
+// Capabilities returns the capabilities of the local driver.
+func (d *localVolumeDriver) Capabilities() VolumeDriverCapabilities {
+	return VolumeDriverCapabilities{
+		Scope: "local",
+	}
+}
+
+// pluginVolumeDriver is the driver for volumes managed by a volume plugin.
+type pluginVolumeDriver struct {
+	name    string
+	runtime *Runtime
+}
+
+// Name returns the name of the volume plugin.
+func (d *pluginVolumeDriver) Name() string {
+	return d.name
+}
+
+// Capabilities returns the capabilities reported by the volume plugin.
+// Plugins that cannot be reached are assumed to be local, as are plugins that
+// do not report a scope.
+func (d *pluginVolumeDriver) Capabilities() VolumeDriverCapabilities {
+	caps := VolumeDriverCapabilities{
+		Scope: "local",
+	}
+	volPlugin, err := d.runtime.getVolumePlugin(d.name)
+	if err != nil {
+		logrus.Debugf("Unable to retrieve capabilities of volume plugin %s: %v", d.name, err)
+		return caps
+	}
+	pluginCaps, err := volPlugin.Capabilities()
+	if err != nil {
+		logrus.Debugf("Unable to retrieve capabilities of volume plugin %s: %v", d.name, err)
+		return caps
+	}
+	if pluginCaps.Scope != "" {
+		caps.Scope = pluginCaps.Scope
+	}
+	return caps
+}
+
+// Status retrieves the volume from the volume plugin. The volume is unhealthy
+// if the plugin cannot be reached or does not know the volume.
+func (d *pluginVolumeDriver) Status(v *Volume) (*VolumeStatus, error) {
+	status := new(VolumeStatus)
+
+	reachable := false
+	status.ServerReachable = &reachable
+
+	volPlugin, err := d.runtime.getVolumePlugin(d.name)
+	if err != nil {
+		status.Message = fmt.Sprintf("error reaching volume plugin %s: %v", d.name, err)
+		return status, nil
+	}
+	reachable = true
+
+	pluginVol, err := volPlugin.GetVolume(v.Name())
+	if err != nil {
+		status.Message = err.Error()
+		return status, nil
+	}
+
+	status.Healthy = true
+	status.Driver = pluginVol.Status
+	return status, nil
+}
+
+// getVolumeDriver retrieves the driver with the given name.
+// An empty name refers to the local driver.
+func (r *Runtime) getVolumeDriver(name string) (VolumeDriver, error) {
+	switch name {
+	case "", LocalVolumeDriver:
+		return new(localVolumeDriver), nil
+	default:
+		if _, ok := r.config.VolumePlugins[name]; ok {
+			return &pluginVolumeDriver{name: name, runtime: r}, nil
+		}
+		return nil, errors.Wrapf(define.ErrNotImplemented, "volume driver %q is not supported", name)
+	}
+}
+
+// getVolumePlugin returns a client of the volume plugin with the given name,
+// activating it if necessary.
+func (r *Runtime) getVolumePlugin(name string) (*plugin.VolumePlugin, error) {
+	socketPath, ok := r.config.VolumePlugins[name]
+	if !ok {
+		return nil, errors.Wrapf(define.ErrNotImplemented, "volume driver %q is not supported", name)
+	}
+	return plugin.GetVolumePlugin(name, socketPath)
+}
+
+// VolumeDrivers returns all volume drivers available to the runtime.
+func (r *Runtime) VolumeDrivers() []VolumeDriver {
+	names := make([]string, 0, len(r.config.VolumePlugins))
+	for name := range r.config.VolumePlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	drivers := []VolumeDriver{new(localVolumeDriver)}
+	for _, name := range names {
+		drivers = append(drivers, &pluginVolumeDriver{name: name, runtime: r})
+	}
+	return drivers
+}
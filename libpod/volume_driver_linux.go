@@ -0,0 +1,27 @@
+// +build linux
+
+package libpod
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Status returns the capacity of the filesystem holding the volume's mount
+// point. The volume is unhealthy if the mount point cannot be accessed.
+func (d *localVolumeDriver) Status(v *Volume) (*VolumeStatus, error) {
+	status := new(VolumeStatus)
+
+	var fs unix.Statfs_t
+	if err := unix.Statfs(v.config.MountPoint, &fs); err != nil {
+		status.Message = fmt.Sprintf("error accessing volume mount point %s: %v", v.config.MountPoint, err)
+		return status, nil
+	}
+
+	status.Healthy = true
+	status.CapacityBytes = fs.Blocks * uint64(fs.Bsize)
+	status.AvailableBytes = fs.Bavail * uint64(fs.Bsize)
+
+	return status, nil
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package libpod
+
+import (
+	"github.com/containers/libpod/libpod/define"
+)
+
+// Status is not supported on this platform.
+func (d *localVolumeDriver) Status(v *Volume) (*VolumeStatus, error) {
+	return nil, define.ErrNotImplemented
+}
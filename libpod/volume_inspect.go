@@ -1,9 +1,12 @@
 package libpod
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/containers/libpod/libpod/define"
+	"github.com/sirupsen/logrus"
 )
 
 // InspectVolumeData is the output of Inspect() on a volume. It is matched to
@@ -19,16 +22,15 @@ type InspectVolumeData struct {
 	// CreatedAt is the date and time the volume was created at. This is not
 	// stored for older Libpod volumes; if so, it will be omitted.
 	CreatedAt time.Time `json:"CreatedAt,omitempty"`
-	// Status is presently unused and provided only for Docker compatibility.
-	// In the future it will be used to return information on the volume's
-	// current state.
+	// Status is the volume's current state as reported by its driver,
+	// including health and capacity. Keys are the fields of VolumeStatus,
+	// followed by the status reported by the volume's plugin.
 	Status map[string]string `json:"Status,omitempty"`
 	// Labels includes the volume's configured labels, key:value pairs that
 	// can be passed during volume creation to provide information for third
 	// party tools.
 	Labels map[string]string `json:"Labels"`
-	// Scope is unused and provided solely for Docker compatibility. It is
-	// unconditionally set to "local".
+	// Scope is the scope of the volume's driver, "local" or "global".
 	Scope string `json:"Scope"`
 	// Options is a set of options that were used when creating the volume.
 	// It is presently not used.
@@ -54,13 +56,19 @@ func (v *Volume) Inspect() (*InspectVolumeData, error) {
 
 	data.Name = v.config.Name
 	data.Driver = v.config.Driver
-	data.Mountpoint = v.config.MountPoint
+	data.Mountpoint = v.MountPoint()
 	data.CreatedAt = v.config.CreatedTime
 	data.Labels = make(map[string]string)
 	for k, v := range v.config.Labels {
 		data.Labels[k] = v
 	}
 	data.Scope = v.Scope()
+	status, err := v.Status()
+	if err != nil {
+		logrus.Debugf("Unable to retrieve status of volume %s: %v", v.config.Name, err)
+	} else {
+		data.Status = status.toMap()
+	}
 	data.Options = make(map[string]string)
 	data.UID = v.config.UID
 	data.GID = v.config.GID
@@ -68,3 +76,25 @@ func (v *Volume) Inspect() (*InspectVolumeData, error) {
 
 	return data, nil
 }
+
+// toMap converts a VolumeStatus into the string map used by volume inspect.
+func (s *VolumeStatus) toMap() map[string]string {
+	status := make(map[string]string)
+	status["Healthy"] = strconv.FormatBool(s.Healthy)
+	if s.Message != "" {
+		status["Message"] = s.Message
+	}
+	if s.CapacityBytes != 0 {
+		status["CapacityBytes"] = strconv.FormatUint(s.CapacityBytes, 10)
+		status["AvailableBytes"] = strconv.FormatUint(s.AvailableBytes, 10)
+	}
+	if s.ServerReachable != nil {
+		status["ServerReachable"] = strconv.FormatBool(*s.ServerReachable)
+	}
+	for key, value := range s.Driver {
+		if _, ok := status[key]; !ok {
+			status[key] = fmt.Sprintf("%v", value)
+		}
+	}
+	return status
+}
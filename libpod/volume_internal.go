@@ -3,12 +3,18 @@ package libpod
 import (
 	"os"
 	"path/filepath"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/plugin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // Creates a new volume
 func newVolume(runtime *Runtime) (*Volume, error) {
 	volume := new(Volume)
 	volume.config = new(VolumeConfig)
+	volume.state = new(VolumeState)
 	volume.runtime = runtime
 	volume.config.Labels = make(map[string]string)
 	volume.config.Options = make(map[string]string)
@@ -16,7 +22,207 @@ func newVolume(runtime *Runtime) (*Volume, error) {
 	return volume, nil
 }
 
-// teardownStorage deletes the volume from volumePath
+// refresh reallocates the volume's lock after a restart.
+// It cannot lock the volume, as its lock may not be valid until this has run.
+func (v *Volume) refresh() error {
+	lock, err := v.runtime.lockManager.AllocateAndRetrieveLock(v.config.LockID)
+	if err != nil {
+		return errors.Wrapf(err, "error acquiring lock %d for volume %s", v.config.LockID, v.Name())
+	}
+	v.lock = lock
+
+	return nil
+}
+
+// teardownStorage deletes the volume from volumePath, or removes it from its
+// volume plugin
 func (v *Volume) teardownStorage() error {
+	if v.UsesVolumeDriver() {
+		volPlugin, err := v.runtime.getVolumePlugin(v.config.Driver)
+		if err != nil {
+			return err
+		}
+		return volPlugin.RemoveVolume(v.Name())
+	}
+	// Never remove the contents of the filesystem mounted as the volume
+	if v.needsMount() {
+		if err := v.unmountLocal(); err != nil {
+			return err
+		}
+	}
 	return os.RemoveAll(filepath.Join(v.runtime.config.VolumePath, v.Name()))
 }
+
+// createDirectories creates the directory of a volume using the local driver
+// and the data directory within it that is mounted into containers, owned by
+// the volume's UID and GID, and limits its size.
+// It is also used to recreate the directories if they were removed.
+func (v *Volume) createDirectories() error {
+	volPathRoot := filepath.Join(v.runtime.config.VolumePath, v.config.Name)
+	if err := os.MkdirAll(volPathRoot, 0700); err != nil {
+		return errors.Wrapf(err, "error creating volume directory %q", volPathRoot)
+	}
+	// The quota must be set before the volume's data directory is
+	// created, so the directory inherits it
+	if err := v.setQuota(); err != nil {
+		return err
+	}
+	if err := os.Chown(volPathRoot, v.config.UID, v.config.GID); err != nil {
+		return errors.Wrapf(err, "error chowning volume directory %q to %d:%d", volPathRoot, v.config.UID, v.config.GID)
+	}
+	fullVolPath := filepath.Join(volPathRoot, "_data")
+	if err := os.Mkdir(fullVolPath, 0755); err != nil && !os.IsExist(err) {
+		return errors.Wrapf(err, "error creating volume directory %q", fullVolPath)
+	}
+	if err := os.Chown(fullVolPath, v.config.UID, v.config.GID); err != nil {
+		return errors.Wrapf(err, "error chowning volume directory %q to %d:%d", fullVolPath, v.config.UID, v.config.GID)
+	}
+	if err := LabelVolumePath(fullVolPath, true); err != nil {
+		return err
+	}
+	v.config.MountPoint = fullVolPath
+	return nil
+}
+
+// prepareLocal ensures the directories of a volume using the local driver are
+// present before it is mounted, recreating them with the volume's ownership if
+// they were removed, and limits its size.
+func (v *Volume) prepareLocal() error {
+	if _, err := os.Stat(v.config.MountPoint); err != nil {
+		if !os.IsNotExist(err) {
+			return errors.Wrapf(err, "error accessing volume directory %q", v.config.MountPoint)
+		}
+		logrus.Warnf("Directory of volume %s was removed, recreating it", v.Name())
+		return v.createDirectories()
+	}
+	return v.setQuota()
+}
+
+// mount asks the volume's plugin to mount the volume for the container with
+// the given ID, and records where it was mounted.
+// Volumes using the local driver are always available; only their size limit
+// is enforced, their directories recreated if they were removed, and the
+// filesystem of tmpfs and device-backed volumes mounted when they are first
+// used.
+// The volume must not be locked.
+func (v *Volume) mount(ctrID string) error {
+	if !v.UsesVolumeDriver() && !v.needsMount() {
+		return v.prepareLocal()
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if err := v.update(); err != nil {
+		return err
+	}
+
+	if v.needsMount() {
+		if v.state.MountCount == 0 {
+			if err := v.prepareLocal(); err != nil {
+				return err
+			}
+			if err := v.mountLocal(); err != nil {
+				return err
+			}
+		}
+		v.state.MountCount++
+		v.state.MountPoint = v.config.MountPoint
+		return v.save()
+	}
+
+	volPlugin, err := v.runtime.getVolumePlugin(v.config.Driver)
+	if err != nil {
+		return err
+	}
+	mountPoint, err := volPlugin.MountVolume(v.Name(), ctrID)
+	if err != nil {
+		return errors.Wrapf(err, "error mounting volume %s", v.Name())
+	}
+	logrus.Debugf("Mounted volume %s for container %s at %s", v.Name(), ctrID, mountPoint)
+
+	v.state.MountCount++
+	v.state.MountPoint = mountPoint
+	v.refreshDriverStatus(volPlugin)
+
+	return v.save()
+}
+
+// unmount asks the volume's plugin to release the mount of the volume made
+// for the container with the given ID.
+// The volume must not be locked.
+func (v *Volume) unmount(ctrID string) error {
+	if !v.UsesVolumeDriver() && !v.needsMount() {
+		return nil
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if err := v.update(); err != nil {
+		return err
+	}
+
+	if v.state.MountCount == 0 {
+		logrus.Debugf("Volume %s is not mounted, not unmounting it for container %s", v.Name(), ctrID)
+		return nil
+	}
+
+	if v.needsMount() {
+		v.state.MountCount--
+		if v.state.MountCount == 0 {
+			if err := v.unmountLocal(); err != nil {
+				return err
+			}
+			v.state.MountPoint = ""
+		}
+		return v.save()
+	}
+
+	volPlugin, err := v.runtime.getVolumePlugin(v.config.Driver)
+	if err != nil {
+		return err
+	}
+	if err := volPlugin.UnmountVolume(v.Name(), ctrID); err != nil {
+		return errors.Wrapf(err, "error unmounting volume %s", v.Name())
+	}
+	logrus.Debugf("Unmounted volume %s for container %s", v.Name(), ctrID)
+
+	v.state.MountCount--
+	if v.state.MountCount == 0 {
+		v.state.MountPoint = ""
+	}
+
+	return v.save()
+}
+
+// refreshDriverStatus records the status of the volume reported by its
+// plugin in the volume's state. It does not save the state.
+// Failures are only logged, as the status is informational.
+func (v *Volume) refreshDriverStatus(volPlugin *plugin.VolumePlugin) {
+	pluginVol, err := volPlugin.GetVolume(v.Name())
+	if err != nil {
+		logrus.Debugf("Unable to retrieve status of volume %s from its plugin: %v", v.Name(), err)
+		return
+	}
+	v.state.DriverStatus = pluginVol.Status
+}
+
+// update retrieves the volume's current state from the database.
+// The volume must be locked.
+func (v *Volume) update() error {
+	if !v.valid {
+		return define.ErrVolumeRemoved
+	}
+
+	return v.runtime.state.UpdateVolume(v)
+}
+
+// save writes the volume's current state to the database.
+// The volume must be locked.
+func (v *Volume) save() error {
+	if err := v.runtime.state.SaveVolume(v); err != nil {
+		return errors.Wrapf(err, "error saving volume %s state", v.Name())
+	}
+	return nil
+}
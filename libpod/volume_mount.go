@@ -0,0 +1,143 @@
+package libpod
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/pkg/mount"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// volumeTypeOption is the option of the local volume driver giving
+	// the type of the filesystem to mount as the volume, such as tmpfs or
+	// ext4.
+	volumeTypeOption = "type"
+	// volumeDeviceOption is the option of the local volume driver giving
+	// the device to mount as the volume.
+	volumeDeviceOption = "device"
+	// volumeMountOptsOption is the option of the local volume driver
+	// giving the comma-separated options used to mount the volume.
+	volumeMountOptsOption = "o"
+	// volumeChownOption is the option of the local volume driver that
+	// chowns the root of the filesystem mounted as the volume to the
+	// volume's UID and GID whenever it is mounted.
+	volumeChownOption = "chown"
+)
+
+// parseVolumeMount parses the options of the local volume driver describing a
+// filesystem to mount as the volume, and records them in the volume's config.
+// Volumes without a type option are plain directories, and are not mounted.
+func (v *Volume) parseVolumeMount() error {
+	fsType := v.config.Options[volumeTypeOption]
+	device := v.config.Options[volumeDeviceOption]
+	mountOpts := v.config.Options[volumeMountOptsOption]
+
+	if fsType == "" {
+		if device != "" {
+			return errors.Wrapf(define.ErrInvalidArg, "the %s option of volume %s requires the %s option", volumeDeviceOption, v.Name(), volumeTypeOption)
+		}
+		return nil
+	}
+	if device == "" {
+		if fsType != "tmpfs" {
+			return errors.Wrapf(define.ErrInvalidArg, "volume %s of type %s requires the %s option", v.Name(), fsType, volumeDeviceOption)
+		}
+		device = "tmpfs"
+	}
+	if v.config.Size != 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "the %s option of volume %s cannot be used with the %s option, use %s=size=... for tmpfs volumes", volumeSizeOption, v.Name(), volumeTypeOption, volumeMountOptsOption)
+	}
+
+	v.config.MountType = fsType
+	v.config.MountDevice = device
+	v.config.MountOptions = mountOpts
+	return nil
+}
+
+// parseVolumeOwnership parses the uid and gid mount options of the local volume
+// driver, which set the owner of the volume, and its chown option.
+// Plain directory volumes accept no other mount options. The uid and gid
+// options are passed on to tmpfs, which supports them, and removed from the
+// mount options of other filesystems, whose root is chowned instead if the
+// chown option is set.
+// The volume's mount options must have been parsed.
+func (v *Volume) parseVolumeOwnership() error {
+	var otherOpts []string
+	for _, opt := range strings.Split(v.config.Options[volumeMountOptsOption], ",") {
+		if opt == "" {
+			continue
+		}
+		splitOpt := strings.SplitN(opt, "=", 2)
+		switch splitOpt[0] {
+		case "uid", "gid":
+			if len(splitOpt) != 2 {
+				return errors.Wrapf(define.ErrInvalidArg, "the %s mount option of volume %s requires a value", splitOpt[0], v.Name())
+			}
+			id, err := strconv.Atoi(splitOpt[1])
+			if err != nil || id < 0 {
+				return errors.Wrapf(define.ErrInvalidArg, "invalid %s %q for volume %s", splitOpt[0], splitOpt[1], v.Name())
+			}
+			if splitOpt[0] == "uid" {
+				v.config.UID = id
+			} else {
+				v.config.GID = id
+			}
+			if v.config.MountType == "tmpfs" {
+				otherOpts = append(otherOpts, opt)
+			}
+		default:
+			if v.config.MountType == "" {
+				return errors.Wrapf(define.ErrInvalidArg, "volume %s only accepts the uid and gid mount options without the %s option, not %q", v.Name(), volumeTypeOption, opt)
+			}
+			otherOpts = append(otherOpts, opt)
+		}
+	}
+	v.config.MountOptions = strings.Join(otherOpts, ",")
+
+	if chown, ok := v.config.Options[volumeChownOption]; ok {
+		if v.config.MountType == "" || v.config.MountType == "tmpfs" {
+			return errors.Wrapf(define.ErrInvalidArg, "the %s option of volume %s is only supported for device-backed volumes", volumeChownOption, v.Name())
+		}
+		doChown, err := strconv.ParseBool(chown)
+		if err != nil {
+			return errors.Wrapf(define.ErrInvalidArg, "invalid value %q for the %s option of volume %s", chown, volumeChownOption, v.Name())
+		}
+		v.config.Chown = doChown
+	}
+	return nil
+}
+
+// needsMount returns whether the volume is a filesystem mounted by the local
+// driver while the volume is used.
+func (v *Volume) needsMount() bool {
+	return !v.UsesVolumeDriver() && v.config.MountType != ""
+}
+
+// mountLocal mounts the volume's filesystem at the volume's mount point.
+// The volume must be locked.
+func (v *Volume) mountLocal() error {
+	if err := mount.Mount(v.config.MountDevice, v.config.MountPoint, v.config.MountType, v.config.MountOptions); err != nil {
+		return errors.Wrapf(err, "error mounting %s filesystem %s for volume %s", v.config.MountType, v.config.MountDevice, v.Name())
+	}
+	logrus.Debugf("Mounted %s filesystem %s for volume %s at %s", v.config.MountType, v.config.MountDevice, v.Name(), v.config.MountPoint)
+	if v.config.Chown {
+		if err := os.Chown(v.config.MountPoint, v.config.UID, v.config.GID); err != nil {
+			return errors.Wrapf(err, "error chowning root of volume %s to %d:%d", v.Name(), v.config.UID, v.config.GID)
+		}
+	}
+	return nil
+}
+
+// unmountLocal unmounts the volume's filesystem, if it is mounted.
+// The volume must be locked.
+func (v *Volume) unmountLocal() error {
+	if err := mount.Unmount(v.config.MountPoint); err != nil {
+		return errors.Wrapf(err, "error unmounting volume %s", v.Name())
+	}
+	logrus.Debugf("Unmounted volume %s from %s", v.Name(), v.config.MountPoint)
+	return nil
+}
@@ -0,0 +1,81 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVolumeMount(t *testing.T) {
+	vol, err := newVolume(nil)
+	assert.NoError(t, err)
+	vol.config.Driver = LocalVolumeDriver
+	assert.NoError(t, vol.parseVolumeMount())
+	assert.False(t, vol.needsMount())
+
+	vol.config.Options = map[string]string{"type": "tmpfs", "o": "size=64m,mode=0700"}
+	assert.NoError(t, vol.parseVolumeMount())
+	assert.True(t, vol.needsMount())
+	assert.Equal(t, "tmpfs", vol.config.MountDevice)
+	assert.Equal(t, "size=64m,mode=0700", vol.config.MountOptions)
+
+	vol.config.Options = map[string]string{"type": "ext4", "device": "/dev/sdb1"}
+	assert.NoError(t, vol.parseVolumeMount())
+	assert.Equal(t, "ext4", vol.config.MountType)
+	assert.Equal(t, "/dev/sdb1", vol.config.MountDevice)
+
+	for _, invalid := range []map[string]string{
+		{"type": "ext4"},
+		{"device": "/dev/sdb1"},
+	} {
+		vol.config.Options = invalid
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(vol.parseVolumeMount()), invalid)
+	}
+
+	vol.config.Options = map[string]string{"type": "tmpfs"}
+	vol.config.Size = 1024
+	assert.Equal(t, define.ErrInvalidArg, errors.Cause(vol.parseVolumeMount()))
+}
+
+func TestParseVolumeOwnership(t *testing.T) {
+	vol, err := newVolume(nil)
+	assert.NoError(t, err)
+	vol.config.Driver = LocalVolumeDriver
+
+	vol.config.Options = map[string]string{"o": "uid=1000,gid=100"}
+	assert.NoError(t, vol.parseVolumeMount())
+	assert.NoError(t, vol.parseVolumeOwnership())
+	assert.Equal(t, 1000, vol.config.UID)
+	assert.Equal(t, 100, vol.config.GID)
+	assert.Equal(t, "", vol.config.MountOptions)
+
+	vol.config.Options = map[string]string{"type": "tmpfs", "o": "size=64m,uid=1000"}
+	assert.NoError(t, vol.parseVolumeMount())
+	assert.NoError(t, vol.parseVolumeOwnership())
+	assert.Equal(t, "size=64m,uid=1000", vol.config.MountOptions)
+
+	vol.config.Options = map[string]string{"type": "ext4", "device": "/dev/sdb1", "o": "noatime,uid=1000,gid=1000", "chown": "true"}
+	assert.NoError(t, vol.parseVolumeMount())
+	assert.NoError(t, vol.parseVolumeOwnership())
+	assert.Equal(t, "noatime", vol.config.MountOptions)
+	assert.True(t, vol.config.Chown)
+
+	for _, invalid := range []map[string]string{
+		{"o": "ro"},
+		{"o": "uid"},
+		{"o": "uid=-1"},
+		{"o": "gid=users"},
+		{"chown": "true"},
+		{"type": "tmpfs", "chown": "true"},
+		{"type": "ext4", "device": "/dev/sdb1", "chown": "maybe"},
+	} {
+		vol, err := newVolume(nil)
+		assert.NoError(t, err)
+		vol.config.Driver = LocalVolumeDriver
+		vol.config.Options = invalid
+		assert.NoError(t, vol.parseVolumeMount(), invalid)
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(vol.parseVolumeOwnership()), invalid)
+	}
+}
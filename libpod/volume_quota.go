@@ -0,0 +1,132 @@
+package libpod
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/drivers/quota"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+const (
+	// volumeSizeOption is the option of the local volume driver that
+	// limits the size of the volume.
+	volumeSizeOption = "size"
+	// volumeDiskUsageMaxAge is how long the disk space used by a volume is
+	// reused once computed.
+	volumeDiskUsageMaxAge = time.Minute
+)
+
+// VolumeDiskUsage is the disk space used by a volume.
+type VolumeDiskUsage struct {
+	// Name is the name of the volume.
+	Name string `json:"name"`
+	// SizeLimit is the size the volume is limited to, in bytes. 0 if the
+	// size of the volume is not limited.
+	SizeLimit uint64 `json:"sizeLimit"`
+	// Used is the size of the files in the volume, in bytes.
+	Used uint64 `json:"used"`
+	// ComputedAt is the time Used was computed at.
+	ComputedAt time.Time `json:"computedAt"`
+}
+
+// parseVolumeSize parses the size option of the local volume driver, as a
+// human-readable size such as 10G. A volume without the option is not
+// limited, and 0 is returned.
+func parseVolumeSize(options map[string]string) (uint64, error) {
+	sizeStr, ok := options[volumeSizeOption]
+	if !ok {
+		return 0, nil
+	}
+	size, err := units.RAMInBytes(sizeStr)
+	if err != nil {
+		return 0, errors.Wrapf(define.ErrInvalidArg, "invalid volume size %q: %v", sizeStr, err)
+	}
+	if size <= 0 {
+		return 0, errors.Wrapf(define.ErrInvalidArg, "volume size %q must be greater than 0", sizeStr)
+	}
+	return uint64(size), nil
+}
+
+// setQuota limits the size of the volume's directory with a project quota on
+// the filesystem holding the volumes path. Quotas are set again whenever the
+// volume is mounted, as they may have been lost, for example if the volumes
+// were restored from a backup.
+// Nothing is done for volumes without a size limit.
+func (v *Volume) setQuota() error {
+	if v.config.Size == 0 {
+		return nil
+	}
+
+	q, err := quota.NewControl(v.runtime.config.VolumePath)
+	if err != nil {
+		return errors.Wrapf(define.ErrNotImplemented, "cannot limit the size of volume %s, project quotas are not available for %s: %v", v.Name(), v.runtime.config.VolumePath, err)
+	}
+	if err := q.SetQuota(filepath.Join(v.runtime.config.VolumePath, v.Name()), quota.Quota{Size: v.config.Size}); err != nil {
+		return errors.Wrapf(err, "error limiting the size of volume %s", v.Name())
+	}
+	return nil
+}
+
+// DiskUsage returns the disk space used by the volume.
+// Computing it requires walking the volume's contents, so the result is kept
+// in the volume's state and reused for volumeDiskUsageMaxAge.
+// Volumes managed by a volume plugin only use space on the host while they
+// are mounted into a container.
+func (v *Volume) DiskUsage() (*VolumeDiskUsage, error) {
+	if !v.valid {
+		return nil, define.ErrVolumeRemoved
+	}
+
+	usage := new(VolumeDiskUsage)
+	usage.Name = v.Name()
+	usage.SizeLimit = v.SizeLimit()
+
+	v.lock.Lock()
+	if err := v.update(); err != nil {
+		v.lock.Unlock()
+		return nil, err
+	}
+	mountPoint := v.config.MountPoint
+	if v.UsesVolumeDriver() {
+		mountPoint = v.state.MountPoint
+	}
+	if !v.state.DiskUsageTime.IsZero() && time.Since(v.state.DiskUsageTime) < volumeDiskUsageMaxAge {
+		usage.Used = v.state.DiskUsed
+		usage.ComputedAt = v.state.DiskUsageTime
+		v.lock.Unlock()
+		return usage, nil
+	}
+	// The volume is not kept locked while its contents are walked, which
+	// can take a while, so containers using it can still be started
+	v.lock.Unlock()
+
+	usage.ComputedAt = time.Now()
+	if mountPoint == "" {
+		return usage, nil
+	}
+	err := filepath.Walk(mountPoint, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			usage.Used += uint64(info.Size())
+		}
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error computing disk usage of volume %s", v.Name())
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if err := v.update(); err != nil {
+		return nil, err
+	}
+	v.state.DiskUsed = usage.Used
+	v.state.DiskUsageTime = usage.ComputedAt
+	if err := v.save(); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
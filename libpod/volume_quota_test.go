@@ -0,0 +1,24 @@
+package libpod
+
+import (
+	"testing"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVolumeSize(t *testing.T) {
+	size, err := parseVolumeSize(map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), size)
+
+	size, err = parseVolumeSize(map[string]string{"size": "10G"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10*1024*1024*1024), size)
+
+	for _, invalid := range []string{"", "0", "-1G", "ten"} {
+		_, err = parseVolumeSize(map[string]string{"size": invalid})
+		assert.Equal(t, define.ErrInvalidArg, errors.Cause(err), invalid)
+	}
+}
@@ -47,11 +47,15 @@ func crImportFromJSON(filePath string, v interface{}) error {
 func crImportCheckpoint(ctx context.Context, runtime *libpod.Runtime, input string, name string) ([]*libpod.Container, error) {
 	// First get the container definition from the
 	// tarball to a temporary directory
-	archiveFile, err := os.Open(input)
+	archiveFile, err := runtime.OpenCheckpointArchive(ctx, input)
 	if err != nil {
-		return nil, errors.Wrapf(err, "Failed to open checkpoint archive %s for import", input)
+		return nil, err
 	}
-	defer errorhandling.CloseQuiet(archiveFile)
+	defer func() {
+		if err := archiveFile.Close(); err != nil {
+			logrus.Errorf("unable to close checkpoint archive %s: %q", input, err)
+		}
+	}()
 	options := &archive.TarOptions{
 		// Here we only need the files config.dump and spec.dump
 		ExcludePatterns: []string{
@@ -60,6 +64,7 @@ func crImportCheckpoint(ctx context.Context, runtime *libpod.Runtime, input stri
 			"ctr.log",
 			"rootfs-diff.tar",
 			"network.status",
+			"volumes",
 		},
 	}
 	dir, err := ioutil.TempDir("", "checkpoint")
@@ -89,8 +94,8 @@ func crImportCheckpoint(ctx context.Context, runtime *libpod.Runtime, input stri
 	}
 
 	// This should not happen as checkpoints with these options are not exported.
-	if (len(config.Dependencies) > 0) || (len(config.NamedVolumes) > 0) {
-		return nil, errors.Errorf("Cannot import checkpoints of containers with named volumes or dependencies")
+	if len(config.Dependencies) > 0 {
+		return nil, errors.Errorf("Cannot import checkpoints of containers with dependencies")
 	}
 
 	ctrID := config.ID
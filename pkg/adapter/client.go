@@ -35,7 +35,12 @@ func (r RemoteRuntime) RemoteEndpoint() (remoteEndpoint *Endpoint, err error) {
 		if len(r.cmd.RemoteUserName) < 1 {
 			return nil, errors.New("you must provide a username when providing a remote host name")
 		}
-		rc := remoteclientconfig.RemoteConnection{r.cmd.RemoteHost, r.cmd.RemoteUserName, false}
+		rc := remoteclientconfig.RemoteConnection{
+			Destination:  r.cmd.RemoteHost,
+			Username:     r.cmd.RemoteUserName,
+			Port:         r.cmd.Port,
+			IdentityFile: r.cmd.IdentityFile,
+		}
 		remoteEndpoint, err = newBridgeConnection("", &rc, r.cmd.LogLevel)
 		//  if the user has a config file with connections in it
 	} else if len(remoteConfigConnections.Connections) > 0 {
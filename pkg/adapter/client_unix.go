@@ -5,12 +5,23 @@ package adapter
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/containers/libpod/cmd/podman/remoteclientconfig"
 )
 
 func formatDefaultBridge(remoteConn *remoteclientconfig.RemoteConnection, logLevel string) string {
+	flags := remoteConn.SSHFlags()
+	for i, flag := range flags {
+		flags[i] = shellQuote(flag)
+	}
 	return fmt.Sprintf(
-		`ssh -T %s@%s -- /usr/bin/varlink -A \'/usr/bin/podman --log-level=%s varlink \\\$VARLINK_ADDRESS\' bridge`,
-		remoteConn.Username, remoteConn.Destination, logLevel)
+		`ssh -T %s %s@%s -- /usr/bin/varlink -A \'/usr/bin/podman --log-level=%s varlink \\\$VARLINK_ADDRESS\' bridge`,
+		strings.Join(flags, " "), remoteConn.Username, remoteConn.Destination, logLevel)
+}
+
+// shellQuote quotes s for the shell running the bridge command, so paths with
+// spaces or shell metacharacters are passed to ssh as a single argument
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
 }
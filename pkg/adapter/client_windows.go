@@ -4,12 +4,13 @@ package adapter
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/containers/libpod/cmd/podman/remoteclientconfig"
 )
 
 func formatDefaultBridge(remoteConn *remoteclientconfig.RemoteConnection, logLevel string) string {
 	return fmt.Sprintf(
-		`ssh -T %s@%s -- /usr/bin/varlink -A '/usr/bin/podman --log-level=%s varlink $VARLINK_ADDRESS' bridge`,
-		remoteConn.Username, remoteConn.Destination, logLevel)
+		`ssh -T %s %s@%s -- /usr/bin/varlink -A '/usr/bin/podman --log-level=%s varlink $VARLINK_ADDRESS' bridge`,
+		strings.Join(remoteConn.SSHFlags(), " "), remoteConn.Username, remoteConn.Destination, logLevel)
 }
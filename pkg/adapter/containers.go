@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,9 +27,9 @@ import (
 	"github.com/containers/libpod/libpod/image"
 	"github.com/containers/libpod/libpod/logs"
 	"github.com/containers/libpod/pkg/adapter/shortcuts"
-	"github.com/containers/libpod/pkg/systemdgen"
 	"github.com/containers/psgo"
 	"github.com/containers/storage"
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -68,7 +69,8 @@ func (r *LocalRuntime) LookupContainer(idOrName string) (*Container, error) {
 // Returns list of successful id(s), map of failed id(s) + error, or error not from container
 func (r *LocalRuntime) StopContainers(ctx context.Context, cli *cliconfig.StopValues) ([]string, map[string]error, error) {
 	var timeout *uint
-	if cli.Flags().Changed("timeout") || cli.Flags().Changed("time") {
+	explicitTimeout := cli.Flags().Changed("timeout") || cli.Flags().Changed("time")
+	if explicitTimeout {
 		t := cli.Timeout
 		timeout = &t
 	}
@@ -97,6 +99,11 @@ func (r *LocalRuntime) StopContainers(ctx context.Context, cli *cliconfig.StopVa
 		pool.Add(shared.Job{
 			ID: c.ID(),
 			Fn: func() error {
+				if explicitTimeout {
+					if err := c.ValidateStopTimeout(*timeout); err != nil {
+						return err
+					}
+				}
 				err := c.StopWithTimeout(*timeout)
 				if err != nil {
 					if errors.Cause(err) == define.ErrCtrStopped {
@@ -285,7 +292,7 @@ func (r *LocalRuntime) WaitOnContainers(ctx context.Context, cli *cliconfig.Wait
 }
 
 // Log logs one or more containers
-func (r *LocalRuntime) Log(c *cliconfig.LogsValues, options *logs.LogOptions) error {
+func (r *LocalRuntime) Log(ctx context.Context, c *cliconfig.LogsValues, options *logs.LogOptions) error {
 
 	var wg sync.WaitGroup
 	options.WaitGroup = &wg
@@ -297,7 +304,7 @@ func (r *LocalRuntime) Log(c *cliconfig.LogsValues, options *logs.LogOptions) er
 	if err != nil {
 		return err
 	}
-	if err := r.Runtime.Log(containers, options, logChannel); err != nil {
+	if err := r.Runtime.Log(ctx, containers, options, logChannel); err != nil {
 		return err
 	}
 	go func() {
@@ -434,7 +441,9 @@ func (r *LocalRuntime) Run(ctx context.Context, c *cliconfig.RunValues, exitCode
 	}
 
 	if c.IsSet("rm") {
-		if err := r.Runtime.RemoveContainer(ctx, ctr, false, false); err != nil {
+		// The container may already have been removed by its cleanup
+		// process
+		if err := r.Runtime.RemoveContainer(ctx, ctr, false, false); err != nil && errors.Cause(err) != define.ErrNoSuchCtr && errors.Cause(err) != define.ErrCtrRemoved {
 			logrus.Errorf("Error removing container %s: %v", ctr.ID(), err)
 		}
 	}
@@ -706,6 +715,75 @@ func (r *LocalRuntime) PauseContainers(ctx context.Context, cli *cliconfig.Pause
 	return pool.Run()
 }
 
+// UpdateContainers updates the resource limits of container(s) based on CLI inputs.
+func (r *LocalRuntime) UpdateContainers(ctx context.Context, cli *cliconfig.UpdateValues) ([]string, map[string]error, error) {
+	var (
+		ok       = []string{}
+		failures = map[string]error{}
+		update   libpod.ContainerResourceUpdate
+	)
+
+	if cli.IsSet("blkio-weight") {
+		u, err := strconv.ParseUint(cli.BlkioWeight, 10, 16)
+		if err != nil {
+			return ok, failures, errors.Wrapf(err, "invalid value for blkio-weight")
+		}
+		blkioWeight := uint16(u)
+		update.BlkioWeight = &blkioWeight
+	}
+	if cli.IsSet("cpu-shares") {
+		update.CPUShares = &cli.CPUShares
+	}
+	if cli.IsSet("memory") {
+		memory, err := units.RAMInBytes(cli.Memory)
+		if err != nil {
+			return ok, failures, errors.Wrapf(err, "invalid value for memory")
+		}
+		update.MemoryLimit = &memory
+	}
+	if cli.IsSet("memory-swap") {
+		memorySwap := int64(-1)
+		if cli.MemorySwap != "-1" {
+			var err error
+			memorySwap, err = units.RAMInBytes(cli.MemorySwap)
+			if err != nil {
+				return ok, failures, errors.Wrapf(err, "invalid value for memory-swap")
+			}
+		}
+		update.MemorySwap = &memorySwap
+	}
+	if cli.IsSet("pids-limit") {
+		update.PidsLimit = &cli.PidsLimit
+	}
+
+	maxWorkers := shared.DefaultPoolSize("update")
+	if cli.GlobalIsSet("max-workers") {
+		maxWorkers = cli.GlobalFlags.MaxWorks
+	}
+	logrus.Debugf("Setting maximum update workers to %d", maxWorkers)
+
+	ctrs, err := shortcuts.GetContainersByContext(false, false, cli.InputArgs, r.Runtime)
+	if err != nil {
+		return ok, failures, err
+	}
+
+	pool := shared.NewPool("update", maxWorkers, len(ctrs))
+	for _, c := range ctrs {
+		ctr := c
+		pool.Add(shared.Job{
+			ID: ctr.ID(),
+			Fn: func() error {
+				err := r.UpdateContainer(ctr, &update)
+				if err != nil {
+					logrus.Debugf("Failed to update container %s: %s", ctr.ID(), err.Error())
+				}
+				return err
+			},
+		})
+	}
+	return pool.Run()
+}
+
 // UnpauseContainers removes container(s) based on CLI inputs.
 func (r *LocalRuntime) UnpauseContainers(ctx context.Context, cli *cliconfig.UnpauseValues) ([]string, map[string]error, error) {
 	var (
@@ -810,6 +888,11 @@ func (r *LocalRuntime) Restart(ctx context.Context, c *cliconfig.RestartValues)
 		pool.Add(shared.Job{
 			ID: ctr.ID(),
 			Fn: func() error {
+				if useTimeout {
+					if err := ctr.ValidateStopTimeout(timeout); err != nil {
+						return err
+					}
+				}
 				err := ctr.RestartWithTimeout(ctx, timeout)
 				if err != nil {
 					logrus.Debugf("Failed to restart container %s: %s", ctr.ID(), err.Error())
@@ -962,6 +1045,18 @@ func (r *LocalRuntime) ExecContainer(ctx context.Context, cli *cliconfig.ExecVal
 		envs = append(envs, fmt.Sprintf("%s=%s", k, v))
 	}
 
+	if cli.Detach {
+		if cli.Interactive {
+			return ec, errors.Errorf("the interactive and detach flags are mutually exclusive")
+		}
+		sessionID, err := ctr.ExecDetached(cli.Tty, cli.Privileged, envs, cmd, cli.User, cli.Workdir, cli.PreserveFDs)
+		if err != nil {
+			return define.TranslateExecErrorToExitCode(ec, err), err
+		}
+		fmt.Println(sessionID)
+		return 0, nil
+	}
+
 	streams := new(libpod.AttachStreams)
 	streams.OutputStream = os.Stdout
 	streams.ErrorStream = os.Stderr
@@ -1095,141 +1190,59 @@ func (r *LocalRuntime) Port(c *cliconfig.PortValues) ([]*Container, error) {
 }
 
 // generateServiceName generates the container name and the service name for systemd service.
-func generateServiceName(c *cliconfig.GenerateSystemdValues, ctr *libpod.Container, pod *libpod.Pod) (string, string) {
-	var kind, name, ctrName string
-	if pod == nil {
-		kind = "container"
-		name = ctr.ID()
-		if c.Name {
-			name = ctr.Name()
-		}
-		ctrName = name
-	} else {
-		kind = "pod"
-		name = pod.ID()
-		ctrName = ctr.ID()
-		if c.Name {
-			name = pod.Name()
-			ctrName = ctr.Name()
-		}
-	}
-	return ctrName, fmt.Sprintf("%s-%s", kind, name)
-}
-
-// generateSystemdgenContainerInfo is a helper to generate a
-// systemdgen.ContainerInfo for `GenerateSystemd`.
-func (r *LocalRuntime) generateSystemdgenContainerInfo(c *cliconfig.GenerateSystemdValues, nameOrID string, pod *libpod.Pod) (*systemdgen.ContainerInfo, bool, error) {
-	ctr, err := r.Runtime.LookupContainer(nameOrID)
-	if err != nil {
-		return nil, false, err
-	}
-
-	timeout := int(ctr.StopTimeout())
-	if c.StopTimeout >= 0 {
-		timeout = c.StopTimeout
-	}
-
-	config := ctr.Config()
-	conmonPidFile := config.ConmonPidFile
-	if conmonPidFile == "" {
-		return nil, true, errors.Errorf("conmon PID file path is empty, try to recreate the container with --conmon-pidfile flag")
-	}
-
-	name, serviceName := generateServiceName(c, ctr, pod)
-	info := &systemdgen.ContainerInfo{
-		ServiceName:       serviceName,
-		ContainerName:     name,
-		RestartPolicy:     c.RestartPolicy,
-		PIDFile:           conmonPidFile,
-		StopTimeout:       timeout,
-		GenerateTimestamp: true,
-	}
-
-	return info, true, nil
-}
-
 // GenerateSystemd creates a unit file for a container or pod.
 func (r *LocalRuntime) GenerateSystemd(c *cliconfig.GenerateSystemdValues) (string, error) {
-	// First assume it's a container.
-	if info, found, err := r.generateSystemdgenContainerInfo(c, c.InputArgs[0], nil); found && err != nil {
-		return "", err
-	} else if found && err == nil {
-		return systemdgen.CreateContainerSystemdUnit(info, c.Files)
+	options := libpod.SystemdUnitOptions{
+		UseName:       c.Name,
+		New:           c.New,
+		RestartPolicy: c.RestartPolicy,
+		Timestamp:     true,
 	}
-
-	// We're either having a pod or garbage.
-	pod, err := r.Runtime.LookupPod(c.InputArgs[0])
-	if err != nil {
-		return "", err
-	}
-
-	// Error out if the pod has no infra container, which we require to be the
-	// main service.
-	if !pod.HasInfraContainer() {
-		return "", fmt.Errorf("error generating systemd unit files: Pod %q has no infra container", pod.Name())
-	}
-
-	// Generate a systemdgen.ContainerInfo for the infra container. This
-	// ContainerInfo acts as the main service of the pod.
-	infraID, err := pod.InfraContainerID()
-	if err != nil {
-		return "", nil
-	}
-	podInfo, _, err := r.generateSystemdgenContainerInfo(c, infraID, pod)
-	if err != nil {
-		return "", nil
-	}
-
-	// Compute the container-dependency graph for the Pod.
-	containers, err := pod.AllContainers()
-	if err != nil {
-		return "", err
-	}
-	if len(containers) == 0 {
-		return "", fmt.Errorf("error generating systemd unit files: Pod %q has no containers", pod.Name())
-	}
-	graph, err := libpod.BuildContainerGraph(containers)
-	if err != nil {
-		return "", err
+	if c.StopTimeout >= 0 {
+		timeout := uint(c.StopTimeout)
+		options.StopTimeout = &timeout
 	}
 
-	// Traverse the dependency graph and create systemdgen.ContainerInfo's for
-	// each container.
-	containerInfos := []*systemdgen.ContainerInfo{podInfo}
-	for ctr, dependencies := range graph.DependencyMap() {
-		// Skip the infra container as we already generated it.
-		if ctr.ID() == infraID {
-			continue
+	// First assume it's a container.
+	var units []*libpod.SystemdUnit
+	if ctr, err := r.Runtime.LookupContainer(c.InputArgs[0]); err == nil {
+		unit, err := r.Runtime.GenerateSystemdUnit(ctr, options)
+		if err != nil {
+			return "", err
 		}
-		ctrInfo, _, err := r.generateSystemdgenContainerInfo(c, ctr.ID(), nil)
+		units = append(units, unit)
+	} else {
+		// We're either having a pod or garbage.
+		pod, err := r.Runtime.LookupPod(c.InputArgs[0])
 		if err != nil {
 			return "", err
 		}
-		// Now add the container's dependencies and at the container as a
-		// required service of the infra container.
-		for _, dep := range dependencies {
-			if dep.ID() == infraID {
-				ctrInfo.BoundToServices = append(ctrInfo.BoundToServices, podInfo.ServiceName)
-			} else {
-				_, serviceName := generateServiceName(c, dep, nil)
-				ctrInfo.BoundToServices = append(ctrInfo.BoundToServices, serviceName)
-			}
+		if units, err = r.Runtime.GeneratePodSystemdUnits(pod, options); err != nil {
+			return "", err
 		}
-		podInfo.RequiredServices = append(podInfo.RequiredServices, ctrInfo.ServiceName)
-		containerInfos = append(containerInfos, ctrInfo)
 	}
 
-	// Now generate the systemd service for all containers.
+	var cwd string
+	if c.Files {
+		var err error
+		if cwd, err = os.Getwd(); err != nil {
+			return "", errors.Wrap(err, "error getting current working directory")
+		}
+	}
 	builder := strings.Builder{}
-	for i, info := range containerInfos {
+	for i, unit := range units {
 		if i > 0 {
 			builder.WriteByte('\n')
 		}
-		out, err := systemdgen.CreateContainerSystemdUnit(info, c.Files)
-		if err != nil {
-			return "", err
+		if !c.Files {
+			builder.WriteString(unit.Content)
+			continue
+		}
+		path := filepath.Join(cwd, unit.Name)
+		if err := ioutil.WriteFile(path, []byte(unit.Content+"\n"), 0644); err != nil {
+			return "", errors.Wrap(err, "error generating systemd unit")
 		}
-		builder.WriteString(out)
+		builder.WriteString(path)
 	}
 
 	return builder.String(), nil
@@ -44,6 +44,11 @@ func (c *Container) Inspect(size bool) (*libpod.InspectContainerData, error) {
 	return &data, err
 }
 
+// Verify is not supported by the remote client
+func (c *Container) Verify(ctx context.Context) (*libpod.ContainerVerifyReport, error) {
+	return nil, define.ErrNotImplemented
+}
+
 // ID returns the ID of the container
 func (c *Container) ID() string {
 	return c.config.ID
@@ -415,7 +420,7 @@ func BatchContainerOp(ctr *Container, opts shared.PsOptions) (shared.BatchContai
 }
 
 // Log one or more containers over a varlink connection
-func (r *LocalRuntime) Log(c *cliconfig.LogsValues, options *logs.LogOptions) error {
+func (r *LocalRuntime) Log(ctx context.Context, c *cliconfig.LogsValues, options *logs.LogOptions) error {
 	// GetContainersLogs
 	reply, err := iopodman.GetContainersLogs().Send(r.Conn, uint64(varlink.More), c.InputArgs, c.Follow, c.Latest, options.Since.Format(time.RFC3339Nano), int64(c.Tail), c.Timestamps)
 	if err != nil {
@@ -444,7 +449,11 @@ func (r *LocalRuntime) Log(c *cliconfig.LogsValues, options *logs.LogOptions) er
 			Msg:          log.Msg,
 			CID:          log.Cid,
 		}
-		fmt.Println(logLine.String(options))
+		// Lines of several containers are interleaved, so a later line
+		// may still be wanted
+		if logLine.Until(options.Until) {
+			fmt.Println(logLine.String(options))
+		}
 		if flags&varlink.Continues == 0 {
 			break
 		}
@@ -753,6 +762,11 @@ func (r *LocalRuntime) attach(ctx context.Context, stdin, stdout *os.File, cid s
 	return errChan, nil
 }
 
+// UpdateContainers updates the resource limits of container(s) based on CLI inputs.
+func (r *LocalRuntime) UpdateContainers(ctx context.Context, cli *cliconfig.UpdateValues) ([]string, map[string]error, error) {
+	return nil, nil, define.ErrNotImplemented
+}
+
 // PauseContainers pauses container(s) based on CLI inputs.
 func (r *LocalRuntime) PauseContainers(ctx context.Context, cli *cliconfig.PauseValues) ([]string, map[string]error, error) {
 	var (
@@ -999,6 +1013,9 @@ func (r *LocalRuntime) ExecContainer(ctx context.Context, cli *cliconfig.ExecVal
 		ec           int = define.ExecErrorCodeGeneric
 	)
 	// default invalid command exit code
+	if cli.Detach {
+		return ec, errors.New("detached exec sessions are not supported by the remote client")
+	}
 	// Validate given environment variables
 	env := map[string]string{}
 	if err := parse.ReadKVStrings(env, []string{}, cli.Env); err != nil {
@@ -3,6 +3,7 @@
 package adapter
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/containernetworking/cni/libcni"
 	"github.com/containers/libpod/cmd/podman/cliconfig"
+	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/pkg/network"
 	"github.com/pkg/errors"
 )
@@ -98,6 +100,23 @@ func (r *LocalRuntime) NetworkRemove(cli *cliconfig.NetworkRmValues) error {
 		if err != nil {
 			return err
 		}
+		// The network must not be in use, unless its containers are
+		// to be disconnected from it
+		tracked, err := r.HasNetwork(name)
+		if err != nil {
+			return err
+		}
+		if tracked {
+			net, err := r.GetNetwork(name)
+			if err != nil {
+				return err
+			}
+			if err := r.Runtime.RemoveNetwork(context.TODO(), net, cli.Force); err != nil {
+				return err
+			}
+		} else if err := r.disconnectNetworkUsers(name, cli.Force); err != nil {
+			return err
+		}
 		if err := os.Remove(cniPath); err != nil {
 			return err
 		}
@@ -106,6 +125,38 @@ func (r *LocalRuntime) NetworkRemove(cli *cliconfig.NetworkRmValues) error {
 	return nil
 }
 
+// NetworkPrune removes the CNI networks tracked by libpod that are not used by
+// any container
+func (r *LocalRuntime) NetworkPrune(ctx context.Context) ([]string, []error) {
+	return r.Runtime.PruneNetworks(ctx)
+}
+
+// disconnectNetworkUsers checks that a network that is not tracked by libpod
+// is not used by any container, or disconnects the containers from it if force
+// is set
+func (r *LocalRuntime) disconnectNetworkUsers(name string, force bool) error {
+	users, err := r.NetworkUsers(name)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	if !force {
+		return errors.Wrapf(define.ErrNetworkBeingUsed, "network %s is being used by the following container(s): %s", name, strings.Join(users, ", "))
+	}
+	for _, id := range users {
+		ctr, err := r.Runtime.GetContainer(id)
+		if err != nil {
+			return err
+		}
+		if err := ctr.NetworkDisconnect(name); err != nil {
+			return errors.Wrapf(err, "error disconnecting container %s from network %s", id, name)
+		}
+	}
+	return nil
+}
+
 // getCNIConfigPathByName finds a CNI network by name and
 // returns its configuration file path
 func getCNIConfigPathByName(name, cniConfigPath string) (string, error) {
@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/containers/buildah/pkg/parse"
@@ -22,6 +23,7 @@ import (
 	"github.com/containers/libpod/pkg/util"
 	"github.com/containers/storage"
 	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/docker/go-units"
 	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -267,6 +269,12 @@ func (r *LocalRuntime) CreatePod(ctx context.Context, cli *cliconfig.PodCreateVa
 			return "", err
 		}
 		options = append(options, nsOptions...)
+		if cli.Flag("infra-image").Changed {
+			options = append(options, libpod.WithInfraContainerImage(cli.InfraImage))
+		}
+		if cli.Flag("infra-command").Changed {
+			options = append(options, libpod.WithInfraContainerCommand(strings.Fields(cli.InfraCommand)))
+		}
 	}
 
 	if len(cli.Publish) > 0 {
@@ -277,6 +285,67 @@ func (r *LocalRuntime) CreatePod(ctx context.Context, cli *cliconfig.PodCreateVa
 		options = append(options, libpod.WithInfraContainerPorts(portBindings))
 
 	}
+
+	if len(cli.Volume) > 0 {
+		volumes, err := shared.CreatePodVolumes(cli.Volume)
+		if err != nil {
+			return "", err
+		}
+		options = append(options, libpod.WithPodVolumes(volumes))
+	}
+
+	if len(cli.Expose) > 0 {
+		exposed, err := shared.CreateExposedPorts(cli.Expose)
+		if err != nil {
+			return "", err
+		}
+		options = append(options, libpod.WithInfraContainerExposedPorts(exposed))
+	}
+
+	if cli.DNSCache {
+		options = append(options, libpod.WithPodDNSCache())
+	}
+
+	if cli.Flag("cpus").Changed || cli.Flag("memory").Changed || cli.Flag("pids-limit").Changed {
+		limits := libpod.PodResourceLimits{PidsLimit: cli.PidsLimit}
+		if cli.CPUs < 0 {
+			return "", errors.Errorf("invalid value %v for --cpus", cli.CPUs)
+		}
+		if cli.CPUs > 0 {
+			limits.CPUPeriod = 100000
+			limits.CPUQuota = int64(cli.CPUs * float64(limits.CPUPeriod))
+		}
+		if cli.Memory != "" {
+			memory, err := units.RAMInBytes(cli.Memory)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid value for --memory")
+			}
+			limits.MemoryLimit = memory
+		}
+		options = append(options, libpod.WithPodResourceLimits(limits))
+	}
+	if cli.Restart != "" {
+		split := strings.SplitN(cli.Restart, ":", 2)
+		var retries uint
+		if len(split) > 1 {
+			numTries, err := strconv.ParseUint(split[1], 10, 0)
+			if err != nil {
+				return "", errors.Wrapf(err, "%s is not a valid number of retries for restart policy", split[1])
+			}
+			retries = uint(numTries)
+		}
+		options = append(options, libpod.WithPodRestartPolicy(split[0], retries))
+		// The infra container must be cleaned up when it exits for the
+		// restart policy to notice
+		if cli.Infra {
+			exitCommand, err := createconfig.CreateExitCommand(r.Runtime, cli.GlobalFlags.Syslog, false)
+			if err != nil {
+				return "", err
+			}
+			options = append(options, libpod.WithInfraContainerExitCommand(exitCommand))
+		}
+	}
+
 	// always have containers use pod cgroups
 	// User Opt out is not yet supported
 	options = append(options, libpod.WithPodCgroups())
@@ -494,6 +563,10 @@ func (r *LocalRuntime) PlayKubeYAML(ctx context.Context, c *cliconfig.KubePlayVa
 	podPorts := getPodPorts(podYAML.Spec.Containers)
 	podOptions = append(podOptions, libpod.WithInfraContainerPorts(podPorts))
 
+	if dnsConfig := kubeDNSToPodDNSConfig(podYAML.Spec); dnsConfig != nil {
+		podOptions = append(podOptions, libpod.WithPodDNSConfig(*dnsConfig))
+	}
+
 	// Create the Pod
 	pod, err = r.NewPod(ctx, podOptions...)
 	if err != nil {
@@ -758,3 +831,32 @@ func kubeContainerToCreateConfig(ctx context.Context, containerYAML v1.Container
 	}
 	return &containerConfig, nil
 }
+
+// kubeDNSToPodDNSConfig converts the DNS policy and configuration of a
+// kubernetes pod spec to the DNS configuration of a pod. There is no cluster
+// DNS, so the ClusterFirst policies fall back to the host's resolv.conf like
+// the Default policy.
+func kubeDNSToPodDNSConfig(spec v1.PodSpec) *libpod.PodDNSConfig {
+	if spec.DNSPolicy != v1.DNSNone && spec.DNSConfig == nil {
+		return nil
+	}
+	dnsConfig := libpod.PodDNSConfig{Policy: libpod.PodDNSPolicyDefault}
+	switch spec.DNSPolicy {
+	case v1.DNSNone:
+		dnsConfig.Policy = libpod.PodDNSPolicyNone
+	case v1.DNSClusterFirst, v1.DNSClusterFirstWithHostNet:
+		logrus.Debugf("No cluster DNS available, using the host's DNS configuration for DNS policy %s", spec.DNSPolicy)
+	}
+	if spec.DNSConfig != nil {
+		dnsConfig.Nameservers = spec.DNSConfig.Nameservers
+		dnsConfig.Searches = spec.DNSConfig.Searches
+		for _, option := range spec.DNSConfig.Options {
+			if option.Value != nil {
+				dnsConfig.Options = append(dnsConfig.Options, fmt.Sprintf("%s:%s", option.Name, *option.Value))
+			} else {
+				dnsConfig.Options = append(dnsConfig.Options, option.Name)
+			}
+		}
+	}
+	return &dnsConfig
+}
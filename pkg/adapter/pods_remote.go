@@ -173,6 +173,15 @@ func (r *LocalRuntime) StartPods(ctx context.Context, cli *cliconfig.PodStartVal
 
 // CreatePod creates a pod for the remote client over a varlink connection
 func (r *LocalRuntime) CreatePod(ctx context.Context, cli *cliconfig.PodCreateValues, labels map[string]string) (string, error) {
+	if cli.DNSCache {
+		return "", errors.New("the remote client does not support --dns-cache")
+	}
+	if len(cli.Expose) > 0 {
+		return "", errors.New("the remote client does not support --expose")
+	}
+	if len(cli.Volume) > 0 {
+		return "", errors.New("the remote client does not support --volume")
+	}
 	var share []string
 	if cli.Share != "" {
 		share = strings.Split(cli.Share, ",")
@@ -183,10 +192,14 @@ func (r *LocalRuntime) CreatePod(ctx context.Context, cli *cliconfig.PodCreateVa
 		Labels:       labels,
 		Share:        share,
 		Infra:        cli.Infra,
-		InfraCommand: cli.InfraCommand,
-		InfraImage:   cli.InfraCommand,
 		Publish:      cli.Publish,
 	}
+	if cli.Flag("infra-image").Changed {
+		pc.InfraImage = cli.InfraImage
+	}
+	if cli.Flag("infra-command").Changed {
+		pc.InfraCommand = cli.InfraCommand
+	}
 
 	return iopodman.CreatePod().Call(r.Conn, pc)
 }
@@ -473,13 +486,18 @@ func (r *LocalRuntime) GetStatPods(c *cliconfig.PodStatsValues) ([]*Pod, error)
 	return pods, nil
 }
 
-// GetPodStats returns the stats for each of its containers
-func (p *Pod) GetPodStats(previousContainerStats map[string]*libpod.ContainerStats) (map[string]*libpod.ContainerStats, error) {
+// GetPodStats returns the stats of the running containers of the pod, merged
+// and per container
+func (p *Pod) GetPodStats(previousContainerStats map[string]*libpod.ContainerStats) (*libpod.PodStats, error) {
 	var (
 		ok       bool
 		prevStat *libpod.ContainerStats
 	)
-	newContainerStats := make(map[string]*libpod.ContainerStats)
+	podStats := &libpod.PodStats{
+		PodID:      p.ID(),
+		Name:       p.Name(),
+		Containers: make(map[string]*libpod.ContainerStats),
+	}
 	containers, err := p.AllContainers()
 	if err != nil {
 		return nil, err
@@ -514,10 +532,16 @@ func (p *Pod) GetPodStats(previousContainerStats map[string]*libpod.ContainerSta
 			return nil, err
 		}
 		if err == nil {
-			newContainerStats[c.ID()] = &newStats
+			newStats.ContainerID = c.ID()
+			podStats.AddContainerStats(&newStats, c.config.NetNsCtr == "")
 		}
 	}
-	return newContainerStats, nil
+	if p.config.ResourceLimits != nil && p.config.ResourceLimits.MemoryLimit > 0 {
+		podStats.SetMemLimit(uint64(p.config.ResourceLimits.MemoryLimit))
+	} else {
+		podStats.SetMemLimit(podStats.MemLimit)
+	}
+	return podStats, nil
 }
 
 // RemovePod removes a pod
@@ -185,7 +185,7 @@ func (r *LocalRuntime) CreateVolume(ctx context.Context, c *cliconfig.VolumeCrea
 		options = append(options, libpod.WithVolumeLabels(labels))
 	}
 
-	if len(options) != 0 {
+	if len(opts) != 0 {
 		options = append(options, libpod.WithVolumeOptions(opts))
 	}
 	newVolume, err := r.NewVolume(ctx, options...)
@@ -317,8 +317,12 @@ func (r *LocalRuntime) Build(ctx context.Context, c *cliconfig.BuildValues, opti
 }
 
 // PruneVolumes is a wrapper function for libpod PruneVolumes
-func (r *LocalRuntime) PruneVolumes(ctx context.Context) ([]string, []error) {
-	return r.Runtime.PruneVolumes(ctx)
+func (r *LocalRuntime) PruneVolumes(ctx context.Context, filters []string) ([]string, uint64, []error) {
+	filterFuncs, err := shared.GenerateVolumePruneFilters(filters)
+	if err != nil {
+		return nil, 0, []error{err}
+	}
+	return r.Runtime.PruneVolumes(ctx, filterFuncs...)
 }
 
 // SaveImage is a wrapper function for saving an image to the local filesystem
@@ -683,17 +683,17 @@ func varlinkVolumeToVolume(r *LocalRuntime, volumes []iopodman.Volume) []*Volume
 }
 
 // PruneVolumes removes all unused volumes from the remote system
-func (r *LocalRuntime) PruneVolumes(ctx context.Context) ([]string, []error) {
+func (r *LocalRuntime) PruneVolumes(ctx context.Context, filters []string) ([]string, uint64, []error) {
 	var errs []error
-	prunedNames, prunedErrors, err := iopodman.VolumesPrune().Call(r.Conn)
+	prunedNames, reclaimed, prunedErrors, err := iopodman.VolumesPrune().Call(r.Conn, filters)
 	if err != nil {
-		return []string{}, []error{err}
+		return []string{}, 0, []error{err}
 	}
 	// We need to transform the string results of the error into actual error types
 	for _, e := range prunedErrors {
 		errs = append(errs, errors.New(e))
 	}
-	return prunedNames, errs
+	return prunedNames, uint64(reclaimed), errs
 }
 
 // SaveImage is a wrapper function for saving an image to the local filesystem
@@ -0,0 +1,338 @@
+package grpc
+
+// The messages of podman.proto. They are encoded by golang/protobuf through
+// their struct tags, as generated code would be.
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// VersionRequest is the request of Version
+type VersionRequest struct {
+}
+
+func (m *VersionRequest) Reset()         { *m = VersionRequest{} }
+func (m *VersionRequest) String() string { return proto.CompactTextString(m) }
+func (*VersionRequest) ProtoMessage()    {}
+
+// VersionResponse is the version of Podman serving the API
+type VersionResponse struct {
+	Version    string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	APIVersion int64  `protobuf:"varint,2,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	GoVersion  string `protobuf:"bytes,3,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	GitCommit  string `protobuf:"bytes,4,opt,name=git_commit,json=gitCommit,proto3" json:"git_commit,omitempty"`
+	Built      int64  `protobuf:"varint,5,opt,name=built,proto3" json:"built,omitempty"`
+	OsArch     string `protobuf:"bytes,6,opt,name=os_arch,json=osArch,proto3" json:"os_arch,omitempty"`
+}
+
+func (m *VersionResponse) Reset()         { *m = VersionResponse{} }
+func (m *VersionResponse) String() string { return proto.CompactTextString(m) }
+func (*VersionResponse) ProtoMessage()    {}
+
+// ListContainersRequest is the request of ListContainers
+type ListContainersRequest struct {
+	All bool `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+	// Labels selects the containers having all the labels
+	Labels map[string]string `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *ListContainersRequest) Reset()         { *m = ListContainersRequest{} }
+func (m *ListContainersRequest) String() string { return proto.CompactTextString(m) }
+func (*ListContainersRequest) ProtoMessage()    {}
+
+// ListContainersResponse is the answer to ListContainers
+type ListContainersResponse struct {
+	Containers []*Container `protobuf:"bytes,1,rep,name=containers,proto3" json:"containers,omitempty"`
+}
+
+func (m *ListContainersResponse) Reset()         { *m = ListContainersResponse{} }
+func (m *ListContainersResponse) String() string { return proto.CompactTextString(m) }
+func (*ListContainersResponse) ProtoMessage()    {}
+
+// Container is the configuration and state of a container
+type Container struct {
+	ID         string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name       string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ImageID    string            `protobuf:"bytes,3,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	ImageName  string            `protobuf:"bytes,4,opt,name=image_name,json=imageName,proto3" json:"image_name,omitempty"`
+	Command    []string          `protobuf:"bytes,5,rep,name=command,proto3" json:"command,omitempty"`
+	State      string            `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	PID        int32             `protobuf:"varint,7,opt,name=pid,proto3" json:"pid,omitempty"`
+	ExitCode   int32             `protobuf:"varint,8,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	CreatedAt  int64             `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt  int64             `protobuf:"varint,10,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt int64             `protobuf:"varint,11,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Labels     map[string]string `protobuf:"bytes,12,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	PodID      string            `protobuf:"bytes,13,opt,name=pod_id,json=podId,proto3" json:"pod_id,omitempty"`
+}
+
+func (m *Container) Reset()         { *m = Container{} }
+func (m *Container) String() string { return proto.CompactTextString(m) }
+func (*Container) ProtoMessage()    {}
+
+// ContainerRequest is the request of the methods acting on a container
+type ContainerRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+}
+
+func (m *ContainerRequest) Reset()         { *m = ContainerRequest{} }
+func (m *ContainerRequest) String() string { return proto.CompactTextString(m) }
+func (*ContainerRequest) ProtoMessage()    {}
+
+// StopContainerRequest is the request of StopContainer
+type StopContainerRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+	// Timeout is in seconds, the stop timeout of the container if 0
+	Timeout int64 `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (m *StopContainerRequest) Reset()         { *m = StopContainerRequest{} }
+func (m *StopContainerRequest) String() string { return proto.CompactTextString(m) }
+func (*StopContainerRequest) ProtoMessage()    {}
+
+// KillContainerRequest is the request of KillContainer
+type KillContainerRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+	// Signal is a name or number, SIGKILL if empty
+	Signal string `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+}
+
+func (m *KillContainerRequest) Reset()         { *m = KillContainerRequest{} }
+func (m *KillContainerRequest) String() string { return proto.CompactTextString(m) }
+func (*KillContainerRequest) ProtoMessage()    {}
+
+// RemoveContainerRequest is the request of RemoveContainer
+type RemoveContainerRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+	// Force stops the container if it is running
+	Force bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	// Volumes removes the anonymous volumes of the container
+	Volumes bool `protobuf:"varint,3,opt,name=volumes,proto3" json:"volumes,omitempty"`
+}
+
+func (m *RemoveContainerRequest) Reset()         { *m = RemoveContainerRequest{} }
+func (m *RemoveContainerRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveContainerRequest) ProtoMessage()    {}
+
+// ContainerResponse is the answer to the methods acting on a container
+type ContainerResponse struct {
+	ID string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (m *ContainerResponse) Reset()         { *m = ContainerResponse{} }
+func (m *ContainerResponse) String() string { return proto.CompactTextString(m) }
+func (*ContainerResponse) ProtoMessage()    {}
+
+// ListPodsRequest is the request of ListPods
+type ListPodsRequest struct {
+	// Labels selects the pods having all the labels
+	Labels map[string]string `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *ListPodsRequest) Reset()         { *m = ListPodsRequest{} }
+func (m *ListPodsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListPodsRequest) ProtoMessage()    {}
+
+// ListPodsResponse is the answer to ListPods
+type ListPodsResponse struct {
+	Pods []*Pod `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
+}
+
+func (m *ListPodsResponse) Reset()         { *m = ListPodsResponse{} }
+func (m *ListPodsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListPodsResponse) ProtoMessage()    {}
+
+// Pod is the configuration and state of a pod
+type Pod struct {
+	ID               string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name             string            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt        int64             `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Labels           map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	InfraContainerID string            `protobuf:"bytes,5,opt,name=infra_container_id,json=infraContainerId,proto3" json:"infra_container_id,omitempty"`
+	// Containers maps the IDs of the containers of the pod to their state
+	Containers map[string]string `protobuf:"bytes,6,rep,name=containers,proto3" json:"containers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *Pod) Reset()         { *m = Pod{} }
+func (m *Pod) String() string { return proto.CompactTextString(m) }
+func (*Pod) ProtoMessage()    {}
+
+// PodRequest is the request of the methods acting on a pod
+type PodRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+}
+
+func (m *PodRequest) Reset()         { *m = PodRequest{} }
+func (m *PodRequest) String() string { return proto.CompactTextString(m) }
+func (*PodRequest) ProtoMessage()    {}
+
+// StopPodRequest is the request of StopPod
+type StopPodRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+	// Timeout is in seconds, the stop timeout of each container if 0
+	Timeout int64 `protobuf:"varint,2,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (m *StopPodRequest) Reset()         { *m = StopPodRequest{} }
+func (m *StopPodRequest) String() string { return proto.CompactTextString(m) }
+func (*StopPodRequest) ProtoMessage()    {}
+
+// RemovePodRequest is the request of RemovePod
+type RemovePodRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+	// Force stops the containers of the pod if they are running
+	Force bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+}
+
+func (m *RemovePodRequest) Reset()         { *m = RemovePodRequest{} }
+func (m *RemovePodRequest) String() string { return proto.CompactTextString(m) }
+func (*RemovePodRequest) ProtoMessage()    {}
+
+// PodResponse is the answer to the methods acting on a pod
+type PodResponse struct {
+	ID string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Errors maps the IDs of the containers the operation failed on to their
+	// error
+	Errors map[string]string `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *PodResponse) Reset()         { *m = PodResponse{} }
+func (m *PodResponse) String() string { return proto.CompactTextString(m) }
+func (*PodResponse) ProtoMessage()    {}
+
+// ListImagesRequest is the request of ListImages
+type ListImagesRequest struct {
+	// All lists the intermediate images as well
+	All bool `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+}
+
+func (m *ListImagesRequest) Reset()         { *m = ListImagesRequest{} }
+func (m *ListImagesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListImagesRequest) ProtoMessage()    {}
+
+// ListImagesResponse is the answer to ListImages
+type ListImagesResponse struct {
+	Images []*Image `protobuf:"bytes,1,rep,name=images,proto3" json:"images,omitempty"`
+}
+
+func (m *ListImagesResponse) Reset()         { *m = ListImagesResponse{} }
+func (m *ListImagesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListImagesResponse) ProtoMessage()    {}
+
+// Image is an image of the local storage
+type Image struct {
+	ID        string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Names     []string          `protobuf:"bytes,2,rep,name=names,proto3" json:"names,omitempty"`
+	CreatedAt int64             `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Size      uint64            `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *Image) Reset()         { *m = Image{} }
+func (m *Image) String() string { return proto.CompactTextString(m) }
+func (*Image) ProtoMessage()    {}
+
+// RemoveImageRequest is the request of RemoveImage
+type RemoveImageRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+	// Force removes the containers using the image
+	Force bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+}
+
+func (m *RemoveImageRequest) Reset()         { *m = RemoveImageRequest{} }
+func (m *RemoveImageRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveImageRequest) ProtoMessage()    {}
+
+// RemoveImageResponse is the answer to RemoveImage
+type RemoveImageResponse struct {
+	Untagged []string `protobuf:"bytes,1,rep,name=untagged,proto3" json:"untagged,omitempty"`
+	Deleted  []string `protobuf:"bytes,2,rep,name=deleted,proto3" json:"deleted,omitempty"`
+}
+
+func (m *RemoveImageResponse) Reset()         { *m = RemoveImageResponse{} }
+func (m *RemoveImageResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveImageResponse) ProtoMessage()    {}
+
+// EventsRequest is the request of Events
+type EventsRequest struct {
+	Since int64 `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	Until int64 `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"`
+	// Filters are the filters of podman events, such as container=NAME
+	Filters []string `protobuf:"bytes,3,rep,name=filters,proto3" json:"filters,omitempty"`
+}
+
+func (m *EventsRequest) Reset()         { *m = EventsRequest{} }
+func (m *EventsRequest) String() string { return proto.CompactTextString(m) }
+func (*EventsRequest) ProtoMessage()    {}
+
+// Event is an event of the runtime
+type Event struct {
+	Type       string            `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Status     string            `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	ID         string            `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	Name       string            `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Image      string            `protobuf:"bytes,5,opt,name=image,proto3" json:"image,omitempty"`
+	Time       int64             `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	ExitCode   int32             `protobuf:"varint,7,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	Attributes map[string]string `protobuf:"bytes,8,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+
+// LogsRequest is the request of Logs
+type LogsRequest struct {
+	NameOrID string `protobuf:"bytes,1,opt,name=name_or_id,json=nameOrId,proto3" json:"name_or_id,omitempty"`
+	Follow   bool   `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
+	Since    int64  `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until    int64  `protobuf:"varint,4,opt,name=until,proto3" json:"until,omitempty"`
+	// Tail is the number of lines sent from the end of the log, all if 0
+	Tail uint64 `protobuf:"varint,5,opt,name=tail,proto3" json:"tail,omitempty"`
+}
+
+func (m *LogsRequest) Reset()         { *m = LogsRequest{} }
+func (m *LogsRequest) String() string { return proto.CompactTextString(m) }
+func (*LogsRequest) ProtoMessage()    {}
+
+// LogLine is a line of the log of a container
+type LogLine struct {
+	// Stream is stdout or stderr
+	Stream  string `protobuf:"bytes,1,opt,name=stream,proto3" json:"stream,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Time    int64  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (m *LogLine) Reset()         { *m = LogLine{} }
+func (m *LogLine) String() string { return proto.CompactTextString(m) }
+func (*LogLine) ProtoMessage()    {}
+
+// StatsRequest is the request of Stats
+type StatsRequest struct {
+	// NamesOrIDs selects the containers, all the running ones if empty
+	NamesOrIDs []string `protobuf:"bytes,1,rep,name=names_or_ids,json=namesOrIds,proto3" json:"names_or_ids,omitempty"`
+	Stream     bool     `protobuf:"varint,2,opt,name=stream,proto3" json:"stream,omitempty"`
+}
+
+func (m *StatsRequest) Reset()         { *m = StatsRequest{} }
+func (m *StatsRequest) String() string { return proto.CompactTextString(m) }
+func (*StatsRequest) ProtoMessage()    {}
+
+// ContainerStats is the resource usage of a running container
+type ContainerStats struct {
+	ID          string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	CPUPercent  float64 `protobuf:"fixed64,3,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	MemUsage    uint64  `protobuf:"varint,4,opt,name=mem_usage,json=memUsage,proto3" json:"mem_usage,omitempty"`
+	MemLimit    uint64  `protobuf:"varint,5,opt,name=mem_limit,json=memLimit,proto3" json:"mem_limit,omitempty"`
+	MemPercent  float64 `protobuf:"fixed64,6,opt,name=mem_percent,json=memPercent,proto3" json:"mem_percent,omitempty"`
+	NetInput    uint64  `protobuf:"varint,7,opt,name=net_input,json=netInput,proto3" json:"net_input,omitempty"`
+	NetOutput   uint64  `protobuf:"varint,8,opt,name=net_output,json=netOutput,proto3" json:"net_output,omitempty"`
+	BlockInput  uint64  `protobuf:"varint,9,opt,name=block_input,json=blockInput,proto3" json:"block_input,omitempty"`
+	BlockOutput uint64  `protobuf:"varint,10,opt,name=block_output,json=blockOutput,proto3" json:"block_output,omitempty"`
+	PIDs        uint64  `protobuf:"varint,11,opt,name=pids,proto3" json:"pids,omitempty"`
+}
+
+func (m *ContainerStats) Reset()         { *m = ContainerStats{} }
+func (m *ContainerStats) String() string { return proto.CompactTextString(m) }
+func (*ContainerStats) ProtoMessage()    {}
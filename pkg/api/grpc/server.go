@@ -0,0 +1,263 @@
+// Package grpc serves the gRPC API defined by podman.proto, an alternative to
+// the REST API for orchestrators. It speaks the gRPC protocol over the HTTP/2
+// server of golang.org/x/net, with the messages encoded by golang/protobuf.
+// Clients authenticate with TLS certificates, or with the permissions of the
+// unix socket the API is served on.
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+)
+
+const (
+	// ServiceName is the full name of the service of podman.proto
+	ServiceName = "podman.v1.Runtime"
+	// maxMessageSize is the size of the largest request message accepted
+	maxMessageSize = 4 << 20
+	// handshakeTimeout is the time clients have to complete the TLS
+	// handshake
+	handshakeTimeout = 10 * time.Second
+)
+
+// method is a method of the service. Methods read their single request with
+// recv, and send their answers with send, once for unary methods.
+type method func(ctx context.Context, recv, send func(proto.Message) error) error
+
+// Server serves the gRPC API
+type Server struct {
+	runtime  *libpod.Runtime
+	listener net.Listener
+	// methods are the methods of the service by name
+	methods map[string]method
+	http2   http2.Server
+}
+
+// NewServer returns a server of the gRPC API of the runtime on the listener.
+// TLS listeners must offer HTTP/2, as h2, through ALPN.
+func NewServer(runtime *libpod.Runtime, listener net.Listener) *Server {
+	s := &Server{runtime: runtime, listener: listener}
+	s.methods = s.runtimeMethods()
+	return s
+}
+
+// Serve serves the API until the context is canceled. Unix sockets are
+// served HTTP/2 without TLS.
+func (s *Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		s.listener.Close()
+	}()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(err, "error accepting connection")
+		}
+		go s.serveConn(ctx, conn)
+	}
+}
+
+// serveConn serves the requests of a connection until the context is
+// canceled
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := tlsConn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+			return
+		}
+		if err := tlsConn.Handshake(); err != nil {
+			logrus.Debugf("TLS handshake with %s failed: %v", conn.RemoteAddr(), err)
+			return
+		}
+		if err := tlsConn.SetDeadline(time.Time{}); err != nil {
+			return
+		}
+		state := tlsConn.ConnectionState()
+		if state.NegotiatedProtocol != http2.NextProtoTLS {
+			logrus.Debugf("Client %s does not support HTTP/2", conn.RemoteAddr())
+			return
+		}
+		if len(state.PeerCertificates) > 0 {
+			logrus.Debugf("Serving gRPC API to %q", state.PeerCertificates[0].Subject.CommonName)
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	s.http2.ServeConn(conn, &http2.ServeConnOpts{Context: ctx, Handler: s})
+}
+
+// ServeHTTP serves a gRPC request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if r.Method != http.MethodPost || (contentType != "application/grpc" && contentType != "application/grpc+proto") {
+		http.Error(w, "only gRPC requests are served", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Accept-Encoding", "identity")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+
+	ctx := r.Context()
+	if value := r.Header.Get("Grpc-Timeout"); value != "" {
+		timeout, err := parseTimeout(value)
+		if err != nil {
+			writeStatus(w, r, err)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	writeStatus(w, r, s.call(ctx, w, r))
+}
+
+// call calls the method of the request
+func (s *Server) call(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	name := strings.TrimPrefix(r.URL.Path, "/"+ServiceName+"/")
+	m, ok := s.methods[name]
+	if !ok || name == r.URL.Path {
+		return statusErrorf(codeUnimplemented, "unknown method %s", r.URL.Path)
+	}
+	if encoding := r.Header.Get("Grpc-Encoding"); encoding != "" && encoding != "identity" {
+		return statusErrorf(codeUnimplemented, "unsupported message encoding %q", encoding)
+	}
+
+	received := false
+	recv := func(msg proto.Message) error {
+		if received {
+			return statusErrorf(codeInternal, "request of %s already received", name)
+		}
+		received = true
+		return readMessage(r.Body, msg)
+	}
+	flusher, _ := w.(http.Flusher)
+	send := func(msg proto.Message) error {
+		if err := writeMessage(w, msg); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+	return m(ctx, recv, send)
+}
+
+// readMessage reads a length-prefixed message
+func readMessage(r io.Reader, msg proto.Message) error {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return statusErrorf(codeInvalidArgument, "missing request message")
+		}
+		return errors.Wrapf(err, "error reading request message")
+	}
+	if header[0] != 0 {
+		return statusErrorf(codeUnimplemented, "compressed messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxMessageSize {
+		return statusErrorf(codeResourceExhausted, "request message of %d bytes exceeds the maximum of %d", length, maxMessageSize)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return errors.Wrapf(err, "error reading request message")
+	}
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return statusErrorf(codeInvalidArgument, "invalid request message: %v", err)
+	}
+	return nil
+}
+
+// writeMessage writes a length-prefixed message
+func writeMessage(w io.Writer, msg proto.Message) error {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return errors.Wrapf(err, "error encoding message")
+	}
+	frame := make([]byte, 5+len(b))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(b)))
+	copy(frame[5:], b)
+	_, err = w.Write(frame)
+	return err
+}
+
+// parseTimeout parses the value of the grpc-timeout header
+func parseTimeout(value string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	if len(value) < 2 || len(value) > 9 {
+		return 0, statusErrorf(codeInvalidArgument, "invalid timeout %q", value)
+	}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, statusErrorf(codeInvalidArgument, "invalid timeout %q", value)
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, statusErrorf(codeInvalidArgument, "invalid timeout %q", value)
+	}
+	return time.Duration(amount) * unit, nil
+}
+
+// writeStatus sets the status of the call as the trailers of the response
+func writeStatus(w http.ResponseWriter, r *http.Request, err error) {
+	code, message := errorStatus(err)
+	switch code {
+	case codeOK:
+	case codeUnknown, codeInternal:
+		logrus.Errorf("Error serving gRPC call %s: %v", r.URL.Path, err)
+	default:
+		logrus.Debugf("gRPC call %s failed: %v", r.URL.Path, err)
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(int(code)))
+	if message != "" {
+		w.Header().Set("Grpc-Message", encodeStatusMessage(message))
+	}
+}
+
+// encodeStatusMessage percent-encodes the status message, as required in the
+// grpc-message header
+func encodeStatusMessage(message string) string {
+	var b strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
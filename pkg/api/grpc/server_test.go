@@ -0,0 +1,230 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+// testMethods are methods exercising the protocol without a runtime
+var testMethods = map[string]method{
+	"Echo": func(ctx context.Context, recv, send func(proto.Message) error) error {
+		var req ContainerRequest
+		if err := recv(&req); err != nil {
+			return err
+		}
+		return send(&ContainerResponse{ID: req.NameOrID})
+	},
+	"Stream": func(ctx context.Context, recv, send func(proto.Message) error) error {
+		var req LogsRequest
+		if err := recv(&req); err != nil {
+			return err
+		}
+		for i := uint64(0); i < req.Tail; i++ {
+			if err := send(&LogLine{Stream: "stdout", Message: req.NameOrID}); err != nil {
+				return err
+			}
+		}
+		return errors.Wrapf(define.ErrNoSuchCtr, "%s gone", req.NameOrID)
+	},
+}
+
+// frame returns the message as a length-prefixed frame
+func frame(t *testing.T, msg proto.Message) []byte {
+	var b bytes.Buffer
+	require.NoError(t, writeMessage(&b, msg))
+	return b.Bytes()
+}
+
+// testCall calls the method, and returns the response and the frames of its
+// body
+func testCall(t *testing.T, client *http.Client, url string, body []byte) (*http.Response, [][]byte) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var frames [][]byte
+	for {
+		var header [5]byte
+		_, err := io.ReadFull(resp.Body, header[:])
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		b := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		_, err = io.ReadFull(resp.Body, b)
+		require.NoError(t, err)
+		frames = append(frames, b)
+	}
+	return resp, frames
+}
+
+func TestServerUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "grpc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "podman.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{listener: listener, methods: testMethods}
+	go server.Serve(ctx)
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, config *tls.Config) (net.Conn, error) {
+			return net.Dial("unix", path)
+		},
+	}}
+
+	resp, frames := testCall(t, client, "http://d/podman.v1.Runtime/Echo", frame(t, &ContainerRequest{NameOrID: "web"}))
+	assert.Equal(t, "application/grpc", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+	require.Len(t, frames, 1)
+	var echo ContainerResponse
+	require.NoError(t, proto.Unmarshal(frames[0], &echo))
+	assert.Equal(t, "web", echo.ID)
+
+	resp, frames = testCall(t, client, "http://d/podman.v1.Runtime/Stream", frame(t, &LogsRequest{NameOrID: "web", Tail: 3}))
+	assert.Len(t, frames, 3)
+	assert.Equal(t, "5", resp.Trailer.Get("Grpc-Status"))
+	assert.Equal(t, "web gone: no such container", resp.Trailer.Get("Grpc-Message"))
+
+	resp, _ = testCall(t, client, "http://d/podman.v1.Runtime/Missing", frame(t, &ContainerRequest{}))
+	assert.Equal(t, "12", resp.Trailer.Get("Grpc-Status"))
+
+	resp, _ = testCall(t, client, "http://d/podman.v1.Runtime/Echo", nil)
+	assert.Equal(t, "3", resp.Trailer.Get("Grpc-Status"))
+
+	compressed := frame(t, &ContainerRequest{NameOrID: "web"})
+	compressed[0] = 1
+	resp, _ = testCall(t, client, "http://d/podman.v1.Runtime/Echo", compressed)
+	assert.Equal(t, "12", resp.Trailer.Get("Grpc-Status"))
+}
+
+// testCertificate returns a certificate signed by the parent, or self-signed
+// if parent is nil
+func testCertificate(t *testing.T, name string, parent *tls.Certificate, isCA bool) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	ca := testCertificate(t, "ca", nil, true)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener = tls.NewListener(listener, &tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t, "server", &ca, false)},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		NextProtos:   []string{http2.NextProtoTLS},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{listener: listener, methods: testMethods}
+	go server.Serve(ctx)
+	url := "https://" + listener.Addr().String() + "/podman.v1.Runtime/Echo"
+
+	client := &http.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{testCertificate(t, "client", &ca, false)},
+	}}}
+	resp, frames := testCall(t, client, url, frame(t, &ContainerRequest{NameOrID: "web"}))
+	assert.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+	assert.Len(t, frames, 1)
+
+	// Clients must present a certificate signed by the CA
+	for _, certificates := range [][]tls.Certificate{nil, {testCertificate(t, "other", nil, false)}} {
+		client = &http.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      pool,
+			Certificates: certificates,
+		}}}
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(frame(t, &ContainerRequest{})))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/grpc")
+		_, err = client.Do(req)
+		assert.Error(t, err)
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	for value, timeout := range map[string]time.Duration{
+		"1H":   time.Hour,
+		"30S":  30 * time.Second,
+		"100m": 100 * time.Millisecond,
+		"5n":   5 * time.Nanosecond,
+	} {
+		parsed, err := parseTimeout(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, timeout, parsed, value)
+	}
+	for _, value := range []string{"", "S", "10", "10x", "-1S", "1234567890S"} {
+		_, err := parseTimeout(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	for err, expected := range map[error]code{
+		nil:                                      codeOK,
+		errors.Wrapf(define.ErrNoSuchPod, "pod"): codeNotFound,
+		errors.Wrapf(define.ErrCtrStateInvalid, "container"): codeFailedPrecondition,
+		define.ErrInvalidArg: codeInvalidArgument,
+		context.Canceled:     codeCanceled,
+		statusErrorf(codeResourceExhausted, "too large"): codeResourceExhausted,
+		errors.New("failure"):                            codeUnknown,
+	} {
+		c, _ := errorStatus(err)
+		assert.Equal(t, expected, c, "%v", err)
+	}
+	assert.Equal(t, "caf%C3%A9 100%25", encodeStatusMessage("café 100%"))
+}
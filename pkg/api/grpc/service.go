@@ -0,0 +1,632 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	"github.com/containers/libpod/libpod/image"
+	"github.com/containers/libpod/libpod/logs"
+	"github.com/docker/docker/pkg/signal"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// statsInterval is the interval between the stats streamed by Stats
+const statsInterval = time.Second
+
+// logChannelSize is the number of log lines read ahead of those sent to the
+// client
+const logChannelSize = 64
+
+// runtimeMethods returns the methods of the service of podman.proto
+func (s *Server) runtimeMethods() map[string]method {
+	return map[string]method{
+		"Version":          s.version,
+		"ListContainers":   s.listContainers,
+		"InspectContainer": s.inspectContainer,
+		"StartContainer":   s.startContainer,
+		"StopContainer":    s.stopContainer,
+		"KillContainer":    s.killContainer,
+		"RemoveContainer":  s.removeContainer,
+		"ListPods":         s.listPods,
+		"StartPod":         s.startPod,
+		"StopPod":          s.stopPod,
+		"RemovePod":        s.removePod,
+		"ListImages":       s.listImages,
+		"RemoveImage":      s.removeImage,
+		"Events":           s.events,
+		"Logs":             s.logs,
+		"Stats":            s.stats,
+	}
+}
+
+// unixNano returns the time in nanoseconds since the Unix epoch, or 0 for the
+// zero time
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano returns the time of the nanoseconds since the Unix epoch, or
+// the zero time for 0
+func fromUnixNano(nsec int64) time.Time {
+	if nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nsec)
+}
+
+// hasLabels returns whether the labels include all the selected ones
+func hasLabels(labels, selected map[string]string) bool {
+	for k, v := range selected {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) version(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req VersionRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	version, err := define.GetVersion()
+	if err != nil {
+		return err
+	}
+	return send(&VersionResponse{
+		Version:    version.Version,
+		APIVersion: version.RemoteAPIVersion,
+		GoVersion:  version.GoVersion,
+		GitCommit:  version.GitCommit,
+		Built:      version.Built,
+		OsArch:     version.OsArch,
+	})
+}
+
+// containerMessage returns the configuration and state of the container
+func containerMessage(ctr *libpod.Container) (*Container, error) {
+	state, err := ctr.State()
+	if err != nil {
+		return nil, err
+	}
+	pid, err := ctr.PID()
+	if err != nil {
+		return nil, err
+	}
+	exitCode, _, err := ctr.ExitCode()
+	if err != nil {
+		return nil, err
+	}
+	started, err := ctr.StartedTime()
+	if err != nil {
+		return nil, err
+	}
+	finished, err := ctr.FinishedTime()
+	if err != nil {
+		return nil, err
+	}
+	imageID, imageName := ctr.Image()
+	return &Container{
+		ID:         ctr.ID(),
+		Name:       ctr.Name(),
+		ImageID:    imageID,
+		ImageName:  imageName,
+		Command:    ctr.Command(),
+		State:      state.String(),
+		PID:        int32(pid),
+		ExitCode:   exitCode,
+		CreatedAt:  unixNano(ctr.CreatedTime()),
+		StartedAt:  unixNano(started),
+		FinishedAt: unixNano(finished),
+		Labels:     ctr.Labels(),
+		PodID:      ctr.PodID(),
+	}, nil
+}
+
+func (s *Server) listContainers(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req ListContainersRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	ctrs, err := s.runtime.GetContainers(func(c *libpod.Container) bool {
+		if !req.All {
+			state, err := c.State()
+			if err != nil || state != define.ContainerStateRunning {
+				return false
+			}
+		}
+		return hasLabels(c.Labels(), req.Labels)
+	})
+	if err != nil {
+		return err
+	}
+	resp := &ListContainersResponse{Containers: make([]*Container, 0, len(ctrs))}
+	for _, ctr := range ctrs {
+		msg, err := containerMessage(ctr)
+		if err != nil {
+			if errors.Cause(err) == define.ErrNoSuchCtr || errors.Cause(err) == define.ErrCtrRemoved {
+				continue
+			}
+			return err
+		}
+		resp.Containers = append(resp.Containers, msg)
+	}
+	return send(resp)
+}
+
+func (s *Server) inspectContainer(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req ContainerRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	ctr, err := s.runtime.LookupContainer(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	msg, err := containerMessage(ctr)
+	if err != nil {
+		return err
+	}
+	return send(msg)
+}
+
+func (s *Server) startContainer(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req ContainerRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	ctr, err := s.runtime.LookupContainer(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	if err := ctr.Start(ctx, true); err != nil {
+		return err
+	}
+	return send(&ContainerResponse{ID: ctr.ID()})
+}
+
+func (s *Server) stopContainer(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req StopContainerRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	if req.Timeout < 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "invalid timeout %d", req.Timeout)
+	}
+	ctr, err := s.runtime.LookupContainer(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	timeout := ctr.StopTimeout()
+	if req.Timeout > 0 {
+		timeout = uint(req.Timeout)
+	}
+	if err := ctr.StopWithTimeout(timeout); err != nil {
+		return err
+	}
+	return send(&ContainerResponse{ID: ctr.ID()})
+}
+
+func (s *Server) killContainer(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req KillContainerRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	sig := req.Signal
+	if sig == "" {
+		sig = "KILL"
+	}
+	parsed, err := signal.ParseSignal(sig)
+	if err != nil {
+		return errors.Wrapf(define.ErrInvalidArg, "%v", err)
+	}
+	ctr, err := s.runtime.LookupContainer(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	if err := ctr.Kill(uint(parsed)); err != nil {
+		return err
+	}
+	return send(&ContainerResponse{ID: ctr.ID()})
+}
+
+func (s *Server) removeContainer(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req RemoveContainerRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	ctr, err := s.runtime.LookupContainer(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	if err := s.runtime.RemoveContainer(ctx, ctr, req.Force, req.Volumes); err != nil {
+		return err
+	}
+	return send(&ContainerResponse{ID: ctr.ID()})
+}
+
+// podMessage returns the configuration and state of the pod
+func podMessage(pod *libpod.Pod) (*Pod, error) {
+	infraID, err := pod.InfraContainerID()
+	if err != nil {
+		return nil, err
+	}
+	status, err := pod.Status()
+	if err != nil {
+		return nil, err
+	}
+	containers := make(map[string]string, len(status))
+	for id, state := range status {
+		containers[id] = state.String()
+	}
+	return &Pod{
+		ID:               pod.ID(),
+		Name:             pod.Name(),
+		CreatedAt:        unixNano(pod.CreatedTime()),
+		Labels:           pod.Labels(),
+		InfraContainerID: infraID,
+		Containers:       containers,
+	}, nil
+}
+
+// podResponse returns the answer to an operation on the pod, reporting the
+// containers it failed on
+func podResponse(pod *libpod.Pod, ctrErrs map[string]error, err error) (*PodResponse, error) {
+	if err != nil && len(ctrErrs) == 0 {
+		return nil, err
+	}
+	resp := &PodResponse{ID: pod.ID()}
+	if len(ctrErrs) > 0 {
+		resp.Errors = make(map[string]string, len(ctrErrs))
+		for id, ctrErr := range ctrErrs {
+			resp.Errors[id] = ctrErr.Error()
+		}
+	}
+	return resp, nil
+}
+
+func (s *Server) listPods(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req ListPodsRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	pods, err := s.runtime.Pods(func(p *libpod.Pod) bool {
+		return hasLabels(p.Labels(), req.Labels)
+	})
+	if err != nil {
+		return err
+	}
+	resp := &ListPodsResponse{Pods: make([]*Pod, 0, len(pods))}
+	for _, pod := range pods {
+		msg, err := podMessage(pod)
+		if err != nil {
+			if errors.Cause(err) == define.ErrNoSuchPod || errors.Cause(err) == define.ErrPodRemoved {
+				continue
+			}
+			return err
+		}
+		resp.Pods = append(resp.Pods, msg)
+	}
+	return send(resp)
+}
+
+func (s *Server) startPod(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req PodRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	pod, err := s.runtime.LookupPod(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	ctrErrs, err := pod.Start(ctx)
+	resp, err := podResponse(pod, ctrErrs, err)
+	if err != nil {
+		return err
+	}
+	return send(resp)
+}
+
+func (s *Server) stopPod(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req StopPodRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	if req.Timeout < 0 {
+		return errors.Wrapf(define.ErrInvalidArg, "invalid timeout %d", req.Timeout)
+	}
+	pod, err := s.runtime.LookupPod(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	// A timeout of -1 stops each container with its own stop timeout
+	timeout := -1
+	if req.Timeout > 0 {
+		timeout = int(req.Timeout)
+	}
+	ctrErrs, err := pod.StopWithTimeout(ctx, false, timeout)
+	resp, err := podResponse(pod, ctrErrs, err)
+	if err != nil {
+		return err
+	}
+	return send(resp)
+}
+
+func (s *Server) removePod(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req RemovePodRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	pod, err := s.runtime.LookupPod(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	if err := s.runtime.RemovePod(ctx, pod, true, req.Force); err != nil {
+		return err
+	}
+	return send(&PodResponse{ID: pod.ID()})
+}
+
+// imageMessage returns the description of the image
+func imageMessage(ctx context.Context, img *image.Image) (*Image, error) {
+	size, err := img.Size(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := img.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		ID:        img.ID(),
+		Names:     img.Names(),
+		CreatedAt: unixNano(img.Created()),
+		Size:      *size,
+		Labels:    labels,
+	}, nil
+}
+
+func (s *Server) listImages(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req ListImagesRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	images, err := s.runtime.ImageRuntime().GetImages()
+	if err != nil {
+		return err
+	}
+	resp := &ListImagesResponse{Images: make([]*Image, 0, len(images))}
+	for _, img := range images {
+		if !req.All && len(img.Names()) == 0 {
+			if isParent, err := img.IsParent(ctx); err == nil && isParent {
+				continue
+			}
+		}
+		msg, err := imageMessage(ctx, img)
+		if err != nil {
+			return err
+		}
+		resp.Images = append(resp.Images, msg)
+	}
+	return send(resp)
+}
+
+func (s *Server) removeImage(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req RemoveImageRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	img, err := s.runtime.ImageRuntime().NewFromLocal(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	msg, err := s.runtime.RemoveImage(ctx, img, req.Force)
+	if err != nil {
+		return err
+	}
+	resp := &RemoveImageResponse{}
+	for _, line := range strings.Split(msg, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Untagged: "):
+			resp.Untagged = append(resp.Untagged, strings.TrimPrefix(line, "Untagged: "))
+		case strings.HasPrefix(line, "Deleted: "):
+			resp.Deleted = append(resp.Deleted, strings.TrimPrefix(line, "Deleted: "))
+		}
+	}
+	return send(resp)
+}
+
+func (s *Server) events(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req EventsRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	options := events.ReadOptions{
+		EventChannel: make(chan *events.Event),
+		Filters:      req.Filters,
+		FromStart:    req.Since != 0,
+		Stream:       req.Until == 0,
+	}
+	if req.Since != 0 {
+		options.Since = fromUnixNano(req.Since).Format(time.RFC3339Nano)
+	}
+	if req.Until != 0 {
+		options.Until = fromUnixNano(req.Until).Format(time.RFC3339Nano)
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- s.runtime.Events(options)
+	}()
+
+	sent := false
+	eventChannel := options.EventChannel
+	for {
+		select {
+		case event, ok := <-eventChannel:
+			if !ok {
+				// Wait for the error the reader returns
+				eventChannel = nil
+				continue
+			}
+			msg := &Event{
+				Type:       string(event.Type),
+				Status:     string(event.Status),
+				ID:         event.ID,
+				Name:       event.Name,
+				Image:      event.Image,
+				Time:       unixNano(event.Time),
+				ExitCode:   int32(event.ContainerExitCode),
+				Attributes: event.Attributes,
+			}
+			if err := send(msg); err != nil {
+				drainEvents(eventChannel)
+				return err
+			}
+			sent = true
+		case err := <-errChan:
+			if err != nil && !sent {
+				return errors.Wrapf(define.ErrInvalidArg, "%v", err)
+			}
+			return err
+		case <-ctx.Done():
+			drainEvents(eventChannel)
+			return ctx.Err()
+		}
+	}
+}
+
+// drainEvents discards the events still read once the call is over, so that
+// the reader is not blocked forever
+func drainEvents(eventChannel chan *events.Event) {
+	if eventChannel == nil {
+		return
+	}
+	go func() {
+		for range eventChannel {
+		}
+	}()
+}
+
+func (s *Server) logs(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req LogsRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	ctr, err := s.runtime.LookupContainer(req.NameOrID)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	options := &logs.LogOptions{
+		Follow:    req.Follow,
+		Since:     fromUnixNano(req.Since),
+		Until:     fromUnixNano(req.Until),
+		Tail:      req.Tail,
+		WaitGroup: &wg,
+	}
+	logChannel := make(chan *logs.LogLine, logChannelSize)
+	if err := s.runtime.Log(ctx, []*libpod.Container{ctr}, options, logChannel); err != nil {
+		return err
+	}
+	go func() {
+		wg.Wait()
+		close(logChannel)
+	}()
+
+	for line := range logChannel {
+		msg := &LogLine{Stream: line.Device, Message: line.Msg, Time: unixNano(line.Time)}
+		if err := send(msg); err != nil {
+			// Let the readers finish, they stop with the context
+			go func() {
+				for range logChannel {
+				}
+			}()
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// statsMessage returns the resource usage of a container
+func statsMessage(stats *libpod.ContainerStats) *ContainerStats {
+	return &ContainerStats{
+		ID:          stats.ContainerID,
+		Name:        stats.Name,
+		CPUPercent:  stats.CPU,
+		MemUsage:    stats.MemUsage,
+		MemLimit:    stats.MemLimit,
+		MemPercent:  stats.MemPerc,
+		NetInput:    stats.NetInput,
+		NetOutput:   stats.NetOutput,
+		BlockInput:  stats.BlockInput,
+		BlockOutput: stats.BlockOutput,
+		PIDs:        stats.PIDs,
+	}
+}
+
+func (s *Server) stats(ctx context.Context, recv, send func(proto.Message) error) error {
+	var req StatsRequest
+	if err := recv(&req); err != nil {
+		return err
+	}
+	ctrs := make([]*libpod.Container, 0, len(req.NamesOrIDs))
+	for _, nameOrID := range req.NamesOrIDs {
+		ctr, err := s.runtime.LookupContainer(nameOrID)
+		if err != nil {
+			return err
+		}
+		ctrs = append(ctrs, ctr)
+	}
+
+	previous := make(map[string]*libpod.ContainerStats)
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	for {
+		var allStats []*libpod.ContainerStats
+		if len(ctrs) == 0 {
+			var ctrErrs map[string]error
+			var err error
+			allStats, ctrErrs, err = s.runtime.GetAllContainerStats(previous, 0)
+			if err != nil {
+				return err
+			}
+			for id, ctrErr := range ctrErrs {
+				logrus.Debugf("Error getting stats of container %s: %v", id, ctrErr)
+			}
+		} else {
+			for _, ctr := range ctrs {
+				stats, err := ctr.GetContainerStats(previous[ctr.ID()])
+				if err != nil {
+					return errors.Wrapf(err, "error getting stats of container %s", ctr.ID())
+				}
+				allStats = append(allStats, stats)
+			}
+		}
+		current := make(map[string]*libpod.ContainerStats, len(allStats))
+		for _, stats := range allStats {
+			if err := send(statsMessage(stats)); err != nil {
+				return err
+			}
+			current[stats.ContainerID] = stats
+		}
+		previous = current
+		if !req.Stream {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/image"
+	"github.com/containers/storage"
+	"github.com/pkg/errors"
+)
+
+// code is a gRPC status code
+type code int
+
+const (
+	codeOK                 code = 0
+	codeCanceled           code = 1
+	codeUnknown            code = 2
+	codeInvalidArgument    code = 3
+	codeDeadlineExceeded   code = 4
+	codeNotFound           code = 5
+	codeAlreadyExists      code = 6
+	codeResourceExhausted  code = 8
+	codeFailedPrecondition code = 9
+	codeUnimplemented      code = 12
+	codeInternal           code = 13
+)
+
+// statusError is an error with its gRPC status code
+type statusError struct {
+	code    code
+	message string
+}
+
+// Error returns the message of the error
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// statusErrorf returns an error with the status code
+func statusErrorf(c code, format string, args ...interface{}) error {
+	return &statusError{code: c, message: fmt.Sprintf(format, args...)}
+}
+
+// errorStatus returns the status code and message of a call failing with the
+// error, derived from its cause
+func errorStatus(err error) (code, string) {
+	if err == nil {
+		return codeOK, ""
+	}
+	cause := errors.Cause(err)
+	if statusErr, ok := cause.(*statusError); ok {
+		return statusErr.code, err.Error()
+	}
+	switch cause {
+	case context.Canceled:
+		return codeCanceled, err.Error()
+	case context.DeadlineExceeded:
+		return codeDeadlineExceeded, err.Error()
+	case define.ErrNoSuchCtr, define.ErrNoSuchPod, define.ErrNoSuchImage, image.ErrNoSuchImage:
+		return codeNotFound, err.Error()
+	case define.ErrCtrExists, define.ErrPodExists, define.ErrImageExists:
+		return codeAlreadyExists, err.Error()
+	case define.ErrCtrStateInvalid, define.ErrCtrStopped, storage.ErrImageUsedByContainer:
+		return codeFailedPrecondition, err.Error()
+	case define.ErrInvalidArg, define.ErrEmptyID:
+		return codeInvalidArgument, err.Error()
+	case define.ErrNotImplemented, define.ErrOSNotSupported:
+		return codeUnimplemented, err.Error()
+	}
+	return codeUnknown, err.Error()
+}
@@ -0,0 +1,874 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containers/libpod/cmd/podman/shared"
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/logs"
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/cri-o/ocicni/pkg/ocicni"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/signal"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultStopTimeout is how long containers are given to stop when the
+// request does not say, as in the Docker API
+const defaultStopTimeout = 10
+
+// logChannelSize is the number of log lines read ahead of those written to
+// the client
+const logChannelSize = 64
+
+// dockerState returns the state of the container as named by the Docker API
+func dockerState(state define.ContainerStatus) string {
+	switch state {
+	case define.ContainerStateConfigured, define.ContainerStateCreated:
+		return "created"
+	case define.ContainerStateRunning:
+		return "running"
+	case define.ContainerStatePaused:
+		return "paused"
+	case define.ContainerStateStopped, define.ContainerStateExited:
+		return "exited"
+	case define.ContainerStateRemoving:
+		return "removing"
+	}
+	return "dead"
+}
+
+// dockerStatus returns the human readable status of the container, as listed
+// by the Docker API
+func dockerStatus(ctr *libpod.Container, state define.ContainerStatus) (string, error) {
+	switch state {
+	case define.ContainerStateRunning, define.ContainerStatePaused:
+		started, err := ctr.StartedTime()
+		if err != nil {
+			return "", err
+		}
+		status := "Up " + units.HumanDuration(time.Since(started))
+		if state == define.ContainerStatePaused {
+			status += " (Paused)"
+		}
+		return status, nil
+	case define.ContainerStateStopped, define.ContainerStateExited:
+		exitCode, _, err := ctr.ExitCode()
+		if err != nil {
+			return "", err
+		}
+		finished, err := ctr.FinishedTime()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Exited (%d) %s ago", exitCode, units.HumanDuration(time.Since(finished))), nil
+	case define.ContainerStateRemoving:
+		return "Removal In Progress", nil
+	case define.ContainerStateUnknown:
+		return "Dead", nil
+	}
+	return "Created", nil
+}
+
+// dockerPorts converts the port mappings of a container into the ports
+// listed by the Docker API
+func dockerPorts(mappings []ocicni.PortMapping) []types.Port {
+	ports := make([]types.Port, 0, len(mappings))
+	for _, mapping := range mappings {
+		ports = append(ports, types.Port{
+			IP:          mapping.HostIP,
+			PrivatePort: uint16(mapping.ContainerPort),
+			PublicPort:  uint16(mapping.HostPort),
+			Type:        mapping.Protocol,
+		})
+	}
+	return ports
+}
+
+// filterContainer returns whether the container matches the filters of a
+// container list request
+func filterContainer(args filters.Args, ctr *libpod.Container, state define.ContainerStatus) bool {
+	if !matchFilter(args, "id", func(id string) bool { return strings.HasPrefix(ctr.ID(), id) }) {
+		return false
+	}
+	if !matchFilter(args, "name", func(name string) bool { return strings.Contains(ctr.Name(), strings.TrimPrefix(name, "/")) }) {
+		return false
+	}
+	if !matchFilter(args, "status", func(status string) bool { return status == dockerState(state) }) {
+		return false
+	}
+	if !matchFilter(args, "ancestor", func(image string) bool {
+		id, name := ctr.Image()
+		return strings.HasPrefix(id, image) || name == image || strings.HasPrefix(name, image+":")
+	}) {
+		return false
+	}
+	if !matchFilter(args, "pod", func(pod string) bool { return ctr.PodID() != "" && strings.HasPrefix(ctr.PodID(), pod) }) {
+		return false
+	}
+	return matchLabels(args, ctr.Labels())
+}
+
+// ListContainers lists the containers, only the running ones unless the all
+// query parameter is set
+func ListContainers(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	all, err := queryBool(r, "all", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	size, err := queryBool(r, "size", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	limit, err := queryInt(r, "limit", -1)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+
+	ctrs, err := runtime.GetAllContainers()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	// Most recently created containers come first
+	sort.Slice(ctrs, func(i, j int) bool { return ctrs[i].CreatedTime().After(ctrs[j].CreatedTime()) })
+
+	list := make([]types.Container, 0, len(ctrs))
+	for _, ctr := range ctrs {
+		if limit >= 0 && len(list) >= limit {
+			break
+		}
+		var summary *types.Container
+		err := ctr.Batch(func(c *libpod.Container) error {
+			state, err := c.State()
+			if err != nil {
+				return err
+			}
+			if !all && limit < 0 && state != define.ContainerStateRunning && state != define.ContainerStatePaused {
+				return nil
+			}
+			if !filterContainer(args, c, state) {
+				return nil
+			}
+			status, err := dockerStatus(c, state)
+			if err != nil {
+				return err
+			}
+			mappings, err := c.PortMappings()
+			if err != nil {
+				return err
+			}
+			imageID, imageName := c.Image()
+			summary = &types.Container{
+				ID:      c.ID(),
+				Names:   []string{"/" + c.Name()},
+				Image:   imageName,
+				ImageID: imageID,
+				Command: strings.Join(c.Command(), " "),
+				Created: c.CreatedTime().Unix(),
+				Ports:   dockerPorts(mappings),
+				Labels:  c.Labels(),
+				State:   dockerState(state),
+				Status:  status,
+			}
+			summary.HostConfig.NetworkMode = string(c.Config().NetMode)
+			if size {
+				if summary.SizeRw, err = c.RWSize(); err != nil {
+					return err
+				}
+				if summary.SizeRootFs, err = c.RootFsSize(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if errors.Cause(err) == define.ErrCtrRemoved || errors.Cause(err) == define.ErrNoSuchCtr {
+				continue
+			}
+			Error(w, 0, err)
+			return
+		}
+		if summary != nil {
+			list = append(list, *summary)
+		}
+	}
+	WriteResponse(w, http.StatusOK, list)
+}
+
+// CreateContainer creates a container as podman create would, without
+// pulling its image
+func CreateContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	var input models.CreateContainerConfig
+	if err := decodeBody(r, &input); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	results, err := shared.DockerCreateToGeneric(r.URL.Query().Get("name"), &input.Config, &input.HostConfig, &input.NetworkingConfig)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := runtime.ImageRuntime().NewFromLocal(input.Image); err != nil {
+		Error(w, http.StatusNotFound, errors.Wrapf(err, "no such image: %s", input.Image))
+		return
+	}
+	ctr, _, err := shared.CreateContainer(r.Context(), &results, runtime)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusCreated, container.ContainerCreateCreatedBody{ID: ctr.ID(), Warnings: []string{}})
+}
+
+// InspectContainer returns the configuration and state of a container in the
+// format of the Docker API
+func InspectContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	size, err := queryBool(r, "size", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	data, err := ctr.Inspect(size)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	inspect, err := dockerInspect(ctr, data)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusOK, inspect)
+}
+
+// dockerInspect converts the inspect data of a container into the format of
+// the Docker API
+func dockerInspect(ctr *libpod.Container, data *libpod.InspectContainerData) (*types.ContainerJSON, error) {
+	// The host configuration is inspected in the Docker format already
+	var hostConfig container.HostConfig
+	raw, err := json.Marshal(data.HostConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &hostConfig); err != nil {
+		return nil, errors.Wrapf(err, "error converting host configuration of container %s", ctr.ID())
+	}
+	var mounts []types.MountPoint
+	raw, err = json.Marshal(data.Mounts)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &mounts); err != nil {
+		return nil, errors.Wrapf(err, "error converting mounts of container %s", ctr.ID())
+	}
+
+	state, err := ctr.State()
+	if err != nil {
+		return nil, err
+	}
+	ctrState := &types.ContainerState{
+		Status:     dockerState(state),
+		Running:    data.State.Running,
+		Paused:     data.State.Paused,
+		Restarting: data.State.Restarting,
+		OOMKilled:  data.State.OOMKilled,
+		Dead:       data.State.Dead,
+		Pid:        data.State.Pid,
+		ExitCode:   int(data.State.ExitCode),
+		Error:      data.State.Error,
+		StartedAt:  data.State.StartedAt.Format(time.RFC3339Nano),
+		FinishedAt: data.State.FinishedAt.Format(time.RFC3339Nano),
+	}
+	if data.State.Healthcheck.Status != "" {
+		ctrState.Health = &types.Health{
+			Status:        data.State.Healthcheck.Status,
+			FailingStreak: data.State.Healthcheck.FailingStreak,
+		}
+	}
+
+	config := &container.Config{
+		Hostname:     data.Config.Hostname,
+		Domainname:   data.Config.DomainName,
+		User:         data.Config.User,
+		AttachStdin:  data.Config.AttachStdin,
+		AttachStdout: data.Config.AttachStdout,
+		AttachStderr: data.Config.AttachStderr,
+		Tty:          data.Config.Tty,
+		OpenStdin:    data.Config.OpenStdin,
+		StdinOnce:    data.Config.StdinOnce,
+		Env:          data.Config.Env,
+		Cmd:          data.Config.Cmd,
+		Image:        data.ImageName,
+		Volumes:      data.Config.Volumes,
+		WorkingDir:   data.Config.WorkingDir,
+		Entrypoint:   ctr.Entrypoint(),
+		Labels:       data.Config.Labels,
+		StopSignal:   strconv.Itoa(int(data.Config.StopSignal)),
+	}
+	if hc := data.Config.Healthcheck; hc != nil {
+		config.Healthcheck = &container.HealthConfig{
+			Test:        hc.Test,
+			Interval:    hc.Interval,
+			Timeout:     hc.Timeout,
+			StartPeriod: hc.StartPeriod,
+			Retries:     hc.Retries,
+		}
+	}
+
+	ports := make(nat.PortMap)
+	for _, mapping := range data.NetworkSettings.Ports {
+		port := nat.Port(fmt.Sprintf("%d/%s", mapping.ContainerPort, mapping.Protocol))
+		ports[port] = append(ports[port], nat.PortBinding{HostIP: mapping.HostIP, HostPort: strconv.Itoa(int(mapping.HostPort))})
+	}
+	networkSettings := &types.NetworkSettings{
+		NetworkSettingsBase: types.NetworkSettingsBase{
+			Bridge:     data.NetworkSettings.Bridge,
+			SandboxID:  data.NetworkSettings.SandboxID,
+			SandboxKey: data.NetworkSettings.SandboxKey,
+			Ports:      ports,
+		},
+		DefaultNetworkSettings: types.DefaultNetworkSettings{
+			EndpointID:          data.NetworkSettings.EndpointID,
+			Gateway:             data.NetworkSettings.Gateway,
+			GlobalIPv6Address:   data.NetworkSettings.GlobalIPv6Address,
+			GlobalIPv6PrefixLen: data.NetworkSettings.GlobalIPv6PrefixLen,
+			IPAddress:           data.NetworkSettings.IPAddress,
+			IPPrefixLen:         data.NetworkSettings.IPPrefixLen,
+			IPv6Gateway:         data.NetworkSettings.IPv6Gateway,
+			MacAddress:          data.NetworkSettings.MacAddress,
+		},
+		Networks: map[string]*network.EndpointSettings{},
+	}
+	if data.NetworkSettings.IPAddress != "" {
+		networkSettings.Networks[hostConfig.NetworkMode.NetworkName()] = &network.EndpointSettings{
+			Gateway:     data.NetworkSettings.Gateway,
+			IPAddress:   data.NetworkSettings.IPAddress,
+			IPPrefixLen: data.NetworkSettings.IPPrefixLen,
+			MacAddress:  data.NetworkSettings.MacAddress,
+		}
+	}
+
+	base := &types.ContainerJSONBase{
+		ID:              data.ID,
+		Created:         data.Created.Format(time.RFC3339Nano),
+		Path:            data.Path,
+		Args:            data.Args,
+		State:           ctrState,
+		Image:           data.ImageID,
+		ResolvConfPath:  data.ResolvConfPath,
+		HostnamePath:    data.HostnamePath,
+		HostsPath:       data.HostsPath,
+		LogPath:         data.LogPath,
+		Name:            "/" + data.Name,
+		RestartCount:    int(data.RestartCount),
+		Driver:          data.Driver,
+		Platform:        "linux",
+		MountLabel:      data.MountLabel,
+		ProcessLabel:    data.ProcessLabel,
+		AppArmorProfile: data.AppArmorProfile,
+		ExecIDs:         data.ExecIDs,
+		HostConfig:      &hostConfig,
+	}
+	if data.GraphDriver != nil {
+		base.GraphDriver = types.GraphDriverData{Name: data.GraphDriver.Name, Data: data.GraphDriver.Data}
+	}
+	if data.SizeRw != 0 || data.SizeRootFs != 0 {
+		base.SizeRw = &data.SizeRw
+		base.SizeRootFs = &data.SizeRootFs
+	}
+	return &types.ContainerJSON{
+		ContainerJSONBase: base,
+		Mounts:            mounts,
+		Config:            config,
+		NetworkSettings:   networkSettings,
+	}, nil
+}
+
+// StartContainer starts a container
+func StartContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	state, err := ctr.State()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if state == define.ContainerStateRunning {
+		WriteResponse(w, http.StatusNotModified, nil)
+		return
+	}
+	if err := ctr.Start(r.Context(), false); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// StopContainer stops a container, killing it if it does not stop within
+// the timeout given by the t query parameter
+func StopContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	timeout, err := queryInt(r, "t", defaultStopTimeout)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	state, err := ctr.State()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if state != define.ContainerStateRunning && state != define.ContainerStatePaused {
+		WriteResponse(w, http.StatusNotModified, nil)
+		return
+	}
+	if err := ctr.StopWithTimeout(uint(timeout)); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// RestartContainer restarts a container, killing it if it does not stop
+// within the timeout given by the t query parameter
+func RestartContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	timeout, err := queryInt(r, "t", defaultStopTimeout)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := ctr.RestartWithTimeout(r.Context(), uint(timeout)); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// KillContainer sends the signal given by the signal query parameter, or
+// SIGKILL, to a container
+func KillContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	sig := r.URL.Query().Get("signal")
+	if sig == "" {
+		sig = "KILL"
+	}
+	parsed, err := signal.ParseSignal(sig)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := ctr.Kill(uint(parsed)); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// PauseContainer pauses a container
+func PauseContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := ctr.Pause(); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// UnpauseContainer unpauses a container
+func UnpauseContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := ctr.Unpause(); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// dockerWaitConditions maps the wait conditions of the Docker API to those of
+// libpod
+var dockerWaitConditions = map[string]libpod.WaitCondition{
+	"":            libpod.WaitConditionStopped,
+	"not-running": libpod.WaitConditionStopped,
+	"next-exit":   libpod.WaitConditionNextExit,
+	"removed":     libpod.WaitConditionRemoved,
+}
+
+// WaitContainer waits for a container to meet the condition query parameter,
+// by default to stop, and returns its exit code. The conditions of libpod are
+// accepted along with those of the Docker API. The wait ends if the client
+// goes away.
+func WaitContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	name := r.URL.Query().Get("condition")
+	condition, ok := dockerWaitConditions[name]
+	if !ok {
+		var err error
+		if condition, err = libpod.StringToWaitCondition(name); err != nil {
+			Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	exitCode, _, err := ctr.WaitWithCondition(r.Context(), condition)
+	if err != nil {
+		if r.Context().Err() != nil {
+			return
+		}
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusOK, container.ContainerWaitOKBody{StatusCode: int64(exitCode)})
+}
+
+// RemoveContainer removes a container, and its anonymous volumes if the v
+// query parameter is set
+func RemoveContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	force, err := queryBool(r, "force", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	volumes, err := queryBool(r, "v", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := runtime.RemoveContainer(r.Context(), ctr, force, volumes); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// PruneContainers removes the containers which are not running
+func PruneContainers(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctrs, err := runtime.GetAllContainers()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	report := types.ContainersPruneReport{ContainersDeleted: []string{}}
+	for _, ctr := range ctrs {
+		state, err := ctr.State()
+		if err != nil {
+			continue
+		}
+		if state == define.ContainerStateRunning || state == define.ContainerStatePaused || state == define.ContainerStateRemoving {
+			continue
+		}
+		if !matchLabels(args, ctr.Labels()) {
+			continue
+		}
+		size, err := ctr.RWSize()
+		if err != nil {
+			size = 0
+		}
+		if err := runtime.RemoveContainer(r.Context(), ctr, false, false); err != nil {
+			if errors.Cause(err) == define.ErrNoSuchCtr || errors.Cause(err) == define.ErrCtrRemoved {
+				continue
+			}
+			Error(w, 0, err)
+			return
+		}
+		report.ContainersDeleted = append(report.ContainersDeleted, ctr.ID())
+		report.SpaceReclaimed += uint64(size)
+	}
+	WriteResponse(w, http.StatusOK, report)
+}
+
+// LogsFromContainer writes the logs of a container, multiplexing stdout and
+// stderr unless the container has a terminal
+func LogsFromContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	follow, err := queryBool(r, "follow", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	stdout, err := queryBool(r, "stdout", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	stderr, err := queryBool(r, "stderr", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	timestamps, err := queryBool(r, "timestamps", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if !stdout && !stderr {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "stdout or stderr must be selected"))
+		return
+	}
+	options := &logs.LogOptions{Follow: follow, Timestamps: timestamps}
+	if tail := r.URL.Query().Get("tail"); tail != "" && tail != "all" {
+		lines, err := strconv.ParseUint(tail, 10, 64)
+		if err != nil {
+			Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "invalid value %q for query parameter tail", tail))
+			return
+		}
+		options.Tail = lines
+	}
+	for name, t := range map[string]*time.Time{"since": &options.Since, "until": &options.Until} {
+		value, err := queryInt(r, name, 0)
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		if value > 0 {
+			*t = time.Unix(int64(value), 0)
+		}
+	}
+
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	var wg sync.WaitGroup
+	options.WaitGroup = &wg
+	logChannel := make(chan *logs.LogLine, logChannelSize)
+	if err := runtime.Log(r.Context(), []*libpod.Container{ctr}, options, logChannel); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	go func() {
+		wg.Wait()
+		close(logChannel)
+	}()
+
+	outWriter, errWriter := outputStreams(w, hasTerminal(ctr))
+	for line := range logChannel {
+		writer := outWriter
+		if line.Device == "stderr" {
+			if !stderr {
+				continue
+			}
+			writer = errWriter
+		} else if !stdout {
+			continue
+		}
+		msg := line.Msg
+		if timestamps {
+			msg = line.Time.Format(time.RFC3339Nano) + " " + msg
+		}
+		if _, err := writer.Write([]byte(msg + "\n")); err != nil {
+			return
+		}
+	}
+}
+
+// AttachContainer attaches to the standard streams of a container. The
+// connection is hijacked to stream the output of the container and, if the
+// stdin query parameter is set, to send it input. The client closing its
+// side of the connection closes the standard input of the container.
+func AttachContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	stdin, err := queryBool(r, "stdin", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	stdout, err := queryBool(r, "stdout", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	stderr, err := queryBool(r, "stderr", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if !stdin && !stdout && !stderr {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "stdin, stdout or stderr must be selected"))
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	state, err := ctr.State()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if state != define.ContainerStateCreated && state != define.ContainerStateRunning {
+		Error(w, 0, errors.Wrapf(define.ErrCtrStateInvalid, "can only attach to created or running containers"))
+		return
+	}
+
+	tty := hasTerminal(ctr)
+	conn, reader, err := hijack(w, r, streamContentType(tty))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	defer closeHijacked(conn)
+	outWriter, errWriter := splitStreams(&streamWriter{w: conn}, tty)
+	streams := &libpod.AttachStreams{
+		OutputStream: outWriter,
+		ErrorStream:  errWriter,
+		AttachOutput: stdout,
+		AttachError:  stderr,
+	}
+	if stdin {
+		streams.InputStream = reader
+		streams.AttachInput = true
+	}
+	if err := ctr.Attach(streams, r.URL.Query().Get("detachKeys"), nil); err != nil && errors.Cause(err) != define.ErrDetach {
+		logrus.Errorf("Error attaching to container %s: %v", ctr.ID(), err)
+	}
+}
+
+// ResizeContainer resizes the terminal of a container to the size given by
+// the h and w query parameters
+func ResizeContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	size, err := terminalSize(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := ctr.AttachResize(size); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusOK, nil)
+}
+
+// PortForwardContainer forwards the hijacked connection of the request to
+// the TCP port of a container given by the port query parameter. Each
+// direction of the forwarded connection is closed on its own, when the side
+// writing to it closes it.
+func PortForwardContainer(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	port, err := queryInt(r, "port", 0)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if port <= 0 || port > math.MaxUint16 {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "invalid port %d", port))
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctrConn, err := ctr.PortForward(uint16(port))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	defer ctrConn.Close()
+	conn, reader, err := hijack(w, r, "application/octet-stream")
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := io.Copy(ctrConn, reader); err != nil {
+			logrus.Debugf("Error forwarding to port %d of container %s: %v", port, ctr.ID(), err)
+		}
+		closeWrite(ctrConn)
+	}()
+	if _, err := io.Copy(conn, ctrConn); err != nil {
+		logrus.Debugf("Error forwarding from port %d of container %s: %v", port, ctr.ID(), err)
+	}
+	closeWrite(conn)
+	<-done
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/logs"
+	"github.com/containers/storage"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/gorilla/mux"
+	"github.com/opencontainers/runtime-tools/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRuntime returns a runtime with an in-memory state, keeping its files
+// in the returned temporary directory, removed by the returned function
+func newTestRuntime(t *testing.T) (*libpod.Runtime, string, func()) {
+	if os.Geteuid() != 0 {
+		t.Skip("Test not running as root")
+	}
+	for _, binary := range []string{"conmon", "runc"} {
+		if _, err := exec.LookPath(binary); err != nil {
+			t.Skipf("%s is not installed", binary)
+		}
+	}
+	dir, err := ioutil.TempDir("", "api-handlers")
+	require.NoError(t, err)
+	config := fmt.Sprintf("lock_type = \"file\"\nevents_logger = \"none\"\nstatic_dir = %q\ntmp_dir = %q\nvolume_path = %q\ncni_config_dir = %q\n",
+		filepath.Join(dir, "static"), filepath.Join(dir, "tmp"), filepath.Join(dir, "volumes"), filepath.Join(dir, "cni"))
+	configPath := filepath.Join(dir, "libpod.conf")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte(config), 0644))
+
+	runtime, err := libpod.NewRuntimeFromConfig(context.Background(), configPath,
+		libpod.WithStateType(libpod.InMemoryStateStore),
+		libpod.WithStorageConfig(storage.StoreOptions{
+			RunRoot:         filepath.Join(dir, "run"),
+			GraphRoot:       filepath.Join(dir, "root"),
+			GraphDriverName: "vfs",
+		}),
+	)
+	if err != nil {
+		os.RemoveAll(dir)
+		require.NoError(t, err)
+	}
+	return runtime, dir, func() {
+		if err := runtime.Shutdown(true); err != nil {
+			t.Logf("Error shutting down runtime: %v", err)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+// newTestContainer creates a container of the runtime, not started, with a
+// root filesystem in the given directory
+func newTestContainer(t *testing.T, runtime *libpod.Runtime, dir string) *libpod.Container {
+	rootfs := filepath.Join(dir, "rootfs")
+	require.NoError(t, os.MkdirAll(rootfs, 0755))
+	g, err := generate.New("linux")
+	require.NoError(t, err)
+	ctr, err := runtime.NewContainer(context.Background(), g.Config, libpod.WithRootFS(rootfs), libpod.WithName("test"))
+	require.NoError(t, err)
+	return ctr
+}
+
+// newRequest returns a request served with the runtime, about the named
+// object
+func newRequest(ctx context.Context, runtime *libpod.Runtime, method, target, name string) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	r = r.WithContext(WithRuntime(ctx, runtime))
+	return mux.SetURLVars(r, map[string]string{"name": name})
+}
+
+func TestWaitContainer(t *testing.T) {
+	runtime, dir, cleanup := newTestRuntime(t)
+	defer cleanup()
+	ctr := newTestContainer(t, runtime, dir)
+
+	// A container that never ran is not running
+	for _, condition := range []string{"", "not-running", "stopped"} {
+		rec := httptest.NewRecorder()
+		WaitContainer(rec, newRequest(context.Background(), runtime, http.MethodPost, "/containers/test/wait?condition="+condition, "test"))
+		require.Equal(t, http.StatusOK, rec.Code, condition)
+		var body container.ContainerWaitOKBody
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+		assert.Equal(t, int64(0), body.StatusCode)
+	}
+
+	rec := httptest.NewRecorder()
+	WaitContainer(rec, newRequest(context.Background(), runtime, http.MethodPost, "/containers/test/wait?condition=never", "test"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	WaitContainer(rec, newRequest(context.Background(), runtime, http.MethodPost, "/containers/nothing/wait", "nothing"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// The wait ends once the client goes away
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		WaitContainer(httptest.NewRecorder(), newRequest(ctx, runtime, http.MethodPost, "/containers/test/wait?condition=next-exit", ctr.ID()))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("wait did not end with the request")
+	}
+}
+
+func TestLogsFromContainer(t *testing.T) {
+	runtime, dir, cleanup := newTestRuntime(t)
+	defer cleanup()
+	ctr := newTestContainer(t, runtime, dir)
+
+	var log bytes.Buffer
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(&log, "%s stdout F out %d\n", now.Format(logs.LogTimeFormat), i)
+		fmt.Fprintf(&log, "%s stderr F err %d\n", now.Format(logs.LogTimeFormat), i)
+	}
+	require.NoError(t, ioutil.WriteFile(ctr.LogPath(), log.Bytes(), 0644))
+
+	rec := httptest.NewRecorder()
+	LogsFromContainer(rec, newRequest(context.Background(), runtime, http.MethodGet, "/containers/test/logs?stdout=1&stderr=1", "test"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var stdout, stderr bytes.Buffer
+	_, err := stdcopy.StdCopy(&stdout, &stderr, rec.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "out 0\nout 1\nout 2\n", stdout.String())
+	assert.Equal(t, "err 0\nerr 1\nerr 2\n", stderr.String())
+
+	// Tails larger than the log are served whole
+	rec = httptest.NewRecorder()
+	LogsFromContainer(rec, newRequest(context.Background(), runtime, http.MethodGet, "/containers/test/logs?stdout=1&tail=1000000000", "test"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	stdout.Reset()
+	_, err = stdcopy.StdCopy(&stdout, ioutil.Discard, rec.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(stdout.String(), "\n"))
+
+	rec = httptest.NewRecorder()
+	LogsFromContainer(rec, newRequest(context.Background(), runtime, http.MethodGet, "/containers/test/logs", "test"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/events"
+	dockerEvents "github.com/docker/docker/api/types/events"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// eventFilters are the filters of events supported by libpod
+var eventFilters = map[string]bool{
+	"container": true,
+	"event":     true,
+	"image":     true,
+	"pod":       true,
+	"volume":    true,
+	"type":      true,
+}
+
+// eventTime converts the since or until query parameter, a Unix timestamp
+// with optional fractional seconds, to the format read by libpod. Other
+// values are passed through, as the CLI accepts them.
+func eventTime(value string) string {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	whole := int64(seconds)
+	return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))).Format(time.RFC3339Nano)
+}
+
+// dockerEvent converts an event of libpod to an event of the Docker API
+func dockerEvent(event *events.Event) dockerEvents.Message {
+	attributes := make(map[string]string, len(event.Attributes)+2)
+	for k, v := range event.Attributes {
+		attributes[k] = v
+	}
+	if event.Name != "" {
+		attributes["name"] = event.Name
+	}
+	if event.Image != "" {
+		attributes["image"] = event.Image
+	}
+	if event.Status == events.Exited {
+		attributes["exitCode"] = strconv.Itoa(event.ContainerExitCode)
+	}
+	return dockerEvents.Message{
+		Status: string(event.Status),
+		ID:     event.ID,
+		From:   event.Image,
+		Type:   string(event.Type),
+		Action: string(event.Status),
+		Actor: dockerEvents.Actor{
+			ID:         event.ID,
+			Attributes: attributes,
+		},
+		Scope:    "local",
+		Time:     event.Time.Unix(),
+		TimeNano: event.Time.UnixNano(),
+	}
+}
+
+// GetEvents streams the events of the runtime as JSON messages, until the
+// client disconnects, or until the time given by the until query parameter
+func GetEvents(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	query := r.URL.Query()
+	stream, err := queryBool(r, "stream", query.Get("until") == "")
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	options := events.ReadOptions{
+		EventChannel: make(chan *events.Event),
+		FromStart:    query.Get("since") != "",
+		Stream:       stream,
+	}
+	if since := query.Get("since"); since != "" {
+		options.Since = eventTime(since)
+	}
+	if until := query.Get("until"); until != "" {
+		options.Until = eventTime(until)
+	}
+	if err := args.Validate(eventFilters); err != nil {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "%v", err))
+		return
+	}
+	for key := range eventFilters {
+		for _, value := range args.Get(key) {
+			options.Filters = append(options.Filters, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- runtime.Events(options)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	flusher, _ := w.(http.Flusher)
+	headerWritten := false
+	writeHeader := func() {
+		if !headerWritten {
+			w.WriteHeader(http.StatusOK)
+			headerWritten = true
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+	if stream {
+		writeHeader()
+	}
+	encoder := json.NewEncoder(w)
+	eventChannel := options.EventChannel
+	for {
+		select {
+		case event, ok := <-eventChannel:
+			if !ok {
+				// Wait for the error the reader returns
+				eventChannel = nil
+				continue
+			}
+			writeHeader()
+			if err := encoder.Encode(dockerEvent(event)); err != nil {
+				logrus.Debugf("Error writing event: %v", err)
+				drainEvents(eventChannel)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case err := <-errChan:
+			if err != nil {
+				if !headerWritten {
+					Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "%v", err))
+					return
+				}
+				logrus.Errorf("Error reading events: %v", err)
+			}
+			writeHeader()
+			return
+		case <-r.Context().Done():
+			drainEvents(eventChannel)
+			return
+		}
+	}
+}
+
+// drainEvents discards the events still read once the client is gone, so
+// that the reader is not blocked forever
+func drainEvents(eventChannel chan *events.Event) {
+	if eventChannel == nil {
+		return
+	}
+	go func() {
+		for range eventChannel {
+		}
+	}()
+}
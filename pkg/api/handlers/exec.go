@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/storage/pkg/stringid"
+	"github.com/docker/docker/api/types"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// execInstance is a command created by the API to be executed in a
+// container. It is kept in memory, as exec instances only live as long as
+// the service.
+type execInstance struct {
+	lock        sync.Mutex
+	id          string
+	containerID string
+	config      types.ExecConfig
+	started     bool
+	running     bool
+	exitCode    int
+	// sessionID is the ID of the exec session of the container running
+	// the command, if started detached
+	sessionID string
+	// resize receives the sizes of the terminal of the command while it
+	// runs attached
+	resize chan remotecommand.TerminalSize
+}
+
+// execInstances are the exec instances created through the API, by ID
+var execInstances = struct {
+	lock      sync.Mutex
+	instances map[string]*execInstance
+}{instances: make(map[string]*execInstance)}
+
+// lookupExec returns the exec instance the request is about
+func lookupExec(r *http.Request) (*execInstance, error) {
+	id := mux.Vars(r)["id"]
+	execInstances.lock.Lock()
+	defer execInstances.lock.Unlock()
+	instance, ok := execInstances.instances[id]
+	if !ok {
+		return nil, errors.Wrapf(define.ErrNoSuchExecSession, "no exec instance with ID %s", id)
+	}
+	return instance, nil
+}
+
+// finish records the exit code of the command of the exec instance
+func (e *execInstance) finish(exitCode int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.running = false
+	e.exitCode = exitCode
+	if e.resize != nil {
+		close(e.resize)
+		e.resize = nil
+	}
+}
+
+// removeStaleExecs forgets the exec instances of the containers which no
+// longer exist
+func removeStaleExecs(runtime *libpod.Runtime) {
+	execInstances.lock.Lock()
+	defer execInstances.lock.Unlock()
+	for id, instance := range execInstances.instances {
+		if exists, err := runtime.HasContainer(instance.containerID); err == nil && !exists {
+			delete(execInstances.instances, id)
+		}
+	}
+}
+
+// CreateExec creates an exec instance running a command in a container
+func CreateExec(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	var config types.ExecConfig
+	if err := decodeBody(r, &config); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if len(config.Cmd) == 0 {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "no command given"))
+		return
+	}
+	ctr, err := runtime.LookupContainer(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	state, err := ctr.State()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if state != define.ContainerStateRunning {
+		Error(w, 0, errors.Wrapf(define.ErrCtrStateInvalid, "container %s is not running", ctr.ID()))
+		return
+	}
+
+	removeStaleExecs(runtime)
+	instance := &execInstance{
+		id:          stringid.GenerateNonCryptoID(),
+		containerID: ctr.ID(),
+		config:      config,
+	}
+	execInstances.lock.Lock()
+	execInstances.instances[instance.id] = instance
+	execInstances.lock.Unlock()
+	WriteResponse(w, http.StatusCreated, types.IDResponse{ID: instance.id})
+}
+
+// StartExec starts the command of an exec instance. Unless detached, the
+// connection is hijacked to stream the output of the command until it
+// exits, and its input if it was created attached to it. The client closing
+// its side of the connection closes the standard input of the command.
+func StartExec(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	var check types.ExecStartCheck
+	if err := decodeBody(r, &check); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	instance, err := lookupExec(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	instance.lock.Lock()
+	if instance.started {
+		instance.lock.Unlock()
+		Error(w, http.StatusConflict, errors.Wrapf(define.ErrCtrStateInvalid, "exec instance %s has already been started", instance.id))
+		return
+	}
+	instance.started = true
+	instance.running = true
+	instance.lock.Unlock()
+
+	ctr, err := runtime.LookupContainer(instance.containerID)
+	if err != nil {
+		instance.finish(define.ExecErrorCodeCannotInvoke)
+		Error(w, 0, err)
+		return
+	}
+	config := instance.config
+	tty := config.Tty || check.Tty
+
+	if check.Detach || config.Detach {
+		sessionID, err := ctr.ExecDetached(tty, config.Privileged, config.Env, config.Cmd, config.User, config.WorkingDir, 0)
+		if err != nil {
+			instance.finish(define.ExecErrorCodeCannotInvoke)
+			Error(w, 0, err)
+			return
+		}
+		instance.lock.Lock()
+		instance.sessionID = sessionID
+		instance.lock.Unlock()
+		WriteResponse(w, http.StatusOK, nil)
+		return
+	}
+
+	conn, reader, err := hijack(w, r, streamContentType(tty))
+	if err != nil {
+		instance.finish(define.ExecErrorCodeCannotInvoke)
+		Error(w, 0, err)
+		return
+	}
+	defer closeHijacked(conn)
+	outWriter, errWriter := splitStreams(&streamWriter{w: conn}, tty)
+	streams := &libpod.AttachStreams{
+		OutputStream: outWriter,
+		ErrorStream:  errWriter,
+		AttachOutput: config.AttachStdout,
+		AttachError:  config.AttachStderr,
+	}
+	if config.AttachStdin {
+		streams.InputStream = reader
+		streams.AttachInput = true
+	}
+	var resize chan remotecommand.TerminalSize
+	if tty {
+		resize = make(chan remotecommand.TerminalSize, 1)
+		instance.lock.Lock()
+		instance.resize = resize
+		instance.lock.Unlock()
+	}
+	exitCode, err := ctr.Exec(tty, config.Privileged, config.Env, config.Cmd, config.User, config.WorkingDir, streams, 0, resize, config.DetachKeys)
+	if err != nil && errors.Cause(err) != define.ErrDetach {
+		logrus.Errorf("Error executing command in container %s: %v", ctr.ID(), err)
+	}
+	instance.finish(exitCode)
+}
+
+// ResizeExec resizes the terminal of the command of an exec instance, to the
+// size given by the h and w query parameters
+func ResizeExec(w http.ResponseWriter, r *http.Request) {
+	size, err := terminalSize(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	instance, err := lookupExec(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	instance.lock.Lock()
+	defer instance.lock.Unlock()
+	if instance.resize == nil {
+		Error(w, 0, errors.Wrapf(define.ErrCtrStateInvalid, "exec instance %s is not running attached with a terminal", instance.id))
+		return
+	}
+	// Only the last size matters if the previous one was not applied yet
+	select {
+	case <-instance.resize:
+	default:
+	}
+	instance.resize <- size
+	WriteResponse(w, http.StatusOK, nil)
+}
+
+// InspectExec returns the state of an exec instance
+func InspectExec(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	instance, err := lookupExec(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	instance.lock.Lock()
+	defer instance.lock.Unlock()
+	report := types.ContainerExecInspect{
+		ExecID:      instance.id,
+		ContainerID: instance.containerID,
+	}
+	if instance.running && instance.sessionID != "" {
+		// Detached commands are monitored by libpod, collect their
+		// exit code once they have exited
+		ctr, err := runtime.LookupContainer(instance.containerID)
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		exitCodes, err := ctr.ReapExecSessions()
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		if exitCode, ok := exitCodes[instance.sessionID]; ok {
+			instance.running = false
+			instance.exitCode = exitCode
+		} else if session, err := ctr.ExecSession(instance.sessionID); err == nil {
+			report.Pid = session.PID
+		}
+	}
+	report.Running = instance.running
+	report.ExitCode = instance.exitCode
+	WriteResponse(w, http.StatusOK, report)
+}
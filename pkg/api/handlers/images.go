@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/libpod/image"
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/containers/libpod/pkg/util"
+	"github.com/containers/storage"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	imagetypes "github.com/docker/docker/api/types/image"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// imageSummary returns the summary of the image listed by the Docker API
+func imageSummary(r *http.Request, img *image.Image) (*types.ImageSummary, error) {
+	ctx := r.Context()
+	size, err := img.Size(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := img.Labels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	digests, err := img.RepoDigests()
+	if err != nil {
+		return nil, err
+	}
+	ctrs, err := img.Containers()
+	if err != nil {
+		return nil, err
+	}
+	summary := &types.ImageSummary{
+		ID:          "sha256:" + img.ID(),
+		Created:     img.Created().Unix(),
+		Labels:      labels,
+		RepoTags:    img.Names(),
+		RepoDigests: digests,
+		Size:        int64(*size),
+		VirtualSize: int64(*size),
+		SharedSize:  -1,
+		Containers:  int64(len(ctrs)),
+	}
+	if parent, err := img.GetParent(ctx); err == nil && parent != nil {
+		summary.ParentID = "sha256:" + parent.ID()
+	}
+	if summary.RepoTags == nil {
+		summary.RepoTags = []string{"<none>:<none>"}
+	}
+	if summary.RepoDigests == nil {
+		summary.RepoDigests = []string{}
+	}
+	return summary, nil
+}
+
+// ListImages lists the images, skipping the intermediate ones unless the all
+// query parameter is set
+func ListImages(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	all, err := queryBool(r, "all", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	images, err := runtime.ImageRuntime().GetImages()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	list := make([]types.ImageSummary, 0, len(images))
+	for _, img := range images {
+		if !all && len(img.Names()) == 0 {
+			if isParent, err := img.IsParent(r.Context()); err == nil && isParent {
+				continue
+			}
+		}
+		if !matchFilter(args, "dangling", func(dangling string) bool { return (dangling == "true") == img.Dangling() }) {
+			continue
+		}
+		if !matchFilter(args, "reference", func(ref string) bool {
+			for _, name := range img.Names() {
+				if name == ref || strings.HasSuffix(name, "/"+ref) || strings.HasPrefix(name, ref+":") || strings.Contains(name, "/"+ref+":") {
+					return true
+				}
+			}
+			return false
+		}) {
+			continue
+		}
+		summary, err := imageSummary(r, img)
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		if !matchLabels(args, summary.Labels) {
+			continue
+		}
+		list = append(list, *summary)
+	}
+	WriteResponse(w, http.StatusOK, list)
+}
+
+// InspectImage returns the configuration of an image in the format of the
+// Docker API
+func InspectImage(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	img, err := runtime.ImageRuntime().NewFromLocal(nameOrID(r))
+	if err != nil {
+		Error(w, http.StatusNotFound, err)
+		return
+	}
+	data, err := img.Inspect(r.Context())
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	inspect := &types.ImageInspect{
+		ID:           "sha256:" + data.ID,
+		RepoTags:     data.RepoTags,
+		RepoDigests:  data.RepoDigests,
+		Parent:       data.Parent,
+		Comment:      data.Comment,
+		Author:       data.Author,
+		Architecture: data.Architecture,
+		Os:           data.Os,
+		Size:         data.Size,
+		VirtualSize:  data.VirtualSize,
+		Config:       &container.Config{},
+	}
+	if data.Created != nil {
+		inspect.Created = data.Created.Format(time.RFC3339Nano)
+	}
+	if data.GraphDriver != nil {
+		inspect.GraphDriver = types.GraphDriverData{Name: data.GraphDriver.Name, Data: data.GraphDriver.Data}
+	}
+	if data.RootFS != nil {
+		inspect.RootFS.Type = data.RootFS.Type
+		for _, layer := range data.RootFS.Layers {
+			inspect.RootFS.Layers = append(inspect.RootFS.Layers, layer.String())
+		}
+	}
+	if config := data.Config; config != nil {
+		inspect.Config = &container.Config{
+			User:         config.User,
+			Env:          config.Env,
+			Cmd:          config.Cmd,
+			Entrypoint:   config.Entrypoint,
+			WorkingDir:   config.WorkingDir,
+			Labels:       config.Labels,
+			StopSignal:   config.StopSignal,
+			ExposedPorts: make(nat.PortSet),
+			Volumes:      config.Volumes,
+		}
+		for port := range config.ExposedPorts {
+			inspect.Config.ExposedPorts[nat.Port(port)] = struct{}{}
+		}
+	}
+	WriteResponse(w, http.StatusOK, inspect)
+}
+
+// HistoryImage returns the history of the layers of an image
+func HistoryImage(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	img, err := runtime.ImageRuntime().NewFromLocal(nameOrID(r))
+	if err != nil {
+		Error(w, http.StatusNotFound, err)
+		return
+	}
+	history, err := img.History(r.Context())
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	items := make([]imagetypes.HistoryResponseItem, 0, len(history))
+	for _, layer := range history {
+		item := imagetypes.HistoryResponseItem{
+			ID:        layer.ID,
+			CreatedBy: layer.CreatedBy,
+			Size:      layer.Size,
+			Comment:   layer.Comment,
+			Tags:      []string{},
+		}
+		if layer.Created != nil {
+			item.Created = layer.Created.Unix()
+		}
+		if layer.ID == img.ID() {
+			item.Tags = img.Names()
+		}
+		items = append(items, item)
+	}
+	WriteResponse(w, http.StatusOK, items)
+}
+
+// TagImage adds the tag given by the repo and tag query parameters to an
+// image
+func TagImage(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	repo := r.URL.Query().Get("repo")
+	if repo == "" {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "query parameter repo must be set"))
+		return
+	}
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		repo += ":" + tag
+	}
+	img, err := runtime.ImageRuntime().NewFromLocal(nameOrID(r))
+	if err != nil {
+		Error(w, http.StatusNotFound, err)
+		return
+	}
+	if err := img.TagImage(repo); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusCreated, nil)
+}
+
+// RemoveImage removes an image, or untags it if it has other names
+func RemoveImage(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	force, err := queryBool(r, "force", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	img, err := runtime.ImageRuntime().NewFromLocal(nameOrID(r))
+	if err != nil {
+		Error(w, http.StatusNotFound, err)
+		return
+	}
+	if !force {
+		ctrs, err := img.Containers()
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		if len(ctrs) > 0 && len(img.Names()) <= 1 {
+			Error(w, http.StatusConflict, errors.Errorf("image %s is being used by %d containers", img.ID(), len(ctrs)))
+			return
+		}
+	}
+	msg, err := runtime.RemoveImage(r.Context(), img, force)
+	if err != nil {
+		code := 0
+		if errors.Cause(err) == storage.ErrImageUsedByContainer {
+			code = http.StatusConflict
+		}
+		Error(w, code, err)
+		return
+	}
+	items := []types.ImageDeleteResponseItem{}
+	for _, line := range strings.Split(msg, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Untagged: "):
+			items = append(items, types.ImageDeleteResponseItem{Untagged: strings.TrimPrefix(line, "Untagged: ")})
+		case strings.HasPrefix(line, "Deleted: "):
+			items = append(items, types.ImageDeleteResponseItem{Deleted: strings.TrimPrefix(line, "Deleted: ")})
+		}
+	}
+	WriteResponse(w, http.StatusOK, items)
+}
+
+// PruneImages removes the dangling images, or all the images not used by
+// containers if the dangling filter is false
+func PruneImages(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	all := false
+	for _, dangling := range args.Get("dangling") {
+		all = dangling == "false" || dangling == "0"
+	}
+	images, err := runtime.ImageRuntime().GetPruneImages(all)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	var reclaimed uint64
+	for _, img := range images {
+		if size, err := img.Size(r.Context()); err == nil {
+			reclaimed += *size
+		}
+	}
+	pruned, err := runtime.ImageRuntime().PruneImages(r.Context(), all)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	report := types.ImagesPruneReport{ImagesDeleted: []types.ImageDeleteResponseItem{}, SpaceReclaimed: reclaimed}
+	for _, id := range pruned {
+		report.ImagesDeleted = append(report.ImagesDeleted, types.ImageDeleteResponseItem{Deleted: id})
+	}
+	WriteResponse(w, http.StatusOK, report)
+}
+
+// CreateImage pulls the image given by the fromImage and tag query
+// parameters, reporting the progress as a stream of JSON messages
+func CreateImage(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	name := r.URL.Query().Get("fromImage")
+	if name == "" {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "query parameter fromImage must be set"))
+		return
+	}
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		if strings.HasPrefix(tag, "sha256:") {
+			name += "@" + tag
+		} else {
+			name += ":" + tag
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	_ = encoder.Encode(models.ProgressMessage{Status: "Pulling from " + name})
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	img, err := runtime.ImageRuntime().New(r.Context(), name, "", "", nil, &image.DockerRegistryOptions{}, image.SigningOptions{}, nil, util.PullImageAlways)
+	if err != nil {
+		// The status code has been sent already, so errors are reported in
+		// the stream as Docker does
+		_ = encoder.Encode(models.ProgressMessage{Error: err.Error()})
+		return
+	}
+	_ = encoder.Encode(models.ProgressMessage{Status: "Downloaded newer image for " + name, ID: img.ID()})
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	cniconfig "github.com/containers/libpod/pkg/network"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/pkg/errors"
+)
+
+// cniIPAM is the part of the configuration of a CNI plugin describing the
+// addresses it allocates
+type cniIPAM struct {
+	IPAM struct {
+		Type   string `json:"type"`
+		Ranges [][]struct {
+			Subnet  string `json:"subnet"`
+			Gateway string `json:"gateway,omitempty"`
+		} `json:"ranges,omitempty"`
+		Subnet  string `json:"subnet,omitempty"`
+		Gateway string `json:"gateway,omitempty"`
+	} `json:"ipam"`
+}
+
+// bridgeConfTemplate is the CNI configuration list of the bridge networks
+// created through the API
+const bridgeConfTemplate = `{
+  "cniVersion": "0.4.0",
+  "name": %q,
+  "plugins": [
+    {
+      "type": "bridge",
+      "bridge": %q,
+      "isGateway": true,
+      "ipMasq": true,
+      "ipam": {
+        "type": "host-local",
+        "routes": [{"dst": "0.0.0.0/0"}],
+        "ranges": [[{"subnet": %q, "gateway": %q}]]
+      }
+    },
+    {
+      "type": "portmap",
+      "capabilities": {"portMappings": true}
+    },
+    {
+      "type": "firewall"
+    }
+  ]
+}
+`
+
+// cniConfigDir returns the directory holding the CNI configuration of the
+// networks of the runtime
+func cniConfigDir(runtime *libpod.Runtime) (string, error) {
+	config, err := runtime.GetConfig()
+	if err != nil {
+		return "", err
+	}
+	if config.CNIConfigDir != "" {
+		return config.CNIConfigDir, nil
+	}
+	return cniconfig.CNIConfigDir, nil
+}
+
+// networkIPAM returns the subnets of a network as described by its CNI
+// configuration
+func networkIPAM(n *libpod.Network) network.IPAM {
+	ipam := network.IPAM{Driver: "default", Config: []network.IPAMConfig{}}
+	conf, err := libcni.ConfListFromFile(n.ConfigPath())
+	if err != nil {
+		return ipam
+	}
+	for _, plugin := range conf.Plugins {
+		var pluginIPAM cniIPAM
+		if err := json.Unmarshal(plugin.Bytes, &pluginIPAM); err != nil || pluginIPAM.IPAM.Type == "" {
+			continue
+		}
+		if pluginIPAM.IPAM.Subnet != "" {
+			ipam.Config = append(ipam.Config, network.IPAMConfig{Subnet: pluginIPAM.IPAM.Subnet, Gateway: pluginIPAM.IPAM.Gateway})
+		}
+		for _, ranges := range pluginIPAM.IPAM.Ranges {
+			for _, r := range ranges {
+				ipam.Config = append(ipam.Config, network.IPAMConfig{Subnet: r.Subnet, Gateway: r.Gateway})
+			}
+		}
+	}
+	return ipam
+}
+
+// dockerNetwork converts a network into the format of the Docker API
+func dockerNetwork(runtime *libpod.Runtime, n *libpod.Network) (*types.NetworkResource, error) {
+	resource := &types.NetworkResource{
+		Name:       n.Name(),
+		ID:         n.Name(),
+		Created:    n.CreatedTime(),
+		Scope:      "local",
+		Driver:     n.Driver(),
+		IPAM:       networkIPAM(n),
+		Containers: map[string]types.EndpointResource{},
+		Options:    map[string]string{},
+		Labels:     n.Labels(),
+	}
+	ctrIDs, err := runtime.NetworkContainers(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ctrIDs {
+		ctr, err := runtime.LookupContainer(id)
+		if err != nil {
+			if errors.Cause(err) == define.ErrNoSuchCtr {
+				continue
+			}
+			return nil, err
+		}
+		resource.Containers[id] = types.EndpointResource{Name: ctr.Name()}
+	}
+	return resource, nil
+}
+
+// ListNetworks lists the networks
+func ListNetworks(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	nets, err := runtime.Networks(func(n *libpod.Network) bool {
+		return matchFilter(args, "name", func(name string) bool { return strings.Contains(n.Name(), name) }) &&
+			matchFilter(args, "driver", func(driver string) bool { return n.Driver() == driver }) &&
+			matchLabels(args, n.Labels())
+	})
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	list := make([]*types.NetworkResource, 0, len(nets))
+	for _, n := range nets {
+		resource, err := dockerNetwork(runtime, n)
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		list = append(list, resource)
+	}
+	WriteResponse(w, http.StatusOK, list)
+}
+
+// InspectNetwork returns a network in the format of the Docker API
+func InspectNetwork(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	n, err := runtime.GetNetwork(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	resource, err := dockerNetwork(runtime, n)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusOK, resource)
+}
+
+// freeSubnet returns the first /24 subnet of 10.89.0.0/16 which none of the
+// CNI configurations in the directory uses
+func freeSubnet(dir string) (*net.IPNet, error) {
+	confs, err := cniconfig.LoadCNIConfsFromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < 256; i++ {
+		subnet := &net.IPNet{IP: net.IPv4(10, 89, byte(i), 0).To4(), Mask: net.CIDRMask(24, 32)}
+		used := false
+		for _, conf := range confs {
+			if strings.Contains(string(conf.Bytes), fmt.Sprintf("%q", subnet.String())) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return subnet, nil
+		}
+	}
+	return nil, errors.Errorf("no free subnet left in 10.89.0.0/16")
+}
+
+// CreateNetwork creates a bridge network, writing its CNI configuration
+func CreateNetwork(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	var input types.NetworkCreateRequest
+	if err := decodeBody(r, &input); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if input.Name == "" {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "network name must be set"))
+		return
+	}
+	if input.Driver != "" && input.Driver != "bridge" {
+		Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrNotImplemented, "network driver %s is not supported", input.Driver))
+		return
+	}
+	if exists, err := runtime.HasNetwork(input.Name); err != nil || exists {
+		if err == nil {
+			err = errors.Wrapf(define.ErrNetworkExists, "network %s already exists", input.Name)
+		}
+		Error(w, 0, err)
+		return
+	}
+	dir, err := cniConfigDir(runtime)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+
+	var subnet *net.IPNet
+	var gateway net.IP
+	if input.IPAM != nil && len(input.IPAM.Config) > 0 {
+		if _, subnet, err = net.ParseCIDR(input.IPAM.Config[0].Subnet); err != nil {
+			Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "invalid subnet %q", input.IPAM.Config[0].Subnet))
+			return
+		}
+		if gw := input.IPAM.Config[0].Gateway; gw != "" {
+			if gateway = net.ParseIP(gw); gateway == nil {
+				Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "invalid gateway %q", gw))
+				return
+			}
+		}
+	} else if subnet, err = freeSubnet(dir); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if gateway == nil {
+		gateway = make(net.IP, len(subnet.IP))
+		copy(gateway, subnet.IP)
+		gateway[len(gateway)-1]++
+	}
+
+	nets, err := runtime.Networks()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	bridge := fmt.Sprintf("cni-podman%d", len(nets)+1)
+	path := filepath.Join(dir, input.Name+".conflist")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	conf := fmt.Sprintf(bridgeConfTemplate, input.Name, bridge, subnet.String(), gateway.String())
+	if err := ioutil.WriteFile(path, []byte(conf), 0644); err != nil {
+		Error(w, 0, errors.Wrapf(err, "error writing CNI configuration of network %s", input.Name))
+		return
+	}
+	n, err := runtime.NewNetwork(r.Context(), libpod.WithNetworkName(input.Name), libpod.WithNetworkDriver("bridge"),
+		libpod.WithNetworkConfigPath(path), libpod.WithNetworkLabels(input.Labels))
+	if err != nil {
+		os.Remove(path)
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusCreated, types.NetworkCreateResponse{ID: n.Name()})
+}
+
+// RemoveNetwork removes a network and its CNI configuration
+func RemoveNetwork(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	force, err := queryBool(r, "force", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	n, err := runtime.GetNetwork(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := runtime.RemoveNetwork(r.Context(), n, force); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := os.Remove(n.ConfigPath()); err != nil && !os.IsNotExist(err) {
+		Error(w, 0, errors.Wrapf(err, "error removing CNI configuration of network %s", n.Name()))
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// PruneNetworks removes the networks not used by any container
+func PruneNetworks(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	pruned, errs := runtime.PruneNetworks(r.Context(), func(n *libpod.Network) bool {
+		return matchLabels(args, n.Labels())
+	})
+	if len(errs) > 0 {
+		Error(w, 0, errs[0])
+		return
+	}
+	if pruned == nil {
+		pruned = []string{}
+	}
+	WriteResponse(w, http.StatusOK, types.NetworksPruneReport{NetworksDeleted: pruned})
+}
+
+// ConnectNetwork connects a container to a network
+func ConnectNetwork(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	var input types.NetworkConnect
+	if err := decodeBody(r, &input); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	n, err := runtime.GetNetwork(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(input.Container)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	var aliases []string
+	var staticIP net.IP
+	if settings := input.EndpointConfig; settings != nil {
+		aliases = settings.Aliases
+		if settings.IPAMConfig != nil && settings.IPAMConfig.IPv4Address != "" {
+			if staticIP = net.ParseIP(settings.IPAMConfig.IPv4Address); staticIP == nil {
+				Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg, "invalid address %q", settings.IPAMConfig.IPv4Address))
+				return
+			}
+		}
+	}
+	if err := ctr.NetworkConnect(n.Name(), aliases, staticIP); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusOK, nil)
+}
+
+// DisconnectNetwork disconnects a container from a network
+func DisconnectNetwork(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	var input types.NetworkDisconnect
+	if err := decodeBody(r, &input); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	n, err := runtime.GetNetwork(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	ctr, err := runtime.LookupContainer(input.Container)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := ctr.NetworkDisconnect(n.Name()); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/containers/libpod/cmd/podman/shared"
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/pkg/signal"
+)
+
+// ListPods lists the pods
+func ListPods(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	pods, err := runtime.Pods(func(p *libpod.Pod) bool {
+		return matchFilter(args, "id", func(id string) bool { return strings.HasPrefix(p.ID(), id) }) &&
+			matchFilter(args, "name", func(name string) bool { return strings.Contains(p.Name(), name) }) &&
+			matchLabels(args, p.Labels())
+	})
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	list := make([]models.PodSummary, 0, len(pods))
+	for _, pod := range pods {
+		status, err := shared.GetPodStatus(pod)
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		ctrs, err := pod.AllContainers()
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		infraID, err := pod.InfraContainerID()
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		list = append(list, models.PodSummary{
+			ID:            pod.ID(),
+			Name:          pod.Name(),
+			Status:        status,
+			Created:       pod.CreatedTime(),
+			Labels:        pod.Labels(),
+			InfraID:       infraID,
+			NumContainers: len(ctrs),
+		})
+	}
+	WriteResponse(w, http.StatusOK, list)
+}
+
+// CreatePod creates a pod
+func CreatePod(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	input := models.PodCreateConfig{Infra: true}
+	if err := decodeBody(r, &input); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	var options []libpod.PodCreateOption
+	if input.Name != "" {
+		options = append(options, libpod.WithPodName(input.Name))
+	}
+	if len(input.Labels) > 0 {
+		options = append(options, libpod.WithPodLabels(input.Labels))
+	}
+	if input.Hostname != "" {
+		options = append(options, libpod.WithPodHostname(input.Hostname))
+	}
+	if input.CgroupParent != "" {
+		options = append(options, libpod.WithPodCgroupParent(input.CgroupParent))
+	}
+	if input.Infra {
+		options = append(options, libpod.WithInfraContainer())
+		share := input.Share
+		if share == "" {
+			share = shared.DefaultKernelNamespaces
+		}
+		nsOptions, err := shared.GetNamespaceOptions(strings.Split(share, ","))
+		if err != nil {
+			Error(w, http.StatusBadRequest, err)
+			return
+		}
+		options = append(options, nsOptions...)
+		if input.InfraImage != "" {
+			options = append(options, libpod.WithInfraContainerImage(input.InfraImage))
+		}
+		if len(input.InfraCommand) > 0 {
+			options = append(options, libpod.WithInfraContainerCommand(input.InfraCommand))
+		}
+	}
+	if len(input.Publish) > 0 {
+		portBindings, err := shared.CreatePortBindings(input.Publish)
+		if err != nil {
+			Error(w, http.StatusBadRequest, err)
+			return
+		}
+		options = append(options, libpod.WithInfraContainerPorts(portBindings))
+	}
+	pod, err := runtime.NewPod(r.Context(), options...)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusCreated, models.PodActionReport{ID: pod.ID()})
+}
+
+// InspectPod returns the configuration and state of a pod
+func InspectPod(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	pod, err := runtime.LookupPod(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	inspect, err := pod.Inspect()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	status, err := shared.GetPodStatus(pod)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	report := models.PodInspect{
+		ID:               inspect.Config.ID,
+		Name:             inspect.Config.Name,
+		Hostname:         inspect.Config.Hostname,
+		Labels:           inspect.Config.Labels,
+		Created:          inspect.Config.CreatedTime,
+		CgroupParent:     inspect.Config.CgroupParent,
+		CgroupPath:       inspect.State.CgroupPath,
+		InfraContainerID: inspect.State.InfraContainerID,
+		Status:           status,
+		Containers:       make([]models.PodContainer, 0, len(inspect.Containers)),
+	}
+	for _, info := range inspect.Containers {
+		podCtr := models.PodContainer{ID: info.ID, State: info.State}
+		if ctr, err := runtime.LookupContainer(info.ID); err == nil {
+			podCtr.Name = ctr.Name()
+		}
+		report.Containers = append(report.Containers, podCtr)
+	}
+	WriteResponse(w, http.StatusOK, report)
+}
+
+// RemovePod removes a pod and its containers, stopping them first if the
+// force query parameter is set
+func RemovePod(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	force, err := queryBool(r, "force", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	pod, err := runtime.LookupPod(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := runtime.RemovePod(r.Context(), pod, true, force); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// podAction returns a handler running the action on the pod the request is
+// about, and reporting the containers the action failed on
+func podAction(action func(r *http.Request, pod *libpod.Pod) (map[string]error, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		runtime := getRuntime(r)
+		pod, err := runtime.LookupPod(nameOrID(r))
+		if err != nil {
+			Error(w, 0, err)
+			return
+		}
+		ctrErrs, err := action(r, pod)
+		if err != nil && len(ctrErrs) == 0 {
+			Error(w, 0, err)
+			return
+		}
+		report := models.PodActionReport{ID: pod.ID()}
+		if len(ctrErrs) > 0 {
+			report.Errs = make(map[string]string, len(ctrErrs))
+			for id, ctrErr := range ctrErrs {
+				report.Errs[id] = ctrErr.Error()
+			}
+			WriteResponse(w, http.StatusConflict, report)
+			return
+		}
+		WriteResponse(w, http.StatusOK, report)
+	}
+}
+
+// StartPod starts the containers of a pod
+var StartPod = podAction(func(r *http.Request, pod *libpod.Pod) (map[string]error, error) {
+	return pod.Start(r.Context())
+})
+
+// StopPod stops the containers of a pod, killing them if they do not stop
+// within the timeout given by the t query parameter
+var StopPod = podAction(func(r *http.Request, pod *libpod.Pod) (map[string]error, error) {
+	timeout, err := queryInt(r, "t", -1)
+	if err != nil {
+		return nil, err
+	}
+	return pod.StopWithTimeout(r.Context(), false, timeout)
+})
+
+// RestartPod restarts the containers of a pod
+var RestartPod = podAction(func(r *http.Request, pod *libpod.Pod) (map[string]error, error) {
+	return pod.Restart(r.Context())
+})
+
+// KillPod sends the signal given by the signal query parameter, or SIGKILL,
+// to the containers of a pod
+var KillPod = podAction(func(r *http.Request, pod *libpod.Pod) (map[string]error, error) {
+	sig := r.URL.Query().Get("signal")
+	if sig == "" {
+		sig = "KILL"
+	}
+	parsed, err := signal.ParseSignal(sig)
+	if err != nil {
+		return nil, err
+	}
+	return pod.Kill(uint(parsed))
+})
+
+// PausePod pauses the containers of a pod
+var PausePod = podAction(func(r *http.Request, pod *libpod.Pod) (map[string]error, error) {
+	return pod.Pause()
+})
+
+// UnpausePod unpauses the containers of a pod
+var UnpausePod = podAction(func(r *http.Request, pod *libpod.Pod) (map[string]error, error) {
+	return pod.Unpause()
+})
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	goruntime "runtime"
+	"time"
+
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/api/types"
+)
+
+// Ping answers the requests checking that the server is up
+func Ping(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("API-Version", models.APIVersion)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte("OK"))
+	}
+}
+
+// Version returns the versions of podman and of the API
+func Version(w http.ResponseWriter, r *http.Request) {
+	version, err := define.GetVersion()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	info, err := getRuntime(r).Info()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	var kernel string
+	for _, data := range info {
+		if data.Type == "host" {
+			kernel, _ = data.Data["kernel"].(string)
+		}
+	}
+	var buildTime string
+	if version.Built != 0 {
+		buildTime = time.Unix(version.Built, 0).Format(time.RFC3339)
+	}
+	WriteResponse(w, http.StatusOK, types.Version{
+		Platform: struct{ Name string }{Name: "podman"},
+		Components: []types.ComponentVersion{{
+			Name:    "Podman Engine",
+			Version: version.Version,
+			Details: map[string]string{"APIVersion": models.APIVersion, "GitCommit": version.GitCommit},
+		}},
+		Version:       version.Version,
+		APIVersion:    models.APIVersion,
+		MinAPIVersion: models.MinAPIVersion,
+		GitCommit:     version.GitCommit,
+		GoVersion:     version.GoVersion,
+		Os:            goruntime.GOOS,
+		Arch:          goruntime.GOARCH,
+		KernelVersion: kernel,
+		BuildTime:     buildTime,
+	})
+}
+
+// Info returns a summary of the host and of the containers and images of the
+// runtime, in the format of the Docker API
+func Info(w http.ResponseWriter, r *http.Request) {
+	rt := getRuntime(r)
+	info, err := rt.Info()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	version, err := define.GetVersion()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	dockerInfo := types.Info{
+		OSType:        goruntime.GOOS,
+		Architecture:  goruntime.GOARCH,
+		NCPU:          goruntime.NumCPU(),
+		NGoroutines:   goruntime.NumGoroutine(),
+		SystemTime:    time.Now().Format(time.RFC3339Nano),
+		ServerVersion: version.Version,
+		LoggingDriver: "k8s-file",
+		Warnings:      []string{},
+	}
+	dockerInfo.Name, _ = os.Hostname()
+	for _, data := range info {
+		switch data.Type {
+		case "host":
+			dockerInfo.KernelVersion, _ = data.Data["kernel"].(string)
+			if memTotal, ok := data.Data["MemTotal"].(int64); ok {
+				dockerInfo.MemTotal = memTotal
+			}
+		case "store":
+			dockerInfo.Driver, _ = data.Data["GraphDriverName"].(string)
+			dockerInfo.DockerRootDir, _ = data.Data["GraphRoot"].(string)
+		}
+	}
+	config, err := rt.GetConfig()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	dockerInfo.CgroupDriver = config.CgroupManager
+	dockerInfo.DefaultRuntime = config.OCIRuntime
+
+	ctrs, err := rt.GetAllContainers()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	for _, ctr := range ctrs {
+		state, err := ctr.State()
+		if err != nil {
+			continue
+		}
+		dockerInfo.Containers++
+		switch state {
+		case define.ContainerStateRunning:
+			dockerInfo.ContainersRunning++
+		case define.ContainerStatePaused:
+			dockerInfo.ContainersPaused++
+		default:
+			dockerInfo.ContainersStopped++
+		}
+	}
+	images, err := rt.ImageRuntime().GetImages()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	dockerInfo.Images = len(images)
+	WriteResponse(w, http.StatusOK, dockerInfo)
+}
+
+// LibpodInfo returns the information podman info prints
+func LibpodInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := getRuntime(r).Info()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	data := make(map[string]interface{}, len(info))
+	for _, i := range info {
+		data[i.Type] = i.Data
+	}
+	WriteResponse(w, http.StatusOK, data)
+}
+
+// DiskUsage returns the disk space used by the images, containers and
+// volumes, in the format of the Docker API
+func DiskUsage(w http.ResponseWriter, r *http.Request) {
+	usage, err := getRuntime(r).SystemDiskUsage(r.Context())
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	df := types.DiskUsage{
+		Images:     make([]*types.ImageSummary, 0, len(usage.Images)),
+		Containers: make([]*types.Container, 0, len(usage.Containers)),
+		Volumes:    make([]*types.Volume, 0, len(usage.Volumes)),
+	}
+	for _, img := range usage.Images {
+		df.LayersSize += int64(img.UniqueSize)
+		df.Images = append(df.Images, &types.ImageSummary{
+			ID:          "sha256:" + img.ID,
+			RepoTags:    img.Names,
+			Created:     img.Created.Unix(),
+			Size:        int64(img.Size),
+			SharedSize:  int64(img.SharedSize),
+			VirtualSize: int64(img.Size),
+			Containers:  int64(img.Containers),
+		})
+	}
+	for _, ctr := range usage.Containers {
+		df.Containers = append(df.Containers, &types.Container{
+			ID:      ctr.ID,
+			Names:   []string{"/" + ctr.Name},
+			ImageID: ctr.ImageID,
+			Created: ctr.Created.Unix(),
+			SizeRw:  int64(ctr.Size),
+			State:   dockerState(ctr.State),
+		})
+	}
+	for _, vol := range usage.Volumes {
+		df.Volumes = append(df.Volumes, &types.Volume{
+			Name:      vol.Name,
+			UsageData: &types.VolumeUsageData{RefCount: int64(vol.Containers), Size: int64(vol.Used)},
+		})
+	}
+	WriteResponse(w, http.StatusOK, df)
+}
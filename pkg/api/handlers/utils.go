@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+type contextKey int
+
+const runtimeKey contextKey = iota
+
+// WithRuntime returns a context giving the handlers the runtime they serve
+// requests with
+func WithRuntime(ctx context.Context, runtime *libpod.Runtime) context.Context {
+	return context.WithValue(ctx, runtimeKey, runtime)
+}
+
+// getRuntime returns the runtime serving the request
+func getRuntime(r *http.Request) *libpod.Runtime {
+	return r.Context().Value(runtimeKey).(*libpod.Runtime)
+}
+
+// WriteResponse writes the value as the JSON body of a response with the
+// given status code
+func WriteResponse(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if value == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logrus.Errorf("Error writing API response: %v", err)
+	}
+}
+
+// Error writes the error as the response of a failed request. The status
+// code is derived from the cause of the error, unless code is positive.
+func Error(w http.ResponseWriter, code int, err error) {
+	if code <= 0 {
+		code = errorCode(err)
+	}
+	if code >= http.StatusInternalServerError {
+		logrus.Errorf("Error serving API request: %v", err)
+	} else {
+		logrus.Debugf("API request failed: %v", err)
+	}
+	WriteResponse(w, code, models.ErrorModel{Message: err.Error()})
+}
+
+// errorCode returns the status code of the response of a request failing
+// with the error
+func errorCode(err error) int {
+	switch errors.Cause(err) {
+	case define.ErrNoSuchCtr, define.ErrNoSuchPod, define.ErrNoSuchVolume, define.ErrNoSuchNetwork, define.ErrNoSuchImage,
+		define.ErrNoSuchExecSession:
+		return http.StatusNotFound
+	case define.ErrCtrExists, define.ErrPodExists, define.ErrVolumeExists, define.ErrNetworkExists, define.ErrImageExists,
+		define.ErrCtrStateInvalid, define.ErrVolumeBeingUsed, define.ErrNetworkBeingUsed:
+		return http.StatusConflict
+	case define.ErrInvalidArg, define.ErrEmptyID:
+		return http.StatusBadRequest
+	case define.ErrNotImplemented, define.ErrOSNotSupported:
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// nameOrID returns the name or ID of the object the request is about
+func nameOrID(r *http.Request) string {
+	return mux.Vars(r)["name"]
+}
+
+// queryBool parses a boolean query parameter, accepting 1 and 0 as Docker
+// clients send them
+func queryBool(r *http.Request, name string, defaultValue bool) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Wrapf(define.ErrInvalidArg, "invalid value %q for query parameter %s", value, name)
+	}
+	return b, nil
+}
+
+// queryInt parses an integer query parameter
+func queryInt(r *http.Request, name string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Wrapf(define.ErrInvalidArg, "invalid value %q for query parameter %s", value, name)
+	}
+	return i, nil
+}
+
+// queryFilters parses the filters query parameter, a JSON encoded map of
+// filter names to the values they accept
+func queryFilters(r *http.Request) (filters.Args, error) {
+	args, err := filters.FromJSON(r.URL.Query().Get("filters"))
+	if err != nil {
+		return args, errors.Wrapf(define.ErrInvalidArg, "invalid filters: %v", err)
+	}
+	return args, nil
+}
+
+// decodeBody decodes the JSON body of the request into value. An empty body
+// leaves value unchanged.
+func decodeBody(r *http.Request, value interface{}) error {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil && err != io.EOF {
+		return errors.Wrapf(define.ErrInvalidArg, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// matchLabels returns whether the labels match all the label filters, given
+// as KEY or KEY=VALUE
+func matchLabels(args filters.Args, labels map[string]string) bool {
+	return args.MatchKVList("label", labels)
+}
+
+// matchFilter returns whether one of the values of the named filter is
+// matched, or whether the filter is not set
+func matchFilter(args filters.Args, name string, match func(string) bool) bool {
+	values := args.Get(name)
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamWriter writes the body of a streaming response, flushing it after
+// every write. It may be written to concurrently.
+type streamWriter struct {
+	lock    sync.Mutex
+	w       io.Writer
+	flusher http.Flusher
+}
+
+// Write writes p to the response and flushes it
+func (s *streamWriter) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n, err := s.w.Write(p)
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return n, err
+}
+
+// writeCloser is a writer whose Close does nothing, as the response is
+// closed by the server
+type writeCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (writeCloser) Close() error {
+	return nil
+}
+
+// outputStreams writes the header of a streaming response with the output
+// of a container, and returns the streams its standard output and error are
+// written to
+func outputStreams(w http.ResponseWriter, tty bool) (io.WriteCloser, io.WriteCloser) {
+	w.Header().Set("Content-Type", streamContentType(tty))
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	return splitStreams(&streamWriter{w: w, flusher: flusher}, tty)
+}
+
+// streamContentType returns the content type of the streams of the output
+// of a container. Unless the container has a terminal, the streams are
+// multiplexed as in the Docker API, which the content type tells clients.
+func streamContentType(tty bool) string {
+	if tty {
+		return models.RawContentType
+	}
+	return models.MultiplexedContentType
+}
+
+// splitStreams returns the streams the standard output and error of a
+// container are written to on the stream, multiplexing them unless the
+// container has a terminal
+func splitStreams(stream io.Writer, tty bool) (io.WriteCloser, io.WriteCloser) {
+	if tty {
+		return writeCloser{stream}, writeCloser{stream}
+	}
+	return writeCloser{stdcopy.NewStdWriter(stream, stdcopy.Stdout)}, writeCloser{stdcopy.NewStdWriter(stream, stdcopy.Stderr)}
+}
+
+// hijack takes over the connection of the request to stream on it in both
+// directions, as the Docker API does to attach to containers. The connection
+// is upgraded if the client asked to. Reads from the client must go through
+// the returned reader, which holds what the server has already buffered.
+func hijack(w http.ResponseWriter, r *http.Request, contentType string) (net.Conn, *bufio.Reader, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.Wrapf(define.ErrNotImplemented, "the connection of the request can not be hijacked")
+	}
+	conn, buffer, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error hijacking connection")
+	}
+	status := "200 OK"
+	header := ""
+	if r.Header.Get("Upgrade") != "" {
+		status = "101 UPGRADED"
+		header = "Connection: Upgrade\r\nUpgrade: tcp\r\n"
+	}
+	if _, err := fmt.Fprintf(conn, "HTTP/1.1 %s\r\nContent-Type: %s\r\n%s\r\n", status, contentType, header); err != nil {
+		conn.Close()
+		return nil, nil, errors.Wrapf(err, "error writing response header")
+	}
+	return conn, buffer.Reader, nil
+}
+
+// closeHijacked closes a hijacked connection, first closing its write side
+// so that the client reads the end of the stream before the connection is
+// torn down
+func closeHijacked(conn net.Conn) {
+	closeWrite(conn)
+	if err := conn.Close(); err != nil {
+		logrus.Debugf("Error closing hijacked connection: %v", err)
+	}
+}
+
+// closeWrite closes the write side of the connection, if it can be closed on
+// its own
+func closeWrite(conn net.Conn) {
+	if closer, ok := conn.(interface{ CloseWrite() error }); ok {
+		if err := closer.CloseWrite(); err != nil {
+			logrus.Debugf("Error closing the write side of a connection: %v", err)
+		}
+	}
+}
+
+// terminalSize parses the h and w query parameters of the requests resizing
+// terminals
+func terminalSize(r *http.Request) (remotecommand.TerminalSize, error) {
+	var size remotecommand.TerminalSize
+	height, err := queryInt(r, "h", 0)
+	if err != nil {
+		return size, err
+	}
+	width, err := queryInt(r, "w", 0)
+	if err != nil {
+		return size, err
+	}
+	if height <= 0 || width <= 0 || height > math.MaxUint16 || width > math.MaxUint16 {
+		return size, errors.Wrapf(define.ErrInvalidArg, "invalid terminal size %dx%d", width, height)
+	}
+	size.Height, size.Width = uint16(height), uint16(width)
+	return size, nil
+}
+
+// hasTerminal returns whether the process of the container has a terminal
+func hasTerminal(ctr *libpod.Container) bool {
+	spec := ctr.Config().Spec
+	return spec != nil && spec.Process != nil && spec.Process.Terminal
+}
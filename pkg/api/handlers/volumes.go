@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// dockerVolume converts a volume into the format of the Docker API
+func dockerVolume(vol *libpod.Volume) *types.Volume {
+	return &types.Volume{
+		Name:       vol.Name(),
+		Driver:     vol.Driver(),
+		Mountpoint: vol.MountPoint(),
+		CreatedAt:  vol.CreatedTime().Format(time.RFC3339),
+		Labels:     vol.Labels(),
+		Options:    vol.Options(),
+		Scope:      vol.Scope(),
+	}
+}
+
+// filterVolume returns whether the volume matches the filters of a volume
+// list request
+func filterVolume(args filters.Args, vol *libpod.Volume) bool {
+	if !matchFilter(args, "name", func(name string) bool { return strings.Contains(vol.Name(), name) }) {
+		return false
+	}
+	if !matchFilter(args, "driver", func(driver string) bool { return vol.Driver() == driver }) {
+		return false
+	}
+	return matchLabels(args, vol.Labels())
+}
+
+// ListVolumes lists the volumes
+func ListVolumes(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	vols, err := runtime.GetAllVolumes()
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	list := volume.VolumeListOKBody{Volumes: []*types.Volume{}, Warnings: []string{}}
+	for _, vol := range vols {
+		if filterVolume(args, vol) {
+			list.Volumes = append(list.Volumes, dockerVolume(vol))
+		}
+	}
+	WriteResponse(w, http.StatusOK, list)
+}
+
+// CreateVolume creates a volume
+func CreateVolume(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	var input volume.VolumeCreateBody
+	if err := decodeBody(r, &input); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	var options []libpod.VolumeCreateOption
+	if input.Name != "" {
+		options = append(options, libpod.WithVolumeName(input.Name))
+	}
+	if input.Driver != "" {
+		options = append(options, libpod.WithVolumeDriver(input.Driver))
+	}
+	if len(input.Labels) > 0 {
+		options = append(options, libpod.WithVolumeLabels(input.Labels))
+	}
+	if len(input.DriverOpts) > 0 {
+		options = append(options, libpod.WithVolumeOptions(input.DriverOpts))
+	}
+	vol, err := runtime.NewVolume(r.Context(), options...)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusCreated, dockerVolume(vol))
+}
+
+// InspectVolume returns a volume in the format of the Docker API
+func InspectVolume(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	vol, err := runtime.GetVolume(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusOK, dockerVolume(vol))
+}
+
+// RemoveVolume removes a volume, and the containers using it if the force
+// query parameter is set
+func RemoveVolume(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	force, err := queryBool(r, "force", false)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	vol, err := runtime.GetVolume(nameOrID(r))
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	if err := runtime.RemoveVolume(r.Context(), vol, force); err != nil {
+		Error(w, 0, err)
+		return
+	}
+	WriteResponse(w, http.StatusNoContent, nil)
+}
+
+// PruneVolumes removes the volumes not used by any container
+func PruneVolumes(w http.ResponseWriter, r *http.Request) {
+	runtime := getRuntime(r)
+	args, err := queryFilters(r)
+	if err != nil {
+		Error(w, 0, err)
+		return
+	}
+	pruned, reclaimed, errs := runtime.PruneVolumes(r.Context(), func(vol *libpod.Volume) bool {
+		return matchLabels(args, vol.Labels())
+	})
+	if len(errs) > 0 {
+		Error(w, 0, errs[0])
+		return
+	}
+	if pruned == nil {
+		pruned = []string{}
+	}
+	WriteResponse(w, http.StatusOK, types.VolumesPruneReport{VolumesDeleted: pruned, SpaceReclaimed: reclaimed})
+}
@@ -0,0 +1,104 @@
+// Package models holds the types of the bodies of the requests and responses
+// of the REST API which are not defined by the Docker API. It is shared by
+// the server and its clients, and must not depend on libpod.
+package models
+
+import (
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+const (
+	// MultiplexedContentType is the type of the streams of the output of
+	// containers without terminal, multiplexing their standard output and
+	// error
+	MultiplexedContentType = "application/vnd.docker.multiplexed-stream"
+	// RawContentType is the type of the streams of the output of containers
+	// with a terminal
+	RawContentType = "application/vnd.docker.raw-stream"
+	// APIVersion is the version of the Docker API served
+	APIVersion = "1.40"
+	// MinAPIVersion is the oldest version of the Docker API whose requests
+	// are accepted
+	MinAPIVersion = "1.24"
+)
+
+// ErrorModel is the body of the responses of failed requests, as returned by
+// the Docker API
+type ErrorModel struct {
+	Message string `json:"message"`
+}
+
+// ProgressMessage is a line of the JSON stream answering the requests pulling
+// images, as written by the Docker API
+type ProgressMessage struct {
+	Status string `json:"status,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CreateContainerConfig is the body of the requests creating containers
+type CreateContainerConfig struct {
+	container.Config
+	HostConfig       container.HostConfig
+	NetworkingConfig network.NetworkingConfig
+}
+
+// PodCreateConfig is the body of the requests creating pods
+type PodCreateConfig struct {
+	Name         string            `json:"name"`
+	Labels       map[string]string `json:"labels"`
+	Hostname     string            `json:"hostname"`
+	CgroupParent string            `json:"cgroupParent"`
+	// Infra is whether the pod gets an infra container holding its
+	// namespaces
+	Infra        bool     `json:"infra"`
+	InfraImage   string   `json:"infraImage"`
+	InfraCommand []string `json:"infraCommand"`
+	// Share lists the kernel namespaces shared by the containers of the
+	// pod, comma separated
+	Share   string   `json:"share"`
+	Publish []string `json:"publish"`
+}
+
+// PodSummary is a pod as listed by the API
+type PodSummary struct {
+	ID            string            `json:"Id"`
+	Name          string            `json:"Name"`
+	Status        string            `json:"Status"`
+	Created       time.Time         `json:"Created"`
+	Labels        map[string]string `json:"Labels"`
+	InfraID       string            `json:"InfraId"`
+	NumContainers int               `json:"NumContainers"`
+}
+
+// PodInspect is the configuration and state of a pod, as inspected through
+// the API
+type PodInspect struct {
+	ID               string            `json:"Id"`
+	Name             string            `json:"Name"`
+	Hostname         string            `json:"Hostname,omitempty"`
+	Labels           map[string]string `json:"Labels"`
+	Created          time.Time         `json:"Created"`
+	CgroupParent     string            `json:"CgroupParent"`
+	CgroupPath       string            `json:"CgroupPath"`
+	InfraContainerID string            `json:"InfraContainerId,omitempty"`
+	Status           string            `json:"Status"`
+	Containers       []PodContainer    `json:"Containers"`
+}
+
+// PodContainer is a container of an inspected pod
+type PodContainer struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State string `json:"State"`
+}
+
+// PodActionReport is the answer to the requests acting on all the containers
+// of a pod, giving the errors the containers failed with
+type PodActionReport struct {
+	ID   string            `json:"Id"`
+	Errs map[string]string `json:"Errs,omitempty"`
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/containers/libpod/pkg/api/handlers"
+	"github.com/gorilla/mux"
+)
+
+func registerContainersHandlers(r *mux.Router) {
+	r.HandleFunc("/containers/json", handlers.ListContainers).Methods(http.MethodGet)
+	r.HandleFunc("/containers/create", handlers.CreateContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/prune", handlers.PruneContainers).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/json", handlers.InspectContainer).Methods(http.MethodGet)
+	r.HandleFunc("/containers/{name}/logs", handlers.LogsFromContainer).Methods(http.MethodGet)
+	r.HandleFunc("/containers/{name}/start", handlers.StartContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/stop", handlers.StopContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/restart", handlers.RestartContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/kill", handlers.KillContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/pause", handlers.PauseContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/unpause", handlers.UnpauseContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/wait", handlers.WaitContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/attach", handlers.AttachContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/resize", handlers.ResizeContainer).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}/exec", handlers.CreateExec).Methods(http.MethodPost)
+	r.HandleFunc("/containers/{name}", handlers.RemoveContainer).Methods(http.MethodDelete)
+	r.HandleFunc("/exec/{id}/start", handlers.StartExec).Methods(http.MethodPost)
+	r.HandleFunc("/exec/{id}/resize", handlers.ResizeExec).Methods(http.MethodPost)
+	r.HandleFunc("/exec/{id}/json", handlers.InspectExec).Methods(http.MethodGet)
+}
+
+func registerLibpodContainersHandlers(r *mux.Router) {
+	r.HandleFunc("/containers/{name}/portforward", handlers.PortForwardContainer).Methods(http.MethodPost)
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/containers/libpod/pkg/api/handlers"
+	"github.com/gorilla/mux"
+)
+
+func registerImagesHandlers(r *mux.Router) {
+	r.HandleFunc("/images/json", handlers.ListImages).Methods(http.MethodGet)
+	r.HandleFunc("/images/create", handlers.CreateImage).Methods(http.MethodPost)
+	r.HandleFunc("/images/prune", handlers.PruneImages).Methods(http.MethodPost)
+	// Image names may contain slashes
+	r.HandleFunc("/images/{name:.*}/json", handlers.InspectImage).Methods(http.MethodGet)
+	r.HandleFunc("/images/{name:.*}/history", handlers.HistoryImage).Methods(http.MethodGet)
+	r.HandleFunc("/images/{name:.*}/tag", handlers.TagImage).Methods(http.MethodPost)
+	r.HandleFunc("/images/{name:.*}", handlers.RemoveImage).Methods(http.MethodDelete)
+}
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/containers/libpod/pkg/api/handlers"
+	"github.com/gorilla/mux"
+)
+
+func registerNetworksHandlers(r *mux.Router) {
+	r.HandleFunc("/networks", handlers.ListNetworks).Methods(http.MethodGet)
+	r.HandleFunc("/networks/create", handlers.CreateNetwork).Methods(http.MethodPost)
+	r.HandleFunc("/networks/prune", handlers.PruneNetworks).Methods(http.MethodPost)
+	r.HandleFunc("/networks/{name}", handlers.InspectNetwork).Methods(http.MethodGet)
+	r.HandleFunc("/networks/{name}", handlers.RemoveNetwork).Methods(http.MethodDelete)
+	r.HandleFunc("/networks/{name}/connect", handlers.ConnectNetwork).Methods(http.MethodPost)
+	r.HandleFunc("/networks/{name}/disconnect", handlers.DisconnectNetwork).Methods(http.MethodPost)
+}
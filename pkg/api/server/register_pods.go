@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/containers/libpod/pkg/api/handlers"
+	"github.com/gorilla/mux"
+)
+
+func registerPodsHandlers(r *mux.Router) {
+	r.HandleFunc("/pods/json", handlers.ListPods).Methods(http.MethodGet)
+	r.HandleFunc("/pods/create", handlers.CreatePod).Methods(http.MethodPost)
+	r.HandleFunc("/pods/{name}/json", handlers.InspectPod).Methods(http.MethodGet)
+	r.HandleFunc("/pods/{name}/start", handlers.StartPod).Methods(http.MethodPost)
+	r.HandleFunc("/pods/{name}/stop", handlers.StopPod).Methods(http.MethodPost)
+	r.HandleFunc("/pods/{name}/restart", handlers.RestartPod).Methods(http.MethodPost)
+	r.HandleFunc("/pods/{name}/kill", handlers.KillPod).Methods(http.MethodPost)
+	r.HandleFunc("/pods/{name}/pause", handlers.PausePod).Methods(http.MethodPost)
+	r.HandleFunc("/pods/{name}/unpause", handlers.UnpausePod).Methods(http.MethodPost)
+	r.HandleFunc("/pods/{name}", handlers.RemovePod).Methods(http.MethodDelete)
+}
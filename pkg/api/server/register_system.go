@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/containers/libpod/pkg/api/handlers"
+	"github.com/gorilla/mux"
+)
+
+func registerSystemHandlers(r *mux.Router) {
+	for _, prefix := range []string{"", "/libpod"} {
+		r.HandleFunc(prefix+"/_ping", handlers.Ping).Methods(http.MethodGet, http.MethodHead)
+		r.HandleFunc(prefix+"/version", handlers.Version).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/system/df", handlers.DiskUsage).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/events", handlers.GetEvents).Methods(http.MethodGet)
+	}
+	r.HandleFunc("/info", handlers.Info).Methods(http.MethodGet)
+	r.HandleFunc("/libpod/info", handlers.LibpodInfo).Methods(http.MethodGet)
+}
@@ -0,0 +1,16 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/containers/libpod/pkg/api/handlers"
+	"github.com/gorilla/mux"
+)
+
+func registerVolumesHandlers(r *mux.Router) {
+	r.HandleFunc("/volumes", handlers.ListVolumes).Methods(http.MethodGet)
+	r.HandleFunc("/volumes/create", handlers.CreateVolume).Methods(http.MethodPost)
+	r.HandleFunc("/volumes/prune", handlers.PruneVolumes).Methods(http.MethodPost)
+	r.HandleFunc("/volumes/{name}", handlers.InspectVolume).Methods(http.MethodGet)
+	r.HandleFunc("/volumes/{name}", handlers.RemoveVolume).Methods(http.MethodDelete)
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containers/libpod/libpod"
+	"github.com/containers/libpod/libpod/define"
+	"github.com/containers/libpod/pkg/api/handlers"
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// versionPrefix matches the API version the paths of the requests may start
+// with, e.g. /v1.40
+var versionPrefix = regexp.MustCompile(`^/v([0-9]+(\.[0-9]+)*)(/|$)`)
+
+// APIServer serves the Docker-compatible REST API, and the podman specific
+// endpoints under /libpod, for a runtime
+type APIServer struct {
+	http.Server
+	// Listener is the listener the requests are accepted on
+	Listener net.Listener
+	// Router routes the requests to the handlers
+	Router *mux.Router
+
+	runtime     *libpod.Runtime
+	idleTimeout time.Duration
+	idle        *idleTracker
+}
+
+// NewServer returns a server for the runtime accepting requests on the
+// listener. Unless idleTimeout is 0, the server stops once it has had no
+// connection for that long.
+func NewServer(runtime *libpod.Runtime, listener net.Listener, idleTimeout time.Duration) *APIServer {
+	router := mux.NewRouter()
+	s := &APIServer{
+		Listener:    listener,
+		Router:      router,
+		runtime:     runtime,
+		idleTimeout: idleTimeout,
+	}
+	s.Server.Handler = s
+	if idleTimeout > 0 {
+		s.idle = newIdleTracker(idleTimeout)
+		s.Server.ConnState = s.idle.connState
+	}
+
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers.Error(w, http.StatusNotFound, errors.Errorf("page not found: %s %s", r.Method, r.URL.Path))
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers.Error(w, http.StatusMethodNotAllowed, errors.Errorf("method %s not allowed for %s", r.Method, r.URL.Path))
+	})
+	registerSystemHandlers(router)
+	libpodRouter := router.PathPrefix("/libpod").Subrouter()
+	// The Docker-compatible endpoints are served under /libpod too
+	for _, r := range []*mux.Router{router, libpodRouter} {
+		registerContainersHandlers(r)
+		registerImagesHandlers(r)
+		registerVolumesHandlers(r)
+		registerNetworksHandlers(r)
+	}
+	registerLibpodContainersHandlers(libpodRouter)
+	registerPodsHandlers(libpodRouter)
+	return s
+}
+
+// ServeHTTP strips the API version from the path of the request, rejecting
+// versions older than the oldest supported, and routes it with the runtime
+// of the server in its context
+func (s *APIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logrus.Debugf("API request: %s %s", r.Method, r.URL.String())
+	if match := versionPrefix.FindStringSubmatch(r.URL.Path); match != nil {
+		if versions.LessThan(match[1], models.MinAPIVersion) {
+			handlers.Error(w, http.StatusBadRequest, errors.Wrapf(define.ErrInvalidArg,
+				"client version %s is too old, the minimum supported API version is %s", match[1], models.MinAPIVersion))
+			return
+		}
+		r.URL.Path = "/" + strings.TrimPrefix(r.URL.Path[len(match[0]):], "/")
+		r.URL.RawPath = ""
+	}
+	w.Header().Set("API-Version", models.APIVersion)
+	w.Header().Set("Server", "Libpod/"+models.APIVersion)
+	if s.runtime != nil {
+		r = r.WithContext(handlers.WithRuntime(r.Context(), s.runtime))
+	}
+	if s.idle != nil {
+		// Hijacked connections are no longer tracked by the server,
+		// the requests streaming on them are
+		s.idle.requestStarted()
+		defer s.idle.requestDone()
+	}
+	s.Router.ServeHTTP(w, r)
+}
+
+// Serve accepts requests until the server is shut down, the context is
+// cancelled, or the server has been idle for too long
+func (s *APIServer) Serve(ctx context.Context) error {
+	done := make(chan struct{})
+	defer close(done)
+	var idle <-chan time.Time
+	if s.idle != nil {
+		idle = s.idle.expired()
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			logrus.Debugf("API service stopping")
+		case <-idle:
+			logrus.Infof("API service expired after %s without connections", s.idleTimeout)
+		case <-done:
+			return
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			logrus.Errorf("Error shutting down API service: %v", err)
+		}
+	}()
+	if err := s.Server.Serve(s.Listener); err != nil && err != http.ErrServerClosed {
+		return errors.Wrapf(err, "error serving API on %s", s.Listener.Addr())
+	}
+	return nil
+}
+
+// NewListener returns a listener on the address, given as unix:///path,
+// tcp://host:port or host:port. TCP connections are served over TLS if
+// tlsConfig is set.
+func NewListener(address string, tlsConfig *tls.Config) (net.Listener, error) {
+	network := "tcp"
+	if split := strings.SplitN(address, "://", 2); len(split) == 2 {
+		network, address = split[0], split[1]
+	}
+	switch network {
+	case "unix":
+		if err := os.MkdirAll(filepath.Dir(address), 0755); err != nil {
+			return nil, errors.Wrapf(err, "error creating directory of API socket %s", address)
+		}
+		if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "error removing API socket %s", address)
+		}
+		listener, err := net.Listen("unix", address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error listening on %s", address)
+		}
+		if err := os.Chmod(address, 0660); err != nil {
+			listener.Close()
+			return nil, errors.Wrapf(err, "error setting permissions of API socket %s", address)
+		}
+		return listener, nil
+	case "tcp":
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error listening on %s", address)
+		}
+		if tlsConfig != nil {
+			listener = tls.NewListener(listener, tlsConfig)
+		}
+		return listener, nil
+	}
+	return nil, errors.Wrapf(define.ErrInvalidArg, "unsupported network %q for API service, expected tcp or unix", network)
+}
+
+// idleTracker reports when a server has had no connection for a while
+type idleTracker struct {
+	lock   sync.Mutex
+	active map[net.Conn]struct{}
+	// requests is the number of requests being served
+	requests int
+	timeout  time.Duration
+	timer    *time.Timer
+}
+
+func newIdleTracker(timeout time.Duration) *idleTracker {
+	return &idleTracker{
+		active:  make(map[net.Conn]struct{}),
+		timeout: timeout,
+		timer:   time.NewTimer(timeout),
+	}
+}
+
+// connState keeps track of the connections being served, and restarts the
+// timer when the last one becomes idle
+func (t *idleTracker) connState(conn net.Conn, state http.ConnState) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch state {
+	case http.StateNew, http.StateActive:
+		t.active[conn] = struct{}{}
+		t.timer.Stop()
+	case http.StateIdle, http.StateClosed, http.StateHijacked:
+		delete(t.active, conn)
+		t.resetIfIdle()
+	}
+}
+
+// requestStarted stops the timer while a request is served
+func (t *idleTracker) requestStarted() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.requests++
+	t.timer.Stop()
+}
+
+// requestDone restarts the timer once the last request has been served, if
+// no connection is active
+func (t *idleTracker) requestDone() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.requests--
+	t.resetIfIdle()
+}
+
+// resetIfIdle restarts the timer if there is no active connection nor
+// request being served
+func (t *idleTracker) resetIfIdle() {
+	if len(t.active) == 0 && t.requests == 0 {
+		t.timer.Reset(t.timeout)
+	}
+}
+
+// expired returns a channel receiving once the timeout has passed without
+// connections
+func (t *idleTracker) expired() <-chan time.Time {
+	return t.timer.C
+}
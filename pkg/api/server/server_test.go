@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTP(t *testing.T) {
+	s := NewServer(nil, nil, 0)
+
+	for _, path := range []string{"/_ping", "/v1.40/_ping", "/v1.24/libpod/_ping", "/libpod/_ping"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, "OK", rec.Body.String(), path)
+		assert.Equal(t, models.APIVersion, rec.Header().Get("API-Version"), path)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1.12/_ping", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1.40/nothing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	var body models.ErrorModel
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Contains(t, body.Message, "/nothing")
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/_ping", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestIdleTracker(t *testing.T) {
+	tracker := newIdleTracker(50 * time.Millisecond)
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	tracker.connState(server, http.StateNew)
+	select {
+	case <-tracker.expired():
+		t.Fatal("tracker expired with an active connection")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	tracker.connState(server, http.StateClosed)
+	select {
+	case <-tracker.expired():
+	case <-time.After(time.Second):
+		t.Fatal("tracker did not expire without connections")
+	}
+}
+
+func TestIdleTrackerHijacked(t *testing.T) {
+	tracker := newIdleTracker(50 * time.Millisecond)
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+
+	tracker.connState(server, http.StateNew)
+	tracker.requestStarted()
+	tracker.connState(server, http.StateHijacked)
+	select {
+	case <-tracker.expired():
+		t.Fatal("tracker expired while streaming on a hijacked connection")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	tracker.requestDone()
+	select {
+	case <-tracker.expired():
+	case <-time.After(time.Second):
+		t.Fatal("tracker did not expire once the request was done")
+	}
+}
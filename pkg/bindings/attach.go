@@ -0,0 +1,190 @@
+package bindings
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// TerminalSize is the size of a terminal, in characters
+type TerminalSize struct {
+	Width  uint16
+	Height uint16
+}
+
+// AttachStreams are the streams attached to a container or to a command run
+// in it
+type AttachStreams struct {
+	// Stdin, if not nil, is copied to the standard input of the
+	// container, which is closed once Stdin is at EOF
+	Stdin io.Reader
+	// Stdout and Stderr, if not nil, receive the output of the container.
+	// The output of containers with a terminal all goes to Stdout.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Resize, if not nil, receives the sizes the terminal of the
+	// container is resized to
+	Resize <-chan TerminalSize
+	// DetachKeys is the sequence of keys read from Stdin which detaches
+	// from the container, instead of the default one
+	DetachKeys string
+}
+
+// hijackedConn is a connection taken over from a request to stream on it in
+// both directions
+type hijackedConn struct {
+	net.Conn
+	// reader holds what was read after the header of the response
+	reader *bufio.Reader
+}
+
+// Read reads from the connection
+func (h *hijackedConn) Read(p []byte) (int, error) {
+	return h.reader.Read(p)
+}
+
+// CloseWrite closes the write side of the connection, telling the server
+// that the input has ended while still reading its output
+func (h *hijackedConn) CloseWrite() error {
+	if closer, ok := h.Conn.(interface{ CloseWrite() error }); ok {
+		return closer.CloseWrite()
+	}
+	return errors.Errorf("connection to %s can not be half-closed", h.RemoteAddr())
+}
+
+// hijack sends a request whose connection is upgraded to stream in both
+// directions, and returns the connection along with the content type of the
+// stream
+func (c *Connection) hijack(ctx context.Context, method, path string, query url.Values, body interface{}) (*hijackedConn, string, error) {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error connecting to the API")
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, "", errors.Wrapf(err, "error sending request %s %s", method, path)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, "", errors.Wrapf(err, "error reading response of %s %s", method, path)
+	}
+	switch {
+	case resp.StatusCode >= http.StatusBadRequest:
+		defer conn.Close()
+		return nil, "", responseError(resp)
+	case resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK:
+		conn.Close()
+		return nil, "", errors.Errorf("unexpected status %s for %s %s", resp.Status, method, path)
+	}
+	return &hijackedConn{Conn: conn, reader: reader}, resp.Header.Get("Content-Type"), nil
+}
+
+// stream copies the attach streams to and from the hijacked connection, until
+// the output ends or ctx is done. The terminal sizes received are applied
+// with resize.
+func stream(ctx context.Context, conn *hijackedConn, contentType string, streams AttachStreams, resize func(TerminalSize) error) error {
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	if streams.Resize != nil {
+		go func() {
+			for {
+				select {
+				case size, ok := <-streams.Resize:
+					if !ok {
+						return
+					}
+					if err := resize(size); err != nil {
+						logrus.Debugf("Error resizing terminal: %v", err)
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	if streams.Stdin != nil {
+		go func() {
+			if _, err := io.Copy(conn, streams.Stdin); err != nil {
+				logrus.Debugf("Error copying standard input: %v", err)
+			}
+			if err := conn.CloseWrite(); err != nil {
+				logrus.Debugf("Error closing standard input: %v", err)
+			}
+		}()
+	}
+	err := copyOutput(contentType, conn, streams.Stdout, streams.Stderr)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// AttachContainer attaches the streams to a container, until its output ends
+// or ctx is done
+func (c *Connection) AttachContainer(ctx context.Context, nameOrID string, streams AttachStreams) error {
+	query := url.Values{}
+	query.Set("stream", "true")
+	query.Set("stdin", strconv.FormatBool(streams.Stdin != nil))
+	query.Set("stdout", strconv.FormatBool(streams.Stdout != nil))
+	query.Set("stderr", strconv.FormatBool(streams.Stderr != nil))
+	if streams.DetachKeys != "" {
+		query.Set("detachKeys", streams.DetachKeys)
+	}
+	conn, contentType, err := c.hijack(ctx, http.MethodPost, objectPath("containers", nameOrID, "attach"), query, nil)
+	if err != nil {
+		return err
+	}
+	return stream(ctx, conn, contentType, streams, func(size TerminalSize) error {
+		return c.ResizeContainer(ctx, nameOrID, size)
+	})
+}
+
+// ResizeContainer resizes the terminal of a running container
+func (c *Connection) ResizeContainer(ctx context.Context, nameOrID string, size TerminalSize) error {
+	return c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "resize"), sizeQuery(size), nil, nil)
+}
+
+// PortForward connects to a TCP port of a running container, which does not
+// need to be published. Closing the write side of the connection, through
+// its CloseWrite method, closes the write side of the connection to the
+// container.
+func (c *Connection) PortForward(ctx context.Context, nameOrID string, port uint16) (net.Conn, error) {
+	query := url.Values{}
+	query.Set("port", strconv.Itoa(int(port)))
+	conn, _, err := c.hijack(ctx, http.MethodPost, "/libpod"+objectPath("containers", nameOrID, "portforward"), query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// sizeQuery returns the query giving the size of a terminal
+func sizeQuery(size TerminalSize) url.Values {
+	query := url.Values{}
+	query.Set("h", strconv.Itoa(int(size.Height)))
+	query.Set("w", strconv.Itoa(int(size.Width)))
+	return query
+}
@@ -0,0 +1,362 @@
+package bindings
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConnection returns a connection to a server answering with the handler
+func testConnection(t *testing.T, handler http.HandlerFunc) (*Connection, func()) {
+	server := httptest.NewServer(handler)
+	conn, err := NewConnection(server.URL[len("http://"):], nil)
+	require.NoError(t, err)
+	return conn, server.Close
+}
+
+func TestNewConnection(t *testing.T) {
+	for uri, baseURL := range map[string]string{
+		"unix:///run/podman/podman.sock": "http://d",
+		"tcp://localhost:8080":           "http://localhost:8080",
+		"localhost:8080":                 "http://localhost:8080",
+		"ssh://user@host:2222":           "http://d",
+	} {
+		conn, err := NewConnection(uri, nil)
+		require.NoError(t, err, uri)
+		assert.Equal(t, baseURL, conn.baseURL, uri)
+	}
+
+	for _, uri := range []string{"unix://", "tcp://", "ssh://", "ftp://host"} {
+		_, err := NewConnection(uri, nil)
+		assert.Error(t, err, uri)
+	}
+}
+
+func TestUnixConnection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bindings")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "podman.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v"+models.APIVersion+"/_ping", r.URL.Path)
+		_, _ = w.Write([]byte("OK"))
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	conn, err := NewConnection("unix://"+path, nil)
+	require.NoError(t, err)
+	assert.NoError(t, conn.Ping(context.Background()))
+}
+
+func TestSSHArgs(t *testing.T) {
+	u, err := url.Parse("ssh://user@host:2222/run/user/1000/podman/podman.sock")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes",
+		"-p", "2222", "-l", "user", "-i", "/home/user/.ssh/id_rsa",
+		"-o", "UserKnownHostsFile=/home/user/known_hosts",
+		"--", "host", "podman", "system", "dial-stdio", "'unix:///run/user/1000/podman/podman.sock'",
+	}, sshArgs(u, SSHOptions{IdentityFile: "/home/user/.ssh/id_rsa", KnownHostsFile: "/home/user/known_hosts"}))
+
+	// The socket is run by the remote shell as a single argument
+	u, err = url.Parse("ssh://host/tmp/it's;rm%20-rf%20~.sock")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes",
+		"--", "host", "podman", "system", "dial-stdio", `'unix:///tmp/it'\''s;rm -rf ~.sock'`,
+	}, sshArgs(u, SSHOptions{}))
+
+	u, err = url.Parse("ssh://host")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=yes",
+		"--", "host", "podman", "system", "dial-stdio",
+	}, sshArgs(u, SSHOptions{}))
+}
+
+// TestSSHHelperProcess is not a test, but the fake SSH client run by
+// TestSSHConnection, relaying its standard streams to a unix socket
+func TestSSHHelperProcess(t *testing.T) {
+	path := os.Getenv("BINDINGS_TEST_SOCKET")
+	if path == "" {
+		return
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	go func() {
+		_, _ = io.Copy(conn, os.Stdin)
+		_ = conn.(*net.UnixConn).CloseWrite()
+	}()
+	_, _ = io.Copy(os.Stdout, conn)
+	os.Exit(0)
+}
+
+func TestSSHConnection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bindings")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "podman.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v"+models.APIVersion+"/exec/id/start" {
+			conn, reader := hijackTest(t, w, r, models.RawContentType)
+			defer conn.Close()
+			_, _ = io.Copy(conn, reader)
+			return
+		}
+		_, _ = w.Write([]byte("OK"))
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	ssh := filepath.Join(dir, "ssh")
+	script := fmt.Sprintf("#!/bin/sh\nBINDINGS_TEST_SOCKET=%s exec %s -test.run=TestSSHHelperProcess\n", path, os.Args[0])
+	require.NoError(t, ioutil.WriteFile(ssh, []byte(script), 0755))
+
+	conn, err := NewSSHConnection("ssh://user@host", SSHOptions{Command: ssh})
+	require.NoError(t, err)
+	assert.NoError(t, conn.Ping(context.Background()))
+
+	var stdout bytes.Buffer
+	err = conn.StartExec(context.Background(), "id", AttachStreams{Stdin: strings.NewReader("echo"), Stdout: &stdout})
+	require.NoError(t, err)
+	assert.Equal(t, "echo", stdout.String())
+
+	failing := filepath.Join(dir, "failing-ssh")
+	require.NoError(t, ioutil.WriteFile(failing, []byte("#!/bin/sh\necho 'Host key verification failed.' >&2\nexit 255\n"), 0755))
+	conn, err = NewSSHConnection("ssh://user@host", SSHOptions{Command: failing})
+	require.NoError(t, err)
+	err = conn.Ping(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Host key verification failed.")
+}
+
+func TestAPIError(t *testing.T) {
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v"+models.APIVersion+"/containers/missing/json", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(models.ErrorModel{Message: "no such container"})
+	})
+	defer done()
+
+	_, err := conn.InspectContainer(context.Background(), "missing")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsConflict(err))
+	assert.Equal(t, "no such container", err.Error())
+}
+
+func TestListContainers(t *testing.T) {
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("all"))
+		args, err := filters.FromJSON(r.URL.Query().Get("filters"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"web"}, args.Get("name"))
+		_, _ = w.Write([]byte(`[{"Id":"abc","Names":["/web"],"State":"running"}]`))
+	})
+	defer done()
+
+	ctrs, err := conn.ListContainers(context.Background(), true, filters.NewArgs(filters.Arg("name", "web")))
+	require.NoError(t, err)
+	require.Len(t, ctrs, 1)
+	assert.Equal(t, "abc", ctrs[0].ID)
+	assert.Equal(t, "running", ctrs[0].State)
+}
+
+func TestContainerLogs(t *testing.T) {
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("stdout"))
+		assert.Equal(t, "true", r.URL.Query().Get("stderr"))
+		assert.Equal(t, "5", r.URL.Query().Get("tail"))
+		w.Header().Set("Content-Type", models.MultiplexedContentType)
+		_, _ = stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte("out\n"))
+		_, _ = stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("err\n"))
+	})
+	defer done()
+
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, conn.ContainerLogs(context.Background(), "web", LogOptions{Tail: 5}, &stdout, &stderr))
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+}
+
+// hijackTest takes over the connection of the request as the API does to
+// stream on it in both directions
+func hijackTest(t *testing.T, w http.ResponseWriter, r *http.Request, contentType string) (net.Conn, *bufio.Reader) {
+	assert.Equal(t, "tcp", r.Header.Get("Upgrade"))
+	// The body of the request precedes the stream
+	_, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	conn, buffer, err := w.(http.Hijacker).Hijack()
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 101 UPGRADED\r\nContent-Type: %s\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n", contentType)
+	require.NoError(t, err)
+	return conn, buffer.Reader
+}
+
+func TestExec(t *testing.T) {
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v" + models.APIVersion + "/containers/web/exec":
+			var config types.ExecConfig
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&config))
+			assert.True(t, config.AttachStdin)
+			assert.True(t, config.AttachStdout)
+			assert.False(t, config.AttachStderr)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"Id":"exec1"}`))
+		case "/v" + models.APIVersion + "/exec/exec1/start":
+			// Echo the input once the client closed it
+			hijacked, reader := hijackTest(t, w, r, models.RawContentType)
+			defer hijacked.Close()
+			input, err := ioutil.ReadAll(reader)
+			assert.NoError(t, err)
+			_, _ = hijacked.Write(append([]byte("hello "), input...))
+		case "/v" + models.APIVersion + "/exec/exec1/json":
+			_, _ = w.Write([]byte(`{"ExecID":"exec1","Running":false,"ExitCode":3}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+	defer done()
+
+	var stdout bytes.Buffer
+	streams := AttachStreams{Stdin: strings.NewReader("world\n"), Stdout: &stdout}
+	exitCode, err := conn.Exec(context.Background(), "web", types.ExecConfig{Cmd: []string{"cat"}}, streams)
+	require.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "hello world\n", stdout.String())
+}
+
+func TestAttachContainer(t *testing.T) {
+	resized := make(chan string, 1)
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v" + models.APIVersion + "/containers/web/attach":
+			assert.Equal(t, "false", r.URL.Query().Get("stdin"))
+			hijacked, _ := hijackTest(t, w, r, models.MultiplexedContentType)
+			defer hijacked.Close()
+			_, _ = stdcopy.NewStdWriter(hijacked, stdcopy.Stdout).Write([]byte("out\n"))
+			// Wait for the resize before ending the output
+			select {
+			case <-resized:
+			case <-time.After(5 * time.Second):
+				t.Error("terminal was not resized")
+			}
+			_, _ = stdcopy.NewStdWriter(hijacked, stdcopy.Stderr).Write([]byte("err\n"))
+		case "/v" + models.APIVersion + "/containers/web/resize":
+			resized <- r.URL.Query().Get("w") + "x" + r.URL.Query().Get("h")
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+	defer done()
+
+	resize := make(chan TerminalSize, 1)
+	resize <- TerminalSize{Width: 80, Height: 24}
+	var stdout, stderr bytes.Buffer
+	require.NoError(t, conn.AttachContainer(context.Background(), "web", AttachStreams{Stdout: &stdout, Stderr: &stderr, Resize: resize}))
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+}
+
+func TestAttachContainerError(t *testing.T) {
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(models.ErrorModel{Message: "container is stopped"})
+	})
+	defer done()
+
+	err := conn.AttachContainer(context.Background(), "web", AttachStreams{Stdout: ioutil.Discard})
+	require.Error(t, err)
+	assert.True(t, IsConflict(err))
+}
+
+func TestPortForward(t *testing.T) {
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v"+models.APIVersion+"/libpod/containers/web/portforward", r.URL.Path)
+		assert.Equal(t, "8080", r.URL.Query().Get("port"))
+		hijacked, reader := hijackTest(t, w, r, "application/octet-stream")
+		defer hijacked.Close()
+		_, _ = io.Copy(hijacked, reader)
+	})
+	defer done()
+
+	forwarded, err := conn.PortForward(context.Background(), "web", 8080)
+	require.NoError(t, err)
+	defer forwarded.Close()
+	_, err = forwarded.Write([]byte("ping"))
+	require.NoError(t, err)
+	require.NoError(t, forwarded.(interface{ CloseWrite() error }).CloseWrite())
+	echoed, err := ioutil.ReadAll(forwarded)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(echoed))
+}
+
+func TestPodActionErrors(t *testing.T) {
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v"+models.APIVersion+"/libpod/pods/mypod/start", r.URL.Path)
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(models.PodActionReport{ID: "pod1", Errs: map[string]string{"ctr1": "failed to start"}})
+	})
+	defer done()
+
+	ctrErrs, err := conn.StartPod(context.Background(), "mypod")
+	require.Error(t, err)
+	assert.True(t, IsConflict(err))
+	require.Len(t, ctrErrs, 1)
+	assert.EqualError(t, ctrErrs["ctr1"], "failed to start")
+}
+
+func TestEvents(t *testing.T) {
+	since := time.Unix(1500000000, 0)
+	conn, done := testConnection(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1500000000.000000000", r.URL.Query().Get("since"))
+		encoder := json.NewEncoder(w)
+		for _, action := range []string{"create", "start"} {
+			_ = encoder.Encode(events.Message{Type: "container", Action: action, ID: "abc"})
+		}
+	})
+	defer done()
+
+	eventChannel := make(chan events.Message)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- conn.Events(context.Background(), EventsOptions{Since: since}, eventChannel)
+	}()
+	var actions []string
+	for event := range eventChannel {
+		assert.Equal(t, "abc", event.ID)
+		actions = append(actions, event.Action)
+	}
+	assert.NoError(t, <-errChan)
+	assert.Equal(t, []string{"create", "start"}, actions)
+}
@@ -0,0 +1,218 @@
+// Package bindings is a client of the REST API served by podman system
+// service. Its functions mirror the operations of the libpod runtime, so that
+// Go programs can manage containers, images, pods, volumes and networks
+// without linking libpod and its storage stack.
+package bindings
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/pkg/errors"
+)
+
+// APIError is the error of a request the API failed
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Message is the error message sent by the API
+	Message string
+}
+
+// Error returns the message of the error
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// IsNotFound returns whether the error is the API answering that the object
+// of a request does not exist
+func IsNotFound(err error) bool {
+	apiErr, ok := errors.Cause(err).(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsConflict returns whether the error is the API answering that a request
+// conflicts with the state of its object, such as stopping a container which
+// is not running
+func IsConflict(err error) bool {
+	apiErr, ok := errors.Cause(err).(*APIError)
+	return ok && apiErr.StatusCode == http.StatusConflict
+}
+
+// Connection is a connection to the REST API
+type Connection struct {
+	client *http.Client
+	// baseURL is the URL the paths of the requests are relative to
+	baseURL string
+	// dial opens the connections hijacked to stream in both directions
+	dial func(ctx context.Context) (net.Conn, error)
+}
+
+// NewConnection returns a connection to the API served on the URI, either
+// unix:///path, tcp://host:port, host:port or ssh://[user@]host[:port][/path].
+// TCP connections use TLS with the given configuration if it is not nil. SSH
+// connections use the default options of NewSSHConnection.
+func NewConnection(uri string, tlsConfig *tls.Config) (*Connection, error) {
+	if !strings.Contains(uri, "://") {
+		uri = "tcp://" + uri
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URI %q", uri)
+	}
+	if u.Scheme == "ssh" {
+		return NewSSHConnection(uri, SSHOptions{})
+	}
+	transport := &http.Transport{}
+	conn := &Connection{client: &http.Client{Transport: transport}}
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return nil, errors.Errorf("invalid URI %q: no socket path", uri)
+		}
+		conn.dial = func(ctx context.Context) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", u.Path)
+		}
+		// The host is ignored when dialing, but required in the URL
+		conn.baseURL = "http://d"
+	case "tcp":
+		if u.Host == "" {
+			return nil, errors.Errorf("invalid URI %q: no host", uri)
+		}
+		conn.dial = func(ctx context.Context) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "tcp", u.Host)
+		}
+		conn.baseURL = "http://" + u.Host
+		if tlsConfig != nil {
+			transport.TLSClientConfig = tlsConfig
+			conn.baseURL = "https://" + u.Host
+		}
+	default:
+		return nil, errors.Errorf("invalid URI %q: unsupported scheme %q", uri, u.Scheme)
+	}
+	dial := conn.dial
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dial(ctx)
+	}
+	if tlsConfig != nil {
+		// Hijacked connections are not opened by the transport
+		conn.dial = func(ctx context.Context) (net.Conn, error) {
+			rawConn, err := dial(ctx)
+			if err != nil {
+				return nil, err
+			}
+			config := tlsConfig.Clone()
+			if config.ServerName == "" {
+				config.ServerName = u.Hostname()
+			}
+			tlsConn := tls.Client(rawConn, config)
+			if err := tlsConn.Handshake(); err != nil {
+				rawConn.Close()
+				return nil, errors.Wrapf(err, "error in TLS handshake with %s", u.Host)
+			}
+			return tlsConn, nil
+		}
+	}
+	return conn, nil
+}
+
+// request sends a request to the API, with the value JSON encoded as its body
+// unless it is nil. Failed requests are returned as errors, unless their
+// status code is one of the accepted ones.
+func (c *Connection) request(ctx context.Context, method, path string, query url.Values, body interface{}, accepted ...int) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error sending request %s %s", method, path)
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+	for _, code := range accepted {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	return nil, responseError(resp)
+}
+
+// newRequest returns a request to the API, with the value JSON encoded as
+// its body unless it is nil
+func (c *Connection) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error encoding request body")
+		}
+		reader = bytes.NewReader(b)
+	}
+	u := fmt.Sprintf("%s/v%s%s", c.baseURL, models.APIVersion, path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating request")
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// responseError returns the error of a failed request
+func responseError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err == nil {
+		var model models.ErrorModel
+		if json.Unmarshal(b, &model) == nil {
+			apiErr.Message = model.Message
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = fmt.Sprintf("request failed with status %s", resp.Status)
+	}
+	return apiErr
+}
+
+// call sends a request to the API and decodes the JSON body of its response
+// into result, unless it is nil
+func (c *Connection) call(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	resp, err := c.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return errors.Wrapf(err, "error decoding response of %s %s", method, path)
+	}
+	return nil
+}
+
+// objectPath returns the path of the object with the given name or ID in the
+// collection
+func objectPath(collection, nameOrID string, elem ...string) string {
+	return "/" + strings.Join(append([]string{collection, url.PathEscape(nameOrID)}, elem...), "/")
+}
@@ -0,0 +1,194 @@
+package bindings
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pkg/errors"
+)
+
+// LogOptions select the logs of a container read by ContainerLogs
+type LogOptions struct {
+	// Follow is whether to keep streaming the logs written after the
+	// request until the container exits
+	Follow bool
+	// Timestamps is whether to prefix every line with its time
+	Timestamps bool
+	// Since and Until, if not zero, limit the logs to the lines written
+	// in between
+	Since time.Time
+	Until time.Time
+	// Tail, if not 0, limits the logs to the given number of last lines
+	Tail uint64
+}
+
+// ListContainers lists the containers matching the filters. Only running
+// containers are listed unless all is set.
+func (c *Connection) ListContainers(ctx context.Context, all bool, args filters.Args) ([]types.Container, error) {
+	query := url.Values{}
+	query.Set("all", strconv.FormatBool(all))
+	if err := setFilters(query, args); err != nil {
+		return nil, err
+	}
+	var ctrs []types.Container
+	if err := c.call(ctx, http.MethodGet, "/containers/json", query, nil, &ctrs); err != nil {
+		return nil, err
+	}
+	return ctrs, nil
+}
+
+// CreateContainer creates a container and returns its ID. The image of the
+// container must have been pulled first.
+func (c *Connection) CreateContainer(ctx context.Context, name string, config *models.CreateContainerConfig) (string, error) {
+	query := url.Values{}
+	if name != "" {
+		query.Set("name", name)
+	}
+	var created container.ContainerCreateCreatedBody
+	if err := c.call(ctx, http.MethodPost, "/containers/create", query, config, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// InspectContainer returns the configuration and state of a container
+func (c *Connection) InspectContainer(ctx context.Context, nameOrID string) (*types.ContainerJSON, error) {
+	var inspect types.ContainerJSON
+	if err := c.call(ctx, http.MethodGet, objectPath("containers", nameOrID, "json"), nil, nil, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+// StartContainer starts a container. Starting a running container does
+// nothing.
+func (c *Connection) StartContainer(ctx context.Context, nameOrID string) error {
+	return c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "start"), nil, nil, nil)
+}
+
+// StopContainer stops a container, killing it if it does not stop within
+// the timeout, or within the default timeout of the API if it is nil
+func (c *Connection) StopContainer(ctx context.Context, nameOrID string, timeout *uint) error {
+	return c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "stop"), timeoutQuery(timeout), nil, nil)
+}
+
+// RestartContainer restarts a container, killing it if it does not stop
+// within the timeout, or within the default timeout of the API if it is nil
+func (c *Connection) RestartContainer(ctx context.Context, nameOrID string, timeout *uint) error {
+	return c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "restart"), timeoutQuery(timeout), nil, nil)
+}
+
+// KillContainer sends a signal, given by name or number, to a container.
+// The signal defaults to SIGKILL.
+func (c *Connection) KillContainer(ctx context.Context, nameOrID, signal string) error {
+	query := url.Values{}
+	if signal != "" {
+		query.Set("signal", signal)
+	}
+	return c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "kill"), query, nil, nil)
+}
+
+// PauseContainer pauses a container
+func (c *Connection) PauseContainer(ctx context.Context, nameOrID string) error {
+	return c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "pause"), nil, nil, nil)
+}
+
+// UnpauseContainer unpauses a container
+func (c *Connection) UnpauseContainer(ctx context.Context, nameOrID string) error {
+	return c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "unpause"), nil, nil, nil)
+}
+
+// WaitContainer waits for a container to exit and returns its exit code
+func (c *Connection) WaitContainer(ctx context.Context, nameOrID string) (int, error) {
+	var wait container.ContainerWaitOKBody
+	if err := c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "wait"), nil, nil, &wait); err != nil {
+		return -1, err
+	}
+	return int(wait.StatusCode), nil
+}
+
+// RemoveContainer removes a container, stopping it first if force is set,
+// and its anonymous volumes if volumes is set
+func (c *Connection) RemoveContainer(ctx context.Context, nameOrID string, force, volumes bool) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(force))
+	query.Set("v", strconv.FormatBool(volumes))
+	return c.call(ctx, http.MethodDelete, objectPath("containers", nameOrID), query, nil, nil)
+}
+
+// ContainerLogs writes the logs of a container to stdout and stderr. Only
+// the streams whose writer is not nil are read. The logs of containers with
+// a terminal are all written to stdout.
+func (c *Connection) ContainerLogs(ctx context.Context, nameOrID string, options LogOptions, stdout, stderr io.Writer) error {
+	query := url.Values{}
+	query.Set("stdout", strconv.FormatBool(stdout != nil))
+	query.Set("stderr", strconv.FormatBool(stderr != nil))
+	query.Set("follow", strconv.FormatBool(options.Follow))
+	query.Set("timestamps", strconv.FormatBool(options.Timestamps))
+	if !options.Since.IsZero() {
+		query.Set("since", strconv.FormatInt(options.Since.Unix(), 10))
+	}
+	if !options.Until.IsZero() {
+		query.Set("until", strconv.FormatInt(options.Until.Unix(), 10))
+	}
+	if options.Tail > 0 {
+		query.Set("tail", strconv.FormatUint(options.Tail, 10))
+	}
+	resp, err := c.request(ctx, http.MethodGet, objectPath("containers", nameOrID, "logs"), query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return copyOutput(resp.Header.Get("Content-Type"), resp.Body, stdout, stderr)
+}
+
+// copyOutput writes the output of a container streamed with the content type
+// to stdout and stderr, demultiplexing it unless the container has a terminal
+func copyOutput(contentType string, stream io.Reader, stdout, stderr io.Writer) error {
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+	var err error
+	if contentType == models.MultiplexedContentType {
+		_, err = stdcopy.StdCopy(stdout, stderr, stream)
+	} else {
+		_, err = io.Copy(stdout, stream)
+	}
+	return errors.Wrapf(err, "error reading container output")
+}
+
+// timeoutQuery returns the query giving the timeout of a request stopping
+// containers
+func timeoutQuery(timeout *uint) url.Values {
+	query := url.Values{}
+	if timeout != nil {
+		query.Set("t", strconv.FormatUint(uint64(*timeout), 10))
+	}
+	return query
+}
+
+// setFilters sets the filters query parameter to the JSON encoded filters
+func setFilters(query url.Values, args filters.Args) error {
+	if args.Len() == 0 {
+		return nil
+	}
+	encoded, err := filters.ToJSON(args)
+	if err != nil {
+		return errors.Wrapf(err, "error encoding filters")
+	}
+	query.Set("filters", encoded)
+	return nil
+}
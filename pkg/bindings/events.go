@@ -0,0 +1,69 @@
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+// EventsOptions select the events read by Events
+type EventsOptions struct {
+	// Since, if not zero, is the time of the first past event read
+	Since time.Time
+	// Until, if not zero, stops reading at the given time. Otherwise
+	// events are streamed until ctx is done.
+	Until time.Time
+	// Filters select the events read, by container, event, image, pod,
+	// volume or type
+	Filters filters.Args
+}
+
+// Events sends the events of the runtime on the channel, which it closes
+// once done
+func (c *Connection) Events(ctx context.Context, options EventsOptions, eventChannel chan<- events.Message) error {
+	defer close(eventChannel)
+	query := url.Values{}
+	if !options.Since.IsZero() {
+		query.Set("since", unixTime(options.Since))
+	}
+	if !options.Until.IsZero() {
+		query.Set("until", unixTime(options.Until))
+	}
+	if err := setFilters(query, options.Filters); err != nil {
+		return err
+	}
+	resp, err := c.request(ctx, http.MethodGet, "/events", query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event events.Message
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(err, "error reading events")
+		}
+		select {
+		case eventChannel <- event:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// unixTime formats the time as the Unix timestamp accepted by the API, with
+// fractional seconds
+func unixTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', 9, 64)
+}
@@ -0,0 +1,76 @@
+package bindings
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/docker/docker/api/types"
+)
+
+// CreateExec creates an exec instance running a command in a running
+// container, and returns its ID
+func (c *Connection) CreateExec(ctx context.Context, nameOrID string, config types.ExecConfig) (string, error) {
+	var created types.IDResponse
+	if err := c.call(ctx, http.MethodPost, objectPath("containers", nameOrID, "exec"), nil, config, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// StartExec starts the command of an exec instance and attaches the streams
+// it was created attached to, until it exits or ctx is done. The detach keys
+// of the streams are ignored, those of the exec instance apply.
+func (c *Connection) StartExec(ctx context.Context, id string, streams AttachStreams) error {
+	conn, contentType, err := c.hijack(ctx, http.MethodPost, objectPath("exec", id, "start"), nil, types.ExecStartCheck{})
+	if err != nil {
+		return err
+	}
+	return stream(ctx, conn, contentType, streams, func(size TerminalSize) error {
+		return c.ResizeExec(ctx, id, size)
+	})
+}
+
+// ResizeExec resizes the terminal of the command of an exec instance
+func (c *Connection) ResizeExec(ctx context.Context, id string, size TerminalSize) error {
+	return c.call(ctx, http.MethodPost, objectPath("exec", id, "resize"), sizeQuery(size), nil, nil)
+}
+
+// StartExecDetached starts the command of an exec instance without waiting
+// for it to exit. Its exit code is given by InspectExec once it has exited.
+func (c *Connection) StartExecDetached(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodPost, objectPath("exec", id, "start"), nil, types.ExecStartCheck{Detach: true}, nil)
+}
+
+// InspectExec returns the state of an exec instance
+func (c *Connection) InspectExec(ctx context.Context, id string) (*types.ContainerExecInspect, error) {
+	var inspect types.ContainerExecInspect
+	if err := c.call(ctx, http.MethodGet, objectPath("exec", id, "json"), nil, nil, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+// Exec runs a command in a running container with the streams attached to
+// it, and returns its exit code. Only the streams which are not nil are
+// attached.
+func (c *Connection) Exec(ctx context.Context, nameOrID string, config types.ExecConfig, streams AttachStreams) (int, error) {
+	config.AttachStdin = streams.Stdin != nil
+	config.AttachStdout = streams.Stdout != nil
+	config.AttachStderr = streams.Stderr != nil
+	config.Detach = false
+	if streams.DetachKeys != "" {
+		config.DetachKeys = streams.DetachKeys
+	}
+	id, err := c.CreateExec(ctx, nameOrID, config)
+	if err != nil {
+		return -1, err
+	}
+	if err := c.StartExec(ctx, id, streams); err != nil {
+		return -1, err
+	}
+	inspect, err := c.InspectExec(ctx, id)
+	if err != nil {
+		return -1, err
+	}
+	return inspect.ExitCode, nil
+}
@@ -0,0 +1,91 @@
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+// ListImages lists the images matching the filters. Intermediate images are
+// only listed if all is set.
+func (c *Connection) ListImages(ctx context.Context, all bool, args filters.Args) ([]types.ImageSummary, error) {
+	query := url.Values{}
+	query.Set("all", strconv.FormatBool(all))
+	if err := setFilters(query, args); err != nil {
+		return nil, err
+	}
+	var images []types.ImageSummary
+	if err := c.call(ctx, http.MethodGet, "/images/json", query, nil, &images); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// InspectImage returns the configuration of an image
+func (c *Connection) InspectImage(ctx context.Context, nameOrID string) (*types.ImageInspect, error) {
+	var inspect types.ImageInspect
+	if err := c.call(ctx, http.MethodGet, objectPath("images", nameOrID, "json"), nil, nil, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+// PullImage pulls an image and returns its ID
+func (c *Connection) PullImage(ctx context.Context, name string) (string, error) {
+	query := url.Values{}
+	query.Set("fromImage", name)
+	resp, err := c.request(ctx, http.MethodPost, "/images/create", query, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	// Errors happening once the pull has started are reported in the
+	// stream of progress messages
+	var id string
+	decoder := json.NewDecoder(resp.Body)
+	for decoder.More() {
+		var msg models.ProgressMessage
+		if err := decoder.Decode(&msg); err != nil {
+			return "", errors.Wrapf(err, "error reading progress of the pull of %s", name)
+		}
+		if msg.Error != "" {
+			return "", errors.Errorf("error pulling image %s: %s", name, msg.Error)
+		}
+		if msg.ID != "" {
+			id = msg.ID
+		}
+	}
+	if id == "" {
+		return "", errors.Errorf("error pulling image %s: no image ID received", name)
+	}
+	return id, nil
+}
+
+// TagImage adds the repository and tag to the names of an image
+func (c *Connection) TagImage(ctx context.Context, nameOrID, repo, tag string) error {
+	query := url.Values{}
+	query.Set("repo", repo)
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	return c.call(ctx, http.MethodPost, objectPath("images", nameOrID, "tag"), query, nil, nil)
+}
+
+// RemoveImage removes an image, even if containers use it if force is set,
+// and returns the untagged and deleted images
+func (c *Connection) RemoveImage(ctx context.Context, nameOrID string, force bool) ([]types.ImageDeleteResponseItem, error) {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(force))
+	var items []types.ImageDeleteResponseItem
+	if err := c.call(ctx, http.MethodDelete, objectPath("images", nameOrID), query, nil, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
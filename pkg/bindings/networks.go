@@ -0,0 +1,46 @@
+package bindings
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// ListNetworks lists the networks matching the filters
+func (c *Connection) ListNetworks(ctx context.Context, args filters.Args) ([]types.NetworkResource, error) {
+	query := url.Values{}
+	if err := setFilters(query, args); err != nil {
+		return nil, err
+	}
+	var networks []types.NetworkResource
+	if err := c.call(ctx, http.MethodGet, "/networks", query, nil, &networks); err != nil {
+		return nil, err
+	}
+	return networks, nil
+}
+
+// InspectNetwork returns the configuration of a network
+func (c *Connection) InspectNetwork(ctx context.Context, name string) (*types.NetworkResource, error) {
+	var network types.NetworkResource
+	if err := c.call(ctx, http.MethodGet, objectPath("networks", name), nil, nil, &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+// CreateNetwork creates a bridge network and returns its ID
+func (c *Connection) CreateNetwork(ctx context.Context, config types.NetworkCreateRequest) (string, error) {
+	var created types.NetworkCreateResponse
+	if err := c.call(ctx, http.MethodPost, "/networks/create", nil, config, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// RemoveNetwork removes a network
+func (c *Connection) RemoveNetwork(ctx context.Context, name string) error {
+	return c.call(ctx, http.MethodDelete, objectPath("networks", name), nil, nil, nil)
+}
@@ -0,0 +1,124 @@
+package bindings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containers/libpod/pkg/api/models"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+// podPath returns the path of a pod, or of the action on it
+func podPath(nameOrID string, elem ...string) string {
+	return "/libpod" + objectPath("pods", nameOrID, elem...)
+}
+
+// CreatePod creates a pod and returns its ID
+func (c *Connection) CreatePod(ctx context.Context, config models.PodCreateConfig) (string, error) {
+	var report models.PodActionReport
+	if err := c.call(ctx, http.MethodPost, "/libpod/pods/create", nil, config, &report); err != nil {
+		return "", err
+	}
+	return report.ID, nil
+}
+
+// ListPods lists the pods matching the filters
+func (c *Connection) ListPods(ctx context.Context, args filters.Args) ([]models.PodSummary, error) {
+	query := url.Values{}
+	if err := setFilters(query, args); err != nil {
+		return nil, err
+	}
+	var pods []models.PodSummary
+	if err := c.call(ctx, http.MethodGet, "/libpod/pods/json", query, nil, &pods); err != nil {
+		return nil, err
+	}
+	return pods, nil
+}
+
+// InspectPod returns the configuration and state of a pod
+func (c *Connection) InspectPod(ctx context.Context, nameOrID string) (*models.PodInspect, error) {
+	var inspect models.PodInspect
+	if err := c.call(ctx, http.MethodGet, podPath(nameOrID, "json"), nil, nil, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+// RemovePod removes a pod and its containers, stopping them first if force
+// is set
+func (c *Connection) RemovePod(ctx context.Context, nameOrID string, force bool) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(force))
+	return c.call(ctx, http.MethodDelete, podPath(nameOrID), query, nil, nil)
+}
+
+// podAction runs an action on the containers of a pod. The errors of the
+// containers the action failed on are returned by container ID, along with
+// an error summarizing them.
+func (c *Connection) podAction(ctx context.Context, nameOrID, action string, query url.Values) (map[string]error, error) {
+	resp, err := c.request(ctx, http.MethodPost, podPath(nameOrID, action), query, nil, http.StatusConflict)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var report models.PodActionReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, errors.Wrapf(err, "error decoding response of %s of pod %s", action, nameOrID)
+	}
+	if len(report.Errs) == 0 {
+		return nil, nil
+	}
+	ctrErrs := make(map[string]error, len(report.Errs))
+	ids := make([]string, 0, len(report.Errs))
+	for id, msg := range report.Errs {
+		ctrErrs[id] = errors.New(msg)
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ctrErrs, &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    "error running " + action + " on containers " + strings.Join(ids, ", ") + " of pod " + nameOrID,
+	}
+}
+
+// StartPod starts the containers of a pod
+func (c *Connection) StartPod(ctx context.Context, nameOrID string) (map[string]error, error) {
+	return c.podAction(ctx, nameOrID, "start", nil)
+}
+
+// StopPod stops the containers of a pod, killing them if they do not stop
+// within the timeout, or within their own stop timeout if it is nil
+func (c *Connection) StopPod(ctx context.Context, nameOrID string, timeout *uint) (map[string]error, error) {
+	return c.podAction(ctx, nameOrID, "stop", timeoutQuery(timeout))
+}
+
+// RestartPod restarts the containers of a pod
+func (c *Connection) RestartPod(ctx context.Context, nameOrID string) (map[string]error, error) {
+	return c.podAction(ctx, nameOrID, "restart", nil)
+}
+
+// KillPod sends a signal, given by name or number, to the containers of a
+// pod. The signal defaults to SIGKILL.
+func (c *Connection) KillPod(ctx context.Context, nameOrID, signal string) (map[string]error, error) {
+	query := url.Values{}
+	if signal != "" {
+		query.Set("signal", signal)
+	}
+	return c.podAction(ctx, nameOrID, "kill", query)
+}
+
+// PausePod pauses the containers of a pod
+func (c *Connection) PausePod(ctx context.Context, nameOrID string) (map[string]error, error) {
+	return c.podAction(ctx, nameOrID, "pause", nil)
+}
+
+// UnpausePod unpauses the containers of a pod
+func (c *Connection) UnpausePod(ctx context.Context, nameOrID string) (map[string]error, error) {
+	return c.podAction(ctx, nameOrID, "unpause", nil)
+}
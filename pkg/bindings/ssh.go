@@ -0,0 +1,245 @@
+package bindings
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// sshIdleTimeout is how long idle connections tunneled through SSH are kept,
+// each of them running an SSH client
+const sshIdleTimeout = 30 * time.Second
+
+// SSHOptions configures the connections to the API tunneled through SSH
+type SSHOptions struct {
+	// IdentityFile is a private key authenticating the user, tried in
+	// addition to the keys of the SSH agent
+	IdentityFile string
+	// KnownHostsFile is the file the key of the host is verified against,
+	// instead of the known_hosts files of the user
+	KnownHostsFile string
+	// Command is the SSH client run, ssh by default
+	Command string
+}
+
+// NewSSHConnection returns a connection to the API of a remote host tunneled
+// through SSH. The URI is ssh://[user@]host[:port][/path], path being the API
+// socket on the host, by default the one of podman system service for the
+// user.
+//
+// Connections run the OpenSSH client, which authenticates with the SSH agent
+// and the keys of the user and refuses hosts whose key is not known, and
+// podman system dial-stdio on the host to relay them to the API socket.
+func NewSSHConnection(uri string, options SSHOptions) (*Connection, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URI %q", uri)
+	}
+	if u.Scheme != "ssh" {
+		return nil, errors.Errorf("invalid URI %q: expected ssh scheme", uri)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.Errorf("invalid URI %q: no host", uri)
+	}
+	command := options.Command
+	if command == "" {
+		command = "ssh"
+	}
+	args := sshArgs(u, options)
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return dialCommand(ctx, command, args...)
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
+		},
+		IdleConnTimeout: sshIdleTimeout,
+	}
+	return &Connection{
+		client: &http.Client{Transport: transport},
+		// The host is ignored when dialing, but required in the URL
+		baseURL: "http://d",
+		dial:    dial,
+	}, nil
+}
+
+// sshArgs returns the arguments of the SSH client connecting to the API
+// socket of the host of the URI
+func sshArgs(u *url.URL, options SSHOptions) []string {
+	args := []string{
+		// Fail rather than prompt, the standard streams carry the API
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=yes",
+	}
+	if port := u.Port(); port != "" {
+		args = append(args, "-p", port)
+	}
+	if u.User != nil && u.User.Username() != "" {
+		args = append(args, "-l", u.User.Username())
+	}
+	if options.IdentityFile != "" {
+		args = append(args, "-i", options.IdentityFile)
+	}
+	if options.KnownHostsFile != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+options.KnownHostsFile)
+	}
+	args = append(args, "--", u.Hostname(), "podman", "system", "dial-stdio")
+	if u.Path != "" && u.Path != "/" {
+		// The remote shell runs the command, quote the socket so it is a
+		// single argument whatever its path holds
+		args = append(args, shellQuote("unix://"+u.Path))
+	}
+	return args
+}
+
+// shellQuote quotes s as a single argument of a shell command
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// commandConn is a connection to the standard input and output of a command
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  *os.File
+	stdout *os.File
+	// stderr is the error output of the command, reported when it fails
+	stderr    bytes.Buffer
+	waitOnce  sync.Once
+	waitErr   error
+	closeOnce sync.Once
+}
+
+// dialCommand starts the command and returns a connection to its standard
+// input and output. The context only bounds the start of the command.
+func dialCommand(ctx context.Context, name string, args ...string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating pipe")
+	}
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return nil, errors.Wrapf(err, "error creating pipe")
+	}
+	conn := &commandConn{cmd: exec.Command(name, args...), stdin: stdinWriter, stdout: stdoutReader}
+	conn.cmd.Stdin = stdinReader
+	conn.cmd.Stdout = stdoutWriter
+	conn.cmd.Stderr = &conn.stderr
+	err = conn.cmd.Start()
+	stdinReader.Close()
+	stdoutWriter.Close()
+	if err != nil {
+		stdinWriter.Close()
+		stdoutReader.Close()
+		return nil, errors.Wrapf(err, "error running %s", name)
+	}
+	return conn, nil
+}
+
+// wait waits for the command to exit and returns the error it failed with
+func (c *commandConn) wait() error {
+	c.waitOnce.Do(func() {
+		if err := c.cmd.Wait(); err != nil {
+			c.waitErr = errors.Wrapf(err, "error running %s: %s", c.cmd.Path, strings.TrimSpace(c.stderr.String()))
+		}
+	})
+	return c.waitErr
+}
+
+// Read reads the standard output of the command, returning the error the
+// command failed with once it is exhausted
+func (c *commandConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if err == io.EOF {
+		if waitErr := c.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// Write writes to the standard input of the command, returning the error the
+// command failed with if it exited
+func (c *commandConn) Write(p []byte) (int, error) {
+	n, err := c.stdin.Write(p)
+	if pathErr, ok := err.(*os.PathError); ok && pathErr.Err == syscall.EPIPE {
+		if waitErr := c.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// CloseWrite closes the standard input of the command
+func (c *commandConn) CloseWrite() error {
+	return c.stdin.Close()
+}
+
+// Close closes the connection, killing the command if it is still running
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.stdin.Close()
+		if c.cmd.Process != nil {
+			_ = c.cmd.Process.Kill()
+		}
+		_ = c.wait()
+		c.stdout.Close()
+	})
+	return nil
+}
+
+// LocalAddr returns the address of the command
+func (c *commandConn) LocalAddr() net.Addr {
+	return commandAddr(c.cmd.Path)
+}
+
+// RemoteAddr returns the address of the command
+func (c *commandConn) RemoteAddr() net.Addr {
+	return commandAddr(c.cmd.Path)
+}
+
+// SetDeadline sets the deadline of the reads and writes of the connection
+func (c *commandConn) SetDeadline(t time.Time) error {
+	if err := c.stdout.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.stdin.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the deadline of the reads of the connection
+func (c *commandConn) SetReadDeadline(t time.Time) error {
+	return c.stdout.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline of the writes of the connection
+func (c *commandConn) SetWriteDeadline(t time.Time) error {
+	return c.stdin.SetWriteDeadline(t)
+}
+
+// commandAddr is the address of a connection to a command
+type commandAddr string
+
+// Network returns the network of the address
+func (a commandAddr) Network() string {
+	return "command"
+}
+
+// String returns the path of the command
+func (a commandAddr) String() string {
+	return string(a)
+}
@@ -0,0 +1,32 @@
+package bindings
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/docker/docker/api/types"
+)
+
+// Ping checks that the API is served
+func (c *Connection) Ping(ctx context.Context) error {
+	return c.call(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+}
+
+// Version returns the versions of podman and of the API
+func (c *Connection) Version(ctx context.Context) (*types.Version, error) {
+	var version types.Version
+	if err := c.call(ctx, http.MethodGet, "/version", nil, nil, &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// Info returns information on the host and the runtime, in the format of
+// the Docker API
+func (c *Connection) Info(ctx context.Context) (*types.Info, error) {
+	var info types.Info
+	if err := c.call(ctx, http.MethodGet, "/info", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
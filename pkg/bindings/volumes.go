@@ -0,0 +1,50 @@
+package bindings
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// ListVolumes lists the volumes matching the filters
+func (c *Connection) ListVolumes(ctx context.Context, args filters.Args) ([]*types.Volume, error) {
+	query := url.Values{}
+	if err := setFilters(query, args); err != nil {
+		return nil, err
+	}
+	var list volume.VolumeListOKBody
+	if err := c.call(ctx, http.MethodGet, "/volumes", query, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Volumes, nil
+}
+
+// CreateVolume creates a volume
+func (c *Connection) CreateVolume(ctx context.Context, config volume.VolumeCreateBody) (*types.Volume, error) {
+	var vol types.Volume
+	if err := c.call(ctx, http.MethodPost, "/volumes/create", nil, config, &vol); err != nil {
+		return nil, err
+	}
+	return &vol, nil
+}
+
+// InspectVolume returns the configuration of a volume
+func (c *Connection) InspectVolume(ctx context.Context, name string) (*types.Volume, error) {
+	var vol types.Volume
+	if err := c.call(ctx, http.MethodGet, objectPath("volumes", name), nil, nil, &vol); err != nil {
+		return nil, err
+	}
+	return &vol, nil
+}
+
+// RemoveVolume removes a volume, and the containers using it if force is set
+func (c *Connection) RemoveVolume(ctx context.Context, name string, force bool) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(force))
+	return c.call(ctx, http.MethodDelete, objectPath("volumes", name), query, nil, nil)
+}
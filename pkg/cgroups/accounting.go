@@ -0,0 +1,90 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAccountingCgroupV1 means lifetime accounting was requested for a cgroup
+// on a cgroup v1 system, where it is not available
+var ErrAccountingCgroupV1 = errors.New("lifetime accounting requires cgroups v2")
+
+// AccountingMetrics keeps the resource usage accumulated over the lifetime of
+// a cgroup
+type AccountingMetrics struct {
+	// MemoryPeak is the highest memory usage of the cgroup, in bytes.
+	// It is 0 if the kernel does not record it.
+	MemoryPeak uint64
+	// CPUUsage is the CPU time used by the cgroup, in nanoseconds
+	CPUUsage uint64
+	// CPUUser is the CPU time used in user mode, in nanoseconds
+	CPUUser uint64
+	// CPUSystem is the CPU time used in kernel mode, in nanoseconds
+	CPUSystem uint64
+	// PidsPeak is the highest number of processes in the cgroup.
+	// It is 0 if the kernel does not record it.
+	PidsPeak uint64
+	// OOMKills is the number of processes killed by the OOM killer
+	OOMKills uint64
+}
+
+// Accounting returns the resource usage accumulated over the lifetime of the
+// cgroup. It is only available with cgroups v2.
+func (c *CgroupControl) Accounting() (*AccountingMetrics, error) {
+	if !c.cgroup2 {
+		return nil, ErrAccountingCgroupV1
+	}
+	if _, err := os.Stat(filepath.Join(cgroupRoot, c.path)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCgroupDeleted
+		}
+		return nil, err
+	}
+
+	m := AccountingMetrics{}
+
+	cpuStat, err := readCgroup2MapFile(c, "cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	for key, dest := range map[string]*uint64{
+		"usage_usec":  &m.CPUUsage,
+		"user_usec":   &m.CPUUser,
+		"system_usec": &m.CPUSystem,
+	} {
+		if val, found := cpuStat[key]; found {
+			if *dest, err = strconv.ParseUint(cleanString(val[0]), 10, 0); err != nil {
+				return nil, errors.Wrapf(err, "parse %s from cpu.stat", key)
+			}
+			*dest *= 1000
+		}
+	}
+
+	memoryEvents, err := readCgroup2MapFile(c, "memory.events")
+	if err != nil {
+		return nil, err
+	}
+	if val, found := memoryEvents["oom_kill"]; found {
+		if m.OOMKills, err = strconv.ParseUint(cleanString(val[0]), 10, 0); err != nil {
+			return nil, errors.Wrapf(err, "parse oom_kill from memory.events")
+		}
+	}
+
+	// memory.peak and pids.peak are missing on older kernels
+	for file, dest := range map[string]*uint64{
+		"memory.peak": &m.MemoryPeak,
+		"pids.peak":   &m.PidsPeak,
+	} {
+		if *dest, err = readFileAsUint64(filepath.Join(cgroupRoot, c.path, file)); err != nil {
+			if !os.IsNotExist(errors.Cause(err)) {
+				return nil, err
+			}
+			*dest = 0
+		}
+	}
+
+	return &m, nil
+}
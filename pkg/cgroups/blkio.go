@@ -3,6 +3,7 @@ package cgroups
 import (
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -24,7 +25,108 @@ func (c *blkioHandler) Apply(ctr *CgroupControl, res *spec.LinuxResources) error
 	if res.BlockIO == nil {
 		return nil
 	}
-	return fmt.Errorf("blkio apply function not implemented yet")
+	var (
+		root   string
+		values []fileValue
+	)
+	if ctr.cgroup2 {
+		root = filepath.Join(cgroupRoot, ctr.path)
+		values = blkioCgroup2Values(res.BlockIO)
+		if len(values) > 0 {
+			if err := ctr.checkCgroup2Controller(IO); err != nil {
+				return err
+			}
+		}
+	} else {
+		root = ctr.getCgroupv1Path(Blkio)
+		values = blkioCgroup1Values(res.BlockIO)
+	}
+	// Every line is written separately, the kernel only parses the first
+	// one of each write
+	for _, v := range values {
+		p := filepath.Join(root, v.file)
+		if err := ioutil.WriteFile(p, []byte(v.value), 0644); err != nil {
+			return errors.Wrapf(err, "write %s to %s", v.value, p)
+		}
+	}
+	return nil
+}
+
+// fileValue is a value to write to a file of a cgroup
+type fileValue struct {
+	file  string
+	value string
+}
+
+// blkioCgroup1Values returns the values setting the block IO limits of a
+// cgroup v1
+func blkioCgroup1Values(blkio *spec.LinuxBlockIO) []fileValue {
+	var values []fileValue
+	if blkio.Weight != nil && *blkio.Weight > 0 {
+		values = append(values, fileValue{"blkio.weight", fmt.Sprintf("%d", *blkio.Weight)})
+	}
+	for _, d := range blkio.WeightDevice {
+		if d.Weight != nil {
+			values = append(values, fileValue{"blkio.weight_device", fmt.Sprintf("%d:%d %d", d.Major, d.Minor, *d.Weight)})
+		}
+	}
+	for _, throttle := range []struct {
+		file    string
+		devices []spec.LinuxThrottleDevice
+	}{
+		{"blkio.throttle.read_bps_device", blkio.ThrottleReadBpsDevice},
+		{"blkio.throttle.write_bps_device", blkio.ThrottleWriteBpsDevice},
+		{"blkio.throttle.read_iops_device", blkio.ThrottleReadIOPSDevice},
+		{"blkio.throttle.write_iops_device", blkio.ThrottleWriteIOPSDevice},
+	} {
+		for _, d := range throttle.devices {
+			values = append(values, fileValue{throttle.file, fmt.Sprintf("%d:%d %d", d.Major, d.Minor, d.Rate)})
+		}
+	}
+	return values
+}
+
+// blkioCgroup2Values returns the values setting the block IO limits of a
+// cgroup v2. Weights are converted to the range of io.weight, and the
+// throttling of each device is merged into a single line of io.max.
+func blkioCgroup2Values(blkio *spec.LinuxBlockIO) []fileValue {
+	var values []fileValue
+	if blkio.Weight != nil && *blkio.Weight > 0 {
+		values = append(values, fileValue{"io.weight", fmt.Sprintf("default %d", ConvertBlkIOWeightToCgroupV2(*blkio.Weight))})
+	}
+	for _, d := range blkio.WeightDevice {
+		if d.Weight != nil {
+			values = append(values, fileValue{"io.weight", fmt.Sprintf("%d:%d %d", d.Major, d.Minor, ConvertBlkIOWeightToCgroupV2(*d.Weight))})
+		}
+	}
+
+	var devices []string
+	limits := map[string][]string{}
+	for _, throttle := range []struct {
+		key     string
+		devices []spec.LinuxThrottleDevice
+	}{
+		{"rbps", blkio.ThrottleReadBpsDevice},
+		{"wbps", blkio.ThrottleWriteBpsDevice},
+		{"riops", blkio.ThrottleReadIOPSDevice},
+		{"wiops", blkio.ThrottleWriteIOPSDevice},
+	} {
+		for _, d := range throttle.devices {
+			device := fmt.Sprintf("%d:%d", d.Major, d.Minor)
+			if _, found := limits[device]; !found {
+				devices = append(devices, device)
+			}
+			rate := "max"
+			if d.Rate > 0 {
+				rate = fmt.Sprintf("%d", d.Rate)
+			}
+			limits[device] = append(limits[device], fmt.Sprintf("%s=%s", throttle.key, rate))
+		}
+	}
+	for _, device := range devices {
+		values = append(values, fileValue{"io.max", fmt.Sprintf("%s %s", device, strings.Join(limits[device], " "))})
+	}
+	return values
 }
 
 // Create the cgroup
@@ -55,11 +157,11 @@ func (c *blkioHandler) Stat(ctr *CgroupControl, m *Metrics) error {
 			if len(d) != 2 {
 				continue
 			}
-			minor, err := strconv.ParseUint(d[0], 10, 0)
+			major, err := strconv.ParseUint(d[0], 10, 0)
 			if err != nil {
 				return err
 			}
-			major, err := strconv.ParseUint(d[1], 10, 0)
+			minor, err := strconv.ParseUint(d[1], 10, 0)
 			if err != nil {
 				return err
 			}
@@ -117,11 +219,11 @@ func (c *blkioHandler) Stat(ctr *CgroupControl, m *Metrics) error {
 			if len(d) != 2 {
 				continue
 			}
-			minor, err := strconv.ParseUint(d[0], 10, 0)
+			major, err := strconv.ParseUint(d[0], 10, 0)
 			if err != nil {
 				return err
 			}
-			major, err := strconv.ParseUint(d[1], 10, 0)
+			minor, err := strconv.ParseUint(d[1], 10, 0)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,134 @@
+package cgroups
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCgroupV2Controller means a resource limit was requested for a cgroup v2
+// whose parent does not enable the controller enforcing it
+var ErrCgroupV2Controller = errors.New("cgroup controller not available")
+
+// RootlessSystemdCgroup returns the cgroup, relative to the cgroup root, in
+// which the systemd user instance of the given user creates the scopes of
+// rootless containers
+func RootlessSystemdCgroup(uid int) string {
+	return fmt.Sprintf("user.slice/user-%d.slice/user@%d.service/user.slice", uid, uid)
+}
+
+// AvailableControllers returns the cgroup v2 controllers which can be used by
+// the given cgroup. If the cgroup does not exist yet, the controllers its
+// closest existing ancestor enables for its children are returned. With
+// rootless containers these are the controllers delegated to the user by
+// systemd.
+func AvailableControllers(path string) ([]string, error) {
+	cgroup2, err := IsCgroup2UnifiedMode()
+	if err != nil {
+		return nil, err
+	}
+	if !cgroup2 {
+		return nil, errors.Errorf("controllers of cgroup %s cannot be listed on cgroups v1", path)
+	}
+
+	dir := filepath.Join(cgroupRoot, path)
+	file := "cgroup.controllers"
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "stat %s", dir)
+		}
+		if dir == cgroupRoot {
+			return nil, errors.Errorf("cgroup root %s does not exist", cgroupRoot)
+		}
+		dir = filepath.Dir(dir)
+		file = "cgroup.subtree_control"
+	}
+	return readControllers(filepath.Join(dir, file))
+}
+
+// readControllers reads a space-separated list of controllers
+func readControllers(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read %s", path)
+	}
+	return strings.Fields(string(content)), nil
+}
+
+// checkCgroup2Controller returns an error if the given controller is not
+// enabled for the cgroup, in which case its interface files are missing
+func (c *CgroupControl) checkCgroup2Controller(name string) error {
+	controllers, err := readControllers(filepath.Join(cgroupRoot, c.path, "cgroup.controllers"))
+	if err != nil {
+		return err
+	}
+	for _, controller := range controllers {
+		if controller == name {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrCgroupV2Controller, "the %s controller is not enabled for cgroup %s, it may not be delegated to the user", name, c.path)
+}
+
+// cgroup2Limit formats a limit for a cgroup v2 interface file, where no limit
+// is written as "max"
+func cgroup2Limit(limit int64) string {
+	if limit <= 0 {
+		return "max"
+	}
+	return fmt.Sprintf("%d", limit)
+}
+
+// ConvertCPUSharesToCgroupV2 converts CPU shares, between 2 and 262144, to a
+// cgroup v2 CPU weight, between 1 and 10000. The default of 1024 shares is
+// converted to about the default weight of 100. 0 shares are converted to 0,
+// meaning the weight is left unset.
+func ConvertCPUSharesToCgroupV2(shares uint64) uint64 {
+	if shares == 0 {
+		return 0
+	}
+	if shares < 2 {
+		shares = 2
+	} else if shares > 262144 {
+		shares = 262144
+	}
+	return 1 + ((shares-2)*9999)/262142
+}
+
+// ConvertBlkIOWeightToCgroupV2 converts a block IO weight, between 10 and
+// 1000, to a cgroup v2 IO weight, between 1 and 10000
+func ConvertBlkIOWeightToCgroupV2(weight uint16) uint64 {
+	w := uint64(weight)
+	if w < 10 {
+		w = 10
+	} else if w > 1000 {
+		w = 1000
+	}
+	return 1 + ((w-10)*9999)/990
+}
+
+// ConvertMemorySwapToCgroupV2 converts a swap limit including the memory
+// limit, as used by cgroups v1 and the OCI runtime spec, to the value of
+// memory.swap.max, which only covers swap. An empty string is returned if
+// the swap limit is left unset.
+func ConvertMemorySwapToCgroupV2(swap *int64, limit int64) (string, error) {
+	if swap == nil || *swap == 0 {
+		return "", nil
+	}
+	if *swap == -1 {
+		return "max", nil
+	}
+	if limit <= 0 {
+		return "", errors.Errorf("a memory limit is required to limit swap")
+	}
+	if *swap < limit {
+		return "", errors.Errorf("memory and swap limit %d is lower than the memory limit %d", *swap, limit)
+	}
+	return fmt.Sprintf("%d", *swap-limit), nil
+}
@@ -0,0 +1,68 @@
+package cgroups
+
+import (
+	"testing"
+
+	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertCPUSharesToCgroupV2(t *testing.T) {
+	assert.Equal(t, uint64(0), ConvertCPUSharesToCgroupV2(0))
+	assert.Equal(t, uint64(1), ConvertCPUSharesToCgroupV2(2))
+	assert.Equal(t, uint64(39), ConvertCPUSharesToCgroupV2(1024))
+	assert.Equal(t, uint64(10000), ConvertCPUSharesToCgroupV2(262144))
+	assert.Equal(t, uint64(10000), ConvertCPUSharesToCgroupV2(1000000))
+}
+
+func TestConvertBlkIOWeightToCgroupV2(t *testing.T) {
+	assert.Equal(t, uint64(1), ConvertBlkIOWeightToCgroupV2(10))
+	assert.Equal(t, uint64(4950), ConvertBlkIOWeightToCgroupV2(500))
+	assert.Equal(t, uint64(10000), ConvertBlkIOWeightToCgroupV2(1000))
+}
+
+func TestConvertMemorySwapToCgroupV2(t *testing.T) {
+	swap := func(v int64) *int64 { return &v }
+
+	value, err := ConvertMemorySwapToCgroupV2(nil, 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	value, err = ConvertMemorySwapToCgroupV2(swap(-1), 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, "max", value)
+
+	value, err = ConvertMemorySwapToCgroupV2(swap(3072), 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, "2048", value)
+
+	_, err = ConvertMemorySwapToCgroupV2(swap(512), 1024)
+	assert.Error(t, err)
+	_, err = ConvertMemorySwapToCgroupV2(swap(512), 0)
+	assert.Error(t, err)
+}
+
+func TestBlkioCgroup2Values(t *testing.T) {
+	throttle := func(major, minor int64, rate uint64) spec.LinuxThrottleDevice {
+		d := spec.LinuxThrottleDevice{Rate: rate}
+		d.Major, d.Minor = major, minor
+		return d
+	}
+	weight := uint16(500)
+	deviceWeight := spec.LinuxWeightDevice{}
+	deviceWeight.Major, deviceWeight.Minor = 8, 0
+	deviceWeight.Weight = new(uint16)
+	*deviceWeight.Weight = 1000
+	blkio := &spec.LinuxBlockIO{
+		Weight:                  &weight,
+		WeightDevice:            []spec.LinuxWeightDevice{deviceWeight},
+		ThrottleReadBpsDevice:   []spec.LinuxThrottleDevice{throttle(8, 0, 1048576)},
+		ThrottleWriteIOPSDevice: []spec.LinuxThrottleDevice{throttle(8, 0, 100), throttle(8, 16, 0)},
+	}
+	assert.Equal(t, []fileValue{
+		{"io.weight", "default 4950"},
+		{"io.weight", "8:0 10000"},
+		{"io.max", "8:0 rbps=1048576 wiops=100"},
+		{"io.max", "8:16 wiops=max"},
+	}, blkioCgroup2Values(blkio))
+}
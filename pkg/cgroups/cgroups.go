@@ -111,6 +111,8 @@ const (
 	Pids = "pids"
 	// Blkio is the blkio controller
 	Blkio = "blkio"
+	// IO is the cgroup v2 controller replacing the blkio controller
+	IO = "io"
 )
 
 var handlers map[string]controllerHandler
@@ -156,24 +158,10 @@ func (c *CgroupControl) getCgroupv1Path(name string) string {
 
 // createCgroupv2Path creates the cgroupv2 path and enables all the available controllers
 func createCgroupv2Path(path string) (Err error) {
-	content, err := ioutil.ReadFile("/sys/fs/cgroup/cgroup.controllers")
-	if err != nil {
-		return errors.Wrapf(err, "read /sys/fs/cgroup/cgroup.controllers")
-	}
 	if !strings.HasPrefix(path, "/sys/fs/cgroup/") {
 		return fmt.Errorf("invalid cgroup path %s", path)
 	}
 
-	res := ""
-	for i, c := range strings.Split(strings.TrimSpace(string(content)), " ") {
-		if i == 0 {
-			res = fmt.Sprintf("+%s", c)
-		} else {
-			res = res + fmt.Sprintf(" +%s", c)
-		}
-	}
-	resByte := []byte(res)
-
 	current := "/sys/fs"
 	elements := strings.Split(path, "/")
 	for i, e := range elements[3:] {
@@ -195,14 +183,47 @@ func createCgroupv2Path(path string) (Err error) {
 		// We enable the controllers for all the path components except the last one.  It is not allowed to add
 		// PIDs if there are already enabled controllers.
 		if i < len(elements[3:])-1 {
-			if err := ioutil.WriteFile(filepath.Join(current, "cgroup.subtree_control"), resByte, 0755); err != nil {
-				return errors.Wrapf(err, "write %s", filepath.Join(current, "cgroup.subtree_control"))
+			if err := enableCgroupv2Controllers(current); err != nil {
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// enableCgroupv2Controllers enables for the children of a cgroup the
+// controllers available to it which are not enabled yet. Only enabling
+// missing controllers allows creating cgroups under a delegated cgroup, whose
+// parents cannot be written to.
+func enableCgroupv2Controllers(dir string) error {
+	available, err := readControllers(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return err
+	}
+	subtreeControl := filepath.Join(dir, "cgroup.subtree_control")
+	enabled, err := readControllers(subtreeControl)
+	if err != nil {
+		return err
+	}
+	enabledSet := make(map[string]bool)
+	for _, c := range enabled {
+		enabledSet[c] = true
+	}
+	var missing []string
+	for _, c := range available {
+		if !enabledSet[c] {
+			missing = append(missing, "+"+c)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if err := ioutil.WriteFile(subtreeControl, []byte(strings.Join(missing, " ")), 0755); err != nil {
+		return errors.Wrapf(err, "write %s", subtreeControl)
+	}
+	return nil
+}
+
 // initialize initializes the specified hierarchy
 func (c *CgroupControl) initialize() (err error) {
 	createdSoFar := map[string]controllerHandler{}
@@ -330,6 +351,14 @@ func Load(path string) (*CgroupControl, error) {
 		path:    path,
 		systemd: false,
 	}
+	if cgroup2 {
+		if _, err := os.Stat(filepath.Join(cgroupRoot, path)); err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrCgroupDeleted
+			}
+			return nil, errors.Wrapf(err, "stat cgroup %s", path)
+		}
+	}
 	if !cgroup2 {
 		controllers, err := getAvailableControllers(handlers, false)
 		if err != nil {
@@ -355,8 +384,9 @@ func Load(path string) (*CgroupControl, error) {
 	return control, nil
 }
 
-// CreateSystemdUnit creates the systemd cgroup
-func (c *CgroupControl) CreateSystemdUnit(path string) error {
+// CreateSystemdUnit creates the systemd cgroup, setting the given properties
+// on its unit
+func (c *CgroupControl) CreateSystemdUnit(path string, properties ...systemdDbus.Property) error {
 	if !c.systemd {
 		return fmt.Errorf("the cgroup controller is not using systemd")
 	}
@@ -367,7 +397,7 @@ func (c *CgroupControl) CreateSystemdUnit(path string) error {
 	}
 	defer conn.Close()
 
-	return systemdCreate(path, conn)
+	return systemdCreate(path, conn, properties...)
 }
 
 // GetUserConnection returns an user connection to D-BUS
@@ -377,8 +407,9 @@ func GetUserConnection(uid int) (*systemdDbus.Conn, error) {
 	})
 }
 
-// CreateSystemdUserUnit creates the systemd cgroup for the specified user
-func (c *CgroupControl) CreateSystemdUserUnit(path string, uid int) error {
+// CreateSystemdUserUnit creates the systemd cgroup for the specified user,
+// setting the given properties on its unit
+func (c *CgroupControl) CreateSystemdUserUnit(path string, uid int, properties ...systemdDbus.Property) error {
 	if !c.systemd {
 		return fmt.Errorf("the cgroup controller is not using systemd")
 	}
@@ -389,7 +420,7 @@ func (c *CgroupControl) CreateSystemdUserUnit(path string, uid int) error {
 	}
 	defer conn.Close()
 
-	return systemdCreate(path, conn)
+	return systemdCreate(path, conn, properties...)
 }
 
 func dbusAuthConnection(uid int, createBus func(opts ...dbus.ConnOption) (*dbus.Conn, error)) (*dbus.Conn, error) {
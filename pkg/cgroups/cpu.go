@@ -55,7 +55,55 @@ func (c *cpuHandler) Apply(ctr *CgroupControl, res *spec.LinuxResources) error {
 	if res.CPU == nil {
 		return nil
 	}
-	return fmt.Errorf("cpu apply not implemented yet")
+	if res.CPU.Shares != nil && *res.CPU.Shares > 0 {
+		if err := c.applyShares(ctr, *res.CPU.Shares); err != nil {
+			return err
+		}
+	}
+	if res.CPU.Quota == nil && res.CPU.Period == nil {
+		return nil
+	}
+
+	period := uint64(100000)
+	if res.CPU.Period != nil && *res.CPU.Period > 0 {
+		period = *res.CPU.Period
+	}
+	quota := int64(-1)
+	if res.CPU.Quota != nil && *res.CPU.Quota > 0 {
+		quota = *res.CPU.Quota
+	}
+
+	if ctr.cgroup2 {
+		if err := ctr.checkCgroup2Controller(CPU); err != nil {
+			return err
+		}
+		max := "max"
+		if quota > 0 {
+			max = fmt.Sprintf("%d", quota)
+		}
+		p := filepath.Join(cgroupRoot, ctr.path, "cpu.max")
+		return ioutil.WriteFile(p, []byte(fmt.Sprintf("%s %d\n", max, period)), 0644)
+	}
+
+	cpuRoot := ctr.getCgroupv1Path(CPU)
+	if err := ioutil.WriteFile(filepath.Join(cpuRoot, "cpu.cfs_period_us"), []byte(fmt.Sprintf("%d\n", period)), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(cpuRoot, "cpu.cfs_quota_us"), []byte(fmt.Sprintf("%d\n", quota)), 0644)
+}
+
+// applyShares sets the relative CPU weight of the cgroup. With cgroups v2 the
+// shares are converted to a weight.
+func (c *cpuHandler) applyShares(ctr *CgroupControl, shares uint64) error {
+	if ctr.cgroup2 {
+		if err := ctr.checkCgroup2Controller(CPU); err != nil {
+			return err
+		}
+		p := filepath.Join(cgroupRoot, ctr.path, "cpu.weight")
+		return ioutil.WriteFile(p, []byte(fmt.Sprintf("%d\n", ConvertCPUSharesToCgroupV2(shares))), 0644)
+	}
+	p := filepath.Join(ctr.getCgroupv1Path(CPU), "cpu.shares")
+	return ioutil.WriteFile(p, []byte(fmt.Sprintf("%d\n", shares)), 0644)
 }
 
 // Create the cgroup
@@ -81,20 +129,20 @@ func (c *cpuHandler) Stat(ctr *CgroupControl, m *Metrics) error {
 			return err
 		}
 		if val, found := values["usage_usec"]; found {
-			usage.Kernel, err = strconv.ParseUint(cleanString(val[0]), 10, 0)
+			usage.Total, err = strconv.ParseUint(cleanString(val[0]), 10, 0)
 			if err != nil {
 				return err
 			}
-			usage.Kernel *= 1000
+			usage.Total *= 1000
 		}
 		if val, found := values["system_usec"]; found {
-			usage.Total, err = strconv.ParseUint(cleanString(val[0]), 10, 0)
+			usage.Kernel, err = strconv.ParseUint(cleanString(val[0]), 10, 0)
 			if err != nil {
 				return err
 			}
-			usage.Total *= 1000
+			usage.Kernel *= 1000
 		}
-		// FIXME: How to read usage.PerCPU?
+		// cgroups v2 does not account the usage per CPU
 	} else {
 		usage.Total, err = readAcct(ctr, "cpuacct.usage")
 		if err != nil {
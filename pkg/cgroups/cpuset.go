@@ -54,10 +54,30 @@ func getCpusetHandler() *cpusetHandler {
 
 // Apply set the specified constraints
 func (c *cpusetHandler) Apply(ctr *CgroupControl, res *spec.LinuxResources) error {
-	if res.CPU == nil {
+	if res.CPU == nil || (res.CPU.Cpus == "" && res.CPU.Mems == "") {
 		return nil
 	}
-	return fmt.Errorf("cpuset apply not implemented yet")
+	cpusetRoot := filepath.Join(cgroupRoot, ctr.path)
+	if ctr.cgroup2 {
+		if err := ctr.checkCgroup2Controller(CPUset); err != nil {
+			return err
+		}
+	} else {
+		cpusetRoot = ctr.getCgroupv1Path(CPUset)
+	}
+	for file, value := range map[string]string{
+		"cpuset.cpus": res.CPU.Cpus,
+		"cpuset.mems": res.CPU.Mems,
+	} {
+		if value == "" {
+			continue
+		}
+		p := filepath.Join(cpusetRoot, file)
+		if err := ioutil.WriteFile(p, []byte(value), 0644); err != nil {
+			return errors.Wrapf(err, "write %s", p)
+		}
+	}
+	return nil
 }
 
 // Create the cgroup
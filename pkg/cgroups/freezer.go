@@ -0,0 +1,114 @@
+package cgroups
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Freezer is the freezer controller
+	Freezer = "freezer"
+
+	// freezeRetries is how many times the freezer state is checked before
+	// giving up on a freeze or thaw taking effect
+	freezeRetries = 1000
+	// freezeRetryInterval is how long to wait between checks of the
+	// freezer state
+	freezeRetryInterval = time.Millisecond
+)
+
+// Freeze suspends every process in the cgroup and its descendants in a single
+// operation, and waits until all of them are frozen.
+func (c *CgroupControl) Freeze() error {
+	return c.setFrozen(true)
+}
+
+// Thaw resumes every process in the cgroup and its descendants, and waits
+// until none of them are frozen.
+func (c *CgroupControl) Thaw() error {
+	return c.setFrozen(false)
+}
+
+func (c *CgroupControl) setFrozen(frozen bool) error {
+	if c.cgroup2 {
+		return c.setFrozenV2(frozen)
+	}
+	return c.setFrozenV1(frozen)
+}
+
+func (c *CgroupControl) setFrozenV1(frozen bool) error {
+	want := "THAWED"
+	if frozen {
+		want = "FROZEN"
+	}
+	p := filepath.Join(c.getCgroupv1Path(Freezer), "freezer.state")
+
+	for i := 0; i < freezeRetries; i++ {
+		// The kernel may need the state written again before a freeze
+		// completes if new tasks were forked while it was in progress
+		if err := ioutil.WriteFile(p, []byte(want), 0644); err != nil {
+			return errors.Wrapf(err, "write %s", p)
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return errors.Wrapf(err, "read %s", p)
+		}
+		if cleanString(string(data)) == want {
+			return nil
+		}
+		time.Sleep(freezeRetryInterval)
+	}
+	return errors.Errorf("timed out waiting for %s to become %s", p, want)
+}
+
+func (c *CgroupControl) setFrozenV2(frozen bool) error {
+	want := "0"
+	if frozen {
+		want = "1"
+	}
+	dir := filepath.Join(cgroupRoot, c.path)
+	p := filepath.Join(dir, "cgroup.freeze")
+	if err := ioutil.WriteFile(p, []byte(want), 0644); err != nil {
+		return errors.Wrapf(err, "write %s", p)
+	}
+
+	eventsPath := filepath.Join(dir, "cgroup.events")
+	for i := 0; i < freezeRetries; i++ {
+		state, err := readCgroup2Frozen(eventsPath)
+		if err != nil {
+			return err
+		}
+		if state == want {
+			return nil
+		}
+		time.Sleep(freezeRetryInterval)
+	}
+	return errors.Errorf("timed out waiting for %s to become %s", p, want)
+}
+
+// readCgroup2Frozen returns the value of the frozen key of the given
+// cgroup.events file
+func readCgroup2Frozen(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "open file %s", path)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) == 2 && parts[0] == "frozen" {
+			return parts[1], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", errors.Wrapf(err, "parsing file %s", path)
+	}
+	return "", errors.Errorf("no frozen state in %s", path)
+}
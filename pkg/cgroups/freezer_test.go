@@ -0,0 +1,26 @@
+package cgroups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCgroup2Frozen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cgroup.events")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("populated 1\nfrozen 1\n"), 0644))
+	state, err := readCgroup2Frozen(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", state)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("populated 0\n"), 0644))
+	_, err = readCgroup2Frozen(path)
+	assert.Error(t, err)
+}
@@ -2,9 +2,11 @@ package cgroups
 
 import (
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 
 	spec "github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/pkg/errors"
 )
 
 type memHandler struct {
@@ -19,7 +21,62 @@ func (c *memHandler) Apply(ctr *CgroupControl, res *spec.LinuxResources) error {
 	if res.Memory == nil {
 		return nil
 	}
-	return fmt.Errorf("memory apply not implemented yet")
+	if ctr.cgroup2 {
+		return c.applyCgroup2(ctr, res.Memory)
+	}
+	if res.Memory.Limit == nil {
+		return nil
+	}
+
+	limit := fmt.Sprintf("%d", *res.Memory.Limit)
+	if *res.Memory.Limit <= 0 {
+		limit = "-1"
+	}
+	p := filepath.Join(ctr.getCgroupv1Path(Memory), "memory.limit_in_bytes")
+	return ioutil.WriteFile(p, []byte(limit+"\n"), 0644)
+}
+
+// applyCgroup2 sets the memory limits of a cgroup v2. The swap limit of cgroups
+// v1 covers memory and swap, while the one of cgroups v2 only covers swap.
+func (c *memHandler) applyCgroup2(ctr *CgroupControl, memory *spec.LinuxMemory) error {
+	if memory.Limit == nil && memory.Reservation == nil && memory.Swap == nil {
+		return nil
+	}
+	if err := ctr.checkCgroup2Controller(Memory); err != nil {
+		return err
+	}
+	var limit int64
+	if memory.Limit != nil {
+		limit = *memory.Limit
+	}
+	swap, err := ConvertMemorySwapToCgroupV2(memory.Swap, limit)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{}
+	if memory.Limit != nil {
+		values["memory.max"] = cgroup2Limit(limit)
+	}
+	if memory.Reservation != nil {
+		values["memory.low"] = cgroup2Limit(*memory.Reservation)
+	}
+	if swap != "" {
+		values["memory.swap.max"] = swap
+	}
+	// The swap limit is set last, memory.swap.max is missing when swap
+	// accounting is disabled
+	for _, file := range []string{"memory.max", "memory.low", "memory.swap.max"} {
+		value, ok := values[file]
+		if !ok {
+			continue
+		}
+		p := filepath.Join(cgroupRoot, ctr.path, file)
+		if err := ioutil.WriteFile(p, []byte(value+"\n"), 0644); err != nil {
+			return errors.Wrapf(err, "write %s", p)
+		}
+	}
+	return nil
 }
 
 // Create the cgroup
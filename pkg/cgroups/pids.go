@@ -28,12 +28,19 @@ func (c *pidHandler) Apply(ctr *CgroupControl, res *spec.LinuxResources) error {
 		PIDRoot = ctr.getCgroupv1Path(Pids)
 	}
 
+	limit := "max"
+	if res.Pids.Limit > 0 {
+		limit = fmt.Sprintf("%d", res.Pids.Limit)
+	}
 	p := filepath.Join(PIDRoot, "pids.max")
-	return ioutil.WriteFile(p, []byte(fmt.Sprintf("%d\n", res.Pids.Limit)), 0644)
+	return ioutil.WriteFile(p, []byte(limit+"\n"), 0644)
 }
 
 // Create the cgroup
 func (c *pidHandler) Create(ctr *CgroupControl) (bool, error) {
+	if ctr.cgroup2 {
+		return false, nil
+	}
 	return ctr.createCgroupDirectory(Pids)
 }
 
@@ -9,7 +9,7 @@ import (
 	"github.com/godbus/dbus"
 )
 
-func systemdCreate(path string, c *systemdDbus.Conn) error {
+func systemdCreate(path string, c *systemdDbus.Conn, extraProperties ...systemdDbus.Property) error {
 	slice, name := filepath.Split(path)
 	slice = strings.TrimSuffix(slice, "/")
 
@@ -35,6 +35,7 @@ func systemdCreate(path string, c *systemdDbus.Conn) error {
 			}
 			properties = append(properties, p)
 		}
+		properties = append(properties, extraProperties...)
 
 		ch := make(chan string)
 		_, err := c.StartTransientUnit(name, "replace", properties, ch)
@@ -77,3 +78,23 @@ func systemdDestroyConn(path string, c *systemdDbus.Conn) error {
 	<-ch
 	return nil
 }
+
+// CreateSystemdScope creates a transient scope under the slice, through the
+// given connection to systemd, and moves the process into it. Resource control
+// is delegated to the scope, so that the process can manage the cgroups under
+// it. Rootless processes use a connection to the systemd user instance.
+func CreateSystemdScope(conn *systemdDbus.Conn, pid int, slice, name string) error {
+	properties := []systemdDbus.Property{
+		systemdDbus.PropSlice(slice),
+		systemdDbus.PropPids(uint32(pid)),
+		{Name: "Delegate", Value: dbus.MakeVariant(true)},
+		{Name: "DefaultDependencies", Value: dbus.MakeVariant(false)},
+	}
+	ch := make(chan string)
+	if _, err := conn.StartTransientUnit(name, "replace", properties, ch); err != nil {
+		return err
+	}
+	// Block until the scope is started
+	<-ch
+	return nil
+}
@@ -1,16 +1,21 @@
 package namespaces
 
 import (
+	"strconv"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 const (
+	autoType      = "auto"
 	bridgeType    = "bridge"
 	containerType = "container"
 	defaultType   = "default"
 	hostType      = "host"
 	noneType      = "none"
 	nsType        = "ns"
+	pastaType     = "pasta"
 	podType       = "pod"
 	privateType   = "private"
 	shareableType = "shareable"
@@ -87,6 +92,31 @@ func (n UsernsMode) IsKeepID() bool {
 	return n == "keep-id"
 }
 
+// IsAuto indicates whether the container gets a user namespace mapping IDs
+// allocated from the subordinate IDs of the host (auto[:size=N]).
+func (n UsernsMode) IsAuto() bool {
+	parts := strings.SplitN(string(n), ":", 2)
+	return parts[0] == autoType
+}
+
+// AutoSize returns the number of IDs requested with auto:size=N, or 0 if none
+// was.
+func (n UsernsMode) AutoSize() (int, error) {
+	parts := strings.SplitN(string(n), ":", 2)
+	if len(parts) < 2 {
+		return 0, nil
+	}
+	option := strings.SplitN(parts[1], "=", 2)
+	if len(option) != 2 || option[0] != "size" {
+		return 0, errors.Errorf("invalid option %q for auto user namespace, only size is supported", parts[1])
+	}
+	size, err := strconv.Atoi(option[1])
+	if err != nil || size <= 0 {
+		return 0, errors.Errorf("invalid size %q for auto user namespace", option[1])
+	}
+	return size, nil
+}
+
 // IsPrivate indicates whether the container uses the a private userns.
 func (n UsernsMode) IsPrivate() bool {
 	return !(n.IsHost() || n.IsContainer())
@@ -97,6 +127,10 @@ func (n UsernsMode) Valid() bool {
 	parts := strings.Split(string(n), ":")
 	switch mode := parts[0]; mode {
 	case "", hostType, "keep-id", nsType:
+	case autoType:
+		if _, err := n.AutoSize(); err != nil {
+			return false
+		}
 	case containerType:
 		if len(parts) != 2 || parts[1] == "" {
 			return false
@@ -326,6 +360,11 @@ func (n NetworkMode) IsSlirp4netns() bool {
 	return n == slirpType
 }
 
+// IsPasta indicates if we are running a rootless network stack using pasta
+func (n NetworkMode) IsPasta() bool {
+	return n == pastaType
+}
+
 // IsNS indicates a network namespace passed in by path (ns:<path>)
 func (n NetworkMode) IsNS() bool {
 	return strings.HasPrefix(string(n), nsType)
@@ -347,5 +386,5 @@ func (n NetworkMode) IsPod() bool {
 
 // IsUserDefined indicates user-created network
 func (n NetworkMode) IsUserDefined() bool {
-	return !n.IsDefault() && !n.IsBridge() && !n.IsHost() && !n.IsNone() && !n.IsContainer() && !n.IsSlirp4netns() && !n.IsNS()
+	return !n.IsDefault() && !n.IsBridge() && !n.IsHost() && !n.IsNone() && !n.IsContainer() && !n.IsSlirp4netns() && !n.IsPasta() && !n.IsNS()
 }
@@ -0,0 +1,210 @@
+// Package schedule parses container start schedules and computes when they
+// are next due.
+//
+// A schedule is either a single point in time, given in RFC 3339 format
+// (e.g. 2020-01-02T15:04:05Z), or a cron expression with the five fields
+// minute, hour, day of month, month and day of week. Fields support "*",
+// lists ("1,15"), ranges ("1-5") and steps ("*/10", "0-30/5"). The macros
+// @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are
+// also accepted. As in cron, if both the day of month and the day of week are
+// restricted, a day matching either of them is due.
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxSearchYears bounds the search for the next run of cron expressions that
+// can never be due, such as "0 0 30 2 *".
+const maxSearchYears = 5
+
+// Schedule computes when a schedule is next due.
+type Schedule interface {
+	// Next returns the first time the schedule is due strictly after the
+	// given time, or the zero time if it is never due again.
+	Next(after time.Time) time.Time
+}
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a schedule.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("schedule must not be empty")
+	}
+	if at, err := time.Parse(time.RFC3339, spec); err == nil {
+		return atSchedule(at), nil
+	}
+	if expanded, ok := macros[spec]; ok {
+		spec = expanded
+	}
+	return parseCron(spec)
+}
+
+// atSchedule is due once, at the given time.
+type atSchedule time.Time
+
+// Next implements Schedule.
+func (s atSchedule) Next(after time.Time) time.Time {
+	if at := time.Time(s); at.After(after) {
+		return at
+	}
+	return time.Time{}
+}
+
+// cronSchedule is due at every minute matching all of its fields. Each field
+// is a bit set of the values it matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day of month and the day of
+	// week fields were "*", which changes how days are matched.
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, errors.Errorf("schedule %q is neither an RFC 3339 time nor a cron expression with %d fields", spec, len(cronFields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+		bits[i] = b
+	}
+
+	s := &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}
+	// Both 0 and 7 are Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps.
+func parseCronField(field string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return 0, errors.Errorf("invalid step %q in %s field", part[i+1:], f.name)
+			}
+			part = part[:i]
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = parseCronValue(bounds[0], f); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(bounds[1], f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, errors.Errorf("invalid range %q in %s field", part, f.name)
+			}
+		default:
+			value, err := parseCronValue(part, f)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if step == 1 {
+				high = value
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, f cronField) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("invalid value %q in %s field, must be between %d and %d", value, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next implements Schedule.
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@sometimes",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Wednesday
+	now := time.Date(2019, time.October, 16, 10, 30, 15, 0, time.UTC)
+
+	for _, tt := range []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2019, time.October, 16, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, time.October, 16, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2019, time.October, 17, 3, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2019, time.October, 16, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2019, time.November, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, time.October, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1-5 * 5", time.Date(2019, time.October, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+		{"2019-10-16T12:00:00Z", time.Date(2019, time.October, 16, 12, 0, 0, 0, time.UTC)},
+		{"2019-10-16T09:00:00Z", time.Time{}},
+	} {
+		s, err := Parse(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.True(t, tt.next.Equal(s.Next(now)), "%s: expected %v, got %v", tt.spec, tt.next, s.Next(now))
+	}
+}
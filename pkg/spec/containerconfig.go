@@ -17,10 +17,14 @@ func (config *CreateConfig) MakeContainerConfig(runtime *libpod.Runtime, pod *li
 		return nil, nil, errors.Wrapf(define.ErrInvalidArg, "pod was given but no pod is specified")
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+
 	// Parse volumes flag into OCI spec mounts and libpod Named Volumes.
 	// If there is an identical mount in the OCI spec, we will replace it
 	// with a mount generated here.
-	mounts, namedVolumes, err := config.parseVolumes(runtime)
+	mounts, namedVolumes, err := config.parseVolumes(runtime, pod)
 	if err != nil {
 		return nil, nil, err
 	}
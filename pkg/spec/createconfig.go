@@ -3,6 +3,7 @@ package createconfig
 import (
 	"net"
 	"os"
+	"os/user"
 	"strconv"
 	"strings"
 	"syscall"
@@ -11,6 +12,7 @@ import (
 	"github.com/containers/libpod/libpod"
 	"github.com/containers/libpod/libpod/define"
 	"github.com/containers/libpod/pkg/namespaces"
+	"github.com/containers/libpod/pkg/rootless"
 	"github.com/containers/storage"
 	"github.com/cri-o/ocicni/pkg/ocicni"
 	"github.com/docker/go-connections/nat"
@@ -63,6 +65,10 @@ type CreateConfig struct {
 	CapDrop            []string // cap-drop
 	CidFile            string
 	ConmonPidFile      string
+	ConmonLogLevel     string // conmon-log-level
+	ConmonSyslog       bool   // conmon-syslog
+	ConmonDebugLog     bool   // conmon-debug-log
+	CreateCommand      []string
 	Cgroupns           string
 	CgroupParent       string            // cgroup-parent
 	Command            []string          // Full command that will be used
@@ -77,12 +83,14 @@ type CreateConfig struct {
 	ExposedPorts       map[nat.Port]struct{}
 	GroupAdd           []string // group-add
 	HealthCheck        *manifest.Schema2HealthConfig
+	HealthOnFailure    string // health-on-failure
 	NoHosts            bool
 	HostAdd            []string //add-host
 	Hostname           string   //hostname
-	HTTPProxy          bool
-	Init               bool   // init
-	InitPath           string //init-path
+	HTTPProxy          *bool    // http-proxy, nil uses the runtime default
+	Init               bool     // init
+	InitCtrType        string   // init-ctr
+	InitPath           string   //init-path
 	Image              string
 	ImageID            string
 	BuiltinImgVolumes  map[string]struct{} // volumes defined in the image config
@@ -101,6 +109,8 @@ type CreateConfig struct {
 	NetMode            namespaces.NetworkMode //net
 	Network            string                 //network
 	NetworkAlias       []string               //network-alias
+	NetIngressRate     uint64                 //network-ingress-rate
+	NetEgressRate      uint64                 //network-egress-rate
 	PidMode            namespaces.PidMode     //pid
 	Pod                string                 //pod
 	CgroupMode         namespaces.CgroupMode  //cgroup
@@ -113,10 +123,13 @@ type CreateConfig struct {
 	ReadOnlyTmpfs      bool     //read-only-tmpfs
 	Resources          CreateResourceConfig
 	RestartPolicy      string
-	Rm                 bool              //rm
-	StopSignal         syscall.Signal    // stop-signal
-	StopTimeout        uint              // stop-timeout
-	Sysctl             map[string]string //sysctl
+	Rm                 bool                    //rm
+	SdNotifyMode       string                  // sdnotify
+	StartSchedule      string                  // schedule
+	StopSignal         syscall.Signal          // stop-signal
+	StopTimeout        uint                    // stop-timeout
+	StopEscalation     []libpod.StopSignalStep // stop-escalation
+	Sysctl             map[string]string       //sysctl
 	Systemd            bool
 	Tmpfs              []string              // tmpfs
 	Tty                bool                  //tty
@@ -147,6 +160,12 @@ func (c *CreateConfig) CreateBlockIO() (*spec.LinuxBlockIO, error) {
 }
 
 func (c *CreateConfig) createExitCommand(runtime *libpod.Runtime) ([]string, error) {
+	return CreateExitCommand(runtime, c.Syslog, c.Rm)
+}
+
+// CreateExitCommand returns the command run by conmon when a container exits,
+// which cleans up the container, and removes it if rm is set
+func CreateExitCommand(runtime *libpod.Runtime, syslog, rm bool) ([]string, error) {
 	config, err := runtime.GetConfig()
 	if err != nil {
 		return nil, err
@@ -173,12 +192,12 @@ func (c *CreateConfig) createExitCommand(runtime *libpod.Runtime) ([]string, err
 		command = append(command, []string{"--events-backend", config.EventsLogger}...)
 	}
 
-	if c.Syslog {
+	if syslog {
 		command = append(command, "--syslog", "true")
 	}
 	command = append(command, []string{"container", "cleanup"}...)
 
-	if c.Rm {
+	if rm {
 		command = append(command, "--rm")
 	}
 
@@ -206,6 +225,15 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 		logrus.Debugf("adding container to pod %s", c.Pod)
 		options = append(options, runtime.WithPod(pod))
 	}
+	if c.InitCtrType != "" {
+		options = append(options, libpod.WithInitCtrType(define.InitContainerType(c.InitCtrType)))
+	}
+	if c.StartSchedule != "" {
+		options = append(options, libpod.WithStartSchedule(c.StartSchedule))
+	}
+	if c.SdNotifyMode != "" {
+		options = append(options, libpod.WithSdNotifyMode(define.SdNotifyMode(c.SdNotifyMode)))
+	}
 	if len(c.PortBindings) > 0 {
 		portBindings, err = c.CreatePortBindings()
 		if err != nil {
@@ -259,10 +287,8 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 		if ns == "" {
 			return nil, errors.Errorf("invalid empty user-defined network namespace")
 		}
-		_, err := os.Stat(ns)
-		if err != nil {
-			return nil, err
-		}
+		// The namespace is checked when the container starts
+		options = append(options, libpod.WithNetNSPath(ns))
 	} else if c.NetMode.IsContainer() {
 		connectedCtr, err := runtime.LookupContainer(c.NetMode.Container())
 		if err != nil {
@@ -270,11 +296,18 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 		}
 		options = append(options, libpod.WithNetNSFrom(connectedCtr))
 	} else if !c.NetMode.IsHost() && !c.NetMode.IsNone() {
-		hasUserns := c.UsernsMode.IsContainer() || c.UsernsMode.IsNS() || len(c.IDMappings.UIDMap) > 0 || len(c.IDMappings.GIDMap) > 0
-		postConfigureNetNS := c.NetMode.IsSlirp4netns() || (hasUserns && !c.UsernsMode.IsHost())
+		hasUserns := c.UsernsMode.IsContainer() || c.UsernsMode.IsNS() || c.UsernsMode.IsAuto() || len(c.IDMappings.UIDMap) > 0 || len(c.IDMappings.GIDMap) > 0
+		postConfigureNetNS := c.NetMode.IsSlirp4netns() || c.NetMode.IsPasta() || (hasUserns && !c.UsernsMode.IsHost())
 		options = append(options, libpod.WithNetNS(portBindings, postConfigureNetNS, string(c.NetMode), networks))
 	}
 
+	if c.NetIngressRate != 0 || c.NetEgressRate != 0 {
+		options = append(options, libpod.WithBandwidth(ocicni.BandwidthConfig{
+			IngressRate: c.NetIngressRate,
+			EgressRate:  c.NetEgressRate,
+		}))
+	}
+
 	if c.CgroupMode.IsNS() {
 		ns := c.CgroupMode.NS()
 		if ns == "" {
@@ -308,8 +341,21 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 			return nil, errors.Wrapf(err, "container %q not found", c.UsernsMode.Container())
 		}
 		options = append(options, libpod.WithUserNSFrom(connectedCtr))
+	} else if c.UsernsMode.IsAuto() {
+		size, err := c.UsernsMode.AutoSize()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, libpod.WithAutoUserNS(size))
 	} else {
 		options = append(options, libpod.WithIDMappings(*c.IDMappings))
+		if c.UsernsMode.IsKeepID() && rootless.IsRootless() {
+			keepID, err := keepIDUser()
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, libpod.WithUserNSKeepID(keepID))
+		}
 	}
 
 	if c.PidMode.IsContainer() {
@@ -345,6 +391,22 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 	// TODO: MNT, USER, CGROUP
 	options = append(options, libpod.WithStopSignal(c.StopSignal))
 	options = append(options, libpod.WithStopTimeout(c.StopTimeout))
+	if len(c.StopEscalation) > 0 {
+		options = append(options, libpod.WithStopEscalation(c.StopEscalation))
+	}
+	if c.ReadOnlyRootfs {
+		// The tmpfs mounts are added by libpod when the container
+		// starts, skipping any path the user mounted something on.
+		var tmpfs []string
+		if c.ReadOnlyTmpfs {
+			config, err := runtime.GetConfig()
+			if err != nil {
+				return nil, err
+			}
+			tmpfs = config.ReadOnlyTmpfsPaths
+		}
+		options = append(options, libpod.WithReadOnlyRootfs(tmpfs))
+	}
 	if len(c.DNSSearch) > 0 {
 		options = append(options, libpod.WithDNSSearch(c.DNSSearch))
 	}
@@ -372,6 +434,19 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 	if c.LogDriver != "" {
 		options = append(options, libpod.WithLogDriver(c.LogDriver))
 	}
+	if logTag := getLoggingTag(c.LogDriverOpt); logTag != "" {
+		options = append(options, libpod.WithLogTag(logTag))
+	}
+	if logAddress := getLoggingAddress(c.LogDriverOpt, c.LogDriver); logAddress != "" {
+		options = append(options, libpod.WithLogAddress(logAddress))
+	}
+	logMaxSize, logMaxFiles, err := getLoggingRotation(c.LogDriverOpt)
+	if err != nil {
+		return nil, err
+	}
+	if logMaxSize != 0 || logMaxFiles != 0 {
+		options = append(options, libpod.WithLogRotation(logMaxSize, logMaxFiles))
+	}
 
 	if c.IPAddress != "" {
 		ip := net.ParseIP(c.IPAddress)
@@ -383,6 +458,18 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 		options = append(options, libpod.WithStaticIP(ip))
 	}
 
+	if c.MacAddress != "" {
+		mac, err := net.ParseMAC(c.MacAddress)
+		if err != nil {
+			return nil, errors.Wrapf(define.ErrInvalidArg, "cannot parse %s as MAC address", c.MacAddress)
+		}
+		options = append(options, libpod.WithStaticMAC(mac))
+	}
+
+	if len(c.NetworkAlias) > 0 {
+		options = append(options, libpod.WithNetworkAliases(c.NetworkAlias))
+	}
+
 	options = append(options, libpod.WithPrivileged(c.Privileged))
 
 	useImageVolumes := c.ImageVolumeType == TypeBind
@@ -390,6 +477,18 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 	options = append(options, libpod.WithRootFSFromImage(c.ImageID, c.Image, useImageVolumes))
 	options = append(options, libpod.WithSecLabels(c.LabelOpts))
 	options = append(options, libpod.WithConmonPidFile(c.ConmonPidFile))
+	if c.ConmonLogLevel != "" {
+		options = append(options, libpod.WithConmonLogLevel(c.ConmonLogLevel))
+	}
+	if c.HTTPProxy != nil {
+		options = append(options, libpod.WithHTTPProxy(*c.HTTPProxy))
+	}
+	if c.ConmonSyslog {
+		options = append(options, libpod.WithConmonSyslog())
+	}
+	if c.ConmonDebugLog {
+		options = append(options, libpod.WithConmonDebugLog())
+	}
 	options = append(options, libpod.WithLabels(c.Labels))
 	options = append(options, libpod.WithUser(c.User))
 	if c.IpcMode.IsHost() {
@@ -414,10 +513,6 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 	}
 
 	if c.RestartPolicy != "" {
-		if c.RestartPolicy == "unless-stopped" {
-			return nil, errors.Wrapf(define.ErrInvalidArg, "the unless-stopped restart policy is not supported")
-		}
-
 		split := strings.Split(c.RestartPolicy, ":")
 		if len(split) > 1 {
 			numTries, err := strconv.Atoi(split[1])
@@ -439,9 +534,16 @@ func (c *CreateConfig) getContainerCreateOptions(runtime *libpod.Runtime, pod *l
 	}
 	options = append(options, libpod.WithExitCommand(exitCmd))
 
+	if len(c.CreateCommand) > 0 {
+		options = append(options, libpod.WithCreateCommand(c.CreateCommand))
+	}
+
 	if c.HealthCheck != nil {
 		options = append(options, libpod.WithHealthCheck(c.HealthCheck))
 		logrus.Debugf("New container has a health check")
+		if c.HealthOnFailure != "" {
+			options = append(options, libpod.WithHealthCheckOnFailure(c.HealthOnFailure))
+		}
 	}
 	return options, nil
 }
@@ -483,3 +585,19 @@ func NatToOCIPortBindings(ports nat.PortMap) ([]ocicni.PortMapping, error) {
 func (c *CreateConfig) AddPrivilegedDevices(g *generate.Generator) error {
 	return c.addPrivilegedDevices(g)
 }
+
+// keepIDUser returns the invoking user, whose IDs are kept inside a container
+// created with --userns=keep-id
+func keepIDUser() (libpod.KeepIDUser, error) {
+	uid := rootless.GetRootlessUID()
+	u, err := user.LookupId(strconv.Itoa(uid))
+	if err != nil {
+		return libpod.KeepIDUser{}, errors.Wrapf(err, "error looking up user %d for --userns=keep-id", uid)
+	}
+	return libpod.KeepIDUser{
+		UID:      uint32(uid),
+		GID:      uint32(rootless.GetRootlessGID()),
+		Username: u.Username,
+		HomeDir:  u.HomeDir,
+	}, nil
+}
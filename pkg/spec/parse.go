@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/docker/go-units"
+	"github.com/pkg/errors"
 )
 
 // Pod signifies a kernel namespace is being shared
@@ -132,11 +133,49 @@ func validateIOpsDevice(val string) (*throttleDevice, error) { //nolint
 	}, nil
 }
 
+// getLoggingPath returns the log file path given in the log-opt options
 func getLoggingPath(opts []string) string {
+	return getLoggingOpt(opts, "path")
+}
+
+// getLoggingTag returns the log tag given in the log-opt options
+func getLoggingTag(opts []string) string {
+	return getLoggingOpt(opts, "tag")
+}
+
+// getLoggingAddress returns the endpoint address given in the log-opt options
+// for the given log driver
+func getLoggingAddress(opts []string, driver string) string {
+	return getLoggingOpt(opts, driver+"-address")
+}
+
+// getLoggingRotation returns the maximum size and number of log files given
+// in the log-opt options, zero if not given
+func getLoggingRotation(opts []string) (int64, uint, error) {
+	var (
+		maxSize  int64
+		maxFiles uint64
+		err      error
+	)
+	if size := getLoggingOpt(opts, "max-size"); size != "" {
+		if maxSize, err = units.RAMInBytes(size); err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid log max-size %q", size)
+		}
+	}
+	if files := getLoggingOpt(opts, "max-file"); files != "" {
+		if maxFiles, err = strconv.ParseUint(files, 10, 32); err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid log max-file %q", files)
+		}
+	}
+	return maxSize, uint(maxFiles), nil
+}
+
+// getLoggingOpt returns the value of the log-opt option with the given key
+func getLoggingOpt(opts []string, key string) string {
 	for _, opt := range opts {
 		arr := strings.SplitN(opt, "=", 2)
 		if len(arr) == 2 {
-			if strings.TrimSpace(arr[0]) == "path" {
+			if strings.TrimSpace(arr[0]) == key {
 				return strings.TrimSpace(arr[1])
 			}
 		}
@@ -198,3 +237,16 @@ func IsValidDeviceMode(mode string) bool {
 	}
 	return true
 }
+
+// ParseBandwidth parses a network rate in bits per second with an optional
+// decimal unit (k, m or g), e.g. 10m for 10 megabits per second
+func ParseBandwidth(rate string) (uint64, error) {
+	bits, err := units.FromHumanSize(rate)
+	if err != nil {
+		return 0, err
+	}
+	if bits < 0 {
+		return 0, errors.Errorf("invalid rate %q: must not be negative", rate)
+	}
+	return uint64(bits), nil
+}
@@ -44,7 +44,7 @@ func (config *CreateConfig) createConfigToOCISpec(runtime *libpod.Runtime, userM
 	canMountSys := true
 
 	isRootless := rootless.IsRootless()
-	hasUserns := config.UsernsMode.IsContainer() || config.UsernsMode.IsNS() || len(config.IDMappings.UIDMap) > 0 || len(config.IDMappings.GIDMap) > 0
+	hasUserns := config.UsernsMode.IsContainer() || config.UsernsMode.IsNS() || config.UsernsMode.IsAuto() || len(config.IDMappings.UIDMap) > 0 || len(config.IDMappings.GIDMap) > 0
 	inUserNS := isRootless || (hasUserns && !config.UsernsMode.IsHost())
 
 	if inUserNS && config.NetMode.IsHost() {
@@ -153,24 +153,6 @@ func (config *CreateConfig) createConfigToOCISpec(runtime *libpod.Runtime, userM
 	}
 	g.SetRootReadonly(config.ReadOnlyRootfs)
 
-	if config.HTTPProxy {
-		for _, envSpec := range []string{
-			"http_proxy",
-			"HTTP_PROXY",
-			"https_proxy",
-			"HTTPS_PROXY",
-			"ftp_proxy",
-			"FTP_PROXY",
-			"no_proxy",
-			"NO_PROXY",
-		} {
-			envVal := os.Getenv(envSpec)
-			if envVal != "" {
-				g.AddProcessEnv(envSpec, envVal)
-			}
-		}
-	}
-
 	hostname := config.Hostname
 	if hostname == "" {
 		if utsCtrID := config.UtsMode.Container(); utsCtrID != "" {
@@ -547,6 +529,9 @@ func addNetNS(config *CreateConfig, g *generate.Generator) error {
 	} else if netMode.IsSlirp4netns() {
 		logrus.Debug("Using slirp4netns netmode")
 		return nil
+	} else if netMode.IsPasta() {
+		logrus.Debug("Using pasta netmode")
+		return nil
 	} else if netMode.IsUserDefined() {
 		logrus.Debug("Using user defined netmode")
 		return nil
@@ -4,10 +4,13 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/containers/libpod/pkg/namespaces"
 	"github.com/containers/libpod/pkg/sysinfo"
 	"github.com/containers/storage"
 	"github.com/containers/storage/pkg/idtools"
+	"github.com/docker/go-connections/nat"
 	"github.com/docker/go-units"
+	"github.com/hashicorp/go-multierror"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -96,3 +99,55 @@ func TestMemorySwap(t *testing.T) {
 
 	assert.Equal(t, *(spec.Linux.Resources.Memory.Swap), swapLimit)
 }
+
+// TestValidateCollectsAllErrors verifies that Validate reports every problem
+// in the config, not just the first one.
+func TestValidateCollectsAllErrors(t *testing.T) {
+	cc := makeTestCreateConfig()
+	assert.NoError(t, cc.Validate())
+
+	cc.MountsFlag = []string{"type=bind"}
+	cc.NetMode = namespaces.NetworkMode("container:foo")
+	cc.PortBindings = nat.PortMap{"80/tcp": []nat.PortBinding{{HostPort: "70000"}}}
+	cc.Resources.Ulimit = []string{"nofile"}
+
+	err := cc.Validate()
+	assert.Error(t, err)
+	multiErr, ok := err.(*multierror.Error)
+	assert.True(t, ok)
+
+	options := []string{}
+	for _, e := range multiErr.Errors {
+		validationErr, ok := e.(*ValidationError)
+		assert.True(t, ok)
+		options = append(options, validationErr.Option)
+	}
+	assert.ElementsMatch(t, []string{"mount", "network", "publish", "ulimit"}, options)
+}
+
+func TestParseBandwidth(t *testing.T) {
+	rate, err := ParseBandwidth("10m")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10000000), rate)
+
+	rate, err = ParseBandwidth("512")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(512), rate)
+
+	_, err = ParseBandwidth("fast")
+	assert.Error(t, err)
+	_, err = ParseBandwidth("-1m")
+	assert.Error(t, err)
+}
+
+func TestValidateBandwidthRequiresCNI(t *testing.T) {
+	cc := makeTestCreateConfig()
+	cc.NetIngressRate = 1000000
+	cc.NetMode = namespaces.NetworkMode("bridge")
+	assert.NoError(t, cc.Validate())
+
+	for _, mode := range []string{"host", "none", "container:foo", "slirp4netns"} {
+		cc.NetMode = namespaces.NetworkMode(mode)
+		assert.Error(t, cc.Validate(), mode)
+	}
+}
@@ -35,10 +35,12 @@ var (
 // Parse all volume-related options in the create config into a set of mounts
 // and named volumes to add to the container.
 // Handles --volumes-from, --volumes, --tmpfs, --init, and --init-path flags.
+// Volumes of the pod the container joins are added by libpod, and supersede
+// image volumes and --volumes-from.
 // TODO: Named volume options -  should we default to rprivate? It bakes into a
 // bind mount under the hood...
 // TODO: handle options parsing/processing via containers/storage/pkg/mount
-func (config *CreateConfig) parseVolumes(runtime *libpod.Runtime) ([]spec.Mount, []*libpod.ContainerNamedVolume, error) {
+func (config *CreateConfig) parseVolumes(runtime *libpod.Runtime, pod *libpod.Pod) ([]spec.Mount, []*libpod.ContainerNamedVolume, error) {
 	// Add image volumes.
 	baseMounts, baseVolumes, err := config.getImageVolumes()
 	if err != nil {
@@ -57,6 +59,12 @@ func (config *CreateConfig) parseVolumes(runtime *libpod.Runtime) ([]spec.Mount,
 	for dest, volume := range vFromVolumes {
 		baseVolumes[dest] = volume
 	}
+	if pod != nil {
+		for _, volume := range pod.Volumes() {
+			delete(baseMounts, volume.Dest)
+			delete(baseVolumes, volume.Dest)
+		}
+	}
 
 	// Next mounts from the --mounts flag.
 	// Do not override yet.
@@ -160,29 +168,6 @@ func (config *CreateConfig) parseVolumes(runtime *libpod.Runtime) ([]spec.Mount,
 		baseVolumes[dest] = volume
 	}
 
-	// If requested, add tmpfs filesystems for read-only containers.
-	if config.ReadOnlyRootfs && config.ReadOnlyTmpfs {
-		readonlyTmpfs := []string{"/tmp", "/var/tmp", "/run"}
-		options := []string{"rw", "rprivate", "nosuid", "nodev", "tmpcopyup"}
-		for _, dest := range readonlyTmpfs {
-			if _, ok := baseMounts[dest]; ok {
-				continue
-			}
-			localOpts := options
-			if dest == "/run" {
-				localOpts = append(localOpts, "noexec", "size=65536k")
-			} else {
-				localOpts = append(localOpts, "exec")
-			}
-			baseMounts[dest] = spec.Mount{
-				Destination: dest,
-				Type:        "tmpfs",
-				Source:      "tmpfs",
-				Options:     localOpts,
-			}
-		}
-	}
-
 	// Check for conflicts between named volumes and mounts
 	for dest := range baseMounts {
 		if _, ok := baseVolumes[dest]; ok {
@@ -329,60 +314,71 @@ func (config *CreateConfig) getMounts() (map[string]spec.Mount, map[string]*libp
 	finalMounts := make(map[string]spec.Mount)
 	finalNamedVolumes := make(map[string]*libpod.ContainerNamedVolume)
 
-	errInvalidSyntax := errors.Errorf("incorrect mount format: should be --mount type=<bind|tmpfs|volume>,[src=<host-dir|volume-name>,]target=<ctr-dir>[,options]")
-
-	// TODO(vrothberg): the manual parsing can be replaced with a regular expression
-	//                  to allow a more robust parsing of the mount format and to give
-	//                  precise errors regarding supported format versus suppored options.
-	for _, mount := range config.MountsFlag {
-		arr := strings.SplitN(mount, ",", 2)
-		if len(arr) < 2 {
-			return nil, nil, errors.Wrapf(errInvalidSyntax, "%q", mount)
-		}
-		kv := strings.Split(arr[0], "=")
-		// TODO: type is not explicitly required in Docker.
-		// If not specified, it defaults to "volume".
-		if len(kv) != 2 || kv[0] != "type" {
-			return nil, nil, errors.Wrapf(errInvalidSyntax, "%q", mount)
-		}
-
-		tokens := strings.Split(arr[1], ",")
-		switch kv[1] {
-		case TypeBind:
-			mount, err := getBindMount(tokens)
-			if err != nil {
-				return nil, nil, err
-			}
-			if _, ok := finalMounts[mount.Destination]; ok {
-				return nil, nil, errors.Wrapf(errDuplicateDest, mount.Destination)
-			}
-			finalMounts[mount.Destination] = mount
-		case TypeTmpfs:
-			mount, err := getTmpfsMount(tokens)
-			if err != nil {
-				return nil, nil, err
-			}
+	for _, mountFlag := range config.MountsFlag {
+		mount, volume, err := parseMountFlag(mountFlag)
+		if err != nil {
+			return nil, nil, err
+		}
+		if mount != nil {
 			if _, ok := finalMounts[mount.Destination]; ok {
 				return nil, nil, errors.Wrapf(errDuplicateDest, mount.Destination)
 			}
-			finalMounts[mount.Destination] = mount
-		case "volume":
-			volume, err := getNamedVolume(tokens)
-			if err != nil {
-				return nil, nil, err
-			}
+			finalMounts[mount.Destination] = *mount
+		} else {
 			if _, ok := finalNamedVolumes[volume.Dest]; ok {
 				return nil, nil, errors.Wrapf(errDuplicateDest, volume.Dest)
 			}
 			finalNamedVolumes[volume.Dest] = volume
-		default:
-			return nil, nil, errors.Errorf("invalid filesystem type %q", kv[1])
 		}
 	}
 
 	return finalMounts, finalNamedVolumes, nil
 }
 
+// Parse a single entry from the --mount flag. Either a mount or a named volume
+// is returned.
+func parseMountFlag(mountFlag string) (*spec.Mount, *libpod.ContainerNamedVolume, error) {
+	errInvalidSyntax := errors.Errorf("incorrect mount format: should be --mount type=<bind|tmpfs|volume>,[src=<host-dir|volume-name>,]target=<ctr-dir>[,options]")
+
+	// TODO(vrothberg): the manual parsing can be replaced with a regular expression
+	//                  to allow a more robust parsing of the mount format and to give
+	//                  precise errors regarding supported format versus suppored options.
+	arr := strings.SplitN(mountFlag, ",", 2)
+	if len(arr) < 2 {
+		return nil, nil, errors.Wrapf(errInvalidSyntax, "%q", mountFlag)
+	}
+	kv := strings.Split(arr[0], "=")
+	// TODO: type is not explicitly required in Docker.
+	// If not specified, it defaults to "volume".
+	if len(kv) != 2 || kv[0] != "type" {
+		return nil, nil, errors.Wrapf(errInvalidSyntax, "%q", mountFlag)
+	}
+
+	tokens := strings.Split(arr[1], ",")
+	switch kv[1] {
+	case TypeBind:
+		mount, err := getBindMount(tokens)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &mount, nil, nil
+	case TypeTmpfs:
+		mount, err := getTmpfsMount(tokens)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &mount, nil, nil
+	case "volume":
+		volume, err := getNamedVolume(tokens)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, volume, nil
+	default:
+		return nil, nil, errors.Errorf("invalid filesystem type %q", kv[1])
+	}
+}
+
 // Parse a single bind mount entry from the --mount flag.
 func getBindMount(args []string) (spec.Mount, error) {
 	newMount := spec.Mount{
@@ -628,71 +624,83 @@ func (config *CreateConfig) getVolumeMounts() (map[string]spec.Mount, map[string
 	mounts := make(map[string]spec.Mount)
 	volumes := make(map[string]*libpod.ContainerNamedVolume)
 
-	volumeFormatErr := errors.Errorf("incorrect volume format, should be host-dir:ctr-dir[:option]")
-
 	for _, vol := range config.Volumes {
-		var (
-			options []string
-			src     string
-			dest    string
-			err     error
-		)
-
-		splitVol := strings.Split(vol, ":")
-		if len(splitVol) > 3 {
-			return nil, nil, errors.Wrapf(volumeFormatErr, vol)
-		}
-
-		src = splitVol[0]
-		if len(splitVol) == 1 {
-			dest = src
-		} else if len(splitVol) > 1 {
-			dest = splitVol[1]
-		}
-		if len(splitVol) > 2 {
-			if options, err = parse.ValidateVolumeOpts(strings.Split(splitVol[2], ",")); err != nil {
-				return nil, nil, err
-			}
-		}
-
-		if err := parse.ValidateVolumeHostDir(src); err != nil {
-			return nil, nil, err
-		}
-		if err := parse.ValidateVolumeCtrDir(dest); err != nil {
+		newMount, newNamedVol, err := parseVolumeFlag(vol)
+		if err != nil {
 			return nil, nil, err
 		}
-
-		if strings.HasPrefix(src, "/") || strings.HasPrefix(src, ".") {
-			// This is not a named volume
-			newMount := spec.Mount{
-				Destination: dest,
-				Type:        string(TypeBind),
-				Source:      src,
-				Options:     options,
-			}
+		if newMount != nil {
 			if _, ok := mounts[newMount.Destination]; ok {
 				return nil, nil, errors.Wrapf(errDuplicateDest, newMount.Destination)
 			}
-			mounts[newMount.Destination] = newMount
+			mounts[newMount.Destination] = *newMount
 		} else {
-			// This is a named volume
-			newNamedVol := new(libpod.ContainerNamedVolume)
-			newNamedVol.Name = src
-			newNamedVol.Dest = dest
-			newNamedVol.Options = options
-
 			if _, ok := volumes[newNamedVol.Dest]; ok {
 				return nil, nil, errors.Wrapf(errDuplicateDest, newNamedVol.Dest)
 			}
 			volumes[newNamedVol.Dest] = newNamedVol
 		}
-
-		logrus.Debugf("User mount %s:%s options %v", src, dest, options)
 	}
 
 	return mounts, volumes, nil
 }
 
+// Parse a single entry from the --volume flag. Either a bind mount or a named
+// volume is returned.
+func parseVolumeFlag(vol string) (*spec.Mount, *libpod.ContainerNamedVolume, error) {
+	var (
+		options []string
+		src     string
+		dest    string
+		err     error
+	)
+
+	volumeFormatErr := errors.Errorf("incorrect volume format, should be host-dir:ctr-dir[:option]")
+
+	splitVol := strings.Split(vol, ":")
+	if len(splitVol) > 3 {
+		return nil, nil, errors.Wrapf(volumeFormatErr, vol)
+	}
+
+	src = splitVol[0]
+	if len(splitVol) == 1 {
+		dest = src
+	} else if len(splitVol) > 1 {
+		dest = splitVol[1]
+	}
+	if len(splitVol) > 2 {
+		if options, err = parse.ValidateVolumeOpts(strings.Split(splitVol[2], ",")); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := parse.ValidateVolumeHostDir(src); err != nil {
+		return nil, nil, err
+	}
+	if err := parse.ValidateVolumeCtrDir(dest); err != nil {
+		return nil, nil, err
+	}
+
+	logrus.Debugf("User mount %s:%s options %v", src, dest, options)
+
+	if strings.HasPrefix(src, "/") || strings.HasPrefix(src, ".") {
+		// This is not a named volume
+		return &spec.Mount{
+			Destination: dest,
+			Type:        string(TypeBind),
+			Source:      src,
+			Options:     options,
+		}, nil, nil
+	}
+
+	// This is a named volume
+	newNamedVol := new(libpod.ContainerNamedVolume)
+	newNamedVol.Name = src
+	newNamedVol.Dest = dest
+	newNamedVol.Options = options
+	return nil, newNamedVol, nil
+}
+
 // Get mounts for container's image volumes
 func (config *CreateConfig) getImageVolumes() (map[string]spec.Mount, map[string]*libpod.ContainerNamedVolume, error) {
 	mounts := make(map[string]spec.Mount)
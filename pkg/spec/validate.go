@@ -0,0 +1,187 @@
+package createconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/containers/buildah/pkg/parse"
+	"github.com/docker/go-units"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// ValidationError is a single problem found while validating a CreateConfig.
+type ValidationError struct {
+	// Option is the option the problem was found in, e.g. "mount".
+	Option string
+	// Value is the offending value, if there is a single one.
+	Value string
+	// Err is the problem that was found.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("invalid %s: %v", e.Option, e.Err)
+	}
+	return fmt.Sprintf("invalid %s %q: %v", e.Option, e.Value, e.Err)
+}
+
+// Cause returns the underlying error, so errors.Cause() can be used on a
+// ValidationError.
+func (e *ValidationError) Cause() error {
+	return e.Err
+}
+
+// Validate checks the CreateConfig for invalid mounts, conflicting namespace
+// settings, invalid port bindings and invalid ulimits.
+// Rather than stopping at the first problem, all problems are collected. If
+// any are found, a *multierror.Error is returned whose Errors are all
+// *ValidationError.
+func (config *CreateConfig) Validate() error {
+	var result *multierror.Error
+
+	addErr := func(option, value string, err error) {
+		result = multierror.Append(result, &ValidationError{Option: option, Value: value, Err: err})
+	}
+
+	config.validateMounts(addErr)
+	config.validateNamespaces(addErr)
+	config.validatePorts(addErr)
+	config.validateUlimits(addErr)
+
+	return result.ErrorOrNil()
+}
+
+// validateMounts checks the --mount, --volume and --tmpfs options.
+func (config *CreateConfig) validateMounts(addErr func(string, string, error)) {
+	mountDests := make(map[string]bool)
+	volumeDests := make(map[string]bool)
+
+	checkDest := func(option, value, dest string, dests map[string]bool) {
+		if dests[dest] {
+			addErr(option, value, errors.Wrapf(errDuplicateDest, dest))
+		}
+		dests[dest] = true
+	}
+
+	for _, mountFlag := range config.MountsFlag {
+		mount, volume, err := parseMountFlag(mountFlag)
+		if err != nil {
+			addErr("mount", mountFlag, err)
+			continue
+		}
+		if mount != nil {
+			checkDest("mount", mountFlag, mount.Destination, mountDests)
+		} else {
+			checkDest("mount", mountFlag, volume.Dest, volumeDests)
+		}
+	}
+	for _, vol := range config.Volumes {
+		mount, volume, err := parseVolumeFlag(vol)
+		if err != nil {
+			addErr("volume", vol, err)
+			continue
+		}
+		if mount != nil {
+			checkDest("volume", vol, mount.Destination, mountDests)
+		} else {
+			checkDest("volume", vol, volume.Dest, volumeDests)
+		}
+	}
+	for _, tmpfs := range config.Tmpfs {
+		dest := strings.Split(tmpfs, ":")[0]
+		if err := parse.ValidateVolumeCtrDir(dest); err != nil {
+			addErr("tmpfs", tmpfs, err)
+			continue
+		}
+		checkDest("tmpfs", tmpfs, dest, mountDests)
+	}
+}
+
+// validateNamespaces checks that the namespace modes are valid and do not
+// conflict with other options.
+func (config *CreateConfig) validateNamespaces(addErr func(string, string, error)) {
+	if !Valid(string(config.PidMode), config.PidMode) {
+		addErr("pid", string(config.PidMode), errors.New("not a valid PID namespace mode"))
+	}
+	if !Valid(string(config.UsernsMode), config.UsernsMode) {
+		addErr("userns", string(config.UsernsMode), errors.New("not a valid user namespace mode"))
+	}
+	if !Valid(string(config.UtsMode), config.UtsMode) {
+		addErr("uts", string(config.UtsMode), errors.New("not a valid UTS namespace mode"))
+	}
+	if !Valid(string(config.IpcMode), config.IpcMode) {
+		addErr("ipc", string(config.IpcMode), errors.New("not a valid IPC namespace mode"))
+	}
+	if !config.CgroupMode.Valid() {
+		addErr("cgroup", string(config.CgroupMode), errors.New("not a valid cgroup mode"))
+	}
+
+	netMode := config.NetMode
+	if netMode.IsContainer() || netMode.IsNone() {
+		if len(config.PortBindings) > 0 {
+			addErr("network", string(netMode), errors.New("cannot set port bindings when the network namespace is not created for the container"))
+		}
+	}
+	if netMode.IsContainer() {
+		if len(config.DNSServers) > 0 || len(config.DNSSearch) > 0 || len(config.DNSOpt) > 0 {
+			addErr("network", string(netMode), errors.New("cannot set DNS options on an existing container network namespace"))
+		}
+		if len(config.HostAdd) > 0 {
+			addErr("network", string(netMode), errors.New("cannot add hosts on an existing container network namespace"))
+		}
+	}
+	if config.NetIngressRate != 0 || config.NetEgressRate != 0 {
+		if netMode.IsContainer() || netMode.IsNone() || netMode.IsHost() || netMode.IsNS() || netMode.IsSlirp4netns() || netMode.IsPasta() {
+			addErr("network", string(netMode), errors.New("cannot limit bandwidth unless the container uses CNI networking"))
+		}
+	}
+	if config.NoHosts && len(config.HostAdd) > 0 {
+		addErr("add-host", "", errors.New("cannot be set together with --no-hosts"))
+	}
+}
+
+// validatePorts checks the port bindings.
+func (config *CreateConfig) validatePorts(addErr func(string, string, error)) {
+	for containerPort, hostPorts := range config.PortBindings {
+		switch containerPort.Proto() {
+		case "tcp", "udp", "sctp":
+		default:
+			addErr("publish", string(containerPort), errors.Errorf("unknown protocol %q", containerPort.Proto()))
+		}
+		if port := containerPort.Int(); port < 1 || port > 65535 {
+			addErr("publish", string(containerPort), errors.New("container port must be between 1 and 65535"))
+		}
+		for _, hostPort := range hostPorts {
+			if hostPort.HostPort == "" {
+				continue
+			}
+			port, err := strconv.Atoi(hostPort.HostPort)
+			if err != nil {
+				addErr("publish", hostPort.HostPort, errors.Wrapf(err, "unable to convert host port to integer"))
+				continue
+			}
+			if port < 0 || port > 65535 {
+				addErr("publish", hostPort.HostPort, errors.New("host port must be between 0 and 65535"))
+			}
+		}
+	}
+}
+
+// validateUlimits checks the --ulimit options.
+func (config *CreateConfig) validateUlimits(addErr func(string, string, error)) {
+	for _, u := range config.Resources.Ulimit {
+		if u == "host" {
+			if len(config.Resources.Ulimit) != 1 {
+				addErr("ulimit", u, errors.New("host cannot be combined with other ulimits"))
+			}
+			continue
+		}
+		if _, err := units.ParseUlimit(u); err != nil {
+			addErr("ulimit", u, errors.Wrapf(err, "requires name=SOFT:HARD"))
+		}
+	}
+}
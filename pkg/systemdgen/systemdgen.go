@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"text/template"
 	"time"
 
@@ -40,6 +42,18 @@ type ContainerInfo struct {
 	// RequiredServices are services this service requires. Note that this
 	// service runs before them.
 	RequiredServices []string
+	// WantedServices are services this service wants. Note that this
+	// service runs before them.
+	WantedServices []string
+	// New, if set the service creates a new container with CreateCommand
+	// when started, and removes it when stopped.
+	New bool
+	// CreateCommand is the command line the container was created with.
+	// Required if New is set.
+	CreateCommand []string
+	// RunCommand creates and starts the container if New is set. Will be
+	// set internally.
+	RunCommand string
 	// PodmanVersion for the header. Will be set internally. Will be auto-filled
 	// if left empty.
 	PodmanVersion string
@@ -81,11 +95,23 @@ After={{- range $index, $value := .BoundToServices -}}{{if $index}} {{end}}{{ $v
 Requires={{- range $index, $value := .RequiredServices -}}{{if $index}} {{end}}{{ $value }}.service{{end}}
 Before={{- range $index, $value := .RequiredServices -}}{{if $index}} {{end}}{{ $value }}.service{{end}}
 {{- end}}
+{{- if .WantedServices}}
+Wants={{- range $index, $value := .WantedServices -}}{{if $index}} {{end}}{{ $value }}.service{{end}}
+Before={{- range $index, $value := .WantedServices -}}{{if $index}} {{end}}{{ $value }}.service{{end}}
+{{- end}}
 
 [Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
 Restart={{.RestartPolicy}}
+{{- if .New}}
+ExecStartPre=-{{.Executable}} rm -f {{.ContainerName}}
+ExecStart={{.RunCommand}}
+ExecStop={{.Executable}} stop {{if (ge .StopTimeout 0)}}-t {{.StopTimeout}}{{end}} {{.ContainerName}}
+ExecStopPost=-{{.Executable}} rm -f {{.ContainerName}}
+{{- else}}
 ExecStart={{.Executable}} start {{.ContainerName}}
 ExecStop={{.Executable}} stop {{if (ge .StopTimeout 0)}}-t {{.StopTimeout}}{{end}} {{.ContainerName}}
+{{- end}}
 KillMode=none
 Type=forking
 PIDFile={{.PIDFile}}
@@ -93,6 +119,65 @@ PIDFile={{.PIDFile}}
 [Install]
 WantedBy=multi-user.target`
 
+// createRunCommand returns the command line creating and starting the
+// container, derived from the command line it was created with. The container
+// is run detached, with the PID file of conmon in the runtime directory of
+// the service, and named so that ExecStop and ExecStopPost find it.
+func createRunCommand(info *ContainerInfo) (string, error) {
+	index := -1
+	for i, arg := range info.CreateCommand {
+		if i == 0 {
+			continue
+		}
+		if arg == "create" || arg == "run" {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return "", errors.Errorf("container %s was not created with podman create or run", info.ContainerName)
+	}
+
+	// Keep the global flags, e.g. the storage options
+	command := []string{info.Executable}
+	for _, arg := range info.CreateCommand[1:index] {
+		command = append(command, escapeArg(arg))
+	}
+	command = append(command, "run", "--conmon-pidfile", "%t/%n-pid", "-d")
+	args := info.CreateCommand[index+1:]
+	hasName := false
+	for _, arg := range args {
+		if arg == "--name" || strings.HasPrefix(arg, "--name=") {
+			hasName = true
+		}
+	}
+	if !hasName {
+		command = append(command, "--name", escapeArg(info.ContainerName))
+	}
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--conmon-pidfile":
+			// Skip the value as well
+			i++
+		case strings.HasPrefix(args[i], "--conmon-pidfile="):
+		default:
+			command = append(command, escapeArg(args[i]))
+		}
+	}
+	return strings.Join(command, " "), nil
+}
+
+// escapeArg escapes the argument of a command line of a unit, quoting it if
+// needed and escaping systemd specifiers and environment variables
+func escapeArg(arg string) string {
+	arg = strings.Replace(arg, "%", "%%", -1)
+	arg = strings.Replace(arg, "$", "$$", -1)
+	if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\;") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
 // CreateContainerSystemdUnit creates a systemd unit file for a container.
 func CreateContainerSystemdUnit(info *ContainerInfo, generateFiles bool) (string, error) {
 	if err := validateRestartPolicy(info.RestartPolicy); err != nil {
@@ -116,8 +201,18 @@ func CreateContainerSystemdUnit(info *ContainerInfo, generateFiles bool) (string
 		info.TimeStamp = fmt.Sprintf("%v", time.Now().Format(time.UnixDate))
 	}
 
+	if info.New {
+		runCommand, err := createRunCommand(info)
+		if err != nil {
+			return "", err
+		}
+		info.RunCommand = runCommand
+		info.PIDFile = "%t/%n-pid"
+	}
+
 	// Sort the slices to assure a deterministic output.
 	sort.Strings(info.RequiredServices)
+	sort.Strings(info.WantedServices)
 	sort.Strings(info.BoundToServices)
 
 	// Generate the template and compile it.
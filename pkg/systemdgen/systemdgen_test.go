@@ -41,6 +41,7 @@ Description=Podman container-639c53578af4d84b8800b4635fa4e680ee80fd67e0e6a2d4eea
 Documentation=man:podman-generate-systemd(1)
 
 [Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
 Restart=always
 ExecStart=/usr/bin/podman start 639c53578af4d84b8800b4635fa4e680ee80fd67e0e6a2d4eea48d1e3230f401
 ExecStop=/usr/bin/podman stop -t 10 639c53578af4d84b8800b4635fa4e680ee80fd67e0e6a2d4eea48d1e3230f401
@@ -59,6 +60,7 @@ Description=Podman container-foobar.service
 Documentation=man:podman-generate-systemd(1)
 
 [Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
 Restart=always
 ExecStart=/usr/bin/podman start foobar
 ExecStop=/usr/bin/podman stop -t 10 foobar
@@ -81,6 +83,7 @@ BindsTo=a.service b.service c.service pod.service
 After=a.service b.service c.service pod.service
 
 [Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
 Restart=always
 ExecStart=/usr/bin/podman start foobar
 ExecStop=/usr/bin/podman stop -t 10 foobar
@@ -101,6 +104,7 @@ Requires=container-1.service container-2.service
 Before=container-1.service container-2.service
 
 [Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
 Restart=always
 ExecStart=/usr/bin/podman start jadda-jadda-infra
 ExecStop=/usr/bin/podman stop -t 10 jadda-jadda-infra
@@ -108,6 +112,48 @@ KillMode=none
 Type=forking
 PIDFile=/var/run/containers/storage/overlay-containers/639c53578af4d84b8800b4635fa4e680ee80fd67e0e6a2d4eea48d1e3230f401/userdata/conmon.pid
 
+[Install]
+WantedBy=multi-user.target`
+
+	podGoodNameWants := `# pod-123abc.service
+# autogenerated by Podman CI
+
+[Unit]
+Description=Podman pod-123abc.service
+Documentation=man:podman-generate-systemd(1)
+Wants=container-1.service container-2.service
+Before=container-1.service container-2.service
+
+[Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
+Restart=on-failure
+ExecStart=/usr/bin/podman start jadda-jadda-infra
+ExecStop=/usr/bin/podman stop -t 10 jadda-jadda-infra
+KillMode=none
+Type=forking
+PIDFile=/var/run/containers/storage/overlay-containers/639c53578af4d84b8800b4635fa4e680ee80fd67e0e6a2d4eea48d1e3230f401/userdata/conmon.pid
+
+[Install]
+WantedBy=multi-user.target`
+
+	goodNameNew := `# container-foobar.service
+# autogenerated by Podman CI
+
+[Unit]
+Description=Podman container-foobar.service
+Documentation=man:podman-generate-systemd(1)
+
+[Service]
+Environment=PODMAN_SYSTEMD_UNIT=%n
+Restart=always
+ExecStartPre=-/usr/bin/podman rm -f foobar
+ExecStart=/usr/bin/podman --log-level debug run --conmon-pidfile %t/%n-pid -d --name foobar -p 80:80 -e "GREETING=hello world" -e PRICE=100%% -e HOME=$$HOME alpine sh -c "echo \"hi\"; top"
+ExecStop=/usr/bin/podman stop -t 10 foobar
+ExecStopPost=-/usr/bin/podman rm -f foobar
+KillMode=none
+Type=forking
+PIDFile=%t/%n-pid
+
 [Install]
 WantedBy=multi-user.target`
 
@@ -172,6 +218,48 @@ WantedBy=multi-user.target`
 			podGoodName,
 			false,
 		},
+		{"pod wanting containers",
+			ContainerInfo{
+				Executable:     "/usr/bin/podman",
+				ServiceName:    "pod-123abc",
+				ContainerName:  "jadda-jadda-infra",
+				RestartPolicy:  "on-failure",
+				PIDFile:        "/var/run/containers/storage/overlay-containers/639c53578af4d84b8800b4635fa4e680ee80fd67e0e6a2d4eea48d1e3230f401/userdata/conmon.pid",
+				StopTimeout:    10,
+				PodmanVersion:  "CI",
+				WantedServices: []string{"container-2", "container-1"},
+			},
+			podGoodNameWants,
+			false,
+		},
+		{"new container",
+			ContainerInfo{
+				Executable:    "/usr/bin/podman",
+				ServiceName:   "container-foobar",
+				ContainerName: "foobar",
+				RestartPolicy: "always",
+				StopTimeout:   10,
+				PodmanVersion: "CI",
+				New:           true,
+				CreateCommand: []string{"podman", "--log-level", "debug", "create", "--conmon-pidfile", "/tmp/pid", "-p", "80:80", "-e", "GREETING=hello world", "-e", "PRICE=100%", "-e", "HOME=$HOME", "alpine", "sh", "-c", `echo "hi"; top`},
+			},
+			goodNameNew,
+			false,
+		},
+		{"new container without create command",
+			ContainerInfo{
+				Executable:    "/usr/bin/podman",
+				ServiceName:   "container-foobar",
+				ContainerName: "foobar",
+				RestartPolicy: "always",
+				StopTimeout:   10,
+				PodmanVersion: "CI",
+				New:           true,
+				CreateCommand: []string{"podman", "play", "kube", "pod.yaml"},
+			},
+			"",
+			true,
+		},
 		{"bad restart policy",
 			ContainerInfo{
 				Executable:    "/usr/bin/podman",
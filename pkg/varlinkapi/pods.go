@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/containers/libpod/pkg/adapter/shortcuts"
+	"strings"
 	"syscall"
 
 	"github.com/containers/libpod/cmd/podman/shared"
@@ -50,6 +51,12 @@ func (i *LibpodAPI) CreatePod(call iopodman.VarlinkCall, create iopodman.PodCrea
 			return err
 		}
 		options = append(options, nsOptions...)
+		if create.InfraImage != "" {
+			options = append(options, libpod.WithInfraContainerImage(create.InfraImage))
+		}
+		if create.InfraCommand != "" {
+			options = append(options, libpod.WithInfraContainerCommand(strings.Fields(create.InfraCommand)))
+		}
 	}
 	options = append(options, libpod.WithPodCgroups())
 
@@ -265,11 +272,11 @@ func (i *LibpodAPI) GetPodStats(call iopodman.VarlinkCall, name string) error {
 	if err != nil {
 		return call.ReplyErrorOccurred(err.Error())
 	}
-	if len(podStats) == 0 {
+	if len(podStats.Containers) == 0 {
 		return call.ReplyNoContainerRunning()
 	}
 	containersStats := make([]iopodman.ContainerStats, 0)
-	for ctrID, containerStats := range podStats {
+	for ctrID, containerStats := range podStats.Containers {
 		cs := iopodman.ContainerStats{
 			Id:           ctrID,
 			Name:         containerStats.Name,
@@ -0,0 +1,89 @@
+// +build varlink
+
+package varlinkapi
+
+import (
+	"fmt"
+
+	iopodman "github.com/containers/libpod/cmd/podman/varlink"
+	"github.com/sirupsen/logrus"
+	"github.com/varlink/go/varlink"
+)
+
+// authenticateMethod is the method clients call to present their API token.
+const authenticateMethod = "Authenticate"
+
+// ScopedInterface is a varlink interface that requires clients to
+// authenticate with an API token. The calls of a client are only made if its
+// token allows them, and are served by the API of the token's namespace.
+type ScopedInterface struct {
+	*iopodman.VarlinkInterface
+
+	tokens   *APITokens
+	apis     map[string]*iopodman.VarlinkInterface
+	sessions tokenSessions
+}
+
+// NewScoped creates a varlink interface accepting the given tokens. apis must
+// hold the API of every namespace the tokens are scoped to.
+func NewScoped(tokens *APITokens, apis map[string]*iopodman.VarlinkInterface) (*ScopedInterface, error) {
+	if len(apis) == 0 {
+		return nil, fmt.Errorf("no varlink APIs to serve")
+	}
+	scoped := &ScopedInterface{
+		tokens: tokens,
+		apis:   apis,
+	}
+	for _, namespace := range tokens.Namespaces() {
+		api, ok := apis[namespace]
+		if !ok {
+			return nil, fmt.Errorf("no varlink API for namespace %q", namespace)
+		}
+		scoped.VarlinkInterface = api
+	}
+	if scoped.VarlinkInterface == nil {
+		for _, api := range apis {
+			scoped.VarlinkInterface = api
+			break
+		}
+	}
+	return scoped, nil
+}
+
+// VarlinkDispatch authenticates clients, and passes their other calls on to
+// the API of their token's namespace if the token allows them.
+func (s *ScopedInterface) VarlinkDispatch(call varlink.Call, methodname string) error {
+	c := iopodman.VarlinkCall{Call: call}
+	if methodname == authenticateMethod {
+		return s.authenticate(c)
+	}
+
+	token := s.sessions.token(call.Conn)
+	if token == nil {
+		return c.ReplyNotAuthorized(fmt.Sprintf("authentication is required to call %s", methodname))
+	}
+	if !token.Allows(methodname) {
+		logrus.Debugf("API token %s is not allowed to call %s", token.Name, methodname)
+		return c.ReplyNotAuthorized(fmt.Sprintf("API token %s is not allowed to call %s", token.Name, methodname))
+	}
+	return s.apis[token.Namespace].VarlinkDispatch(call, methodname)
+}
+
+// authenticate binds the client's connection to the API token it presents.
+func (s *ScopedInterface) authenticate(call iopodman.VarlinkCall) error {
+	var in struct {
+		Token string `json:"token"`
+	}
+	if err := call.GetParameters(&in); err != nil {
+		return call.ReplyInvalidParameter("parameters")
+	}
+
+	token, err := s.tokens.Lookup(in.Token)
+	if err != nil {
+		return call.ReplyNotAuthorized(err.Error())
+	}
+	s.sessions.bind(call.Conn, token)
+	logrus.Debugf("Client authenticated with API token %s for namespace %q", token.Name, token.Namespace)
+
+	return call.ReplyAuthenticate(token.Namespace)
+}
@@ -11,6 +11,16 @@ import (
 	"github.com/containers/libpod/cmd/podman/varlink"
 )
 
+// Authenticate is only served by a ScopedInterface. Services without API
+// tokens accept every client, so the namespace of the service is returned.
+func (i *LibpodAPI) Authenticate(call iopodman.VarlinkCall, token string) error {
+	config, err := i.Runtime.GetConfig()
+	if err != nil {
+		return call.ReplyErrorOccurred(err.Error())
+	}
+	return call.ReplyAuthenticate(config.Namespace)
+}
+
 // GetVersion ...
 func (i *LibpodAPI) GetVersion(call iopodman.VarlinkCall) error {
 	versionInfo, err := define.GetVersion()
@@ -0,0 +1,171 @@
+package varlinkapi
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"path"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidAPIToken indicates that a client presented a token that is not
+// accepted by the varlink service.
+var ErrInvalidAPIToken = errors.New("invalid API token")
+
+// APIToken grants clients of the varlink service access to a single libpod
+// namespace, limited to a set of methods.
+type APIToken struct {
+	// Name identifies the token in logs.
+	Name string `json:"name"`
+	// SHA256 is the hex-encoded SHA-256 digest of the token. The token
+	// itself is not stored.
+	SHA256 string `json:"sha256"`
+	// Namespace is the libpod namespace the token is scoped to. An empty
+	// namespace is the one the service was started with.
+	Namespace string `json:"namespace"`
+	// Allow lists the varlink methods the token may call, as method names
+	// or shell patterns such as "Get*".
+	Allow []string `json:"allow"`
+
+	digest []byte
+}
+
+// Allows returns whether the token may call the given varlink method.
+func (t *APIToken) Allows(method string) bool {
+	for _, pattern := range t.Allow {
+		// The patterns were validated when the tokens were loaded
+		if matched, _ := path.Match(pattern, method); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokens are the tokens accepted by the varlink service.
+type APITokens struct {
+	tokens []*APIToken
+}
+
+// LoadAPITokens loads the tokens in the given file, a JSON list of APIToken.
+func LoadAPITokens(path string) (*APITokens, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading API tokens file %s", path)
+	}
+	var tokens []*APIToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, errors.Wrapf(err, "error decoding API tokens file %s", path)
+	}
+	return newAPITokens(tokens)
+}
+
+func newAPITokens(tokens []*APIToken) (*APITokens, error) {
+	names := make(map[string]bool)
+	for _, token := range tokens {
+		if token.Name == "" {
+			return nil, errors.Errorf("API tokens must have a name")
+		}
+		if names[token.Name] {
+			return nil, errors.Errorf("API token name %s is used more than once", token.Name)
+		}
+		names[token.Name] = true
+
+		digest, err := hex.DecodeString(token.SHA256)
+		if err != nil || len(digest) != sha256.Size {
+			return nil, errors.Errorf("API token %s does not have a valid SHA-256 digest", token.Name)
+		}
+		token.digest = digest
+
+		for _, pattern := range token.Allow {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, errors.Wrapf(err, "invalid method pattern %q of API token %s", pattern, token.Name)
+			}
+		}
+	}
+	return &APITokens{tokens: tokens}, nil
+}
+
+// Lookup returns the API token matching the token presented by a client.
+func (t *APITokens) Lookup(token string) (*APIToken, error) {
+	digest := sha256.Sum256([]byte(token))
+	var found *APIToken
+	// Compare against every token in constant time, so the time taken
+	// does not reveal how close a guess was
+	for _, apiToken := range t.tokens {
+		if subtle.ConstantTimeCompare(digest[:], apiToken.digest) == 1 {
+			found = apiToken
+		}
+	}
+	if found == nil {
+		return nil, ErrInvalidAPIToken
+	}
+	return found, nil
+}
+
+// Namespaces returns the namespaces the tokens are scoped to.
+func (t *APITokens) Namespaces() []string {
+	seen := make(map[string]bool)
+	namespaces := []string{}
+	for _, token := range t.tokens {
+		if !seen[token.Namespace] {
+			seen[token.Namespace] = true
+			namespaces = append(namespaces, token.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// tokenSessions records the API token each client connection authenticated
+// with.
+type tokenSessions struct {
+	lock     sync.Mutex
+	sessions map[*net.Conn]*APIToken
+}
+
+// bind records that the connection authenticated with the token. Sessions of
+// connections that were closed since are forgotten.
+func (s *tokenSessions) bind(conn *net.Conn, token *APIToken) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.sessions == nil {
+		s.sessions = make(map[*net.Conn]*APIToken)
+	}
+	for c := range s.sessions {
+		if connClosed(*c) {
+			delete(s.sessions, c)
+		}
+	}
+	s.sessions[conn] = token
+}
+
+// token returns the API token the connection authenticated with, or nil if it
+// has not authenticated.
+func (s *tokenSessions) token(conn *net.Conn) *APIToken {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.sessions[conn]
+}
+
+// connClosed returns whether the connection was closed. Connections that do
+// not expose their file descriptor are assumed to be open.
+func connClosed(conn net.Conn) bool {
+	sysConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+	rawConn, err := sysConn.SyscallConn()
+	if err != nil {
+		return true
+	}
+	return rawConn.Control(func(uintptr) {}) != nil
+}
@@ -0,0 +1,83 @@
+package varlinkapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func digest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAPITokens(t *testing.T) {
+	tokens, err := newAPITokens([]*APIToken{
+		{Name: "ci", SHA256: digest("ci-secret"), Namespace: "ci", Allow: []string{"Get*", "StartContainer"}},
+		{Name: "monitor", SHA256: digest("monitor-secret"), Allow: []string{"ListContainers"}},
+	})
+	require.NoError(t, err)
+
+	token, err := tokens.Lookup("ci-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", token.Name)
+	assert.True(t, token.Allows("GetContainer"))
+	assert.True(t, token.Allows("StartContainer"))
+	assert.False(t, token.Allows("RemoveContainer"))
+
+	_, err = tokens.Lookup("wrong")
+	assert.Equal(t, ErrInvalidAPIToken, errors.Cause(err))
+
+	assert.Equal(t, []string{"", "ci"}, tokens.Namespaces())
+}
+
+func TestAPITokensInvalid(t *testing.T) {
+	_, err := newAPITokens([]*APIToken{{Name: "a", SHA256: "abc"}})
+	assert.Error(t, err)
+
+	_, err = newAPITokens([]*APIToken{{Name: "a", SHA256: digest("x"), Allow: []string{"["}}})
+	assert.Error(t, err)
+
+	_, err = newAPITokens([]*APIToken{{Name: "a", SHA256: digest("x")}, {Name: "a", SHA256: digest("y")}})
+	assert.Error(t, err)
+}
+
+func TestTokenSessions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "varlinkapi_test_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("unix", listener.Addr().String())
+		require.NoError(t, err)
+		return conn
+	}
+	first, second := dial(), dial()
+	defer second.Close()
+
+	token := &APIToken{Name: "ci"}
+	var sessions tokenSessions
+	assert.Nil(t, sessions.token(&first))
+
+	sessions.bind(&first, token)
+	assert.Equal(t, token, sessions.token(&first))
+	assert.Nil(t, sessions.token(&second))
+
+	// Closed connections are forgotten when another one authenticates
+	first.Close()
+	sessions.bind(&second, token)
+	assert.Nil(t, sessions.token(&first))
+	assert.Equal(t, token, sessions.token(&second))
+}
@@ -3,6 +3,7 @@
 package varlinkapi
 
 import (
+	"github.com/containers/libpod/cmd/podman/shared"
 	"github.com/containers/libpod/cmd/podman/varlink"
 	"github.com/containers/libpod/libpod"
 )
@@ -75,11 +76,15 @@ func (i *LibpodAPI) GetVolumes(call iopodman.VarlinkCall, args []string, all boo
 }
 
 // VolumesPrune removes unused images via a varlink call
-func (i *LibpodAPI) VolumesPrune(call iopodman.VarlinkCall) error {
+func (i *LibpodAPI) VolumesPrune(call iopodman.VarlinkCall, filters []string) error {
 	var errs []string
-	prunedNames, prunedErrors := i.Runtime.PruneVolumes(getContext())
+	filterFuncs, err := shared.GenerateVolumePruneFilters(filters)
+	if err != nil {
+		return call.ReplyErrorOccurred(err.Error())
+	}
+	prunedNames, reclaimed, prunedErrors := i.Runtime.PruneVolumes(getContext(), filterFuncs...)
 	if len(prunedErrors) == 0 {
-		return call.ReplyVolumesPrune(prunedNames, []string{})
+		return call.ReplyVolumesPrune(prunedNames, int64(reclaimed), []string{})
 	}
 
 	// We need to take the errors and capture their strings to go back over
@@ -87,5 +92,43 @@ func (i *LibpodAPI) VolumesPrune(call iopodman.VarlinkCall) error {
 	for _, e := range prunedErrors {
 		errs = append(errs, e.Error())
 	}
-	return call.ReplyVolumesPrune(prunedNames, errs)
+	return call.ReplyVolumesPrune(prunedNames, int64(reclaimed), errs)
+}
+
+// GetVolumesDiskUsage returns the disk usage of volumes via a varlink call
+func (i *LibpodAPI) GetVolumesDiskUsage(call iopodman.VarlinkCall, args []string, all bool) error {
+	var (
+		err     error
+		volumes []*libpod.Volume
+		usage   []iopodman.VolumeDiskUsage
+	)
+	if all {
+		volumes, err = i.Runtime.GetAllVolumes()
+		if err != nil {
+			return call.ReplyErrorOccurred(err.Error())
+		}
+	} else {
+		for _, v := range args {
+			vol, err := i.Runtime.GetVolume(v)
+			if err != nil {
+				return call.ReplyVolumeNotFound(v, err.Error())
+			}
+			volumes = append(volumes, vol)
+		}
+		if len(volumes) == 0 {
+			return call.ReplyGetVolumesDiskUsage(usage)
+		}
+	}
+	volUsages, err := i.Runtime.VolumeDiskUsage(volumes...)
+	if err != nil {
+		return call.ReplyErrorOccurred(err.Error())
+	}
+	for _, volUsage := range volUsages {
+		usage = append(usage, iopodman.VolumeDiskUsage{
+			Name:      volUsage.Name,
+			SizeLimit: int64(volUsage.SizeLimit),
+			Used:      int64(volUsage.Used),
+		})
+	}
+	return call.ReplyGetVolumesDiskUsage(usage)
 }